@@ -18,6 +18,7 @@ import (
 	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
 	"github.com/CodeRushOJ/croj-judging-server/internal/external"
 	"github.com/CodeRushOJ/croj-judging-server/internal/httpapi"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 	"github.com/CodeRushOJ/croj-judging-server/internal/service"
 	"github.com/CodeRushOJ/croj-judging-server/internal/worker"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/config"
@@ -47,6 +48,10 @@ func buildWebhookWorkers(externalConfig config.ExternalAPIConfig, database *sql.
 	if err != nil {
 		return nil, err
 	}
+	signingKeys, err := external.DecodeWebhookSigningKeyRing(externalConfig.WebhookSigningKeyID, externalConfig.WebhookSigningKeysJSON)
+	if err != nil {
+		return nil, err
+	}
 	outbox, err := external.NewMySQLWebhookOutboxRepository(external.MySQLWebhookOutboxRepositoryConfig{
 		Database: database, Random: rand.Reader,
 	})
@@ -57,7 +62,7 @@ func buildWebhookWorkers(externalConfig config.ExternalAPIConfig, database *sql.
 	for index := 0; index < externalConfig.WebhookWorkerConcurrency; index++ {
 		workerID := externalConfig.WorkerID + "-webhook-" + strconv.Itoa(index)
 		webhookWorker, err := external.NewWebhookWorker(external.WebhookWorkerConfig{
-			Repository: outbox, CallbackCipher: callbackCipher, WorkerID: workerID,
+			Repository: outbox, CallbackCipher: callbackCipher, SigningKeys: signingKeys, WorkerID: workerID,
 		})
 		if err != nil {
 			return nil, err
@@ -185,7 +190,8 @@ func newExternalRuntime(
 	if err != nil {
 		return nil, err
 	}
-	if externalConfig.WorkerConcurrency <= 0 || externalConfig.BundleUploadConcurrency <= 0 || externalConfig.JobBodyConcurrency <= 0 || strings.TrimSpace(externalConfig.WorkerID) == "" ||
+	if externalConfig.WorkerConcurrency <= 0 || externalConfig.BundleUploadConcurrency <= 0 || externalConfig.JobBodyConcurrency <= 0 ||
+		externalConfig.JobEventStreamConcurrency <= 0 || externalConfig.RunConcurrency <= 0 || strings.TrimSpace(externalConfig.WorkerID) == "" ||
 		strings.TrimSpace(externalConfig.RedisAddress) == "" || externalConfig.SourceKeyVersion <= 0 || externalConfig.SourceKeyVersion > 65535 {
 		return nil, fmt.Errorf("external worker concurrency and source key version are invalid")
 	}
@@ -228,6 +234,7 @@ func newExternalRuntime(
 		MaxUploadBytes: cfg.TestBundles.MaxObjectBytes, ArchiveLimits: archiveLimits,
 		MaxTimeLimitMillis: cfg.TestBundles.MaxTimeLimitMillis, MaxMemoryLimitMiB: cfg.TestBundles.MaxMemoryLimitMiB,
 		IdempotencyTTL: idempotencyTTL, IdempotencyPepper: idempotencyPepper, Random: rand.Reader,
+		CursorKey: cursorKey,
 	})
 	if err != nil {
 		return nil, err
@@ -244,11 +251,24 @@ func newExternalRuntime(
 	if err != nil {
 		return nil, err
 	}
+	metrics.SetQueueDepthSource(queueDepthSource(jobRepository))
+	customRunner, err := worker.NewCustomRunner(jobRepository, core)
+	if err != nil {
+		return nil, err
+	}
+	runService, err := httpapi.NewCanonicalRunService(customRunner)
+	if err != nil {
+		return nil, err
+	}
 	credentialStore, err := external.NewSQLCredentialStore(database)
 	if err != nil {
 		return nil, err
 	}
-	authenticator, err := httpapi.NewAuthenticator(credentialStore, authPepper)
+	apiKeyUsage, err := external.NewAPIKeyUsageRecorder(database, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	authenticator, err := httpapi.NewAuthenticator(credentialStore, authPepper, httpapi.WithCredentialUsageRecorder(apiKeyUsage))
 	if err != nil {
 		return nil, err
 	}
@@ -269,11 +289,33 @@ func newExternalRuntime(
 		JudgeModes: []string{"ACM", "OI"}, Checkers: external.CanonicalCheckers(),
 		Limits: httpapi.CapabilityLimits{
 			MaxSourceBytes: external.MaximumSourceBytes, MaxBundleBytes: cfg.TestBundles.MaxObjectBytes,
-			MaxCaseBytes: cfg.TestBundles.MaxCaseBytes, MaxCaseCount: 256,
+			MaxCaseBytes: cfg.TestBundles.MaxCaseBytes, MaxCaseCount: external.MaximumBundleCases,
 			MaxTimeLimitMillis: cfg.TestBundles.MaxTimeLimitMillis, MaxMemoryLimitMiB: cfg.TestBundles.MaxMemoryLimitMiB,
 		},
 	}
-	handler, err := httpapi.NewServer(authenticator, capabilities,
+	callbackCipher, err := external.DecodeCallbackKeyRing(externalConfig.CallbackKeyVersion, externalConfig.CallbackKeysJSON, rand.Reader)
+	if err != nil {
+		_ = redisClient.Close()
+		return nil, err
+	}
+	callbackProvisioner, err := external.NewProvisioner(database, rand.Reader, external.WithCallbackCipher(callbackCipher))
+	if err != nil {
+		_ = redisClient.Close()
+		return nil, err
+	}
+	webhookDeliveries, err := external.NewMySQLWebhookOutboxRepository(external.MySQLWebhookOutboxRepositoryConfig{
+		Database: database, Random: rand.Reader, CursorKey: cursorKey,
+	})
+	if err != nil {
+		_ = redisClient.Close()
+		return nil, err
+	}
+	signingKeys, err := external.DecodeWebhookSigningKeyRing(externalConfig.WebhookSigningKeyID, externalConfig.WebhookSigningKeysJSON)
+	if err != nil {
+		_ = redisClient.Close()
+		return nil, err
+	}
+	serverOptions := []httpapi.ServerOption{
 		httpapi.WithJobService(jobService),
 		httpapi.WithJobWriteQuota(quota, external.QuotaLimit{Capacity: externalConfig.JobSubmitCapacity, RefillPeriod: quotaRefill}),
 		httpapi.WithBundleApplication(bundleService),
@@ -282,7 +324,18 @@ func newExternalRuntime(
 		httpapi.WithBundleOperationTimeout(bundleOperationTimeout),
 		httpapi.WithJobBodyProtection(jobBodyReadTimeout, externalConfig.JobBodyConcurrency),
 		httpapi.WithJobSubmitTimeout(jobSubmitTimeout),
-	)
+		httpapi.WithJobEventStream(jobService, externalConfig.JobEventStreamConcurrency),
+		httpapi.WithRunService(runService),
+		httpapi.WithRunQuota(quota, external.QuotaLimit{Capacity: externalConfig.RunCapacity, RefillPeriod: quotaRefill}),
+		httpapi.WithRunConcurrency(externalConfig.RunConcurrency),
+		httpapi.WithCallbackApplication(callbackProvisioner),
+		httpapi.WithWebhookDeliveryApplication(webhookDeliveries),
+		httpapi.WithUsageApplication(jobRepository),
+	}
+	if signingKeys != nil {
+		serverOptions = append(serverOptions, httpapi.WithWebhookSigningKeys(signingKeys))
+	}
+	handler, err := httpapi.NewServer(authenticator, capabilities, serverOptions...)
 	if err != nil {
 		_ = redisClient.Close()
 		return nil, err
@@ -313,6 +366,14 @@ func newExternalRuntime(
 		_ = redisClient.Close()
 		return nil, err
 	}
+	bundleRetentionWorker, err := external.NewBundleRetentionWorker(external.BundleRetentionWorkerConfig{
+		Repository: bundleRepository, Objects: bundleObjects,
+		IdleDelay: retentionIdleDelay, DeleteTimeout: retentionDeleteTimeout,
+	})
+	if err != nil {
+		_ = redisClient.Close()
+		return nil, err
+	}
 	idempotencyRetentionWorker, err := external.NewIdempotencyRetentionWorker(jobRepository, 1000, retentionIdleDelay)
 	if err != nil {
 		_ = redisClient.Close()
@@ -325,7 +386,7 @@ func newExternalRuntime(
 		_ = redisClient.Close()
 		return nil, err
 	}
-	workers := make([]app.Worker, 0, externalConfig.WorkerConcurrency+len(webhookWorkers)+5)
+	workers := make([]app.Worker, 0, externalConfig.WorkerConcurrency+len(webhookWorkers)+8)
 	for index := 0; index < externalConfig.WorkerConcurrency; index++ {
 		workerID := externalConfig.WorkerID + "-" + strconv.Itoa(index)
 		workers = append(workers, app.NewWorker(func(ctx context.Context) error { return runner.Run(ctx, workerID, idleBackoff) }))
@@ -333,14 +394,31 @@ func newExternalRuntime(
 	workers = append(workers, webhookWorkers...)
 	workers = append(workers, app.NewWorker(reservationWorker.Run))
 	workers = append(workers, app.NewWorker(retentionWorker.Run))
+	workers = append(workers, app.NewWorker(bundleRetentionWorker.Run))
 	workers = append(workers, app.NewWorker(idempotencyRetentionWorker.Run))
 	workers = append(workers, app.NewWorker(bundleStagingCollector.Run))
+	workers = append(workers, app.NewWorker(apiKeyUsage.Run))
 	reconciler, err := external.NewBundleReconciler(bundleService)
 	if err != nil {
 		_ = redisClient.Close()
 		return nil, err
 	}
 	workers = append(workers, app.NewWorker(func(ctx context.Context) error { return reconciler.Run(ctx, 5*time.Second) }))
+	if externalConfig.AdminListenAddress != "" {
+		usageExporter, err := external.NewMySQLUsageExporter(database)
+		if err != nil {
+			_ = redisClient.Close()
+			return nil, err
+		}
+		adminHandler, err := httpapi.NewAdminHandler(externalConfig.AdminToken, usageExporter)
+		if err != nil {
+			_ = redisClient.Close()
+			return nil, err
+		}
+		workers = append(workers, app.NewWorker(func(ctx context.Context) error {
+			return serveAdmin(ctx, externalConfig.AdminListenAddress, adminHandler)
+		}))
+	}
 	probes := map[string]app.Probe{
 		"mysql": app.NewProbe(func(ctx context.Context) error {
 			if err := database.PingContext(ctx); err != nil {
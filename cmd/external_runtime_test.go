@@ -30,6 +30,15 @@ func TestBuildWebhookWorkersRequiresKeyRingAndCreatesReplicaOwnedWorkers(t *test
 	if err != nil || len(workers) != 3 {
 		t.Fatalf("workers=%d error=%v", len(workers), err)
 	}
+	config.WebhookSigningKeyID, config.WebhookSigningKeysJSON = "2026-10", `{"2026-10":"`+key+`"}`
+	if workers, err := buildWebhookWorkers(config, &sql.DB{}); err != nil || len(workers) != 3 {
+		t.Fatalf("workers with v2 signing=%d error=%v", len(workers), err)
+	}
+	config.WebhookSigningKeysJSON = ""
+	if _, err := buildWebhookWorkers(config, &sql.DB{}); err == nil {
+		t.Fatal("v2 signing key ID without a key ring was accepted")
+	}
+	config.WebhookSigningKeyID = ""
 	config.CallbackKeysJSON = "{}"
 	if _, err := buildWebhookWorkers(config, &sql.DB{}); err == nil {
 		t.Fatal("missing active callback key was accepted")
@@ -30,7 +30,7 @@ func run() error {
 		return fmt.Errorf("JUDGE_DATABASE_DSN is required")
 	}
 	var pepper []byte
-	if commandMatches(os.Args[1:], "api-key", "create") {
+	if commandMatches(os.Args[1:], "api-key", "create") || commandMatches(os.Args[1:], "api-key", "rotate") {
 		encodedPepper := os.Getenv("JUDGE_API_KEY_PEPPER_B64")
 		decoded, err := base64.StdEncoding.DecodeString(encodedPepper)
 		if err != nil || len(decoded) < 32 {
@@ -72,15 +72,37 @@ func run() error {
 	if err != nil {
 		return err
 	}
-	return admincli.Run(ctx, os.Args[1:], provisioner, pepper, os.Stdout)
+	webhooks, err := external.NewMySQLWebhookOutboxRepository(external.MySQLWebhookOutboxRepositoryConfig{
+		Database: database, Random: rand.Reader,
+	})
+	if err != nil {
+		return err
+	}
+	usage, err := external.NewMySQLUsageExporter(database)
+	if err != nil {
+		return err
+	}
+	return admincli.Run(ctx, os.Args[1:], adminBackend{Provisioner: provisioner, MySQLWebhookOutboxRepository: webhooks, MySQLUsageExporter: usage}, pepper, os.Stdout)
+}
+
+// adminBackend serves provisioning commands from the Provisioner, callback
+// pings from the webhook outbox, which a running judge server's webhook
+// worker delivers, and usage exports from the accounting tables.
+type adminBackend struct {
+	*external.Provisioner
+	*external.MySQLWebhookOutboxRepository
+	*external.MySQLUsageExporter
 }
 
 func migrationOnly(arguments []string) bool {
 	return len(arguments) == 2 && arguments[0] == "schema" && arguments[1] == "migrate"
 }
 
+// callbackProvisionerOptions loads the callback key ring for the commands
+// that encrypt or re-encrypt callback secrets.
 func callbackProvisionerOptions(arguments []string, getenv func(string) string, random io.Reader) ([]external.ProvisionerOption, error) {
-	if !commandMatches(arguments, "callback", "create") {
+	if !commandMatches(arguments, "callback", "create") && !commandMatches(arguments, "callback", "rotate-secret") &&
+		!commandMatches(arguments, "callback", "update-url") {
 		return nil, nil
 	}
 	callbackCipher, err := external.DecodeCallbackKeyRing(
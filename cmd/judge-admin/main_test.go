@@ -6,14 +6,18 @@ import (
 	"testing"
 )
 
-func TestCallbackProvisionerOptionsRequireKeysOnlyForCallbackCreate(t *testing.T) {
+func TestCallbackProvisionerOptionsRequireKeysOnlyForCallbackSecretCommands(t *testing.T) {
 	getenv := func(string) string { return "" }
-	options, err := callbackProvisionerOptions([]string{"tenant", "create"}, getenv, bytes.NewReader(make([]byte, 32)))
-	if err != nil || len(options) != 0 {
-		t.Fatalf("tenant options=%d error=%v", len(options), err)
+	for _, arguments := range [][]string{{"tenant", "create"}, {"callback", "list"}, {"callback", "disable"}} {
+		options, err := callbackProvisionerOptions(arguments, getenv, bytes.NewReader(make([]byte, 32)))
+		if err != nil || len(options) != 0 {
+			t.Fatalf("%v options=%d error=%v", arguments, len(options), err)
+		}
 	}
-	if _, err := callbackProvisionerOptions([]string{"callback", "create"}, getenv, bytes.NewReader(make([]byte, 32))); err == nil {
-		t.Fatal("callback create accepted missing key configuration")
+	for _, action := range []string{"create", "rotate-secret", "update-url"} {
+		if _, err := callbackProvisionerOptions([]string{"callback", action}, getenv, bytes.NewReader(make([]byte, 32))); err == nil {
+			t.Fatalf("callback %s accepted missing key configuration", action)
+		}
 	}
 }
 
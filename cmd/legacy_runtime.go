@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/database"
@@ -59,7 +59,7 @@ func (runtime *legacyRuntime) Run(ctx context.Context) error {
 			var err error
 			next, err = runtime.newConsumer()
 			if err != nil {
-				log.Printf("Legacy RocketMQ consumer initialization is not ready; retrying: %v", err)
+				slog.WarnContext(ctx, "Legacy RocketMQ consumer initialization is not ready; retrying", "error", err)
 				if err := waitForLegacyConsumerRetry(ctx, retryDelay); err != nil {
 					return err
 				}
@@ -67,9 +67,9 @@ func (runtime *legacyRuntime) Run(ctx context.Context) error {
 			}
 		}
 		if err := next.Start(); err != nil {
-			log.Printf("Legacy RocketMQ topic route is not ready; retrying with a fresh consumer: %v", err)
+			slog.WarnContext(ctx, "Legacy RocketMQ topic route is not ready; retrying with a fresh consumer", "error", err)
 			if shutdownErr := next.Shutdown(); shutdownErr != nil {
-				log.Printf("Failed to discard the unready legacy RocketMQ consumer: %v", shutdownErr)
+				slog.ErrorContext(ctx, "Failed to discard the unready legacy RocketMQ consumer", "error", shutdownErr)
 			}
 			next = nil
 			if err := waitForLegacyConsumerRetry(ctx, retryDelay); err != nil {
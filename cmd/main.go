@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,28 +17,38 @@ import (
 	"github.com/CodeRushOJ/croj-judging-server/internal/consumer"
 	"github.com/CodeRushOJ/croj-judging-server/internal/database"
 	"github.com/CodeRushOJ/croj-judging-server/internal/discovery"
+	"github.com/CodeRushOJ/croj-judging-server/internal/logging"
 	"github.com/CodeRushOJ/croj-judging-server/internal/sandbox"
 	"github.com/CodeRushOJ/croj-judging-server/internal/scheduler"
 	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/config"
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// tracingShutdownTimeout bounds the final span flush so an unreachable
+// collector cannot hold the process past its termination grace period.
+const tracingShutdownTimeout = 5 * time.Second
+
 func main() {
-	fmt.Println("Starting Judging Server...")
+	slog.Info("Starting Judging Server")
 
 	// 加载配置
-	cfg, err := config.LoadConfig("configs/config.yaml")
+	const configPath = "configs/config.yaml"
+	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", "error", err)
+	}
+	if err := logging.Setup(logging.Config{Level: cfg.Logging.Level, Format: cfg.Logging.Format}); err != nil {
+		fatal("Invalid logging configuration", "error", err)
 	}
-	fmt.Println("Config loaded.")
+	slog.Info("Config loaded", "path", configPath)
 	if !cfg.LegacyJudge.Enabled && !cfg.ExternalAPI.Enabled {
-		log.Fatal("at least one of legacy Judge or external REST must be enabled")
+		fatal("at least one of legacy Judge or external REST must be enabled")
 	}
 	refreshInterval, err := time.ParseDuration(cfg.SandboxDiscovery.RefreshInterval)
 	if err != nil {
-		log.Fatalf("Invalid sandbox discovery refresh interval: %v", err)
+		fatal("Invalid sandbox discovery refresh interval", "error", err)
 	}
 	var sandboxSelector service.SandboxSelector
 	var legacyScheduler *scheduler.Scheduler
@@ -46,16 +56,16 @@ func main() {
 	if cfg.SandboxDiscovery.Target != "" {
 		targetSelector, err := scheduler.NewTarget(cfg.SandboxDiscovery.Target)
 		if err != nil {
-			log.Fatalf("Invalid sandbox gRPC target: %v", err)
+			fatal("Invalid sandbox gRPC target", "error", err)
 		}
 		sandboxSelector = targetSelector
 		sandboxReadinessProbe = sandboxDNSProbe(cfg.SandboxDiscovery.Target)
-		fmt.Println("gRPC DNS round_robin sandbox target initialized.")
+		slog.Info("gRPC DNS round_robin sandbox target initialized")
 	} else {
 		if !cfg.SandboxDiscovery.AllowLegacyEndpointSlice {
-			log.Fatal("SANDBOX_GRPC_TARGET is required; set SANDBOX_ALLOW_LEGACY_ENDPOINT_SLICE=true only for the deprecated fallback")
+			fatal("SANDBOX_GRPC_TARGET is required; set SANDBOX_ALLOW_LEGACY_ENDPOINT_SLICE=true only for the deprecated fallback")
 		}
-		log.Printf("DEPRECATED: direct EndpointSlice scheduling is enabled explicitly; configure SANDBOX_GRPC_TARGET for a headless Service")
+		slog.Warn("DEPRECATED: direct EndpointSlice scheduling is enabled explicitly; configure SANDBOX_GRPC_TARGET for a headless Service")
 		discoveryClient, err := discovery.NewKubernetesDiscovery(
 			cfg.SandboxDiscovery.Namespace,
 			cfg.SandboxDiscovery.Service,
@@ -63,7 +73,7 @@ func main() {
 			cfg.SandboxDiscovery.Kubeconfig,
 		)
 		if err != nil {
-			log.Fatalf("Failed to initialize legacy Kubernetes sandbox discovery: %v", err)
+			fatal("Failed to initialize legacy Kubernetes sandbox discovery", "error", err)
 		}
 		legacyScheduler = scheduler.New(discoveryClient)
 		sandboxSelector = legacyScheduler
@@ -75,11 +85,11 @@ func main() {
 
 	executeTimeout, err := time.ParseDuration(cfg.SandboxDiscovery.ExecuteTimeout)
 	if err != nil || executeTimeout <= 0 {
-		log.Fatalf("Invalid sandbox execute timeout: %q", cfg.SandboxDiscovery.ExecuteTimeout)
+		fatal("Invalid sandbox execute timeout", "value", cfg.SandboxDiscovery.ExecuteTimeout)
 	}
 	connectionIdleTTL, err := time.ParseDuration(cfg.SandboxDiscovery.ConnectionIdleTTL)
 	if err != nil || connectionIdleTTL <= 0 {
-		log.Fatalf("Invalid sandbox connection idle TTL: %q", cfg.SandboxDiscovery.ConnectionIdleTTL)
+		fatal("Invalid sandbox connection idle TTL", "value", cfg.SandboxDiscovery.ConnectionIdleTTL)
 	}
 	sandboxClient := sandbox.NewClientWithCache(
 		executeTimeout,
@@ -88,12 +98,12 @@ func main() {
 	)
 	defer func() {
 		if err := sandboxClient.Close(); err != nil {
-			log.Printf("Failed to close sandbox client: %v", err)
+			slog.Error("Failed to close sandbox client", "error", err)
 		}
 	}()
 	bundleCacheTTL, err := time.ParseDuration(cfg.TestBundles.CacheTTL)
 	if err != nil || bundleCacheTTL <= 0 {
-		log.Fatalf("Invalid test bundle cache TTL: %q", cfg.TestBundles.CacheTTL)
+		fatal("Invalid test bundle cache TTL", "value", cfg.TestBundles.CacheTTL)
 	}
 	objectStore, err := bundle.NewMinIOStore(bundle.MinIOConfig{
 		Endpoint:  cfg.TestBundles.Endpoint,
@@ -104,7 +114,7 @@ func main() {
 		SecretKey: cfg.TestBundles.SecretKey,
 	})
 	if err != nil {
-		log.Fatalf("Failed to initialize test bundle object storage: %v", err)
+		fatal("Failed to initialize test bundle object storage", "error", err)
 	}
 	bundleCache, err := bundle.NewCache(
 		cfg.TestBundles.CacheDir,
@@ -114,7 +124,7 @@ func main() {
 		objectStore,
 	)
 	if err != nil {
-		log.Fatalf("Failed to initialize test bundle cache: %v", err)
+		fatal("Failed to initialize test bundle cache", "error", err)
 	}
 	archiveLimits := bundle.ArchiveLimits{
 		MaxFiles:            cfg.TestBundles.MaxFiles,
@@ -124,7 +134,7 @@ func main() {
 		MaxCompressionRatio: cfg.TestBundles.MaxCompressionRatio,
 	}
 	if err := bundle.ValidateArchiveLimits(archiveLimits); err != nil {
-		log.Fatalf("Invalid test bundle archive limits: %v", err)
+		fatal("Invalid test bundle archive limits", "error", err)
 	}
 	bundleProvider := bundle.NewProvider(bundleCache, archiveLimits)
 	bundlePipeline := service.NewBatchBundlePipeline(
@@ -135,11 +145,11 @@ func main() {
 	var judgeDatabase *sql.DB
 	if cfg.ExternalAPI.Enabled {
 		if cfg.ExternalAPI.JudgeDatabaseDSN == "" {
-			log.Fatal("JUDGE_DATABASE_DSN is required when external REST is enabled")
+			fatal("JUDGE_DATABASE_DSN is required when external REST is enabled")
 		}
 		judgeDatabase, err = sql.Open("mysql", cfg.ExternalAPI.JudgeDatabaseDSN)
 		if err != nil {
-			log.Fatalf("Failed to open external Judge database: %v", err)
+			fatal("Failed to open external Judge database", "error", err)
 		}
 		judgeDatabase.SetMaxOpenConns(32)
 		judgeDatabase.SetMaxIdleConns(16)
@@ -150,11 +160,11 @@ func main() {
 		if judgeDatabase != nil {
 			_ = judgeDatabase.Close()
 		}
-		log.Fatalf("Failed to initialize external runtime: %v", err)
+		fatal("Failed to initialize external runtime", "error", err)
 	}
 	defer func() {
 		if err := external.Close(); err != nil {
-			log.Printf("Failed to close external runtime: %v", err)
+			slog.Error("Failed to close external runtime", "error", err)
 		}
 	}()
 	legacy, err := initializeLegacyRuntime(cfg.LegacyJudge.Enabled, func() (*legacyRuntime, error) {
@@ -199,15 +209,15 @@ func main() {
 		return runtime, nil
 	})
 	if err != nil {
-		log.Fatalf("Failed to initialize legacy judge adapter: %v", err)
+		fatal("Failed to initialize legacy judge adapter", "error", err)
 	}
 	defer func() {
 		if err := legacy.Close(); err != nil {
-			log.Printf("Failed to close legacy backend database: %v", err)
+			slog.Error("Failed to close legacy backend database", "error", err)
 		}
 	}()
 	if cfg.LegacyJudge.Enabled {
-		fmt.Println("Legacy backend database and RocketMQ judge adapter initialized.")
+		slog.Info("Legacy backend database and RocketMQ judge adapter initialized")
 	}
 
 	// 使用 context 来管理 consumer 的生命周期
@@ -216,9 +226,34 @@ func main() {
 	if legacyScheduler != nil {
 		go legacyScheduler.Run(ctx, refreshInterval)
 	}
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Endpoint: cfg.Tracing.OTLPEndpoint, Insecure: cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName, SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to initialize tracing", "error", err)
+	}
+	defer func() {
+		flushContext, cancelFlush := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancelFlush()
+		if err := shutdownTracing(flushContext); err != nil {
+			slog.Error("Failed to flush trace exporter", "error", err)
+		}
+	}()
+	if cfg.Tracing.OTLPEndpoint != "" {
+		slog.Info("Exporting OpenTelemetry spans", "endpoint", cfg.Tracing.OTLPEndpoint)
+	}
+	var metricsDone <-chan error
+	if cfg.Metrics.ListenAddress != "" {
+		metricsDone, err = startMetricsServer(ctx, cfg.Metrics.ListenAddress)
+		if err != nil {
+			fatal("Failed to start metrics listener", "error", err)
+		}
+		slog.Info("Serving Prometheus metrics", "address", cfg.Metrics.ListenAddress, "path", "/metrics")
+	}
 	var externalDone <-chan error
 	if cfg.ExternalAPI.Enabled {
-		fmt.Printf("Starting external REST API on %s...\n", cfg.ExternalAPI.ListenAddress)
+		slog.Info("Starting external REST API", "address", cfg.ExternalAPI.ListenAddress)
 		externalDone = startExternalRuntime(ctx, external.runtime, cancel)
 	}
 
@@ -227,7 +262,7 @@ func main() {
 		done := make(chan error, 1)
 		legacyDone = done
 		go func() {
-			fmt.Println("Starting supervised legacy RocketMQ consumer...")
+			slog.Info("Starting supervised legacy RocketMQ consumer")
 			done <- legacy.Run(ctx)
 		}()
 	}
@@ -238,29 +273,42 @@ func main() {
 
 	select {
 	case <-quit:
-		fmt.Println("Received shutdown signal.")
+		slog.Info("Received shutdown signal")
 		cancel() // 收到信号，取消 context
 	case <-ctx.Done():
-		fmt.Println("Consumer context cancelled.")
+		slog.Info("Consumer context cancelled")
 		// Consumer 出错导致退出
 	}
 
-	fmt.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 	if externalDone != nil {
 		if err := <-externalDone; err != nil && !errors.Is(err, context.Canceled) {
-			log.Printf("External runtime error: %v", err)
+			slog.Error("External runtime error", "error", err)
 		}
-		fmt.Println("External durable workers and REST API stopped.")
+		slog.Info("External durable workers and REST API stopped")
 	}
 
 	if legacyDone != nil {
 		if err := <-legacyDone; err != nil && !errors.Is(err, context.Canceled) {
-			log.Printf("Legacy RocketMQ consumer shutdown error: %v", err)
+			slog.Error("Legacy RocketMQ consumer shutdown error", "error", err)
+		}
+		slog.Info("Legacy RocketMQ consumer stopped")
+	}
+
+	if metricsDone != nil {
+		if err := <-metricsDone; err != nil {
+			slog.Error("Metrics listener error", "error", err)
 		}
-		fmt.Println("Legacy RocketMQ consumer stopped.")
 	}
 
-	fmt.Println("Server gracefully stopped.")
+	slog.Info("Server gracefully stopped")
+}
+
+// fatal records one structured error and exits without running deferred
+// cleanup, matching the startup failure semantics of log.Fatal.
+func fatal(message string, args ...any) {
+	slog.Error(message, args...)
+	os.Exit(1)
 }
 
 // initializeLegacyRuntime is the process boundary for every Backend DB,
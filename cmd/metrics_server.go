@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
+)
+
+const metricsShutdownTimeout = 5 * time.Second
+
+// startMetricsServer listens before returning so a taken port fails startup
+// instead of silently leaving the process unscraped. The listener is separate
+// from the external API so /metrics is never reachable with tenant traffic.
+func startMetricsServer(ctx context.Context, address string) (<-chan error, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen for metrics on %s: %w", address, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	server := &http.Server{
+		Handler: mux, ReadHeaderTimeout: 5 * time.Second, ReadTimeout: 10 * time.Second,
+		WriteTimeout: 30 * time.Second, IdleTimeout: time.Minute,
+	}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownContext, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownContext)
+	}()
+	go func() {
+		err := server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		done <- err
+	}()
+	return done, nil
+}
+
+func queueDepthSource(repository *external.MySQLJobRepository) func(context.Context) ([]metrics.TenantQueueDepth, error) {
+	return func(ctx context.Context) ([]metrics.TenantQueueDepth, error) {
+		depths, err := repository.QueueDepth(ctx)
+		if err != nil {
+			return nil, err
+		}
+		converted := make([]metrics.TenantQueueDepth, 0, len(depths))
+		for _, depth := range depths {
+			converted = append(converted, metrics.TenantQueueDepth{
+				Tenant: depth.TenantExternalID, Status: string(depth.Status), Jobs: depth.Jobs,
+			})
+		}
+		return converted, nil
+	}
+}
@@ -0,0 +1,105 @@
+package admincli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+func listAPIKeys(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("api-key list", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse API key list flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("api-key list does not accept positional arguments")
+	}
+	if *tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	keys, err := provisioner.ListAPIKeys(ctx, *tenantID)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(writer, "PREFIX\tSTATUS\tCREATED\tEXPIRES\tLAST USED\tSCOPES"); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		scopes := make([]string, 0, len(key.Scopes))
+		for _, scope := range key.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		if _, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.LookupPrefix, key.Status, key.CreatedAt.Format(time.RFC3339), optionalTime(key.ExpiresAt), optionalTime(key.LastUsedAt),
+			strings.Join(scopes, ",")); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func revokeAPIKey(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("api-key revoke", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	prefix := flags.String("prefix", "", "API key lookup prefix")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse API key revoke flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("api-key revoke does not accept positional arguments")
+	}
+	if *tenantID == "" || *prefix == "" {
+		return fmt.Errorf("tenant ID and API key prefix are required")
+	}
+	if err := provisioner.RevokeAPIKey(ctx, *tenantID, *prefix); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(output, "API key revoked: %s\n", *prefix)
+	return err
+}
+
+// rotateAPIKey issues a replacement key and keeps the old one working for
+// --overlap; --overlap 0 revokes the old key immediately.
+func rotateAPIKey(ctx context.Context, arguments []string, provisioner Provisioner, pepper []byte, output io.Writer) error {
+	flags := flag.NewFlagSet("api-key rotate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	prefix := flags.String("prefix", "", "lookup prefix of the key to replace")
+	overlap := flags.Duration("overlap", 24*time.Hour, "how long the old key keeps working")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse API key rotate flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("api-key rotate does not accept positional arguments")
+	}
+	if *tenantID == "" || *prefix == "" {
+		return fmt.Errorf("tenant ID and API key prefix are required")
+	}
+	if *overlap < 0 || *overlap > external.MaximumAPIKeyRotationOverlap {
+		return fmt.Errorf("API key rotation overlap must be between 0 and %s", external.MaximumAPIKeyRotationOverlap)
+	}
+	rotation, err := provisioner.RotateAPIKey(ctx, *tenantID, *prefix, *overlap, pepper)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(output, "API key rotated: %s -> %s\nPrevious key valid until: %s\nAPI key (shown once): %s\n",
+		rotation.PreviousPrefix, rotation.Replacement.LookupPrefix, rotation.PreviousValidUntil.Format(time.RFC3339), rotation.Replacement.Plaintext)
+	return err
+}
+
+func optionalTime(value *time.Time) string {
+	if value == nil {
+		return "-"
+	}
+	return value.Format(time.RFC3339)
+}
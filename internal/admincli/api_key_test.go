@@ -0,0 +1,77 @@
+package admincli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+func TestRunListsAPIKeysWithUsageAndExpiry(t *testing.T) {
+	created := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	lastUsed := time.Date(2026, 10, 17, 7, 59, 0, 0, time.UTC)
+	stub := &provisionerStub{apiKeys: []external.APIKeySummary{
+		{LookupPrefix: "aaaaaaaaaaaaaaa", Status: external.APIKeyActive, Scopes: []external.Scope{external.ScopeJobSubmit, external.ScopeJobRead},
+			CreatedAt: created, ExpiresAt: &expires, LastUsedAt: &lastUsed},
+		{LookupPrefix: "bbbbbbbbbbbbbbb", Status: external.APIKeyRevoked, Scopes: []external.Scope{external.ScopeBundleRead},
+			CreatedAt: created, RevokedAt: &lastUsed},
+	}}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{"api-key", "list", "--tenant", testTenant.TenantID}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	want := "PREFIX           STATUS   CREATED               EXPIRES               LAST USED             SCOPES\n" +
+		"aaaaaaaaaaaaaaa  ACTIVE   2026-09-01T08:00:00Z  2026-10-18T08:00:00Z  2026-10-17T07:59:00Z  job:submit,job:read\n" +
+		"bbbbbbbbbbbbbbb  REVOKED  2026-09-01T08:00:00Z  -                     -                     bundle:read\n"
+	if stub.tenantID != testTenant.TenantID || output.String() != want {
+		t.Fatalf("tenant=%q output=%q", stub.tenantID, output.String())
+	}
+}
+
+func TestRunRevokesAndRotatesAPIKeys(t *testing.T) {
+	stub := &provisionerStub{}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{"api-key", "revoke", "--tenant", testTenant.TenantID, "--prefix", "aaaaaaaaaaaaaaa"}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if stub.keyPrefix != "aaaaaaaaaaaaaaa" || output.String() != "API key revoked: aaaaaaaaaaaaaaa\n" {
+		t.Fatalf("prefix=%q output=%q", stub.keyPrefix, output.String())
+	}
+
+	plaintext := "croj_ccccccccccccccc_0123456789012345678901234567890123456789012"
+	stub = &provisionerStub{rotation: external.APIKeyRotation{
+		PreviousPrefix: "aaaaaaaaaaaaaaa", PreviousValidUntil: time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC),
+		Replacement: external.APIKeyMaterial{Plaintext: plaintext, LookupPrefix: "ccccccccccccccc"},
+	}}
+	pepper := bytes.Repeat([]byte{0x55}, 32)
+	output.Reset()
+	if err := Run(context.Background(), []string{
+		"api-key", "rotate", "--tenant", testTenant.TenantID, "--prefix", "aaaaaaaaaaaaaaa", "--overlap", "12h",
+	}, stub, pepper, &output); err != nil {
+		t.Fatal(err)
+	}
+	if stub.keyOverlap != 12*time.Hour || !bytes.Equal(stub.pepper, pepper) {
+		t.Fatalf("overlap=%s pepper=%x", stub.keyOverlap, stub.pepper)
+	}
+	want := "API key rotated: aaaaaaaaaaaaaaa -> ccccccccccccccc\nPrevious key valid until: 2026-10-17T20:00:00Z\nAPI key (shown once): " + plaintext + "\n"
+	if output.String() != want || strings.Count(output.String(), plaintext) != 1 {
+		t.Fatalf("rotate output = %q", output.String())
+	}
+
+	for _, arguments := range [][]string{
+		{"api-key", "list"},
+		{"api-key", "revoke", "--tenant", testTenant.TenantID},
+		{"api-key", "rotate", "--prefix", "aaaaaaaaaaaaaaa"},
+		{"api-key", "rotate", "--tenant", testTenant.TenantID, "--prefix", "aaaaaaaaaaaaaaa", "--overlap", "-1h"},
+		{"api-key", "rotate", "--tenant", testTenant.TenantID, "--prefix", "aaaaaaaaaaaaaaa", "--overlap", "200h"},
+	} {
+		stub := &provisionerStub{}
+		if err := Run(context.Background(), arguments, stub, pepper, &bytes.Buffer{}); err == nil || stub.keyCalls != 0 {
+			t.Fatalf("%v: error=%v calls=%d", arguments, err, stub.keyCalls)
+		}
+	}
+}
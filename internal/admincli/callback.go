@@ -0,0 +1,126 @@
+package admincli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+func listCallbacks(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("callback list", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse callback list flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("callback list does not accept positional arguments")
+	}
+	if *tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	callbacks, err := provisioner.ListCallbacks(ctx, *tenantID)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(writer, "CALLBACK\tCREATED\tPREVIOUS SECRET UNTIL\tEVENTS\tURL"); err != nil {
+		return err
+	}
+	for _, callback := range callbacks {
+		events := "-"
+		if len(callback.EventTypes) > 0 {
+			events = strings.Join(callback.EventTypes, ",")
+		}
+		if _, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			callback.CallbackID, callback.CreatedAt.Format(time.RFC3339), optionalTime(callback.PreviousSecretExpiresAt), events, callback.URL); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func disableCallback(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("callback disable", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	callbackID := flags.String("callback", "", "callback external ID")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse callback disable flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("callback disable does not accept positional arguments")
+	}
+	if *tenantID == "" || *callbackID == "" {
+		return fmt.Errorf("callback tenant and callback ID are required")
+	}
+	if err := provisioner.DisableCallback(ctx, *tenantID, *callbackID); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(output, "Callback disabled: %s\n", *callbackID)
+	return err
+}
+
+// updateCallbackURL moves a callback to a new destination; the receiver
+// keeps its signing secret.
+func updateCallbackURL(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("callback update-url", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	callbackID := flags.String("callback", "", "callback external ID")
+	destinationURL := flags.String("url", "", "absolute HTTPS callback URL")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse callback update-url flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("callback update-url does not accept positional arguments")
+	}
+	if *tenantID == "" || *callbackID == "" || *destinationURL == "" {
+		return fmt.Errorf("callback tenant, callback ID and URL are required")
+	}
+	destination, err := provisioner.UpdateCallbackURL(ctx, *tenantID, *callbackID, *destinationURL)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(output, "Callback URL updated: %s\nURL: %s\n", *callbackID, destination)
+	return err
+}
+
+// rotateCallbackSecret issues a new signing secret; deliveries carry a
+// signature for the old secret too until --grace ends. --grace 0 stops the
+// old secret immediately.
+func rotateCallbackSecret(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("callback rotate-secret", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	callbackID := flags.String("callback", "", "callback external ID")
+	grace := flags.Duration("grace", 24*time.Hour, "how long deliveries are also signed with the old secret")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse callback rotate-secret flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("callback rotate-secret does not accept positional arguments")
+	}
+	if *tenantID == "" || *callbackID == "" {
+		return fmt.Errorf("callback tenant and callback ID are required")
+	}
+	if *grace < 0 || *grace > external.MaximumCallbackSecretGrace {
+		return fmt.Errorf("callback secret grace must be between 0 and %s", external.MaximumCallbackSecretGrace)
+	}
+	material, err := provisioner.RotateCallbackSecretWithGrace(ctx, *tenantID, *callbackID, *grace)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output, "Callback secret rotated: %s\nPrevious secret signs until: %s\n",
+		material.CallbackID, optionalTime(material.PreviousSecretValidUntil)); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(output, "Callback secret (shown once): %s\n", material.Secret)
+	return err
+}
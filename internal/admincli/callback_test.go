@@ -0,0 +1,84 @@
+package admincli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+func TestRunListsCallbacksWithRotationGrace(t *testing.T) {
+	created := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	graceEnd := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	stub := &provisionerStub{callbacks: []external.CallbackSummary{
+		{CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", URL: "https://oj.example.com:443/hooks", CreatedAt: created, PreviousSecretExpiresAt: &graceEnd,
+			EventTypes: []string{external.WebhookEventJobStarted}},
+		{CallbackID: "cdcdcdcdcdcdcdcdcdcdcdcdcd", URL: "https://other.example.com:443/hooks", CreatedAt: created, EventTypes: []string{}},
+	}}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{"callback", "list", "--tenant", testTenant.TenantID}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	want := "CALLBACK                    CREATED               PREVIOUS SECRET UNTIL  EVENTS             URL\n" +
+		"cbcbcbcbcbcbcbcbcbcbcbcbcb  2026-09-01T08:00:00Z  2026-10-18T08:00:00Z   judge.job.started  https://oj.example.com:443/hooks\n" +
+		"cdcdcdcdcdcdcdcdcdcdcdcdcd  2026-09-01T08:00:00Z  -                      -                  https://other.example.com:443/hooks\n"
+	if stub.tenantID != testTenant.TenantID || output.String() != want {
+		t.Fatalf("tenant=%q output=%q", stub.tenantID, output.String())
+	}
+}
+
+func TestRunDisablesMovesAndRotatesCallbacks(t *testing.T) {
+	stub := &provisionerStub{}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{
+		"callback", "disable", "--tenant", testTenant.TenantID, "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb",
+	}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if stub.callbackID != "cbcbcbcbcbcbcbcbcbcbcbcbcb" || output.String() != "Callback disabled: cbcbcbcbcbcbcbcbcbcbcbcbcb\n" {
+		t.Fatalf("callback=%q output=%q", stub.callbackID, output.String())
+	}
+
+	stub = &provisionerStub{callback: external.CallbackMaterial{Destination: "https://new.example.com:443/hooks"}}
+	output.Reset()
+	if err := Run(context.Background(), []string{
+		"callback", "update-url", "--tenant", testTenant.TenantID, "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb", "--url", "https://new.example.com/hooks",
+	}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if stub.callbackURL != "https://new.example.com/hooks" ||
+		output.String() != "Callback URL updated: cbcbcbcbcbcbcbcbcbcbcbcbcb\nURL: https://new.example.com:443/hooks\n" {
+		t.Fatalf("url=%q output=%q", stub.callbackURL, output.String())
+	}
+
+	secret := "croj_whsec_" + strings.Repeat("s", 43)
+	graceEnd := time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)
+	stub = &provisionerStub{callback: external.CallbackMaterial{CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", Secret: secret, PreviousSecretValidUntil: &graceEnd}}
+	output.Reset()
+	if err := Run(context.Background(), []string{
+		"callback", "rotate-secret", "--tenant", testTenant.TenantID, "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb", "--grace", "12h",
+	}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	want := "Callback secret rotated: cbcbcbcbcbcbcbcbcbcbcbcbcb\nPrevious secret signs until: 2026-10-17T20:00:00Z\nCallback secret (shown once): " + secret + "\n"
+	if stub.callbackGrace != 12*time.Hour || output.String() != want || strings.Count(output.String(), secret) != 1 {
+		t.Fatalf("grace=%s output=%q", stub.callbackGrace, output.String())
+	}
+
+	for _, arguments := range [][]string{
+		{"callback", "list"},
+		{"callback", "disable", "--tenant", testTenant.TenantID},
+		{"callback", "update-url", "--tenant", testTenant.TenantID, "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb"},
+		{"callback", "rotate-secret", "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb"},
+		{"callback", "rotate-secret", "--tenant", testTenant.TenantID, "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb", "--grace", "-1h"},
+		{"callback", "rotate-secret", "--tenant", testTenant.TenantID, "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb", "--grace", "200h"},
+	} {
+		stub := &provisionerStub{}
+		if err := Run(context.Background(), arguments, stub, nil, &bytes.Buffer{}); err == nil || stub.callbackCalls != 0 {
+			t.Fatalf("%v: error=%v calls=%d", arguments, err, stub.callbackCalls)
+		}
+	}
+}
@@ -14,7 +14,21 @@ import (
 type Provisioner interface {
 	CreateTenant(context.Context, string, external.TenantPolicy) (string, error)
 	CreateAPIKey(context.Context, string, []external.Scope, *time.Time, []byte) (external.APIKeyMaterial, error)
-	CreateCallback(context.Context, string, string) (external.CallbackMaterial, error)
+	ListAPIKeys(context.Context, string) ([]external.APIKeySummary, error)
+	RevokeAPIKey(context.Context, string, string) error
+	RotateAPIKey(context.Context, string, string, time.Duration, []byte) (external.APIKeyRotation, error)
+	CreateCallback(context.Context, string, string, []string) (external.CallbackMaterial, error)
+	ListCallbacks(context.Context, string) ([]external.CallbackSummary, error)
+	DisableCallback(context.Context, string, string) error
+	UpdateCallbackURL(context.Context, string, string, string) (string, error)
+	RotateCallbackSecretWithGrace(context.Context, string, string, time.Duration) (external.CallbackMaterial, error)
+	PingCallback(context.Context, string, string, time.Duration) (external.WebhookPing, error)
+	ExportUsage(context.Context, external.UsageExportQuery) ([]external.UsageExportRow, error)
+	ListTenants(context.Context) ([]external.TenantSummary, error)
+	GetTenant(context.Context, string) (external.TenantDetails, error)
+	UpdateTenant(context.Context, string, external.TenantUpdate) (external.TenantSummary, error)
+	DisableTenant(context.Context, string, bool) (int64, error)
+	EnableTenant(context.Context, string) error
 }
 
 func Run(ctx context.Context, arguments []string, provisioner Provisioner, pepper []byte, output io.Writer) error {
@@ -22,15 +36,41 @@ func Run(ctx context.Context, arguments []string, provisioner Provisioner, peppe
 		return fmt.Errorf("provisioner and output are required")
 	}
 	if len(arguments) < 2 {
-		return fmt.Errorf("usage: judge-admin <tenant|api-key|callback> create [flags]")
+		return fmt.Errorf("usage: judge-admin <tenant|api-key|callback> create [flags] | judge-admin tenant <list|show|update|disable|enable> [flags] | judge-admin api-key <list|revoke|rotate> [flags] | judge-admin callback <list|disable|update-url|rotate-secret|ping> [flags] | judge-admin usage export [flags]")
 	}
 	switch arguments[0] + " " + arguments[1] {
 	case "tenant create":
 		return createTenant(ctx, arguments[2:], provisioner, output)
+	case "tenant list":
+		return listTenants(ctx, arguments[2:], provisioner, output)
+	case "tenant show":
+		return showTenant(ctx, arguments[2:], provisioner, output)
+	case "tenant update":
+		return updateTenant(ctx, arguments[2:], provisioner, output)
+	case "tenant disable", "tenant enable":
+		return setTenantStatus(ctx, arguments[1], arguments[2:], provisioner, output)
 	case "api-key create":
 		return createAPIKey(ctx, arguments[2:], provisioner, pepper, output)
+	case "api-key list":
+		return listAPIKeys(ctx, arguments[2:], provisioner, output)
+	case "api-key revoke":
+		return revokeAPIKey(ctx, arguments[2:], provisioner, output)
+	case "api-key rotate":
+		return rotateAPIKey(ctx, arguments[2:], provisioner, pepper, output)
 	case "callback create":
 		return createCallback(ctx, arguments[2:], provisioner, output)
+	case "callback list":
+		return listCallbacks(ctx, arguments[2:], provisioner, output)
+	case "callback disable":
+		return disableCallback(ctx, arguments[2:], provisioner, output)
+	case "callback update-url":
+		return updateCallbackURL(ctx, arguments[2:], provisioner, output)
+	case "callback rotate-secret":
+		return rotateCallbackSecret(ctx, arguments[2:], provisioner, output)
+	case "callback ping":
+		return pingCallback(ctx, arguments[2:], provisioner, output)
+	case "usage export":
+		return exportUsage(ctx, arguments[2:], provisioner, output)
 	default:
 		return fmt.Errorf("unsupported command %q", strings.Join(arguments[:2], " "))
 	}
@@ -41,6 +81,7 @@ func createCallback(ctx context.Context, arguments []string, provisioner Provisi
 	flags.SetOutput(io.Discard)
 	tenantID := flags.String("tenant", "", "tenant external ID")
 	destinationURL := flags.String("url", "", "absolute HTTPS callback URL")
+	events := flags.String("events", "", "comma-separated optional event types (judge.job.started,judge.job.progress)")
 	if err := flags.Parse(arguments); err != nil {
 		return fmt.Errorf("parse callback flags: %w", err)
 	}
@@ -50,30 +91,118 @@ func createCallback(ctx context.Context, arguments []string, provisioner Provisi
 	if *tenantID == "" || *destinationURL == "" {
 		return fmt.Errorf("callback tenant and URL are required")
 	}
-	material, err := provisioner.CreateCallback(ctx, *tenantID, *destinationURL)
+	var eventTypes []string
+	if *events != "" {
+		eventTypes = strings.Split(*events, ",")
+	}
+	material, err := provisioner.CreateCallback(ctx, *tenantID, *destinationURL, eventTypes)
 	if err != nil {
 		return err
 	}
 	if _, err := fmt.Fprintf(output, "Callback created: %s\n", material.CallbackID); err != nil {
 		return err
 	}
+	if len(material.EventTypes) > 0 {
+		if _, err := fmt.Fprintf(output, "Optional events: %s\n", strings.Join(material.EventTypes, ",")); err != nil {
+			return err
+		}
+	}
 	_, err = fmt.Fprintf(output, "Callback secret (shown once): %s\n", material.Secret)
 	return err
 }
 
+// pingCallback queues a judge.ping event for the callback and waits for a
+// running webhook worker to attempt it once.
+func pingCallback(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("callback ping", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	callbackID := flags.String("callback", "", "callback external ID")
+	wait := flags.Duration("wait", 30*time.Second, "how long to wait for the delivery outcome")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse callback ping flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("callback ping does not accept positional arguments")
+	}
+	if *tenantID == "" || *callbackID == "" {
+		return fmt.Errorf("callback tenant and callback ID are required")
+	}
+	if *wait < 0 || *wait > external.MaximumWebhookPingWait {
+		return fmt.Errorf("callback ping wait must be between 0 and %s", external.MaximumWebhookPingWait)
+	}
+	ping, err := provisioner.PingCallback(ctx, *tenantID, *callbackID, *wait)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output, "Ping event: %s\nStatus: %s\n", ping.EventID, ping.Status); err != nil {
+		return err
+	}
+	if !ping.Settled() {
+		_, err = fmt.Fprintln(output, "The webhook worker has not settled the ping yet; check the delivery log for this event.")
+		return err
+	}
+	if ping.HTTPStatus != nil {
+		if _, err := fmt.Fprintf(output, "HTTP status: %d\n", *ping.HTTPStatus); err != nil {
+			return err
+		}
+	}
+	if ping.LatencyMillis != nil {
+		if _, err := fmt.Fprintf(output, "Latency: %d ms\n", *ping.LatencyMillis); err != nil {
+			return err
+		}
+	}
+	if ping.SignatureAccepted != nil {
+		accepted := "no"
+		if *ping.SignatureAccepted {
+			accepted = "yes"
+		}
+		if _, err := fmt.Fprintf(output, "Signature accepted: %s\n", accepted); err != nil {
+			return err
+		}
+	}
+	if ping.ErrorCode != "" {
+		_, err = fmt.Fprintf(output, "Error code: %s\n", ping.ErrorCode)
+	}
+	return err
+}
+
+// exportUsage writes the billable usage of the inclusive day range. Without
+// --tenant it exports every tenant.
+func exportUsage(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("usage export", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "optional tenant external ID")
+	from := flags.String("from", "", "first accounting day (YYYY-MM-DD)")
+	to := flags.String("to", "", "last accounting day (YYYY-MM-DD)")
+	encodedFormat := flags.String("format", string(external.UsageExportCSV), "csv or jsonl")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse usage export flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage export does not accept positional arguments")
+	}
+	format, err := external.ParseUsageExportFormat(*encodedFormat)
+	if err != nil {
+		return err
+	}
+	query, err := external.ParseUsageExportQuery(*tenantID, *from, *to)
+	if err != nil {
+		return err
+	}
+	rows, err := provisioner.ExportUsage(ctx, query)
+	if err != nil {
+		return err
+	}
+	return external.WriteUsageExport(output, format, rows)
+}
+
 func createTenant(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
 	flags := flag.NewFlagSet("tenant create", flag.ContinueOnError)
 	flags.SetOutput(io.Discard)
 	name := flags.String("name", "", "tenant display name")
 	policy := external.TenantPolicy{}
-	flags.IntVar(&policy.MaxQueuedJobs, "max-queued", 100, "maximum queued jobs")
-	flags.IntVar(&policy.MaxRunningJobs, "max-running", 4, "maximum running jobs")
-	flags.Int64Var(&policy.MaxSourceBytes, "max-source-bytes", 1<<20, "maximum source bytes")
-	flags.IntVar(&policy.MaxRetainedBundles, "max-bundles", 200, "maximum retained bundles")
-	flags.Int64Var(&policy.DailyExecutionMillis, "daily-execution-ms", 3_600_000, "daily execution budget")
-	flags.IntVar(&policy.MaxInfrastructureTries, "max-infra-tries", 3, "maximum infrastructure attempts")
-	flags.IntVar(&policy.MaxTimeLimitMillis, "max-time-limit-ms", 10_000, "maximum per-bundle time limit in milliseconds")
-	flags.IntVar(&policy.MaxMemoryLimitMiB, "max-memory-limit-mib", 1024, "maximum per-bundle memory limit in MiB")
+	registerTenantPolicyFlags(flags, &policy)
 	if err := flags.Parse(arguments); err != nil {
 		return fmt.Errorf("parse tenant flags: %w", err)
 	}
@@ -131,6 +260,7 @@ func parseScopes(encoded string) ([]external.Scope, error) {
 		external.ScopeJobSubmit:        {},
 		external.ScopeJobRead:          {},
 		external.ScopeJobCancel:        {},
+		external.ScopeRunExecute:       {},
 	}
 	values := strings.Split(encoded, ",")
 	scopes := make([]external.Scope, 0, len(values))
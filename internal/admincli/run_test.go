@@ -11,25 +11,131 @@ import (
 )
 
 type provisionerStub struct {
-	tenantName    string
-	tenantPolicy  external.TenantPolicy
-	tenantID      string
-	scopes        []external.Scope
-	expiresAt     *time.Time
-	pepper        []byte
-	material      external.APIKeyMaterial
-	keyCalls      int
-	callbackURL   string
-	callback      external.CallbackMaterial
-	callbackCalls int
-}
-
-func (stub *provisionerStub) CreateCallback(_ context.Context, tenantID, destinationURL string) (external.CallbackMaterial, error) {
-	stub.tenantID, stub.callbackURL = tenantID, destinationURL
+	tenantName       string
+	tenantPolicy     external.TenantPolicy
+	tenantID         string
+	scopes           []external.Scope
+	expiresAt        *time.Time
+	pepper           []byte
+	material         external.APIKeyMaterial
+	keyCalls         int
+	callbackURL      string
+	eventTypes       []string
+	callback         external.CallbackMaterial
+	callbackCalls    int
+	callbackID       string
+	pingWait         time.Duration
+	ping             external.WebhookPing
+	usageQuery       external.UsageExportQuery
+	usageRows        []external.UsageExportRow
+	usageCalls       int
+	tenants          []external.TenantSummary
+	details          external.TenantDetails
+	tenantUpdate     external.TenantUpdate
+	tenantStatus     string
+	tenantCalls      int
+	interrupted      int64
+	interruptRunning bool
+	keyPrefix        string
+	keyOverlap       time.Duration
+	apiKeys          []external.APIKeySummary
+	rotation         external.APIKeyRotation
+	callbacks        []external.CallbackSummary
+	callbackGrace    time.Duration
+}
+
+func (stub *provisionerStub) ListAPIKeys(_ context.Context, tenantID string) ([]external.APIKeySummary, error) {
+	stub.tenantID = tenantID
+	stub.keyCalls++
+	return stub.apiKeys, nil
+}
+
+func (stub *provisionerStub) RevokeAPIKey(_ context.Context, tenantID, prefix string) error {
+	stub.tenantID, stub.keyPrefix = tenantID, prefix
+	stub.keyCalls++
+	return nil
+}
+
+func (stub *provisionerStub) RotateAPIKey(_ context.Context, tenantID, prefix string, overlap time.Duration, pepper []byte) (external.APIKeyRotation, error) {
+	stub.tenantID, stub.keyPrefix, stub.keyOverlap, stub.pepper = tenantID, prefix, overlap, pepper
+	stub.keyCalls++
+	return stub.rotation, nil
+}
+
+func (stub *provisionerStub) ListTenants(context.Context) ([]external.TenantSummary, error) {
+	stub.tenantCalls++
+	return stub.tenants, nil
+}
+
+func (stub *provisionerStub) GetTenant(_ context.Context, tenantID string) (external.TenantDetails, error) {
+	stub.tenantID = tenantID
+	stub.tenantCalls++
+	return stub.details, nil
+}
+
+func (stub *provisionerStub) UpdateTenant(_ context.Context, tenantID string, update external.TenantUpdate) (external.TenantSummary, error) {
+	stub.tenantID, stub.tenantUpdate = tenantID, update
+	stub.tenantCalls++
+	return stub.details.TenantSummary, nil
+}
+
+func (stub *provisionerStub) DisableTenant(_ context.Context, tenantID string, interruptRunning bool) (int64, error) {
+	stub.tenantID, stub.tenantStatus, stub.interruptRunning = tenantID, "DISABLED", interruptRunning
+	stub.tenantCalls++
+	if !interruptRunning {
+		return 0, nil
+	}
+	return stub.interrupted, nil
+}
+
+func (stub *provisionerStub) EnableTenant(_ context.Context, tenantID string) error {
+	stub.tenantID, stub.tenantStatus = tenantID, "ACTIVE"
+	stub.tenantCalls++
+	return nil
+}
+
+func (stub *provisionerStub) ExportUsage(_ context.Context, query external.UsageExportQuery) ([]external.UsageExportRow, error) {
+	stub.usageQuery = query
+	stub.usageCalls++
+	return stub.usageRows, nil
+}
+
+func (stub *provisionerStub) CreateCallback(_ context.Context, tenantID, destinationURL string, eventTypes []string) (external.CallbackMaterial, error) {
+	stub.tenantID, stub.callbackURL, stub.eventTypes = tenantID, destinationURL, eventTypes
 	stub.callbackCalls++
 	return stub.callback, nil
 }
 
+func (stub *provisionerStub) ListCallbacks(_ context.Context, tenantID string) ([]external.CallbackSummary, error) {
+	stub.tenantID = tenantID
+	stub.callbackCalls++
+	return stub.callbacks, nil
+}
+
+func (stub *provisionerStub) DisableCallback(_ context.Context, tenantID, callbackID string) error {
+	stub.tenantID, stub.callbackID = tenantID, callbackID
+	stub.callbackCalls++
+	return nil
+}
+
+func (stub *provisionerStub) UpdateCallbackURL(_ context.Context, tenantID, callbackID, destinationURL string) (string, error) {
+	stub.tenantID, stub.callbackID, stub.callbackURL = tenantID, callbackID, destinationURL
+	stub.callbackCalls++
+	return stub.callback.Destination, nil
+}
+
+func (stub *provisionerStub) RotateCallbackSecretWithGrace(_ context.Context, tenantID, callbackID string, grace time.Duration) (external.CallbackMaterial, error) {
+	stub.tenantID, stub.callbackID, stub.callbackGrace = tenantID, callbackID, grace
+	stub.callbackCalls++
+	return stub.callback, nil
+}
+
+func (stub *provisionerStub) PingCallback(_ context.Context, tenantID, callbackID string, wait time.Duration) (external.WebhookPing, error) {
+	stub.tenantID, stub.callbackID, stub.pingWait = tenantID, callbackID, wait
+	stub.callbackCalls++
+	return stub.ping, nil
+}
+
 func (stub *provisionerStub) CreateTenant(_ context.Context, name string, policy external.TenantPolicy) (string, error) {
 	stub.tenantName, stub.tenantPolicy = name, policy
 	return "ceirceirceirceirceirceirce", nil
@@ -48,11 +154,13 @@ func TestRunCreatesATenantWithExplicitPolicy(t *testing.T) {
 		"tenant", "create", "--name", "Acme OJ",
 		"--max-queued", "80", "--max-running", "4", "--max-source-bytes", "1048576",
 		"--max-bundles", "120", "--daily-execution-ms", "3600000", "--max-infra-tries", "3",
+		"--max-priority", "5", "--max-running-runs", "6",
 	}, stub, nil, &output)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if stub.tenantName != "Acme OJ" || stub.tenantPolicy.MaxQueuedJobs != 80 || stub.tenantPolicy.MaxRunningJobs != 4 || stub.tenantPolicy.MaxInfrastructureTries != 3 {
+	if stub.tenantName != "Acme OJ" || stub.tenantPolicy.MaxQueuedJobs != 80 || stub.tenantPolicy.MaxRunningJobs != 4 || stub.tenantPolicy.MaxInfrastructureTries != 3 ||
+		stub.tenantPolicy.MaxJobPriority != 5 || stub.tenantPolicy.MaxRunningRuns != 6 {
 		t.Fatalf("tenant request = %q %+v", stub.tenantName, stub.tenantPolicy)
 	}
 	if output.String() != "Tenant created: ceirceirceirceirceirceirce\n" {
@@ -98,6 +206,27 @@ func TestRunCreatesACallbackAndPrintsTheSecretExactlyOnce(t *testing.T) {
 	if strings.Count(output.String(), secret) != 1 || strings.Count(output.String(), "deirceirceirceirceirceirce") != 1 || !strings.Contains(output.String(), "shown once") {
 		t.Fatalf("callback output = %q", output.String())
 	}
+	if stub.eventTypes != nil || strings.Contains(output.String(), "Optional events") {
+		t.Fatalf("unsubscribed callback event types = %q output=%q", stub.eventTypes, output.String())
+	}
+}
+
+func TestRunCreatesACallbackSubscribedToOptionalEvents(t *testing.T) {
+	stub := &provisionerStub{callback: external.CallbackMaterial{
+		CallbackID: "deirceirceirceirceirceirce", Secret: "croj_whsec_x",
+		EventTypes: []string{external.WebhookEventJobStarted, external.WebhookEventJobProgress},
+	}}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{
+		"callback", "create", "--tenant", "ceirceirceirceirceirceirce", "--url", "https://oj.example.com/hook",
+		"--events", "judge.job.progress,judge.job.started",
+	}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(stub.eventTypes, ",") != "judge.job.progress,judge.job.started" ||
+		!strings.Contains(output.String(), "Optional events: judge.job.started,judge.job.progress\n") {
+		t.Fatalf("event types = %q output=%q", stub.eventTypes, output.String())
+	}
 }
 
 func TestRunRejectsInvalidCallbackFlagsBeforeProvisioning(t *testing.T) {
@@ -129,3 +258,77 @@ func TestRunRejectsUnknownOrDuplicateScopesBeforeProvisioning(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPingsACallbackAndReportsTheOutcome(t *testing.T) {
+	httpStatus, latency, accepted := 401, int64(12), false
+	stub := &provisionerStub{ping: external.WebhookPing{
+		EventID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", Status: external.WebhookDeliveryDead,
+		HTTPStatus: &httpStatus, LatencyMillis: &latency, SignatureAccepted: &accepted, ErrorCode: external.WebhookErrorHTTPPermanent,
+	}}
+	var output bytes.Buffer
+	err := Run(context.Background(), []string{
+		"callback", "ping", "--tenant", "ceirceirceirceirceirceirce", "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb", "--wait", "5s",
+	}, stub, nil, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stub.tenantID != "ceirceirceirceirceirceirce" || stub.callbackID != "cbcbcbcbcbcbcbcbcbcbcbcbcb" || stub.pingWait != 5*time.Second {
+		t.Fatalf("ping request tenant=%q callback=%q wait=%s", stub.tenantID, stub.callbackID, stub.pingWait)
+	}
+	want := "Ping event: aaaaaaaaaaaaaaaaaaaaaaaaaa\nStatus: DEAD\nHTTP status: 401\nLatency: 12 ms\nSignature accepted: no\nError code: http_permanent\n"
+	if output.String() != want {
+		t.Fatalf("output = %q", output.String())
+	}
+	for _, arguments := range [][]string{
+		{"callback", "ping", "--tenant", "ceirceirceirceirceirceirce"},
+		{"callback", "ping", "--tenant", "ceirceirceirceirceirceirce", "--callback", "cbcbcbcbcbcbcbcbcbcbcbcbcb", "--wait", "2m"},
+	} {
+		stub := &provisionerStub{}
+		if err := Run(context.Background(), arguments, stub, nil, &bytes.Buffer{}); err == nil || stub.callbackCalls != 0 {
+			t.Fatalf("%v: error=%v calls=%d", arguments, err, stub.callbackCalls)
+		}
+	}
+}
+
+func TestRunExportsUsageInTheRequestedFormat(t *testing.T) {
+	stub := &provisionerStub{usageRows: []external.UsageExportRow{
+		{TenantID: "ceirceirceirceirceirceirce", Day: "2026-09-01", BundleStorageBytes: 4096},
+		{TenantID: "ceirceirceirceirceirceirce", Day: "2026-09-01", Language: "cpp17", JobExecutionMillis: 1500, RunExecutionMillis: 20, JobsSucceeded: 2, JobsFailed: 1},
+	}}
+	var output bytes.Buffer
+	err := Run(context.Background(), []string{
+		"usage", "export", "--tenant", "ceirceirceirceirceirceirce", "--from", "2026-09-01", "--to", "2026-09-30",
+	}, stub, nil, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stub.usageQuery.TenantID != "ceirceirceirceirceirceirce" || stub.usageQuery.From.Format(time.DateOnly) != "2026-09-01" ||
+		stub.usageQuery.To.Format(time.DateOnly) != "2026-09-30" {
+		t.Fatalf("usage query = %+v", stub.usageQuery)
+	}
+	want := "tenant_id,day,language,job_execution_millis,run_execution_millis,jobs_succeeded,jobs_failed,jobs_cancelled,bundle_storage_bytes\n" +
+		"ceirceirceirceirceirceirce,2026-09-01,,0,0,0,0,0,4096\n" +
+		"ceirceirceirceirceirceirce,2026-09-01,cpp17,1500,20,2,1,0,0\n"
+	if output.String() != want {
+		t.Fatalf("output = %q", output.String())
+	}
+	output.Reset()
+	if err := Run(context.Background(), []string{"usage", "export", "--from", "2026-09-01", "--to", "2026-09-01", "--format", "jsonl"}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], `{"tenantId":"ceirceirceirceirceirceirce","day":"2026-09-01","language":"cpp17"`) {
+		t.Fatalf("jsonl output = %q", output.String())
+	}
+	for _, arguments := range [][]string{
+		{"usage", "export", "--from", "2026-09-01"},
+		{"usage", "export", "--from", "2026-09-30", "--to", "2026-09-01"},
+		{"usage", "export", "--from", "2025-01-01", "--to", "2026-09-01"},
+		{"usage", "export", "--from", "2026-09-01", "--to", "2026-09-30", "--format", "xlsx"},
+		{"usage", "export", "--tenant", "not a tenant", "--from", "2026-09-01", "--to", "2026-09-30"},
+	} {
+		stub := &provisionerStub{}
+		if err := Run(context.Background(), arguments, stub, nil, &bytes.Buffer{}); err == nil || stub.usageCalls != 0 {
+			t.Fatalf("%v: error=%v calls=%d", arguments, err, stub.usageCalls)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package admincli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+// registerTenantPolicyFlags binds every policy field to the flag names that
+// tenant create and tenant update share. The defaults are the create
+// defaults; tenant update only applies the flags that were given.
+func registerTenantPolicyFlags(flags *flag.FlagSet, policy *external.TenantPolicy) {
+	flags.IntVar(&policy.MaxQueuedJobs, "max-queued", 100, "maximum queued jobs")
+	flags.IntVar(&policy.MaxRunningJobs, "max-running", 4, "maximum running jobs")
+	flags.Int64Var(&policy.MaxSourceBytes, "max-source-bytes", 1<<20, "maximum source bytes")
+	flags.IntVar(&policy.MaxRetainedBundles, "max-bundles", 200, "maximum retained bundles")
+	flags.Int64Var(&policy.DailyExecutionMillis, "daily-execution-ms", 3_600_000, "daily execution budget")
+	flags.IntVar(&policy.MaxInfrastructureTries, "max-infra-tries", 3, "maximum infrastructure attempts")
+	flags.IntVar(&policy.MaxTimeLimitMillis, "max-time-limit-ms", 10_000, "maximum per-bundle time limit in milliseconds")
+	flags.IntVar(&policy.MaxMemoryLimitMiB, "max-memory-limit-mib", 1024, "maximum per-bundle memory limit in MiB")
+	flags.IntVar(&policy.MaxJobPriority, "max-priority", 0, "highest job priority class submissions may request")
+	flags.IntVar(&policy.MaxRunningRuns, "max-running-runs", 2, "maximum concurrent custom runs")
+}
+
+func listTenants(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	if len(arguments) != 0 {
+		return fmt.Errorf("tenant list does not accept arguments")
+	}
+	tenants, err := provisioner.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(writer, "TENANT\tSTATUS\tCREATED\tNAME"); err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if _, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", tenant.TenantID, tenant.Status, tenant.CreatedAt.Format(time.RFC3339), tenant.Name); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func showTenant(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("tenant show", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse tenant show flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("tenant show does not accept positional arguments")
+	}
+	if *tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	details, err := provisioner.GetTenant(ctx, *tenantID)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output, "Tenant: %s\nName: %s\nStatus: %s\nCreated: %s\nUpdated: %s\nQueued jobs: %d\nRunning jobs: %d\n",
+		details.TenantID, details.Name, details.Status, details.CreatedAt.Format(time.RFC3339), details.UpdatedAt.Format(time.RFC3339),
+		details.QueuedJobs, details.RunningJobs); err != nil {
+		return err
+	}
+	if err := writeTenantPolicy(output, details.Policy); err != nil {
+		return err
+	}
+	if len(details.RecentAudit) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(output, "Recent changes:"); err != nil {
+		return err
+	}
+	for _, entry := range details.RecentAudit {
+		if _, err := fmt.Fprintf(output, "  %s %s %s %s\n", entry.CreatedAt.Format(time.RFC3339), entry.Action, entry.SubjectID, entry.Detail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateTenant changes only the name and policy fields given as flags.
+func updateTenant(ctx context.Context, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("tenant update", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	name := flags.String("name", "", "tenant display name")
+	policy := external.TenantPolicy{}
+	registerTenantPolicyFlags(flags, &policy)
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse tenant update flags: %w", err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("tenant update does not accept positional arguments")
+	}
+	if *tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	var update external.TenantUpdate
+	fields := map[string]func(){
+		"name":                 func() { update.Name = name },
+		"max-queued":           func() { update.MaxQueuedJobs = &policy.MaxQueuedJobs },
+		"max-running":          func() { update.MaxRunningJobs = &policy.MaxRunningJobs },
+		"max-source-bytes":     func() { update.MaxSourceBytes = &policy.MaxSourceBytes },
+		"max-bundles":          func() { update.MaxRetainedBundles = &policy.MaxRetainedBundles },
+		"daily-execution-ms":   func() { update.DailyExecutionMillis = &policy.DailyExecutionMillis },
+		"max-infra-tries":      func() { update.MaxInfrastructureTries = &policy.MaxInfrastructureTries },
+		"max-time-limit-ms":    func() { update.MaxTimeLimitMillis = &policy.MaxTimeLimitMillis },
+		"max-memory-limit-mib": func() { update.MaxMemoryLimitMiB = &policy.MaxMemoryLimitMiB },
+		"max-priority":         func() { update.MaxJobPriority = &policy.MaxJobPriority },
+		"max-running-runs":     func() { update.MaxRunningRuns = &policy.MaxRunningRuns },
+	}
+	changed := 0
+	flags.Visit(func(given *flag.Flag) {
+		if set, ok := fields[given.Name]; ok {
+			set()
+			changed++
+		}
+	})
+	if changed == 0 {
+		return fmt.Errorf("tenant update requires at least one name or policy flag")
+	}
+	tenant, err := provisioner.UpdateTenant(ctx, *tenantID, update)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output, "Tenant updated: %s\nName: %s\n", tenant.TenantID, tenant.Name); err != nil {
+		return err
+	}
+	return writeTenantPolicy(output, tenant.Policy)
+}
+
+func setTenantStatus(ctx context.Context, action string, arguments []string, provisioner Provisioner, output io.Writer) error {
+	flags := flag.NewFlagSet("tenant "+action, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tenantID := flags.String("tenant", "", "tenant external ID")
+	var interruptRunning *bool
+	if action == "disable" {
+		interruptRunning = flags.Bool("interrupt-running", false, "also end the leases of running jobs so they fail as TENANT_DISABLED")
+	}
+	if err := flags.Parse(arguments); err != nil {
+		return fmt.Errorf("parse tenant %s flags: %w", action, err)
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("tenant %s does not accept positional arguments", action)
+	}
+	if *tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if action == "enable" {
+		if err := provisioner.EnableTenant(ctx, *tenantID); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(output, "Tenant enabled: %s\n", *tenantID)
+		return err
+	}
+	interrupted, err := provisioner.DisableTenant(ctx, *tenantID, *interruptRunning)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output, "Tenant disabled: %s\n", *tenantID); err != nil || !*interruptRunning {
+		return err
+	}
+	_, err = fmt.Fprintf(output, "Running jobs interrupted: %d\n", interrupted)
+	return err
+}
+
+func writeTenantPolicy(output io.Writer, policy external.TenantPolicy) error {
+	_, err := fmt.Fprintf(output,
+		"Max queued jobs: %d\nMax running jobs: %d\nMax source bytes: %d\nMax retained bundles: %d\nDaily execution ms: %d\n"+
+			"Max infrastructure tries: %d\nMax time limit ms: %d\nMax memory limit MiB: %d\nMax job priority: %d\nMax running custom runs: %d\n",
+		policy.MaxQueuedJobs, policy.MaxRunningJobs, policy.MaxSourceBytes, policy.MaxRetainedBundles, policy.DailyExecutionMillis,
+		policy.MaxInfrastructureTries, policy.MaxTimeLimitMillis, policy.MaxMemoryLimitMiB, policy.MaxJobPriority, policy.RunningRunLimit())
+	return err
+}
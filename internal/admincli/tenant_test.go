@@ -0,0 +1,119 @@
+package admincli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+var testTenant = external.TenantSummary{
+	TenantID: "ceirceirceirceirceirceirce", Name: "Acme OJ", Status: external.TenantActive,
+	Policy: external.TenantPolicy{
+		MaxQueuedJobs: 80, MaxRunningJobs: 4, MaxSourceBytes: 1048576, MaxRetainedBundles: 120, DailyExecutionMillis: 3600000,
+		MaxInfrastructureTries: 3, MaxTimeLimitMillis: 10000, MaxMemoryLimitMiB: 1024, MaxJobPriority: 5, MaxRunningRuns: 2,
+	},
+	CreatedAt: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2026, 10, 2, 9, 30, 0, 0, time.UTC),
+}
+
+const testTenantPolicyOutput = "Max queued jobs: 80\nMax running jobs: 4\nMax source bytes: 1048576\nMax retained bundles: 120\nDaily execution ms: 3600000\n" +
+	"Max infrastructure tries: 3\nMax time limit ms: 10000\nMax memory limit MiB: 1024\nMax job priority: 5\nMax running custom runs: 2\n"
+
+func TestRunListsAndShowsTenants(t *testing.T) {
+	disabled := testTenant
+	disabled.TenantID, disabled.Name, disabled.Status = "dddddddddddddddddddddddddd", "Beta", external.TenantDisabled
+	stub := &provisionerStub{tenants: []external.TenantSummary{testTenant, disabled}}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{"tenant", "list"}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	want := "TENANT                      STATUS    CREATED               NAME\n" +
+		"ceirceirceirceirceirceirce  ACTIVE    2026-09-01T08:00:00Z  Acme OJ\n" +
+		"dddddddddddddddddddddddddd  DISABLED  2026-09-01T08:00:00Z  Beta\n"
+	if output.String() != want {
+		t.Fatalf("list output = %q", output.String())
+	}
+
+	stub = &provisionerStub{details: external.TenantDetails{
+		TenantSummary: testTenant, QueuedJobs: 7, RunningJobs: 2,
+		RecentAudit: []external.AdminAuditEntry{{
+			Action: "tenant.enabled", SubjectID: testTenant.TenantID, Detail: json.RawMessage(`{}`), CreatedAt: testTenant.UpdatedAt,
+		}},
+	}}
+	output.Reset()
+	if err := Run(context.Background(), []string{"tenant", "show", "--tenant", testTenant.TenantID}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	want = "Tenant: ceirceirceirceirceirceirce\nName: Acme OJ\nStatus: ACTIVE\nCreated: 2026-09-01T08:00:00Z\nUpdated: 2026-10-02T09:30:00Z\n" +
+		"Queued jobs: 7\nRunning jobs: 2\n" + testTenantPolicyOutput +
+		"Recent changes:\n  2026-10-02T09:30:00Z tenant.enabled ceirceirceirceirceirceirce {}\n"
+	if stub.tenantID != testTenant.TenantID || output.String() != want {
+		t.Fatalf("show tenant=%q output=%q", stub.tenantID, output.String())
+	}
+}
+
+func TestRunUpdatesOnlyTheGivenTenantFields(t *testing.T) {
+	stub := &provisionerStub{details: external.TenantDetails{TenantSummary: testTenant}}
+	var output bytes.Buffer
+	err := Run(context.Background(), []string{
+		"tenant", "update", "--tenant", testTenant.TenantID, "--max-queued", "80", "--max-priority", "5", "--name", "Acme OJ",
+	}, stub, nil, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := stub.tenantUpdate
+	if update.Name == nil || *update.Name != "Acme OJ" || update.MaxQueuedJobs == nil || *update.MaxQueuedJobs != 80 ||
+		update.MaxJobPriority == nil || *update.MaxJobPriority != 5 {
+		t.Fatalf("update = %+v", update)
+	}
+	if update.MaxRunningJobs != nil || update.MaxSourceBytes != nil || update.MaxRetainedBundles != nil || update.DailyExecutionMillis != nil ||
+		update.MaxInfrastructureTries != nil || update.MaxTimeLimitMillis != nil || update.MaxMemoryLimitMiB != nil || update.MaxRunningRuns != nil {
+		t.Fatalf("flags that were not given changed the policy: %+v", update)
+	}
+	if output.String() != "Tenant updated: ceirceirceirceirceirceirce\nName: Acme OJ\n"+testTenantPolicyOutput {
+		t.Fatalf("output = %q", output.String())
+	}
+	for _, arguments := range [][]string{
+		{"tenant", "update", "--tenant", testTenant.TenantID},
+		{"tenant", "update", "--max-queued", "10"},
+		{"tenant", "update", "--tenant", testTenant.TenantID, "--max-queued", "ten"},
+		{"tenant", "show"},
+		{"tenant", "list", "extra"},
+		{"tenant", "disable"},
+		{"tenant", "enable", "--tenant", testTenant.TenantID, "--interrupt-running"},
+	} {
+		stub := &provisionerStub{}
+		if err := Run(context.Background(), arguments, stub, nil, &bytes.Buffer{}); err == nil || stub.tenantCalls != 0 {
+			t.Fatalf("%v: error=%v calls=%d", arguments, err, stub.tenantCalls)
+		}
+	}
+}
+
+func TestRunDisablesAndEnablesATenant(t *testing.T) {
+	stub := &provisionerStub{interrupted: 3}
+	var output bytes.Buffer
+	if err := Run(context.Background(), []string{"tenant", "disable", "--tenant", testTenant.TenantID}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if stub.tenantStatus != "DISABLED" || stub.interruptRunning || output.String() != "Tenant disabled: ceirceirceirceirceirceirce\n" {
+		t.Fatalf("status=%q interrupt=%t output=%q", stub.tenantStatus, stub.interruptRunning, output.String())
+	}
+	output.Reset()
+	if err := Run(context.Background(), []string{"tenant", "disable", "--tenant", testTenant.TenantID, "--interrupt-running"}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if !stub.interruptRunning || output.String() != "Tenant disabled: ceirceirceirceirceirceirce\nRunning jobs interrupted: 3\n" {
+		t.Fatalf("interrupt=%t output=%q", stub.interruptRunning, output.String())
+	}
+	output.Reset()
+	if err := Run(context.Background(), []string{"tenant", "enable", "--tenant", testTenant.TenantID}, stub, nil, &output); err != nil {
+		t.Fatal(err)
+	}
+	if stub.tenantStatus != "ACTIVE" || !strings.HasPrefix(output.String(), "Tenant enabled: ") {
+		t.Fatalf("status=%q output=%q", stub.tenantStatus, output.String())
+	}
+}
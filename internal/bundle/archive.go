@@ -13,6 +13,14 @@ import (
 
 const maxSpecialJudgeSourceBytes int64 = 4 << 20
 
+// CompileFile is a digest-verified grader file ready to be placed beside the
+// contestant source in the sandbox compile directory.
+type CompileFile struct {
+	Name    string
+	Content string
+	Header  bool
+}
+
 type ArchiveLimits struct {
 	MaxFiles            int
 	MaxManifestBytes    int64
@@ -78,6 +86,18 @@ func InspectArchive(filename string, limits ArchiveLimits) (Manifest, []byte, er
 			return Manifest{}, nil, fmt.Errorf("validate special judge source: %w", err)
 		}
 	}
+	if artifact.manifest.Interactor != nil {
+		if err := artifact.validateTextEntry(artifact.manifest.Interactor.Source, maxSpecialJudgeSourceBytes); err != nil {
+			return Manifest{}, nil, fmt.Errorf("validate interactor source: %w", err)
+		}
+	}
+	for _, grader := range artifact.manifest.Graders {
+		for _, file := range append(append([]GraderFile(nil), grader.Sources...), grader.Headers...) {
+			if err := artifact.validateTextEntry(file.Source, maxSpecialJudgeSourceBytes); err != nil {
+				return Manifest{}, nil, fmt.Errorf("validate %s grader file %q: %w", grader.Language, file.Name, err)
+			}
+		}
+	}
 	return artifact.manifest, append([]byte(nil), artifact.manifestJSON...), nil
 }
 
@@ -114,13 +134,50 @@ func (artifact *Artifact) ReadSpecialJudge() (string, error) {
 	if artifact.manifest.SpecialJudge == nil {
 		return "", fmt.Errorf("bundle does not contain a special judge")
 	}
-	source, err := artifact.readText(artifact.manifest.SpecialJudge.Source, maxSpecialJudgeSourceBytes)
+	return artifact.readJudgeProgram("special judge", *artifact.manifest.SpecialJudge)
+}
+
+func (artifact *Artifact) ReadInteractor() (string, error) {
+	if artifact.manifest.Interactor == nil {
+		return "", fmt.Errorf("bundle does not contain an interactor")
+	}
+	return artifact.readJudgeProgram("interactor", SpecialJudge(*artifact.manifest.Interactor))
+}
+
+// ReadGrader returns the grader sources followed by the grader headers for a
+// sandbox language, each verified against its pinned SHA-256.
+func (artifact *Artifact) ReadGrader(language string) ([]CompileFile, error) {
+	grader, ok := artifact.manifest.GraderFor(language)
+	if !ok {
+		return nil, fmt.Errorf("bundle does not contain a %q grader", language)
+	}
+	files := make([]CompileFile, 0, len(grader.Sources)+len(grader.Headers))
+	for index, file := range append(append([]GraderFile(nil), grader.Sources...), grader.Headers...) {
+		content, err := artifact.readPinnedText(file.Source, file.SourceSHA256)
+		if err != nil {
+			return nil, fmt.Errorf("read %s grader file %q: %w", language, file.Name, err)
+		}
+		files = append(files, CompileFile{Name: file.Name, Content: content, Header: index >= len(grader.Sources)})
+	}
+	return files, nil
+}
+
+func (artifact *Artifact) readPinnedText(name, expectedSHA256 string) (string, error) {
+	content, err := artifact.readText(name, maxSpecialJudgeSourceBytes)
 	if err != nil {
-		return "", fmt.Errorf("read special judge source: %w", err)
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(content))
+	if hex.EncodeToString(digest[:]) != expectedSHA256 {
+		return "", fmt.Errorf("digest mismatch")
 	}
-	digest := sha256.Sum256([]byte(source))
-	if hex.EncodeToString(digest[:]) != artifact.manifest.SpecialJudge.SourceSHA256 {
-		return "", fmt.Errorf("special judge source digest mismatch")
+	return content, nil
+}
+
+func (artifact *Artifact) readJudgeProgram(label string, program SpecialJudge) (string, error) {
+	source, err := artifact.readPinnedText(program.Source, program.SourceSHA256)
+	if err != nil {
+		return "", fmt.Errorf("read %s source: %w", label, err)
 	}
 	return source, nil
 }
@@ -168,8 +225,8 @@ func (artifact *Artifact) validate(expected *Manifest) error {
 		return fmt.Errorf("artifact manifest.json disagrees with database manifest_json")
 	}
 	referenced := map[string]struct{}{"manifest.json": {}}
-	if actual.SpecialJudge != nil {
-		referenced[actual.SpecialJudge.Source] = struct{}{}
+	for _, name := range actual.programPaths() {
+		referenced[name] = struct{}{}
 	}
 	for _, testCase := range actual.Cases {
 		referenced[testCase.Input] = struct{}{}
@@ -190,6 +247,16 @@ func (artifact *Artifact) validate(expected *Manifest) error {
 			return err
 		}
 	}
+	if actual.Interactor != nil {
+		if _, err := artifact.ReadInteractor(); err != nil {
+			return err
+		}
+	}
+	for _, grader := range actual.Graders {
+		if _, err := artifact.ReadGrader(grader.Language); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -64,6 +64,84 @@ func TestOpenArchiveReadsAndVerifiesSpecialJudgeSource(t *testing.T) {
 	}
 }
 
+func TestOpenArchiveReadsAndVerifiesInteractorSource(t *testing.T) {
+	source := "int main() { return 0; }\n"
+	digest := sha256.Sum256([]byte(source))
+	manifest := `{"schemaVersion":2,"judgeMode":"ACM","checker":"interactive","limits":{"timeLimitMillis":1500,"memoryLimitMiB":256},"interactor":{"language":"cpp","source":"interactor/main.cpp","sourceSha256":"` +
+		hex.EncodeToString(digest[:]) +
+		`","timeLimitMillis":3000,"memoryLimitMiB":128},"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":1}]}`
+	path := writeZIP(t, []zipEntry{
+		{name: "manifest.json", body: manifest},
+		{name: "interactor/main.cpp", body: source},
+		{name: "cases/01.in", body: "42\n"},
+		{name: "cases/01.out", body: "42\n"},
+	})
+	artifact, err := OpenArchive(path, []byte(manifest), DefaultArchiveLimits())
+	if err != nil {
+		t.Fatalf("OpenArchive: %v", err)
+	}
+	defer artifact.Close()
+	if got, err := artifact.ReadInteractor(); err != nil || got != source {
+		t.Fatalf("ReadInteractor = %q, %v", got, err)
+	}
+	if _, err := artifact.ReadSpecialJudge(); err == nil {
+		t.Fatal("interactive bundle exposed a special judge")
+	}
+
+	tampered := writeZIP(t, []zipEntry{
+		{name: "manifest.json", body: manifest},
+		{name: "interactor/main.cpp", body: "// tampered\n" + source},
+		{name: "cases/01.in", body: "42\n"},
+		{name: "cases/01.out", body: "42\n"},
+	})
+	if artifact, err := OpenArchive(tampered, []byte(manifest), DefaultArchiveLimits()); err == nil {
+		artifact.Close()
+		t.Fatal("expected interactor digest mismatch")
+	}
+}
+
+func TestOpenArchiveReadsAndVerifiesGraderFiles(t *testing.T) {
+	grader, header := "#include \"solution.h\"\nint main() { return solve(); }\n", "int solve();\n"
+	graderDigest, headerDigest := sha256.Sum256([]byte(grader)), sha256.Sum256([]byte(header))
+	manifest := `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1500,"memoryLimitMiB":256},"graders":[{"language":"cpp","sources":[{"name":"grader.cpp","source":"grader/cpp/grader.cpp","sourceSha256":"` +
+		hex.EncodeToString(graderDigest[:]) +
+		`"}],"headers":[{"name":"solution.h","source":"grader/cpp/solution.h","sourceSha256":"` +
+		hex.EncodeToString(headerDigest[:]) +
+		`"}]}],"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":1}]}`
+	entries := []zipEntry{
+		{name: "manifest.json", body: manifest},
+		{name: "grader/cpp/grader.cpp", body: grader},
+		{name: "grader/cpp/solution.h", body: header},
+		{name: "cases/01.in", body: "2 3\n"},
+		{name: "cases/01.out", body: "5\n"},
+	}
+	artifact, err := OpenArchive(writeZIP(t, entries), []byte(manifest), DefaultArchiveLimits())
+	if err != nil {
+		t.Fatalf("OpenArchive: %v", err)
+	}
+	defer artifact.Close()
+	files, err := artifact.ReadGrader("cpp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0] != (CompileFile{Name: "grader.cpp", Content: grader}) ||
+		files[1] != (CompileFile{Name: "solution.h", Content: header, Header: true}) {
+		t.Fatalf("ReadGrader = %+v", files)
+	}
+	if _, err := artifact.ReadGrader("python"); err == nil {
+		t.Fatal("ReadGrader returned files for an undeclared language")
+	}
+
+	entries[2].body = "int solve(long);\n"
+	if artifact, err := OpenArchive(writeZIP(t, entries), []byte(manifest), DefaultArchiveLimits()); err == nil {
+		artifact.Close()
+		t.Fatal("expected grader header digest mismatch")
+	}
+	if _, _, err := InspectArchive(writeZIP(t, entries[:2]), DefaultArchiveLimits()); err == nil {
+		t.Fatal("expected missing grader header to be rejected")
+	}
+}
+
 func TestOpenArchiveRejectsUnsafeZIPs(t *testing.T) {
 	differentManifest := strings.Replace(validManifest, `"checker":"exact"`, `"checker":"token"`, 1)
 	tests := map[string][]zipEntry{
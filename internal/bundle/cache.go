@@ -12,6 +12,11 @@ import (
 	"sync"
 	"time"
 	"unicode/utf8"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Object struct {
@@ -81,11 +86,16 @@ func NewCache(directory string, maxBytes, maxObjectBytes int64, ttl time.Duratio
 	}, nil
 }
 
-func (cache *Cache) Resolve(ctx context.Context, metadata Metadata) (string, error) {
+func (cache *Cache) Resolve(ctx context.Context, metadata Metadata) (_ string, err error) {
 	if err := cache.validateMetadata(metadata); err != nil {
 		return "", Invalid(err)
 	}
 	metadata.SHA256 = strings.ToLower(metadata.SHA256)
+	ctx, span := tracing.Start(ctx, "bundle.Cache.Resolve", trace.WithAttributes(
+		attribute.String("croj.bundle.sha256", metadata.SHA256),
+		attribute.Int64("croj.bundle.size_bytes", metadata.SizeBytes),
+	))
+	defer func() { tracing.End(span, err) }()
 	cache.mu.Lock()
 	cache.pruneExpiredLocked(time.Now())
 	if flight := cache.flights[metadata.SHA256]; flight != nil {
@@ -101,6 +111,8 @@ func (cache *Cache) Resolve(ctx context.Context, metadata Metadata) (string, err
 			if err := verifyCachedFile(flight.path, metadata); err != nil {
 				return "", Invalid(fmt.Errorf("bundle metadata disagrees with coalesced cache entry: %w", err))
 			}
+			// A coalesced caller never touches object storage itself.
+			recordLookup(ctx, true)
 			cache.record(metadata.SHA256, flight.path, metadata.SizeBytes)
 			return flight.path, nil
 		}
@@ -121,9 +133,11 @@ func (cache *Cache) Resolve(ctx context.Context, metadata Metadata) (string, err
 func (cache *Cache) resolveOne(ctx context.Context, metadata Metadata) (string, error) {
 	target := filepath.Join(cache.directory, metadata.SHA256+".zip")
 	if err := verifyCachedFile(target, metadata); err == nil {
+		recordLookup(ctx, true)
 		cache.record(metadata.SHA256, target, metadata.SizeBytes)
 		return target, nil
 	}
+	recordLookup(ctx, false)
 	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
 		return "", fmt.Errorf("remove corrupt bundle cache entry: %w", err)
 	}
@@ -178,6 +192,11 @@ func (cache *Cache) resolveOne(ctx context.Context, metadata Metadata) (string,
 	return target, nil
 }
 
+func recordLookup(ctx context.Context, hit bool) {
+	metrics.RecordBundleCacheLookup(hit)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("croj.bundle_cache.hit", hit))
+}
+
 func (cache *Cache) validateMetadata(metadata Metadata) error {
 	if strings.TrimSpace(metadata.ObjectKey) == "" || len(metadata.ObjectKey) > 512 || strings.ContainsRune(metadata.ObjectKey, '\x00') || !utf8.ValidString(metadata.ObjectKey) ||
 		metadata.SizeBytes <= 0 || metadata.SizeBytes > cache.maxObjectBytes || metadata.SizeBytes > cache.maxBytes {
@@ -258,6 +277,7 @@ func (cache *Cache) pruneExpiredLocked(now time.Time) {
 		if now.Sub(entry.lastUsed) >= cache.ttl {
 			_ = os.Remove(entry.path)
 			delete(cache.entries, checksum)
+			metrics.RecordBundleCacheEviction("ttl")
 		}
 	}
 }
@@ -279,6 +299,7 @@ func (cache *Cache) evictLRULocked(protectedChecksum string) {
 		}
 		_ = os.Remove(oldest.path)
 		delete(cache.entries, oldestChecksum)
+		metrics.RecordBundleCacheEviction("capacity")
 	}
 }
 
@@ -12,6 +12,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 )
 
 type fakeObjectStore struct {
@@ -226,6 +228,54 @@ func TestCacheEvictsLRUAndExpiredEntries(t *testing.T) {
 	}
 }
 
+func TestCacheCountsHitsMissesAndEvictions(t *testing.T) {
+	first := []byte("first")
+	second := []byte("second")
+	store := &fakeObjectStore{objects: map[string][]byte{"first": first, "second": second}}
+	cache, err := NewCache(t.TempDir(), int64(len(second)), 1<<20, time.Hour, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits, misses := cacheCounter(t, "lookups_total", "hit"), cacheCounter(t, "lookups_total", "miss")
+	capacity, expired := cacheCounter(t, "evictions_total", "capacity"), cacheCounter(t, "evictions_total", "ttl")
+	for _, metadata := range []Metadata{cacheMetadata("first", first), cacheMetadata("first", first), cacheMetadata("second", second)} {
+		if _, err := cache.Resolve(context.Background(), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache.ttl = 0
+	cache.mu.Lock()
+	cache.pruneExpiredLocked(time.Now())
+	cache.mu.Unlock()
+	if cacheCounter(t, "lookups_total", "hit")-hits != 1 || cacheCounter(t, "lookups_total", "miss")-misses != 2 ||
+		cacheCounter(t, "evictions_total", "capacity")-capacity != 1 || cacheCounter(t, "evictions_total", "ttl")-expired != 1 {
+		t.Fatalf("hits=%v misses=%v capacity=%v ttl=%v",
+			cacheCounter(t, "lookups_total", "hit")-hits, cacheCounter(t, "lookups_total", "miss")-misses,
+			cacheCounter(t, "evictions_total", "capacity")-capacity, cacheCounter(t, "evictions_total", "ttl")-expired)
+	}
+}
+
+// cacheCounter reads one bundle cache counter from the process registry;
+// callers compare deltas because the registry outlives individual tests.
+func cacheCounter(t *testing.T, name, label string) float64 {
+	t.Helper()
+	families, err := metrics.Gatherer().Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "croj_bundle_cache_"+name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == label {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
 func cacheMetadata(key string, data []byte) Metadata {
 	sum := sha256.Sum256(data)
 	return Metadata{ObjectKey: key, SHA256: hex.EncodeToString(sum[:]), SizeBytes: int64(len(data))}
@@ -15,6 +15,7 @@ import (
 
 type JudgeMode string
 type Checker = judgecontract.Checker
+type SubtaskAggregation string
 
 const (
 	JudgeModeACM       JudgeMode = "ACM"
@@ -22,25 +23,43 @@ const (
 	CheckerExact                 = judgecontract.CheckerExact
 	CheckerToken                 = judgecontract.CheckerToken
 	CheckerSpecial               = judgecontract.CheckerSpecial
+	CheckerInteractive           = judgecontract.CheckerInteractive
+	CheckerFloat                 = judgecontract.CheckerFloat
 	maxCases                     = 10_000
 	maxTotalScore                = 1_000_000_000
 	maxExecutionMillis           = 86_400_000
 	maxMemoryMiB                 = 2_147_483_647
+	maxSubtasks                  = 256
+	maxGraderFiles               = 16
+	maxFloatEpsilon              = 1.0
+)
+
+// SubtaskAggregationMin awards a subtask score only when every grouped case is
+// accepted (IOI all-or-nothing). SubtaskAggregationSum awards the accepted
+// share of the grouped case weights, rounded down.
+const (
+	SubtaskAggregationMin SubtaskAggregation = "min"
+	SubtaskAggregationSum SubtaskAggregation = "sum"
 )
 
 var (
 	caseIDPattern      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
 	lowerSHA256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	compileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
 )
 
 type Manifest struct {
-	SchemaVersion int           `json:"schemaVersion"`
-	JudgeMode     JudgeMode     `json:"judgeMode"`
-	Checker       Checker       `json:"checker"`
-	Limits        Limits        `json:"limits"`
-	TotalScore    *int          `json:"totalScore,omitempty"`
-	SpecialJudge  *SpecialJudge `json:"specialJudge,omitempty"`
-	Cases         []Case        `json:"cases"`
+	SchemaVersion  int             `json:"schemaVersion"`
+	JudgeMode      JudgeMode       `json:"judgeMode"`
+	Checker        Checker         `json:"checker"`
+	Limits         Limits          `json:"limits"`
+	TotalScore     *int            `json:"totalScore,omitempty"`
+	SpecialJudge   *SpecialJudge   `json:"specialJudge,omitempty"`
+	Interactor     *Interactor     `json:"interactor,omitempty"`
+	FloatTolerance *FloatTolerance `json:"floatTolerance,omitempty"`
+	Graders        []Grader        `json:"graders,omitempty"`
+	Cases          []Case          `json:"cases"`
+	Subtasks       []Subtask       `json:"subtasks,omitempty"`
 }
 
 type Limits struct {
@@ -56,6 +75,44 @@ type SpecialJudge struct {
 	MemoryLimitMiB  int    `json:"memoryLimitMiB"`
 }
 
+// Interactor is the judge program of an interactive bundle. It is pinned and
+// limited exactly like SpecialJudge but runs concurrently with the contestant.
+type Interactor SpecialJudge
+
+// FloatTolerance parameterizes the built-in float checker. A numeric token is
+// accepted within either epsilon; a zero epsilon disables that bound.
+type FloatTolerance struct {
+	AbsoluteEpsilon float64 `json:"absoluteEpsilon"`
+	RelativeEpsilon float64 `json:"relativeEpsilon"`
+}
+
+// Grader turns a function-implementation problem into a runnable program for
+// one language. Sources are compiled together with the contestant source;
+// headers are only placed beside it so that the contestant can include them.
+type Grader struct {
+	Language string       `json:"language"`
+	Sources  []GraderFile `json:"sources"`
+	Headers  []GraderFile `json:"headers,omitempty"`
+}
+
+// GraderFile names a pinned archive entry and the file name it receives in the
+// sandbox compile directory.
+type GraderFile struct {
+	Name         string `json:"name"`
+	Source       string `json:"source"`
+	SourceSHA256 string `json:"sourceSha256"`
+}
+
+// Subtask is a schemaVersion 3 scoring group. Dependencies name earlier
+// subtasks that must earn their full score before this one can score.
+type Subtask struct {
+	ID           string             `json:"id"`
+	Score        int                `json:"score"`
+	Aggregation  SubtaskAggregation `json:"aggregation"`
+	Cases        []string           `json:"cases"`
+	Dependencies []string           `json:"dependencies,omitempty"`
+}
+
 type Case struct {
 	ID     string `json:"id"`
 	Input  string `json:"input"`
@@ -83,7 +140,7 @@ func ParseManifest(data []byte) (Manifest, error) {
 }
 
 func (manifest Manifest) Validate() error {
-	if manifest.SchemaVersion != 1 && manifest.SchemaVersion != 2 {
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > 3 {
 		return fmt.Errorf("unsupported manifest schemaVersion %d", manifest.SchemaVersion)
 	}
 	if manifest.Limits.TimeLimitMillis <= 0 || manifest.Limits.TimeLimitMillis > maxExecutionMillis ||
@@ -92,14 +149,15 @@ func (manifest Manifest) Validate() error {
 	}
 	if manifest.SchemaVersion == 1 {
 		if manifest.JudgeMode != JudgeModeACM || !judgecontract.IsCanonicalChecker(manifest.Checker) ||
-			manifest.TotalScore != nil || manifest.SpecialJudge != nil {
+			manifest.TotalScore != nil || manifest.SpecialJudge != nil || manifest.Interactor != nil ||
+			manifest.FloatTolerance != nil || len(manifest.Graders) != 0 {
 			return fmt.Errorf("manifest v1 supports ACM exact/token only")
 		}
 	} else {
 		if manifest.JudgeMode != JudgeModeACM && manifest.JudgeMode != JudgeModeOI {
 			return fmt.Errorf("unsupported judgeMode %q", manifest.JudgeMode)
 		}
-		if !judgecontract.IsCanonicalChecker(manifest.Checker) && manifest.Checker != CheckerSpecial {
+		if !judgecontract.IsCanonicalChecker(manifest.Checker) {
 			return fmt.Errorf("unsupported checker %q", manifest.Checker)
 		}
 	}
@@ -107,30 +165,48 @@ func (manifest Manifest) Validate() error {
 		if manifest.SpecialJudge == nil {
 			return fmt.Errorf("special checker requires specialJudge")
 		}
-		if _, ok := judgecontract.ResolveLanguage(manifest.SpecialJudge.Language); !ok {
-			return fmt.Errorf("specialJudge language is unsupported")
+		if err := validateJudgeProgram("specialJudge", *manifest.SpecialJudge); err != nil {
+			return err
+		}
+	} else if manifest.SpecialJudge != nil {
+		return fmt.Errorf("specialJudge is only valid for the special checker")
+	}
+	if manifest.Checker == CheckerInteractive {
+		if manifest.Interactor == nil {
+			return fmt.Errorf("interactive checker requires interactor")
 		}
-		if err := validateArtifactPath(manifest.SpecialJudge.Source); err != nil ||
-			manifest.SpecialJudge.Source == "manifest.json" {
-			return fmt.Errorf("specialJudge source path is invalid")
+		if err := validateJudgeProgram("interactor", SpecialJudge(*manifest.Interactor)); err != nil {
+			return err
 		}
-		if !lowerSHA256Pattern.MatchString(manifest.SpecialJudge.SourceSHA256) {
-			return fmt.Errorf("specialJudge sourceSha256 must be lowercase SHA-256")
+	} else if manifest.Interactor != nil {
+		return fmt.Errorf("interactor is only valid for the interactive checker")
+	}
+	if manifest.Checker == CheckerFloat {
+		tolerance := manifest.FloatTolerance
+		if tolerance == nil {
+			return fmt.Errorf("float checker requires floatTolerance")
 		}
-		if manifest.SpecialJudge.TimeLimitMillis <= 0 || manifest.SpecialJudge.TimeLimitMillis > maxExecutionMillis ||
-			manifest.SpecialJudge.MemoryLimitMiB <= 0 || manifest.SpecialJudge.MemoryLimitMiB > maxMemoryMiB {
-			return fmt.Errorf("specialJudge limits are outside supported bounds")
+		if !(tolerance.AbsoluteEpsilon >= 0 && tolerance.AbsoluteEpsilon <= maxFloatEpsilon) ||
+			!(tolerance.RelativeEpsilon >= 0 && tolerance.RelativeEpsilon <= maxFloatEpsilon) ||
+			tolerance.AbsoluteEpsilon == 0 && tolerance.RelativeEpsilon == 0 {
+			return fmt.Errorf("floatTolerance epsilons must be within 0..%g and not both zero", maxFloatEpsilon)
 		}
-	} else if manifest.SpecialJudge != nil {
-		return fmt.Errorf("specialJudge is only valid for the special checker")
+	} else if manifest.FloatTolerance != nil {
+		return fmt.Errorf("floatTolerance is only valid for the float checker")
 	}
 	if len(manifest.Cases) == 0 || len(manifest.Cases) > maxCases {
 		return fmt.Errorf("manifest cases must contain 1..%d entries", maxCases)
 	}
+	if err := manifest.validateGraders(); err != nil {
+		return err
+	}
 	ids := make(map[string]struct{}, len(manifest.Cases))
 	paths := make(map[string]struct{}, len(manifest.Cases)*2)
-	if manifest.SpecialJudge != nil {
-		paths[manifest.SpecialJudge.Source] = struct{}{}
+	for _, name := range manifest.programPaths() {
+		if _, exists := paths[name]; exists {
+			return fmt.Errorf("duplicate program path %q", name)
+		}
+		paths[name] = struct{}{}
 	}
 	var weightSum int64
 	for index, testCase := range manifest.Cases {
@@ -164,6 +240,12 @@ func (manifest Manifest) Validate() error {
 			paths[name] = struct{}{}
 		}
 	}
+	if manifest.SchemaVersion == 3 {
+		return manifest.validateSubtasks(ids)
+	}
+	if len(manifest.Subtasks) != 0 {
+		return fmt.Errorf("subtasks require manifest schemaVersion 3")
+	}
 	if manifest.JudgeMode == JudgeModeOI {
 		if manifest.TotalScore == nil || *manifest.TotalScore <= 0 || *manifest.TotalScore > maxTotalScore ||
 			int64(*manifest.TotalScore) != weightSum {
@@ -175,6 +257,163 @@ func (manifest Manifest) Validate() error {
 	return nil
 }
 
+func validateJudgeProgram(field string, program SpecialJudge) error {
+	if _, ok := judgecontract.ResolveLanguage(program.Language); !ok {
+		return fmt.Errorf("%s language is unsupported", field)
+	}
+	if err := validateArtifactPath(program.Source); err != nil || program.Source == "manifest.json" {
+		return fmt.Errorf("%s source path is invalid", field)
+	}
+	if !lowerSHA256Pattern.MatchString(program.SourceSHA256) {
+		return fmt.Errorf("%s sourceSha256 must be lowercase SHA-256", field)
+	}
+	if program.TimeLimitMillis <= 0 || program.TimeLimitMillis > maxExecutionMillis ||
+		program.MemoryLimitMiB <= 0 || program.MemoryLimitMiB > maxMemoryMiB {
+		return fmt.Errorf("%s limits are outside supported bounds", field)
+	}
+	return nil
+}
+
+// GraderFor returns the grader declared for a sandbox language.
+func (manifest Manifest) GraderFor(language string) (Grader, bool) {
+	for _, grader := range manifest.Graders {
+		if grader.Language == language {
+			return grader, true
+		}
+	}
+	return Grader{}, false
+}
+
+// SupportsLanguage reports whether a submission in language can be judged.
+// Full-program bundles accept every language; grader bundles accept only the
+// languages they ship a grader for.
+func (manifest Manifest) SupportsLanguage(language string) bool {
+	if len(manifest.Graders) == 0 {
+		return true
+	}
+	_, ok := manifest.GraderFor(language)
+	return ok
+}
+
+// programPaths lists every trusted program entry the manifest references
+// besides case data, in manifest order.
+func (manifest Manifest) programPaths() []string {
+	var paths []string
+	if manifest.SpecialJudge != nil {
+		paths = append(paths, manifest.SpecialJudge.Source)
+	}
+	if manifest.Interactor != nil {
+		paths = append(paths, manifest.Interactor.Source)
+	}
+	for _, grader := range manifest.Graders {
+		for _, file := range grader.Sources {
+			paths = append(paths, file.Source)
+		}
+		for _, file := range grader.Headers {
+			paths = append(paths, file.Source)
+		}
+	}
+	return paths
+}
+
+func (manifest Manifest) validateGraders() error {
+	languages := make(map[string]struct{}, len(manifest.Graders))
+	for index, grader := range manifest.Graders {
+		if _, ok := judgecontract.ResolveLanguage(grader.Language); !ok {
+			return fmt.Errorf("grader %d language is unsupported", index)
+		}
+		if _, exists := languages[grader.Language]; exists {
+			return fmt.Errorf("duplicate grader language %q", grader.Language)
+		}
+		languages[grader.Language] = struct{}{}
+		if len(grader.Sources) == 0 || len(grader.Sources)+len(grader.Headers) > maxGraderFiles {
+			return fmt.Errorf("grader %q must declare 1..%d files including at least one source", grader.Language, maxGraderFiles)
+		}
+		names := make(map[string]struct{}, len(grader.Sources)+len(grader.Headers))
+		for _, file := range append(append([]GraderFile(nil), grader.Sources...), grader.Headers...) {
+			if !compileNamePattern.MatchString(file.Name) {
+				return fmt.Errorf("grader %q file name is invalid", grader.Language)
+			}
+			if _, exists := names[file.Name]; exists {
+				return fmt.Errorf("grader %q repeats file name %q", grader.Language, file.Name)
+			}
+			names[file.Name] = struct{}{}
+			if err := validateArtifactPath(file.Source); err != nil || file.Source == "manifest.json" {
+				return fmt.Errorf("grader %q file %q source path is invalid", grader.Language, file.Name)
+			}
+			if !lowerSHA256Pattern.MatchString(file.SourceSHA256) {
+				return fmt.Errorf("grader %q file %q sourceSha256 must be lowercase SHA-256", grader.Language, file.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSubtasks enforces the v3 grouping contract: every case belongs to at
+// least one subtask, dependencies only point backwards so the graph is acyclic
+// by construction, and totalScore is the exact sum of subtask scores.
+func (manifest Manifest) validateSubtasks(caseIDs map[string]struct{}) error {
+	if manifest.JudgeMode != JudgeModeOI {
+		return fmt.Errorf("manifest v3 subtasks require OI")
+	}
+	if len(manifest.Subtasks) == 0 || len(manifest.Subtasks) > maxSubtasks {
+		return fmt.Errorf("manifest subtasks must contain 1..%d entries", maxSubtasks)
+	}
+	subtaskIDs := make(map[string]struct{}, len(manifest.Subtasks))
+	covered := make(map[string]struct{}, len(caseIDs))
+	var scoreSum int64
+	for index, subtask := range manifest.Subtasks {
+		if !caseIDPattern.MatchString(subtask.ID) {
+			return fmt.Errorf("subtask %d has invalid id", index)
+		}
+		if _, exists := subtaskIDs[subtask.ID]; exists {
+			return fmt.Errorf("duplicate subtask id %q", subtask.ID)
+		}
+		if subtask.Score <= 0 || subtask.Score > maxTotalScore {
+			return fmt.Errorf("subtask %q score is outside supported bounds", subtask.ID)
+		}
+		scoreSum += int64(subtask.Score)
+		if scoreSum > maxTotalScore {
+			return fmt.Errorf("subtask scores exceed supported total")
+		}
+		if subtask.Aggregation != SubtaskAggregationMin && subtask.Aggregation != SubtaskAggregationSum {
+			return fmt.Errorf("subtask %q aggregation %q is unsupported", subtask.ID, subtask.Aggregation)
+		}
+		if len(subtask.Cases) == 0 || len(subtask.Cases) > maxCases {
+			return fmt.Errorf("subtask %q must reference 1..%d cases", subtask.ID, maxCases)
+		}
+		members := make(map[string]struct{}, len(subtask.Cases))
+		for _, caseID := range subtask.Cases {
+			if _, exists := caseIDs[caseID]; !exists {
+				return fmt.Errorf("subtask %q references unknown case %q", subtask.ID, caseID)
+			}
+			if _, exists := members[caseID]; exists {
+				return fmt.Errorf("subtask %q references case %q twice", subtask.ID, caseID)
+			}
+			members[caseID] = struct{}{}
+			covered[caseID] = struct{}{}
+		}
+		dependencies := make(map[string]struct{}, len(subtask.Dependencies))
+		for _, dependency := range subtask.Dependencies {
+			if _, exists := subtaskIDs[dependency]; !exists {
+				return fmt.Errorf("subtask %q dependency %q must name an earlier subtask", subtask.ID, dependency)
+			}
+			if _, exists := dependencies[dependency]; exists {
+				return fmt.Errorf("subtask %q repeats dependency %q", subtask.ID, dependency)
+			}
+			dependencies[dependency] = struct{}{}
+		}
+		subtaskIDs[subtask.ID] = struct{}{}
+	}
+	if len(covered) != len(caseIDs) {
+		return fmt.Errorf("every case must belong to at least one subtask")
+	}
+	if manifest.TotalScore == nil || int64(*manifest.TotalScore) != scoreSum {
+		return fmt.Errorf("OI totalScore must equal the sum of subtask scores")
+	}
+	return nil
+}
+
 func validateArtifactPath(name string) error {
 	if name == "" || len(name) > 512 || !utf8.ValidString(name) || strings.ContainsRune(name, '\x00') || strings.Contains(name, `\`) || strings.HasPrefix(name, "/") {
 		return fmt.Errorf("invalid artifact path")
@@ -8,6 +8,7 @@ import (
 )
 
 const validManifest = `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1500,"memoryLimitMiB":256},"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":1}]}`
+const validOIManifestV3 = `{"schemaVersion":3,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1500,"memoryLimitMiB":256},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1},{"id":"b","input":"b.in","output":"b.out","weight":1},{"id":"c","input":"c.in","output":"c.out","weight":2}],"subtasks":[{"id":"small","score":40,"aggregation":"min","cases":["a","b"]},{"id":"large","score":60,"aggregation":"sum","cases":["b","c"],"dependencies":["small"]}]}`
 const validOIManifestV2 = `{"schemaVersion":2,"judgeMode":"OI","checker":"token","limits":{"timeLimitMillis":1500,"memoryLimitMiB":256},"totalScore":100,"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":30},{"id":"case-02","input":"cases/02.in","output":"cases/02.out","weight":70}]}`
 
 func TestParseManifestV1(t *testing.T) {
@@ -37,6 +38,21 @@ func TestParseManifestV2OI(t *testing.T) {
 	}
 }
 
+func TestParseManifestV3Subtasks(t *testing.T) {
+	manifest, err := ParseManifest([]byte(validOIManifestV3))
+	if err != nil {
+		t.Fatalf("ParseManifest: %v", err)
+	}
+	if manifest.SchemaVersion != 3 || len(manifest.Subtasks) != 2 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	large := manifest.Subtasks[1]
+	if large.ID != "large" || large.Score != 60 || large.Aggregation != SubtaskAggregationSum ||
+		!reflect.DeepEqual(large.Cases, []string{"b", "c"}) || !reflect.DeepEqual(large.Dependencies, []string{"small"}) {
+		t.Fatalf("subtask = %+v", large)
+	}
+}
+
 func TestParseManifestV2SpecialJudge(t *testing.T) {
 	source := []byte("package main")
 	digest := sha256.Sum256(source)
@@ -54,13 +70,55 @@ func TestParseManifestV2SpecialJudge(t *testing.T) {
 	}
 }
 
+func TestParseManifestV2Interactive(t *testing.T) {
+	body := `{"schemaVersion":2,"judgeMode":"OI","checker":"interactive","limits":{"timeLimitMillis":1000,"memoryLimitMiB":256},"totalScore":10,"interactor":{"language":"cpp","source":"interactor/main.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":3000,"memoryLimitMiB":128},"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":10}]}`
+	manifest, err := ParseManifest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseManifest: %v", err)
+	}
+	if manifest.Checker != CheckerInteractive || manifest.Interactor == nil || manifest.SpecialJudge != nil ||
+		manifest.Interactor.Language != "cpp" || manifest.Interactor.TimeLimitMillis != 3000 {
+		t.Fatalf("manifest = %+v interactor = %+v", manifest, manifest.Interactor)
+	}
+}
+
+func TestParseManifestV2Graders(t *testing.T) {
+	body := `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":256},"graders":[{"language":"cpp","sources":[{"name":"grader.cpp","source":"grader/cpp/grader.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}],"headers":[{"name":"solution.h","source":"grader/cpp/solution.h","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]},{"language":"python","sources":[{"name":"grader.py","source":"grader/python/grader.py","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":1}]}`
+	manifest, err := ParseManifest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseManifest: %v", err)
+	}
+	grader, ok := manifest.GraderFor("cpp")
+	if !ok || len(grader.Sources) != 1 || len(grader.Headers) != 1 || grader.Headers[0].Name != "solution.h" {
+		t.Fatalf("cpp grader = %+v, %v", grader, ok)
+	}
+	if !manifest.SupportsLanguage("python") || manifest.SupportsLanguage("java") {
+		t.Fatal("grader bundle must accept exactly its declared languages")
+	}
+	if full := (Manifest{}); !full.SupportsLanguage("java") {
+		t.Fatal("full-program bundle must accept every language")
+	}
+}
+
+func TestParseManifestV2FloatTolerance(t *testing.T) {
+	body := `{"schemaVersion":2,"judgeMode":"ACM","checker":"float","limits":{"timeLimitMillis":1000,"memoryLimitMiB":256},"floatTolerance":{"absoluteEpsilon":1e-6,"relativeEpsilon":0},"cases":[{"id":"case-01","input":"cases/01.in","output":"cases/01.out","weight":1}]}`
+	manifest, err := ParseManifest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseManifest: %v", err)
+	}
+	if manifest.Checker != CheckerFloat || manifest.FloatTolerance == nil ||
+		manifest.FloatTolerance.AbsoluteEpsilon != 1e-6 || manifest.FloatTolerance.RelativeEpsilon != 0 {
+		t.Fatalf("manifest = %+v tolerance = %+v", manifest, manifest.FloatTolerance)
+	}
+}
+
 func TestParseManifestRejectsInvalidContract(t *testing.T) {
 	tests := map[string]string{
 		"missing limits": `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
 		"zero time":      `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":0,"memoryLimitMiB":256},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
 		"zero memory":    `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":0},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
 		"unknown field":  `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","cases":[],"secret":"x"}`,
-		"unsupported v3": `{"schemaVersion":3,"judgeMode":"ACM","checker":"exact","cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"unsupported v4": `{"schemaVersion":4,"judgeMode":"ACM","checker":"exact","cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
 		"empty cases":    `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","cases":[]}`,
 		"duplicate id":   `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","cases":[{"id":"a","input":"a.in","output":"a.out","weight":1},{"id":"a","input":"b.in","output":"b.out","weight":1}]}`,
 		"duplicate path": `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","cases":[{"id":"a","input":"a.in","output":"a.out","weight":1},{"id":"b","input":"a.in","output":"b.out","weight":1}]}`,
@@ -82,19 +140,65 @@ func TestParseManifestRejectsInvalidContract(t *testing.T) {
 func TestParseManifestRejectsInvalidV2ScoringAndCheckerContracts(t *testing.T) {
 	validSpecial := `"specialJudge":{"language":"go","source":"checker/main.go","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128}`
 	tests := map[string]string{
-		"OI missing total":          `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"OI score mismatch":         `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":99}]}`,
-		"OI zero weight":            `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":0},{"id":"b","input":"b.in","output":"b.out","weight":100}]}`,
-		"ACM total score":           `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"ACM non-unit weight":       `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":2}]}`,
-		"v1 OI remains closed":      `{"schemaVersion":1,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":1,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"v1 special remains closed": `{"schemaVersion":1,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},` + validSpecial + `,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"special missing config":    `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"config for exact":          `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},` + validSpecial + `,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"unknown language":          `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"ruby","source":"checker/main.rb","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"bad source digest":         `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"go","source":"checker/main.go","sourceSha256":"abc","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"source duplicates case":    `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"go","source":"a.in","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
-		"checker limit too high":    `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"go","source":"checker/main.go","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":86400001,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"OI missing total":           `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"OI score mismatch":          `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":99}]}`,
+		"OI zero weight":             `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":0},{"id":"b","input":"b.in","output":"b.out","weight":100}]}`,
+		"ACM total score":            `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":100,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"ACM non-unit weight":        `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":2}]}`,
+		"v1 OI remains closed":       `{"schemaVersion":1,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":1,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"v1 special remains closed":  `{"schemaVersion":1,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},` + validSpecial + `,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"special missing config":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"config for exact":           `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},` + validSpecial + `,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"unknown language":           `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"ruby","source":"checker/main.rb","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"bad source digest":          `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"go","source":"checker/main.go","sourceSha256":"abc","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"source duplicates case":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"go","source":"a.in","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"interactive missing config": `{"schemaVersion":2,"judgeMode":"ACM","checker":"interactive","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"interactor for special":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},` + validSpecial + `,"interactor":{"language":"go","source":"interactor/main.go","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"v1 interactive closed":      `{"schemaVersion":1,"judgeMode":"ACM","checker":"interactive","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"interactor":{"language":"go","source":"interactor/main.go","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"interactor bad digest":      `{"schemaVersion":2,"judgeMode":"ACM","checker":"interactive","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"interactor":{"language":"go","source":"interactor/main.go","sourceSha256":"ABC","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"interactor duplicates case": `{"schemaVersion":2,"judgeMode":"ACM","checker":"interactive","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"interactor":{"language":"go","source":"a.out","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":2000,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"v1 graders closed":          `{"schemaVersion":1,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[{"name":"grader.cpp","source":"grader.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader without sources":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[],"headers":[{"name":"solution.h","source":"solution.h","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader unknown language":    `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"ruby","sources":[{"name":"grader.rb","source":"grader.rb","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader duplicate language":  `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[{"name":"a.cpp","source":"a.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]},{"language":"cpp","sources":[{"name":"b.cpp","source":"b.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader name traversal":      `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[{"name":"../grader.cpp","source":"grader.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader repeated name":       `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[{"name":"grader.cpp","source":"a.cpp","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}],"headers":[{"name":"grader.cpp","source":"b.h","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader bad digest":          `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[{"name":"grader.cpp","source":"grader.cpp","sourceSha256":"abc"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"grader duplicates case":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"graders":[{"language":"cpp","sources":[{"name":"grader.cpp","source":"a.in","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}]}],"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"float missing tolerance":    `{"schemaVersion":2,"judgeMode":"ACM","checker":"float","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"float zero tolerance":       `{"schemaVersion":2,"judgeMode":"ACM","checker":"float","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"floatTolerance":{"absoluteEpsilon":0,"relativeEpsilon":0},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"float negative epsilon":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"float","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"floatTolerance":{"absoluteEpsilon":-1e-6,"relativeEpsilon":1e-6},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"float epsilon too large":    `{"schemaVersion":2,"judgeMode":"ACM","checker":"float","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"floatTolerance":{"absoluteEpsilon":2,"relativeEpsilon":0},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"tolerance for exact":        `{"schemaVersion":2,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"floatTolerance":{"absoluteEpsilon":1e-6,"relativeEpsilon":0},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"v1 float closed":            `{"schemaVersion":1,"judgeMode":"ACM","checker":"float","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"floatTolerance":{"absoluteEpsilon":1e-6,"relativeEpsilon":0},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+		"checker limit too high":     `{"schemaVersion":2,"judgeMode":"ACM","checker":"special","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"specialJudge":{"language":"go","source":"checker/main.go","sourceSha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","timeLimitMillis":86400001,"memoryLimitMiB":128},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}]}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseManifest([]byte(body)); err == nil {
+				t.Fatal("expected manifest error")
+			}
+		})
+	}
+}
+
+func TestParseManifestRejectsInvalidV3Subtasks(t *testing.T) {
+	prefix := `{"schemaVersion":3,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":10,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1},{"id":"b","input":"b.in","output":"b.out","weight":1}],`
+	tests := map[string]string{
+		"missing subtasks":      prefix + `"subtasks":[]}`,
+		"ACM subtasks":          `{"schemaVersion":3,"judgeMode":"ACM","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}],"subtasks":[{"id":"s","score":1,"aggregation":"min","cases":["a"]}]}`,
+		"v2 subtasks":           `{"schemaVersion":2,"judgeMode":"OI","checker":"exact","limits":{"timeLimitMillis":1000,"memoryLimitMiB":64},"totalScore":1,"cases":[{"id":"a","input":"a.in","output":"a.out","weight":1}],"subtasks":[{"id":"s","score":1,"aggregation":"min","cases":["a"]}]}`,
+		"score mismatch":        prefix + `"subtasks":[{"id":"s","score":9,"aggregation":"min","cases":["a","b"]}]}`,
+		"zero score":            prefix + `"subtasks":[{"id":"s","score":0,"aggregation":"min","cases":["a"]},{"id":"t","score":10,"aggregation":"min","cases":["b"]}]}`,
+		"unknown aggregation":   prefix + `"subtasks":[{"id":"s","score":10,"aggregation":"max","cases":["a","b"]}]}`,
+		"unknown case":          prefix + `"subtasks":[{"id":"s","score":10,"aggregation":"min","cases":["a","b","z"]}]}`,
+		"repeated case":         prefix + `"subtasks":[{"id":"s","score":10,"aggregation":"min","cases":["a","b","a"]}]}`,
+		"uncovered case":        prefix + `"subtasks":[{"id":"s","score":10,"aggregation":"min","cases":["a"]}]}`,
+		"duplicate id":          prefix + `"subtasks":[{"id":"s","score":5,"aggregation":"min","cases":["a"]},{"id":"s","score":5,"aggregation":"min","cases":["b"]}]}`,
+		"forward dependency":    prefix + `"subtasks":[{"id":"s","score":5,"aggregation":"min","cases":["a"],"dependencies":["t"]},{"id":"t","score":5,"aggregation":"min","cases":["b"]}]}`,
+		"self dependency":       prefix + `"subtasks":[{"id":"s","score":10,"aggregation":"min","cases":["a","b"],"dependencies":["s"]}]}`,
+		"repeated dependency":   prefix + `"subtasks":[{"id":"s","score":5,"aggregation":"min","cases":["a"]},{"id":"t","score":5,"aggregation":"min","cases":["b"],"dependencies":["s","s"]}]}`,
+		"unknown subtask field": prefix + `"subtasks":[{"id":"s","score":10,"aggregation":"min","cases":["a","b"],"weight":1}]}`,
 	}
 	for name, body := range tests {
 		t.Run(name, func(t *testing.T) {
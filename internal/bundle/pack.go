@@ -25,17 +25,14 @@ func WriteDeterministicArchive(destination io.Writer, manifest Manifest, files m
 	if err != nil {
 		return nil, fmt.Errorf("encode canonical manifest: %w", err)
 	}
-	referencedCount := len(manifest.Cases) * 2
-	if manifest.SpecialJudge != nil {
-		referencedCount++
-	}
-	referenced := make(map[string]struct{}, referencedCount)
+	programPaths := manifest.programPaths()
+	referenced := make(map[string]struct{}, len(manifest.Cases)*2+len(programPaths))
 	for _, testCase := range manifest.Cases {
 		referenced[testCase.Input] = struct{}{}
 		referenced[testCase.Output] = struct{}{}
 	}
-	if manifest.SpecialJudge != nil {
-		referenced[manifest.SpecialJudge.Source] = struct{}{}
+	for _, name := range programPaths {
+		referenced[name] = struct{}{}
 	}
 	if len(files) != len(referenced) {
 		return nil, fmt.Errorf("archive files do not exactly match manifest cases")
@@ -24,6 +24,11 @@ const (
 	StatusRuntimeError        Status = "RUNTIME_ERROR"
 	StatusOutputLimitExceeded Status = "OUTPUT_LIMIT_EXCEEDED"
 	StatusSystemError         Status = "SYSTEM_ERROR"
+	// StatusIdlenessLimitExceeded is an interactive case in which the
+	// contestant and the interactor both waited for the other. It is only
+	// produced by the external interactive pipeline, never sent to the
+	// legacy backend.
+	StatusIdlenessLimitExceeded Status = "IDLENESS_LIMIT_EXCEEDED"
 )
 
 type Result struct {
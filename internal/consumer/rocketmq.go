@@ -3,7 +3,7 @@ package consumer
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"sort"
 	"strings"
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/config"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/model"
 	"github.com/apache/rocketmq-client-go/v2"
@@ -103,17 +104,17 @@ func (resolver *rocketMQNameServerResolver) Resolve() []string {
 
 		addresses, err := resolver.lookup.LookupHost(ctx, endpoint.host)
 		if err != nil {
-			log.Printf("rocketmq name-server DNS refresh failed for %s: %v", endpoint.host, err)
+			slog.Warn("rocketmq name-server DNS refresh failed", "host", endpoint.host, "error", err)
 			return resolver.lastKnownGood()
 		}
 		if len(addresses) == 0 {
-			log.Printf("rocketmq name-server DNS refresh returned no addresses for %s", endpoint.host)
+			slog.Warn("rocketmq name-server DNS refresh returned no addresses", "host", endpoint.host)
 			return resolver.lastKnownGood()
 		}
 		for _, address := range addresses {
 			ip := net.ParseIP(strings.TrimSpace(address))
 			if ip == nil {
-				log.Printf("rocketmq name-server DNS refresh returned invalid IP for %s", endpoint.host)
+				slog.Warn("rocketmq name-server DNS refresh returned an invalid IP", "host", endpoint.host)
 				return resolver.lastKnownGood()
 			}
 			resolved[net.JoinHostPort(ip.String(), endpoint.port)] = struct{}{}
@@ -147,7 +148,7 @@ func (resolver *rocketMQNameServerResolver) lastKnownGood() []string {
 
 // NewRocketMQConsumer 创建一个新的 RocketMQ 消费者
 func NewRocketMQConsumer(cfg config.RocketMQConfig, processor EventProcessor) (*RocketMQConsumer, error) {
-	fmt.Println("Initializing RocketMQ Consumer...")
+	slog.Info("Initializing RocketMQ consumer", "topic", cfg.Topic, "group", cfg.Consumer.Group)
 
 	if cfg.NameServer == "" {
 		return nil, fmt.Errorf("rocketmq name-server is not configured")
@@ -198,33 +199,45 @@ func (rc *RocketMQConsumer) handleMessage(ctx context.Context, msgs ...*primitiv
 	for _, msg := range msgs {
 		event, err := DecodeSubmissionRequested(msg.Body)
 		if err != nil {
-			log.Printf("discarding invalid SubmissionRequested message: %v", err)
+			slog.WarnContext(ctx, "discarding invalid SubmissionRequested message", "message_id", msg.MsgId, "error", err)
+			metrics.RecordLegacyConsume("invalid")
 			continue
 		}
 		if err := rc.processor.ProcessEvent(ctx, event); err != nil {
 			if callback.IsPermanent(err) {
-				log.Printf("judge event %s submission=%d attempt=%d rejected permanently: %v",
-					event.EventID, event.SubmissionID, event.AttemptNo, err)
+				slog.ErrorContext(ctx, "judge event rejected permanently", legacyEventAttributes(event, err)...)
+				metrics.RecordLegacyConsume("rejected")
 				continue
 			}
-			log.Printf("judge event %s submission=%d attempt=%d will retry: %v",
-				event.EventID, event.SubmissionID, event.AttemptNo, err)
+			slog.WarnContext(ctx, "judge event will retry", legacyEventAttributes(event, err)...)
+			metrics.RecordLegacyConsume("retry")
 			return consumer.ConsumeRetryLater, nil
 		}
+		metrics.RecordLegacyConsume("processed")
 	}
 	return consumer.ConsumeSuccess, nil
 }
 
+// legacyEventAttributes 日志中只记录事件标识，不包含用户提交内容
+func legacyEventAttributes(event model.SubmissionRequested, err error) []any {
+	return []any{
+		"event_id", event.EventID,
+		"submission", event.SubmissionID,
+		"attempt", event.AttemptNo,
+		"error", err,
+	}
+}
+
 // Start 启动消费者
 func (rc *RocketMQConsumer) Start() error {
-	fmt.Println("Starting RocketMQ Consumer...")
+	slog.Info("Starting RocketMQ consumer", "topic", rc.topic)
 	return rc.consumer.Start()
 	// return nil // 临时返回
 }
 
 // Shutdown 关闭消费者
 func (rc *RocketMQConsumer) Shutdown() error {
-	fmt.Println("Shutting down RocketMQ Consumer...")
+	slog.Info("Shutting down RocketMQ consumer", "topic", rc.topic)
 	return rc.consumer.Shutdown()
 	// return nil // 临时返回
 }
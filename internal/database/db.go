@@ -3,6 +3,7 @@ package database
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/CodeRushOJ/croj-judging-server/pkg/config"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/model"
@@ -17,7 +18,7 @@ type Database struct {
 
 // NewDatabase 创建一个新的数据库连接
 func NewDatabase(cfg config.DatabaseConfig) (*Database, error) {
-	fmt.Println("Connecting to database...")
+	slog.Info("Connecting to database", "host", cfg.Host, "database", cfg.Name)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		cfg.User,
 		cfg.Password,
@@ -36,18 +37,18 @@ func NewDatabase(cfg config.DatabaseConfig) (*Database, error) {
 // GetSubmissionByID 从数据库中获取提交记录
 // 函数名保留，但内部使用 model.Task
 func (d *Database) GetSubmissionByID(submissionID int64) (*model.Task, error) {
-	fmt.Printf("Getting submission %d from database...\\n", submissionID)
+	slog.Debug("Getting submission from database", "submission", submissionID)
 	submission := &model.Task{} // 使用 model.Task
 	// model.Task 结构体已通过 TableName() 指定映射到 t_submission 表
 	result := d.DB.First(submission, submissionID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			fmt.Printf("Submission %d not found in database.\\n", submissionID)
+			slog.Debug("Submission not found in database", "submission", submissionID)
 			return nil, nil // 明确返回 nil, nil 表示未找到
 		}
 		return nil, fmt.Errorf("failed to get submission %d: %w", submissionID, result.Error)
 	}
-	fmt.Printf("Successfully retrieved submission %d. ProblemID: %d, Status: %d\\n", submissionID, submission.ProblemID, submission.Status)
+	slog.Debug("Retrieved submission", "submission", submissionID, "problem", submission.ProblemID, "status", submission.Status)
 	return submission, nil
 }
 
@@ -95,7 +96,7 @@ func (d *Database) GetTestBundleByProblemVersionID(problemVersionID int64) (*mod
 
 // Close 关闭数据库连接
 func (d *Database) Close() error {
-	fmt.Println("Closing database connection...")
+	slog.Info("Closing database connection")
 	sqlDB, err := d.DB.DB()
 	if err != nil {
 		return err
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"log/slog"
 	"strings"
 )
 
@@ -24,6 +25,12 @@ type APIKeyMaterial struct {
 
 func (APIKeyMaterial) String() string { return "[REDACTED API KEY]" }
 
+// LogValue keeps the public lookup prefix so an operator can identify the
+// key without the secret half ever reaching a log sink.
+func (material APIKeyMaterial) LogValue() slog.Value {
+	return slog.StringValue("[REDACTED API KEY " + material.LookupPrefix + "]")
+}
+
 func GenerateAPIKey(random io.Reader, pepper []byte) (APIKeyMaterial, error) {
 	if random == nil {
 		return APIKeyMaterial{}, fmt.Errorf("cryptographic random source is required")
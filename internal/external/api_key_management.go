@@ -0,0 +1,229 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MaximumAPIKeyRotationOverlap bounds how long a rotated key keeps working
+// next to its replacement.
+const MaximumAPIKeyRotationOverlap = 7 * 24 * time.Hour
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key does not exist")
+	ErrAPIKeyInactive = errors.New("API key is revoked or expired")
+
+	// apiKeyLookupPrefixPattern accepts every prefix the authenticator can
+	// look up, including keys issued before the current generator.
+	apiKeyLookupPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9]{8,24}$`)
+)
+
+type APIKeyStatus string
+
+const (
+	APIKeyActive  APIKeyStatus = "ACTIVE"
+	APIKeyExpired APIKeyStatus = "EXPIRED"
+	APIKeyRevoked APIKeyStatus = "REVOKED"
+)
+
+// APIKeySummary is the operator view of one API key. The secret and its
+// digest are never read back. Status is evaluated against the database
+// clock, the same clock that revocation and rotation write.
+type APIKeySummary struct {
+	LookupPrefix string
+	Status       APIKeyStatus
+	Scopes       []Scope
+	CreatedAt    time.Time
+	ExpiresAt    *time.Time
+	RevokedAt    *time.Time
+	LastUsedAt   *time.Time
+}
+
+// APIKeyRotation is the result of RotateAPIKey. PreviousValidUntil is when
+// the rotated key stops authenticating.
+type APIKeyRotation struct {
+	PreviousPrefix     string
+	PreviousValidUntil time.Time
+	Replacement        APIKeyMaterial
+}
+
+// ListAPIKeys returns every key of a tenant, including revoked and expired
+// ones, oldest first. LastUsedAt lags real use by up to one flush interval
+// of the serving processes' APIKeyUsageRecorder.
+func (provisioner *Provisioner) ListAPIKeys(ctx context.Context, tenantID string) ([]APIKeySummary, error) {
+	if provisioner == nil || provisioner.executor == nil {
+		return nil, fmt.Errorf("provisioner is not configured")
+	}
+	if !externalIDPattern.MatchString(tenantID) {
+		return nil, ErrTenantNotFound
+	}
+	rows, err := provisioner.executor.QueryContext(ctx, `
+SELECT api_key.lookup_prefix,
+       CASE WHEN api_key.revoked_at IS NOT NULL THEN 'REVOKED'
+            WHEN api_key.expires_at <= CURRENT_TIMESTAMP(3) THEN 'EXPIRED'
+            ELSE 'ACTIVE' END,
+       api_key.scopes_json, api_key.created_at, api_key.expires_at, api_key.revoked_at, api_key.last_used_at
+FROM t_external_tenant AS tenant
+LEFT JOIN t_external_api_key AS api_key ON api_key.tenant_id = tenant.id
+WHERE tenant.external_id = ?
+ORDER BY api_key.created_at, api_key.id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list API keys: %w", err)
+	}
+	defer rows.Close()
+	tenantFound := false
+	keys := make([]APIKeySummary, 0)
+	for rows.Next() {
+		tenantFound = true
+		var prefix, status sql.NullString
+		var encodedScopes []byte
+		var createdAt, expiresAt, revokedAt, lastUsedAt sql.NullTime
+		if err := rows.Scan(&prefix, &status, &encodedScopes, &createdAt, &expiresAt, &revokedAt, &lastUsedAt); err != nil {
+			return nil, fmt.Errorf("scan API key: %w", err)
+		}
+		if !prefix.Valid {
+			// The outer join yields one all-NULL row for a tenant without keys.
+			continue
+		}
+		scopes, err := decodeScopes(encodedScopes)
+		if err != nil {
+			return nil, fmt.Errorf("scopes of API key %s: %w", prefix.String, err)
+		}
+		keys = append(keys, APIKeySummary{
+			LookupPrefix: prefix.String,
+			Status:       APIKeyStatus(status.String),
+			Scopes:       scopes,
+			CreatedAt:    createdAt.Time.UTC(),
+			ExpiresAt:    nullableTimePointer(expiresAt),
+			RevokedAt:    nullableTimePointer(revokedAt),
+			LastUsedAt:   nullableTimePointer(lastUsedAt),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list API keys: %w", err)
+	}
+	if !tenantFound {
+		return nil, ErrTenantNotFound
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops a key at once: the authenticator reads the key row on
+// every request and rejects revoked keys. Keys of disabled tenants can be
+// revoked too. Revoking a revoked key changes nothing and records no audit
+// entry.
+func (provisioner *Provisioner) RevokeAPIKey(ctx context.Context, tenantID, lookupPrefix string) error {
+	if !apiKeyLookupPrefixPattern.MatchString(lookupPrefix) {
+		return ErrAPIKeyNotFound
+	}
+	return provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, _ TenantSummary) error {
+		var keyID uint64
+		var revoked bool
+		err := tx.QueryRowContext(ctx, `
+SELECT id, revoked_at IS NOT NULL
+FROM t_external_api_key
+WHERE tenant_id = ? AND lookup_prefix = ?
+FOR UPDATE`, internalID, lookupPrefix).Scan(&keyID, &revoked)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAPIKeyNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("read API key: %w", err)
+		}
+		if revoked {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE t_external_api_key SET revoked_at = CURRENT_TIMESTAMP(3) WHERE id = ?", keyID); err != nil {
+			return fmt.Errorf("revoke API key: %w", err)
+		}
+		return insertAdminAudit(ctx, tx, internalID, "api_key.revoked", lookupPrefix, struct{}{})
+	})
+}
+
+// RotateAPIKey issues a replacement with the same scopes and expiry as an
+// active key and lets the old key keep working for overlap, so clients can
+// roll over without an outage. The old key's expiry is only ever brought
+// forward; an overlap of zero revokes it in the same transaction.
+func (provisioner *Provisioner) RotateAPIKey(
+	ctx context.Context,
+	tenantID string,
+	lookupPrefix string,
+	overlap time.Duration,
+	pepper []byte,
+) (APIKeyRotation, error) {
+	if provisioner == nil || provisioner.random == nil {
+		return APIKeyRotation{}, fmt.Errorf("provisioner is not configured")
+	}
+	if overlap < 0 || overlap > MaximumAPIKeyRotationOverlap {
+		return APIKeyRotation{}, fmt.Errorf("API key rotation overlap must be between 0 and %s", MaximumAPIKeyRotationOverlap)
+	}
+	if !apiKeyLookupPrefixPattern.MatchString(lookupPrefix) {
+		return APIKeyRotation{}, ErrAPIKeyNotFound
+	}
+	rotation := APIKeyRotation{PreviousPrefix: lookupPrefix}
+	err := provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		if tenant.Status != TenantActive {
+			return ErrTenantDisabled
+		}
+		var keyID uint64
+		var encodedScopes []byte
+		var expiresAt sql.NullTime
+		var active bool
+		err := tx.QueryRowContext(ctx, `
+SELECT id, scopes_json, expires_at,
+       revoked_at IS NULL AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP(3))
+FROM t_external_api_key
+WHERE tenant_id = ? AND lookup_prefix = ?
+FOR UPDATE`, internalID, lookupPrefix).Scan(&keyID, &encodedScopes, &expiresAt, &active)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAPIKeyNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("read API key: %w", err)
+		}
+		if !active {
+			return ErrAPIKeyInactive
+		}
+		material, err := GenerateAPIKey(provisioner.random, pepper)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_api_key(tenant_id, lookup_prefix, key_digest, scopes_json, expires_at)
+VALUES (?, ?, ?, ?, ?)`, internalID, material.LookupPrefix, material.Digest, encodedScopes, expiresAt); err != nil {
+			return fmt.Errorf("create replacement API key: %w", err)
+		}
+		statement := `
+UPDATE t_external_api_key
+SET expires_at = LEAST(COALESCE(expires_at, CURRENT_TIMESTAMP(3) + INTERVAL ? MICROSECOND),
+                       CURRENT_TIMESTAMP(3) + INTERVAL ? MICROSECOND)
+WHERE id = ?`
+		arguments := []any{overlap.Microseconds(), overlap.Microseconds(), keyID}
+		if overlap == 0 {
+			statement, arguments = "UPDATE t_external_api_key SET revoked_at = CURRENT_TIMESTAMP(3) WHERE id = ?", []any{keyID}
+		}
+		if _, err := tx.ExecContext(ctx, statement, arguments...); err != nil {
+			return fmt.Errorf("end rotated API key: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx,
+			"SELECT COALESCE(revoked_at, expires_at) FROM t_external_api_key WHERE id = ?", keyID).
+			Scan(&rotation.PreviousValidUntil); err != nil {
+			return fmt.Errorf("read rotated API key: %w", err)
+		}
+		rotation.PreviousValidUntil = rotation.PreviousValidUntil.UTC()
+		rotation.Replacement = material
+		return insertAdminAudit(ctx, tx, internalID, "api_key.rotated", lookupPrefix, map[string]any{
+			"replacementPrefix":  material.LookupPrefix,
+			"overlapSeconds":     int64(overlap / time.Second),
+			"previousValidUntil": rotation.PreviousValidUntil,
+		})
+	})
+	if err != nil {
+		return APIKeyRotation{}, err
+	}
+	return rotation, nil
+}
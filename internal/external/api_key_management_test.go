@@ -0,0 +1,106 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvisionerAPIKeyRotationOverlapsAndRevocationIsImmediate(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("k", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, strings.Repeat("m", 26), "", 10)
+	provisioner, err := NewProvisioner(database, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pepper := bytes.Repeat([]byte{0x42}, sha256.Size)
+	original, err := provisioner.CreateAPIKey(context.Background(), tenantID, []Scope{ScopeJobSubmit, ScopeJobRead}, nil, pepper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewAPIKeyUsageRecorder(database, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usedAt := time.Now().UTC().Truncate(time.Millisecond)
+	recorder.RecordCredentialUse(original.LookupPrefix, usedAt)
+	if err := recorder.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	rotation, err := provisioner.RotateAPIKey(context.Background(), tenantID, original.LookupPrefix, time.Hour, pepper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Replacement.LookupPrefix == original.LookupPrefix || time.Until(rotation.PreviousValidUntil) <= 50*time.Minute ||
+		time.Until(rotation.PreviousValidUntil) > 70*time.Minute {
+		t.Fatalf("rotation = %+v", rotation)
+	}
+	store, err := NewSQLCredentialStore(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prefix := range []string{original.LookupPrefix, rotation.Replacement.LookupPrefix} {
+		credential, err := store.FindCredentialByPrefix(context.Background(), prefix)
+		if err != nil || credential == nil || credential.RevokedAt != nil || len(credential.Scopes) != 2 {
+			t.Fatalf("credential %s = %+v error=%v", prefix, credential, err)
+		}
+	}
+	keys, err := provisioner.ListAPIKeys(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].LookupPrefix != original.LookupPrefix || keys[0].Status != APIKeyActive || keys[0].ExpiresAt == nil ||
+		keys[0].LastUsedAt == nil || !keys[0].LastUsedAt.Equal(usedAt) || keys[1].ExpiresAt != nil || keys[1].LastUsedAt != nil {
+		t.Fatalf("keys = %+v", keys)
+	}
+
+	if err := provisioner.RevokeAPIKey(context.Background(), tenantID, original.LookupPrefix); err != nil {
+		t.Fatal(err)
+	}
+	if err := provisioner.RevokeAPIKey(context.Background(), tenantID, original.LookupPrefix); err != nil {
+		t.Fatalf("repeated revoke = %v", err)
+	}
+	credential, err := store.FindCredentialByPrefix(context.Background(), original.LookupPrefix)
+	if err != nil || credential == nil || credential.RevokedAt == nil {
+		t.Fatalf("revoked credential = %+v error=%v", credential, err)
+	}
+	if _, err := provisioner.RotateAPIKey(context.Background(), tenantID, original.LookupPrefix, time.Hour, pepper); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Fatalf("rotate revoked key = %v", err)
+	}
+	immediate, err := provisioner.RotateAPIKey(context.Background(), tenantID, rotation.Replacement.LookupPrefix, 0, pepper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err = provisioner.ListAPIKeys(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[0].Status != APIKeyRevoked || keys[1].Status != APIKeyRevoked || keys[2].LookupPrefix != immediate.Replacement.LookupPrefix ||
+		keys[2].Status != APIKeyActive {
+		t.Fatalf("keys after immediate rotation = %+v", keys)
+	}
+
+	if err := provisioner.RevokeAPIKey(context.Background(), tenantID, "unknownprefix"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("unknown prefix = %v", err)
+	}
+	if err := provisioner.RevokeAPIKey(context.Background(), strings.Repeat("v", 26), immediate.Replacement.LookupPrefix); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("key of another tenant = %v", err)
+	}
+	if _, err := provisioner.ListAPIKeys(context.Background(), strings.Repeat("v", 26)); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("list unknown tenant = %v", err)
+	}
+	if count := mustCount(t, database, "SELECT COUNT(*) FROM t_external_admin_audit WHERE action = 'api_key.rotated'"); count != 2 {
+		t.Fatalf("rotation audit rows = %d", count)
+	}
+	if count := mustCount(t, database, "SELECT COUNT(*) FROM t_external_admin_audit WHERE action = 'api_key.revoked'"); count != 1 {
+		t.Fatalf("revocation audit rows = %d", count)
+	}
+}
@@ -0,0 +1,91 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// apiKeyUsageFinalFlushTimeout bounds the flush on shutdown so a stalled
+// database cannot hold the process open.
+const apiKeyUsageFinalFlushTimeout = 5 * time.Second
+
+type apiKeyUsageExecutor interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+}
+
+// APIKeyUsageRecorder keeps the latest successful authentication of each
+// key in memory and writes it to t_external_api_key.last_used_at once per
+// flush interval, so the request path never writes to the database. Several
+// serving processes may flush the same key; only a later time is written.
+type APIKeyUsageRecorder struct {
+	executor apiKeyUsageExecutor
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+func NewAPIKeyUsageRecorder(database *sql.DB, interval time.Duration) (*APIKeyUsageRecorder, error) {
+	if database == nil || interval < time.Second || interval > time.Hour {
+		return nil, fmt.Errorf("API key usage database and a flush interval between 1s and 1h are required")
+	}
+	return &APIKeyUsageRecorder{executor: database, interval: interval, pending: make(map[string]time.Time)}, nil
+}
+
+// RecordCredentialUse notes a successful authentication. Only keys that
+// authenticated are recorded, so the pending set is bounded by the number
+// of issued keys.
+func (recorder *APIKeyUsageRecorder) RecordCredentialUse(lookupPrefix string, usedAt time.Time) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if previous, ok := recorder.pending[lookupPrefix]; !ok || usedAt.After(previous) {
+		recorder.pending[lookupPrefix] = usedAt.UTC()
+	}
+}
+
+func (recorder *APIKeyUsageRecorder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(recorder.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), apiKeyUsageFinalFlushTimeout)
+			_ = recorder.flush(flushContext)
+			cancel()
+			return context.Cause(ctx)
+		case <-ticker.C:
+			if err := recorder.flush(ctx); err != nil && ctx.Err() == nil && !IsTransientDatabaseError(err) {
+				return err
+			}
+		}
+	}
+}
+
+// flush writes the pending uses in prefix order. Uses that could not be
+// written are kept for the next flush unless a later use replaced them.
+func (recorder *APIKeyUsageRecorder) flush(ctx context.Context) error {
+	recorder.mu.Lock()
+	pending := recorder.pending
+	recorder.pending = make(map[string]time.Time, len(pending))
+	recorder.mu.Unlock()
+
+	prefixes := slices.Sorted(maps.Keys(pending))
+	for index, prefix := range prefixes {
+		usedAt := pending[prefix]
+		if _, err := recorder.executor.ExecContext(ctx, `
+UPDATE t_external_api_key
+SET last_used_at = ?
+WHERE lookup_prefix = ? AND (last_used_at IS NULL OR last_used_at < ?)`, usedAt, prefix, usedAt); err != nil {
+			for _, unwritten := range prefixes[index:] {
+				recorder.RecordCredentialUse(unwritten, pending[unwritten])
+			}
+			return fmt.Errorf("record API key use: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+type apiKeyUsageExecutorStub struct {
+	arguments [][]any
+	err       error
+}
+
+func (executor *apiKeyUsageExecutorStub) ExecContext(_ context.Context, _ string, arguments ...any) (sql.Result, error) {
+	if executor.err != nil {
+		return nil, executor.err
+	}
+	executor.arguments = append(executor.arguments, arguments)
+	return nil, nil
+}
+
+func TestAPIKeyUsageRecorderWritesOnlyTheLatestUsePerKeyAndFlush(t *testing.T) {
+	executor := &apiKeyUsageExecutorStub{}
+	recorder := &APIKeyUsageRecorder{executor: executor, interval: time.Minute, pending: make(map[string]time.Time)}
+	base := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	for second := range 100 {
+		recorder.RecordCredentialUse("publickey2", base.Add(time.Duration(second)*time.Second))
+	}
+	recorder.RecordCredentialUse("publickey1", base)
+	recorder.RecordCredentialUse("publickey1", base.Add(-time.Hour))
+	if err := recorder.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(executor.arguments) != 2 || executor.arguments[0][1] != "publickey1" || !executor.arguments[0][0].(time.Time).Equal(base) ||
+		executor.arguments[1][1] != "publickey2" || !executor.arguments[1][0].(time.Time).Equal(base.Add(99*time.Second)) {
+		t.Fatalf("writes = %v", executor.arguments)
+	}
+	if err := recorder.flush(context.Background()); err != nil || len(executor.arguments) != 2 {
+		t.Fatalf("an idle flush wrote %v, error=%v", executor.arguments, err)
+	}
+}
+
+func TestAPIKeyUsageRecorderKeepsUnwrittenUsesForTheNextFlush(t *testing.T) {
+	executor := &apiKeyUsageExecutorStub{err: errors.New("connection refused")}
+	recorder := &APIKeyUsageRecorder{executor: executor, interval: time.Minute, pending: make(map[string]time.Time)}
+	usedAt := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	recorder.RecordCredentialUse("publickey1", usedAt)
+	if err := recorder.flush(context.Background()); err == nil {
+		t.Fatal("expected the failed write to be reported")
+	}
+	recorder.RecordCredentialUse("publickey1", usedAt.Add(-time.Minute))
+	executor.err = nil
+	if err := recorder.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(executor.arguments) != 1 || !executor.arguments[0][0].(time.Time).Equal(usedAt) {
+		t.Fatalf("writes = %v", executor.arguments)
+	}
+}
@@ -157,10 +157,23 @@ FOR UPDATE`, tenantInternalID, bundleUploadOperationScope, input.IdempotencyDige
 		return BundleCommitResult{}, fmt.Errorf("read bundle idempotency under lock: %w", err)
 	}
 
-	metadata, status, stagingKey, found, err := findBundleByDigest(ctx, transaction, tenantInternalID, input.RequestHash)
+	stored, found, err := findBundleByDigest(ctx, transaction, tenantInternalID, input.RequestHash)
 	if err != nil {
 		return BundleCommitResult{}, err
 	}
+	metadata, status, stagingKey := stored.metadata, stored.status, stored.stagingKey
+	if !found || stored.retired {
+		if stored.deleting {
+			return BundleCommitResult{}, ErrBundlePublishing
+		}
+		retained, err := retainedBundleCount(ctx, transaction, tenantInternalID)
+		if err != nil {
+			return BundleCommitResult{}, err
+		}
+		if retained >= policy.MaxRetainedBundles {
+			return BundleCommitResult{}, ErrBundleRetentionLimit
+		}
+	}
 	if !found {
 		metadata = input.Metadata
 		if _, err := transaction.ExecContext(ctx, `
@@ -171,17 +184,15 @@ INSERT INTO t_external_bundle(
 			metadata.SizeBytes, metadata.CaseCount, metadata.ManifestVersion, input.ManifestJSON, metadata.CreatedAt); err != nil {
 			return BundleCommitResult{}, fmt.Errorf("insert immutable bundle metadata: %w", err)
 		}
-	} else {
-		if bundlePublicationNeedsFreshStaging(status, stagingKey) {
-			if err := reviveBundlePublication(ctx, transaction, tenantInternalID, metadata.BundleID, input); err != nil {
-				return BundleCommitResult{}, err
-			}
-			status = BundlePublicationPending
-			stagingKey = input.StagingObjectKey
-		} else if _, err := transaction.ExecContext(ctx, `
-UPDATE t_external_bundle SET delete_marked_at = NULL
-WHERE tenant_id = ? AND external_id = ? AND deleted_at IS NULL`, tenantInternalID, metadata.BundleID); err != nil {
-			return BundleCommitResult{}, fmt.Errorf("retain existing immutable bundle: %w", err)
+	} else if stored.objectRemoved || bundlePublicationNeedsFreshStaging(status, stagingKey) {
+		if err := reviveBundlePublication(ctx, transaction, tenantInternalID, metadata.BundleID, input); err != nil {
+			return BundleCommitResult{}, err
+		}
+		status = BundlePublicationPending
+		stagingKey = input.StagingObjectKey
+	} else if stored.retired {
+		if err := restoreRetiredBundle(ctx, transaction, tenantInternalID, metadata.BundleID); err != nil {
+			return BundleCommitResult{}, err
 		}
 	}
 	if !found {
@@ -212,9 +223,11 @@ UPDATE t_external_bundle
 SET staging_object_key = ?, publication_status = 'PENDING', ready_at = NULL,
     publish_lease_token = NULL, publish_lease_until = NULL, publish_attempt_count = 0,
     publish_next_attempt_at = ?, publish_last_error_code = NULL, publish_abandoned_at = NULL,
-    delete_marked_at = NULL
-WHERE tenant_id = ? AND external_id = ? AND sha256 = ? AND deleted_at IS NULL
-  AND (publication_status = 'ABANDONED' OR staging_object_key IS NULL)`,
+    delete_marked_at = NULL, delete_token = NULL, delete_lease_until = NULL, delete_next_attempt_at = NULL,
+    delete_attempt_count = 0, delete_last_error_code = NULL, deleted_at = NULL
+WHERE tenant_id = ? AND external_id = ? AND sha256 = ?
+  AND (delete_lease_until IS NULL OR delete_lease_until <= UTC_TIMESTAMP(3))
+  AND (publication_status = 'ABANDONED' OR staging_object_key IS NULL OR delete_marked_at IS NOT NULL)`,
 		input.StagingObjectKey, input.Metadata.CreatedAt, tenantInternalID, bundleID, input.RequestHash[:])
 	if err != nil {
 		return fmt.Errorf("revive immutable bundle publication: %w", err)
@@ -226,6 +239,34 @@ WHERE tenant_id = ? AND external_id = ? AND sha256 = ? AND deleted_at IS NULL
 	return nil
 }
 
+// restoreRetiredBundle cancels a retirement whose object removal has never
+// been attempted, so the published object is still in place.
+func restoreRetiredBundle(ctx context.Context, transaction *sql.Tx, tenantInternalID uint64, bundleID string) error {
+	result, err := transaction.ExecContext(ctx, `
+UPDATE t_external_bundle
+SET delete_marked_at = NULL, delete_next_attempt_at = NULL, delete_last_error_code = NULL
+WHERE tenant_id = ? AND external_id = ? AND delete_marked_at IS NOT NULL AND delete_token IS NULL
+  AND delete_attempt_count = 0 AND deleted_at IS NULL`, tenantInternalID, bundleID)
+	if err != nil {
+		return fmt.Errorf("restore retired immutable bundle: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected != 1 {
+		return fmt.Errorf("restore retired immutable bundle: state changed concurrently")
+	}
+	return nil
+}
+
+func retainedBundleCount(ctx context.Context, transaction *sql.Tx, tenantInternalID uint64) (int, error) {
+	var retained int
+	if err := transaction.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM t_external_bundle
+WHERE tenant_id = ? AND deleted_at IS NULL AND delete_marked_at IS NULL`, tenantInternalID).Scan(&retained); err != nil {
+		return 0, fmt.Errorf("count retained bundles: %w", err)
+	}
+	return retained, nil
+}
+
 func (repository *SQLBundleRepository) ClaimBundlePublication(ctx context.Context, tenantID, bundleID, leaseToken string, now, leaseUntil time.Time) (BundlePublicationClaim, bool, error) {
 	if repository == nil || repository.database == nil || !externalIDPattern.MatchString(tenantID) || !externalIDPattern.MatchString(bundleID) || !externalIDPattern.MatchString(leaseToken) || !leaseUntil.After(now) {
 		return BundlePublicationClaim{}, false, fmt.Errorf("bundle publication claim is invalid")
@@ -401,7 +442,7 @@ FROM t_external_bundle AS bundle
 JOIN t_external_tenant AS tenant ON tenant.id = bundle.tenant_id
 WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'
   AND bundle.external_id = ? AND bundle.publication_status = 'READY'
-  AND bundle.ready_at IS NOT NULL AND bundle.deleted_at IS NULL
+  AND bundle.ready_at IS NOT NULL AND bundle.delete_marked_at IS NULL AND bundle.deleted_at IS NULL
 LIMIT 1`, tenantID, bundleID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return BundleMetadata{}, ErrBundleNotFound
@@ -412,43 +453,51 @@ LIMIT 1`, tenantID, bundleID))
 	return metadata, nil
 }
 
-func findBundleByDigest(ctx context.Context, transaction *sql.Tx, tenantInternalID uint64, digest [sha256.Size]byte) (BundleMetadata, BundlePublicationStatus, string, bool, error) {
-	metadata, status, stagingKey, err := scanBundleMetadataWithPublication(transaction.QueryRowContext(ctx, `
-SELECT external_id, sha256, size_bytes, case_count, manifest_version, created_at,
-       publication_status, staging_object_key
-FROM t_external_bundle
-WHERE tenant_id = ? AND sha256 = ? AND deleted_at IS NULL
-LIMIT 1 FOR UPDATE`, tenantInternalID, digest[:]))
-	if errors.Is(err, sql.ErrNoRows) {
-		return BundleMetadata{}, "", "", false, nil
-	}
-	if err != nil {
-		return BundleMetadata{}, "", "", false, fmt.Errorf("find immutable bundle by digest: %w", err)
-	}
-	return metadata, status, stagingKey, true, nil
+// storedBundleDigest is the locked row for one tenant content digest,
+// including retired and tombstoned rows that still hold the unique key.
+type storedBundleDigest struct {
+	metadata      BundleMetadata
+	status        BundlePublicationStatus
+	stagingKey    string
+	retired       bool
+	objectRemoved bool
+	deleting      bool
 }
 
-func scanBundleMetadataWithPublication(row rowScanner) (BundleMetadata, BundlePublicationStatus, string, error) {
+func findBundleByDigest(ctx context.Context, transaction *sql.Tx, tenantInternalID uint64, digest [sha256.Size]byte) (storedBundleDigest, bool, error) {
+	var stored storedBundleDigest
 	var metadata BundleMetadata
-	var digest []byte
+	var digestBytes []byte
 	var status string
 	var stagingKey sql.NullString
-	if err := row.Scan(&metadata.BundleID, &digest, &metadata.SizeBytes, &metadata.CaseCount, &metadata.ManifestVersion, &metadata.CreatedAt, &status, &stagingKey); err != nil {
-		return BundleMetadata{}, "", "", err
+	err := transaction.QueryRowContext(ctx, `
+SELECT external_id, sha256, size_bytes, case_count, manifest_version, created_at,
+       publication_status, staging_object_key,
+       delete_marked_at IS NOT NULL,
+       deleted_at IS NOT NULL OR delete_attempt_count > 0,
+       delete_lease_until IS NOT NULL AND delete_lease_until > UTC_TIMESTAMP(3)
+FROM t_external_bundle
+WHERE tenant_id = ? AND sha256 = ?
+LIMIT 1 FOR UPDATE`, tenantInternalID, digest[:]).Scan(&metadata.BundleID, &digestBytes, &metadata.SizeBytes, &metadata.CaseCount,
+		&metadata.ManifestVersion, &metadata.CreatedAt, &status, &stagingKey, &stored.retired, &stored.objectRemoved, &stored.deleting)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storedBundleDigest{}, false, nil
 	}
-	if len(digest) != sha256.Size {
-		return BundleMetadata{}, "", "", fmt.Errorf("stored bundle digest is invalid")
+	if err != nil {
+		return storedBundleDigest{}, false, fmt.Errorf("find immutable bundle by digest: %w", err)
 	}
-	metadata.SHA256 = hex.EncodeToString(digest)
-	metadata.CreatedAt = metadata.CreatedAt.UTC()
-	if !validBundleMetadata(metadata) {
-		return BundleMetadata{}, "", "", fmt.Errorf("stored bundle metadata is invalid")
+	if len(digestBytes) != sha256.Size {
+		return storedBundleDigest{}, false, fmt.Errorf("stored bundle digest is invalid")
 	}
-	publicationStatus := BundlePublicationStatus(status)
-	if !validBundlePublicationStatus(publicationStatus) {
-		return BundleMetadata{}, "", "", fmt.Errorf("stored bundle publication status is invalid")
+	metadata.SHA256 = hex.EncodeToString(digestBytes)
+	metadata.CreatedAt = metadata.CreatedAt.UTC()
+	stored.metadata = metadata
+	stored.status = BundlePublicationStatus(status)
+	stored.stagingKey = stagingKey.String
+	if !validBundleMetadata(metadata) || !validBundlePublicationStatus(stored.status) {
+		return storedBundleDigest{}, false, fmt.Errorf("stored bundle metadata is invalid")
 	}
-	return metadata, publicationStatus, stagingKey.String, nil
+	return stored, true, nil
 }
 
 func scanBundlePublicationClaim(row rowScanner) (BundlePublicationClaim, error) {
@@ -0,0 +1,582 @@
+package external
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBundleInUse                 = errors.New("immutable bundle is referenced by active judge jobs")
+	ErrBundleRetentionLimit        = errors.New("tenant retained bundle limit is reached")
+	ErrInvalidBundleCursor         = errors.New("invalid immutable bundle cursor")
+	ErrInvalidBundleListQuery      = errors.New("invalid immutable bundle list query")
+	ErrBundleRetentionNotAvailable = errors.New("bundle retention is not available")
+	ErrBundleRetentionDeleteFailed = errors.New("bundle retention object deletion failed")
+)
+
+// BundleStatus is the tenant-visible lifecycle of one bundle. PENDING covers
+// both queued and in-flight publication; RETIRED bundles are awaiting object
+// removal and can no longer be referenced by new jobs.
+type BundleStatus string
+
+const (
+	BundleStatusPending   BundleStatus = "PENDING"
+	BundleStatusReady     BundleStatus = "READY"
+	BundleStatusAbandoned BundleStatus = "ABANDONED"
+	BundleStatusRetired   BundleStatus = "RETIRED"
+)
+
+type BundleSummary struct {
+	BundleMetadata
+	Status     BundleStatus `json:"status"`
+	RetiredAt  *time.Time   `json:"retiredAt,omitempty"`
+	InternalID uint64       `json:"-"`
+}
+
+type BundleListOptions struct {
+	Cursor        string
+	Limit         int
+	Status        BundleStatus
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+type BundleListResult struct {
+	Items      []BundleSummary `json:"items"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+// BundleListQuery is the decoded repository form of BundleListOptions. The
+// keyset boundary is exclusive and follows created_at DESC, id DESC order.
+type BundleListQuery struct {
+	Status          BundleStatus
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	AfterCreatedAt  time.Time
+	AfterInternalID uint64
+	Limit           int
+}
+
+type BundleCursor struct {
+	TenantID      string
+	Status        BundleStatus
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	CreatedAt     time.Time
+	InternalID    uint64
+}
+
+// bundleCursorKind keeps bundle cursors apart from job cursors, which are
+// signed with the same key and share the other payload fields.
+const bundleCursorKind = "bundle"
+
+type bundleCursorPayload struct {
+	Version       int          `json:"v"`
+	Kind          string       `json:"k"`
+	TenantID      string       `json:"t"`
+	Status        BundleStatus `json:"s,omitempty"`
+	CreatedAfter  int64        `json:"a,omitempty"`
+	CreatedBefore int64        `json:"b,omitempty"`
+	CreatedMS     int64        `json:"c"`
+	InternalID    uint64       `json:"i"`
+}
+
+// BundleCursorCodec signs list cursors so a page boundary cannot be replayed
+// against another tenant or a different status/creation-range filter.
+type BundleCursorCodec struct{ key []byte }
+
+func NewBundleCursorCodec(key []byte) (*BundleCursorCodec, error) {
+	if len(key) < sha256.Size {
+		return nil, fmt.Errorf("bundle cursor HMAC key must contain at least 256 bits")
+	}
+	return &BundleCursorCodec{key: append([]byte(nil), key...)}, nil
+}
+
+func (codec *BundleCursorCodec) Encode(cursor BundleCursor) (string, error) {
+	if codec == nil || len(codec.key) < sha256.Size || !validBundleCursor(cursor) {
+		return "", ErrInvalidBundleCursor
+	}
+	payload, err := json.Marshal(bundleCursorPayload{
+		Version: 1, Kind: bundleCursorKind, TenantID: cursor.TenantID, Status: cursor.Status,
+		CreatedAfter: unixMilliOrZero(cursor.CreatedAfter), CreatedBefore: unixMilliOrZero(cursor.CreatedBefore),
+		CreatedMS: cursor.CreatedAt.UTC().UnixMilli(), InternalID: cursor.InternalID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: encode payload", ErrInvalidBundleCursor)
+	}
+	signature := hmac.New(sha256.New, codec.key)
+	_, _ = signature.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signature.Sum(nil)), nil
+}
+
+func (codec *BundleCursorCodec) Decode(encoded, tenantID string, options BundleListOptions) (BundleCursor, error) {
+	if codec == nil || len(codec.key) < sha256.Size || len(encoded) == 0 || len(encoded) > 512 {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	parts := strings.Split(encoded, ".")
+	if len(parts) != 2 {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	providedSignature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	expectedSignature := hmac.New(sha256.New, codec.key)
+	_, _ = expectedSignature.Write(payload)
+	if !hmac.Equal(providedSignature, expectedSignature.Sum(nil)) {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	var decoded bundleCursorPayload
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decoded); err != nil || decoded.Version != 1 || decoded.Kind != bundleCursorKind {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	cursor := BundleCursor{
+		TenantID: decoded.TenantID, Status: decoded.Status,
+		CreatedAt: time.UnixMilli(decoded.CreatedMS).UTC(), InternalID: decoded.InternalID,
+	}
+	if decoded.CreatedAfter != 0 {
+		cursor.CreatedAfter = time.UnixMilli(decoded.CreatedAfter).UTC()
+	}
+	if decoded.CreatedBefore != 0 {
+		cursor.CreatedBefore = time.UnixMilli(decoded.CreatedBefore).UTC()
+	}
+	if cursor.TenantID != tenantID || cursor.Status != options.Status ||
+		unixMilliOrZero(cursor.CreatedAfter) != unixMilliOrZero(options.CreatedAfter) ||
+		unixMilliOrZero(cursor.CreatedBefore) != unixMilliOrZero(options.CreatedBefore) || !validBundleCursor(cursor) {
+		return BundleCursor{}, ErrInvalidBundleCursor
+	}
+	return cursor, nil
+}
+
+func validBundleCursor(cursor BundleCursor) bool {
+	return externalIDPattern.MatchString(cursor.TenantID) && !cursor.CreatedAt.IsZero() && cursor.InternalID != 0 &&
+		validBundleStatusFilter(cursor.Status)
+}
+
+func validBundleStatusFilter(status BundleStatus) bool {
+	switch status {
+	case "", BundleStatusPending, BundleStatusReady, BundleStatusAbandoned, BundleStatusRetired:
+		return true
+	default:
+		return false
+	}
+}
+
+func unixMilliOrZero(value time.Time) int64 {
+	if value.IsZero() {
+		return 0
+	}
+	return value.UTC().UnixMilli()
+}
+
+func (repository *SQLBundleRepository) ListBundles(ctx context.Context, tenantID string, query BundleListQuery) ([]BundleSummary, error) {
+	if repository == nil || repository.database == nil || !externalIDPattern.MatchString(tenantID) ||
+		query.Limit < 1 || query.Limit > 101 || !validBundleStatusFilter(query.Status) {
+		return nil, fmt.Errorf("bundle list query is invalid")
+	}
+	arguments := []any{tenantID}
+	conditions := []string{"tenant.external_id = ?", "tenant.status = 'ACTIVE'", "bundle.deleted_at IS NULL"}
+	switch query.Status {
+	case BundleStatusPending:
+		conditions = append(conditions, "bundle.publication_status IN ('PENDING','PUBLISHING')", "bundle.delete_marked_at IS NULL")
+	case BundleStatusReady:
+		conditions = append(conditions, "bundle.publication_status = 'READY'", "bundle.delete_marked_at IS NULL")
+	case BundleStatusAbandoned:
+		conditions = append(conditions, "bundle.publication_status = 'ABANDONED'", "bundle.delete_marked_at IS NULL")
+	case BundleStatusRetired:
+		conditions = append(conditions, "bundle.delete_marked_at IS NOT NULL")
+	}
+	if !query.CreatedAfter.IsZero() {
+		conditions = append(conditions, "bundle.created_at >= ?")
+		arguments = append(arguments, query.CreatedAfter)
+	}
+	if !query.CreatedBefore.IsZero() {
+		conditions = append(conditions, "bundle.created_at < ?")
+		arguments = append(arguments, query.CreatedBefore)
+	}
+	if query.AfterInternalID != 0 {
+		conditions = append(conditions, "(bundle.created_at < ? OR (bundle.created_at = ? AND bundle.id < ?))")
+		arguments = append(arguments, query.AfterCreatedAt, query.AfterCreatedAt, query.AfterInternalID)
+	}
+	arguments = append(arguments, query.Limit)
+	rows, err := repository.database.QueryContext(ctx, `
+SELECT bundle.id, bundle.external_id, bundle.sha256, bundle.size_bytes, bundle.case_count,
+       bundle.manifest_version, bundle.created_at, bundle.publication_status, bundle.delete_marked_at
+FROM t_external_bundle AS bundle FORCE INDEX (idx_external_bundle_tenant_created)
+JOIN t_external_tenant AS tenant ON tenant.id = bundle.tenant_id
+WHERE `+strings.Join(conditions, " AND ")+`
+ORDER BY bundle.created_at DESC, bundle.id DESC
+LIMIT ?`, arguments...)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant bundles: %w", err)
+	}
+	defer rows.Close()
+	summaries := make([]BundleSummary, 0, query.Limit)
+	for rows.Next() {
+		var summary BundleSummary
+		var digest []byte
+		var status string
+		var retiredAt sql.NullTime
+		if err := rows.Scan(&summary.InternalID, &summary.BundleID, &digest, &summary.SizeBytes, &summary.CaseCount,
+			&summary.ManifestVersion, &summary.CreatedAt, &status, &retiredAt); err != nil {
+			return nil, fmt.Errorf("scan listed bundle: %w", err)
+		}
+		if len(digest) != sha256.Size {
+			return nil, fmt.Errorf("stored bundle digest is invalid")
+		}
+		summary.SHA256 = hex.EncodeToString(digest)
+		summary.CreatedAt = summary.CreatedAt.UTC()
+		publicationStatus := BundlePublicationStatus(status)
+		if !validBundleMetadata(summary.BundleMetadata) || !validBundlePublicationStatus(publicationStatus) {
+			return nil, fmt.Errorf("stored bundle metadata is invalid")
+		}
+		summary.Status = bundleStatusFor(publicationStatus, retiredAt.Valid)
+		if retiredAt.Valid {
+			retired := retiredAt.Time.UTC()
+			summary.RetiredAt = &retired
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate listed bundles: %w", err)
+	}
+	return summaries, nil
+}
+
+func bundleStatusFor(status BundlePublicationStatus, retired bool) BundleStatus {
+	switch {
+	case retired:
+		return BundleStatusRetired
+	case status == BundlePublicationReady:
+		return BundleStatusReady
+	case status == BundlePublicationAbandoned:
+		return BundleStatusAbandoned
+	default:
+		return BundleStatusPending
+	}
+}
+
+// RetireBundle marks one tenant bundle for object removal. It follows the
+// tenant -> bundle lock order used by job admission, so a job can never be
+// admitted against a bundle whose retirement has committed. Upload
+// idempotency records are dropped with the mark so a later upload of the
+// same content revives the bundle instead of replaying a retired response.
+func (repository *SQLBundleRepository) RetireBundle(ctx context.Context, tenantID, bundleID string) error {
+	if repository == nil || repository.database == nil || !externalIDPattern.MatchString(tenantID) || !externalIDPattern.MatchString(bundleID) {
+		return ErrBundleNotFound
+	}
+	transaction, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin bundle retirement: %w", err)
+	}
+	defer transaction.Rollback()
+	var tenantInternalID uint64
+	if err := transaction.QueryRowContext(ctx, `SELECT id FROM t_external_tenant WHERE external_id = ? AND status = 'ACTIVE' FOR UPDATE`, tenantID).Scan(&tenantInternalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBundleNotFound
+		}
+		return fmt.Errorf("lock bundle retirement tenant: %w", err)
+	}
+	var bundleInternalID uint64
+	var status string
+	var markedAt sql.NullTime
+	if err := transaction.QueryRowContext(ctx, `
+SELECT id, publication_status, delete_marked_at
+FROM t_external_bundle
+WHERE tenant_id = ? AND external_id = ? AND deleted_at IS NULL
+FOR UPDATE`, tenantInternalID, bundleID).Scan(&bundleInternalID, &status, &markedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBundleNotFound
+		}
+		return fmt.Errorf("lock retired bundle: %w", err)
+	}
+	if markedAt.Valid {
+		return nil
+	}
+	publicationStatus := BundlePublicationStatus(status)
+	if publicationStatus == BundlePublicationPending || publicationStatus == BundlePublicationPublishing {
+		return ErrBundleInUse
+	}
+	activeJobs, err := activeBundleJobs(ctx, transaction, tenantInternalID, bundleInternalID)
+	if err != nil {
+		return err
+	}
+	if activeJobs != 0 {
+		return ErrBundleInUse
+	}
+	now, err := mysqlCurrentTime(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	result, err := transaction.ExecContext(ctx, `
+UPDATE t_external_bundle
+SET delete_marked_at = ?, delete_next_attempt_at = ?, delete_attempt_count = 0, delete_last_error_code = NULL
+WHERE id = ? AND tenant_id = ? AND delete_marked_at IS NULL AND deleted_at IS NULL`,
+		now, now, bundleInternalID, tenantInternalID)
+	if err != nil {
+		return fmt.Errorf("mark retired bundle: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return fmt.Errorf("mark retired bundle: state changed concurrently")
+	}
+	if _, err := transaction.ExecContext(ctx, `
+DELETE FROM t_external_idempotency
+WHERE tenant_id = ? AND operation_scope = ? AND resource_external_id = ?`,
+		tenantInternalID, bundleUploadOperationScope, bundleID); err != nil {
+		return fmt.Errorf("remove retired bundle idempotency: %w", err)
+	}
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("commit bundle retirement: %w", err)
+	}
+	return nil
+}
+
+func activeBundleJobs(ctx context.Context, transaction *sql.Tx, tenantInternalID, bundleInternalID uint64) (int, error) {
+	var active int
+	if err := transaction.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM t_external_job
+WHERE tenant_id = ? AND bundle_id = ? AND status IN ('QUEUED','RUNNING')`,
+		tenantInternalID, bundleInternalID).Scan(&active); err != nil {
+		return 0, fmt.Errorf("count active bundle jobs: %w", err)
+	}
+	return active, nil
+}
+
+// BundleRetentionClaim fences one retired bundle's object removal. Shared is
+// set when another live row of the tenant still addresses the same content,
+// in which case only the metadata is tombstoned.
+type BundleRetentionClaim struct {
+	TenantInternalID uint64
+	BundleInternalID uint64
+	TenantID         string
+	BundleID         string
+	ObjectKey        string
+	Shared           bool
+	DeleteToken      []byte
+}
+
+func (repository *SQLBundleRepository) ClaimBundleRetention(ctx context.Context, leaseDuration time.Duration) (BundleRetentionClaim, error) {
+	if repository == nil || repository.database == nil || leaseDuration <= 0 || leaseDuration > 15*time.Minute {
+		return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+	}
+	rows, err := repository.database.QueryContext(ctx, `
+SELECT tenant_id, id
+FROM t_external_bundle FORCE INDEX (idx_external_bundle_retention)
+WHERE deleted_at IS NULL AND delete_marked_at IS NOT NULL
+  AND delete_next_attempt_at <= CURRENT_TIMESTAMP(3)
+  AND (delete_lease_until IS NULL OR delete_lease_until <= CURRENT_TIMESTAMP(3))
+ORDER BY delete_next_attempt_at, id
+LIMIT 32`)
+	if err != nil {
+		return BundleRetentionClaim{}, fmt.Errorf("select retired bundle candidates: %w", err)
+	}
+	candidates := make([]BundleRetentionClaim, 0, 32)
+	for rows.Next() {
+		var candidate BundleRetentionClaim
+		if err := rows.Scan(&candidate.TenantInternalID, &candidate.BundleInternalID); err != nil {
+			_ = rows.Close()
+			return BundleRetentionClaim{}, fmt.Errorf("scan retired bundle candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return BundleRetentionClaim{}, fmt.Errorf("iterate retired bundle candidates: %w", err)
+	}
+	_ = rows.Close()
+	for _, candidate := range candidates {
+		claim, err := repository.claimBundleRetentionCandidate(ctx, candidate, leaseDuration)
+		if errors.Is(err, ErrBundleRetentionNotAvailable) {
+			continue
+		}
+		return claim, err
+	}
+	return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+}
+
+func (repository *SQLBundleRepository) claimBundleRetentionCandidate(ctx context.Context, claim BundleRetentionClaim, leaseDuration time.Duration) (BundleRetentionClaim, error) {
+	transaction, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return BundleRetentionClaim{}, fmt.Errorf("begin bundle retention claim: %w", err)
+	}
+	defer transaction.Rollback()
+	now, err := mysqlCurrentTime(ctx, transaction)
+	if err != nil {
+		return BundleRetentionClaim{}, err
+	}
+	if err := transaction.QueryRowContext(ctx, "SELECT external_id FROM t_external_tenant WHERE id = ? FOR UPDATE SKIP LOCKED", claim.TenantInternalID).Scan(&claim.TenantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+		}
+		return BundleRetentionClaim{}, fmt.Errorf("lock bundle retention tenant claim: %w", err)
+	}
+	var digest []byte
+	if err := transaction.QueryRowContext(ctx, `
+SELECT external_id, object_key, sha256
+FROM t_external_bundle
+WHERE id = ? AND tenant_id = ? AND deleted_at IS NULL AND delete_marked_at IS NOT NULL
+  AND delete_next_attempt_at <= ? AND (delete_lease_until IS NULL OR delete_lease_until <= ?)
+FOR UPDATE SKIP LOCKED`, claim.BundleInternalID, claim.TenantInternalID, now, now).Scan(&claim.BundleID, &claim.ObjectKey, &digest); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+		}
+		return BundleRetentionClaim{}, fmt.Errorf("lock retired bundle claim: %w", err)
+	}
+	activeJobs, err := activeBundleJobs(ctx, transaction, claim.TenantInternalID, claim.BundleInternalID)
+	if err != nil {
+		return BundleRetentionClaim{}, err
+	}
+	if activeJobs != 0 {
+		return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+	}
+	var sharing int
+	if err := transaction.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM t_external_bundle
+WHERE tenant_id = ? AND sha256 = ? AND id <> ? AND deleted_at IS NULL`,
+		claim.TenantInternalID, digest, claim.BundleInternalID).Scan(&sharing); err != nil {
+		return BundleRetentionClaim{}, fmt.Errorf("count bundles sharing retired content: %w", err)
+	}
+	claim.Shared = sharing != 0
+	claim.DeleteToken = make([]byte, 32)
+	if _, err := rand.Read(claim.DeleteToken); err != nil {
+		return BundleRetentionClaim{}, fmt.Errorf("generate bundle retention fence: %w", err)
+	}
+	result, err := transaction.ExecContext(ctx, `
+UPDATE t_external_bundle
+SET delete_token = ?, delete_lease_until = ?, delete_next_attempt_at = ?,
+    delete_attempt_count = delete_attempt_count + 1, delete_last_error_code = NULL
+WHERE id = ? AND tenant_id = ? AND deleted_at IS NULL AND delete_marked_at IS NOT NULL`,
+		claim.DeleteToken, now.Add(leaseDuration), now, claim.BundleInternalID, claim.TenantInternalID)
+	if err != nil {
+		return BundleRetentionClaim{}, fmt.Errorf("lease retired bundle: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+	}
+	if !validBundleRetentionClaim(claim) {
+		return BundleRetentionClaim{}, fmt.Errorf("stored retired bundle claim is invalid")
+	}
+	if err := transaction.Commit(); err != nil {
+		return BundleRetentionClaim{}, fmt.Errorf("commit bundle retention claim: %w", err)
+	}
+	return claim, nil
+}
+
+func (repository *SQLBundleRepository) RecordBundleRetentionFailure(ctx context.Context, claim BundleRetentionClaim, retryDelay time.Duration) error {
+	if repository == nil || repository.database == nil || !validBundleRetentionClaim(claim) || retryDelay <= 0 || retryDelay > time.Hour {
+		return ErrBundleRetentionNotAvailable
+	}
+	transaction, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin bundle retention retry: %w", err)
+	}
+	defer transaction.Rollback()
+	now, err := mysqlCurrentTime(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	if err := lockBundleRetentionTenant(ctx, transaction, claim); err != nil {
+		return err
+	}
+	result, err := transaction.ExecContext(ctx, `
+UPDATE t_external_bundle
+SET delete_last_error_code = 'OBJECT_DELETE_FAILED', delete_lease_until = ?, delete_next_attempt_at = ?
+WHERE id = ? AND tenant_id = ? AND delete_token = ? AND delete_lease_until > ? AND deleted_at IS NULL`,
+		now, now.Add(retryDelay), claim.BundleInternalID, claim.TenantInternalID, claim.DeleteToken, now)
+	if err != nil {
+		return fmt.Errorf("record bundle retention retry: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return ErrBundleRetentionNotAvailable
+	}
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("commit bundle retention retry: %w", err)
+	}
+	return nil
+}
+
+// FinalizeBundleRetention tombstones the bundle row. The row is kept because
+// terminal job history still references it; a later upload of the same
+// content revives it under a fresh publication.
+func (repository *SQLBundleRepository) FinalizeBundleRetention(ctx context.Context, claim BundleRetentionClaim) error {
+	if repository == nil || repository.database == nil || !validBundleRetentionClaim(claim) {
+		return ErrBundleRetentionNotAvailable
+	}
+	transaction, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin bundle retention finalize: %w", err)
+	}
+	defer transaction.Rollback()
+	now, err := mysqlCurrentTime(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	if err := lockBundleRetentionTenant(ctx, transaction, claim); err != nil {
+		return err
+	}
+	result, err := transaction.ExecContext(ctx, `
+UPDATE t_external_bundle
+SET deleted_at = ?, delete_token = NULL, delete_lease_until = NULL, delete_last_error_code = NULL
+WHERE id = ? AND tenant_id = ? AND object_key = ? AND delete_token = ? AND delete_lease_until > ?
+  AND delete_marked_at IS NOT NULL AND deleted_at IS NULL`,
+		now, claim.BundleInternalID, claim.TenantInternalID, claim.ObjectKey, claim.DeleteToken, now)
+	if err != nil {
+		return fmt.Errorf("tombstone retired bundle: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return ErrBundleRetentionNotAvailable
+	}
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("commit bundle retention finalize: %w", err)
+	}
+	return nil
+}
+
+func lockBundleRetentionTenant(ctx context.Context, transaction *sql.Tx, claim BundleRetentionClaim) error {
+	var tenantID string
+	if err := transaction.QueryRowContext(ctx, "SELECT external_id FROM t_external_tenant WHERE id = ? FOR UPDATE", claim.TenantInternalID).Scan(&tenantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBundleRetentionNotAvailable
+		}
+		return fmt.Errorf("lock bundle retention tenant: %w", err)
+	}
+	if tenantID != claim.TenantID {
+		return ErrBundleRetentionNotAvailable
+	}
+	return nil
+}
+
+func validBundleRetentionClaim(claim BundleRetentionClaim) bool {
+	return claim.TenantInternalID > 0 && claim.BundleInternalID > 0 &&
+		externalIDPattern.MatchString(claim.TenantID) && externalIDPattern.MatchString(claim.BundleID) &&
+		validBundleObjectKey(claim.TenantID, claim.ObjectKey) && len(claim.DeleteToken) == 32
+}
+
+func validBundleObjectKey(tenantID, key string) bool {
+	parts := strings.Split(key, "/")
+	if !externalIDPattern.MatchString(tenantID) || len(parts) != 4 || parts[0] != "external" || parts[1] != tenantID || parts[2] != "sha256" || !strings.HasSuffix(parts[3], ".zip") {
+		return false
+	}
+	digest := strings.TrimSuffix(parts[3], ".zip")
+	decoded, err := hex.DecodeString(digest)
+	return err == nil && len(decoded) == sha256.Size && strings.ToLower(digest) == digest &&
+		key == path.Join("external", tenantID, "sha256", digest+".zip")
+}
@@ -0,0 +1,310 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+)
+
+func TestBundleCursorIsBoundToTenantStatusAndCreatedRange(t *testing.T) {
+	codec, err := NewBundleCursorCodec([]byte(strings.Repeat("c", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := BundleListOptions{
+		Status:        BundleStatusReady,
+		CreatedAfter:  time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		CreatedBefore: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+	want := BundleCursor{
+		TenantID: testTenantID, Status: options.Status, CreatedAfter: options.CreatedAfter, CreatedBefore: options.CreatedBefore,
+		CreatedAt: time.Date(2026, 7, 19, 10, 11, 12, 123000000, time.UTC), InternalID: 42,
+	}
+	encoded, err := codec.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := codec.Decode(encoded, testTenantID, options)
+	if err != nil || got != want {
+		t.Fatalf("decoded cursor = %+v error=%v, want %+v", got, err, want)
+	}
+	parts := strings.Split(encoded, ".")
+	for name, attempt := range map[string]struct {
+		encoded string
+		tenant  string
+		options BundleListOptions
+	}{
+		"tampered": {parts[0] + "." + strings.Repeat("A", len(parts[1])), testTenantID, options},
+		"tenant":   {encoded, "bbbbbbbbbbbbbbbbbbbbbbbbbb", options},
+		"status":   {encoded, testTenantID, BundleListOptions{Status: BundleStatusRetired, CreatedAfter: options.CreatedAfter, CreatedBefore: options.CreatedBefore}},
+		"after":    {encoded, testTenantID, BundleListOptions{Status: options.Status, CreatedBefore: options.CreatedBefore}},
+		"before":   {encoded, testTenantID, BundleListOptions{Status: options.Status, CreatedAfter: options.CreatedAfter, CreatedBefore: options.CreatedBefore.Add(time.Hour)}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := codec.Decode(attempt.encoded, attempt.tenant, attempt.options); !errors.Is(err, ErrInvalidBundleCursor) {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+	jobCodec, err := NewJobCursorCodec([]byte(strings.Repeat("c", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobCursor, err := jobCodec.Encode(JobCursor{TenantID: testTenantID, CreatedAt: want.CreatedAt, InternalID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.Decode(jobCursor, testTenantID, BundleListOptions{}); !errors.Is(err, ErrInvalidBundleCursor) {
+		t.Fatalf("job cursor accepted as bundle cursor: %v", err)
+	}
+	bundleCursor, err := codec.Encode(BundleCursor{TenantID: testTenantID, CreatedAt: want.CreatedAt, InternalID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jobCodec.Decode(bundleCursor, testTenantID, JobListOptions{}); !errors.Is(err, ErrInvalidJobCursor) {
+		t.Fatalf("bundle cursor accepted as job cursor: %v", err)
+	}
+	if _, err := NewBundleCursorCodec([]byte("weak")); err == nil {
+		t.Fatal("weak cursor key accepted")
+	}
+}
+
+func TestBundleServiceListsStablePagesAndRetiresBundles(t *testing.T) {
+	repository := newMemoryBundleRepository()
+	service, err := NewBundleService(repository, &atomicMemoryObjectStore{}, BundleServiceConfig{
+		TempDir: t.TempDir(), MaxUploadBytes: 1 << 20, ArchiveLimits: bundle.DefaultArchiveLimits(),
+		MaxTimeLimitMillis: 60_000, MaxMemoryLimitMiB: 4096, IdempotencyTTL: 24 * time.Hour,
+		IdempotencyPepper: testIdempotencyPepper(), CursorKey: bytes.Repeat([]byte{0x17}, 32), Random: rand.Reader,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.now = func() time.Time { return time.Date(2026, 7, 19, 1, 2, 3, 0, time.UTC) }
+	uploaded := make([]BundleMetadata, 0, 3)
+	for index, input := range []string{"first", "second", "third"} {
+		metadata, _, err := service.Upload(context.Background(), testTenantID, "list-upload-key-"+string(rune('a'+index)), bytes.NewReader(validExternalBundle(t, input)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		uploaded = append(uploaded, metadata)
+	}
+
+	first, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 2})
+	if err != nil || len(first.Items) != 2 || first.NextCursor == "" ||
+		first.Items[0].BundleID != uploaded[2].BundleID || first.Items[1].BundleID != uploaded[1].BundleID {
+		t.Fatalf("first page=%+v error=%v", first, err)
+	}
+	second, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil || len(second.Items) != 1 || second.NextCursor != "" || second.Items[0].BundleID != uploaded[0].BundleID {
+		t.Fatalf("second page=%+v error=%v", second, err)
+	}
+	if _, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 2, Cursor: first.NextCursor, Status: BundleStatusReady}); !errors.Is(err, ErrInvalidBundleListQuery) {
+		t.Fatalf("cursor reused with another filter error=%v", err)
+	}
+	if _, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 2, Cursor: first.NextCursor}); err != nil {
+		t.Fatal(err)
+	}
+	inverted := BundleListOptions{Limit: 10, CreatedAfter: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), CreatedBefore: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := service.List(context.Background(), testTenantID, inverted); !errors.Is(err, ErrInvalidBundleListQuery) {
+		t.Fatalf("inverted created range error=%v", err)
+	}
+
+	if err := service.Delete(context.Background(), testTenantID, uploaded[1].BundleID); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Delete(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", uploaded[0].BundleID); !errors.Is(err, ErrBundleNotFound) {
+		t.Fatalf("cross-tenant delete error=%v", err)
+	}
+	retired, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 10, Status: BundleStatusRetired})
+	if err != nil || len(retired.Items) != 1 || retired.Items[0].BundleID != uploaded[1].BundleID || retired.Items[0].Status != BundleStatusRetired {
+		t.Fatalf("retired page=%+v error=%v", retired, err)
+	}
+	ready, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 10, Status: BundleStatusReady})
+	if err != nil || len(ready.Items) != 2 {
+		t.Fatalf("ready page=%+v error=%v", ready, err)
+	}
+}
+
+func TestBundleServiceListingRequiresCursorKey(t *testing.T) {
+	service := newTestBundleService(t, newMemoryBundleRepository(), &atomicMemoryObjectStore{}, t.TempDir(), 1<<20, bundle.DefaultArchiveLimits())
+	if _, err := service.List(context.Background(), testTenantID, BundleListOptions{Limit: 1}); err == nil {
+		t.Fatal("listing without a cursor key succeeded")
+	}
+	if _, err := NewBundleService(newMemoryBundleRepository(), &atomicMemoryObjectStore{}, BundleServiceConfig{
+		MaxUploadBytes: 1 << 20, ArchiveLimits: bundle.DefaultArchiveLimits(), MaxTimeLimitMillis: 60_000, MaxMemoryLimitMiB: 4096,
+		IdempotencyTTL: time.Hour, IdempotencyPepper: testIdempotencyPepper(), CursorKey: []byte("weak"),
+	}); err == nil {
+		t.Fatal("weak bundle cursor key accepted")
+	}
+}
+
+type scriptedBundleRetentionRepository struct {
+	claims    []BundleRetentionClaim
+	retries   int
+	finalized []string
+}
+
+func (repository *scriptedBundleRetentionRepository) ClaimBundleRetention(context.Context, time.Duration) (BundleRetentionClaim, error) {
+	if len(repository.claims) == 0 {
+		return BundleRetentionClaim{}, ErrBundleRetentionNotAvailable
+	}
+	claim := repository.claims[0]
+	repository.claims = repository.claims[1:]
+	return claim, nil
+}
+
+func (repository *scriptedBundleRetentionRepository) RecordBundleRetentionFailure(_ context.Context, claim BundleRetentionClaim, _ time.Duration) error {
+	repository.retries++
+	repository.claims = append(repository.claims, claim)
+	return nil
+}
+
+func (repository *scriptedBundleRetentionRepository) FinalizeBundleRetention(_ context.Context, claim BundleRetentionClaim) error {
+	repository.finalized = append(repository.finalized, claim.BundleID)
+	return nil
+}
+
+type flakyBundleObjectDeleter struct {
+	failures int
+	deleted  []string
+}
+
+func (deleter *flakyBundleObjectDeleter) Delete(_ context.Context, key string) error {
+	if deleter.failures > 0 {
+		deleter.failures--
+		return errors.New("temporary object failure")
+	}
+	deleter.deleted = append(deleter.deleted, key)
+	return nil
+}
+
+func TestBundleRetentionWorkerKeepsSharedContentAndRetriesFailedDeletes(t *testing.T) {
+	digest := sha256.Sum256([]byte("retired"))
+	objectKey := "external/" + testTenantID + "/sha256/" + hex.EncodeToString(digest[:]) + ".zip"
+	repository := &scriptedBundleRetentionRepository{claims: []BundleRetentionClaim{
+		{BundleID: "shared", ObjectKey: objectKey, Shared: true},
+		{BundleID: "owned", ObjectKey: objectKey},
+	}}
+	objects := &flakyBundleObjectDeleter{failures: 1}
+	worker, err := NewBundleRetentionWorker(BundleRetentionWorkerConfig{
+		Repository: repository, Objects: objects, IdleDelay: time.Millisecond,
+		DeleteTimeout: time.Second, ClaimLease: 2 * time.Second, RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.ProcessNext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(objects.deleted) != 0 || len(repository.finalized) != 1 || repository.finalized[0] != "shared" {
+		t.Fatalf("shared content deleted=%v finalized=%v", objects.deleted, repository.finalized)
+	}
+	if err := worker.ProcessNext(context.Background()); !errors.Is(err, ErrBundleRetentionDeleteFailed) {
+		t.Fatalf("failed delete error=%v", err)
+	}
+	if err := worker.ProcessNext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if repository.retries != 1 || len(objects.deleted) != 1 || objects.deleted[0] != objectKey || len(repository.finalized) != 2 {
+		t.Fatalf("retries=%d deleted=%v finalized=%v", repository.retries, objects.deleted, repository.finalized)
+	}
+	if err := worker.ProcessNext(context.Background()); !errors.Is(err, ErrBundleRetentionNotAvailable) {
+		t.Fatalf("idle error=%v", err)
+	}
+	if _, err := NewBundleRetentionWorker(BundleRetentionWorkerConfig{
+		Repository: repository, Objects: objects, DeleteTimeout: time.Minute, ClaimLease: time.Minute,
+	}); err == nil {
+		t.Fatal("claim lease that does not outlive the delete timeout was accepted")
+	}
+}
+
+func TestBundleObjectKeyMustBeTheTenantContentAddress(t *testing.T) {
+	digest := sha256.Sum256([]byte("bundle"))
+	valid := "external/" + testTenantID + "/sha256/" + hex.EncodeToString(digest[:]) + ".zip"
+	if !validBundleObjectKey(testTenantID, valid) {
+		t.Fatal("content address rejected")
+	}
+	for _, key := range []string{
+		"external/" + testTenantID + "/sources/" + testTenantID + ".bin",
+		"external/bbbbbbbbbbbbbbbbbbbbbbbbbb/sha256/" + hex.EncodeToString(digest[:]) + ".zip",
+		"external/" + testTenantID + "/sha256/" + strings.ToUpper(hex.EncodeToString(digest[:])) + ".zip",
+		"external-staging/" + testTenantID + "/sha256/" + hex.EncodeToString(digest[:]) + ".zip",
+	} {
+		if validBundleObjectKey(testTenantID, key) {
+			t.Fatalf("key %q accepted", key)
+		}
+	}
+}
+
+func TestSQLBundleRepositoryRetiresRemovesAndRevivesBundles(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID, bundleID := strings.Repeat("r", 26), strings.Repeat("u", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 2)
+	if _, err := database.Exec(`UPDATE t_external_bundle SET object_key = CONCAT('external/', ?, '/sha256/', LOWER(HEX(sha256)), '.zip') WHERE external_id = ?`, tenantID, bundleID); err != nil {
+		t.Fatal(err)
+	}
+	jobs := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	bundles, err := NewSQLBundleRepository(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	submitted, err := jobs.Submit(ctx, tenantID, "bundle-retire-job-01", JudgeJobRequest{BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){}")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundles.RetireBundle(ctx, tenantID, bundleID); !errors.Is(err, ErrBundleInUse) {
+		t.Fatalf("retire with queued job error=%v", err)
+	}
+	if _, err := jobs.Cancel(ctx, tenantID, submitted.Job.ExternalID); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundles.RetireBundle(ctx, strings.Repeat("z", 26), bundleID); !errors.Is(err, ErrBundleNotFound) {
+		t.Fatalf("cross-tenant retire error=%v", err)
+	}
+	for range 2 {
+		if err := bundles.RetireBundle(ctx, tenantID, bundleID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := bundles.FindBundle(ctx, tenantID, bundleID); !errors.Is(err, ErrBundleNotFound) {
+		t.Fatalf("retired bundle remained readable: %v", err)
+	}
+	if _, err := jobs.Submit(ctx, tenantID, "bundle-retire-job-02", JudgeJobRequest{BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){}")}); err == nil {
+		t.Fatal("retired bundle admitted a new job")
+	}
+	listed, err := bundles.ListBundles(ctx, tenantID, BundleListQuery{Status: BundleStatusRetired, Limit: 10})
+	if err != nil || len(listed) != 1 || listed[0].BundleID != bundleID || listed[0].RetiredAt == nil {
+		t.Fatalf("retired listing=%+v error=%v", listed, err)
+	}
+
+	claim, err := bundles.ClaimBundleRetention(ctx, time.Minute)
+	if err != nil || claim.BundleID != bundleID || claim.Shared {
+		t.Fatalf("claim=%+v error=%v", claim, err)
+	}
+	if _, err := bundles.ClaimBundleRetention(ctx, time.Minute); !errors.Is(err, ErrBundleRetentionNotAvailable) {
+		t.Fatalf("leased bundle was claimed twice: %v", err)
+	}
+	if err := bundles.FinalizeBundleRetention(ctx, claim); err != nil {
+		t.Fatal(err)
+	}
+	if deleted := mustCount(t, database, `SELECT COUNT(*) FROM t_external_bundle WHERE external_id = ? AND deleted_at IS NOT NULL AND delete_token IS NULL`, bundleID); deleted != 1 {
+		t.Fatalf("tombstoned rows=%d", deleted)
+	}
+	listed, err = bundles.ListBundles(ctx, tenantID, BundleListQuery{Limit: 10})
+	if err != nil || len(listed) != 0 {
+		t.Fatalf("tombstoned listing=%+v error=%v", listed, err)
+	}
+	if err := bundles.RetireBundle(ctx, tenantID, bundleID); !errors.Is(err, ErrBundleNotFound) {
+		t.Fatalf("retire after removal error=%v", err)
+	}
+}
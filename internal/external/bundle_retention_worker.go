@@ -0,0 +1,113 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type BundleRetentionRepository interface {
+	ClaimBundleRetention(context.Context, time.Duration) (BundleRetentionClaim, error)
+	RecordBundleRetentionFailure(context.Context, BundleRetentionClaim, time.Duration) error
+	FinalizeBundleRetention(context.Context, BundleRetentionClaim) error
+}
+
+// BundleObjectDeleter removes a published bundle from its final content
+// address. Deleting an absent object must succeed.
+type BundleObjectDeleter interface {
+	Delete(context.Context, string) error
+}
+
+type BundleRetentionWorkerConfig struct {
+	Repository    BundleRetentionRepository
+	Objects       BundleObjectDeleter
+	IdleDelay     time.Duration
+	DeleteTimeout time.Duration
+	ClaimLease    time.Duration
+	RetryDelay    time.Duration
+}
+
+// BundleRetentionWorker removes the objects of bundles retired through the
+// REST API. Each removal runs under a fenced delete lease so another replica
+// can take over only after the lease and retry time have passed.
+type BundleRetentionWorker struct {
+	repository    BundleRetentionRepository
+	objects       BundleObjectDeleter
+	idleDelay     time.Duration
+	deleteTimeout time.Duration
+	claimLease    time.Duration
+	retryDelay    time.Duration
+}
+
+func NewBundleRetentionWorker(config BundleRetentionWorkerConfig) (*BundleRetentionWorker, error) {
+	if config.IdleDelay == 0 {
+		config.IdleDelay = time.Minute
+	}
+	if config.DeleteTimeout == 0 {
+		config.DeleteTimeout = 30 * time.Second
+	}
+	if config.ClaimLease == 0 {
+		config.ClaimLease = 2 * time.Minute
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = time.Minute
+	}
+	if config.Repository == nil || config.Objects == nil ||
+		config.IdleDelay <= 0 || config.IdleDelay > time.Hour || config.DeleteTimeout <= 0 || config.DeleteTimeout > time.Minute ||
+		config.ClaimLease <= config.DeleteTimeout || config.ClaimLease > 15*time.Minute || config.RetryDelay <= 0 || config.RetryDelay > time.Hour {
+		return nil, fmt.Errorf("bundle retention repository, object store, and bounded durations are required")
+	}
+	return &BundleRetentionWorker{
+		repository: config.Repository, objects: config.Objects,
+		idleDelay: config.IdleDelay, deleteTimeout: config.DeleteTimeout,
+		claimLease: config.ClaimLease, retryDelay: config.RetryDelay,
+	}, nil
+}
+
+func (worker *BundleRetentionWorker) ProcessNext(ctx context.Context) error {
+	if worker == nil || worker.repository == nil || worker.objects == nil {
+		return fmt.Errorf("bundle retention worker is not configured")
+	}
+	claim, err := worker.repository.ClaimBundleRetention(ctx, worker.claimLease)
+	if err != nil {
+		return err
+	}
+	if !claim.Shared {
+		deleteContext, cancel := context.WithTimeout(ctx, worker.deleteTimeout)
+		err = worker.objects.Delete(deleteContext, claim.ObjectKey)
+		cancel()
+		if err != nil {
+			if recordErr := worker.repository.RecordBundleRetentionFailure(ctx, claim, worker.retryDelay); recordErr != nil {
+				if !errors.Is(recordErr, ErrBundleRetentionNotAvailable) && !IsTransientDatabaseError(recordErr) {
+					return recordErr
+				}
+				return errors.Join(ErrBundleRetentionDeleteFailed, recordErr)
+			}
+			return ErrBundleRetentionDeleteFailed
+		}
+	}
+	return worker.repository.FinalizeBundleRetention(ctx, claim)
+}
+
+func (worker *BundleRetentionWorker) Run(ctx context.Context) error {
+	for {
+		err := worker.ProcessNext(ctx)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, ErrBundleRetentionNotAvailable) && !errors.Is(err, ErrBundleRetentionDeleteFailed) &&
+			!IsTransientDatabaseError(err) {
+			return err
+		}
+		timer := time.NewTimer(worker.idleDelay)
+		select {
+		case <-ctx.Done():
+			if !timer.Stop() {
+				<-timer.C
+			}
+			return context.Cause(ctx)
+		case <-timer.C:
+		}
+	}
+}
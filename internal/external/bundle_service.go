@@ -25,7 +25,10 @@ var (
 	ErrBundleAbandoned     = errors.New("immutable bundle publication was abandoned")
 )
 
-const maxExternalBundleCasesV1 = 256
+// MaximumBundleCases matches the manifest ceiling. The judge splits larger
+// bundles into several compile-once sandbox batches, so it is not bounded by
+// the per-stream case limit of ExecuteBatchV1.
+const MaximumBundleCases = 10_000
 
 const defaultBundleMaintenanceTimeout = 30 * time.Second
 
@@ -95,6 +98,8 @@ type BundleRepository interface {
 	FailBundlePublication(context.Context, BundlePublicationClaim, string, time.Time, int) (bool, error)
 	SweepUnrecoverableBundlePublications(context.Context, time.Time, int) (int64, error)
 	FindBundle(context.Context, string, string) (BundleMetadata, error)
+	ListBundles(context.Context, string, BundleListQuery) ([]BundleSummary, error)
+	RetireBundle(context.Context, string, string) error
 }
 
 type BundleUploadAdmission func(context.Context, int64) error
@@ -112,13 +117,16 @@ type BundleServiceConfig struct {
 	MaxPublishAttempts  int
 	PendingAbandonAfter time.Duration
 	MaintenanceTimeout  time.Duration
-	Random              io.Reader
+	// CursorKey signs bundle list cursors. Listing is disabled when empty.
+	CursorKey []byte
+	Random    io.Reader
 }
 
 type BundleService struct {
 	repository BundleRepository
 	store      BundleObjectStore
 	config     BundleServiceConfig
+	cursor     *BundleCursorCodec
 	now        func() time.Time
 }
 
@@ -160,8 +168,17 @@ func NewBundleService(repository BundleRepository, store BundleObjectStore, conf
 	} else if config.MaintenanceTimeout < time.Millisecond || config.MaintenanceTimeout > 5*time.Minute {
 		return nil, fmt.Errorf("bundle maintenance timeout must be between one millisecond and five minutes")
 	}
+	var cursor *BundleCursorCodec
+	if len(config.CursorKey) != 0 {
+		codec, err := NewBundleCursorCodec(config.CursorKey)
+		if err != nil {
+			return nil, err
+		}
+		cursor = codec
+	}
 	config.IdempotencyPepper = append([]byte(nil), config.IdempotencyPepper...)
-	return &BundleService{repository: repository, store: store, config: config, now: time.Now}, nil
+	config.CursorKey = nil
+	return &BundleService{repository: repository, store: store, config: config, cursor: cursor, now: time.Now}, nil
 }
 
 func (service *BundleService) Upload(ctx context.Context, tenantID, idempotencyKey string, source io.Reader) (BundleMetadata, bool, error) {
@@ -218,8 +235,8 @@ func (service *BundleService) UploadWithAdmission(ctx context.Context, tenantID,
 	if err != nil {
 		return BundleMetadata{}, false, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
 	}
-	if len(manifest.Cases) > maxExternalBundleCasesV1 {
-		return BundleMetadata{}, false, fmt.Errorf("%w: manifest exceeds %d cases", ErrInvalidBundle, maxExternalBundleCasesV1)
+	if len(manifest.Cases) > MaximumBundleCases {
+		return BundleMetadata{}, false, fmt.Errorf("%w: manifest exceeds %d cases", ErrInvalidBundle, MaximumBundleCases)
 	}
 	if !manifestWithinExecutionCeilings(
 		manifest,
@@ -271,7 +288,7 @@ func (service *BundleService) UploadWithAdmission(ctx context.Context, tenantID,
 		IdempotencyExpiresAt: now.Add(service.config.IdempotencyTTL),
 	})
 	if err != nil {
-		if errors.Is(err, ErrIdempotencyConflict) || errors.Is(err, ErrBundleNotFound) || errors.Is(err, ErrInvalidBundle) {
+		if errors.Is(err, ErrIdempotencyConflict) || errors.Is(err, ErrBundleNotFound) || errors.Is(err, ErrInvalidBundle) || errors.Is(err, ErrBundleRetentionLimit) {
 			maintenanceContext, cancelMaintenance := service.maintenanceContext(ctx)
 			_ = service.store.Discard(maintenanceContext, stagingKey)
 			cancelMaintenance()
@@ -307,11 +324,20 @@ func manifestWithinExecutionCeilings(
 		manifest.Limits.MemoryLimitMiB > maxMemoryLimitMiB {
 		return false
 	}
-	return manifest.SpecialJudge == nil ||
-		manifest.SpecialJudge.TimeLimitMillis > 0 &&
-			manifest.SpecialJudge.TimeLimitMillis <= maxTimeLimitMillis &&
-			manifest.SpecialJudge.MemoryLimitMiB > 0 &&
-			manifest.SpecialJudge.MemoryLimitMiB <= maxMemoryLimitMiB
+	programs := make([]bundle.SpecialJudge, 0, 2)
+	if manifest.SpecialJudge != nil {
+		programs = append(programs, *manifest.SpecialJudge)
+	}
+	if manifest.Interactor != nil {
+		programs = append(programs, bundle.SpecialJudge(*manifest.Interactor))
+	}
+	for _, program := range programs {
+		if program.TimeLimitMillis <= 0 || program.TimeLimitMillis > maxTimeLimitMillis ||
+			program.MemoryLimitMiB <= 0 || program.MemoryLimitMiB > maxMemoryLimitMiB {
+			return false
+		}
+	}
+	return true
 }
 
 func (service *BundleService) publishOwnedBundle(ctx context.Context, tenantID string, metadata BundleMetadata, digest [sha256.Size]byte) error {
@@ -376,6 +402,57 @@ func (service *BundleService) Get(ctx context.Context, tenantID, bundleID string
 	return service.repository.FindBundle(ctx, tenantID, bundleID)
 }
 
+// List returns one page of the tenant's live bundles, newest first. Retired
+// bundles stay visible as RETIRED until the retention worker removes them.
+func (service *BundleService) List(ctx context.Context, tenantID string, options BundleListOptions) (BundleListResult, error) {
+	if service == nil || !externalIDPattern.MatchString(tenantID) {
+		return BundleListResult{}, ErrBundleNotFound
+	}
+	if service.cursor == nil {
+		return BundleListResult{}, fmt.Errorf("bundle listing is not configured")
+	}
+	if options.Limit < 1 || options.Limit > 100 || !validBundleStatusFilter(options.Status) ||
+		!options.CreatedAfter.IsZero() && !options.CreatedBefore.IsZero() && !options.CreatedAfter.Before(options.CreatedBefore) {
+		return BundleListResult{}, ErrInvalidBundleListQuery
+	}
+	query := BundleListQuery{
+		Status: options.Status, CreatedAfter: options.CreatedAfter.UTC(), CreatedBefore: options.CreatedBefore.UTC(), Limit: options.Limit + 1,
+	}
+	if options.Cursor != "" {
+		cursor, err := service.cursor.Decode(options.Cursor, tenantID, options)
+		if err != nil {
+			return BundleListResult{}, ErrInvalidBundleListQuery
+		}
+		query.AfterCreatedAt, query.AfterInternalID = cursor.CreatedAt, cursor.InternalID
+	}
+	items, err := service.repository.ListBundles(ctx, tenantID, query)
+	if err != nil {
+		return BundleListResult{}, err
+	}
+	result := BundleListResult{Items: items}
+	if len(items) > options.Limit {
+		last := items[options.Limit-1]
+		result.Items = items[:options.Limit]
+		result.NextCursor, err = service.cursor.Encode(BundleCursor{
+			TenantID: tenantID, Status: options.Status, CreatedAfter: query.CreatedAfter, CreatedBefore: query.CreatedBefore,
+			CreatedAt: last.CreatedAt, InternalID: last.InternalID,
+		})
+		if err != nil {
+			return BundleListResult{}, fmt.Errorf("encode next bundle cursor: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// Delete retires one tenant bundle. The object itself is removed later by
+// BundleRetentionWorker; retiring an already retired bundle succeeds.
+func (service *BundleService) Delete(ctx context.Context, tenantID, bundleID string) error {
+	if service == nil || !externalIDPattern.MatchString(tenantID) || !externalIDPattern.MatchString(bundleID) {
+		return ErrBundleNotFound
+	}
+	return service.repository.RetireBundle(ctx, tenantID, bundleID)
+}
+
 type contextReader struct {
 	ctx    context.Context
 	reader io.Reader
@@ -13,6 +13,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -32,6 +33,8 @@ type memoryBundleRepository struct {
 	claims          map[string]BundlePublicationClaim
 	leaseUntil      map[string]time.Time
 	attempts        map[string]int
+	internalIDs     map[string]uint64
+	retired         map[string]bool
 	logicalCreates  int
 	commitErr       error
 	completeErrOnce error
@@ -46,6 +49,7 @@ func newMemoryBundleRepository() *memoryBundleRepository {
 	return &memoryBundleRepository{
 		idempotency: map[string]bundleUploadRecord{}, bundles: map[string]BundleMetadata{}, status: map[string]BundlePublicationStatus{},
 		staging: map[string]string{}, claims: map[string]BundlePublicationClaim{}, leaseUntil: map[string]time.Time{}, attempts: map[string]int{},
+		internalIDs: map[string]uint64{}, retired: map[string]bool{},
 	}
 }
 
@@ -84,6 +88,7 @@ func (repository *memoryBundleRepository) CommitBundleUpload(_ context.Context,
 		repository.status[bundleKey] = BundlePublicationPending
 		repository.staging[bundleKey] = input.StagingObjectKey
 		repository.logicalCreates++
+		repository.internalIDs[bundleKey] = uint64(repository.logicalCreates)
 	} else if bundlePublicationNeedsFreshStaging(repository.status[bundleKey], repository.staging[bundleKey]) {
 		repository.status[bundleKey] = BundlePublicationPending
 		repository.staging[bundleKey] = input.StagingObjectKey
@@ -197,6 +202,51 @@ func (repository *memoryBundleRepository) FindBundle(_ context.Context, tenantID
 	return BundleMetadata{}, ErrBundleNotFound
 }
 
+func (repository *memoryBundleRepository) ListBundles(_ context.Context, tenantID string, query BundleListQuery) ([]BundleSummary, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	var summaries []BundleSummary
+	for key, metadata := range repository.bundles {
+		if !strings.HasPrefix(key, tenantID+":") {
+			continue
+		}
+		summary := BundleSummary{BundleMetadata: metadata, Status: bundleStatusFor(repository.status[key], repository.retired[key]), InternalID: repository.internalIDs[key]}
+		if query.Status != "" && summary.Status != query.Status ||
+			!query.CreatedAfter.IsZero() && metadata.CreatedAt.Before(query.CreatedAfter) ||
+			!query.CreatedBefore.IsZero() && !metadata.CreatedAt.Before(query.CreatedBefore) ||
+			query.AfterInternalID != 0 && (metadata.CreatedAt.After(query.AfterCreatedAt) ||
+				metadata.CreatedAt.Equal(query.AfterCreatedAt) && summary.InternalID >= query.AfterInternalID) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(left, right int) bool {
+		if !summaries[left].CreatedAt.Equal(summaries[right].CreatedAt) {
+			return summaries[left].CreatedAt.After(summaries[right].CreatedAt)
+		}
+		return summaries[left].InternalID > summaries[right].InternalID
+	})
+	if len(summaries) > query.Limit {
+		summaries = summaries[:query.Limit]
+	}
+	return summaries, nil
+}
+
+func (repository *memoryBundleRepository) RetireBundle(_ context.Context, tenantID, bundleID string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	for key, metadata := range repository.bundles {
+		if strings.HasPrefix(key, tenantID+":") && metadata.BundleID == bundleID {
+			if status := repository.status[key]; status == BundlePublicationPending || status == BundlePublicationPublishing {
+				return ErrBundleInUse
+			}
+			repository.retired[key] = true
+			return nil
+		}
+	}
+	return ErrBundleNotFound
+}
+
 type atomicMemoryObjectStore struct {
 	mu           sync.Mutex
 	staged       map[string][]byte
@@ -666,10 +716,10 @@ func TestBundleServiceReusesHardenedArchiveValidation(t *testing.T) {
 	}
 }
 
-func TestBundleServiceRejectsManifestAboveExternalCaseLimit(t *testing.T) {
+func TestBundleServiceAcceptsManifestAboveSandboxBatchCaseLimit(t *testing.T) {
 	manifest := bundle.Manifest{SchemaVersion: 1, JudgeMode: bundle.JudgeModeACM, Checker: bundle.CheckerExact, Limits: bundle.Limits{TimeLimitMillis: 1000, MemoryLimitMiB: 256}}
 	entries := map[string]zipEntry{}
-	for index := range 257 {
+	for index := range 300 {
 		id := fmt.Sprintf("case-%03d", index)
 		input, output := id+".in", id+".out"
 		manifest.Cases = append(manifest.Cases, bundle.Case{ID: id, Input: input, Output: output, Weight: 1})
@@ -684,9 +734,9 @@ func TestBundleServiceRejectsManifestAboveExternalCaseLimit(t *testing.T) {
 	repository := newMemoryBundleRepository()
 	store := &atomicMemoryObjectStore{}
 	service := newTestBundleService(t, repository, store, t.TempDir(), 1<<20, bundle.DefaultArchiveLimits())
-	_, _, err = service.Upload(context.Background(), testTenantID, "upload-key-00001", bytes.NewReader(zipBytes(t, entries)))
-	if !errors.Is(err, ErrInvalidBundle) || repository.logicalCreates != 0 || len(store.objects) != 0 {
-		t.Fatalf("error=%v rows=%d objects=%d", err, repository.logicalCreates, len(store.objects))
+	metadata, _, err := service.Upload(context.Background(), testTenantID, "upload-key-00001", bytes.NewReader(zipBytes(t, entries)))
+	if err != nil || metadata.CaseCount != 300 || repository.logicalCreates != 1 || len(store.objects) != 1 {
+		t.Fatalf("metadata=%+v error=%v rows=%d objects=%d", metadata, err, repository.logicalCreates, len(store.objects))
 	}
 }
 
@@ -16,6 +16,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const callbackSecretNonceBytes = 12
@@ -38,8 +39,13 @@ func (EncryptedCallbackSecret) String() string   { return "[REDACTED ENCRYPTED C
 func (EncryptedCallbackSecret) GoString() string { return "[REDACTED ENCRYPTED CALLBACK SECRET]" }
 
 type CallbackMaterial struct {
-	CallbackID string
-	Secret     string
+	CallbackID  string
+	Destination string
+	Secret      string
+	EventTypes  []string
+	// PreviousSecretValidUntil is set when a rotation keeps signing with the
+	// replaced secret for a grace period.
+	PreviousSecretValidUntil *time.Time
 }
 
 func (CallbackMaterial) String() string   { return "[REDACTED CALLBACK MATERIAL]" }
@@ -0,0 +1,430 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaximumActiveCallbacks bounds the enabled callbacks of one tenant so the
+// management listing stays a single bounded read.
+const MaximumActiveCallbacks = 32
+
+// MaximumCallbackSecretGrace bounds how long a rotated callback secret keeps
+// signing deliveries next to its replacement.
+const MaximumCallbackSecretGrace = 7 * 24 * time.Hour
+
+// maximumCallbackDestinationBytes matches t_external_callback.destination_url.
+const maximumCallbackDestinationBytes = 2048
+
+var (
+	ErrCallbackNotFound           = errors.New("callback does not exist")
+	ErrCallbackLimitReached       = errors.New("active callback limit is reached")
+	ErrCallbackChanged            = errors.New("callback changed concurrently")
+	ErrInvalidCallbackDestination = errors.New("callback destination is invalid")
+	ErrInvalidCallbackEventTypes  = errors.New("callback event types are invalid")
+)
+
+// CallbackSummary is the tenant-visible description of an enabled callback.
+// The signing secret is never read back after creation or rotation.
+// EventTypes lists the optional events the callback subscribes to; terminal
+// job events are always delivered.
+type CallbackSummary struct {
+	CallbackID string    `json:"callbackId"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// PreviousSecretExpiresAt is the end of an operator rotation grace
+	// period. It is shown to operators only.
+	PreviousSecretExpiresAt *time.Time `json:"-"`
+}
+
+// canonicalCallbackEventTypes validates optional event subscriptions and
+// returns them in canonical order together with their SET column value.
+func canonicalCallbackEventTypes(eventTypes []string) ([]string, string, error) {
+	canonical := make([]string, 0, len(OptionalWebhookEventTypes))
+	for _, eventType := range OptionalWebhookEventTypes {
+		count := 0
+		for _, requested := range eventTypes {
+			if requested == eventType {
+				count++
+			}
+		}
+		if count > 1 {
+			return nil, "", fmt.Errorf("%w: %s is repeated", ErrInvalidCallbackEventTypes, eventType)
+		}
+		if count == 1 {
+			canonical = append(canonical, eventType)
+		}
+	}
+	if len(canonical) != len(eventTypes) {
+		return nil, "", fmt.Errorf("%w: only %s can be subscribed", ErrInvalidCallbackEventTypes, strings.Join(OptionalWebhookEventTypes, " and "))
+	}
+	return canonical, strings.Join(canonical, ","), nil
+}
+
+// splitCallbackEventTypes reads the optional_event_types SET column.
+func splitCallbackEventTypes(stored string) []string {
+	eventTypes := make([]string, 0, len(OptionalWebhookEventTypes))
+	for _, eventType := range strings.Split(stored, ",") {
+		if slices.Contains(OptionalWebhookEventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes
+}
+
+// ListCallbacks returns the enabled callbacks of an active tenant, oldest
+// first. Disabled callbacks cannot be re-enabled and are omitted.
+func (provisioner *Provisioner) ListCallbacks(ctx context.Context, tenantID string) ([]CallbackSummary, error) {
+	if provisioner == nil || provisioner.executor == nil {
+		return nil, fmt.Errorf("callback provisioner is not configured")
+	}
+	if !externalIDPattern.MatchString(tenantID) {
+		return nil, fmt.Errorf("tenant ID is invalid")
+	}
+	rows, err := provisioner.executor.QueryContext(ctx, `
+SELECT callback.external_id, callback.destination_url, callback.optional_event_types, callback.created_at,
+       IF(callback.previous_secret_expires_at > CURRENT_TIMESTAMP(3), callback.previous_secret_expires_at, NULL)
+FROM t_external_callback AS callback
+JOIN t_external_tenant AS tenant ON tenant.id = callback.tenant_id
+WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE' AND callback.disabled_at IS NULL
+ORDER BY callback.created_at, callback.id
+LIMIT ?`, tenantID, MaximumActiveCallbacks)
+	if err != nil {
+		return nil, fmt.Errorf("list callbacks: %w", err)
+	}
+	defer rows.Close()
+	callbacks := make([]CallbackSummary, 0)
+	for rows.Next() {
+		var callback CallbackSummary
+		var eventTypes string
+		var previousSecretExpiresAt sql.NullTime
+		if err := rows.Scan(&callback.CallbackID, &callback.URL, &eventTypes, &callback.CreatedAt, &previousSecretExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan callback: %w", err)
+		}
+		callback.EventTypes = splitCallbackEventTypes(eventTypes)
+		callback.PreviousSecretExpiresAt = nullableTimePointer(previousSecretExpiresAt)
+		callback.CreatedAt = callback.CreatedAt.UTC()
+		callbacks = append(callbacks, callback)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list callbacks: %w", err)
+	}
+	return callbacks, nil
+}
+
+// DisableCallback stops deliveries to a callback permanently. Pending
+// outbox rows are dead-lettered by the webhook worker on their next claim,
+// and new jobs can no longer reference the callback. The change runs under
+// the tenant row lock and is audited; disabling an already disabled
+// callback succeeds without a new audit row. A disabled tenant is reported
+// as ErrTenantDisabled.
+func (provisioner *Provisioner) DisableCallback(ctx context.Context, tenantID, callbackID string) error {
+	if !externalIDPattern.MatchString(callbackID) {
+		return ErrCallbackNotFound
+	}
+	return provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		if tenant.Status != TenantActive {
+			return ErrTenantDisabled
+		}
+		var id uint64
+		var host string
+		var disabled bool
+		err := tx.QueryRowContext(ctx, `
+SELECT id, allowed_host, disabled_at IS NOT NULL
+FROM t_external_callback
+WHERE tenant_id = ? AND external_id = ?
+FOR UPDATE`, internalID, callbackID).Scan(&id, &host, &disabled)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCallbackNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("read callback: %w", err)
+		}
+		if disabled {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE t_external_callback SET disabled_at = CURRENT_TIMESTAMP(3) WHERE id = ?", id); err != nil {
+			return fmt.Errorf("disable callback: %w", err)
+		}
+		return insertAdminAudit(ctx, tx, internalID, "callback.disabled", callbackID, map[string]string{"host": host})
+	})
+}
+
+// RotateCallbackSecret replaces the signing secret of an enabled callback
+// and returns the new secret once. The replaced secret stops signing at
+// once, ending any grace period of an earlier operator rotation. The update
+// is conditioned on the nonce that was read, so a concurrent rotation or
+// disable is reported as ErrCallbackChanged instead of being overwritten.
+func (provisioner *Provisioner) RotateCallbackSecret(ctx context.Context, tenantID, callbackID string) (CallbackMaterial, error) {
+	if provisioner == nil || provisioner.executor == nil || provisioner.random == nil || provisioner.callbackCipher == nil {
+		return CallbackMaterial{}, fmt.Errorf("callback provisioner is not configured")
+	}
+	if !externalIDPattern.MatchString(tenantID) {
+		return CallbackMaterial{}, fmt.Errorf("tenant ID is invalid")
+	}
+	if !externalIDPattern.MatchString(callbackID) {
+		return CallbackMaterial{}, ErrCallbackNotFound
+	}
+	rows, err := provisioner.executor.QueryContext(ctx, `
+SELECT callback.destination_url, callback.secret_nonce, callback.optional_event_types
+FROM t_external_callback AS callback
+JOIN t_external_tenant AS tenant ON tenant.id = callback.tenant_id
+WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'
+  AND callback.external_id = ? AND callback.disabled_at IS NULL`, tenantID, callbackID)
+	if err != nil {
+		return CallbackMaterial{}, fmt.Errorf("read callback: %w", err)
+	}
+	var destination, eventTypes string
+	var previousNonce []byte
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&destination, &previousNonce, &eventTypes)
+	}
+	if closeErr := rows.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		return CallbackMaterial{}, fmt.Errorf("read callback: %w", err)
+	}
+	if !found {
+		return CallbackMaterial{}, ErrCallbackNotFound
+	}
+	secret, encrypted, err := provisioner.newCallbackSecret(tenantID, callbackID, destination)
+	if err != nil {
+		return CallbackMaterial{}, err
+	}
+	defer clear(encrypted.Ciphertext)
+	defer clear(encrypted.Nonce)
+	result, err := provisioner.executor.ExecContext(ctx, `
+UPDATE t_external_callback AS callback
+JOIN t_external_tenant AS tenant ON tenant.id = callback.tenant_id
+SET callback.secret_ciphertext = ?, callback.secret_nonce = ?, callback.secret_key_version = ?,
+    callback.previous_secret_ciphertext = NULL, callback.previous_secret_nonce = NULL,
+    callback.previous_secret_key_version = NULL, callback.previous_secret_expires_at = NULL
+WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'
+  AND callback.external_id = ? AND callback.disabled_at IS NULL
+  AND callback.destination_url = ? AND callback.secret_nonce <=> ?`,
+		encrypted.Ciphertext, encrypted.Nonce, encrypted.KeyVersion,
+		tenantID, callbackID, destination, previousNonce)
+	if err != nil {
+		return CallbackMaterial{}, fmt.Errorf("rotate callback secret: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return CallbackMaterial{}, fmt.Errorf("confirm callback secret rotation: %w", err)
+	}
+	if affected != 1 {
+		return CallbackMaterial{}, ErrCallbackChanged
+	}
+	return CallbackMaterial{CallbackID: callbackID, Destination: destination, Secret: secret, EventTypes: splitCallbackEventTypes(eventTypes)}, nil
+}
+
+// lockedCallback is an enabled callback row read under FOR UPDATE by an
+// operator change. previous is set only while its grace period lasts.
+type lockedCallback struct {
+	id          uint64
+	destination string
+	host        string
+	eventTypes  string
+	secret      EncryptedCallbackSecret
+	previous    *EncryptedCallbackSecret
+}
+
+func lockEnabledCallback(ctx context.Context, tx *sql.Tx, tenantInternalID uint64, callbackID string) (lockedCallback, error) {
+	var callback lockedCallback
+	var previousCiphertext, previousNonce []byte
+	var previousVersion sql.NullInt64
+	err := tx.QueryRowContext(ctx, `
+SELECT id, destination_url, allowed_host, optional_event_types,
+       secret_ciphertext, secret_nonce, secret_key_version,
+       IF(previous_secret_expires_at > CURRENT_TIMESTAMP(3), previous_secret_ciphertext, NULL),
+       IF(previous_secret_expires_at > CURRENT_TIMESTAMP(3), previous_secret_nonce, NULL),
+       IF(previous_secret_expires_at > CURRENT_TIMESTAMP(3), previous_secret_key_version, NULL)
+FROM t_external_callback
+WHERE tenant_id = ? AND external_id = ? AND disabled_at IS NULL
+FOR UPDATE`, tenantInternalID, callbackID).Scan(
+		&callback.id, &callback.destination, &callback.host, &callback.eventTypes,
+		&callback.secret.Ciphertext, &callback.secret.Nonce, &callback.secret.KeyVersion,
+		&previousCiphertext, &previousNonce, &previousVersion,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return lockedCallback{}, ErrCallbackNotFound
+	}
+	if err != nil {
+		return lockedCallback{}, fmt.Errorf("read callback: %w", err)
+	}
+	if previousVersion.Valid {
+		callback.previous = &EncryptedCallbackSecret{Ciphertext: previousCiphertext, Nonce: previousNonce, KeyVersion: uint16(previousVersion.Int64)}
+	}
+	return callback, nil
+}
+
+// RotateCallbackSecretWithGrace replaces the signing secret of an enabled
+// callback and returns the new secret once. For grace the webhook worker
+// signs every delivery with both secrets, so a receiver can switch secrets
+// without rejecting deliveries. Only one replaced secret is kept: rotating
+// again inside a grace period drops the older one. A grace of zero behaves
+// like RotateCallbackSecret.
+func (provisioner *Provisioner) RotateCallbackSecretWithGrace(
+	ctx context.Context,
+	tenantID string,
+	callbackID string,
+	grace time.Duration,
+) (CallbackMaterial, error) {
+	if provisioner == nil || provisioner.random == nil || provisioner.callbackCipher == nil {
+		return CallbackMaterial{}, fmt.Errorf("callback provisioner is not configured")
+	}
+	if grace < 0 || grace > MaximumCallbackSecretGrace {
+		return CallbackMaterial{}, fmt.Errorf("callback secret grace must be between 0 and %s", MaximumCallbackSecretGrace)
+	}
+	if !externalIDPattern.MatchString(callbackID) {
+		return CallbackMaterial{}, ErrCallbackNotFound
+	}
+	var material CallbackMaterial
+	err := provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		if tenant.Status != TenantActive {
+			return ErrTenantDisabled
+		}
+		callback, err := lockEnabledCallback(ctx, tx, internalID, callbackID)
+		if err != nil {
+			return err
+		}
+		secret, encrypted, err := provisioner.newCallbackSecret(tenantID, callbackID, callback.destination)
+		if err != nil {
+			return err
+		}
+		defer clear(encrypted.Ciphertext)
+		defer clear(encrypted.Nonce)
+		// The replaced secret is copied from the values read under the row
+		// lock: MySQL does not order the assignments of one UPDATE by column.
+		var previousCiphertext, previousNonce, previousVersion, previousGrace any
+		if grace > 0 {
+			previousCiphertext, previousNonce, previousVersion = callback.secret.Ciphertext, callback.secret.Nonce, callback.secret.KeyVersion
+			previousGrace = grace.Microseconds()
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE t_external_callback
+SET secret_ciphertext = ?, secret_nonce = ?, secret_key_version = ?,
+    previous_secret_ciphertext = ?, previous_secret_nonce = ?, previous_secret_key_version = ?,
+    previous_secret_expires_at = CURRENT_TIMESTAMP(3) + INTERVAL ? MICROSECOND
+WHERE id = ?`,
+			encrypted.Ciphertext, encrypted.Nonce, encrypted.KeyVersion,
+			previousCiphertext, previousNonce, previousVersion, previousGrace, callback.id); err != nil {
+			return fmt.Errorf("rotate callback secret: %w", err)
+		}
+		var previousSecretValidUntil sql.NullTime
+		if err := tx.QueryRowContext(ctx,
+			"SELECT previous_secret_expires_at FROM t_external_callback WHERE id = ?", callback.id).
+			Scan(&previousSecretValidUntil); err != nil {
+			return fmt.Errorf("read rotated callback: %w", err)
+		}
+		material = CallbackMaterial{
+			CallbackID: callbackID, Destination: callback.destination, Secret: secret,
+			EventTypes: splitCallbackEventTypes(callback.eventTypes), PreviousSecretValidUntil: nullableTimePointer(previousSecretValidUntil),
+		}
+		return insertAdminAudit(ctx, tx, internalID, "callback.secret_rotated", callbackID, map[string]any{
+			"graceSeconds":             int64(grace / time.Second),
+			"previousSecretValidUntil": material.PreviousSecretValidUntil,
+		})
+	})
+	if err != nil {
+		return CallbackMaterial{}, err
+	}
+	return material, nil
+}
+
+// UpdateCallbackURL moves an enabled callback to a new destination after the
+// same public DNS validation as CreateCallback. Secrets are encrypted for
+// their destination, so the current secret, and a replaced secret inside its
+// grace period, are re-encrypted for the new one; the receiver keeps its
+// secret. Pending deliveries go to the new destination on their next
+// attempt. Setting the current destination again changes nothing and records
+// no audit entry.
+func (provisioner *Provisioner) UpdateCallbackURL(ctx context.Context, tenantID, callbackID, rawDestination string) (string, error) {
+	if provisioner == nil || provisioner.callbackCipher == nil || provisioner.callbackResolver == nil {
+		return "", fmt.Errorf("callback provisioner is not configured")
+	}
+	if !externalIDPattern.MatchString(callbackID) {
+		return "", ErrCallbackNotFound
+	}
+	destination, err := provisioner.publicCallbackDestination(ctx, rawDestination)
+	if err != nil {
+		return "", err
+	}
+	err = provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		if tenant.Status != TenantActive {
+			return ErrTenantDisabled
+		}
+		callback, err := lockEnabledCallback(ctx, tx, internalID, callbackID)
+		if err != nil {
+			return err
+		}
+		if callback.destination == destination.URL {
+			return nil
+		}
+		secret, err := provisioner.reencryptCallbackSecret(tenantID, callbackID, callback.destination, destination.URL, callback.secret)
+		if err != nil {
+			return err
+		}
+		defer clear(secret.Ciphertext)
+		defer clear(secret.Nonce)
+		var previousCiphertext, previousNonce, previousVersion any
+		if callback.previous != nil {
+			previous, err := provisioner.reencryptCallbackSecret(tenantID, callbackID, callback.destination, destination.URL, *callback.previous)
+			if err != nil {
+				return err
+			}
+			defer clear(previous.Ciphertext)
+			defer clear(previous.Nonce)
+			previousCiphertext, previousNonce, previousVersion = previous.Ciphertext, previous.Nonce, previous.KeyVersion
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE t_external_callback
+SET destination_url = ?, allowed_host = ?, allowed_port = ?,
+    secret_ciphertext = ?, secret_nonce = ?, secret_key_version = ?,
+    previous_secret_ciphertext = ?, previous_secret_nonce = ?, previous_secret_key_version = ?,
+    previous_secret_expires_at = IF(? IS NULL, NULL, previous_secret_expires_at)
+WHERE id = ?`,
+			destination.URL, destination.Host, destination.Port,
+			secret.Ciphertext, secret.Nonce, secret.KeyVersion,
+			previousCiphertext, previousNonce, previousVersion, previousVersion, callback.id); err != nil {
+			return fmt.Errorf("update callback destination: %w", err)
+		}
+		// Only hosts are audited; paths and queries may carry receiver tokens.
+		return insertAdminAudit(ctx, tx, internalID, "callback.url_updated", callbackID, map[string]string{
+			"previousHost": callback.host,
+			"host":         destination.Host,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return destination.URL, nil
+}
+
+// reencryptCallbackSecret moves an encrypted secret from one destination's
+// AAD to another's under the active key version.
+func (provisioner *Provisioner) reencryptCallbackSecret(
+	tenantID string,
+	callbackID string,
+	from string,
+	to string,
+	encrypted EncryptedCallbackSecret,
+) (EncryptedCallbackSecret, error) {
+	plaintext, err := provisioner.callbackCipher.Decrypt(tenantID, callbackID, from, encrypted)
+	defer clear(plaintext)
+	if err != nil {
+		return EncryptedCallbackSecret{}, err
+	}
+	return provisioner.callbackCipher.Encrypt(tenantID, callbackID, to, plaintext)
+}
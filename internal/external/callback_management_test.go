@@ -0,0 +1,258 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvisionerClassifiesCallbackCreationFailures(t *testing.T) {
+	callbackCipher, err := NewCallbackCipher(1, map[uint16][]byte{1: bytes.Repeat([]byte{1}, 32)}, bytes.NewReader(bytes.Repeat([]byte{2}, 12)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := callbackResolverStub{addresses: []netip.Addr{netip.MustParseAddr("8.8.8.8")}}
+	for name, test := range map[string]struct {
+		destination string
+		eventTypes  []string
+		resolver    callbackResolverStub
+		affected    int64
+		want        error
+	}{
+		"plain HTTP":     {destination: "http://oj.example.com/hook", resolver: public, affected: 1, want: ErrInvalidCallbackDestination},
+		"IP literal":     {destination: "https://8.8.8.8/hook", resolver: public, affected: 1, want: ErrInvalidCallbackDestination},
+		"too long":       {destination: "https://oj.example.com/" + strings.Repeat("a", 2048), resolver: public, affected: 1, want: ErrInvalidCallbackDestination},
+		"unresolvable":   {destination: "https://oj.example.com/hook", resolver: callbackResolverStub{err: errors.New("no such host")}, affected: 1, want: ErrInvalidCallbackDestination},
+		"private":        {destination: "https://oj.example.com/hook", resolver: callbackResolverStub{addresses: []netip.Addr{netip.MustParseAddr("10.0.0.1")}}, affected: 1, want: ErrUnsafeCallbackDestination},
+		"refusal lookup": {destination: "https://oj.example.com/hook", resolver: public, affected: 0, want: errProvisionerQueryNotStubbed},
+		"terminal event": {destination: "https://oj.example.com/hook", eventTypes: []string{"judge.job.completed"}, resolver: public, affected: 1, want: ErrInvalidCallbackEventTypes},
+		"repeated event": {destination: "https://oj.example.com/hook", eventTypes: []string{WebhookEventJobStarted, WebhookEventJobStarted}, resolver: public, affected: 1, want: ErrInvalidCallbackEventTypes},
+	} {
+		t.Run(name, func(t *testing.T) {
+			provisioner := &Provisioner{
+				executor: &provisionExecutorStub{affected: test.affected}, random: bytes.NewReader(bytes.Repeat([]byte{3}, externalIDRandomBytes+32)),
+				callbackCipher: callbackCipher, callbackResolver: test.resolver,
+			}
+			if _, err := provisioner.CreateCallback(context.Background(), "ceirceirceirceirceirceirce", test.destination, test.eventTypes); !errors.Is(err, test.want) {
+				t.Fatalf("error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestProvisionerManagesCallbacksOnMySQL(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID, otherTenantID := "ceirceirceirceirceirceirce", "ceirceirceirceirceirceircf"
+	insertTenantBundleAndCallback(t, database, tenantID, "bundlebundlebundlebundleaa", "", 10)
+	insertTenantBundleAndCallback(t, database, otherTenantID, "bundlebundlebundlebundleab", "", 10)
+	callbackCipher, err := NewCallbackCipher(3, map[uint16][]byte{3: bytes.Repeat([]byte{0x33}, 32)}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provisioner, err := NewProvisioner(database, rand.Reader, WithCallbackCipher(callbackCipher),
+		WithCallbackResolver(callbackResolverStub{addresses: []netip.Addr{netip.MustParseAddr("8.8.8.8")}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	created, err := provisioner.CreateCallback(ctx, tenantID, "https://oj.example.com/hooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCallback, err := provisioner.CreateCallback(ctx, otherTenantID, "https://other.example.com/hooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed, err := provisioner.ListCallbacks(ctx, tenantID)
+	if err != nil || len(listed) != 1 || listed[0].CallbackID != created.CallbackID || listed[0].URL != "https://oj.example.com:443/hooks" {
+		t.Fatalf("listed = %+v error=%v", listed, err)
+	}
+
+	rotated, err := provisioner.RotateCallbackSecret(ctx, tenantID, created.CallbackID)
+	if err != nil || rotated.Secret == created.Secret || rotated.Destination != created.Destination {
+		t.Fatalf("rotation error=%v changed=%t", err, rotated.Secret != created.Secret)
+	}
+	var encrypted EncryptedCallbackSecret
+	if err := database.QueryRowContext(ctx, `
+SELECT secret_ciphertext, secret_nonce, secret_key_version FROM t_external_callback WHERE external_id = ?`, created.CallbackID).
+		Scan(&encrypted.Ciphertext, &encrypted.Nonce, &encrypted.KeyVersion); err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := callbackCipher.Decrypt(tenantID, created.CallbackID, created.Destination, encrypted)
+	if err != nil || string(plaintext) != rotated.Secret {
+		t.Fatalf("stored secret does not match the rotated secret: %v", err)
+	}
+	if _, err := provisioner.RotateCallbackSecret(ctx, otherTenantID, created.CallbackID); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("cross-tenant rotation error = %v", err)
+	}
+
+	if err := provisioner.DisableCallback(ctx, otherTenantID, created.CallbackID); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("cross-tenant disable error = %v", err)
+	}
+	for range 2 {
+		if err := provisioner.DisableCallback(ctx, tenantID, created.CallbackID); err != nil {
+			t.Fatalf("disable error = %v", err)
+		}
+	}
+	if listed, err := provisioner.ListCallbacks(ctx, tenantID); err != nil || len(listed) != 0 {
+		t.Fatalf("listed after disable = %+v error=%v", listed, err)
+	}
+	if count := mustCount(t, database, "SELECT COUNT(*) FROM t_external_admin_audit WHERE action = 'callback.disabled' AND subject_external_id = '"+created.CallbackID+"'"); count != 1 {
+		t.Fatalf("disable audit rows = %d", count)
+	}
+	if _, err := provisioner.RotateCallbackSecret(ctx, tenantID, created.CallbackID); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("disabled rotation error = %v", err)
+	}
+
+	for range MaximumActiveCallbacks {
+		if _, err := provisioner.CreateCallback(ctx, tenantID, "https://oj.example.com/hooks", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := provisioner.CreateCallback(ctx, tenantID, "https://oj.example.com/hooks", nil); !errors.Is(err, ErrCallbackLimitReached) {
+		t.Fatalf("over-limit creation error = %v", err)
+	}
+	if _, err := provisioner.CreateCallback(ctx, "ceirceirceirceirceirceirzz", "https://oj.example.com/hooks", nil); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("unknown tenant creation error = %v", err)
+	}
+	if _, err := provisioner.DisableTenant(ctx, otherTenantID, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provisioner.CreateCallback(ctx, otherTenantID, "https://other.example.com/hooks", nil); !errors.Is(err, ErrTenantDisabled) || errors.Is(err, ErrCallbackLimitReached) {
+		t.Fatalf("disabled tenant creation error = %v", err)
+	}
+	if err := provisioner.DisableCallback(ctx, otherTenantID, otherCallback.CallbackID); !errors.Is(err, ErrTenantDisabled) {
+		t.Fatalf("disabled tenant callback disable error = %v", err)
+	}
+}
+
+func TestProvisionerValidatesCallbackURLUpdateBeforeLocking(t *testing.T) {
+	callbackCipher, err := NewCallbackCipher(1, map[uint16][]byte{1: bytes.Repeat([]byte{1}, 32)}, bytes.NewReader(bytes.Repeat([]byte{2}, 12)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, test := range map[string]struct {
+		callbackID  string
+		destination string
+		resolver    callbackResolverStub
+		want        error
+	}{
+		"unknown callback": {callbackID: "not-a-callback", destination: "https://oj.example.com/hook", want: ErrCallbackNotFound},
+		"plain HTTP":       {callbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", destination: "http://oj.example.com/hook", want: ErrInvalidCallbackDestination},
+		"unresolvable": {callbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", destination: "https://oj.example.com/hook",
+			resolver: callbackResolverStub{err: errors.New("no such host")}, want: ErrInvalidCallbackDestination},
+		"private": {callbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", destination: "https://oj.example.com/hook",
+			resolver: callbackResolverStub{addresses: []netip.Addr{netip.MustParseAddr("192.168.1.1")}}, want: ErrUnsafeCallbackDestination},
+	} {
+		t.Run(name, func(t *testing.T) {
+			provisioner := &Provisioner{callbackCipher: callbackCipher, callbackResolver: test.resolver}
+			if _, err := provisioner.UpdateCallbackURL(context.Background(), "ceirceirceirceirceirceirce", test.callbackID, test.destination); !errors.Is(err, test.want) {
+				t.Fatalf("error = %v, want %v", err, test.want)
+			}
+		})
+	}
+	provisioner := &Provisioner{random: rand.Reader, callbackCipher: callbackCipher}
+	if _, err := provisioner.RotateCallbackSecretWithGrace(context.Background(), "ceirceirceirceirceirceirce", "cbcbcbcbcbcbcbcbcbcbcbcbcb", MaximumCallbackSecretGrace+time.Second); err == nil {
+		t.Fatal("grace above the maximum was accepted")
+	}
+}
+
+func TestProvisionerRotatesCallbackSecretWithGraceAndMovesItOnMySQL(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := "ceirceirceirceirceirceirce"
+	insertTenantBundleAndCallback(t, database, tenantID, "bundlebundlebundlebundleaa", "", 10)
+	callbackCipher, err := NewCallbackCipher(3, map[uint16][]byte{3: bytes.Repeat([]byte{0x33}, 32)}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provisioner, err := NewProvisioner(database, rand.Reader, WithCallbackCipher(callbackCipher),
+		WithCallbackResolver(callbackResolverStub{addresses: []netip.Addr{netip.MustParseAddr("8.8.8.8")}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	readSecrets := func(callbackID, destination string) (string, string) {
+		t.Helper()
+		var current, previous EncryptedCallbackSecret
+		var previousVersion *uint16
+		if err := database.QueryRowContext(ctx, `
+SELECT secret_ciphertext, secret_nonce, secret_key_version,
+       previous_secret_ciphertext, previous_secret_nonce, previous_secret_key_version
+FROM t_external_callback WHERE external_id = ?`, callbackID).
+			Scan(&current.Ciphertext, &current.Nonce, &current.KeyVersion, &previous.Ciphertext, &previous.Nonce, &previousVersion); err != nil {
+			t.Fatal(err)
+		}
+		currentPlaintext, err := callbackCipher.Decrypt(tenantID, callbackID, destination, current)
+		if err != nil {
+			t.Fatalf("decrypt current secret: %v", err)
+		}
+		if previousVersion == nil {
+			return string(currentPlaintext), ""
+		}
+		previous.KeyVersion = *previousVersion
+		previousPlaintext, err := callbackCipher.Decrypt(tenantID, callbackID, destination, previous)
+		if err != nil {
+			t.Fatalf("decrypt previous secret: %v", err)
+		}
+		return string(currentPlaintext), string(previousPlaintext)
+	}
+
+	created, err := provisioner.CreateCallback(ctx, tenantID, "https://oj.example.com/hooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := provisioner.RotateCallbackSecretWithGrace(ctx, tenantID, created.CallbackID, time.Hour)
+	if err != nil || rotated.PreviousSecretValidUntil == nil || time.Until(*rotated.PreviousSecretValidUntil) <= 50*time.Minute {
+		t.Fatalf("rotation = %+v error=%v", rotated.PreviousSecretValidUntil, err)
+	}
+	if current, previous := readSecrets(created.CallbackID, created.Destination); current != rotated.Secret || previous != created.Secret {
+		t.Fatal("grace rotation did not keep the replaced secret")
+	}
+	listed, err := provisioner.ListCallbacks(ctx, tenantID)
+	if err != nil || len(listed) != 1 || listed[0].PreviousSecretExpiresAt == nil || !listed[0].PreviousSecretExpiresAt.Equal(*rotated.PreviousSecretValidUntil) {
+		t.Fatalf("listed = %+v error=%v", listed, err)
+	}
+
+	destination, err := provisioner.UpdateCallbackURL(ctx, tenantID, created.CallbackID, "https://new.example.com/hooks")
+	if err != nil || destination != "https://new.example.com:443/hooks" {
+		t.Fatalf("destination=%q error=%v", destination, err)
+	}
+	if current, previous := readSecrets(created.CallbackID, destination); current != rotated.Secret || previous != created.Secret {
+		t.Fatal("URL update did not re-encrypt both secrets for the new destination")
+	}
+	var allowedHost string
+	if err := database.QueryRowContext(ctx, "SELECT allowed_host FROM t_external_callback WHERE external_id = ?", created.CallbackID).Scan(&allowedHost); err != nil ||
+		allowedHost != "new.example.com" {
+		t.Fatalf("allowed host=%q error=%v", allowedHost, err)
+	}
+	if _, err := provisioner.UpdateCallbackURL(ctx, tenantID, created.CallbackID, "https://new.example.com/hooks"); err != nil {
+		t.Fatalf("repeated URL update = %v", err)
+	}
+
+	immediate, err := provisioner.RotateCallbackSecret(ctx, tenantID, created.CallbackID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current, previous := readSecrets(created.CallbackID, destination); current != immediate.Secret || previous != "" {
+		t.Fatal("immediate rotation did not end the grace period")
+	}
+	if _, err := provisioner.RotateCallbackSecretWithGrace(ctx, tenantID, "cbcbcbcbcbcbcbcbcbcbcbcbcb", time.Hour); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("unknown callback rotation = %v", err)
+	}
+	if count := mustCount(t, database, "SELECT COUNT(*) FROM t_external_admin_audit WHERE action = 'callback.secret_rotated'"); count != 1 {
+		t.Fatalf("rotation audit rows = %d", count)
+	}
+	if count := mustCount(t, database, "SELECT COUNT(*) FROM t_external_admin_audit WHERE action = 'callback.url_updated'"); count != 1 {
+		t.Fatalf("URL update audit rows = %d", count)
+	}
+}
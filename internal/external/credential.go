@@ -18,6 +18,8 @@ const (
 	ScopeJobSubmit        Scope = "job:submit"
 	ScopeJobRead          Scope = "job:read"
 	ScopeJobCancel        Scope = "job:cancel"
+	ScopeRunExecute       Scope = "run:execute"
+	ScopeCallbackWrite    Scope = "callback:write"
 )
 
 var validScopes = map[Scope]struct{}{
@@ -27,6 +29,8 @@ var validScopes = map[Scope]struct{}{
 	ScopeJobSubmit:        {},
 	ScopeJobRead:          {},
 	ScopeJobCancel:        {},
+	ScopeRunExecute:       {},
+	ScopeCallbackWrite:    {},
 }
 
 type Credential struct {
@@ -41,6 +45,12 @@ type CredentialStore interface {
 	FindCredentialByPrefix(context.Context, string) (*Credential, error)
 }
 
+// CredentialUsageRecorder is told about every successful authentication
+// with the key's lookup prefix. Implementations must not block on I/O.
+type CredentialUsageRecorder interface {
+	RecordCredentialUse(string, time.Time)
+}
+
 type credentialQueryer interface {
 	QueryRowContext(context.Context, string, ...any) rowScanner
 }
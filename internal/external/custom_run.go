@@ -0,0 +1,105 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+)
+
+var (
+	ErrCustomRunInvalid        = errors.New("custom run request is invalid")
+	ErrCustomRunCapacity       = errors.New("tenant concurrent custom run limit reached")
+	ErrDailyExecutionExhausted = errors.New("tenant daily execution budget is exhausted")
+	ErrCustomRunLeaseLost      = errors.New("custom run reservation lease was lost")
+)
+
+// MaximumRunInputBytes bounds both stdin and the optional expected output of
+// one custom run. Both are held in memory and echoed through one sandbox call.
+const MaximumRunInputBytes = 1 << 20
+
+// CustomRunRequest executes caller-owned source against caller-owned input
+// without a bundle. Zero limits select the tenant ceilings; larger values are
+// capped by them.
+type CustomRunRequest struct {
+	Language        string
+	SourceCode      []byte
+	Stdin           []byte
+	ExpectedOutput  []byte
+	CompareOutput   bool
+	TimeLimitMillis int
+	MemoryLimitMiB  int
+}
+
+// LogValue reports the request shape only; source and input never reach a log.
+func (request CustomRunRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("language", request.Language),
+		slog.Int("source_bytes", len(request.SourceCode)),
+		slog.Int("stdin_bytes", len(request.Stdin)),
+		slog.Bool("compare_output", request.CompareOutput),
+	)
+}
+
+func ValidateCustomRunRequest(request CustomRunRequest) error {
+	if !languageIDPattern.MatchString(request.Language) {
+		return fmt.Errorf("%w: language ID is invalid", ErrCustomRunInvalid)
+	}
+	if len(request.SourceCode) == 0 || len(request.SourceCode) > MaximumSourceBytes || !utf8.Valid(request.SourceCode) {
+		return fmt.Errorf("%w: source code is empty, oversized, or not UTF-8", ErrCustomRunInvalid)
+	}
+	if len(request.Stdin) > MaximumRunInputBytes || !utf8.Valid(request.Stdin) {
+		return fmt.Errorf("%w: stdin is oversized or not UTF-8", ErrCustomRunInvalid)
+	}
+	if !request.CompareOutput && len(request.ExpectedOutput) != 0 ||
+		len(request.ExpectedOutput) > MaximumRunInputBytes || !utf8.Valid(request.ExpectedOutput) {
+		return fmt.Errorf("%w: expected output is oversized or not UTF-8", ErrCustomRunInvalid)
+	}
+	if request.TimeLimitMillis < 0 || request.MemoryLimitMiB < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrCustomRunInvalid)
+	}
+	return nil
+}
+
+// CustomRunReservation is the daily-ledger reservation held while one run
+// executes. Its lease bounds how long a crashed pod can keep the
+// reservation before the next reservation of the same tenant refunds it.
+type CustomRunReservation struct {
+	InternalID       uint64
+	ExternalID       string
+	TenantInternalID uint64
+	TimeLimitMillis  int
+	MemoryLimitMiB   int
+	ReservedMillis   int64
+}
+
+// CustomRunSettlement releases a reservation. Measured time is capped by the
+// reservation; ChargeFullReservation covers runs without trusted
+// measurements, and InfrastructureFailure refunds a platform fault.
+type CustomRunSettlement struct {
+	ConsumedMillis        int64
+	ChargeFullReservation bool
+	InfrastructureFailure bool
+}
+
+type CustomRunResult struct {
+	RunID           string
+	Verdict         string
+	ExitCode        int
+	TimeMillis      int64
+	MemoryBytes     int64
+	Stdout          string
+	Stderr          string
+	CompileOutput   string
+	TimeLimitMillis int
+	MemoryLimitMiB  int
+}
+
+func (result CustomRunResult) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("run", result.RunID),
+		slog.String("verdict", result.Verdict),
+		slog.Int64("time_ms", result.TimeMillis),
+		slog.Int("stdout_bytes", len(result.Stdout)),
+	)
+}
@@ -0,0 +1,260 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type JobEventType string
+
+const (
+	JobEventStatus  JobEventType = "STATUS"
+	JobEventCompile JobEventType = "COMPILE"
+	JobEventCase    JobEventType = "CASE"
+)
+
+const (
+	MaximumJobEventPage = 500
+	// jobEventInsertChunk keeps one multi-row insert far below the MySQL
+	// placeholder limit even for a maximum-size progress report.
+	jobEventInsertChunk = 500
+)
+
+var ErrJobEventsInvalid = errors.New("job event request is invalid")
+
+// JobEvent is one durable step in a job's life. Sequence is strictly
+// increasing per job and doubles as the resume position for stream readers.
+// Compile and case events belong to AttemptNo; a later attempt starts over.
+type JobEvent struct {
+	Sequence      uint32
+	Type          JobEventType
+	AttemptNo     uint32
+	Status        JobStatus
+	FailureCode   string
+	CompileStatus string
+	Case          *DurableCaseResult
+	OccurredAt    time.Time
+}
+
+// JobEventPage holds events after the requested position. Status is read
+// before the events, so a terminal Status guarantees the terminal status event
+// is either in Events or at an earlier sequence.
+type JobEventPage struct {
+	Status JobStatus
+	Events []JobEvent
+}
+
+// JobProgress is partial evidence reported by the active attempt. It is
+// informational only; Complete remains the single source of the final result.
+type JobProgress struct {
+	CompileStatus string
+	Cases         []DurableCaseResult
+}
+
+type jobEventPayload struct {
+	Status        JobStatus          `json:"status,omitempty"`
+	FailureCode   string             `json:"failureCode,omitempty"`
+	CompileStatus string             `json:"compileStatus,omitempty"`
+	Case          *DurableCaseResult `json:"case,omitempty"`
+}
+
+type jobEventRow struct {
+	eventType JobEventType
+	payload   jobEventPayload
+}
+
+// appendJobStatusEvent records the status the job row holds inside tx. Every
+// caller has already locked or written that row, which serializes sequence
+// allocation for the job.
+func appendJobStatusEvent(ctx context.Context, tx *sql.Tx, now time.Time, job ExternalJobRecord) error {
+	payload := jobEventPayload{Status: job.Status}
+	if job.Status == JobStatusFailed {
+		payload.FailureCode = job.FailureCode
+	}
+	return appendJobEvents(ctx, tx, job.InternalID, job.TenantInternalID, job.AttemptNo, now,
+		jobEventRow{eventType: JobEventStatus, payload: payload})
+}
+
+func appendJobEvents(
+	ctx context.Context,
+	tx *sql.Tx,
+	jobID, tenantID uint64,
+	attemptNo uint32,
+	now time.Time,
+	rows ...jobEventRow,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	var lastSequence uint64
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(sequence_no), 0) FROM t_external_job_event WHERE job_id = ?",
+		jobID).Scan(&lastSequence); err != nil {
+		return repositoryUnavailable("read job event sequence", err)
+	}
+	if lastSequence+uint64(len(rows)) > uint64(^uint32(0)) {
+		return ErrExternalJobUnavailable
+	}
+	for start := 0; start < len(rows); start += jobEventInsertChunk {
+		chunk := rows[start:min(start+jobEventInsertChunk, len(rows))]
+		placeholders := make([]string, 0, len(chunk))
+		arguments := make([]any, 0, len(chunk)*7)
+		for index, row := range chunk {
+			payloadJSON, err := json.Marshal(row.payload)
+			if err != nil {
+				return repositoryUnavailable("encode job event", err)
+			}
+			placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+			arguments = append(arguments,
+				jobID, lastSequence+uint64(start+index+1), tenantID, attemptNo,
+				row.eventType, payloadJSON, now)
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_job_event(job_id, sequence_no, tenant_id, attempt_no, event_type, payload_json, created_at)
+VALUES `+strings.Join(placeholders, ", "), arguments...); err != nil {
+			return repositoryUnavailable("persist job events", err)
+		}
+	}
+	return nil
+}
+
+// RecordProgress appends compile and case events for the active claim and,
+// when the job's callback subscribes to judge.job.progress, refreshes its
+// coalesced progress webhook in the same transaction. The lease fence makes a stale worker's late report fail with ErrStaleJobClaim
+// instead of interleaving with the attempt that replaced it.
+func (repository *MySQLJobRepository) RecordProgress(ctx context.Context, claim WorkerJobClaim, progress JobProgress) error {
+	if repository == nil || !validWorkerClaim(claim) || validateJobProgress(progress) != nil {
+		return ErrInvalidJobState
+	}
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return repositoryUnavailable("begin progress", err)
+	}
+	defer tx.Rollback()
+	now, err := mysqlCurrentTime(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if _, err := lockActiveClaim(ctx, tx, claim); err != nil {
+		return err
+	}
+	rows := make([]jobEventRow, 0, len(progress.Cases)+1)
+	if progress.CompileStatus != "" {
+		rows = append(rows, jobEventRow{eventType: JobEventCompile, payload: jobEventPayload{CompileStatus: progress.CompileStatus}})
+	}
+	for _, item := range progress.Cases {
+		rows = append(rows, jobEventRow{eventType: JobEventCase, payload: jobEventPayload{Case: &item}})
+	}
+	if err := appendJobEvents(ctx, tx, claim.Job.InternalID, claim.Job.TenantInternalID, claim.AttemptNo, now, rows...); err != nil {
+		return err
+	}
+	if err := repository.recordJobProgressWebhookEvent(ctx, tx, now, claim, progress); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return repositoryUnavailable("commit progress", err)
+	}
+	return nil
+}
+
+func validateJobProgress(progress JobProgress) error {
+	switch progress.CompileStatus {
+	case "", "SUCCEEDED", "FAILED":
+	default:
+		return ErrInvalidJobState
+	}
+	if progress.CompileStatus == "" && len(progress.Cases) == 0 || len(progress.Cases) > MaximumBundleCases {
+		return ErrInvalidJobState
+	}
+	for _, item := range progress.Cases {
+		if strings.TrimSpace(item.CaseID) == "" || len(item.CaseID) > 128 ||
+			strings.TrimSpace(item.Verdict) == "" || len(item.Verdict) > 64 ||
+			item.TimeMillis < 0 || item.MemoryBytes < 0 || !validScorePair(item.Score, item.MaxScore) {
+			return ErrInvalidJobState
+		}
+	}
+	return nil
+}
+
+// ListJobEvents returns up to limit events after afterSequence for a job the
+// tenant owns. Jobs of other tenants are indistinguishable from missing jobs.
+func (repository *MySQLJobRepository) ListJobEvents(
+	ctx context.Context,
+	tenantExternalID, jobExternalID string,
+	afterSequence uint32,
+	limit int,
+) (JobEventPage, error) {
+	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) || !externalIDPattern.MatchString(jobExternalID) {
+		return JobEventPage{}, ErrExternalJobNotFound
+	}
+	if limit < 1 || limit > MaximumJobEventPage {
+		return JobEventPage{}, ErrJobEventsInvalid
+	}
+	var jobInternalID uint64
+	var page JobEventPage
+	err := repository.database.QueryRowContext(ctx, `
+SELECT job.id, job.status
+FROM t_external_job job
+JOIN t_external_tenant tenant ON tenant.id = job.tenant_id
+WHERE tenant.external_id = ? AND job.external_id = ?`,
+		tenantExternalID, jobExternalID).Scan(&jobInternalID, &page.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return JobEventPage{}, ErrExternalJobNotFound
+	}
+	if err != nil {
+		return JobEventPage{}, repositoryUnavailable("read job event owner", err)
+	}
+	rows, err := repository.database.QueryContext(ctx, `
+SELECT sequence_no, event_type, attempt_no, payload_json, created_at
+FROM t_external_job_event
+WHERE job_id = ? AND sequence_no > ?
+ORDER BY sequence_no
+LIMIT ?`, jobInternalID, afterSequence, limit)
+	if err != nil {
+		return JobEventPage{}, repositoryUnavailable("list job events", err)
+	}
+	defer rows.Close()
+	page.Events = make([]JobEvent, 0, limit)
+	for rows.Next() {
+		var event JobEvent
+		var payloadJSON []byte
+		if err := rows.Scan(&event.Sequence, &event.Type, &event.AttemptNo, &payloadJSON, &event.OccurredAt); err != nil {
+			return JobEventPage{}, repositoryUnavailable("scan job event", err)
+		}
+		if err := decodeJobEventPayload(&event, payloadJSON); err != nil {
+			return JobEventPage{}, repositoryUnavailable("decode job event", err)
+		}
+		page.Events = append(page.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return JobEventPage{}, repositoryUnavailable("list job events", err)
+	}
+	return page, nil
+}
+
+func decodeJobEventPayload(event *JobEvent, payloadJSON []byte) error {
+	var payload jobEventPayload
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return err
+	}
+	valid := false
+	switch event.Type {
+	case JobEventStatus:
+		valid = payload.Status != ""
+	case JobEventCompile:
+		valid = payload.CompileStatus != ""
+	case JobEventCase:
+		valid = payload.Case != nil
+	}
+	if !valid {
+		return fmt.Errorf("job event %d has an invalid %s payload", event.Sequence, event.Type)
+	}
+	event.Status, event.FailureCode = payload.Status, payload.FailureCode
+	event.CompileStatus, event.Case = payload.CompileStatus, payload.Case
+	return nil
+}
@@ -0,0 +1,53 @@
+package external
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateJobProgressRequiresBoundedEvidence(t *testing.T) {
+	score, maximum := 0, 10
+	valid := DurableCaseResult{CaseID: "case-1", Verdict: "WRONG_ANSWER", TimeMillis: 3, MemoryBytes: 1024, Score: &score, MaxScore: &maximum}
+	for name, progress := range map[string]JobProgress{
+		"compile only": {CompileStatus: "FAILED"},
+		"cases only":   {Cases: []DurableCaseResult{valid}},
+	} {
+		if err := validateJobProgress(progress); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for name, progress := range map[string]JobProgress{
+		"empty":            {},
+		"unknown compile":  {CompileStatus: "PENDING"},
+		"blank case":       {Cases: []DurableCaseResult{{Verdict: "ACCEPTED"}}},
+		"long case ID":     {Cases: []DurableCaseResult{{CaseID: strings.Repeat("c", 129), Verdict: "ACCEPTED"}}},
+		"negative time":    {Cases: []DurableCaseResult{{CaseID: "case-1", Verdict: "ACCEPTED", TimeMillis: -1}}},
+		"unpaired score":   {Cases: []DurableCaseResult{{CaseID: "case-1", Verdict: "ACCEPTED", Score: &score}}},
+		"excess case list": {Cases: make([]DurableCaseResult, MaximumBundleCases+1)},
+	} {
+		if err := validateJobProgress(progress); err == nil {
+			t.Errorf("%s: invalid progress was accepted", name)
+		}
+	}
+}
+
+func TestDecodeJobEventPayloadRejectsMismatchedType(t *testing.T) {
+	event := JobEvent{Sequence: 4, Type: JobEventCase}
+	if err := decodeJobEventPayload(&event, []byte(`{"case":{"caseId":"case-1","verdict":"ACCEPTED","timeMillis":1,"memoryBytes":2}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if event.Case == nil || event.Case.CaseID != "case-1" || event.Status != "" {
+		t.Fatalf("event = %+v", event)
+	}
+	for eventType, payload := range map[JobEventType]string{
+		JobEventStatus:  `{"compileStatus":"SUCCEEDED"}`,
+		JobEventCompile: `{"status":"RUNNING"}`,
+		JobEventCase:    `{}`,
+		"UNKNOWN":       `{"status":"RUNNING"}`,
+	} {
+		event := JobEvent{Sequence: 1, Type: eventType}
+		if err := decodeJobEventPayload(&event, []byte(payload)); err == nil {
+			t.Errorf("%s payload %s was accepted", eventType, payload)
+		}
+	}
+}
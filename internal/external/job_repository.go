@@ -8,8 +8,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
 )
@@ -58,7 +60,9 @@ type ExternalJobRecord struct {
 	Status           JobStatus
 	Language         string
 	StopOnFailure    bool
+	Priority         int
 	ClientReference  string
+	RejudgeOf        string
 	AttemptNo        uint32
 	WorkerID         string
 	LeaseUntil       *time.Time
@@ -68,6 +72,9 @@ type ExternalJobRecord struct {
 	CreatedAt        time.Time
 	StartedAt        *time.Time
 	CompletedAt      *time.Time
+	// TraceParent is the W3C traceparent of the admitting request, kept so a
+	// worker attempt can link back to it. Empty when no trace was active.
+	TraceParent string
 }
 
 type SubmitJobResult struct {
@@ -75,10 +82,17 @@ type SubmitJobResult struct {
 	Replayed bool
 }
 
+// JobListOptions filters a tenant's job list. Zero values do not filter;
+// CreatedAfter is inclusive and CreatedBefore exclusive.
 type JobListOptions struct {
-	Cursor string
-	Limit  int
-	Status JobStatus
+	Cursor          string
+	Limit           int
+	Status          JobStatus
+	BundleID        string
+	Language        string
+	ClientReference string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
 }
 
 type JobListResult struct {
@@ -86,6 +100,13 @@ type JobListResult struct {
 	NextCursor string
 }
 
+// TenantJobDepth counts one tenant's jobs in one non-terminal status.
+type TenantJobDepth struct {
+	TenantExternalID string
+	Status           JobStatus
+	Jobs             int
+}
+
 type WorkerJobClaim struct {
 	Job        ExternalJobRecord
 	WorkerID   string
@@ -109,6 +130,14 @@ type WorkerExecutionInput struct {
 	Bundle        WorkerBundleInput
 }
 
+func (input WorkerExecutionInput) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("language", input.Language),
+		slog.Int("source_bytes", len(input.SourceCode)),
+		slog.String("bundle_sha256", input.Bundle.SHA256),
+	)
+}
+
 func (claim WorkerJobClaim) String() string {
 	return fmt.Sprintf("WorkerJobClaim{JobID:%s Status:%s WorkerID:%s AttemptNo:%d LeaseUntil:%s}",
 		claim.Job.ExternalID, claim.Job.Status, claim.WorkerID, claim.AttemptNo, claim.LeaseUntil.UTC().Format(time.RFC3339Nano))
@@ -132,18 +161,31 @@ const (
 )
 
 type JobCursor struct {
-	TenantID   string
-	Status     JobStatus
-	CreatedAt  time.Time
-	InternalID uint64
+	TenantID        string
+	Status          JobStatus
+	BundleID        string
+	Language        string
+	ClientReference string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	CreatedAt       time.Time
+	InternalID      uint64
 }
 
+// jobCursorPayload omits unset filters, so cursors issued before the filters
+// existed still decode for an unfiltered list. The client reference is bound
+// by digest to keep the cursor within its length limit.
 type jobCursorPayload struct {
-	Version    int       `json:"v"`
-	TenantID   string    `json:"t"`
-	Status     JobStatus `json:"s,omitempty"`
-	CreatedMS  int64     `json:"c"`
-	InternalID uint64    `json:"i"`
+	Version         int       `json:"v"`
+	TenantID        string    `json:"t"`
+	Status          JobStatus `json:"s,omitempty"`
+	BundleID        string    `json:"u,omitempty"`
+	Language        string    `json:"l,omitempty"`
+	ClientReference string    `json:"r,omitempty"`
+	CreatedAfter    int64     `json:"a,omitempty"`
+	CreatedBefore   int64     `json:"b,omitempty"`
+	CreatedMS       int64     `json:"c"`
+	InternalID      uint64    `json:"i"`
 }
 
 type JobCursorCodec struct{ key []byte }
@@ -161,64 +203,99 @@ func (codec *JobCursorCodec) Encode(cursor JobCursor) (string, error) {
 	}
 	payload, err := json.Marshal(jobCursorPayload{
 		Version: 1, TenantID: cursor.TenantID, Status: cursor.Status,
+		BundleID: cursor.BundleID, Language: cursor.Language, ClientReference: clientReferenceDigest(cursor.ClientReference),
+		CreatedAfter: unixMilliOrZero(cursor.CreatedAfter), CreatedBefore: unixMilliOrZero(cursor.CreatedBefore),
 		CreatedMS: cursor.CreatedAt.UTC().UnixMilli(), InternalID: cursor.InternalID,
 	})
 	if err != nil {
 		return "", fmt.Errorf("%w: encode payload", ErrInvalidJobCursor)
 	}
+	return codec.sign(payload), nil
+}
+
+// Decode accepts only a cursor issued for the same tenant and filters.
+func (codec *JobCursorCodec) Decode(encoded, tenantID string, options JobListOptions) (JobCursor, error) {
+	payload, ok := codec.verify(encoded)
+	if !ok {
+		return JobCursor{}, ErrInvalidJobCursor
+	}
+	var decoded jobCursorPayload
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decoded); err != nil || decoded.Version != 1 {
+		return JobCursor{}, ErrInvalidJobCursor
+	}
+	if decoded.TenantID != tenantID || decoded.Status != options.Status || decoded.BundleID != options.BundleID ||
+		decoded.Language != options.Language || decoded.ClientReference != clientReferenceDigest(options.ClientReference) ||
+		decoded.CreatedAfter != unixMilliOrZero(options.CreatedAfter) || decoded.CreatedBefore != unixMilliOrZero(options.CreatedBefore) {
+		return JobCursor{}, ErrInvalidJobCursor
+	}
+	cursor := JobCursor{
+		TenantID: decoded.TenantID, Status: decoded.Status,
+		BundleID: options.BundleID, Language: options.Language, ClientReference: options.ClientReference,
+		CreatedAfter: options.CreatedAfter, CreatedBefore: options.CreatedBefore,
+		CreatedAt: time.UnixMilli(decoded.CreatedMS).UTC(), InternalID: decoded.InternalID,
+	}
+	if !validJobCursor(cursor) {
+		return JobCursor{}, ErrInvalidJobCursor
+	}
+	return cursor, nil
+}
+
+func clientReferenceDigest(clientReference string) string {
+	if clientReference == "" {
+		return ""
+	}
+	digest := sha256.Sum256([]byte(clientReference))
+	return base64.RawURLEncoding.EncodeToString(digest[:16])
+}
+
+// sign returns the payload and its HMAC as two base64url segments.
+func (codec *JobCursorCodec) sign(payload []byte) string {
 	signature := hmac.New(sha256.New, codec.key)
 	_, _ = signature.Write(payload)
 	return base64.RawURLEncoding.EncodeToString(payload) + "." +
-		base64.RawURLEncoding.EncodeToString(signature.Sum(nil)), nil
+		base64.RawURLEncoding.EncodeToString(signature.Sum(nil))
 }
 
-func (codec *JobCursorCodec) Decode(encoded, tenantID string, status JobStatus) (JobCursor, error) {
+// verify returns the payload of a cursor this codec signed.
+func (codec *JobCursorCodec) verify(encoded string) ([]byte, bool) {
 	if codec == nil || len(codec.key) < sha256.Size || len(encoded) == 0 || len(encoded) > 512 {
-		return JobCursor{}, ErrInvalidJobCursor
+		return nil, false
 	}
 	parts := strings.Split(encoded, ".")
 	if len(parts) != 2 {
-		return JobCursor{}, ErrInvalidJobCursor
+		return nil, false
 	}
 	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
 	if err != nil {
-		return JobCursor{}, ErrInvalidJobCursor
+		return nil, false
 	}
 	providedSignature, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return JobCursor{}, ErrInvalidJobCursor
+		return nil, false
 	}
 	expectedSignature := hmac.New(sha256.New, codec.key)
 	_, _ = expectedSignature.Write(payload)
-	if !hmac.Equal(providedSignature, expectedSignature.Sum(nil)) {
-		return JobCursor{}, ErrInvalidJobCursor
-	}
-	var decoded jobCursorPayload
-	decoder := json.NewDecoder(strings.NewReader(string(payload)))
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(&decoded); err != nil || decoded.Version != 1 {
-		return JobCursor{}, ErrInvalidJobCursor
-	}
-	cursor := JobCursor{
-		TenantID: decoded.TenantID, Status: decoded.Status,
-		CreatedAt: time.UnixMilli(decoded.CreatedMS).UTC(), InternalID: decoded.InternalID,
-	}
-	if cursor.TenantID != tenantID || cursor.Status != status || !validJobCursor(cursor) {
-		return JobCursor{}, ErrInvalidJobCursor
-	}
-	return cursor, nil
+	return payload, hmac.Equal(providedSignature, expectedSignature.Sum(nil))
 }
 
 func validJobCursor(cursor JobCursor) bool {
 	if !externalIDPattern.MatchString(cursor.TenantID) || cursor.CreatedAt.IsZero() || cursor.InternalID == 0 {
 		return false
 	}
-	switch cursor.Status {
-	case "", JobStatusQueued, JobStatusRunning, JobStatusSucceeded, JobStatusFailed, JobStatusCancelled:
-		return true
-	default:
-		return false
-	}
+	return validJobListFilter(JobListOptions{
+		Status: cursor.Status, BundleID: cursor.BundleID, Language: cursor.Language, ClientReference: cursor.ClientReference,
+		CreatedAfter: cursor.CreatedAfter, CreatedBefore: cursor.CreatedBefore,
+	})
+}
+
+func validJobListFilter(options JobListOptions) bool {
+	return validJobStatusFilter(options.Status) &&
+		(options.BundleID == "" || externalIDPattern.MatchString(options.BundleID)) &&
+		(options.Language == "" || languageIDPattern.MatchString(options.Language)) &&
+		len(options.ClientReference) <= 255 && utf8.ValidString(options.ClientReference) &&
+		(options.CreatedAfter.IsZero() || options.CreatedBefore.IsZero() || options.CreatedAfter.Before(options.CreatedBefore))
 }
 
 func SourceObjectKey(tenantID, sourceID string) (string, error) {
@@ -23,7 +23,7 @@ func TestJobCursorIsAuthenticatedAndBoundToTenantAndFilter(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	got, err := codec.Decode(encoded, want.TenantID, want.Status)
+	got, err := codec.Decode(encoded, want.TenantID, JobListOptions{Status: want.Status})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -36,17 +36,67 @@ func TestJobCursorIsAuthenticatedAndBoundToTenantAndFilter(t *testing.T) {
 		t.Fatalf("cursor parts = %d", len(parts))
 	}
 	tampered := parts[0] + "." + strings.Repeat("A", len(parts[1]))
-	if _, err := codec.Decode(tampered, want.TenantID, want.Status); !errors.Is(err, ErrInvalidJobCursor) {
+	if _, err := codec.Decode(tampered, want.TenantID, JobListOptions{Status: want.Status}); !errors.Is(err, ErrInvalidJobCursor) {
 		t.Fatalf("tampered cursor error = %v", err)
 	}
-	if _, err := codec.Decode(encoded, "bbbbbbbbbbbbbbbbbbbbbbbbbb", want.Status); !errors.Is(err, ErrInvalidJobCursor) {
+	if _, err := codec.Decode(encoded, "bbbbbbbbbbbbbbbbbbbbbbbbbb", JobListOptions{Status: want.Status}); !errors.Is(err, ErrInvalidJobCursor) {
 		t.Fatalf("cross-tenant cursor error = %v", err)
 	}
-	if _, err := codec.Decode(encoded, want.TenantID, JobStatusRunning); !errors.Is(err, ErrInvalidJobCursor) {
+	if _, err := codec.Decode(encoded, want.TenantID, JobListOptions{Status: JobStatusRunning}); !errors.Is(err, ErrInvalidJobCursor) {
 		t.Fatal("wrong tenant/status call unexpectedly succeeded")
 	}
 }
 
+func TestJobCursorIsBoundToEverySearchFilter(t *testing.T) {
+	codec, err := NewJobCursorCodec([]byte(strings.Repeat("c", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := JobListOptions{
+		BundleID: "bbbbbbbbbbbbbbbbbbbbbbbbbb", Language: "cpp", ClientReference: strings.Repeat("Ä", 127),
+		CreatedAfter:  time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		CreatedBefore: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+	want := JobCursor{
+		TenantID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", BundleID: filter.BundleID, Language: filter.Language, ClientReference: filter.ClientReference,
+		CreatedAfter: filter.CreatedAfter, CreatedBefore: filter.CreatedBefore,
+		CreatedAt: time.Date(2026, 7, 19, 10, 11, 12, 123000000, time.UTC), InternalID: 42,
+	}
+	encoded, err := codec.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) > 512 {
+		t.Fatalf("cursor length = %d", len(encoded))
+	}
+	if got, err := codec.Decode(encoded, want.TenantID, filter); err != nil || got != want {
+		t.Fatalf("decoded=%+v err=%v", got, err)
+	}
+	for name, change := range map[string]func(*JobListOptions){
+		"bundle":           func(options *JobListOptions) { options.BundleID = "" },
+		"language":         func(options *JobListOptions) { options.Language = "python" },
+		"client reference": func(options *JobListOptions) { options.ClientReference = strings.Repeat("ä", 127) },
+		"created after":    func(options *JobListOptions) { options.CreatedAfter = options.CreatedAfter.Add(time.Millisecond) },
+		"created before":   func(options *JobListOptions) { options.CreatedBefore = time.Time{} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			changed := filter
+			change(&changed)
+			if _, err := codec.Decode(encoded, want.TenantID, changed); !errors.Is(err, ErrInvalidJobCursor) {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+
+	unfiltered, err := codec.Encode(JobCursor{TenantID: want.TenantID, CreatedAt: want.CreatedAt, InternalID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.Decode(unfiltered, want.TenantID, filter); !errors.Is(err, ErrInvalidJobCursor) {
+		t.Fatalf("unfiltered cursor accepted for a filtered list: %v", err)
+	}
+}
+
 func TestJobCursorCodecRejectsWeakKeysAndInvalidPositions(t *testing.T) {
 	if _, err := NewJobCursorCodec([]byte("weak")); err == nil {
 		t.Fatal("weak cursor key accepted")
@@ -100,3 +150,59 @@ func TestWorkerClaimFormattingRedactsSourceMetadataAndLeaseToken(t *testing.T) {
 		}
 	}
 }
+
+func TestBundleRejudgeCursorIsBoundToTheFanOut(t *testing.T) {
+	codec, err := NewJobCursorCodec([]byte(strings.Repeat("c", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bound := BundleRejudgeCursor{
+		TenantID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", BundleID: "bbbbbbbbbbbbbbbbbbbbbbbbbb",
+		ReplacementBundleID: "cccccccccccccccccccccccccc", Priority: 1,
+	}
+	want := bound
+	want.InternalID = 42
+	encoded, err := codec.EncodeBundleRejudge(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := codec.DecodeBundleRejudge(encoded, bound); err != nil || got != want {
+		t.Fatalf("decoded cursor = %+v, %v", got, err)
+	}
+	for name, other := range map[string]func(*BundleRejudgeCursor){
+		"tenant":      func(cursor *BundleRejudgeCursor) { cursor.TenantID = "dddddddddddddddddddddddddd" },
+		"bundle":      func(cursor *BundleRejudgeCursor) { cursor.BundleID = "dddddddddddddddddddddddddd" },
+		"replacement": func(cursor *BundleRejudgeCursor) { cursor.ReplacementBundleID = "" },
+		"priority":    func(cursor *BundleRejudgeCursor) { cursor.Priority = 0 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			mismatched := bound
+			other(&mismatched)
+			if _, err := codec.DecodeBundleRejudge(encoded, mismatched); !errors.Is(err, ErrInvalidJobCursor) {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+
+	listCursor, err := codec.Encode(JobCursor{TenantID: bound.TenantID, CreatedAt: time.Now(), InternalID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.DecodeBundleRejudge(listCursor, bound); !errors.Is(err, ErrInvalidJobCursor) {
+		t.Fatalf("job list cursor accepted as a rejudge cursor: %v", err)
+	}
+	if _, err := codec.Decode(encoded, bound.TenantID, JobListOptions{}); !errors.Is(err, ErrInvalidJobCursor) {
+		t.Fatalf("rejudge cursor accepted as a job list cursor: %v", err)
+	}
+}
+
+func TestBundleRejudgeItemKeysAreValidAndScopedToTheRequestKey(t *testing.T) {
+	jobID := "aaaaaaaaaaaaaaaaaaaaaaaaaa"
+	first := bundleRejudgeItemKeyPrefix("bundle-rejudge-0001") + jobID
+	if err := ValidateIdempotencyKey(first); err != nil {
+		t.Fatalf("derived key %q: %v", first, err)
+	}
+	if first != bundleRejudgeItemKeyPrefix("bundle-rejudge-0001")+jobID || first == bundleRejudgeItemKeyPrefix("bundle-rejudge-0002")+jobID {
+		t.Fatal("derived keys are not a function of the request key")
+	}
+}
@@ -6,12 +6,18 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"regexp"
 	"unicode/utf8"
 )
 
 var languageIDPattern = regexp.MustCompile(`^[a-z][a-z0-9._-]{1,31}$`)
 
+// MaximumJobPriority is the highest priority class a tenant policy can grant.
+// Priority 0 is the default class; workers claim higher classes first within
+// a tenant.
+const MaximumJobPriority = 9
+
 type JudgeJobRequest struct {
 	BundleID        string
 	Language        string
@@ -19,6 +25,24 @@ type JudgeJobRequest struct {
 	StopOnFailure   bool
 	CallbackID      string
 	ClientReference string
+	Priority        int
+	// RejudgeOf names the job whose stored source this request re-runs. It is
+	// set only by the rejudge paths, never from client input.
+	RejudgeOf string
+	// rejudgeHash, set by the rejudge paths, replaces the canonical request
+	// hash as the idempotency identity of the request.
+	rejudgeHash []byte
+}
+
+// LogValue reports the request shape only; the source never reaches a log.
+func (request JudgeJobRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("bundle", request.BundleID),
+		slog.String("language", request.Language),
+		slog.Int("source_bytes", len(request.SourceCode)),
+		slog.Bool("stop_on_failure", request.StopOnFailure),
+		slog.Int("priority", request.Priority),
+	)
 }
 
 func ValidateIdempotencyKey(key string) error {
@@ -61,6 +85,12 @@ func CanonicalJobRequestHash(request JudgeJobRequest, maxSourceBytes int64) ([]b
 	if len(request.ClientReference) > 255 || !utf8.ValidString(request.ClientReference) {
 		return nil, fmt.Errorf("client reference is oversized or not UTF-8")
 	}
+	if request.Priority < 0 || request.Priority > MaximumJobPriority {
+		return nil, fmt.Errorf("priority must be between 0 and %d", MaximumJobPriority)
+	}
+	if request.RejudgeOf != "" && !externalIDPattern.MatchString(request.RejudgeOf) {
+		return nil, fmt.Errorf("rejudged job ID is invalid")
+	}
 	sourceDigest := sha256.Sum256(request.SourceCode)
 	canonical := struct {
 		BundleID        string `json:"bundleId"`
@@ -70,6 +100,10 @@ func CanonicalJobRequestHash(request JudgeJobRequest, maxSourceBytes int64) ([]b
 		StopOnFailure   bool   `json:"stopOnFailure"`
 		CallbackID      string `json:"callbackId,omitempty"`
 		ClientReference string `json:"clientReference,omitempty"`
+		// Omitted at the default class so hashes recorded before priorities
+		// existed still match their replays.
+		Priority  int    `json:"priority,omitempty"`
+		RejudgeOf string `json:"rejudgeOf,omitempty"`
 	}{
 		BundleID:        request.BundleID,
 		Language:        request.Language,
@@ -78,6 +112,8 @@ func CanonicalJobRequestHash(request JudgeJobRequest, maxSourceBytes int64) ([]b
 		StopOnFailure:   request.StopOnFailure,
 		CallbackID:      request.CallbackID,
 		ClientReference: request.ClientReference,
+		Priority:        request.Priority,
+		RejudgeOf:       request.RejudgeOf,
 	}
 	encoded, err := json.Marshal(canonical)
 	if err != nil {
@@ -86,3 +122,29 @@ func CanonicalJobRequestHash(request JudgeJobRequest, maxSourceBytes int64) ([]b
 	digest := sha256.Sum256(encoded)
 	return append([]byte(nil), digest[:]...), nil
 }
+
+// CanonicalRejudgeRequestHash is the idempotency identity of a rejudge. It
+// covers the rejudged job and what the client sent, never the source or
+// callback the server copies from the original, so a retry replays no matter
+// how the original's callback changed in between.
+func CanonicalRejudgeRequestHash(rejudgeOf string, options RejudgeOptions) ([]byte, error) {
+	if !externalIDPattern.MatchString(rejudgeOf) {
+		return nil, fmt.Errorf("rejudged job ID is invalid")
+	}
+	if options.BundleID != "" && !externalIDPattern.MatchString(options.BundleID) {
+		return nil, fmt.Errorf("replacement bundle ID is invalid")
+	}
+	if options.Priority < 0 || options.Priority > MaximumJobPriority {
+		return nil, fmt.Errorf("priority must be between 0 and %d", MaximumJobPriority)
+	}
+	encoded, err := json.Marshal(struct {
+		RejudgeOf           string `json:"rejudgeOf"`
+		ReplacementBundleID string `json:"replacementBundleId,omitempty"`
+		Priority            int    `json:"priority,omitempty"`
+	}{RejudgeOf: rejudgeOf, ReplacementBundleID: options.BundleID, Priority: options.Priority})
+	if err != nil {
+		return nil, fmt.Errorf("encode canonical rejudge request: %w", err)
+	}
+	digest := sha256.Sum256(encoded)
+	return append([]byte(nil), digest[:]...), nil
+}
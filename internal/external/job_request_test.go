@@ -6,6 +6,8 @@ import (
 	"crypto/sha256"
 	"strings"
 	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/logging"
 )
 
 func TestValidateIdempotencyKeyAcceptsOnlyBoundedVisibleASCII(t *testing.T) {
@@ -68,6 +70,7 @@ func TestCanonicalJobRequestHashCoversEveryMeaningfulFieldWithoutRetainingSource
 		"stop":      func(value JudgeJobRequest) JudgeJobRequest { value.StopOnFailure = false; return value },
 		"callback":  func(value JudgeJobRequest) JudgeJobRequest { value.CallbackID = ""; return value },
 		"reference": func(value JudgeJobRequest) JudgeJobRequest { value.ClientReference = "submission-43"; return value },
+		"priority":  func(value JudgeJobRequest) JudgeJobRequest { value.Priority = 1; return value },
 	}
 	for name, mutate := range mutations {
 		t.Run(name, func(t *testing.T) {
@@ -82,15 +85,62 @@ func TestCanonicalJobRequestHashCoversEveryMeaningfulFieldWithoutRetainingSource
 	}
 }
 
+func TestCanonicalRejudgeRequestHashCoversOnlyClientFieldsAndTheOriginal(t *testing.T) {
+	original := "ceirceirceirceirceirceirce"
+	want, err := CanonicalRejudgeRequestHash(original, RejudgeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]struct {
+		rejudgeOf string
+		options   RejudgeOptions
+	}{
+		"original":    {rejudgeOf: "ceirceirceirceirceirceircf"},
+		"replacement": {rejudgeOf: original, options: RejudgeOptions{BundleID: "ceirceirceirceirceirceircg"}},
+		"priority":    {rejudgeOf: original, options: RejudgeOptions{Priority: 1}},
+	} {
+		got, err := CanonicalRejudgeRequestHash(change.rejudgeOf, change.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hmac.Equal(got, want) {
+			t.Fatalf("mutation %s did not change the rejudge hash", name)
+		}
+	}
+	// A submission that copies the same original is a different request.
+	submitted, err := CanonicalJobRequestHash(JudgeJobRequest{
+		BundleID: original, Language: "cpp", SourceCode: []byte("x"), RejudgeOf: original,
+	}, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hmac.Equal(submitted, want) {
+		t.Fatal("rejudge hash collides with a submission hash")
+	}
+	for name, options := range map[string]RejudgeOptions{
+		"replacement":   {BundleID: "bad"},
+		"high priority": {Priority: MaximumJobPriority + 1},
+	} {
+		if _, err := CanonicalRejudgeRequestHash(original, options); err == nil {
+			t.Fatalf("%s: expected rejudge rejection", name)
+		}
+	}
+	if _, err := CanonicalRejudgeRequestHash("bad", RejudgeOptions{}); err == nil {
+		t.Fatal("expected rejection of an invalid original")
+	}
+}
+
 func TestCanonicalJobRequestRejectsInvalidOrOversizeFields(t *testing.T) {
 	valid := JudgeJobRequest{BundleID: "ceirceirceirceirceirceirce", Language: "cpp", SourceCode: []byte("x")}
 	tests := map[string]JudgeJobRequest{
-		"bundle":       {Language: "cpp", SourceCode: []byte("x")},
-		"language":     {BundleID: valid.BundleID, Language: "../../bin/sh", SourceCode: []byte("x")},
-		"empty source": {BundleID: valid.BundleID, Language: "cpp"},
-		"large source": {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("xx")},
-		"callback":     {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("x"), CallbackID: "bad"},
-		"reference":    {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("x"), ClientReference: strings.Repeat("x", 256)},
+		"bundle":        {Language: "cpp", SourceCode: []byte("x")},
+		"language":      {BundleID: valid.BundleID, Language: "../../bin/sh", SourceCode: []byte("x")},
+		"empty source":  {BundleID: valid.BundleID, Language: "cpp"},
+		"large source":  {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("xx")},
+		"callback":      {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("x"), CallbackID: "bad"},
+		"reference":     {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("x"), ClientReference: strings.Repeat("x", 256)},
+		"low priority":  {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("x"), Priority: -1},
+		"high priority": {BundleID: valid.BundleID, Language: "cpp", SourceCode: []byte("x"), Priority: MaximumJobPriority + 1},
 	}
 	for name, request := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -104,3 +154,28 @@ func TestCanonicalJobRequestRejectsInvalidOrOversizeFields(t *testing.T) {
 		})
 	}
 }
+
+func TestPayloadTypesLogOnlyIdentifiersAndSizes(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := logging.New(&output, logging.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("payloads",
+		"request", JudgeJobRequest{BundleID: "bundle-1", Language: "cpp", SourceCode: []byte("int main() {}")},
+		"input", WorkerExecutionInput{Language: "cpp", SourceCode: []byte("int main() {}")},
+		"delivery", WebhookDelivery{EventID: "event-1", Secret: []byte("callback-secret"), Body: []byte(`{"verdict":"ACCEPTED"}`)},
+		"key", APIKeyMaterial{Plaintext: "croj_live_plaintext", LookupPrefix: "croj_live_abc", Digest: []byte("digest")},
+	)
+	text := output.String()
+	for _, leaked := range []string{"int main", "callback-secret", "ACCEPTED", "plaintext", "digest"} {
+		if strings.Contains(text, leaked) {
+			t.Fatalf("record leaked %q: %s", leaked, text)
+		}
+	}
+	for _, expected := range []string{`"bundle":"bundle-1"`, `"source_bytes":13`, `"event_id":"event-1"`, `"body_bytes":22`, `[REDACTED API KEY croj_live_abc]`} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("record is missing %s: %s", expected, text)
+		}
+	}
+}
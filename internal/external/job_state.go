@@ -37,14 +37,23 @@ type DurableCaseResult struct {
 	MaxScore    *int   `json:"maxScore,omitempty"`
 }
 
+type DurableSubtaskResult struct {
+	SubtaskID       string `json:"subtaskId"`
+	Verdict         string `json:"verdict"`
+	Score           int    `json:"score"`
+	MaxScore        int    `json:"maxScore"`
+	DependenciesMet bool   `json:"dependenciesMet"`
+}
+
 type DurableJobResult struct {
-	Verdict       string              `json:"verdict"`
-	CompileStatus string              `json:"compileStatus"`
-	TimeMillis    int64               `json:"timeMillis"`
-	MemoryBytes   int64               `json:"memoryBytes"`
-	Score         *int                `json:"score,omitempty"`
-	TotalScore    *int                `json:"totalScore,omitempty"`
-	Cases         []DurableCaseResult `json:"cases"`
+	Verdict       string                 `json:"verdict"`
+	CompileStatus string                 `json:"compileStatus"`
+	TimeMillis    int64                  `json:"timeMillis"`
+	MemoryBytes   int64                  `json:"memoryBytes"`
+	Score         *int                   `json:"score,omitempty"`
+	TotalScore    *int                   `json:"totalScore,omitempty"`
+	Cases         []DurableCaseResult    `json:"cases"`
+	Subtasks      []DurableSubtaskResult `json:"subtasks,omitempty"`
 }
 
 type DurableJob struct {
@@ -133,6 +142,7 @@ func (job *DurableJob) Complete(claim JobClaim, result DurableJobResult, now tim
 			copied.Cases[index].Score = copyScore(item.Score)
 			copied.Cases[index].MaxScore = copyScore(item.MaxScore)
 		}
+		copied.Subtasks = append([]DurableSubtaskResult(nil), result.Subtasks...)
 		job.Status = JobStatusSucceeded
 		job.Result = &copied
 	}
@@ -145,12 +155,16 @@ func (job *DurableJob) Complete(claim JobClaim, result DurableJobResult, now tim
 func validateDurableJobResult(result DurableJobResult) error {
 	if strings.TrimSpace(result.Verdict) == "" || len(result.Verdict) > 64 ||
 		strings.TrimSpace(result.CompileStatus) == "" || len(result.CompileStatus) > 64 ||
-		result.TimeMillis < 0 || result.MemoryBytes < 0 || len(result.Cases) > 256 {
+		result.TimeMillis < 0 || result.MemoryBytes < 0 || len(result.Cases) > MaximumBundleCases {
 		return ErrInvalidJobState
 	}
 	if !validScorePair(result.Score, result.TotalScore) {
 		return ErrInvalidJobState
 	}
+	subtaskScored := len(result.Subtasks) > 0
+	if subtaskScored && (result.Score == nil || result.CompileStatus != "SUCCEEDED") {
+		return ErrInvalidJobState
+	}
 	caseIDs := make(map[string]struct{}, len(result.Cases))
 	var caseScoreSum, caseMaximumSum int64
 	for _, item := range result.Cases {
@@ -162,7 +176,7 @@ func validateDurableJobResult(result DurableJobResult) error {
 		if !validScorePair(item.Score, item.MaxScore) {
 			return ErrInvalidJobState
 		}
-		if result.Score == nil {
+		if result.Score == nil || subtaskScored {
 			if item.Score != nil {
 				return ErrInvalidJobState
 			}
@@ -189,6 +203,25 @@ func validateDurableJobResult(result DurableJobResult) error {
 		}
 		return nil
 	}
+	if subtaskScored {
+		caseScoreSum, caseMaximumSum = 0, 0
+		subtaskIDs := make(map[string]struct{}, len(result.Subtasks))
+		for _, item := range result.Subtasks {
+			if strings.TrimSpace(item.SubtaskID) == "" || len(item.SubtaskID) > 128 ||
+				strings.TrimSpace(item.Verdict) == "" || len(item.Verdict) > 64 ||
+				!validScorePair(&item.Score, &item.MaxScore) ||
+				(!item.DependenciesMet && item.Score != 0) ||
+				(item.DependenciesMet && item.Verdict == "ACCEPTED" && item.Score != item.MaxScore) {
+				return ErrInvalidJobState
+			}
+			if _, exists := subtaskIDs[item.SubtaskID]; exists {
+				return ErrInvalidJobState
+			}
+			subtaskIDs[item.SubtaskID] = struct{}{}
+			caseScoreSum += int64(item.Score)
+			caseMaximumSum += int64(item.MaxScore)
+		}
+	}
 	if result.CompileStatus != "SUCCEEDED" ||
 		caseScoreSum != int64(*result.Score) ||
 		caseMaximumSum != int64(*result.TotalScore) {
@@ -139,6 +139,33 @@ func TestCompletionRejectsMalformedRedactedResults(t *testing.T) {
 			Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED",
 			Cases: []DurableCaseResult{{CaseID: "1", Verdict: "ACCEPTED", Score: &full, MaxScore: &total}},
 		},
+		"ACM result contains subtasks": {
+			Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED",
+			Subtasks: []DurableSubtaskResult{{SubtaskID: "s", Verdict: "ACCEPTED", Score: 100, MaxScore: 100, DependenciesMet: true}},
+		},
+		"subtask result contains case score": {
+			Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED", Score: &full, TotalScore: &total,
+			Cases:    []DurableCaseResult{{CaseID: "1", Verdict: "ACCEPTED", Score: &full, MaxScore: &total}},
+			Subtasks: []DurableSubtaskResult{{SubtaskID: "s", Verdict: "ACCEPTED", Score: 100, MaxScore: 100, DependenciesMet: true}},
+		},
+		"subtask scored without dependencies": {
+			Verdict: "WRONG_ANSWER", CompileStatus: "SUCCEEDED", Score: &firstMax, TotalScore: &total,
+			Subtasks: []DurableSubtaskResult{
+				{SubtaskID: "a", Verdict: "WRONG_ANSWER", Score: 0, MaxScore: 30, DependenciesMet: true},
+				{SubtaskID: "b", Verdict: "ACCEPTED", Score: 30, MaxScore: 70, DependenciesMet: false},
+			},
+		},
+		"subtask aggregate mismatch": {
+			Verdict: "WRONG_ANSWER", CompileStatus: "SUCCEEDED", Score: &score, TotalScore: &total,
+			Subtasks: []DurableSubtaskResult{{SubtaskID: "s", Verdict: "WRONG_ANSWER", Score: 30, MaxScore: 100, DependenciesMet: true}},
+		},
+		"duplicate subtask": {
+			Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED", Score: &full, TotalScore: &total,
+			Subtasks: []DurableSubtaskResult{
+				{SubtaskID: "s", Verdict: "ACCEPTED", Score: 30, MaxScore: 30, DependenciesMet: true},
+				{SubtaskID: "s", Verdict: "ACCEPTED", Score: 70, MaxScore: 70, DependenciesMet: true},
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			job := DurableJob{Status: JobStatusRunning, AttemptNo: 1, WorkerID: "worker-a", LeaseUntil: now.Add(time.Minute)}
@@ -164,6 +191,14 @@ func TestCompletionAcceptsConsistentOIScoresIncludingCompileFailure(t *testing.T
 		"compile failure": {
 			Verdict: "COMPILE_ERROR", CompileStatus: "FAILED", Score: &zero, TotalScore: &total,
 		},
+		"subtasks": {
+			Verdict: "WRONG_ANSWER", CompileStatus: "SUCCEEDED", Score: &thirty, TotalScore: &total,
+			Cases: []DurableCaseResult{{CaseID: "1", Verdict: "ACCEPTED"}, {CaseID: "2", Verdict: "WRONG_ANSWER"}},
+			Subtasks: []DurableSubtaskResult{
+				{SubtaskID: "small", Verdict: "ACCEPTED", Score: 30, MaxScore: 30, DependenciesMet: true},
+				{SubtaskID: "large", Verdict: "WRONG_ANSWER", Score: 0, MaxScore: 70, DependenciesMet: true},
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			job := DurableJob{Status: JobStatusRunning, AttemptNo: 1, WorkerID: "worker-a", LeaseUntil: now.Add(time.Minute)}
@@ -293,6 +293,45 @@ func validateMigrationPostconditions(ctx context.Context, connection migrationCo
 	case migration.Version == 6 && migration.Name == "execution_accounting_retention":
 		query = executionAccountingRetentionValidationSQL
 		description = "execution accounting and retention schema"
+	case migration.Version == 7 && migration.Name == "job_event_stream":
+		query = jobEventStreamValidationSQL
+		description = "job event stream schema"
+	case migration.Version == 8 && migration.Name == "job_trace_context":
+		query = jobTraceContextValidationSQL
+		description = "job trace context schema"
+	case migration.Version == 9 && migration.Name == "custom_run":
+		query = customRunValidationSQL
+		description = "custom run schema"
+	case migration.Version == 10 && migration.Name == "bundle_retention":
+		query = bundleRetentionValidationSQL
+		description = "bundle retention schema"
+	case migration.Version == 11 && migration.Name == "webhook_ping":
+		query = webhookPingValidationSQL
+		description = "webhook ping schema"
+	case migration.Version == 12 && migration.Name == "webhook_job_progress":
+		query = webhookJobProgressValidationSQL
+		description = "webhook job progress schema"
+	case migration.Version == 13 && migration.Name == "job_priority":
+		query = jobPriorityValidationSQL
+		description = "job priority schema"
+	case migration.Version == 14 && migration.Name == "job_rejudge":
+		query = jobRejudgeValidationSQL
+		description = "job rejudge schema"
+	case migration.Version == 15 && migration.Name == "job_list_filters":
+		query = jobListFiltersValidationSQL
+		description = "job list filter indexes"
+	case migration.Version == 16 && migration.Name == "usage_export":
+		query = usageExportValidationSQL
+		description = "usage export schema"
+	case migration.Version == 17 && migration.Name == "admin_audit":
+		query = adminAuditValidationSQL
+		description = "admin audit schema"
+	case migration.Version == 18 && migration.Name == "callback_secret_rotation":
+		query = callbackSecretRotationValidationSQL
+		description = "callback secret rotation schema"
+	case migration.Version == 19 && migration.Name == "custom_run_cap_retention":
+		query = customRunCapRetentionValidationSQL
+		description = "custom run cap and retention schema"
 	default:
 		return nil
 	}
@@ -636,6 +675,166 @@ const executionAccountingRetentionValidationSQL = `SELECT
               '(event_type in (_utf8mb4''marked'',_utf8mb4''delete_retry'',_utf8mb4''deleted''))'
     )`
 
+const jobEventStreamValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.tables
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job_event' AND engine = 'InnoDB'
+          AND table_collation = 'utf8mb4_0900_ai_ci'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND column_name = 'sequence_no' AND column_type = 'int unsigned' AND is_nullable = 'NO'
+          AND column_default IS NULL
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND column_name = 'tenant_id' AND column_type = 'bigint unsigned' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND column_name = 'event_type' AND column_type = 'varchar(16)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND column_name = 'payload_json' AND data_type = 'json' AND is_nullable = 'NO'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND index_name = 'PRIMARY' AND non_unique = 0
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'job_id,sequence_no'
+    AND COALESCE((
+        SELECT GROUP_CONCAT(CONCAT(column_name, ':', referenced_column_name)
+                            ORDER BY ordinal_position SEPARATOR ',')
+        FROM information_schema.key_column_usage
+        WHERE constraint_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND constraint_name = 'fk_external_job_event_job_tenant'
+          AND referenced_table_name = 't_external_job'
+    ), '') = 'job_id:id,tenant_id:tenant_id'
+    AND EXISTS (
+        SELECT 1 FROM information_schema.referential_constraints
+        WHERE constraint_schema = DATABASE() AND table_name = 't_external_job_event'
+          AND constraint_name = 'fk_external_job_event_job_tenant'
+          AND referenced_table_name = 't_external_job'
+          AND delete_rule = 'RESTRICT' AND update_rule = 'RESTRICT'
+    )
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_job_event'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_job_event_sequence'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') =
+              '(sequence_no > 0)'
+    )
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_job_event'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_job_event_type'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') =
+              '(event_type in (_utf8mb4''status'',_utf8mb4''compile'',_utf8mb4''case''))'
+    )`
+
+const jobTraceContextValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job'
+          AND column_name = 'trace_parent' AND column_type = 'char(55)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin'
+          AND is_nullable = 'YES' AND column_default IS NULL
+    )`
+
+const customRunValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.tables
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run' AND engine = 'InnoDB'
+          AND table_collation = 'utf8mb4_0900_ai_ci'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND column_name = 'external_id' AND column_type = 'char(26)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND column_name = 'accounting_day' AND data_type = 'date' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND column_name = 'reserved_millis' AND column_type = 'bigint unsigned' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND column_name = 'lease_until' AND column_type = 'datetime(3)' AND is_nullable = 'NO'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND index_name = 'uk_external_run_external_id' AND non_unique = 0
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'external_id'
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND index_name = 'idx_external_run_tenant_status_lease'
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'tenant_id,status,lease_until'
+    AND EXISTS (
+        SELECT 1 FROM information_schema.referential_constraints
+        WHERE constraint_schema = DATABASE() AND table_name = 't_external_run'
+          AND constraint_name = 'fk_external_run_tenant'
+          AND referenced_table_name = 't_external_tenant'
+          AND delete_rule = 'RESTRICT' AND update_rule = 'RESTRICT'
+    )
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_run'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_run_status'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') =
+              '(status in (_utf8mb4''running'',_utf8mb4''completed'',_utf8mb4''failed'',_utf8mb4''expired''))'
+    )
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_run'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_run_reservation'
+          AND table_constraint.enforced = 'YES'
+    )`
+
 const tenantPolicyCeilingsValidationSQL = `SELECT NOT EXISTS (
     SELECT 1 FROM t_external_tenant
     WHERE NOT JSON_CONTAINS_PATH(policy_json, 'all', '$.maxTimeLimitMillis', '$.maxMemoryLimitMiB')
@@ -675,12 +874,7 @@ const durableWebhookSchemaValidationSQL = `SELECT
         WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
           AND column_name = 'dead_at' AND column_type = 'datetime(3)' AND is_nullable = 'YES'
     )
-    AND COALESCE((
-        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
-        FROM information_schema.statistics
-        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
-          AND index_name = 'uk_external_webhook_job' AND non_unique = 0
-    ), '') = 'job_id'
+    AND ` + webhookJobUniquenessSQL + `
     AND COALESCE((
         SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
         FROM information_schema.statistics
@@ -904,3 +1098,307 @@ func splitMigrationStatements(script string) ([]string, error) {
 	}
 	return statements, nil
 }
+
+const bundleRetentionValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND column_name = 'delete_token' AND column_type = 'binary(32)' AND is_nullable = 'YES'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND column_name = 'delete_lease_until' AND column_type = 'datetime(3)' AND is_nullable = 'YES'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND column_name = 'delete_next_attempt_at' AND column_type = 'datetime(3)' AND is_nullable = 'YES'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND column_name = 'delete_attempt_count' AND column_type = 'int unsigned' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND column_name = 'delete_last_error_code' AND column_type = 'varchar(64)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin' AND is_nullable = 'YES'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND index_name = 'idx_external_bundle_tenant_created'
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'tenant_id,created_at,id'
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_bundle'
+          AND index_name = 'idx_external_bundle_retention'
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'deleted_at,delete_marked_at,delete_next_attempt_at,id'
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_bundle'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_bundle_delete_fence'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') =
+              '(((delete_marked_at is null) and (delete_token is null) and (delete_lease_until is null) and (delete_next_attempt_at is null) and (deleted_at is null)) or ((delete_marked_at is not null) and (delete_next_attempt_at is not null) and (((delete_token is null) and (delete_lease_until is null)) or ((delete_token is not null) and (delete_lease_until is not null)))))'
+    )`
+
+const webhookPingValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+          AND column_name = 'job_id' AND column_type = 'bigint unsigned' AND is_nullable = 'YES'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+          AND column_name = 'last_latency_ms' AND column_type = 'int unsigned' AND is_nullable = 'YES'
+    )
+    AND ` + webhookJobUniquenessSQL + `
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_webhook_outbox'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_webhook_subject'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') =
+              '(((event_type = _utf8mb4''judge.ping'') and (job_id is null)) or ((event_type <> _utf8mb4''judge.ping'') and (job_id is not null)))'
+    )`
+
+// webhookJobUniquenessSQL is the one-terminal-event-per-job guarantee that v5
+// and v11 rely on. Schema v12 moves it from job_id to the generated
+// terminal_job_id so non-terminal events can share a job; either form
+// satisfies the earlier postconditions on an upgraded database.
+const webhookJobUniquenessSQL = `(
+        COALESCE((
+            SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+            FROM information_schema.statistics
+            WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+              AND index_name = 'uk_external_webhook_job' AND non_unique = 0
+        ), '') = 'job_id'
+        OR COALESCE((
+            SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+            FROM information_schema.statistics
+            WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+              AND index_name = 'uk_external_webhook_terminal_job' AND non_unique = 0
+        ), '') = 'terminal_job_id'
+    )`
+
+const webhookJobProgressValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_callback'
+          AND column_name = 'optional_event_types' AND is_nullable = 'NO'
+          AND column_type = 'set(''judge.job.started'',''judge.job.progress'')'
+          AND column_default = ''
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+          AND column_name = 'terminal_job_id' AND column_type = 'bigint unsigned' AND is_nullable = 'YES'
+          AND extra = 'STORED GENERATED'
+          AND REPLACE(REPLACE(LOWER(generation_expression), CHAR(96), ''), CHAR(92), '') =
+              '(case when (event_type in (_utf8mb4''judge.job.completed'',_utf8mb4''judge.job.failed'',_utf8mb4''judge.job.cancelled'')) then job_id end)'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+          AND index_name = 'uk_external_webhook_terminal_job' AND non_unique = 0
+    ), '') = 'terminal_job_id'
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+          AND index_name = 'idx_external_webhook_job_event'
+    ), '') = 'job_id,event_type,status'
+    AND NOT EXISTS (
+        SELECT 1 FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_webhook_outbox'
+          AND index_name = 'uk_external_webhook_job'
+    )`
+
+const jobPriorityValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job'
+          AND column_name = 'priority' AND column_type = 'tinyint unsigned'
+          AND is_nullable = 'NO' AND column_default = '0'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(CONCAT(column_name, IF(collation = 'D', ' desc', '')) ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job'
+          AND index_name = 'idx_external_job_priority_claim'
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'tenant_id,status,priority desc,next_attempt_at,created_at,id'
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_job'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_job_priority'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') = '(priority <= 9)'
+    )`
+
+const jobRejudgeValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_job'
+          AND column_name = 'rejudge_of_external_id' AND column_type = 'char(26)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin' AND is_nullable = 'YES'
+    )`
+
+const jobListFiltersValidationSQL = `SELECT
+    COALESCE((
+        SELECT GROUP_CONCAT(CONCAT(index_name, ':', columns) ORDER BY index_name SEPARATOR ';')
+        FROM (
+            SELECT index_name, GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',') AS columns
+            FROM information_schema.statistics
+            WHERE table_schema = DATABASE() AND table_name = 't_external_job'
+              AND index_name IN ('idx_external_job_tenant_bundle', 'idx_external_job_tenant_language', 'idx_external_job_tenant_reference')
+              AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+            GROUP BY index_name
+        ) AS filter_index
+    ), '') = CONCAT(
+        'idx_external_job_tenant_bundle:tenant_id,bundle_id,created_at,id;',
+        'idx_external_job_tenant_language:tenant_id,language_id,created_at,id;',
+        'idx_external_job_tenant_reference:tenant_id,client_reference,created_at,id'
+    )`
+
+const usageExportValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.tables
+        WHERE table_schema = DATABASE() AND table_name = 't_external_usage_rollup' AND engine = 'InnoDB'
+          AND table_collation = 'utf8mb4_0900_ai_ci'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_usage_rollup'
+          AND column_name = 'language_id' AND column_type = 'varchar(64)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_usage_rollup'
+          AND column_name = 'job_execution_millis' AND column_type = 'bigint unsigned' AND is_nullable = 'NO'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_usage_rollup'
+          AND index_name = 'uk_external_usage_rollup' AND non_unique = 0
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'tenant_id,usage_day,language_id'
+    AND COALESCE((
+        SELECT GROUP_CONCAT(CONCAT(table_name, '.', index_name, ':', columns) ORDER BY table_name SEPARATOR ';')
+        FROM (
+            SELECT table_name, index_name, GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',') AS columns
+            FROM information_schema.statistics
+            WHERE table_schema = DATABASE()
+              AND ((table_name = 't_external_job_attempt' AND index_name = 'idx_external_attempt_accounting')
+                OR (table_name = 't_external_run' AND index_name = 'idx_external_run_accounting'))
+              AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+            GROUP BY table_name, index_name
+        ) AS accounting_index
+    ), '') = CONCAT(
+        't_external_job_attempt.idx_external_attempt_accounting:accounting_day,tenant_id;',
+        't_external_run.idx_external_run_accounting:accounting_day,tenant_id'
+    )`
+
+const adminAuditValidationSQL = `SELECT
+    EXISTS (
+        SELECT 1 FROM information_schema.tables
+        WHERE table_schema = DATABASE() AND table_name = 't_external_admin_audit' AND engine = 'InnoDB'
+          AND table_collation = 'utf8mb4_0900_ai_ci'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_admin_audit'
+          AND column_name = 'action' AND column_type = 'varchar(64)'
+          AND character_set_name = 'ascii' AND collation_name = 'ascii_bin' AND is_nullable = 'NO'
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_admin_audit'
+          AND column_name = 'detail_json' AND data_type = 'json' AND is_nullable = 'NO'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_admin_audit'
+          AND index_name = 'idx_external_admin_audit_tenant_time'
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'tenant_id,created_at,id'`
+
+const callbackSecretRotationValidationSQL = `SELECT
+    COALESCE((
+        SELECT GROUP_CONCAT(CONCAT(column_name, ':', column_type, ':', is_nullable) ORDER BY ordinal_position SEPARATOR ',')
+        FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_callback'
+          AND column_name IN ('previous_secret_ciphertext', 'previous_secret_nonce', 'previous_secret_key_version', 'previous_secret_expires_at')
+    ), '') = CONCAT(
+        'previous_secret_ciphertext:varbinary(2048):YES,previous_secret_nonce:binary(12):YES,',
+        'previous_secret_key_version:smallint unsigned:YES,previous_secret_expires_at:datetime(3):YES'
+    )
+    AND EXISTS (
+        SELECT 1
+        FROM information_schema.table_constraints AS table_constraint
+        JOIN information_schema.check_constraints AS check_constraint
+          ON check_constraint.constraint_schema = table_constraint.constraint_schema
+         AND check_constraint.constraint_name = table_constraint.constraint_name
+        WHERE table_constraint.constraint_schema = DATABASE()
+          AND table_constraint.table_name = 't_external_callback'
+          AND table_constraint.constraint_type = 'CHECK'
+          AND table_constraint.constraint_name = 'chk_external_callback_previous_secret'
+          AND table_constraint.enforced = 'YES'
+          AND REPLACE(REPLACE(LOWER(check_constraint.check_clause), CHAR(96), ''), CHAR(92), '') = CONCAT(
+              '(((previous_secret_ciphertext is null) and (previous_secret_nonce is null) and ',
+              '(previous_secret_key_version is null) and (previous_secret_expires_at is null)) or ',
+              '((length(previous_secret_ciphertext) > 16) and (length(previous_secret_nonce) = 12) and ',
+              '(previous_secret_key_version > 0) and (previous_secret_expires_at is not null)))'
+          )
+    )`
+
+const customRunCapRetentionValidationSQL = `SELECT
+    NOT EXISTS (
+        SELECT 1 FROM t_external_tenant
+        WHERE JSON_TYPE(JSON_EXTRACT(policy_json, '$.maxRunningRuns')) IS NULL
+           OR JSON_TYPE(JSON_EXTRACT(policy_json, '$.maxRunningRuns')) NOT IN ('INTEGER', 'UNSIGNED INTEGER')
+           OR CAST(JSON_UNQUOTE(JSON_EXTRACT(policy_json, '$.maxRunningRuns')) AS UNSIGNED) = 0
+    )
+    AND EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 't_external_usage_rollup'
+          AND column_name = 'run_execution_millis' AND column_type = 'bigint unsigned'
+          AND is_nullable = 'NO' AND column_default = '0'
+    )
+    AND COALESCE((
+        SELECT GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ',')
+        FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 't_external_run'
+          AND index_name = 'idx_external_run_finished'
+          AND index_type = 'BTREE' AND is_visible = 'YES' AND sub_part IS NULL
+    ), '') = 'finished_at,id'`
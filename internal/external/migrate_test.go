@@ -14,7 +14,7 @@ func TestEmbeddedMigrationsDefineTheCompleteJudgeOwnedSchema(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(migrations) != 6 || migrations[0].Version != 1 || migrations[0].Name != "initial_external_judge" || migrations[1].Version != 2 || migrations[1].Name != "external_bundle_ready" || migrations[2].Version != 3 || migrations[2].Name != "durable_job_fencing" || migrations[3].Version != 4 || migrations[3].Name != "tenant_policy_execution_ceilings" || migrations[4].Version != 5 || migrations[4].Name != "durable_webhook_outbox" || migrations[5].Version != 6 || migrations[5].Name != "execution_accounting_retention" {
+	if len(migrations) != 19 || migrations[0].Version != 1 || migrations[0].Name != "initial_external_judge" || migrations[1].Version != 2 || migrations[1].Name != "external_bundle_ready" || migrations[2].Version != 3 || migrations[2].Name != "durable_job_fencing" || migrations[3].Version != 4 || migrations[3].Name != "tenant_policy_execution_ceilings" || migrations[4].Version != 5 || migrations[4].Name != "durable_webhook_outbox" || migrations[5].Version != 6 || migrations[5].Name != "execution_accounting_retention" || migrations[6].Version != 7 || migrations[6].Name != "job_event_stream" || migrations[7].Version != 8 || migrations[7].Name != "job_trace_context" || migrations[8].Version != 9 || migrations[8].Name != "custom_run" || migrations[9].Version != 10 || migrations[9].Name != "bundle_retention" || migrations[10].Version != 11 || migrations[10].Name != "webhook_ping" || migrations[11].Version != 12 || migrations[11].Name != "webhook_job_progress" || migrations[12].Version != 13 || migrations[12].Name != "job_priority" || migrations[13].Version != 14 || migrations[13].Name != "job_rejudge" || migrations[14].Version != 15 || migrations[14].Name != "job_list_filters" || migrations[15].Version != 16 || migrations[15].Name != "usage_export" || migrations[16].Version != 17 || migrations[16].Name != "admin_audit" || migrations[17].Version != 18 || migrations[17].Name != "callback_secret_rotation" || migrations[18].Version != 19 || migrations[18].Name != "custom_run_cap_retention" {
 		t.Fatalf("migrations = %+v", migrations)
 	}
 	if len(migrations[0].Checksum) != 64 {
@@ -154,7 +154,7 @@ func TestExecutionAccountingAndRetentionMigrationDefinesDurableContracts(t *test
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(migrations) != 6 || migrations[5].Version != 6 || migrations[5].Name != "execution_accounting_retention" {
+	if len(migrations) < 6 || migrations[5].Version != 6 || migrations[5].Name != "execution_accounting_retention" {
 		t.Fatalf("migrations = %+v", migrations)
 	}
 	sql := strings.ToLower(migrations[5].SQL)
@@ -208,6 +208,381 @@ func TestExecutionAccountingAndRetentionPostconditionCoversRuntimeDependencies(t
 	}
 }
 
+func TestJobEventStreamMigrationDefinesTenantBoundOrderedEvents(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 7 || migrations[6].Version != 7 || migrations[6].Name != "job_event_stream" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[6].SQL)
+	for _, contract := range []string{
+		"create table if not exists t_external_job_event",
+		"sequence_no int unsigned not null",
+		"primary key (job_id, sequence_no)",
+		"foreign key (job_id, tenant_id) references t_external_job(id, tenant_id)",
+		"on delete restrict on update restrict",
+		"check (event_type in ('status','compile','case'))",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	validation := strings.ToLower(jobEventStreamValidationSQL)
+	for _, contract := range []string{
+		"fk_external_job_event_job_tenant",
+		"chk_external_job_event_sequence",
+		"chk_external_job_event_type",
+		"'job_id,sequence_no'",
+		"delete_rule = 'restrict'",
+	} {
+		if !strings.Contains(validation, contract) {
+			t.Errorf("v7 postcondition is missing runtime dependency %q", contract)
+		}
+	}
+}
+
+func TestJobTraceContextMigrationAddsNullableTraceParent(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 8 || migrations[7].Version != 8 || migrations[7].Name != "job_trace_context" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[7].SQL)
+	for _, contract := range []string{
+		"-- migrate:replay-errors 1060",
+		"alter table t_external_job",
+		"add column trace_parent char(55) character set ascii collate ascii_bin null",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	if !strings.Contains(strings.ToLower(jobTraceContextValidationSQL), "column_name = 'trace_parent' and column_type = 'char(55)'") {
+		t.Error("v8 postcondition does not pin the trace_parent column")
+	}
+}
+
+func TestCustomRunMigrationDefinesTenantBoundLeasedReservations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 9 || migrations[8].Version != 9 || migrations[8].Name != "custom_run" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[8].SQL)
+	for _, contract := range []string{
+		"create table if not exists t_external_run",
+		"external_id char(26) character set ascii collate ascii_bin not null",
+		"unique key uk_external_run_external_id (external_id)",
+		"key idx_external_run_tenant_status_lease (tenant_id, status, lease_until)",
+		"foreign key (tenant_id) references t_external_tenant(id)",
+		"check (status in ('running','completed','failed','expired'))",
+		"check (reserved_millis > 0 and consumed_millis <= reserved_millis)",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	validation := strings.ToLower(customRunValidationSQL)
+	for _, contract := range []string{
+		"fk_external_run_tenant",
+		"chk_external_run_status",
+		"chk_external_run_reservation",
+		"'tenant_id,status,lease_until'",
+	} {
+		if !strings.Contains(validation, contract) {
+			t.Errorf("v9 postcondition is missing runtime dependency %q", contract)
+		}
+	}
+}
+
+func TestBundleRetentionMigrationFencesObjectDeletion(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 10 || migrations[9].Version != 10 || migrations[9].Name != "bundle_retention" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[9].SQL)
+	for _, contract := range []string{
+		"add column delete_token binary(32) null after delete_marked_at",
+		"add column delete_lease_until datetime(3) null after delete_token",
+		"add key idx_external_bundle_tenant_created(tenant_id, created_at, id)",
+		"add key idx_external_bundle_retention(deleted_at, delete_marked_at, delete_next_attempt_at, id)",
+		"add constraint chk_external_bundle_delete_fence",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	validation := strings.ToLower(bundleRetentionValidationSQL)
+	for _, contract := range []string{
+		"'tenant_id,created_at,id'",
+		"'deleted_at,delete_marked_at,delete_next_attempt_at,id'",
+		"chk_external_bundle_delete_fence",
+	} {
+		if !strings.Contains(validation, contract) {
+			t.Errorf("v10 postcondition is missing runtime dependency %q", contract)
+		}
+	}
+}
+
+func TestWebhookPingMigrationAllowsJoblessPingEventsOnly(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 11 || migrations[10].Version != 11 || migrations[10].Name != "webhook_ping" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[10].SQL)
+	for _, contract := range []string{
+		"modify column job_id bigint unsigned null",
+		"add column last_latency_ms int unsigned null after last_http_status",
+		"add constraint chk_external_webhook_subject",
+		"(event_type = 'judge.ping' and job_id is null)",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	validation := strings.ToLower(webhookPingValidationSQL)
+	for _, contract := range []string{
+		"'job_id' and column_type = 'bigint unsigned' and is_nullable = 'yes'",
+		"'last_latency_ms'",
+		"uk_external_webhook_job",
+		"chk_external_webhook_subject",
+	} {
+		if !strings.Contains(validation, contract) {
+			t.Errorf("v11 postcondition is missing runtime dependency %q", contract)
+		}
+	}
+}
+
+func TestWebhookJobProgressMigrationKeepsOneTerminalEventPerJob(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 12 || migrations[11].Version != 12 || migrations[11].Name != "webhook_job_progress" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[11].SQL)
+	for _, contract := range []string{
+		"add column optional_event_types set('judge.job.started','judge.job.progress') not null default ''",
+		"generated always as (case when event_type in ('judge.job.completed','judge.job.failed','judge.job.cancelled') then job_id end) stored",
+		"add unique key uk_external_webhook_terminal_job (terminal_job_id)",
+		"add key idx_external_webhook_job_event (job_id, event_type, status)",
+		"drop index uk_external_webhook_job",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	if strings.Index(sql, "uk_external_webhook_terminal_job") > strings.Index(sql, "drop index uk_external_webhook_job") {
+		t.Error("per-job key is dropped before its terminal replacement exists")
+	}
+	validation := strings.ToLower(webhookJobProgressValidationSQL)
+	for _, contract := range []string{
+		"'optional_event_types'",
+		"'stored generated'",
+		"uk_external_webhook_terminal_job",
+		"idx_external_webhook_job_event",
+	} {
+		if !strings.Contains(validation, contract) {
+			t.Errorf("v12 postcondition is missing runtime dependency %q", contract)
+		}
+	}
+	for _, earlier := range []string{durableWebhookSchemaValidationSQL, webhookPingValidationSQL} {
+		if !strings.Contains(earlier, "uk_external_webhook_terminal_job") {
+			t.Error("an earlier postcondition rejects the v12 terminal uniqueness key")
+		}
+	}
+}
+
+func TestJobPriorityMigrationIndexesPerTenantClaimOrder(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 13 || migrations[12].Version != 13 || migrations[12].Name != "job_priority" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[12].SQL)
+	for _, contract := range []string{
+		"add column priority tinyint unsigned not null default 0",
+		"add constraint chk_external_job_priority check (priority <= 9)",
+		"add key idx_external_job_priority_claim (tenant_id, status, priority desc, next_attempt_at, created_at, id)",
+	} {
+		if !strings.Contains(sql, contract) {
+			t.Errorf("migration is missing contract %q", contract)
+		}
+	}
+	if !strings.Contains(sql, fmt.Sprintf("priority <= %d", MaximumJobPriority)) {
+		t.Error("schema priority bound differs from MaximumJobPriority")
+	}
+	validation := strings.ToLower(jobPriorityValidationSQL)
+	for _, contract := range []string{"'priority'", "idx_external_job_priority_claim", "priority desc", "chk_external_job_priority"} {
+		if !strings.Contains(validation, contract) {
+			t.Errorf("v13 postcondition is missing runtime dependency %q", contract)
+		}
+	}
+}
+
+func TestJobRejudgeMigrationAddsAnUnconstrainedOriginReference(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 14 || migrations[13].Version != 14 || migrations[13].Name != "job_rejudge" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[13].SQL)
+	if !strings.Contains(sql, "add column rejudge_of_external_id char(26) character set ascii collate ascii_bin null") {
+		t.Error("migration is missing the rejudge origin column")
+	}
+	// Source retention deletes original jobs, so the reference must survive them.
+	if strings.Contains(sql, "foreign key") {
+		t.Error("rejudge origin must not reference a job that retention can delete")
+	}
+	if !strings.Contains(strings.ToLower(jobRejudgeValidationSQL), "'rejudge_of_external_id'") {
+		t.Error("v14 postcondition is missing the rejudge origin column")
+	}
+}
+
+func TestJobListFiltersMigrationIndexesEachFilterInListOrder(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 15 || migrations[14].Version != 15 || migrations[14].Name != "job_list_filters" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[14].SQL)
+	validation := strings.ToLower(jobListFiltersValidationSQL)
+	for index, column := range map[string]string{
+		"idx_external_job_tenant_reference": "client_reference",
+		"idx_external_job_tenant_bundle":    "bundle_id",
+		"idx_external_job_tenant_language":  "language_id",
+	} {
+		if !strings.Contains(sql, "add key "+index+" (tenant_id, "+column+", created_at, id)") {
+			t.Errorf("migration is missing index %s", index)
+		}
+		if !strings.Contains(validation, index+":tenant_id,"+column+",created_at,id") {
+			t.Errorf("v15 postcondition is missing index %s", index)
+		}
+	}
+}
+
+func TestUsageExportMigrationKeepsRetainedUsageAndIndexesAccountingDays(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 16 || migrations[15].Version != 16 || migrations[15].Name != "usage_export" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[15].SQL)
+	validation := strings.ToLower(usageExportValidationSQL)
+	for _, fragment := range []string{
+		"create table if not exists t_external_usage_rollup",
+		"unique key uk_external_usage_rollup (tenant_id, usage_day, language_id)",
+		"add key idx_external_attempt_accounting (accounting_day, tenant_id)",
+		"add key idx_external_run_accounting (accounting_day, tenant_id)",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("migration is missing %q", fragment)
+		}
+	}
+	for _, fragment := range []string{
+		"'tenant_id,usage_day,language_id'",
+		"t_external_job_attempt.idx_external_attempt_accounting:accounting_day,tenant_id",
+		"t_external_run.idx_external_run_accounting:accounting_day,tenant_id",
+	} {
+		if !strings.Contains(validation, fragment) {
+			t.Errorf("v16 postcondition is missing %q", fragment)
+		}
+	}
+}
+
+func TestAdminAuditMigrationRecordsOperatorChangesPerTenant(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 17 || migrations[16].Version != 17 || migrations[16].Name != "admin_audit" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[16].SQL)
+	for _, fragment := range []string{
+		"create table if not exists t_external_admin_audit",
+		"key idx_external_admin_audit_tenant_time (tenant_id, created_at, id)",
+		"foreign key (tenant_id) references t_external_tenant(id)",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("migration is missing %q", fragment)
+		}
+	}
+	if !strings.Contains(adminAuditValidationSQL, "'tenant_id,created_at,id'") {
+		t.Error("v17 postcondition does not check the audit index")
+	}
+}
+
+func TestCallbackSecretRotationMigrationKeepsThePreviousSecretComplete(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 18 || migrations[17].Version != 18 || migrations[17].Name != "callback_secret_rotation" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[17].SQL)
+	for _, fragment := range []string{
+		"add column previous_secret_ciphertext varbinary(2048) null",
+		"add column previous_secret_nonce binary(12) null",
+		"add column previous_secret_key_version smallint unsigned null",
+		"add column previous_secret_expires_at datetime(3) null",
+		"add constraint chk_external_callback_previous_secret",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("migration is missing %q", fragment)
+		}
+	}
+	if !strings.Contains(callbackSecretRotationValidationSQL, "'chk_external_callback_previous_secret'") {
+		t.Error("v18 postcondition does not check the previous secret constraint")
+	}
+}
+
+func TestCustomRunCapRetentionMigrationBackfillsTheRunCap(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 19 || migrations[18].Version != 19 || migrations[18].Name != "custom_run_cap_retention" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+	sql := strings.ToLower(migrations[18].SQL)
+	for _, fragment := range []string{
+		"json_set(policy_json, '$.maxrunningruns'",
+		"not json_contains_path(policy_json, 'one', '$.maxrunningruns')",
+		"add column run_execution_millis bigint unsigned not null default 0",
+		"add key idx_external_run_finished (finished_at, id)",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("migration is missing %q", fragment)
+		}
+	}
+	if !strings.Contains(customRunCapRetentionValidationSQL, "'idx_external_run_finished'") {
+		t.Error("v19 postcondition does not check the run retention index")
+	}
+}
+
 func TestMigrationStatementsAreExplicitAndReplaySafe(t *testing.T) {
 	migrations, err := Migrations()
 	if err != nil {
@@ -268,7 +643,7 @@ func TestApplyMigrationsUsesAnAdvisoryLockAndRecordsChecksums(t *testing.T) {
 		t.Fatalf("first execution = %s", connection.executions[0].query)
 	}
 	last := connection.executions[len(connection.executions)-1]
-	if !strings.Contains(strings.ToLower(last.query), "insert into t_judge_schema_history") || fmt.Sprint(last.arguments) != fmt.Sprint([]any{6, "execution_accounting_retention", migrations[5].Checksum}) {
+	if !strings.Contains(strings.ToLower(last.query), "insert into t_judge_schema_history") || fmt.Sprint(last.arguments) != fmt.Sprint([]any{19, "custom_run_cap_retention", migrations[18].Checksum}) {
 		t.Fatalf("history execution = %#v", last)
 	}
 }
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS t_external_job_event (
+    job_id BIGINT UNSIGNED NOT NULL,
+    sequence_no INT UNSIGNED NOT NULL,
+    tenant_id BIGINT UNSIGNED NOT NULL,
+    attempt_no INT UNSIGNED NOT NULL DEFAULT 0,
+    event_type VARCHAR(16) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    payload_json JSON NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    PRIMARY KEY (job_id, sequence_no),
+    KEY idx_external_job_event_job_tenant (job_id, tenant_id),
+    CONSTRAINT fk_external_job_event_job_tenant FOREIGN KEY (job_id, tenant_id) REFERENCES t_external_job(id, tenant_id)
+        ON DELETE RESTRICT ON UPDATE RESTRICT,
+    CONSTRAINT chk_external_job_event_sequence CHECK (sequence_no > 0),
+    CONSTRAINT chk_external_job_event_type CHECK (event_type IN ('STATUS','COMPILE','CASE'))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,3 @@
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_job
+    ADD COLUMN trace_parent CHAR(55) CHARACTER SET ascii COLLATE ascii_bin NULL AFTER request_hash;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS t_external_run (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id BIGINT UNSIGNED NOT NULL,
+    external_id CHAR(26) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    language VARCHAR(32) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    status VARCHAR(16) CHARACTER SET ascii COLLATE ascii_bin NOT NULL DEFAULT 'RUNNING',
+    accounting_day DATE NOT NULL,
+    reserved_millis BIGINT UNSIGNED NOT NULL,
+    consumed_millis BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    lease_until DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    finished_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_external_run_external_id (external_id),
+    KEY idx_external_run_tenant_status_lease (tenant_id, status, lease_until),
+    CONSTRAINT fk_external_run_tenant FOREIGN KEY (tenant_id) REFERENCES t_external_tenant(id)
+        ON DELETE RESTRICT ON UPDATE RESTRICT,
+    CONSTRAINT chk_external_run_status CHECK (status IN ('RUNNING','COMPLETED','FAILED','EXPIRED')),
+    CONSTRAINT chk_external_run_reservation CHECK (reserved_millis > 0 AND consumed_millis <= reserved_millis)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
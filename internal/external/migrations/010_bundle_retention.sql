@@ -0,0 +1,40 @@
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_bundle
+    ADD COLUMN delete_token BINARY(32) NULL AFTER delete_marked_at;
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_bundle
+    ADD COLUMN delete_lease_until DATETIME(3) NULL AFTER delete_token;
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_bundle
+    ADD COLUMN delete_next_attempt_at DATETIME(3) NULL AFTER delete_lease_until;
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_bundle
+    ADD COLUMN delete_attempt_count INT UNSIGNED NOT NULL DEFAULT 0 AFTER delete_next_attempt_at;
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_bundle
+    ADD COLUMN delete_last_error_code VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NULL AFTER delete_attempt_count;
+-- migrate:split
+UPDATE t_external_bundle
+SET delete_next_attempt_at = delete_marked_at
+WHERE delete_marked_at IS NOT NULL AND delete_next_attempt_at IS NULL;
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_bundle
+    ADD KEY idx_external_bundle_tenant_created(tenant_id, created_at, id);
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_bundle
+    ADD KEY idx_external_bundle_retention(deleted_at, delete_marked_at, delete_next_attempt_at, id);
+-- migrate:split
+-- migrate:replay-errors 3822
+ALTER TABLE t_external_bundle
+    ADD CONSTRAINT chk_external_bundle_delete_fence
+        CHECK (
+            (delete_marked_at IS NULL AND delete_token IS NULL AND delete_lease_until IS NULL AND delete_next_attempt_at IS NULL AND deleted_at IS NULL)
+            OR (delete_marked_at IS NOT NULL AND delete_next_attempt_at IS NOT NULL
+                AND ((delete_token IS NULL AND delete_lease_until IS NULL) OR (delete_token IS NOT NULL AND delete_lease_until IS NOT NULL)))
+        );
@@ -0,0 +1,11 @@
+ALTER TABLE t_external_webhook_outbox
+    MODIFY COLUMN job_id BIGINT UNSIGNED NULL;
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_webhook_outbox
+    ADD COLUMN last_latency_ms INT UNSIGNED NULL AFTER last_http_status;
+-- migrate:split
+-- migrate:replay-errors 3822
+ALTER TABLE t_external_webhook_outbox
+    ADD CONSTRAINT chk_external_webhook_subject
+        CHECK ((event_type = 'judge.ping' AND job_id IS NULL) OR (event_type <> 'judge.ping' AND job_id IS NOT NULL));
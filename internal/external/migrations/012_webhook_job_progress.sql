@@ -0,0 +1,21 @@
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_callback
+    ADD COLUMN optional_event_types SET('judge.job.started','judge.job.progress') NOT NULL DEFAULT '' AFTER allowed_port;
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_webhook_outbox
+    ADD COLUMN terminal_job_id BIGINT UNSIGNED
+        GENERATED ALWAYS AS (CASE WHEN event_type IN ('judge.job.completed','judge.job.failed','judge.job.cancelled') THEN job_id END) STORED
+        AFTER job_id;
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_webhook_outbox
+    ADD UNIQUE KEY uk_external_webhook_terminal_job (terminal_job_id);
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_webhook_outbox
+    ADD KEY idx_external_webhook_job_event (job_id, event_type, status);
+-- migrate:split
+-- migrate:replay-errors 1091
+ALTER TABLE t_external_webhook_outbox
+    DROP INDEX uk_external_webhook_job;
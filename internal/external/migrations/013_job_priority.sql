@@ -0,0 +1,11 @@
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_job
+    ADD COLUMN priority TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER stop_on_failure;
+-- migrate:split
+-- migrate:replay-errors 3822
+ALTER TABLE t_external_job
+    ADD CONSTRAINT chk_external_job_priority CHECK (priority <= 9);
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_job
+    ADD KEY idx_external_job_priority_claim (tenant_id, status, priority DESC, next_attempt_at, created_at, id);
@@ -0,0 +1,3 @@
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_job
+    ADD COLUMN rejudge_of_external_id CHAR(26) CHARACTER SET ascii COLLATE ascii_bin NULL AFTER client_reference;
@@ -0,0 +1,11 @@
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_job
+    ADD KEY idx_external_job_tenant_reference (tenant_id, client_reference, created_at, id);
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_job
+    ADD KEY idx_external_job_tenant_bundle (tenant_id, bundle_id, created_at, id);
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_job
+    ADD KEY idx_external_job_tenant_language (tenant_id, language_id, created_at, id);
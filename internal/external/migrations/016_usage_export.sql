@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS t_external_usage_rollup (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id BIGINT UNSIGNED NOT NULL,
+    usage_day DATE NOT NULL,
+    language_id VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    job_execution_millis BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    jobs_succeeded BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    jobs_failed BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    jobs_cancelled BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_external_usage_rollup (tenant_id, usage_day, language_id),
+    KEY idx_external_usage_rollup_day (usage_day, tenant_id),
+    CONSTRAINT fk_external_usage_rollup_tenant FOREIGN KEY (tenant_id) REFERENCES t_external_tenant(id)
+        ON DELETE RESTRICT ON UPDATE RESTRICT
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_job_attempt
+    ADD KEY idx_external_attempt_accounting (accounting_day, tenant_id);
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_run
+    ADD KEY idx_external_run_accounting (accounting_day, tenant_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS t_external_admin_audit (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id BIGINT UNSIGNED NOT NULL,
+    action VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    subject_external_id VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    detail_json JSON NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    PRIMARY KEY (id),
+    KEY idx_external_admin_audit_tenant_time (tenant_id, created_at, id),
+    CONSTRAINT fk_external_admin_audit_tenant FOREIGN KEY (tenant_id) REFERENCES t_external_tenant(id)
+        ON DELETE RESTRICT ON UPDATE RESTRICT
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,16 @@
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_callback
+    ADD COLUMN previous_secret_ciphertext VARBINARY(2048) NULL AFTER secret_key_version,
+    ADD COLUMN previous_secret_nonce BINARY(12) NULL AFTER previous_secret_ciphertext,
+    ADD COLUMN previous_secret_key_version SMALLINT UNSIGNED NULL AFTER previous_secret_nonce,
+    ADD COLUMN previous_secret_expires_at DATETIME(3) NULL AFTER previous_secret_key_version;
+-- migrate:split
+-- migrate:replay-errors 3822
+ALTER TABLE t_external_callback
+    ADD CONSTRAINT chk_external_callback_previous_secret
+        CHECK (
+            (previous_secret_ciphertext IS NULL AND previous_secret_nonce IS NULL AND
+             previous_secret_key_version IS NULL AND previous_secret_expires_at IS NULL) OR
+            (OCTET_LENGTH(previous_secret_ciphertext) > 16 AND OCTET_LENGTH(previous_secret_nonce) = 12 AND
+             previous_secret_key_version > 0 AND previous_secret_expires_at IS NOT NULL)
+        );
@@ -0,0 +1,11 @@
+UPDATE t_external_tenant
+SET policy_json = JSON_SET(policy_json, '$.maxRunningRuns', CAST(JSON_UNQUOTE(JSON_EXTRACT(policy_json, '$.maxRunningJobs')) AS UNSIGNED))
+WHERE NOT JSON_CONTAINS_PATH(policy_json, 'one', '$.maxRunningRuns');
+-- migrate:split
+-- migrate:replay-errors 1060
+ALTER TABLE t_external_usage_rollup
+    ADD COLUMN run_execution_millis BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER job_execution_millis;
+-- migrate:split
+-- migrate:replay-errors 1061
+ALTER TABLE t_external_run
+    ADD KEY idx_external_run_finished (finished_at, id);
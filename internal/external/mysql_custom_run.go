@@ -0,0 +1,288 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// customRunLeaseAllowance covers compilation, sandbox queueing and the
+// settlement round trip on top of the run's own time limit. A run still
+// RUNNING after its lease belongs to a crashed pod and is refunded.
+const customRunLeaseAllowance = 2 * time.Minute
+
+// ReserveCustomRun admits one custom run against the tenant's concurrent run
+// ceiling and reserves its full time limit in the MySQL daily ledger. Runs
+// have their own MaxRunningRuns ceiling and share only DailyExecutionMillis
+// with judge jobs, so an interactive run never displaces queued work.
+func (repository *MySQLJobRepository) ReserveCustomRun(ctx context.Context, tenantExternalID string, request CustomRunRequest) (_ CustomRunReservation, err error) {
+	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) {
+		return CustomRunReservation{}, ErrCustomRunInvalid
+	}
+	if err := ValidateCustomRunRequest(request); err != nil {
+		return CustomRunReservation{}, err
+	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.ReserveCustomRun", attribute.String("croj.tenant", tenantExternalID))
+	defer func() { endSpan(span, err) }()
+	runExternalID, err := generateExternalID(repository.random)
+	if err != nil {
+		return CustomRunReservation{}, repositoryUnavailable("generate custom run ID", err)
+	}
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return CustomRunReservation{}, repositoryUnavailable("begin custom run reservation", err)
+	}
+	defer tx.Rollback()
+	clock, err := mysqlCurrentTransactionClock(ctx, tx)
+	if err != nil {
+		return CustomRunReservation{}, err
+	}
+	tenantID, policy, err := lockTenantPolicy(ctx, tx, tenantExternalID)
+	if err != nil {
+		return CustomRunReservation{}, err
+	}
+	if int64(len(request.SourceCode)) > policy.MaxSourceBytes {
+		return CustomRunReservation{}, fmt.Errorf("%w: source code exceeds tenant policy", ErrCustomRunInvalid)
+	}
+	if _, err := expireCustomRuns(ctx, tx, tenantID, clock.now); err != nil {
+		return CustomRunReservation{}, err
+	}
+	var running int
+	if err := tx.QueryRowContext(ctx, `
+	SELECT COUNT(*) FROM t_external_run WHERE tenant_id = ? AND status = 'RUNNING'`, tenantID).Scan(&running); err != nil {
+		return CustomRunReservation{}, repositoryUnavailable("count running custom runs", err)
+	}
+	if running >= policy.RunningRunLimit() {
+		return CustomRunReservation{}, ErrCustomRunCapacity
+	}
+	timeLimit := cappedRunLimit(request.TimeLimitMillis, policy.MaxTimeLimitMillis)
+	memoryLimit := cappedRunLimit(request.MemoryLimitMiB, policy.MaxMemoryLimitMiB)
+	reserved := int64(timeLimit)
+	decision, err := reserveDailyExecution(ctx, tx, tenantID, clock.accountingDay, policy.DailyExecutionMillis, reserved)
+	if err != nil {
+		return CustomRunReservation{}, err
+	}
+	if decision != dailyReservationAllowed {
+		return CustomRunReservation{}, ErrDailyExecutionExhausted
+	}
+	leaseUntil := clock.now.Add(time.Duration(timeLimit)*time.Millisecond + customRunLeaseAllowance)
+	result, err := tx.ExecContext(ctx, `
+	INSERT INTO t_external_run(tenant_id, external_id, language, accounting_day, reserved_millis, lease_until, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`, tenantID, runExternalID, request.Language, clock.accountingDay, reserved, leaseUntil, clock.now)
+	if err != nil {
+		return CustomRunReservation{}, repositoryUnavailable("insert custom run", err)
+	}
+	runID, err := result.LastInsertId()
+	if err != nil || runID <= 0 {
+		return CustomRunReservation{}, repositoryUnavailable("insert custom run", ErrExternalJobUnavailable)
+	}
+	if err := tx.Commit(); err != nil {
+		return CustomRunReservation{}, repositoryUnavailable("commit custom run reservation", err)
+	}
+	return CustomRunReservation{
+		InternalID:       uint64(runID),
+		ExternalID:       runExternalID,
+		TenantInternalID: tenantID,
+		TimeLimitMillis:  timeLimit,
+		MemoryLimitMiB:   memoryLimit,
+		ReservedMillis:   reserved,
+	}, nil
+}
+
+// SettleCustomRun releases the reservation of a run that is still RUNNING. A
+// run already expired by a later reservation returns ErrCustomRunLeaseLost;
+// the expiry refunded it, so the caller has nothing left to settle.
+func (repository *MySQLJobRepository) SettleCustomRun(ctx context.Context, reservation CustomRunReservation, settlement CustomRunSettlement) (err error) {
+	if repository == nil || reservation.InternalID == 0 || reservation.TenantInternalID == 0 || settlement.ConsumedMillis < 0 {
+		return ErrCustomRunInvalid
+	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.SettleCustomRun", attribute.String("croj.run", reservation.ExternalID))
+	defer func() { endSpan(span, err) }()
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return repositoryUnavailable("begin custom run settlement", err)
+	}
+	defer tx.Rollback()
+	now, err := mysqlCurrentTime(ctx, tx)
+	if err != nil {
+		return err
+	}
+	var tenantID uint64
+	if err := tx.QueryRowContext(ctx, `
+	SELECT id FROM t_external_tenant WHERE id = ? FOR UPDATE`, reservation.TenantInternalID).Scan(&tenantID); err != nil {
+		return repositoryUnavailable("lock custom run tenant", err)
+	}
+	var accountingDay time.Time
+	var reserved int64
+	if err := tx.QueryRowContext(ctx, `
+	SELECT accounting_day, reserved_millis FROM t_external_run
+	WHERE id = ? AND tenant_id = ? AND status = 'RUNNING' FOR UPDATE`, reservation.InternalID, tenantID).
+		Scan(&accountingDay, &reserved); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCustomRunLeaseLost
+		}
+		return repositoryUnavailable("lock custom run", err)
+	}
+	status := "COMPLETED"
+	consumed := min(settlement.ConsumedMillis, reserved)
+	switch {
+	case settlement.InfrastructureFailure:
+		status, consumed = "FAILED", 0
+	case settlement.ChargeFullReservation:
+		consumed = reserved
+	}
+	if err := releaseCustomRunReservation(ctx, tx, tenantID, accountingDay, reserved, consumed, now); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+	UPDATE t_external_run SET status = ?, consumed_millis = ?, finished_at = ?
+	WHERE id = ?`, status, consumed, now, reservation.InternalID); err != nil {
+		return repositoryUnavailable("finish custom run", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return repositoryUnavailable("commit custom run settlement", err)
+	}
+	return nil
+}
+
+// RefundExpiredCustomRuns refunds the runs of up to tenants tenants whose
+// lease ended without a settlement. ReserveCustomRun does the same for its
+// own tenant, but a tenant that makes no further run would otherwise keep a
+// crashed pod's reservation against DailyExecutionMillis until the day
+// rolls over. Each tenant is handled under its tenant lock in its own
+// transaction, and the refund wakes jobs deferred on the daily budget.
+func (repository *MySQLJobRepository) RefundExpiredCustomRuns(ctx context.Context, tenants int) (int64, error) {
+	if repository == nil || tenants < 1 || tenants > 1000 {
+		return 0, ErrSourceRetentionNotAvailable
+	}
+	// Probing each tenant through idx_external_run_tenant_status_lease keeps
+	// the pass off the full run table.
+	rows, err := repository.database.QueryContext(ctx, `
+	SELECT tenant.id FROM t_external_tenant AS tenant
+	WHERE EXISTS (
+	    SELECT 1 FROM t_external_run AS run
+	    WHERE run.tenant_id = tenant.id AND run.status = 'RUNNING' AND run.lease_until <= CURRENT_TIMESTAMP(3))
+	ORDER BY tenant.id
+	LIMIT ?`, tenants)
+	if err != nil {
+		return 0, repositoryUnavailable("find expired custom runs", err)
+	}
+	tenantIDs := make([]uint64, 0, tenants)
+	for rows.Next() {
+		var tenantID uint64
+		if err := rows.Scan(&tenantID); err != nil {
+			_ = rows.Close()
+			return 0, repositoryUnavailable("scan expired custom run tenant", err)
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, repositoryUnavailable("iterate expired custom run tenants", err)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, repositoryUnavailable("close expired custom run tenants", err)
+	}
+	var refunded int64
+	for _, tenantID := range tenantIDs {
+		count, err := repository.refundExpiredTenantRuns(ctx, tenantID)
+		if err != nil {
+			return refunded, err
+		}
+		refunded += count
+	}
+	return refunded, nil
+}
+
+func (repository *MySQLJobRepository) refundExpiredTenantRuns(ctx context.Context, tenantID uint64) (int64, error) {
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, repositoryUnavailable("begin custom run refund", err)
+	}
+	defer tx.Rollback()
+	clock, err := mysqlCurrentTransactionClock(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	var lockedID uint64
+	if err := tx.QueryRowContext(ctx, `
+	SELECT id FROM t_external_tenant WHERE id = ? FOR UPDATE`, tenantID).Scan(&lockedID); err != nil {
+		return 0, repositoryUnavailable("lock custom run refund tenant", err)
+	}
+	count, err := expireCustomRuns(ctx, tx, tenantID, clock.now)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, repositoryUnavailable("commit custom run refund", err)
+	}
+	return count, nil
+}
+
+// expireCustomRuns refunds runs whose pod stopped before settling. It runs
+// under the tenant lock, so a concurrent settlement either finishes first or
+// observes the EXPIRED status and reports a lost lease.
+func expireCustomRuns(ctx context.Context, tx *sql.Tx, tenantID uint64, now time.Time) (int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+	SELECT id, accounting_day, reserved_millis FROM t_external_run
+	WHERE tenant_id = ? AND status = 'RUNNING' AND lease_until <= ?
+	ORDER BY id FOR UPDATE`, tenantID, now)
+	if err != nil {
+		return 0, repositoryUnavailable("lock expired custom runs", err)
+	}
+	type expiredRun struct {
+		id            uint64
+		accountingDay time.Time
+		reserved      int64
+	}
+	var expired []expiredRun
+	for rows.Next() {
+		var run expiredRun
+		if err := rows.Scan(&run.id, &run.accountingDay, &run.reserved); err != nil {
+			rows.Close()
+			return 0, repositoryUnavailable("scan expired custom run", err)
+		}
+		expired = append(expired, run)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, repositoryUnavailable("close expired custom runs", err)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, repositoryUnavailable("read expired custom runs", err)
+	}
+	for _, run := range expired {
+		if err := releaseCustomRunReservation(ctx, tx, tenantID, run.accountingDay, run.reserved, 0, now); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+		UPDATE t_external_run SET status = 'EXPIRED', finished_at = ? WHERE id = ?`, now, run.id); err != nil {
+			return 0, repositoryUnavailable("expire custom run", err)
+		}
+	}
+	return int64(len(expired)), nil
+}
+
+func releaseCustomRunReservation(ctx context.Context, tx *sql.Tx, tenantID uint64, accountingDay time.Time, reserved, consumed int64, now time.Time) error {
+	result, err := tx.ExecContext(ctx, `
+	UPDATE t_external_execution_daily
+	SET reserved_millis = reserved_millis - ?, consumed_millis = consumed_millis + ?
+	WHERE tenant_id = ? AND accounting_day = ? AND reserved_millis >= ?`, reserved, consumed, tenantID, accountingDay, reserved)
+	if err != nil {
+		return repositoryUnavailable("settle custom run ledger", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return repositoryUnavailable("settle custom run ledger", ErrExternalJobUnavailable)
+	}
+	return wakeDailyDeferredJobs(ctx, tx, tenantID, accountingDay, now)
+}
+
+func cappedRunLimit(requested, ceiling int) int {
+	if requested <= 0 || requested > ceiling {
+		return ceiling
+	}
+	return requested
+}
@@ -0,0 +1,148 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMySQLCustomRunReservesCappedLimitsAndSettlesMeasuredTime(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("r", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, strings.Repeat("s", 26), "", 4)
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	reservation, err := repository.ReserveCustomRun(context.Background(), tenantID, CustomRunRequest{
+		Language: "cpp", SourceCode: []byte("int main(){}"), TimeLimitMillis: 60_000, MemoryLimitMiB: 128,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reservation.TimeLimitMillis != 10_000 || reservation.MemoryLimitMiB != 128 || reservation.ReservedMillis != 10_000 {
+		t.Fatalf("reservation = %+v", reservation)
+	}
+	assertExecutionAccounting(t, database, 10_000, 0)
+	if _, err := repository.ReserveCustomRun(context.Background(), tenantID, CustomRunRequest{
+		Language: "cpp", SourceCode: []byte("int main(){}"),
+	}); !errors.Is(err, ErrCustomRunCapacity) {
+		t.Fatalf("second concurrent run error = %v", err)
+	}
+	if err := repository.SettleCustomRun(context.Background(), reservation, CustomRunSettlement{ConsumedMillis: 250}); err != nil {
+		t.Fatal(err)
+	}
+	assertExecutionAccounting(t, database, 0, 250)
+	if err := repository.SettleCustomRun(context.Background(), reservation, CustomRunSettlement{ConsumedMillis: 250}); !errors.Is(err, ErrCustomRunLeaseLost) {
+		t.Fatalf("double settlement error = %v", err)
+	}
+	var status string
+	var consumed int64
+	if err := database.QueryRow(`SELECT status, consumed_millis FROM t_external_run WHERE external_id = ?`, reservation.ExternalID).Scan(&status, &consumed); err != nil {
+		t.Fatal(err)
+	}
+	if status != "COMPLETED" || consumed != 250 {
+		t.Fatalf("run status=%s consumed=%d", status, consumed)
+	}
+}
+
+func TestMySQLCustomRunDailyBudgetAndExpiredLeaseRefund(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("u", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, strings.Repeat("v", 26), "", 4)
+	if _, err := database.Exec(`UPDATE t_external_tenant SET policy_json = JSON_SET(policy_json, '$.maxRunningJobs', 2, '$.dailyExecutionMillis', 1500) WHERE external_id = ?`, tenantID); err != nil {
+		t.Fatal(err)
+	}
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	request := CustomRunRequest{Language: "cpp", SourceCode: []byte("int main(){}"), TimeLimitMillis: 1000}
+	crashed, err := repository.ReserveCustomRun(context.Background(), tenantID, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.ReserveCustomRun(context.Background(), tenantID, request); !errors.Is(err, ErrDailyExecutionExhausted) {
+		t.Fatalf("over-budget run error = %v", err)
+	}
+	if _, err := database.Exec(`UPDATE t_external_run SET lease_until = CURRENT_TIMESTAMP(3) - INTERVAL 1 SECOND WHERE id = ?`, crashed.InternalID); err != nil {
+		t.Fatal(err)
+	}
+	next, err := repository.ReserveCustomRun(context.Background(), tenantID, request)
+	if err != nil {
+		t.Fatalf("expired run reservation was not refunded: %v", err)
+	}
+	assertExecutionAccounting(t, database, 1000, 0)
+	if err := repository.SettleCustomRun(context.Background(), crashed, CustomRunSettlement{ConsumedMillis: 10}); !errors.Is(err, ErrCustomRunLeaseLost) {
+		t.Fatalf("expired run settlement error = %v", err)
+	}
+	if err := repository.SettleCustomRun(context.Background(), next, CustomRunSettlement{InfrastructureFailure: true, ConsumedMillis: 900}); err != nil {
+		t.Fatal(err)
+	}
+	assertExecutionAccounting(t, database, 0, 0)
+
+	// A tenant that never reserves again is refunded by the periodic pass.
+	abandoned, err := repository.ReserveCustomRun(context.Background(), tenantID, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refunded, err := repository.RefundExpiredCustomRuns(context.Background(), 10); err != nil || refunded != 0 {
+		t.Fatalf("refund of a live lease = %d error=%v", refunded, err)
+	}
+	if _, err := database.Exec(`UPDATE t_external_run SET lease_until = CURRENT_TIMESTAMP(3) - INTERVAL 1 SECOND WHERE id = ?`, abandoned.InternalID); err != nil {
+		t.Fatal(err)
+	}
+	if refunded, err := repository.RefundExpiredCustomRuns(context.Background(), 10); err != nil || refunded != 1 {
+		t.Fatalf("refund of an expired lease = %d error=%v", refunded, err)
+	}
+	assertExecutionAccounting(t, database, 0, 0)
+	var status string
+	if err := database.QueryRow(`SELECT status FROM t_external_run WHERE id = ?`, abandoned.InternalID).Scan(&status); err != nil || status != "EXPIRED" {
+		t.Fatalf("abandoned run status=%s error=%v", status, err)
+	}
+}
+
+func TestMySQLCustomRunOwnCapAndRetentionRollsUpUsage(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("w", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, strings.Repeat("x", 26), "", 4)
+	if _, err := database.Exec(`UPDATE t_external_tenant SET policy_json = JSON_SET(policy_json, '$.maxRunningRuns', 2) WHERE external_id = ?`, tenantID); err != nil {
+		t.Fatal(err)
+	}
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	request := CustomRunRequest{Language: "cpp", SourceCode: []byte("int main(){}"), TimeLimitMillis: 1000}
+	var reservations []CustomRunReservation
+	for range 2 {
+		reservation, err := repository.ReserveCustomRun(context.Background(), tenantID, request)
+		if err != nil {
+			t.Fatalf("run within maxRunningRuns above maxRunningJobs: %v", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+	if _, err := repository.ReserveCustomRun(context.Background(), tenantID, request); !errors.Is(err, ErrCustomRunCapacity) {
+		t.Fatalf("run beyond maxRunningRuns error = %v", err)
+	}
+	for index, reservation := range reservations {
+		if err := repository.SettleCustomRun(context.Background(), reservation, CustomRunSettlement{ConsumedMillis: int64(100 * (index + 1))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.Exec(`UPDATE t_external_run SET finished_at = CURRENT_TIMESTAMP(3) - INTERVAL 2 DAY WHERE id = ?`, reservations[0].InternalID); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := repository.ExpireCustomRunBatch(context.Background(), 24*time.Hour, 1000); err != nil || count != 1 {
+		t.Fatalf("expired runs=%d err=%v", count, err)
+	}
+	if _, err := repository.ExpireCustomRunBatch(context.Background(), 24*time.Hour, 1000); !errors.Is(err, ErrSourceRetentionNotAvailable) {
+		t.Fatalf("second retention batch error = %v", err)
+	}
+	var remaining, rolledUp int64
+	if err := database.QueryRow(`SELECT COUNT(*) FROM t_external_run`).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.QueryRow(`SELECT run_execution_millis FROM t_external_usage_rollup WHERE language_id = 'cpp'`).Scan(&rolledUp); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 1 || rolledUp != 100 {
+		t.Fatalf("remaining runs=%d rolled up=%d", remaining, rolledUp)
+	}
+}
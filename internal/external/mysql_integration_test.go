@@ -645,9 +645,9 @@ func resetMySQLIntegrationSchema(t *testing.T, database *sql.DB) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	for _, table := range []string{
-		"t_external_retention_audit", "t_external_execution_daily", "t_external_source_reservation", "t_external_webhook_outbox", "t_external_job_attempt", "t_external_idempotency",
+		"t_external_retention_audit", "t_external_execution_daily", "t_external_source_reservation", "t_external_webhook_outbox", "t_external_job_event", "t_external_job_attempt", "t_external_idempotency",
 		"t_external_job", "t_external_source_object", "t_external_callback", "t_external_bundle",
-		"t_external_api_key", "t_external_tenant", "t_judge_schema_history",
+		"t_external_run", "t_external_api_key", "t_external_tenant", "t_judge_schema_history",
 	} {
 		if _, err := database.ExecContext(ctx, "DROP TABLE IF EXISTS "+table); err != nil {
 			t.Fatalf("drop %s: %v", table, err)
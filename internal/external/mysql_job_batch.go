@@ -0,0 +1,158 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MaximumJobBatchSize bounds one SubmitBatch call. Every staged item holds
+// two reservation row locks in the admission transaction.
+const MaximumJobBatchSize = 100
+
+// BatchJobSubmission is one item of a batch, keyed like a single submission.
+type BatchJobSubmission struct {
+	IdempotencyKey string
+	Request        JudgeJobRequest
+}
+
+// BatchSubmitJobResult reports one item. Err carries the error Submit would
+// have returned for the same request.
+type BatchSubmitJobResult struct {
+	SubmitJobResult
+	Err error
+}
+
+// SubmitBatch admits several jobs for one tenant. Each item is staged exactly
+// like Submit (replay lookup, admission, source upload) and every staged item
+// is then checked and inserted in a single transaction under one tenant
+// policy lock, so the queued quota is charged in item order. A conflict,
+// policy rejection or exhausted quota affects only its own item; any other
+// failure in the transaction fails every staged item and is compensated.
+// admit is called at most once per item, with the item index, and only for
+// items that create a job.
+func (repository *MySQLJobRepository) SubmitBatch(
+	ctx context.Context,
+	tenantExternalID string,
+	items []BatchJobSubmission,
+	admit func(context.Context, int) error,
+) (results []BatchSubmitJobResult, resultErr error) {
+	if repository == nil || admit == nil || !externalIDPattern.MatchString(tenantExternalID) ||
+		len(items) == 0 || len(items) > MaximumJobBatchSize {
+		return nil, ErrExternalJobInvalid
+	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.SubmitBatch",
+		attribute.String("croj.tenant", tenantExternalID), attribute.Int("croj.batch_size", len(items)))
+	defer func() { endSpan(span, resultErr) }()
+	ctx, cancelSubmit := submissionOperationContext(ctx, repository.submitOperationTimeout)
+	defer cancelSubmit()
+
+	results = make([]BatchSubmitJobResult, len(items))
+	staged := make(map[int]*jobSubmission, len(items))
+	var order []int
+	keys := make(map[string]struct{}, len(items))
+	for index, item := range items {
+		submission, err := repository.prepareSubmission(tenantExternalID, item.IdempotencyKey, item.Request)
+		if err != nil {
+			results[index].Err = err
+			continue
+		}
+		// A second item with the same key would wait on the first item's
+		// coordination reservation for the rest of the request.
+		if _, duplicate := keys[string(submission.keyDigest)]; duplicate {
+			results[index].Err = fmt.Errorf("%w: duplicate idempotency key in batch", ErrExternalJobInvalid)
+			continue
+		}
+		keys[string(submission.keyDigest)] = struct{}{}
+		replay, ok, err := repository.stageSubmission(ctx, submission, func(admissionContext context.Context) error {
+			return admit(admissionContext, index)
+		})
+		if err != nil || !ok {
+			results[index] = BatchSubmitJobResult{SubmitJobResult: replay, Err: err}
+			continue
+		}
+		staged[index] = submission
+		order = append(order, index)
+	}
+	if len(order) == 0 {
+		return results, nil
+	}
+	failStaged := func(err error) {
+		for _, index := range order {
+			results[index] = BatchSubmitJobResult{Err: err}
+		}
+	}
+
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		failStaged(repositoryUnavailable("begin batch submit transaction", err))
+		for _, index := range order {
+			_ = repository.abandonSubmission(ctx, staged[index])
+		}
+		return results, nil
+	}
+	commitOutcomeUnknown := false
+	committed := false
+	var abandoned []int
+	defer func() {
+		if commitOutcomeUnknown {
+			return
+		}
+		if !committed {
+			// Always release database locks before compensating object storage.
+			_ = tx.Rollback()
+			abandoned = order
+		}
+		for _, index := range abandoned {
+			if err := repository.abandonSubmission(ctx, staged[index]); err != nil {
+				results[index] = BatchSubmitJobResult{Err: err}
+			}
+		}
+	}()
+	var claims []sourceReservationClaim
+	for _, index := range order {
+		claims = append(claims, staged[index].reservationClaims()...)
+	}
+	if err := lockSubmissionReservations(ctx, tx, claims...); err != nil {
+		failStaged(repositoryUnavailable("lock encrypted source reservations", err))
+		return results, nil
+	}
+	admission, err := beginSubmissionAdmission(ctx, tx, tenantExternalID)
+	if err != nil {
+		failStaged(err)
+		return results, nil
+	}
+	for _, index := range order {
+		result, err := repository.persistSubmission(ctx, tx, admission, staged[index])
+		switch {
+		case err == nil:
+			results[index] = BatchSubmitJobResult{SubmitJobResult: result}
+			if result.Replayed {
+				abandoned = append(abandoned, index)
+			}
+		case errors.Is(err, ErrExternalJobConflict), errors.Is(err, ErrExternalJobInvalid), errors.Is(err, ErrQueuedQuotaExceeded):
+			results[index] = BatchSubmitJobResult{Err: err}
+			abandoned = append(abandoned, index)
+		default:
+			failStaged(err)
+			return results, nil
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		// As in Submit, keep every staged object for the sweepers; idempotent
+		// retries of the items determine what committed.
+		commitOutcomeUnknown = true
+		failStaged(repositoryUnavailable("commit submitted jobs with unknown outcome", err))
+		return results, nil
+	}
+	committed = true
+	for _, index := range order {
+		if results[index].Err == nil && !results[index].Replayed {
+			staged[index].published()
+		}
+	}
+	return results, nil
+}
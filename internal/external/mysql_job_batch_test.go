@@ -0,0 +1,98 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMySQLJobRepositorySubmitBatchReportsPerItemOutcomes(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("a", 26)
+	bundleID := strings.Repeat("b", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 3)
+	store := newMemorySourceStore()
+	repository := newTestMySQLJobRepository(t, database, store)
+	request := func(source string) JudgeJobRequest {
+		return JudgeJobRequest{BundleID: bundleID, Language: "cpp", SourceCode: []byte(source)}
+	}
+	existing, err := repository.Submit(context.Background(), tenantID, "batch-job-key-0001", request("int main(){return 1;}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var admitted []int
+	results, err := repository.SubmitBatch(context.Background(), tenantID, []BatchJobSubmission{
+		{IdempotencyKey: "batch-job-key-0001", Request: request("int main(){return 1;}")},
+		{IdempotencyKey: "batch-job-key-0002", Request: request("int main(){return 2;}")},
+		{IdempotencyKey: "batch-job-key-0001", Request: request("int main(){return 9;}")},
+		{IdempotencyKey: "batch-job-key-0003", Request: JudgeJobRequest{BundleID: strings.Repeat("z", 26), Language: "cpp", SourceCode: []byte("x")}},
+		{IdempotencyKey: "batch-job-key-0004", Request: request("int main(){return 4;}")},
+		{IdempotencyKey: "batch-job-key-0005", Request: request("int main(){return 5;}")},
+	}, func(_ context.Context, index int) error {
+		admitted = append(admitted, index)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Replayed || results[0].Job.ExternalID != existing.Job.ExternalID {
+		t.Fatalf("replayed item = %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Replayed || results[1].Job.Status != JobStatusQueued {
+		t.Fatalf("created item = %+v", results[1])
+	}
+	if !errors.Is(results[2].Err, ErrExternalJobInvalid) || !errors.Is(results[3].Err, ErrExternalJobInvalid) {
+		t.Fatalf("invalid items = %+v, %+v", results[2], results[3])
+	}
+	// The tenant may queue three jobs: the existing one, item 1 and item 4.
+	if results[4].Err != nil || !errors.Is(results[5].Err, ErrQueuedQuotaExceeded) {
+		t.Fatalf("quota items = %+v, %+v", results[4], results[5])
+	}
+	if jobs := mustCount(t, database, "SELECT COUNT(*) FROM t_external_job"); jobs != 3 {
+		t.Fatalf("jobs = %d", jobs)
+	}
+	if reservations := mustCount(t, database, "SELECT COUNT(*) FROM t_external_source_reservation"); reservations != 0 {
+		t.Fatalf("leftover source reservations = %d", reservations)
+	}
+	objects, _, _ := store.snapshot()
+	if len(objects) != 3 {
+		t.Fatalf("source objects = %d, want one per job", len(objects))
+	}
+	if len(admitted) != 3 {
+		t.Fatalf("admitted item indexes = %v", admitted)
+	}
+	assertMySQLDoesNotContain(t, database, "int main(){return 2;}")
+}
+
+func TestMySQLJobRepositorySubmitBatchRejectsAdmissionPerItem(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("c", 26)
+	bundleID := strings.Repeat("d", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 10)
+	store := newMemorySourceStore()
+	repository := newTestMySQLJobRepository(t, database, store)
+	rateLimited := errors.New("rate limited")
+	results, err := repository.SubmitBatch(context.Background(), tenantID, []BatchJobSubmission{
+		{IdempotencyKey: "batch-admit-key-0001", Request: JudgeJobRequest{BundleID: bundleID, Language: "cpp", SourceCode: []byte("a")}},
+		{IdempotencyKey: "batch-admit-key-0002", Request: JudgeJobRequest{BundleID: bundleID, Language: "cpp", SourceCode: []byte("b")}},
+	}, func(_ context.Context, index int) error {
+		if index == 1 {
+			return rateLimited
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Err != nil || !errors.Is(results[1].Err, rateLimited) {
+		t.Fatalf("results = %+v", results)
+	}
+	_, puts, _ := store.snapshot()
+	if jobs := mustCount(t, database, "SELECT COUNT(*) FROM t_external_job"); jobs != 1 || puts != 1 {
+		t.Fatalf("jobs=%d source puts=%d", jobs, puts)
+	}
+}
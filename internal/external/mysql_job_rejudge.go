@@ -0,0 +1,405 @@
+package external
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrJobNotRejudgeable = errors.New("external judge job cannot be rejudged")
+
+// bundleRejudgeCursorKind keeps bundle rejudge cursors apart from job list
+// cursors signed with the same key.
+const bundleRejudgeCursorKind = "bundle-rejudge"
+
+// RejudgeOptions selects what a rejudge changes. An empty BundleID keeps the
+// original job's bundle.
+type RejudgeOptions struct {
+	BundleID string
+	Priority int
+}
+
+// BundleRejudgeOptions pages a bundle rejudge. The options are bound into the
+// cursor, so every page of one fan-out rejudges onto the same bundle.
+type BundleRejudgeOptions struct {
+	RejudgeOptions
+	Cursor string
+	Limit  int
+}
+
+// BundleRejudgeItem reports the rejudge of one original job of the bundle.
+type BundleRejudgeItem struct {
+	RejudgeOf string
+	BatchSubmitJobResult
+}
+
+type BundleRejudgeResult struct {
+	Items      []BundleRejudgeItem
+	NextCursor string
+}
+
+type BundleRejudgeCursor struct {
+	TenantID            string
+	BundleID            string
+	ReplacementBundleID string
+	Priority            int
+	InternalID          uint64
+}
+
+type bundleRejudgeCursorPayload struct {
+	Version             int    `json:"v"`
+	Kind                string `json:"k"`
+	TenantID            string `json:"t"`
+	BundleID            string `json:"b"`
+	ReplacementBundleID string `json:"r,omitempty"`
+	Priority            int    `json:"p,omitempty"`
+	InternalID          uint64 `json:"i"`
+}
+
+func (codec *JobCursorCodec) EncodeBundleRejudge(cursor BundleRejudgeCursor) (string, error) {
+	if codec == nil || len(codec.key) < sha256.Size || !validBundleRejudgeCursor(cursor) {
+		return "", ErrInvalidJobCursor
+	}
+	payload, err := json.Marshal(bundleRejudgeCursorPayload{
+		Version: 1, Kind: bundleRejudgeCursorKind, TenantID: cursor.TenantID, BundleID: cursor.BundleID,
+		ReplacementBundleID: cursor.ReplacementBundleID, Priority: cursor.Priority, InternalID: cursor.InternalID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: encode payload", ErrInvalidJobCursor)
+	}
+	return codec.sign(payload), nil
+}
+
+// DecodeBundleRejudge accepts only a cursor issued for the same tenant,
+// bundle, replacement bundle and priority.
+func (codec *JobCursorCodec) DecodeBundleRejudge(encoded string, want BundleRejudgeCursor) (BundleRejudgeCursor, error) {
+	payload, ok := codec.verify(encoded)
+	if !ok {
+		return BundleRejudgeCursor{}, ErrInvalidJobCursor
+	}
+	var decoded bundleRejudgeCursorPayload
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decoded); err != nil || decoded.Version != 1 || decoded.Kind != bundleRejudgeCursorKind {
+		return BundleRejudgeCursor{}, ErrInvalidJobCursor
+	}
+	cursor := BundleRejudgeCursor{
+		TenantID: decoded.TenantID, BundleID: decoded.BundleID, ReplacementBundleID: decoded.ReplacementBundleID,
+		Priority: decoded.Priority, InternalID: decoded.InternalID,
+	}
+	if cursor.TenantID != want.TenantID || cursor.BundleID != want.BundleID ||
+		cursor.ReplacementBundleID != want.ReplacementBundleID || cursor.Priority != want.Priority ||
+		!validBundleRejudgeCursor(cursor) {
+		return BundleRejudgeCursor{}, ErrInvalidJobCursor
+	}
+	return cursor, nil
+}
+
+func validBundleRejudgeCursor(cursor BundleRejudgeCursor) bool {
+	return externalIDPattern.MatchString(cursor.TenantID) && externalIDPattern.MatchString(cursor.BundleID) &&
+		(cursor.ReplacementBundleID == "" || externalIDPattern.MatchString(cursor.ReplacementBundleID)) &&
+		cursor.Priority >= 0 && cursor.Priority <= MaximumJobPriority && cursor.InternalID != 0
+}
+
+// Rejudge admits a new job that re-runs the stored source of a finished job
+// and records the original in RejudgeOf. The source is decrypted and stored
+// again under the new job, so the rejudge outlives the original's retention.
+// Language, stopOnFailure and clientReference are copied; the callback is
+// kept only while it is still enabled. Admission and quota are exactly those
+// of Submit. The idempotency identity is CanonicalRejudgeRequestHash, and a
+// retry replays from the idempotency record before the original is read.
+func (repository *MySQLJobRepository) Rejudge(
+	ctx context.Context,
+	tenantExternalID string,
+	idempotencyKey string,
+	jobExternalID string,
+	options RejudgeOptions,
+	admit func(context.Context) error,
+) (result SubmitJobResult, resultErr error) {
+	if repository == nil || admit == nil || !externalIDPattern.MatchString(tenantExternalID) {
+		return SubmitJobResult{}, ErrExternalJobInvalid
+	}
+	if !externalIDPattern.MatchString(jobExternalID) {
+		return SubmitJobResult{}, ErrExternalJobNotFound
+	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.Rejudge",
+		attribute.String("croj.tenant", tenantExternalID), attribute.String("croj.rejudge_of", jobExternalID))
+	defer func() { endSpan(span, resultErr) }()
+	keyDigest, requestHash, err := repository.rejudgeIdentity(idempotencyKey, jobExternalID, options)
+	if err != nil {
+		return SubmitJobResult{}, err
+	}
+	if replay, found, err := repository.findSubmitReplay(ctx, tenantExternalID, keyDigest, requestHash); err != nil || found {
+		return replay, err
+	}
+	original, err := getExternalJob(ctx, repository.database, tenantExternalID, jobExternalID, false)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SubmitJobResult{}, ErrExternalJobNotFound
+	}
+	if err != nil {
+		return SubmitJobResult{}, repositoryUnavailable("read rejudged job", err)
+	}
+	request, err := repository.rejudgeRequest(ctx, original, options)
+	if err != nil {
+		return SubmitJobResult{}, err
+	}
+	defer clear(request.SourceCode)
+	request.rejudgeHash = requestHash
+	return repository.Submit(ctx, tenantExternalID, idempotencyKey, request, admit)
+}
+
+// rejudgeIdentity derives the idempotency key digest and request hash that a
+// rejudge of jobExternalID is recorded under.
+func (repository *MySQLJobRepository) rejudgeIdentity(idempotencyKey, jobExternalID string, options RejudgeOptions) ([]byte, []byte, error) {
+	keyDigest, err := DigestIdempotencyKey(idempotencyKey, repository.idempotencyPepper)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid idempotency key", ErrExternalJobInvalid)
+	}
+	requestHash, err := CanonicalRejudgeRequestHash(jobExternalID, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrExternalJobInvalid, err)
+	}
+	return keyDigest, requestHash, nil
+}
+
+// RejudgeBundle rejudges one page of a bundle's retained original jobs, in
+// job order. Originals are judged jobs (see rejudgeableStatus) that are not
+// themselves rejudges and whose source retention has not started, so the
+// rejudges a fan-out creates never become candidates of a later page. Each
+// item is recorded under a key derived from idempotencyKey and the original
+// job, so retrying a page with the same key replays instead of duplicating,
+// without reading the source again.
+// A page holds at most options.Limit originals and, beyond its first item, at
+// most MaximumSourceBytes of source. admit follows the SubmitBatch contract
+// with the index of the item in the returned page.
+func (repository *MySQLJobRepository) RejudgeBundle(
+	ctx context.Context,
+	tenantExternalID string,
+	idempotencyKey string,
+	bundleExternalID string,
+	options BundleRejudgeOptions,
+	admit func(context.Context, int) error,
+) (_ BundleRejudgeResult, resultErr error) {
+	if repository == nil || admit == nil || !externalIDPattern.MatchString(tenantExternalID) ||
+		ValidateIdempotencyKey(idempotencyKey) != nil || options.Limit < 1 || options.Limit > MaximumJobBatchSize ||
+		options.BundleID != "" && !externalIDPattern.MatchString(options.BundleID) ||
+		options.Priority < 0 || options.Priority > MaximumJobPriority {
+		return BundleRejudgeResult{}, ErrExternalJobInvalid
+	}
+	if !externalIDPattern.MatchString(bundleExternalID) {
+		return BundleRejudgeResult{}, ErrBundleNotFound
+	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.RejudgeBundle",
+		attribute.String("croj.tenant", tenantExternalID), attribute.String("croj.bundle", bundleExternalID))
+	defer func() { endSpan(span, resultErr) }()
+	bound := BundleRejudgeCursor{
+		TenantID: tenantExternalID, BundleID: bundleExternalID,
+		ReplacementBundleID: options.BundleID, Priority: options.Priority,
+	}
+	var afterInternalID uint64
+	if options.Cursor != "" {
+		cursor, err := repository.cursor.DecodeBundleRejudge(options.Cursor, bound)
+		if err != nil {
+			return BundleRejudgeResult{}, ErrInvalidJobCursor
+		}
+		afterInternalID = cursor.InternalID
+	}
+	var bundleExists int
+	if err := repository.database.QueryRowContext(ctx, `
+SELECT EXISTS(SELECT 1 FROM t_external_bundle AS bundle
+JOIN t_external_tenant AS tenant ON tenant.id = bundle.tenant_id
+WHERE tenant.external_id = ? AND bundle.external_id = ?)`, tenantExternalID, bundleExternalID).Scan(&bundleExists); err != nil {
+		return BundleRejudgeResult{}, repositoryUnavailable("read rejudged bundle", err)
+	}
+	if bundleExists != 1 {
+		return BundleRejudgeResult{}, ErrBundleNotFound
+	}
+	originals, more, err := repository.bundleRejudgeCandidates(ctx, tenantExternalID, bundleExternalID, afterInternalID, options.Limit)
+	if err != nil {
+		return BundleRejudgeResult{}, err
+	}
+	result := BundleRejudgeResult{Items: make([]BundleRejudgeItem, len(originals))}
+	if len(originals) == 0 {
+		return result, nil
+	}
+	if more {
+		bound.InternalID = originals[len(originals)-1].InternalID
+		if result.NextCursor, err = repository.cursor.EncodeBundleRejudge(bound); err != nil {
+			return BundleRejudgeResult{}, repositoryUnavailable("encode next cursor", err)
+		}
+	}
+
+	itemKeyPrefix := bundleRejudgeItemKeyPrefix(idempotencyKey)
+	var submissions []BatchJobSubmission
+	var indexes []int
+	defer func() {
+		for _, submission := range submissions {
+			clear(submission.Request.SourceCode)
+		}
+	}()
+	for index, original := range originals {
+		result.Items[index].RejudgeOf = original.ExternalID
+		itemKey := itemKeyPrefix + original.ExternalID
+		keyDigest, requestHash, err := repository.rejudgeIdentity(itemKey, original.ExternalID, options.RejudgeOptions)
+		if err != nil {
+			return BundleRejudgeResult{}, err
+		}
+		replay, found, err := repository.findSubmitReplay(ctx, tenantExternalID, keyDigest, requestHash)
+		if err != nil || found {
+			result.Items[index].SubmitJobResult, result.Items[index].Err = replay, err
+			continue
+		}
+		request, err := repository.rejudgeRequest(ctx, original, options.RejudgeOptions)
+		if err != nil {
+			result.Items[index].Err = err
+			continue
+		}
+		request.rejudgeHash = requestHash
+		submissions = append(submissions, BatchJobSubmission{IdempotencyKey: itemKey, Request: request})
+		indexes = append(indexes, index)
+	}
+	if len(submissions) == 0 {
+		return result, nil
+	}
+	outcomes, err := repository.SubmitBatch(ctx, tenantExternalID, submissions, func(admissionContext context.Context, position int) error {
+		return admit(admissionContext, indexes[position])
+	})
+	if err != nil {
+		return BundleRejudgeResult{}, err
+	}
+	for position, outcome := range outcomes {
+		result.Items[indexes[position]].BatchSubmitJobResult = outcome
+	}
+	return result, nil
+}
+
+// bundleRejudgeCandidates returns the next page of originals after
+// afterInternalID and whether the bundle has more. A page is cut short once
+// its combined source would exceed MaximumSourceBytes.
+func (repository *MySQLJobRepository) bundleRejudgeCandidates(
+	ctx context.Context,
+	tenantExternalID, bundleExternalID string,
+	afterInternalID uint64,
+	limit int,
+) ([]ExternalJobRecord, bool, error) {
+	rows, err := repository.database.QueryContext(ctx, externalJobSelect+`
+WHERE tenant.external_id = ? AND bundle.external_id = ? AND job.id > ?
+  AND job.rejudge_of_external_id IS NULL AND job.status IN ('SUCCEEDED', 'FAILED')
+  AND source.delete_marked_at IS NULL
+ORDER BY job.id
+LIMIT ?`, tenantExternalID, bundleExternalID, afterInternalID, limit+1)
+	if err != nil {
+		return nil, false, repositoryUnavailable("list rejudge candidates", err)
+	}
+	defer rows.Close()
+	var originals []ExternalJobRecord
+	var sourceBytes int64
+	more := false
+	for rows.Next() {
+		job, err := scanExternalJob(rows)
+		if err != nil {
+			return nil, false, repositoryUnavailable("scan rejudge candidate", err)
+		}
+		if len(originals) == limit || len(originals) > 0 && sourceBytes+job.Source.SizeBytes > MaximumSourceBytes {
+			more = true
+			break
+		}
+		sourceBytes += job.Source.SizeBytes
+		originals = append(originals, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, repositoryUnavailable("iterate rejudge candidates", err)
+	}
+	return originals, more, nil
+}
+
+// bundleRejudgeItemKeyPrefix derives the per-job key namespace of one
+// fan-out. Appending a job ID yields a valid 67-character key.
+func bundleRejudgeItemKeyPrefix(idempotencyKey string) string {
+	digest := sha256.Sum256([]byte(idempotencyKey))
+	return "rejudge:" + hex.EncodeToString(digest[:16]) + ":"
+}
+
+// rejudgeableStatus reports whether a job in status carries a verdict that a
+// rejudge can replace. Cancelled jobs were withdrawn by the client and never
+// judged, so neither a single nor a bundle rejudge revives them.
+// bundleRejudgeCandidates selects the same statuses in SQL.
+func rejudgeableStatus(status JobStatus) bool {
+	return status == JobStatusSucceeded || status == JobStatusFailed
+}
+
+// rejudgeRequest rebuilds the request of a judged job from its stored source.
+// A source that retention has started to delete is not rejudgeable.
+func (repository *MySQLJobRepository) rejudgeRequest(ctx context.Context, original ExternalJobRecord, options RejudgeOptions) (JudgeJobRequest, error) {
+	if !rejudgeableStatus(original.Status) {
+		return JudgeJobRequest{}, fmt.Errorf("%w: job has no verdict to replace", ErrJobNotRejudgeable)
+	}
+	retained, callbackEnabled, err := repository.rejudgeSourceState(ctx, original)
+	if err != nil {
+		return JudgeJobRequest{}, err
+	}
+	if !retained {
+		return JudgeJobRequest{}, fmt.Errorf("%w: source has been released by retention", ErrJobNotRejudgeable)
+	}
+	source := original.Source
+	getContext, cancelGet := context.WithTimeout(ctx, repository.sourceObjectOperationTimeout)
+	ciphertext, err := repository.sourceObjects.Get(getContext, source.ObjectKey, source.SizeBytes+sourceCiphertextOverheadBytes)
+	cancelGet()
+	if err != nil {
+		// Retention marks the source before deleting the object, so a missing
+		// object of a still-unmarked source is an outage, not a release.
+		if retained, _, stateErr := repository.rejudgeSourceState(ctx, original); stateErr == nil && !retained {
+			return JudgeJobRequest{}, fmt.Errorf("%w: source has been released by retention", ErrJobNotRejudgeable)
+		}
+		return JudgeJobRequest{}, fmt.Errorf("%w: encrypted source object is unavailable", ErrExternalJobUnavailable)
+	}
+	plaintext, err := repository.sourceCipher.Decrypt(original.TenantExternalID, source.ExternalID, EncryptedSource{
+		Ciphertext: ciphertext,
+		Nonce:      append([]byte(nil), source.Nonce...),
+		KeyVersion: source.KeyVersion,
+		SHA256:     append([]byte(nil), source.SHA256...),
+		SizeBytes:  source.SizeBytes,
+	})
+	clear(ciphertext)
+	if err != nil {
+		return JudgeJobRequest{}, repositoryUnavailable("decrypt rejudged source", err)
+	}
+	request := JudgeJobRequest{
+		BundleID: original.BundleExternalID, Language: original.Language, SourceCode: plaintext,
+		StopOnFailure: original.StopOnFailure, ClientReference: original.ClientReference,
+		Priority: options.Priority, RejudgeOf: original.ExternalID,
+	}
+	if options.BundleID != "" {
+		request.BundleID = options.BundleID
+	}
+	if callbackEnabled {
+		request.CallbackID = original.CallbackID
+	}
+	return request, nil
+}
+
+// rejudgeSourceState reports whether the job still exists with an unmarked
+// source, and whether its callback is still enabled.
+func (repository *MySQLJobRepository) rejudgeSourceState(ctx context.Context, original ExternalJobRecord) (bool, bool, error) {
+	var marked, callbackEnabled bool
+	err := repository.database.QueryRowContext(ctx, `
+SELECT source.delete_marked_at IS NOT NULL, COALESCE(callback.disabled_at IS NULL, FALSE)
+FROM t_external_job AS job
+JOIN t_external_source_object AS source ON source.id = job.source_object_id AND source.tenant_id = job.tenant_id
+LEFT JOIN t_external_callback AS callback ON callback.id = job.callback_id AND callback.tenant_id = job.tenant_id
+WHERE job.id = ? AND job.tenant_id = ?`, original.InternalID, original.TenantInternalID).Scan(&marked, &callbackEnabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, repositoryUnavailable("read rejudged source state", err)
+	}
+	return !marked, callbackEnabled, nil
+}
@@ -0,0 +1,182 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func finishExternalJob(t *testing.T, database *sql.DB, jobID string) {
+	t.Helper()
+	if _, err := database.Exec("UPDATE t_external_job SET status = 'SUCCEEDED', completed_at = NOW(3) WHERE external_id = ?", jobID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRejudgeAcceptsOnlyJobsWithAVerdict(t *testing.T) {
+	repository := &MySQLJobRepository{}
+	for status, want := range map[JobStatus]bool{
+		JobStatusQueued: false, JobStatusRunning: false, JobStatusCancelled: false,
+		JobStatusSucceeded: true, JobStatusFailed: true,
+	} {
+		if rejudgeableStatus(status) != want {
+			t.Fatalf("%s rejudgeable = %t", status, !want)
+		}
+		if want {
+			continue
+		}
+		// Refused before any source state is read.
+		if _, err := repository.rejudgeRequest(context.Background(), ExternalJobRecord{Status: status}, RejudgeOptions{}); !errors.Is(err, ErrJobNotRejudgeable) {
+			t.Fatalf("%s rejudge error = %v", status, err)
+		}
+	}
+}
+
+func TestMySQLJobRepositoryRejudgeCopiesTheSourceAndLinksTheOriginal(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("e", 26)
+	bundleID := strings.Repeat("f", 26)
+	replacementID := strings.Repeat("g", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 10)
+	if _, err := database.Exec(`
+INSERT INTO t_external_bundle(external_id, tenant_id, sha256, object_key, size_bytes, case_count, manifest_version, manifest_json, publication_status, ready_at)
+SELECT ?, tenant_id, UNHEX(SHA2(?, 256)), CONCAT(object_key, '.fixed'), size_bytes, case_count, manifest_version, manifest_json, publication_status, ready_at
+FROM t_external_bundle WHERE external_id = ?`, replacementID, replacementID, bundleID); err != nil {
+		t.Fatal(err)
+	}
+	store := newMemorySourceStore()
+	repository := newTestMySQLJobRepository(t, database, store)
+	admit := func(context.Context) error { return nil }
+	original, err := repository.Submit(context.Background(), tenantID, "rejudge-original-0001", JudgeJobRequest{
+		BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){return 7;}"), StopOnFailure: true, ClientReference: "submission-7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repository.Rejudge(context.Background(), tenantID, "rejudge-key-000001", original.Job.ExternalID, RejudgeOptions{}, admit); !errors.Is(err, ErrJobNotRejudgeable) {
+		t.Fatalf("queued job rejudge error = %v", err)
+	}
+	finishExternalJob(t, database, original.Job.ExternalID)
+	rejudged, err := repository.Rejudge(context.Background(), tenantID, "rejudge-key-000001", original.Job.ExternalID, RejudgeOptions{BundleID: replacementID, Priority: 1}, admit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := rejudged.Job
+	if rejudged.Replayed || job.ExternalID == original.Job.ExternalID || job.RejudgeOf != original.Job.ExternalID || job.Status != JobStatusQueued ||
+		job.BundleExternalID != replacementID || job.Priority != 1 || !job.StopOnFailure || job.ClientReference != "submission-7" || job.Language != "cpp" {
+		t.Fatalf("rejudge = %+v", rejudged)
+	}
+	if job.Source.ObjectKey == original.Job.Source.ObjectKey {
+		t.Fatal("rejudge shares the original's source object")
+	}
+	objects, _, _ := store.snapshot()
+	if len(objects) != 2 {
+		t.Fatalf("source objects = %d, want one per job", len(objects))
+	}
+	// A retry replays from the idempotency record without reading the source.
+	if err := store.Delete(context.Background(), original.Job.Source.ObjectKey); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := repository.Rejudge(context.Background(), tenantID, "rejudge-key-000001", original.Job.ExternalID, RejudgeOptions{BundleID: replacementID, Priority: 1}, admit)
+	if err != nil || !replayed.Replayed || replayed.Job.ExternalID != job.ExternalID {
+		t.Fatalf("replay=%+v err=%v", replayed, err)
+	}
+	if _, err := repository.Rejudge(context.Background(), tenantID, "rejudge-key-000001", original.Job.ExternalID, RejudgeOptions{}, admit); !errors.Is(err, ErrExternalJobConflict) {
+		t.Fatalf("changed rejudge under the same key error = %v", err)
+	}
+	if _, err := repository.Rejudge(context.Background(), strings.Repeat("h", 26), "rejudge-key-000002", original.Job.ExternalID, RejudgeOptions{}, admit); !errors.Is(err, ErrExternalJobNotFound) {
+		t.Fatalf("cross-tenant rejudge error = %v", err)
+	}
+	cancelled, err := repository.Submit(context.Background(), tenantID, "rejudge-original-0002", JudgeJobRequest{
+		BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){return 8;}"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("UPDATE t_external_job SET status = 'CANCELLED', completed_at = NOW(3) WHERE external_id = ?", cancelled.Job.ExternalID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.Rejudge(context.Background(), tenantID, "rejudge-key-000003", cancelled.Job.ExternalID, RejudgeOptions{}, admit); !errors.Is(err, ErrJobNotRejudgeable) {
+		t.Fatalf("cancelled job rejudge error = %v", err)
+	}
+	stored, err := repository.Get(context.Background(), tenantID, job.ExternalID)
+	if err != nil || stored.RejudgeOf != original.Job.ExternalID {
+		t.Fatalf("stored=%+v err=%v", stored, err)
+	}
+	assertMySQLDoesNotContain(t, database, "int main(){return 7;}")
+}
+
+func TestMySQLJobRepositoryRejudgeBundlePagesOriginalsAndReplaysRetries(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("j", 26)
+	bundleID := strings.Repeat("k", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 20)
+	store := newMemorySourceStore()
+	repository := newTestMySQLJobRepository(t, database, store)
+	var originals []string
+	for index := range 3 {
+		submitted, err := repository.Submit(context.Background(), tenantID, fmt.Sprintf("bundle-original-%04d", index), JudgeJobRequest{
+			BundleID: bundleID, Language: "cpp", SourceCode: []byte(fmt.Sprintf("int main(){return %d;}", index)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		originals = append(originals, submitted.Job.ExternalID)
+		if index < 2 {
+			finishExternalJob(t, database, submitted.Job.ExternalID)
+		}
+	}
+	if _, err := database.Exec("UPDATE t_external_job SET status = 'CANCELLED', completed_at = NOW(3) WHERE external_id = ?", originals[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	var admitted []int
+	admit := func(_ context.Context, index int) error {
+		admitted = append(admitted, index)
+		return nil
+	}
+	first, err := repository.RejudgeBundle(context.Background(), tenantID, "bundle-rejudge-0001", bundleID, BundleRejudgeOptions{Limit: 1}, admit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Items) != 1 || first.Items[0].RejudgeOf != originals[0] || first.Items[0].Err != nil || first.Items[0].Job.RejudgeOf != originals[0] || first.NextCursor == "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	second, err := repository.RejudgeBundle(context.Background(), tenantID, "bundle-rejudge-0001", bundleID, BundleRejudgeOptions{Cursor: first.NextCursor, Limit: 1}, admit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cancelled third job has no verdict to replace, and the new rejudges are never originals.
+	if len(second.Items) != 1 || second.Items[0].RejudgeOf != originals[1] || second.Items[0].Err != nil || second.NextCursor != "" {
+		t.Fatalf("second page = %+v", second)
+	}
+	if len(admitted) != 2 {
+		t.Fatalf("admitted = %v", admitted)
+	}
+
+	retried, err := repository.RejudgeBundle(context.Background(), tenantID, "bundle-rejudge-0001", bundleID, BundleRejudgeOptions{Limit: 1}, admit)
+	if err != nil || len(retried.Items) != 1 || !retried.Items[0].Replayed || retried.Items[0].Job.ExternalID != first.Items[0].Job.ExternalID {
+		t.Fatalf("retried page=%+v err=%v", retried, err)
+	}
+	if len(admitted) != 2 {
+		t.Fatalf("replayed page was admitted again: %v", admitted)
+	}
+	if jobs := mustCount(t, database, "SELECT COUNT(*) FROM t_external_job"); jobs != 5 {
+		t.Fatalf("jobs = %d", jobs)
+	}
+
+	if _, err := repository.RejudgeBundle(context.Background(), tenantID, "bundle-rejudge-0001", bundleID, BundleRejudgeOptions{
+		RejudgeOptions: RejudgeOptions{Priority: 2}, Cursor: first.NextCursor, Limit: 1,
+	}, admit); !errors.Is(err, ErrInvalidJobCursor) {
+		t.Fatalf("cursor reused with another priority error = %v", err)
+	}
+	if _, err := repository.RejudgeBundle(context.Background(), tenantID, "bundle-rejudge-0002", strings.Repeat("z", 26), BundleRejudgeOptions{Limit: 1}, admit); !errors.Is(err, ErrBundleNotFound) {
+		t.Fatalf("unknown bundle error = %v", err)
+	}
+}
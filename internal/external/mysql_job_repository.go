@@ -10,8 +10,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -104,206 +109,331 @@ func (repository *MySQLJobRepository) Submit(
 	if repository == nil || admit == nil || !externalIDPattern.MatchString(tenantExternalID) {
 		return SubmitJobResult{}, ErrExternalJobInvalid
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.Submit", attribute.String("croj.tenant", tenantExternalID))
+	defer func() {
+		span.SetAttributes(attribute.String("croj.job", result.Job.ExternalID), attribute.Bool("croj.replayed", result.Replayed))
+		endSpan(span, resultErr)
+	}()
 	ctx, cancelSubmit := submissionOperationContext(ctx, repository.submitOperationTimeout)
 	defer cancelSubmit()
+	submission, err := repository.prepareSubmission(tenantExternalID, idempotencyKey, request)
+	if err != nil {
+		return SubmitJobResult{}, err
+	}
+	if replay, staged, err := repository.stageSubmission(ctx, submission, admit); err != nil || !staged {
+		return replay, err
+	}
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		_ = repository.abandonSubmission(ctx, submission)
+		return SubmitJobResult{}, repositoryUnavailable("begin submit transaction", err)
+	}
+	commitOutcomeUnknown := false
+	committed := false
+	defer func() {
+		if committed || commitOutcomeUnknown {
+			return
+		}
+		// Always release database locks before compensating object storage.
+		_ = tx.Rollback()
+		if err := repository.abandonSubmission(ctx, submission); err != nil {
+			resultErr = err
+		}
+	}()
+	if err := lockSubmissionReservations(ctx, tx, submission.reservationClaims()...); err != nil {
+		return SubmitJobResult{}, repositoryUnavailable("lock encrypted source reservation", err)
+	}
+	admission, err := beginSubmissionAdmission(ctx, tx, tenantExternalID)
+	if err != nil {
+		return SubmitJobResult{}, err
+	}
+	result, err = repository.persistSubmission(ctx, tx, admission, submission)
+	if err != nil || result.Replayed {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		// Commit errors can be outcome-ambiguous (the server may have committed
+		// before the connection failed). Deleting here could break a committed
+		// job. Keep this private, content-opaque object for the retention sweeper;
+		// an idempotent retry determines whether the transaction committed.
+		commitOutcomeUnknown = true
+		return SubmitJobResult{}, repositoryUnavailable("commit submitted job with unknown outcome", err)
+	}
+	committed = true
+	submission.published()
+	return result, nil
+}
+
+// jobSubmission carries one request through admission. Its flags record what
+// the request still owns, so compensation after a failure at any step releases
+// exactly the reservations and object this attempt created.
+type jobSubmission struct {
+	tenantExternalID        string
+	request                 JudgeJobRequest
+	keyDigest               []byte
+	requestHash             []byte
+	coordinationObjectKey   string
+	coordinationToken       []byte
+	sourceExternalID        string
+	sourceObjectKey         string
+	sourceReservationToken  []byte
+	jobExternalID           string
+	encrypted               EncryptedSource
+	coordinationActive      bool
+	sourceReservationActive bool
+	objectMayExist          bool
+}
+
+func (submission *jobSubmission) reservationClaims() []sourceReservationClaim {
+	return []sourceReservationClaim{
+		{objectKey: submission.coordinationObjectKey, ownerToken: submission.coordinationToken},
+		{objectKey: submission.sourceObjectKey, ownerToken: submission.sourceReservationToken},
+	}
+}
+
+// published records that a committed transaction now owns the source object
+// and has consumed both reservations.
+func (submission *jobSubmission) published() {
+	submission.coordinationActive = false
+	submission.sourceReservationActive = false
+	submission.objectMayExist = false
+}
+
+// prepareSubmission derives identities and encrypts the source without any
+// I/O beyond the random source.
+func (repository *MySQLJobRepository) prepareSubmission(
+	tenantExternalID string,
+	idempotencyKey string,
+	request JudgeJobRequest,
+) (*jobSubmission, error) {
 	keyDigest, err := DigestIdempotencyKey(idempotencyKey, repository.idempotencyPepper)
 	if err != nil {
-		return SubmitJobResult{}, fmt.Errorf("%w: invalid idempotency key", ErrExternalJobInvalid)
+		return nil, fmt.Errorf("%w: invalid idempotency key", ErrExternalJobInvalid)
 	}
 	// Canonical identity must remain stable when an operator tightens policy.
 	requestHash, err := CanonicalJobRequestHash(request, int64(len(request.SourceCode)))
 	if err != nil {
-		return SubmitJobResult{}, fmt.Errorf("%w: %v", ErrExternalJobInvalid, err)
+		return nil, fmt.Errorf("%w: %v", ErrExternalJobInvalid, err)
 	}
-	coordinationExternalID := submissionSourceExternalID(tenantExternalID, keyDigest)
-	coordinationObjectKey, err := SourceObjectKey(tenantExternalID, coordinationExternalID)
+	if request.rejudgeHash != nil {
+		requestHash = request.rejudgeHash
+	}
+	submission := &jobSubmission{
+		tenantExternalID: tenantExternalID, request: request,
+		keyDigest: keyDigest, requestHash: requestHash,
+	}
+	submission.coordinationObjectKey, err = SourceObjectKey(tenantExternalID, submissionSourceExternalID(tenantExternalID, keyDigest))
 	if err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("derive submission reservation key", err)
+		return nil, repositoryUnavailable("derive submission reservation key", err)
 	}
-	sourceExternalID, err := generateExternalID(repository.random)
+	submission.sourceExternalID, err = generateExternalID(repository.random)
 	if err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("generate source object ID", err)
+		return nil, repositoryUnavailable("generate source object ID", err)
 	}
-	jobExternalID, err := generateExternalID(repository.random)
+	submission.jobExternalID, err = generateExternalID(repository.random)
 	if err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("generate job ID", err)
+		return nil, repositoryUnavailable("generate job ID", err)
 	}
-	sourceObjectKey, err := SourceObjectKey(tenantExternalID, sourceExternalID)
+	submission.sourceObjectKey, err = SourceObjectKey(tenantExternalID, submission.sourceExternalID)
 	if err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("derive source object key", err)
+		return nil, repositoryUnavailable("derive source object key", err)
 	}
-	encrypted, err := repository.sourceCipher.Encrypt(tenantExternalID, sourceExternalID, request.SourceCode)
+	submission.encrypted, err = repository.sourceCipher.Encrypt(tenantExternalID, submission.sourceExternalID, request.SourceCode)
 	if err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("encrypt source", err)
+		return nil, repositoryUnavailable("encrypt source", err)
 	}
-	coordinationToken := make([]byte, 32)
-	if _, err := io.ReadFull(repository.random, coordinationToken); err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("generate submission reservation token", err)
+	submission.coordinationToken = make([]byte, 32)
+	if _, err := io.ReadFull(repository.random, submission.coordinationToken); err != nil {
+		return nil, repositoryUnavailable("generate submission reservation token", err)
 	}
-	sourceReservationToken := make([]byte, 32)
-	if _, err := io.ReadFull(repository.random, sourceReservationToken); err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("generate source reservation token", err)
+	submission.sourceReservationToken = make([]byte, 32)
+	if _, err := io.ReadFull(repository.random, submission.sourceReservationToken); err != nil {
+		return nil, repositoryUnavailable("generate source reservation token", err)
 	}
-	if replay, found, err := repository.findSubmitReplay(ctx, tenantExternalID, keyDigest, requestHash); err != nil {
-		return SubmitJobResult{}, err
+	return submission, nil
+}
+
+// stageSubmission resolves replays, charges admission and persists the
+// encrypted source object, all outside any database transaction. It reports
+// false with the replayed job when the Idempotency-Key was already accepted.
+func (repository *MySQLJobRepository) stageSubmission(
+	ctx context.Context,
+	submission *jobSubmission,
+	admit func(context.Context) error,
+) (SubmitJobResult, bool, error) {
+	tenantExternalID := submission.tenantExternalID
+	if replay, found, err := repository.findSubmitReplay(ctx, tenantExternalID, submission.keyDigest, submission.requestHash); err != nil {
+		return SubmitJobResult{}, false, err
 	} else if found {
-		return replay, nil
+		return replay, false, nil
 	}
-	if err := repository.preflightSubmit(ctx, tenantExternalID, request); err != nil {
-		return SubmitJobResult{}, err
+	if err := repository.preflightSubmit(ctx, tenantExternalID, submission.request); err != nil {
+		return SubmitJobResult{}, false, err
 	}
 	coordinationReplay, coordinationAcquired, err := repository.acquireSubmissionCoordination(
-		ctx, tenantExternalID, keyDigest, requestHash, coordinationObjectKey, coordinationToken,
+		ctx, tenantExternalID, submission.keyDigest, submission.requestHash,
+		submission.coordinationObjectKey, submission.coordinationToken,
 	)
 	if err != nil {
-		return SubmitJobResult{}, err
+		return SubmitJobResult{}, false, err
 	}
 	if !coordinationAcquired {
-		return coordinationReplay, nil
-	}
-	coordinationActive := true
-	sourceReservationActive := false
-	releaseAllReservations := func() {
-		// A timed-out MySQL statement can keep its server-side transaction and
-		// row locks alive until MySQL notices the closed connection. Waiting on
-		// those same rows here would extend an application deadline by the
-		// server lock timeout. The fenced reservation sweeper is the recovery
-		// path once the request context has expired.
-		if ctx.Err() != nil || (!sourceReservationActive && !coordinationActive) {
-			return
-		}
-		cleanupContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		var deleted sql.Result
-		var deleteErr error
-		switch {
-		case sourceReservationActive && coordinationActive:
-			deleted, deleteErr = repository.database.ExecContext(cleanupContext, `
-DELETE FROM t_external_source_reservation
-WHERE (object_key = ? AND owner_token = ?) OR (object_key = ? AND owner_token = ?)`,
-				sourceObjectKey, sourceReservationToken, coordinationObjectKey, coordinationToken)
-		case sourceReservationActive:
-			deleted, deleteErr = repository.database.ExecContext(cleanupContext, `
-DELETE FROM t_external_source_reservation
-WHERE object_key = ? AND owner_token = ?`, sourceObjectKey, sourceReservationToken)
-		default:
-			deleted, deleteErr = repository.database.ExecContext(cleanupContext, `
-DELETE FROM t_external_source_reservation
-WHERE object_key = ? AND owner_token = ?`, coordinationObjectKey, coordinationToken)
-		}
-		if deleteErr != nil {
-			return
-		}
-		affected, rowsErr := deleted.RowsAffected()
-		if rowsErr != nil {
-			return
-		}
-		if sourceReservationActive && coordinationActive && affected == 2 {
-			sourceReservationActive = false
-			coordinationActive = false
-		} else if sourceReservationActive && !coordinationActive && affected == 1 {
-			sourceReservationActive = false
-		} else if !sourceReservationActive && coordinationActive && affected == 1 {
-			coordinationActive = false
-		}
+		return coordinationReplay, false, nil
 	}
+	submission.coordinationActive = true
 	// A predecessor can commit between the lock-free replay lookup and this
 	// reservation claim. Recheck before charging quota or touching MinIO.
-	if replay, found, err := repository.findSubmitReplay(ctx, tenantExternalID, keyDigest, requestHash); err != nil {
-		releaseAllReservations()
-		return SubmitJobResult{}, err
+	if replay, found, err := repository.findSubmitReplay(ctx, tenantExternalID, submission.keyDigest, submission.requestHash); err != nil {
+		repository.releaseSubmissionReservations(ctx, submission)
+		return SubmitJobResult{}, false, err
 	} else if found {
-		releaseAllReservations()
-		return replay, nil
+		repository.releaseSubmissionReservations(ctx, submission)
+		return replay, false, nil
 	}
 	if err := admit(ctx); err != nil {
-		releaseAllReservations()
-		return SubmitJobResult{}, err
+		repository.releaseSubmissionReservations(ctx, submission)
+		return SubmitJobResult{}, false, err
 	}
-	if err := repository.acquireSourceReservation(ctx, sourceObjectKey, sourceReservationToken); err != nil {
-		releaseAllReservations()
-		return SubmitJobResult{}, err
+	if err := repository.acquireSourceReservation(ctx, submission.sourceObjectKey, submission.sourceReservationToken); err != nil {
+		repository.releaseSubmissionReservations(ctx, submission)
+		return SubmitJobResult{}, false, err
 	}
-	sourceReservationActive = true
-	objectMayExist := true
+	submission.sourceReservationActive = true
+	submission.objectMayExist = true
 	createContext, cancelCreate := context.WithTimeout(ctx, repository.sourceObjectOperationTimeout)
-	createErr := repository.sourceObjects.Create(createContext, sourceObjectKey, encrypted.Ciphertext)
+	createErr := repository.sourceObjects.Create(createContext, submission.sourceObjectKey, submission.encrypted.Ciphertext)
 	cancelCreate()
 	if createErr != nil {
 		if errors.Is(createErr, ErrSourceObjectExists) {
-			if replay, found, replayErr := repository.findSubmitReplay(ctx, tenantExternalID, keyDigest, requestHash); replayErr == nil && found {
-				releaseAllReservations()
-				return replay, nil
+			if replay, found, replayErr := repository.findSubmitReplay(ctx, tenantExternalID, submission.keyDigest, submission.requestHash); replayErr == nil && found {
+				repository.releaseSubmissionReservations(ctx, submission)
+				return replay, false, nil
 			}
 		}
 		// Any other object-store failure can be outcome-ambiguous. Retain the
 		// fenced reservation for the reconciler rather than risking deletion of
 		// bytes that MinIO may have committed.
-		return SubmitJobResult{}, fmt.Errorf("%w: persist encrypted source", ErrExternalJobUnavailable)
+		return SubmitJobResult{}, false, fmt.Errorf("%w: persist encrypted source", ErrExternalJobUnavailable)
 	}
-	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		cleanupContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if cleanupErr := repository.sourceObjects.Delete(cleanupContext, sourceObjectKey); cleanupErr == nil {
-			objectMayExist = false
-			releaseAllReservations()
-		}
-		return SubmitJobResult{}, repositoryUnavailable("begin submit transaction", err)
+	return SubmitJobResult{}, true, nil
+}
+
+// releaseSubmissionReservations deletes the reservations a submission still
+// owns. Failures leave them to the fenced reservation sweeper.
+func (repository *MySQLJobRepository) releaseSubmissionReservations(ctx context.Context, submission *jobSubmission) {
+	// A timed-out MySQL statement can keep its server-side transaction and
+	// row locks alive until MySQL notices the closed connection. Waiting on
+	// those same rows here would extend an application deadline by the
+	// server lock timeout. The fenced reservation sweeper is the recovery
+	// path once the request context has expired.
+	if ctx.Err() != nil || (!submission.sourceReservationActive && !submission.coordinationActive) {
+		return
+	}
+	cleanupContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var deleted sql.Result
+	var deleteErr error
+	switch {
+	case submission.sourceReservationActive && submission.coordinationActive:
+		deleted, deleteErr = repository.database.ExecContext(cleanupContext, `
+DELETE FROM t_external_source_reservation
+WHERE (object_key = ? AND owner_token = ?) OR (object_key = ? AND owner_token = ?)`,
+			submission.sourceObjectKey, submission.sourceReservationToken,
+			submission.coordinationObjectKey, submission.coordinationToken)
+	case submission.sourceReservationActive:
+		deleted, deleteErr = repository.database.ExecContext(cleanupContext, `
+DELETE FROM t_external_source_reservation
+WHERE object_key = ? AND owner_token = ?`, submission.sourceObjectKey, submission.sourceReservationToken)
+	default:
+		deleted, deleteErr = repository.database.ExecContext(cleanupContext, `
+DELETE FROM t_external_source_reservation
+WHERE object_key = ? AND owner_token = ?`, submission.coordinationObjectKey, submission.coordinationToken)
 	}
-	commitOutcomeUnknown := false
-	committed := false
-	defer func() {
-		if committed || commitOutcomeUnknown {
-			return
-		}
-		// Always release database locks before compensating object storage.
-		_ = tx.Rollback()
-		if !objectMayExist {
-			releaseAllReservations()
-			return
-		}
+	if deleteErr != nil {
+		return
+	}
+	affected, rowsErr := deleted.RowsAffected()
+	if rowsErr != nil {
+		return
+	}
+	if submission.sourceReservationActive && submission.coordinationActive && affected == 2 {
+		submission.sourceReservationActive = false
+		submission.coordinationActive = false
+	} else if submission.sourceReservationActive && !submission.coordinationActive && affected == 1 {
+		submission.sourceReservationActive = false
+	} else if !submission.sourceReservationActive && submission.coordinationActive && affected == 1 {
+		submission.coordinationActive = false
+	}
+}
+
+// abandonSubmission deletes the source object of a submission that no
+// committed job references, then releases its reservations. The caller must
+// have rolled back or committed the transaction that locked them.
+func (repository *MySQLJobRepository) abandonSubmission(ctx context.Context, submission *jobSubmission) error {
+	if submission.objectMayExist {
 		cleanupContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		if cleanupErr := repository.sourceObjects.Delete(cleanupContext, sourceObjectKey); cleanupErr != nil {
-			resultErr = fmt.Errorf("%w: source compensation failed", ErrExternalJobUnavailable)
-			return
+		if err := repository.sourceObjects.Delete(cleanupContext, submission.sourceObjectKey); err != nil {
+			return fmt.Errorf("%w: source compensation failed", ErrExternalJobUnavailable)
 		}
-		objectMayExist = false
-		releaseAllReservations()
-	}()
-	if err := lockSubmissionReservations(ctx, tx,
-		sourceReservationClaim{objectKey: coordinationObjectKey, ownerToken: coordinationToken},
-		sourceReservationClaim{objectKey: sourceObjectKey, ownerToken: sourceReservationToken},
-	); err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("lock encrypted source reservation", err)
+		submission.objectMayExist = false
 	}
+	repository.releaseSubmissionReservations(ctx, submission)
+	return nil
+}
+
+// submissionAdmission is the tenant state an admission transaction holds
+// locked. queuedJobs is counted on first use and then tracks the jobs the
+// transaction itself queues.
+type submissionAdmission struct {
+	tenantInternalID uint64
+	policy           TenantPolicy
+	now              time.Time
+	queuedJobs       int
+	queuedCounted    bool
+}
 
+func beginSubmissionAdmission(ctx context.Context, tx *sql.Tx, tenantExternalID string) (*submissionAdmission, error) {
 	tenantInternalID, policy, err := lockTenantPolicy(ctx, tx, tenantExternalID)
 	if err != nil {
-		return SubmitJobResult{}, err
+		return nil, err
 	}
-	// Current admission limits are applied only after an idempotent replay has
-	// had a chance to return its already-accepted resource.
 	now, err := mysqlCurrentTime(ctx, tx)
 	if err != nil {
-		return SubmitJobResult{}, err
+		return nil, err
 	}
+	return &submissionAdmission{tenantInternalID: tenantInternalID, policy: policy, now: now}, nil
+}
+
+// persistSubmission runs the authoritative admission checks for one staged
+// submission and inserts its job. Replays, conflicts, policy rejections and
+// quota exhaustion are decided before the first insert, so a caller may keep
+// the transaction after any of them; every other error leaves partial writes.
+func (repository *MySQLJobRepository) persistSubmission(
+	ctx context.Context,
+	tx *sql.Tx,
+	admission *submissionAdmission,
+	submission *jobSubmission,
+) (SubmitJobResult, error) {
+	tenantExternalID, tenantInternalID := submission.tenantExternalID, admission.tenantInternalID
+	request, now := submission.request, admission.now
+	// Current admission limits are applied only after an idempotent replay has
+	// had a chance to return its already-accepted resource.
 	if _, err := tx.ExecContext(ctx, `
 DELETE FROM t_external_idempotency
 WHERE tenant_id = ? AND operation_scope = ? AND key_digest = ? AND expires_at <= ?`,
-		tenantInternalID, submitJobIdempotencyScope, keyDigest, now); err != nil {
+		tenantInternalID, submitJobIdempotencyScope, submission.keyDigest, now); err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("expire idempotency record", err)
 	}
 	var storedHash []byte
 	var existingJobID string
-	err = tx.QueryRowContext(ctx, `
+	err := tx.QueryRowContext(ctx, `
 SELECT request_hash, resource_external_id
 FROM t_external_idempotency
 WHERE tenant_id = ? AND operation_scope = ? AND key_digest = ?`,
-		tenantInternalID, submitJobIdempotencyScope, keyDigest).Scan(&storedHash, &existingJobID)
+		tenantInternalID, submitJobIdempotencyScope, submission.keyDigest).Scan(&storedHash, &existingJobID)
 	if err == nil {
-		if !bytes.Equal(storedHash, requestHash) {
+		if !bytes.Equal(storedHash, submission.requestHash) {
 			return SubmitJobResult{}, ErrExternalJobConflict
 		}
 		job, err := getExternalJob(ctx, tx, tenantExternalID, existingJobID, false)
@@ -315,31 +445,40 @@ WHERE tenant_id = ? AND operation_scope = ? AND key_digest = ?`,
 	if !errors.Is(err, sql.ErrNoRows) {
 		return SubmitJobResult{}, repositoryUnavailable("read idempotency record", err)
 	}
-	if int64(len(request.SourceCode)) > policy.MaxSourceBytes {
+	if int64(len(request.SourceCode)) > admission.policy.MaxSourceBytes {
 		return SubmitJobResult{}, fmt.Errorf("%w: source code exceeds current tenant policy", ErrExternalJobInvalid)
 	}
+	if request.Priority > admission.policy.MaxJobPriority {
+		return SubmitJobResult{}, fmt.Errorf("%w: priority exceeds current tenant policy", ErrExternalJobInvalid)
+	}
 
-	var queuedJobs int
-	if err := tx.QueryRowContext(ctx,
-		"SELECT COUNT(*) FROM t_external_job WHERE tenant_id = ? AND status = 'QUEUED'",
-		tenantInternalID).Scan(&queuedJobs); err != nil {
-		return SubmitJobResult{}, repositoryUnavailable("establish queued quota", err)
+	if !admission.queuedCounted {
+		if err := tx.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM t_external_job WHERE tenant_id = ? AND status = 'QUEUED'",
+			tenantInternalID).Scan(&admission.queuedJobs); err != nil {
+			return SubmitJobResult{}, repositoryUnavailable("establish queued quota", err)
+		}
+		admission.queuedCounted = true
 	}
-	if queuedJobs >= policy.MaxQueuedJobs {
+	if admission.queuedJobs >= admission.policy.MaxQueuedJobs {
 		return SubmitJobResult{}, ErrQueuedQuotaExceeded
 	}
 
 	var bundleInternalID uint64
+	var bundleManifestJSON []byte
 	if err := tx.QueryRowContext(ctx, `
-SELECT id FROM t_external_bundle
+SELECT id, manifest_json FROM t_external_bundle
 WHERE tenant_id = ? AND external_id = ? AND publication_status = 'READY' AND ready_at IS NOT NULL
   AND delete_marked_at IS NULL AND deleted_at IS NULL`,
-		tenantInternalID, request.BundleID).Scan(&bundleInternalID); err != nil {
+		tenantInternalID, request.BundleID).Scan(&bundleInternalID, &bundleManifestJSON); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return SubmitJobResult{}, fmt.Errorf("%w: bundle is unavailable", ErrExternalJobInvalid)
 		}
 		return SubmitJobResult{}, repositoryUnavailable("read tenant bundle", err)
 	}
+	if err := validateBundleLanguage(bundleManifestJSON, request.Language); err != nil {
+		return SubmitJobResult{}, err
+	}
 	var callbackInternalID sql.NullInt64
 	if request.CallbackID != "" {
 		var callbackID int64
@@ -357,12 +496,13 @@ FOR SHARE`,
 		}
 		callbackInternalID = sql.NullInt64{Int64: callbackID, Valid: true}
 	}
+	encrypted := submission.encrypted
 	sourceResult, err := tx.ExecContext(ctx, `
 INSERT INTO t_external_source_object(
     external_id, tenant_id, object_key, source_sha256, source_size_bytes,
     encryption_key_version, encryption_nonce
 ) VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		sourceExternalID, tenantInternalID, sourceObjectKey, encrypted.SHA256, encrypted.SizeBytes,
+		submission.sourceExternalID, tenantInternalID, submission.sourceObjectKey, encrypted.SHA256, encrypted.SizeBytes,
 		encrypted.KeyVersion, encrypted.Nonce)
 	if err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("persist source metadata", err)
@@ -374,17 +514,19 @@ INSERT INTO t_external_source_object(
 	if _, err := tx.ExecContext(ctx, `
 INSERT INTO t_external_job(
     external_id, tenant_id, bundle_id, source_object_id, callback_id, status,
-    language_id, stop_on_failure, client_reference, request_hash, next_attempt_at, created_at
-) VALUES (?, ?, ?, ?, ?, 'QUEUED', ?, ?, NULLIF(?, ''), ?, ?, ?)`,
-		jobExternalID, tenantInternalID, bundleInternalID, sourceInternalID, callbackInternalID,
-		request.Language, request.StopOnFailure, request.ClientReference, requestHash, now, now); err != nil {
+    language_id, stop_on_failure, priority, client_reference, rejudge_of_external_id,
+    request_hash, trace_parent, next_attempt_at, created_at
+) VALUES (?, ?, ?, ?, ?, 'QUEUED', ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, NULLIF(?, ''), ?, ?)`,
+		submission.jobExternalID, tenantInternalID, bundleInternalID, sourceInternalID, callbackInternalID,
+		request.Language, request.StopOnFailure, request.Priority, request.ClientReference, request.RejudgeOf, submission.requestHash,
+		tracing.TraceParent(ctx), now, now); err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("persist queued job", err)
 	}
 	responseJSON, err := json.Marshal(struct {
 		JobID     string    `json:"jobId"`
 		Status    JobStatus `json:"status"`
 		CreatedAt time.Time `json:"createdAt"`
-	}{JobID: jobExternalID, Status: JobStatusQueued, CreatedAt: now})
+	}{JobID: submission.jobExternalID, Status: JobStatusQueued, CreatedAt: now})
 	if err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("encode idempotency response", err)
 	}
@@ -393,36 +535,29 @@ INSERT INTO t_external_idempotency(
     tenant_id, operation_scope, key_digest, request_hash, resource_type,
     resource_external_id, response_status, response_json, expires_at
 ) VALUES (?, ?, ?, ?, 'judge-job', ?, 202, ?, ?)`,
-		tenantInternalID, submitJobIdempotencyScope, keyDigest, requestHash,
-		jobExternalID, responseJSON, now.Add(repository.idempotencyTTL)); err != nil {
+		tenantInternalID, submitJobIdempotencyScope, submission.keyDigest, submission.requestHash,
+		submission.jobExternalID, responseJSON, now.Add(repository.idempotencyTTL)); err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("persist idempotency record", err)
 	}
 	deletedReservations, err := tx.ExecContext(ctx, `
 DELETE FROM t_external_source_reservation
 WHERE (object_key = ? AND owner_token = ?) OR (object_key = ? AND owner_token = ?)`,
-		coordinationObjectKey, coordinationToken, sourceObjectKey, sourceReservationToken)
+		submission.coordinationObjectKey, submission.coordinationToken,
+		submission.sourceObjectKey, submission.sourceReservationToken)
 	if err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("publish source reservation", err)
 	}
 	if affected, rowsErr := deletedReservations.RowsAffected(); rowsErr != nil || affected != 2 {
 		return SubmitJobResult{}, repositoryUnavailable("confirm published source reservations", errors.Join(rowsErr, fmt.Errorf("affected reservations: %d", affected)))
 	}
-	job, err := getExternalJob(ctx, tx, tenantExternalID, jobExternalID, false)
+	job, err := getExternalJob(ctx, tx, tenantExternalID, submission.jobExternalID, false)
 	if err != nil {
 		return SubmitJobResult{}, repositoryUnavailable("read submitted job", err)
 	}
-	if err := tx.Commit(); err != nil {
-		// Commit errors can be outcome-ambiguous (the server may have committed
-		// before the connection failed). Deleting here could break a committed
-		// job. Keep this private, content-opaque object for the retention sweeper;
-		// an idempotent retry determines whether the transaction committed.
-		commitOutcomeUnknown = true
-		return SubmitJobResult{}, repositoryUnavailable("commit submitted job with unknown outcome", err)
+	if err := appendJobStatusEvent(ctx, tx, now, job); err != nil {
+		return SubmitJobResult{}, err
 	}
-	committed = true
-	coordinationActive = false
-	sourceReservationActive = false
-	objectMayExist = false
+	admission.queuedJobs++
 	return SubmitJobResult{Job: job}, nil
 }
 
@@ -432,11 +567,14 @@ func submissionOperationContext(parent context.Context, timeout time.Duration) (
 
 const sourceReservationAdmissionLease = 25 * time.Minute
 
-func lockSubmissionReservations(ctx context.Context, tx *sql.Tx, first, second sourceReservationClaim) error {
-	if second.objectKey < first.objectKey {
-		first, second = second, first
-	}
-	for _, claim := range []sourceReservationClaim{first, second} {
+// lockSubmissionReservations locks reservations in object-key order, so
+// concurrent single and batch submissions cannot deadlock on each other.
+func lockSubmissionReservations(ctx context.Context, tx *sql.Tx, claims ...sourceReservationClaim) error {
+	claims = slices.Clone(claims)
+	slices.SortFunc(claims, func(left, right sourceReservationClaim) int {
+		return strings.Compare(left.objectKey, right.objectKey)
+	})
+	for _, claim := range claims {
 		var locked string
 		if err := tx.QueryRowContext(ctx, `
 SELECT object_key FROM t_external_source_reservation
@@ -507,6 +645,9 @@ WHERE external_id = ? AND status = 'ACTIVE'`, tenantExternalID).Scan(&tenantInte
 	if int64(len(request.SourceCode)) > policy.MaxSourceBytes {
 		return fmt.Errorf("%w: source code exceeds current tenant policy", ErrExternalJobInvalid)
 	}
+	if request.Priority > policy.MaxJobPriority {
+		return fmt.Errorf("%w: priority exceeds current tenant policy", ErrExternalJobInvalid)
+	}
 	var queuedJobs int
 	if err := repository.database.QueryRowContext(ctx,
 		"SELECT COUNT(*) FROM t_external_job WHERE tenant_id = ? AND status = 'QUEUED'", tenantInternalID).Scan(&queuedJobs); err != nil {
@@ -515,15 +656,18 @@ WHERE external_id = ? AND status = 'ACTIVE'`, tenantExternalID).Scan(&tenantInte
 	if queuedJobs >= policy.MaxQueuedJobs {
 		return ErrQueuedQuotaExceeded
 	}
-	var bundleExists int
+	var manifestJSON []byte
 	if err := repository.database.QueryRowContext(ctx, `
-SELECT EXISTS(SELECT 1 FROM t_external_bundle
+SELECT manifest_json FROM t_external_bundle
 WHERE tenant_id = ? AND external_id = ? AND publication_status = 'READY' AND ready_at IS NOT NULL
-  AND delete_marked_at IS NULL AND deleted_at IS NULL)`, tenantInternalID, request.BundleID).Scan(&bundleExists); err != nil {
+  AND delete_marked_at IS NULL AND deleted_at IS NULL`, tenantInternalID, request.BundleID).Scan(&manifestJSON); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: bundle is unavailable", ErrExternalJobInvalid)
+		}
 		return repositoryUnavailable("preflight tenant bundle", err)
 	}
-	if bundleExists != 1 {
-		return fmt.Errorf("%w: bundle is unavailable", ErrExternalJobInvalid)
+	if err := validateBundleLanguage(manifestJSON, request.Language); err != nil {
+		return err
 	}
 	if request.CallbackID != "" {
 		var callbackExists int
@@ -541,6 +685,19 @@ WHERE tenant_id = ? AND external_id = ? AND disabled_at IS NULL
 	return nil
 }
 
+// validateBundleLanguage rejects submissions a grader bundle cannot build. The
+// manifest is immutable, so the check is stable across idempotent replays.
+func validateBundleLanguage(manifestJSON []byte, language string) error {
+	manifest, err := bundle.ParseManifest(manifestJSON)
+	if err != nil {
+		return fmt.Errorf("%w: stored bundle manifest is invalid", ErrExternalJobUnavailable)
+	}
+	if !manifest.SupportsLanguage(language) {
+		return fmt.Errorf("%w: bundle has no grader for the submission language", ErrExternalJobInvalid)
+	}
+	return nil
+}
+
 func (repository *MySQLJobRepository) acquireSourceReservation(ctx context.Context, objectKey string, ownerToken []byte) error {
 	for {
 		acquired, err := repository.tryAcquireSourceReservation(ctx, objectKey, ownerToken, sourceReservationAdmissionLease)
@@ -677,10 +834,12 @@ WHERE external_id = ? FOR UPDATE`, tenantExternalID).Scan(&tenantInternalID, &st
 	return tenantInternalID, policy, nil
 }
 
-func (repository *MySQLJobRepository) Get(ctx context.Context, tenantExternalID, jobExternalID string) (ExternalJobRecord, error) {
+func (repository *MySQLJobRepository) Get(ctx context.Context, tenantExternalID, jobExternalID string) (_ ExternalJobRecord, err error) {
 	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) || !externalIDPattern.MatchString(jobExternalID) {
 		return ExternalJobRecord{}, ErrExternalJobNotFound
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.Get", attribute.String("croj.tenant", tenantExternalID), attribute.String("croj.job", jobExternalID))
+	defer func() { endSpan(span, err) }()
 	job, err := getExternalJob(ctx, repository.database, tenantExternalID, jobExternalID, false)
 	if errors.Is(err, sql.ErrNoRows) {
 		return ExternalJobRecord{}, ErrExternalJobNotFound
@@ -691,18 +850,42 @@ func (repository *MySQLJobRepository) Get(ctx context.Context, tenantExternalID,
 	return job, nil
 }
 
-func (repository *MySQLJobRepository) List(ctx context.Context, tenantExternalID string, options JobListOptions) (JobListResult, error) {
-	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) || options.Limit < 1 || options.Limit > 100 || !validJobStatusFilter(options.Status) {
+func (repository *MySQLJobRepository) List(ctx context.Context, tenantExternalID string, options JobListOptions) (_ JobListResult, err error) {
+	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) || options.Limit < 1 || options.Limit > 100 || !validJobListFilter(options) {
 		return JobListResult{}, ErrExternalJobInvalid
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.List", attribute.String("croj.tenant", tenantExternalID))
+	defer func() { endSpan(span, err) }()
 	arguments := []any{tenantExternalID}
 	conditions := []string{"tenant.external_id = ?"}
 	if options.Status != "" {
 		conditions = append(conditions, "job.status = ?")
 		arguments = append(arguments, options.Status)
 	}
+	if options.BundleID != "" {
+		conditions = append(conditions, "bundle.external_id = ?")
+		arguments = append(arguments, options.BundleID)
+	}
+	if options.Language != "" {
+		conditions = append(conditions, "job.language_id = ?")
+		arguments = append(arguments, options.Language)
+	}
+	if options.ClientReference != "" {
+		// The column collation is case- and accent-insensitive; the binary
+		// comparison keeps the match exact while the index narrows the scan.
+		conditions = append(conditions, "job.client_reference = ? AND job.client_reference COLLATE utf8mb4_bin = ?")
+		arguments = append(arguments, options.ClientReference, options.ClientReference)
+	}
+	if !options.CreatedAfter.IsZero() {
+		conditions = append(conditions, "job.created_at >= ?")
+		arguments = append(arguments, options.CreatedAfter.UTC())
+	}
+	if !options.CreatedBefore.IsZero() {
+		conditions = append(conditions, "job.created_at < ?")
+		arguments = append(arguments, options.CreatedBefore.UTC())
+	}
 	if options.Cursor != "" {
-		cursor, err := repository.cursor.Decode(options.Cursor, tenantExternalID, options.Status)
+		cursor, err := repository.cursor.Decode(options.Cursor, tenantExternalID, options)
 		if err != nil {
 			return JobListResult{}, ErrInvalidJobCursor
 		}
@@ -734,6 +917,8 @@ func (repository *MySQLJobRepository) List(ctx context.Context, tenantExternalID
 		result.Jobs = jobs[:options.Limit]
 		result.NextCursor, err = repository.cursor.Encode(JobCursor{
 			TenantID: tenantExternalID, Status: options.Status,
+			BundleID: options.BundleID, Language: options.Language, ClientReference: options.ClientReference,
+			CreatedAfter: options.CreatedAfter, CreatedBefore: options.CreatedBefore,
 			CreatedAt: last.CreatedAt, InternalID: last.InternalID,
 		})
 		if err != nil {
@@ -743,10 +928,43 @@ func (repository *MySQLJobRepository) List(ctx context.Context, tenantExternalID
 	return result, nil
 }
 
-func (repository *MySQLJobRepository) Cancel(ctx context.Context, tenantExternalID, jobExternalID string) (ExternalJobRecord, error) {
+// QueueDepth counts queued and running jobs per tenant for metrics scrapes.
+// The status predicate is the leading column of idx_external_job_claim, so
+// terminal history is never scanned.
+func (repository *MySQLJobRepository) QueueDepth(ctx context.Context) ([]TenantJobDepth, error) {
+	if repository == nil {
+		return nil, ErrExternalJobUnavailable
+	}
+	rows, err := repository.database.QueryContext(ctx, `
+SELECT tenant.external_id, job.status, COUNT(*)
+FROM t_external_job AS job
+JOIN t_external_tenant AS tenant ON tenant.id = job.tenant_id
+WHERE job.status IN ('QUEUED', 'RUNNING')
+GROUP BY tenant.external_id, job.status`)
+	if err != nil {
+		return nil, repositoryUnavailable("count queued jobs", err)
+	}
+	defer rows.Close()
+	var depths []TenantJobDepth
+	for rows.Next() {
+		var depth TenantJobDepth
+		if err := rows.Scan(&depth.TenantExternalID, &depth.Status, &depth.Jobs); err != nil {
+			return nil, repositoryUnavailable("scan queue depth", err)
+		}
+		depths = append(depths, depth)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repositoryUnavailable("iterate queue depth", err)
+	}
+	return depths, nil
+}
+
+func (repository *MySQLJobRepository) Cancel(ctx context.Context, tenantExternalID, jobExternalID string) (_ ExternalJobRecord, err error) {
 	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) || !externalIDPattern.MatchString(jobExternalID) {
 		return ExternalJobRecord{}, ErrExternalJobNotFound
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.Cancel", attribute.String("croj.tenant", tenantExternalID), attribute.String("croj.job", jobExternalID))
+	defer func() { endSpan(span, err) }()
 	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
 	if err != nil {
 		return ExternalJobRecord{}, repositoryUnavailable("begin cancellation", err)
@@ -798,6 +1016,9 @@ WHERE id = ? AND status = 'QUEUED'`, now, now, job.InternalID)
 		return ExternalJobRecord{}, repositoryUnavailable("read cancelled job", err)
 	}
 	if emitTerminalEvent {
+		if err := appendJobStatusEvent(ctx, tx, now, job); err != nil {
+			return ExternalJobRecord{}, err
+		}
 		if _, err := repository.insertTerminalWebhookEvent(ctx, tx, now, job); err != nil {
 			return ExternalJobRecord{}, err
 		}
@@ -818,10 +1039,10 @@ const externalJobSelect = `
 SELECT job.id, job.tenant_id, job.external_id, tenant.external_id, bundle.external_id,
        source.id, source.external_id, source.object_key, source.source_sha256,
        source.source_size_bytes, source.encryption_key_version, source.encryption_nonce,
-       callback.external_id, job.status, job.language_id, job.stop_on_failure,
-       job.client_reference, job.attempt_no, job.worker_id, job.lease_until,
+       callback.external_id, job.status, job.language_id, job.stop_on_failure, job.priority,
+       job.client_reference, job.rejudge_of_external_id, job.attempt_no, job.worker_id, job.lease_until,
        job.cancel_requested_at, job.result_json, job.failure_code,
-       job.created_at, job.started_at, job.completed_at
+       job.created_at, job.started_at, job.completed_at, job.trace_parent
 FROM t_external_job AS job
 JOIN t_external_tenant AS tenant ON tenant.id = job.tenant_id
 JOIN t_external_bundle AS bundle ON bundle.id = job.bundle_id AND bundle.tenant_id = job.tenant_id
@@ -838,7 +1059,7 @@ func getExternalJob(ctx context.Context, queryer queryerSQL, tenantExternalID, j
 
 func scanExternalJob(scanner rowScannerSQL) (ExternalJobRecord, error) {
 	var job ExternalJobRecord
-	var callbackID, clientReference, workerID, failureCode sql.NullString
+	var callbackID, clientReference, rejudgeOf, workerID, failureCode, traceParent sql.NullString
 	var leaseUntil, cancelRequested, startedAt, completedAt sql.NullTime
 	var resultJSON []byte
 	var keyVersion uint64
@@ -846,10 +1067,10 @@ func scanExternalJob(scanner rowScannerSQL) (ExternalJobRecord, error) {
 		&job.InternalID, &job.TenantInternalID, &job.ExternalID, &job.TenantExternalID, &job.BundleExternalID,
 		&job.Source.InternalID, &job.Source.ExternalID, &job.Source.ObjectKey, &job.Source.SHA256,
 		&job.Source.SizeBytes, &keyVersion, &job.Source.Nonce,
-		&callbackID, &job.Status, &job.Language, &job.StopOnFailure,
-		&clientReference, &job.AttemptNo, &workerID, &leaseUntil,
+		&callbackID, &job.Status, &job.Language, &job.StopOnFailure, &job.Priority,
+		&clientReference, &rejudgeOf, &job.AttemptNo, &workerID, &leaseUntil,
 		&cancelRequested, &resultJSON, &failureCode,
-		&job.CreatedAt, &startedAt, &completedAt,
+		&job.CreatedAt, &startedAt, &completedAt, &traceParent,
 	); err != nil {
 		return ExternalJobRecord{}, err
 	}
@@ -859,8 +1080,10 @@ func scanExternalJob(scanner rowScannerSQL) (ExternalJobRecord, error) {
 	job.Source.KeyVersion = uint16(keyVersion)
 	job.CallbackID = callbackID.String
 	job.ClientReference = clientReference.String
+	job.RejudgeOf = rejudgeOf.String
 	job.WorkerID = workerID.String
 	job.FailureCode = failureCode.String
+	job.TraceParent = traceParent.String
 	job.LeaseUntil = nullableTimePointer(leaseUntil)
 	job.CancelRequested = nullableTimePointer(cancelRequested)
 	job.StartedAt = nullableTimePointer(startedAt)
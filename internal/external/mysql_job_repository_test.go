@@ -212,6 +212,40 @@ func TestMySQLJobRepositoryRejectsPendingBundleOwnership(t *testing.T) {
 	}
 }
 
+func TestMySQLJobRepositoryRejectsLanguageWithoutBundleGrader(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("g", 26)
+	bundleID := strings.Repeat("h", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 2)
+	if _, err := database.Exec(`
+UPDATE t_external_bundle SET manifest_version = 2, manifest_json = JSON_OBJECT(
+    'schemaVersion', 2, 'judgeMode', 'ACM', 'checker', 'exact',
+    'limits', JSON_OBJECT('timeLimitMillis', 1000, 'memoryLimitMiB', 256),
+    'graders', JSON_ARRAY(JSON_OBJECT('language', 'cpp', 'sources', JSON_ARRAY(JSON_OBJECT(
+        'name', 'grader.cpp', 'source', 'grader/grader.cpp', 'sourceSha256', REPEAT('a', 64))))),
+    'cases', JSON_ARRAY(JSON_OBJECT('id', 'case-1', 'input', '1.in', 'output', '1.out', 'weight', 1)))
+WHERE external_id = ?`, bundleID); err != nil {
+		t.Fatal(err)
+	}
+	store := newMemorySourceStore()
+	repository := newTestMySQLJobRepository(t, database, store)
+	_, err := repository.Submit(context.Background(), tenantID, "missing-grader-key", JudgeJobRequest{
+		BundleID: bundleID, Language: "python", SourceCode: []byte("def solve(): pass"),
+	})
+	if !errors.Is(err, ErrExternalJobInvalid) {
+		t.Fatalf("missing grader submit error = %v", err)
+	}
+	if _, puts, _ := store.snapshot(); puts != 0 {
+		t.Fatalf("missing grader submit wrote %d source objects", puts)
+	}
+	if _, err := repository.Submit(context.Background(), tenantID, "declared-grader-key", JudgeJobRequest{
+		BundleID: bundleID, Language: "cpp", SourceCode: []byte("int solve() { return 0; }"),
+	}); err != nil {
+		t.Fatalf("declared grader submit error = %v", err)
+	}
+}
+
 func TestMySQLJobRepositoryConcurrentReplayCreatesOneJobAndOneObject(t *testing.T) {
 	database := openMySQLIntegration(t)
 	prepareExternalJobDatabase(t, database)
@@ -754,6 +788,128 @@ func TestMySQLJobRepositoryListUsesStableTenantBoundCursor(t *testing.T) {
 	}
 }
 
+func TestMySQLJobRepositoryListFiltersByReferenceBundleLanguageAndTime(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("p", 26)
+	bundleA := strings.Repeat("q", 26)
+	bundleB := strings.Repeat("r", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleA, "", 10)
+	if _, err := database.Exec(`
+INSERT INTO t_external_bundle(external_id, tenant_id, sha256, object_key, size_bytes, case_count, manifest_version, manifest_json, publication_status, ready_at)
+SELECT ?, tenant_id, UNHEX(SHA2(?, 256)), CONCAT(object_key, '.second'), size_bytes, case_count, manifest_version, manifest_json, publication_status, ready_at
+FROM t_external_bundle WHERE external_id = ?`, bundleB, bundleB, bundleA); err != nil {
+		t.Fatal(err)
+	}
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	jobs := []JudgeJobRequest{
+		{BundleID: bundleA, Language: "cpp", ClientReference: "submission-7"},
+		{BundleID: bundleA, Language: "python", ClientReference: "Submission-7"},
+		{BundleID: bundleB, Language: "cpp", ClientReference: "submission-7"},
+		{BundleID: bundleB, Language: "python"},
+	}
+	jobIDs := make([]string, len(jobs))
+	for index, job := range jobs {
+		job.SourceCode = []byte(fmt.Sprintf("int main(){return %d;}", index))
+		result, err := repository.Submit(context.Background(), tenantID, fmt.Sprintf("filter-job-key-%04d", index), job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobIDs[index] = result.Job.ExternalID
+		if _, err := database.Exec("UPDATE t_external_job SET created_at = TIMESTAMP('2026-07-19 10:00:00') + INTERVAL ? HOUR WHERE external_id = ?", index, result.Job.ExternalID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listed := func(options JobListOptions) []string {
+		t.Helper()
+		options.Limit = 10
+		result, err := repository.List(context.Background(), tenantID, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, job := range result.Jobs {
+			ids = append(ids, job.ExternalID)
+		}
+		return ids
+	}
+	for name, test := range map[string]struct {
+		options JobListOptions
+		want    []string
+	}{
+		"exact client reference": {JobListOptions{ClientReference: "submission-7"}, []string{jobIDs[2], jobIDs[0]}},
+		"bundle":                 {JobListOptions{BundleID: bundleB}, []string{jobIDs[3], jobIDs[2]}},
+		"language":               {JobListOptions{Language: "python"}, []string{jobIDs[3], jobIDs[1]}},
+		"time range": {JobListOptions{
+			CreatedAfter:  time.Date(2026, 7, 19, 11, 0, 0, 0, time.UTC),
+			CreatedBefore: time.Date(2026, 7, 19, 13, 0, 0, 0, time.UTC),
+		}, []string{jobIDs[2], jobIDs[1]}},
+		"combined": {JobListOptions{BundleID: bundleA, Language: "cpp", ClientReference: "submission-7"}, []string{jobIDs[0]}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := listed(test.options); fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Fatalf("jobs = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	first, err := repository.List(context.Background(), tenantID, JobListOptions{Limit: 1, ClientReference: "submission-7"})
+	if err != nil || len(first.Jobs) != 1 || first.NextCursor == "" {
+		t.Fatalf("first=%+v err=%v", first, err)
+	}
+	second, err := repository.List(context.Background(), tenantID, JobListOptions{Limit: 1, ClientReference: "submission-7", Cursor: first.NextCursor})
+	if err != nil || len(second.Jobs) != 1 || second.Jobs[0].ExternalID != jobIDs[0] {
+		t.Fatalf("second=%+v err=%v", second, err)
+	}
+	if _, err := repository.List(context.Background(), tenantID, JobListOptions{Limit: 1, ClientReference: "Submission-7", Cursor: first.NextCursor}); !errors.Is(err, ErrInvalidJobCursor) {
+		t.Fatalf("cursor reused with another reference error = %v", err)
+	}
+	if _, err := repository.List(context.Background(), tenantID, JobListOptions{
+		Limit: 1, CreatedAfter: time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC), CreatedBefore: time.Date(2026, 7, 19, 0, 0, 0, 0, time.UTC),
+	}); !errors.Is(err, ErrExternalJobInvalid) {
+		t.Fatalf("inverted range error = %v", err)
+	}
+}
+
+func TestMySQLJobRepositoryQueueDepthCountsNonTerminalJobsPerTenant(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantA := strings.Repeat("k", 26)
+	tenantB := strings.Repeat("m", 26)
+	insertTenantBundleAndCallback(t, database, tenantA, strings.Repeat("n", 26), "", 10)
+	insertTenantBundleAndCallback(t, database, tenantB, strings.Repeat("o", 26), "", 10)
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	for index, tenant := range []string{tenantA, tenantA, tenantA, tenantB} {
+		bundleID := strings.Repeat("n", 26)
+		if tenant == tenantB {
+			bundleID = strings.Repeat("o", 26)
+		}
+		if _, err := repository.Submit(context.Background(), tenant, fmt.Sprintf("depth-job-key-%04d", index), JudgeJobRequest{
+			BundleID: bundleID, Language: "cpp", SourceCode: []byte(fmt.Sprintf("int main(){return %d;}", index)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.Exec("UPDATE t_external_job SET status = 'RUNNING' WHERE id = (SELECT id FROM (SELECT MIN(id) AS id FROM t_external_job) AS first)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("UPDATE t_external_job SET status = 'SUCCEEDED' WHERE id = (SELECT id FROM (SELECT MAX(id) AS id FROM t_external_job) AS last)"); err != nil {
+		t.Fatal(err)
+	}
+
+	depths, err := repository.QueueDepth(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int, len(depths))
+	for _, depth := range depths {
+		got[depth.TenantExternalID+"/"+string(depth.Status)] = depth.Jobs
+	}
+	if len(got) != 2 || got[tenantA+"/QUEUED"] != 2 || got[tenantA+"/RUNNING"] != 1 {
+		t.Fatalf("queue depth = %+v", depths)
+	}
+}
+
 func TestMySQLJobRepositorySweepsOnlyUnreferencedSourceReservations(t *testing.T) {
 	database := openMySQLIntegration(t)
 	prepareExternalJobDatabase(t, database)
@@ -1095,9 +1251,9 @@ func prepareExternalJobDatabase(t *testing.T, database *sql.DB) {
 		t.Fatal(err)
 	}
 	for _, table := range []string{
-		"t_external_retention_audit", "t_external_execution_daily", "t_external_webhook_outbox", "t_external_job_attempt", "t_external_idempotency",
+		"t_external_retention_audit", "t_external_usage_rollup", "t_external_admin_audit", "t_external_execution_daily", "t_external_webhook_outbox", "t_external_job_event", "t_external_job_attempt", "t_external_idempotency",
 		"t_external_job", "t_external_source_reservation", "t_external_source_object", "t_external_callback", "t_external_bundle",
-		"t_external_api_key", "t_external_tenant",
+		"t_external_run", "t_external_api_key", "t_external_tenant",
 	} {
 		if _, err := database.ExecContext(ctx, "DELETE FROM "+table); err != nil {
 			t.Fatalf("clear %s: %v", table, err)
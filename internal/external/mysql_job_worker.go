@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var infrastructureCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,63}$`)
@@ -24,8 +25,7 @@ func maximumManifestExecutionMillis(manifest bundle.Manifest) (int64, bool) {
 		return 0, false
 	}
 	perCase := int64(manifest.Limits.TimeLimitMillis)
-	if manifest.SpecialJudge != nil {
-		checkerMillis := int64(manifest.SpecialJudge.TimeLimitMillis)
+	for _, checkerMillis := range judgeProgramMillis(manifest) {
 		if checkerMillis <= 0 || perCase > math.MaxInt64-checkerMillis {
 			return 0, false
 		}
@@ -38,6 +38,20 @@ func maximumManifestExecutionMillis(manifest bundle.Manifest) (int64, bool) {
 	return perCase * caseCount, true
 }
 
+// judgeProgramMillis lists the per-case limits of judge-owned programs. An
+// interactor runs beside the contestant rather than after it; reserving its
+// limit as well keeps the reservation a conservative upper bound.
+func judgeProgramMillis(manifest bundle.Manifest) []int64 {
+	var limits []int64
+	if manifest.SpecialJudge != nil {
+		limits = append(limits, int64(manifest.SpecialJudge.TimeLimitMillis))
+	}
+	if manifest.Interactor != nil {
+		limits = append(limits, int64(manifest.Interactor.TimeLimitMillis))
+	}
+	return limits
+}
+
 type dailyReservationDecision uint8
 
 const (
@@ -61,10 +75,12 @@ func (repository *MySQLJobRepository) LoadClaimSource(ctx context.Context, claim
 	return input.SourceCode, nil
 }
 
-func (repository *MySQLJobRepository) LoadClaimInput(ctx context.Context, claim WorkerJobClaim) (WorkerExecutionInput, error) {
+func (repository *MySQLJobRepository) LoadClaimInput(ctx context.Context, claim WorkerJobClaim) (_ WorkerExecutionInput, err error) {
 	if repository == nil || !validWorkerClaim(claim) {
 		return WorkerExecutionInput{}, ErrInvalidJobState
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.LoadClaimInput", claimAttributes(claim)...)
+	defer func() { endSpan(span, err) }()
 	var tenantExternalID string
 	var source SourceObjectMetadata
 	var keyVersion uint64
@@ -72,7 +88,7 @@ func (repository *MySQLJobRepository) LoadClaimInput(ctx context.Context, claim
 	var bundleDigest []byte
 	var manifestJSON []byte
 	var encodedPolicy []byte
-	err := repository.database.QueryRowContext(ctx, `
+	err = repository.database.QueryRowContext(ctx, `
 SELECT tenant.external_id, source.external_id, source.object_key, source.source_sha256,
        source.source_size_bytes, source.encryption_key_version, source.encryption_nonce,
        job.language_id, job.stop_on_failure, bundle.object_key, bundle.sha256,
@@ -285,16 +301,18 @@ func (repository *MySQLJobRepository) claimOneWithClock(
 	var attemptNo uint32
 	var cancelRequested sql.NullTime
 	var claimManifestJSON []byte
+	// The tenant was chosen fairly above. Within it, expired leases are
+	// recovered first, then higher priority classes, then submission order.
 	err = tx.QueryRowContext(ctx, `
 	SELECT job.id, job.external_id, job.status, job.attempt_no,
 	       job.cancel_requested_at, bundle.manifest_json
-	FROM t_external_job AS job FORCE INDEX (idx_external_job_claim)
+	FROM t_external_job AS job FORCE INDEX (idx_external_job_priority_claim)
 	JOIN t_external_bundle AS bundle ON bundle.id = job.bundle_id AND bundle.tenant_id = job.tenant_id
 	WHERE job.tenant_id = ? AND (
 	    (? = 'ACTIVE' AND job.status = 'QUEUED' AND job.next_attempt_at <= ?) OR
 	    (job.status = 'RUNNING' AND job.lease_until <= ?)
 	)
-	ORDER BY (job.status = 'RUNNING') DESC, job.next_attempt_at, job.created_at, job.id
+	ORDER BY (job.status = 'RUNNING') DESC, job.priority DESC, job.next_attempt_at, job.created_at, job.id
 	LIMIT 1 FOR UPDATE SKIP LOCKED`, candidateTenantID, tenantStatus, leaseNow, leaseNow).
 		Scan(&jobInternalID, &jobExternalID, &status, &attemptNo, &cancelRequested, &claimManifestJSON)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -339,6 +357,9 @@ WHERE id = ? AND status = 'RUNNING' AND attempt_no = ?`, leaseNow, jobInternalID
 			if err != nil {
 				return WorkerJobClaim{}, false, repositoryUnavailable("read disabled tenant job", err)
 			}
+			if err := appendJobStatusEvent(ctx, tx, leaseNow, job); err != nil {
+				return WorkerJobClaim{}, false, err
+			}
 			if _, err := repository.insertTerminalWebhookEvent(ctx, tx, leaseNow, job); err != nil {
 				return WorkerJobClaim{}, false, err
 			}
@@ -359,6 +380,9 @@ WHERE id = ? AND status = 'RUNNING' AND attempt_no = ?`, leaseNow, jobInternalID
 			if err != nil {
 				return WorkerJobClaim{}, false, repositoryUnavailable("read recovered cancellation", err)
 			}
+			if err := appendJobStatusEvent(ctx, tx, leaseNow, job); err != nil {
+				return WorkerJobClaim{}, false, err
+			}
 			if _, err := repository.insertTerminalWebhookEvent(ctx, tx, leaseNow, job); err != nil {
 				return WorkerJobClaim{}, false, err
 			}
@@ -379,6 +403,9 @@ WHERE id = ? AND status = 'RUNNING' AND attempt_no = ?`, leaseNow, jobInternalID
 			if err != nil {
 				return WorkerJobClaim{}, false, repositoryUnavailable("read exhausted expired job", err)
 			}
+			if err := appendJobStatusEvent(ctx, tx, leaseNow, job); err != nil {
+				return WorkerJobClaim{}, false, err
+			}
 			if _, err := repository.insertTerminalWebhookEvent(ctx, tx, leaseNow, job); err != nil {
 				return WorkerJobClaim{}, false, err
 			}
@@ -409,6 +436,13 @@ WHERE id = ? AND status = 'RUNNING' AND attempt_no = ?`, leaseNow, jobInternalID
 	WHERE id = ? AND status = 'RUNNING' AND attempt_no = ?`, deferredUntil, jobInternalID, attemptNo); err != nil {
 				return WorkerJobClaim{}, false, repositoryUnavailable("defer recovered daily quota job", err)
 			}
+			job, err := getExternalJobByInternalID(ctx, tx, jobInternalID)
+			if err != nil {
+				return WorkerJobClaim{}, false, repositoryUnavailable("read deferred recovered job", err)
+			}
+			if err := appendJobStatusEvent(ctx, tx, leaseNow, job); err != nil {
+				return WorkerJobClaim{}, false, err
+			}
 			if err := advanceTenantFairness(ctx, tx, candidateTenantID, leaseNow); err != nil {
 				return WorkerJobClaim{}, false, err
 			}
@@ -499,6 +533,12 @@ WHERE id = ? AND status = ? AND attempt_no = ?`,
 	if err != nil {
 		return WorkerJobClaim{}, false, repositoryUnavailable("read claimed job", err)
 	}
+	if err := appendJobStatusEvent(ctx, tx, leaseNow, job); err != nil {
+		return WorkerJobClaim{}, false, err
+	}
+	if err := repository.insertJobStartedWebhookEvent(ctx, tx, leaseNow, job, newAttemptNo); err != nil {
+		return WorkerJobClaim{}, false, err
+	}
 	if err := tx.Commit(); err != nil {
 		return WorkerJobClaim{}, false, repositoryUnavailable("commit worker claim", err)
 	}
@@ -541,6 +581,9 @@ func (repository *MySQLJobRepository) failImpossibleDailyExecution(
 	if err != nil {
 		return repositoryUnavailable("read impossible daily execution job", err)
 	}
+	if err := appendJobStatusEvent(ctx, tx, now, job); err != nil {
+		return err
+	}
 	if _, err := repository.insertTerminalWebhookEvent(ctx, tx, now, job); err != nil {
 		return err
 	}
@@ -637,10 +680,12 @@ func (repository *MySQLJobRepository) Complete(
 	ctx context.Context,
 	claim WorkerJobClaim,
 	result DurableJobResult,
-) error {
+) (err error) {
 	if repository == nil || !validWorkerClaim(claim) || validateDurableJobResult(result) != nil {
 		return ErrInvalidJobState
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.Complete", claimAttributes(claim)...)
+	defer func() { endSpan(span, err) }()
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		return ErrInvalidJobState
@@ -695,6 +740,9 @@ WHERE id = ? AND status = 'RUNNING' AND attempt_no = ? AND worker_id = ? AND lea
 	if err != nil {
 		return repositoryUnavailable("read completed job", err)
 	}
+	if err := appendJobStatusEvent(ctx, tx, now, job); err != nil {
+		return err
+	}
 	if _, err := repository.insertTerminalWebhookEvent(ctx, tx, now, job); err != nil {
 		return err
 	}
@@ -708,10 +756,13 @@ func (repository *MySQLJobRepository) FailInfrastructure(
 	ctx context.Context,
 	claim WorkerJobClaim,
 	failure InfrastructureFailure,
-) (FailureDisposition, error) {
+) (_ FailureDisposition, err error) {
 	if repository == nil || !validWorkerClaim(claim) || !infrastructureCodePattern.MatchString(failure.Code) || failure.RetryDelay < 0 || failure.RetryDelay > time.Hour {
 		return "", ErrInvalidJobState
 	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.FailInfrastructure",
+		append(claimAttributes(claim), attribute.String("croj.failure_code", failure.Code))...)
+	defer func() { endSpan(span, err) }()
 	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
 	if err != nil {
 		return "", repositoryUnavailable("begin infrastructure failure", err)
@@ -778,11 +829,14 @@ WHERE id = ? AND status = 'RUNNING' AND attempt_no = ? AND worker_id = ? AND lea
 	if err := finishAttempt(ctx, tx, claim, attemptStatus, attemptFailureCode, consumedMillis, now); err != nil {
 		return "", err
 	}
+	job, err := getExternalJobByInternalID(ctx, tx, claim.Job.InternalID)
+	if err != nil {
+		return "", repositoryUnavailable("read failed job", err)
+	}
+	if err := appendJobStatusEvent(ctx, tx, now, job); err != nil {
+		return "", err
+	}
 	if disposition != FailureRequeued {
-		job, err := getExternalJobByInternalID(ctx, tx, claim.Job.InternalID)
-		if err != nil {
-			return "", repositoryUnavailable("read terminal failed job", err)
-		}
 		if _, err := repository.insertTerminalWebhookEvent(ctx, tx, now, job); err != nil {
 			return "", err
 		}
@@ -920,14 +974,23 @@ func releaseAttemptReservation(ctx context.Context, tx *sql.Tx, tenantID, jobID
 	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
 		return 0, repositoryUnavailable("settle daily execution ledger", ErrExternalJobUnavailable)
 	}
+	if err := wakeDailyDeferredJobs(ctx, tx, tenantID, accountingDay.Time, now); err != nil {
+		return 0, err
+	}
+	return consumedMillis, nil
+}
+
+// wakeDailyDeferredJobs makes jobs deferred to the next accounting day
+// claimable again once a settlement returned part of that day's budget.
+func wakeDailyDeferredJobs(ctx context.Context, tx *sql.Tx, tenantID uint64, accountingDay, now time.Time) error {
 	if _, err := tx.ExecContext(ctx, `
 	UPDATE t_external_job
 	SET next_attempt_at = ?
 	WHERE tenant_id = ? AND status = 'QUEUED'
-	  AND next_attempt_at = TIMESTAMP(DATE_ADD(?, INTERVAL 1 DAY))`, now, tenantID, accountingDay.Time); err != nil {
-		return 0, repositoryUnavailable("wake daily quota jobs after settlement", err)
+	  AND next_attempt_at = TIMESTAMP(DATE_ADD(?, INTERVAL 1 DAY))`, now, tenantID, accountingDay); err != nil {
+		return repositoryUnavailable("wake daily quota jobs after settlement", err)
 	}
-	return consumedMillis, nil
+	return nil
 }
 
 func settleAttemptReservation(ctx context.Context, tx *sql.Tx, claim WorkerJobClaim, now time.Time, cases []DurableCaseResult, chargeFullReservation bool) (int64, error) {
@@ -110,6 +110,61 @@ WHERE external_id = ?`, tenantID); err != nil {
 	}
 }
 
+func TestMySQLWorkerClaimsHigherPriorityFirstWithoutBypassingTenantFairness(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantA := strings.Repeat("a", 26)
+	tenantB := strings.Repeat("b", 26)
+	bundleA := strings.Repeat("c", 26)
+	bundleB := strings.Repeat("d", 26)
+	insertTenantBundleAndCallback(t, database, tenantA, bundleA, "", 8)
+	insertTenantBundleAndCallback(t, database, tenantB, bundleB, "", 8)
+	if _, err := database.Exec(`UPDATE t_external_tenant
+SET policy_json = JSON_SET(policy_json, '$.maxRunningJobs', 4, '$.maxJobPriority', 5)
+WHERE external_id = ?`, tenantA); err != nil {
+		t.Fatal(err)
+	}
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	submit := func(tenantID, bundleID, key string, priority int) (SubmitJobResult, error) {
+		return repository.Submit(context.Background(), tenantID, key, JudgeJobRequest{
+			BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){return 0;}"), Priority: priority,
+		})
+	}
+	practice, err := submit(tenantA, bundleA, "priority-job-key-0001", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contest, err := submit(tenantA, bundleA, "priority-job-key-0002", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contest.Job.Priority != 5 {
+		t.Fatalf("submitted priority = %d", contest.Job.Priority)
+	}
+	other, err := submit(tenantB, bundleB, "priority-job-key-0003", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := submit(tenantA, bundleA, "priority-job-key-0004", 6); !errors.Is(err, ErrExternalJobInvalid) {
+		t.Fatalf("priority above tenant policy error = %v", err)
+	}
+	if _, err := submit(tenantB, bundleB, "priority-job-key-0005", 1); !errors.Is(err, ErrExternalJobInvalid) {
+		t.Fatalf("priority on a tenant without priority classes error = %v", err)
+	}
+
+	// Tenant A is served first, by priority; tenant B takes the next turn
+	// even though tenant A still has a queued job.
+	for _, want := range []string{contest.Job.ExternalID, other.Job.ExternalID, practice.Job.ExternalID} {
+		claim, err := repository.ClaimNext(context.Background(), "priority-worker", 30*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claim.Job.ExternalID != want {
+			t.Fatalf("claimed %s (priority %d), want %s", claim.Job.ExternalID, claim.Job.Priority, want)
+		}
+	}
+}
+
 func TestMySQLWorkerSkipsQuotaFullTenantWithoutStarvingOthers(t *testing.T) {
 	database := openMySQLIntegration(t)
 	prepareExternalJobDatabase(t, database)
@@ -1174,6 +1229,65 @@ func TestMySQLWorkerCancelAndInfrastructureFailureShareTenantJobLockOrder(t *tes
 	}
 }
 
+func TestMySQLWorkerRecordsOrderedJobEventsForTheOwningTenant(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("e", 26)
+	bundleID := strings.Repeat("f", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 4)
+	otherTenantID := strings.Repeat("g", 26)
+	insertTenantBundleAndCallback(t, database, otherTenantID, strings.Repeat("h", 26), "", 4)
+	clock := &mutableClock{now: time.Now().UTC()}
+	repository := newTestMySQLJobRepositoryWithClock(t, database, newMemorySourceStore(), clock.Now)
+	submitted, err := repository.Submit(context.Background(), tenantID, "event-stream-key-001", JudgeJobRequest{
+		BundleID: bundleID, Language: "go126", SourceCode: []byte("package main\nfunc main(){}"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim, err := repository.ClaimNext(context.Background(), "event-worker", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := DurableCaseResult{CaseID: "case-01", Verdict: "ACCEPTED", TimeMillis: 12, MemoryBytes: 4096}
+	if err := repository.RecordProgress(context.Background(), claim, JobProgress{CompileStatus: "SUCCEEDED", Cases: []DurableCaseResult{accepted}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Complete(context.Background(), claim, DurableJobResult{
+		Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED", TimeMillis: 12, MemoryBytes: 4096, Cases: []DurableCaseResult{accepted},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.RecordProgress(context.Background(), claim, JobProgress{CompileStatus: "SUCCEEDED"}); !errors.Is(err, ErrStaleJobClaim) {
+		t.Fatalf("progress after completion error = %v", err)
+	}
+
+	page, err := repository.ListJobEvents(context.Background(), tenantID, submitted.Job.ExternalID, 0, MaximumJobEventPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Status != JobStatusSucceeded || len(page.Events) != 5 {
+		t.Fatalf("page = %+v", page)
+	}
+	wantTypes := []JobEventType{JobEventStatus, JobEventStatus, JobEventCompile, JobEventCase, JobEventStatus}
+	wantStatuses := []JobStatus{JobStatusQueued, JobStatusRunning, "", "", JobStatusSucceeded}
+	for index, event := range page.Events {
+		if event.Sequence != uint32(index+1) || event.Type != wantTypes[index] || event.Status != wantStatuses[index] {
+			t.Fatalf("event %d = %+v", index, event)
+		}
+	}
+	if page.Events[2].AttemptNo != 1 || page.Events[2].CompileStatus != "SUCCEEDED" || page.Events[3].Case == nil || *page.Events[3].Case != accepted {
+		t.Fatalf("progress events = %+v %+v", page.Events[2], page.Events[3])
+	}
+	resumed, err := repository.ListJobEvents(context.Background(), tenantID, submitted.Job.ExternalID, 3, 1)
+	if err != nil || len(resumed.Events) != 1 || resumed.Events[0].Sequence != 4 {
+		t.Fatalf("resumed page = %+v, error = %v", resumed, err)
+	}
+	if _, err := repository.ListJobEvents(context.Background(), otherTenantID, submitted.Job.ExternalID, 0, 10); !errors.Is(err, ErrExternalJobNotFound) {
+		t.Fatalf("cross-tenant error = %v", err)
+	}
+}
+
 func newTestMySQLJobRepositoryWithClock(
 	t *testing.T,
 	database *sql.DB,
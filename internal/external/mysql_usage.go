@@ -0,0 +1,95 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TenantUsage reads the tenant's policy, job and bundle counts, and the last
+// historyDays days of its execution ledger, oldest first and ending today,
+// from one consistent snapshot.
+func (repository *MySQLJobRepository) TenantUsage(ctx context.Context, tenantExternalID string, historyDays int) (_ TenantUsage, err error) {
+	if repository == nil || !externalIDPattern.MatchString(tenantExternalID) || historyDays < 1 || historyDays > MaximumUsageHistoryDays {
+		return TenantUsage{}, ErrInvalidUsageQuery
+	}
+	ctx, span := startSpan(ctx, "MySQLJobRepository.TenantUsage", attribute.String("croj.tenant", tenantExternalID))
+	defer func() { endSpan(span, err) }()
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return TenantUsage{}, repositoryUnavailable("begin usage snapshot", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var tenantID uint64
+	var encodedPolicy []byte
+	var today time.Time
+	err = tx.QueryRowContext(ctx, `
+SELECT id, policy_json, CURRENT_DATE FROM t_external_tenant
+WHERE external_id = ? AND status = 'ACTIVE'`, tenantExternalID).Scan(&tenantID, &encodedPolicy, &today)
+	if errors.Is(err, sql.ErrNoRows) {
+		return TenantUsage{}, ErrExternalJobNotFound
+	}
+	if err != nil {
+		return TenantUsage{}, repositoryUnavailable("read usage tenant", err)
+	}
+	policy, err := decodeTenantPolicy(encodedPolicy)
+	if err != nil {
+		return TenantUsage{}, ErrExternalJobUnavailable
+	}
+	usage := TenantUsage{
+		Jobs:    JobUsage{MaxQueued: policy.MaxQueuedJobs, MaxRunning: policy.MaxRunningJobs},
+		Bundles: BundleUsage{MaxRetained: policy.MaxRetainedBundles},
+	}
+	if err := tx.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(status = 'QUEUED'), 0), COALESCE(SUM(status = 'RUNNING'), 0)
+FROM t_external_job WHERE tenant_id = ? AND status IN ('QUEUED', 'RUNNING')`, tenantID).Scan(&usage.Jobs.Queued, &usage.Jobs.Running); err != nil {
+		return TenantUsage{}, repositoryUnavailable("count usage jobs", err)
+	}
+	if usage.Bundles.Retained, err = retainedBundleCount(ctx, tx, tenantID); err != nil {
+		return TenantUsage{}, repositoryUnavailable("count usage bundles", err)
+	}
+
+	year, month, day := today.Date()
+	today = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	first := today.AddDate(0, 0, 1-historyDays)
+	rows, err := tx.QueryContext(ctx, `
+SELECT accounting_day, reserved_millis, consumed_millis FROM t_external_execution_daily
+WHERE tenant_id = ? AND accounting_day BETWEEN ? AND ?`, tenantID, first, today)
+	if err != nil {
+		return TenantUsage{}, repositoryUnavailable("read usage ledger", err)
+	}
+	defer rows.Close()
+	ledger := make(map[string]ExecutionUsageDay, historyDays)
+	for rows.Next() {
+		var accountingDay time.Time
+		var entry ExecutionUsageDay
+		if err := rows.Scan(&accountingDay, &entry.ReservedMillis, &entry.ConsumedMillis); err != nil {
+			return TenantUsage{}, repositoryUnavailable("scan usage ledger", err)
+		}
+		entry.Day = accountingDay.Format(time.DateOnly)
+		ledger[entry.Day] = entry
+	}
+	if err := rows.Err(); err != nil {
+		return TenantUsage{}, repositoryUnavailable("iterate usage ledger", err)
+	}
+	usage.History = make([]ExecutionUsageDay, historyDays)
+	for index := range usage.History {
+		key := first.AddDate(0, 0, index).Format(time.DateOnly)
+		entry, found := ledger[key]
+		if !found {
+			entry = ExecutionUsageDay{Day: key}
+		}
+		usage.History[index] = entry
+	}
+	current := usage.History[historyDays-1]
+	usage.Execution = ExecutionUsage{
+		Day: current.Day, ReservedMillis: current.ReservedMillis, ConsumedMillis: current.ConsumedMillis,
+		RemainingMillis: max(0, policy.DailyExecutionMillis-current.ReservedMillis-current.ConsumedMillis),
+		LimitMillis:     policy.DailyExecutionMillis,
+	}
+	return usage, nil
+}
@@ -0,0 +1,315 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MySQLUsageExporter reads billable usage for operators. It needs only the
+// database, so judge-admin can export without the runtime's key material.
+type MySQLUsageExporter struct {
+	database *sql.DB
+}
+
+func NewMySQLUsageExporter(database *sql.DB) (*MySQLUsageExporter, error) {
+	if database == nil {
+		return nil, fmt.Errorf("usage export database is required")
+	}
+	return &MySQLUsageExporter{database: database}, nil
+}
+
+type usageExportKey struct {
+	tenantID uint64
+	day      string
+	language string
+}
+
+// ExportUsage returns the rows of the query sorted by tenant ID, day and
+// language from one consistent snapshot. Jobs and custom runs that retention
+// already removed are read from t_external_usage_rollup, so a finished day exports
+// the same rows no matter when it is exported.
+func (exporter *MySQLUsageExporter) ExportUsage(ctx context.Context, query UsageExportQuery) (_ []UsageExportRow, err error) {
+	if exporter == nil || exporter.database == nil {
+		return nil, ErrExternalJobUnavailable
+	}
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	ctx, span := startSpan(ctx, "MySQLUsageExporter.ExportUsage", attribute.String("croj.tenant", query.TenantID))
+	defer func() { endSpan(span, err) }()
+	tx, err := exporter.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, repositoryUnavailable("begin usage export snapshot", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	tenants, tenantIDs, err := usageExportTenants(ctx, tx, query.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	// tenantFilter is 0 for every tenant; internal IDs start at 1.
+	var tenantFilter uint64
+	if query.TenantID != "" {
+		tenantFilter = tenantIDs[0]
+	}
+	from, to := query.From.Format(time.DateOnly), query.To.Format(time.DateOnly)
+	end := query.To.AddDate(0, 0, 1).Format(time.DateOnly)
+	usage := make(map[usageExportKey]*UsageExportRow)
+	row := func(tenantID uint64, day time.Time, language string) *UsageExportRow {
+		key := usageExportKey{tenantID: tenantID, day: day.Format(time.DateOnly), language: language}
+		if usage[key] == nil {
+			usage[key] = &UsageExportRow{TenantID: tenants[tenantID], Day: key.day, Language: language}
+		}
+		return usage[key]
+	}
+
+	if err := scanUsageExport(ctx, tx, "read settled attempt usage", `
+SELECT attempt.tenant_id, attempt.accounting_day, job.language_id, SUM(attempt.consumed_execution_millis)
+FROM t_external_job_attempt AS attempt
+JOIN t_external_job AS job ON job.id = attempt.job_id
+WHERE attempt.accounting_day BETWEEN ? AND ? AND (? = 0 OR attempt.tenant_id = ?) AND attempt.status <> 'RUNNING'
+GROUP BY attempt.tenant_id, attempt.accounting_day, job.language_id
+HAVING SUM(attempt.consumed_execution_millis) > 0`, []any{from, to, tenantFilter, tenantFilter},
+		func(tenantID uint64, day time.Time, language string, values []int64) {
+			row(tenantID, day, language).JobExecutionMillis += values[0]
+		}, 1); err != nil {
+		return nil, err
+	}
+	if err := scanUsageExport(ctx, tx, "read settled custom run usage", `
+SELECT tenant_id, accounting_day, language, SUM(consumed_millis)
+FROM t_external_run
+WHERE accounting_day BETWEEN ? AND ? AND (? = 0 OR tenant_id = ?) AND status <> 'RUNNING'
+GROUP BY tenant_id, accounting_day, language
+HAVING SUM(consumed_millis) > 0`, []any{from, to, tenantFilter, tenantFilter},
+		func(tenantID uint64, day time.Time, language string, values []int64) {
+			row(tenantID, day, language).RunExecutionMillis += values[0]
+		}, 1); err != nil {
+		return nil, err
+	}
+	if err := scanUsageExport(ctx, tx, "read finished job usage", `
+SELECT tenant_id, DATE(completed_at), language_id,
+       SUM(status = 'SUCCEEDED'), SUM(status = 'FAILED'), SUM(status = 'CANCELLED')
+FROM t_external_job
+WHERE status IN ('SUCCEEDED', 'FAILED', 'CANCELLED') AND completed_at >= ? AND completed_at < ?
+  AND (? = 0 OR tenant_id = ?)
+GROUP BY tenant_id, DATE(completed_at), language_id`, []any{from, end, tenantFilter, tenantFilter},
+		func(tenantID uint64, day time.Time, language string, values []int64) {
+			counted := row(tenantID, day, language)
+			counted.JobsSucceeded += values[0]
+			counted.JobsFailed += values[1]
+			counted.JobsCancelled += values[2]
+		}, 3); err != nil {
+		return nil, err
+	}
+	if err := scanUsageExport(ctx, tx, "read retained job usage", `
+SELECT tenant_id, usage_day, language_id, job_execution_millis, run_execution_millis, jobs_succeeded, jobs_failed, jobs_cancelled
+FROM t_external_usage_rollup
+WHERE usage_day BETWEEN ? AND ? AND (? = 0 OR tenant_id = ?)`, []any{from, to, tenantFilter, tenantFilter},
+		func(tenantID uint64, day time.Time, language string, values []int64) {
+			retained := row(tenantID, day, language)
+			retained.JobExecutionMillis += values[0]
+			retained.RunExecutionMillis += values[1]
+			retained.JobsSucceeded += values[2]
+			retained.JobsFailed += values[3]
+			retained.JobsCancelled += values[4]
+		}, 5); err != nil {
+		return nil, err
+	}
+
+	for _, tenantID := range tenantIDs {
+		for day := query.From; !day.After(query.To); day = day.AddDate(0, 0, 1) {
+			row(tenantID, day, "")
+		}
+	}
+	if err := addBundleStorage(ctx, tx, tenantFilter, from, end, func(tenantID uint64, day time.Time, bytes int64) {
+		row(tenantID, day, "").BundleStorageBytes += bytes
+	}); err != nil {
+		return nil, err
+	}
+
+	rows := make([]UsageExportRow, 0, len(usage))
+	for _, exported := range usage {
+		rows = append(rows, *exported)
+	}
+	sort.Slice(rows, func(left, right int) bool {
+		if rows[left].TenantID != rows[right].TenantID {
+			return rows[left].TenantID < rows[right].TenantID
+		}
+		if rows[left].Day != rows[right].Day {
+			return rows[left].Day < rows[right].Day
+		}
+		return rows[left].Language < rows[right].Language
+	})
+	return rows, nil
+}
+
+// usageExportTenants maps internal tenant IDs to external IDs and returns
+// the internal IDs ordered by external ID. Disabled tenants are exported too;
+// their past usage is still billable.
+func usageExportTenants(ctx context.Context, tx *sql.Tx, tenantExternalID string) (map[uint64]string, []uint64, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT id, external_id FROM t_external_tenant
+WHERE ? = '' OR external_id = ?
+ORDER BY external_id`, tenantExternalID, tenantExternalID)
+	if err != nil {
+		return nil, nil, repositoryUnavailable("read usage export tenants", err)
+	}
+	defer rows.Close()
+	tenants := make(map[uint64]string)
+	var ordered []uint64
+	for rows.Next() {
+		var id uint64
+		var externalID string
+		if err := rows.Scan(&id, &externalID); err != nil {
+			return nil, nil, repositoryUnavailable("scan usage export tenant", err)
+		}
+		tenants[id] = externalID
+		ordered = append(ordered, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, repositoryUnavailable("read usage export tenants", err)
+	}
+	if tenantExternalID != "" && len(ordered) == 0 {
+		return nil, nil, ErrUsageTenantNotFound
+	}
+	return tenants, ordered, nil
+}
+
+// scanUsageExport reads rows of (tenant, day, language, values...) where the
+// values are the given number of integer sums.
+func scanUsageExport(
+	ctx context.Context,
+	tx *sql.Tx,
+	operation, query string,
+	arguments []any,
+	apply func(uint64, time.Time, string, []int64),
+	valueCount int,
+) error {
+	rows, err := tx.QueryContext(ctx, query, arguments...)
+	if err != nil {
+		return repositoryUnavailable(operation, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tenantID uint64
+		var day time.Time
+		var language string
+		values := make([]int64, valueCount)
+		destinations := []any{&tenantID, &day, &language}
+		for index := range values {
+			destinations = append(destinations, &values[index])
+		}
+		if err := rows.Scan(destinations...); err != nil {
+			return repositoryUnavailable(operation, err)
+		}
+		apply(tenantID, day, language, values)
+	}
+	if err := rows.Err(); err != nil {
+		return repositoryUnavailable(operation, err)
+	}
+	return nil
+}
+
+// addBundleStorage charges each published bundle to every day it was still
+// stored at the end of: from the day it became ready until the day before
+// its content was deleted. Bundles awaiting deletion still occupy storage.
+func addBundleStorage(ctx context.Context, tx *sql.Tx, tenantFilter uint64, from, end string, apply func(uint64, time.Time, int64)) error {
+	rows, err := tx.QueryContext(ctx, `
+SELECT tenant_id, size_bytes, DATE(ready_at), DATE(deleted_at)
+FROM t_external_bundle
+WHERE ready_at IS NOT NULL AND ready_at < ? AND (deleted_at IS NULL OR deleted_at >= ?)
+  AND (? = 0 OR tenant_id = ?)`, end, from, tenantFilter, tenantFilter)
+	if err != nil {
+		return repositoryUnavailable("read bundle storage usage", err)
+	}
+	defer rows.Close()
+	first, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return ErrInvalidUsageExport
+	}
+	last, err := time.Parse(time.DateOnly, end)
+	if err != nil {
+		return ErrInvalidUsageExport
+	}
+	for rows.Next() {
+		var tenantID uint64
+		var size int64
+		var readyDay time.Time
+		var deletedDay sql.NullTime
+		if err := rows.Scan(&tenantID, &size, &readyDay, &deletedDay); err != nil {
+			return repositoryUnavailable("scan bundle storage usage", err)
+		}
+		day := normalizedDate(readyDay)
+		if day.Before(first) {
+			day = first
+		}
+		stop := last
+		if deletedDay.Valid && normalizedDate(deletedDay.Time).Before(stop) {
+			stop = normalizedDate(deletedDay.Time)
+		}
+		for ; day.Before(stop); day = day.AddDate(0, 0, 1) {
+			apply(tenantID, day, size)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return repositoryUnavailable("read bundle storage usage", err)
+	}
+	return nil
+}
+
+func normalizedDate(value time.Time) time.Time {
+	year, month, day := value.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// rollUpRetainedRunUsage keeps the settled time of the custom runs matched by
+// filter, which retention deletes in the same transaction.
+func rollUpRetainedRunUsage(ctx context.Context, tx *sql.Tx, filter string, arguments []any) error {
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_usage_rollup(tenant_id, usage_day, language_id, run_execution_millis)
+SELECT tenant_id, accounting_day, language, SUM(consumed_millis)
+FROM t_external_run
+WHERE `+filter+` AND status <> 'RUNNING'
+GROUP BY tenant_id, accounting_day, language
+HAVING SUM(consumed_millis) > 0
+ON DUPLICATE KEY UPDATE run_execution_millis = run_execution_millis + VALUES(run_execution_millis)`, arguments...); err != nil {
+		return repositoryUnavailable("roll up retained custom run usage", err)
+	}
+	return nil
+}
+
+// rollUpRetainedJobUsage keeps the billable usage of a job that source
+// retention is about to delete. It runs in the deleting transaction, so each
+// job is counted either live or in the rollup, never both.
+func rollUpRetainedJobUsage(ctx context.Context, tx *sql.Tx, claim SourceRetentionClaim) error {
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_usage_rollup(tenant_id, usage_day, language_id, job_execution_millis)
+SELECT attempt.tenant_id, attempt.accounting_day, job.language_id, SUM(attempt.consumed_execution_millis)
+FROM t_external_job_attempt AS attempt
+JOIN t_external_job AS job ON job.id = attempt.job_id
+WHERE attempt.tenant_id = ? AND attempt.job_id = ? AND attempt.accounting_day IS NOT NULL AND attempt.status <> 'RUNNING'
+GROUP BY attempt.tenant_id, attempt.accounting_day, job.language_id
+HAVING SUM(attempt.consumed_execution_millis) > 0
+ON DUPLICATE KEY UPDATE job_execution_millis = job_execution_millis + VALUES(job_execution_millis)`,
+		claim.TenantInternalID, claim.JobInternalID); err != nil {
+		return repositoryUnavailable("roll up retained attempt usage", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_usage_rollup(tenant_id, usage_day, language_id, jobs_succeeded, jobs_failed, jobs_cancelled)
+SELECT tenant_id, DATE(completed_at), language_id, status = 'SUCCEEDED', status = 'FAILED', status = 'CANCELLED'
+FROM t_external_job
+WHERE tenant_id = ? AND id = ? AND status IN ('SUCCEEDED', 'FAILED', 'CANCELLED') AND completed_at IS NOT NULL
+ON DUPLICATE KEY UPDATE
+    jobs_succeeded = jobs_succeeded + VALUES(jobs_succeeded),
+    jobs_failed = jobs_failed + VALUES(jobs_failed),
+    jobs_cancelled = jobs_cancelled + VALUES(jobs_cancelled)`,
+		claim.TenantInternalID, claim.JobInternalID); err != nil {
+		return repositoryUnavailable("roll up retained job usage", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMySQLUsageExporterIsStableAcrossSourceRetention(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("x", 26)
+	bundleID := strings.Repeat("y", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 10)
+	insertTenantBundleAndCallback(t, database, strings.Repeat("z", 26), strings.Repeat("w", 26), "", 10)
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	var jobIDs []string
+	for index := range 2 {
+		result, err := repository.Submit(context.Background(), tenantID, fmt.Sprintf("usage-export-key-%04d", index), JudgeJobRequest{
+			BundleID: bundleID, Language: "cpp", SourceCode: []byte(fmt.Sprintf("int main(){return %d;}", index)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobIDs = append(jobIDs, result.Job.ExternalID)
+	}
+	for index, status := range []string{"SUCCEEDED", "FAILED"} {
+		if _, err := database.Exec("UPDATE t_external_job SET status = ?, completed_at = '2026-09-02 10:00:00' WHERE external_id = ?", status, jobIDs[index]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.Exec("UPDATE t_external_bundle SET ready_at = '2026-09-01 08:00:00' WHERE external_id = ?", bundleID); err != nil {
+		t.Fatal(err)
+	}
+	exporter, err := NewMySQLUsageExporter(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, err := ParseUsageExportQuery(tenantID, "2026-09-01", "2026-09-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UsageExportRow{
+		{TenantID: tenantID, Day: "2026-09-01", BundleStorageBytes: 128},
+		{TenantID: tenantID, Day: "2026-09-02", BundleStorageBytes: 128},
+		{TenantID: tenantID, Day: "2026-09-02", Language: "cpp", JobsSucceeded: 1, JobsFailed: 1},
+		{TenantID: tenantID, Day: "2026-09-03", BundleStorageBytes: 128},
+	}
+	rows, err := exporter.ExportUsage(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Fatalf("rows = %+v, want %+v", rows, want)
+	}
+
+	var claim SourceRetentionClaim
+	if err := database.QueryRow("SELECT tenant_id, id FROM t_external_job WHERE external_id = ?", jobIDs[0]).Scan(&claim.TenantInternalID, &claim.JobInternalID); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := database.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := rollUpRetainedJobUsage(context.Background(), tx, claim); err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{
+		"SET FOREIGN_KEY_CHECKS = 0",
+		fmt.Sprintf("DELETE FROM t_external_job WHERE id = %d", claim.JobInternalID),
+		"SET FOREIGN_KEY_CHECKS = 1",
+	} {
+		if _, err := tx.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = exporter.ExportUsage(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Fatalf("rows after retention = %+v, want %+v", rows, want)
+	}
+
+	everyone, err := ParseUsageExportQuery("", "2026-09-01", "2026-09-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err = exporter.ExportUsage(context.Background(), everyone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].TenantID != tenantID || rows[1].TenantID != strings.Repeat("z", 26) || rows[1].BundleStorageBytes != 0 {
+		t.Fatalf("all-tenant rows = %+v", rows)
+	}
+	unknown, err := ParseUsageExportQuery(strings.Repeat("v", 26), "2026-09-01", "2026-09-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.ExportUsage(context.Background(), unknown); !errors.Is(err, ErrUsageTenantNotFound) {
+		t.Fatalf("unknown tenant error = %v", err)
+	}
+}
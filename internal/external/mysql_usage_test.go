@@ -0,0 +1,75 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMySQLJobRepositoryTenantUsageReportsPolicyCountsAndDailySeries(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("s", 26)
+	otherTenantID := strings.Repeat("t", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, strings.Repeat("u", 26), "", 10)
+	insertTenantBundleAndCallback(t, database, otherTenantID, strings.Repeat("v", 26), "", 10)
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	var jobIDs []string
+	for index := range 3 {
+		result, err := repository.Submit(context.Background(), tenantID, fmt.Sprintf("usage-job-key-%04d", index), JudgeJobRequest{
+			BundleID: strings.Repeat("u", 26), Language: "cpp", SourceCode: []byte(fmt.Sprintf("int main(){return %d;}", index)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobIDs = append(jobIDs, result.Job.ExternalID)
+	}
+	if _, err := database.Exec("UPDATE t_external_job SET status = 'RUNNING' WHERE external_id = ?", jobIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	finishExternalJob(t, database, jobIDs[1])
+	if _, err := database.Exec(`
+INSERT INTO t_external_execution_daily(tenant_id, accounting_day, reserved_millis, consumed_millis)
+SELECT id, CURRENT_DATE, 1000, 2500 FROM t_external_tenant WHERE external_id = ?
+UNION ALL SELECT id, CURRENT_DATE - INTERVAL 2 DAY, 0, 700 FROM t_external_tenant WHERE external_id = ?
+UNION ALL SELECT id, CURRENT_DATE - INTERVAL 5 DAY, 0, 900 FROM t_external_tenant WHERE external_id = ?
+UNION ALL SELECT id, CURRENT_DATE, 0, 99999 FROM t_external_tenant WHERE external_id = ?`, tenantID, tenantID, tenantID, otherTenantID); err != nil {
+		t.Fatal(err)
+	}
+	var today time.Time
+	if err := database.QueryRow("SELECT CURRENT_DATE").Scan(&today); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := repository.TenantUsage(context.Background(), tenantID, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantExecution := ExecutionUsage{
+		Day: today.Format(time.DateOnly), ReservedMillis: 1000, ConsumedMillis: 2500, RemainingMillis: 3_600_000 - 3500, LimitMillis: 3_600_000,
+	}
+	if usage.Execution != wantExecution {
+		t.Fatalf("execution = %+v", usage.Execution)
+	}
+	if usage.Jobs != (JobUsage{Queued: 1, MaxQueued: 10, Running: 1, MaxRunning: 1}) || usage.Bundles != (BundleUsage{Retained: 1, MaxRetained: 100}) {
+		t.Fatalf("jobs=%+v bundles=%+v", usage.Jobs, usage.Bundles)
+	}
+	wantHistory := []ExecutionUsageDay{
+		{Day: today.AddDate(0, 0, -2).Format(time.DateOnly), ConsumedMillis: 700},
+		{Day: today.AddDate(0, 0, -1).Format(time.DateOnly)},
+		{Day: today.Format(time.DateOnly), ReservedMillis: 1000, ConsumedMillis: 2500},
+	}
+	if fmt.Sprint(usage.History) != fmt.Sprint(wantHistory) {
+		t.Fatalf("history = %+v, want %+v", usage.History, wantHistory)
+	}
+
+	if _, err := repository.TenantUsage(context.Background(), tenantID, MaximumUsageHistoryDays+1); !errors.Is(err, ErrInvalidUsageQuery) {
+		t.Fatalf("oversized history error = %v", err)
+	}
+	if _, err := repository.TenantUsage(context.Background(), strings.Repeat("w", 26), 1); !errors.Is(err, ErrExternalJobNotFound) {
+		t.Fatalf("unknown tenant error = %v", err)
+	}
+}
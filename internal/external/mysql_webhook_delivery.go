@@ -0,0 +1,204 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+const webhookDeliverySelect = `
+SELECT outbox.id, outbox.event_id, outbox.event_type, job.external_id, callback.external_id,
+       outbox.status, outbox.attempt_count, outbox.last_http_status, outbox.last_latency_ms, outbox.last_error_code,
+       outbox.next_attempt_at, outbox.created_at, outbox.delivered_at, outbox.dead_at
+FROM t_external_webhook_outbox AS outbox
+JOIN t_external_tenant AS tenant ON tenant.id = outbox.tenant_id
+LEFT JOIN t_external_job AS job ON job.id = outbox.job_id AND job.tenant_id = outbox.tenant_id
+JOIN t_external_callback AS callback ON callback.id = outbox.callback_id AND callback.tenant_id = outbox.tenant_id`
+
+// ListWebhookDeliveries returns one page of the tenant's retained outbox
+// rows, newest first. Terminal rows stay listed until SweepTerminal removes
+// them; the signed body is never returned.
+func (repository *MySQLWebhookOutboxRepository) ListWebhookDeliveries(
+	ctx context.Context,
+	tenantID string,
+	options WebhookDeliveryListOptions,
+) (WebhookDeliveryListResult, error) {
+	if repository == nil || repository.cursor == nil {
+		return WebhookDeliveryListResult{}, repositoryUnavailable("list webhook deliveries", errors.New("delivery log is not configured"))
+	}
+	if !externalIDPattern.MatchString(tenantID) || options.Limit < 1 || options.Limit > 100 ||
+		!validWebhookDeliveryStatusFilter(options.Status) {
+		return WebhookDeliveryListResult{}, ErrInvalidWebhookDeliveryQuery
+	}
+	arguments := []any{tenantID}
+	conditions := []string{"tenant.external_id = ?", "tenant.status = 'ACTIVE'"}
+	if options.Status != "" {
+		conditions = append(conditions, "outbox.status = ?")
+		arguments = append(arguments, options.Status)
+	}
+	if options.Cursor != "" {
+		cursor, err := repository.cursor.Decode(options.Cursor, tenantID, options.Status)
+		if err != nil {
+			return WebhookDeliveryListResult{}, ErrInvalidWebhookDeliveryCursor
+		}
+		conditions = append(conditions, "outbox.id < ?")
+		arguments = append(arguments, cursor.InternalID)
+	}
+	arguments = append(arguments, options.Limit+1)
+	rows, err := repository.database.QueryContext(ctx, webhookDeliverySelect+`
+WHERE `+strings.Join(conditions, " AND ")+`
+ORDER BY outbox.id DESC
+LIMIT ?`, arguments...)
+	if err != nil {
+		return WebhookDeliveryListResult{}, repositoryUnavailable("list webhook deliveries", err)
+	}
+	defer rows.Close()
+	deliveries := make([]WebhookDeliverySummary, 0, options.Limit+1)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return WebhookDeliveryListResult{}, repositoryUnavailable("scan listed webhook delivery", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return WebhookDeliveryListResult{}, repositoryUnavailable("iterate listed webhook deliveries", err)
+	}
+	result := WebhookDeliveryListResult{Items: deliveries}
+	if len(deliveries) > options.Limit {
+		result.Items = deliveries[:options.Limit]
+		result.NextCursor, err = repository.cursor.Encode(WebhookDeliveryCursor{
+			TenantID: tenantID, Status: options.Status, InternalID: deliveries[options.Limit-1].InternalID,
+		})
+		if err != nil {
+			return WebhookDeliveryListResult{}, repositoryUnavailable("encode next webhook delivery cursor", err)
+		}
+	}
+	return result, nil
+}
+
+// RedeliverWebhook moves one DEAD outbox row back to PENDING. The event ID
+// and the stored body bytes are left untouched so receivers deduplicate the
+// redelivery exactly like an automatic retry; the attempt budget and the
+// delivery window start over from the database clock. A started or progress
+// event retired as superseded is refused: its snapshot is older than one the
+// receiver may already hold.
+func (repository *MySQLWebhookOutboxRepository) RedeliverWebhook(
+	ctx context.Context,
+	tenantID string,
+	eventID string,
+) (WebhookDeliverySummary, error) {
+	if repository == nil || !externalIDPattern.MatchString(tenantID) {
+		return WebhookDeliverySummary{}, ErrWebhookSettlementInvalid
+	}
+	if !externalIDPattern.MatchString(eventID) {
+		return WebhookDeliverySummary{}, ErrWebhookDeliveryNotFound
+	}
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return WebhookDeliverySummary{}, repositoryUnavailable("begin webhook redelivery", err)
+	}
+	defer tx.Rollback()
+	now, err := mysqlCurrentTime(ctx, tx)
+	if err != nil {
+		return WebhookDeliverySummary{}, err
+	}
+	var outboxID uint64
+	var status WebhookDeliveryStatus
+	var eventType string
+	var errorCode sql.NullString
+	var callbackDisabled sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+SELECT outbox.id, outbox.status, outbox.event_type, outbox.last_error_code, callback.disabled_at
+FROM t_external_webhook_outbox AS outbox
+JOIN t_external_tenant AS tenant ON tenant.id = outbox.tenant_id
+JOIN t_external_callback AS callback ON callback.id = outbox.callback_id AND callback.tenant_id = outbox.tenant_id
+WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE' AND outbox.event_id = ?
+FOR UPDATE OF outbox`, tenantID, eventID).Scan(&outboxID, &status, &eventType, &errorCode, &callbackDisabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return WebhookDeliverySummary{}, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return WebhookDeliverySummary{}, repositoryUnavailable("lock webhook redelivery", err)
+	}
+	if status != WebhookDeliveryDead {
+		return WebhookDeliverySummary{}, ErrWebhookDeliveryNotDead
+	}
+	if errorCode.String == WebhookErrorSuperseded {
+		return WebhookDeliverySummary{}, ErrWebhookDeliverySuperseded
+	}
+	if callbackDisabled.Valid {
+		return WebhookDeliverySummary{}, ErrWebhookCallbackDisabled
+	}
+	window := repository.deliveryWindow
+	switch eventType {
+	case WebhookEventPing:
+		window = webhookPingDeliveryWindow
+	case WebhookEventJobStarted, WebhookEventJobProgress:
+		window = webhookProgressDeliveryWindow
+	}
+	result, err := tx.ExecContext(ctx, `
+UPDATE t_external_webhook_outbox
+SET status = 'PENDING', attempt_count = 0, next_attempt_at = ?, expires_at = ?, dead_at = NULL
+WHERE id = ? AND status = 'DEAD'`, now, now.Add(window), outboxID)
+	if err != nil {
+		return WebhookDeliverySummary{}, repositoryUnavailable("reschedule dead webhook", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return WebhookDeliverySummary{}, repositoryUnavailable("confirm webhook redelivery", err)
+	}
+	if affected != 1 {
+		return WebhookDeliverySummary{}, ErrWebhookDeliveryNotDead
+	}
+	delivery, err := scanWebhookDelivery(tx.QueryRowContext(ctx, webhookDeliverySelect+`
+WHERE outbox.id = ?`, outboxID))
+	if err != nil {
+		return WebhookDeliverySummary{}, repositoryUnavailable("read redelivered webhook", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return WebhookDeliverySummary{}, repositoryUnavailable("commit webhook redelivery", err)
+	}
+	return delivery, nil
+}
+
+func scanWebhookDelivery(scanner interface{ Scan(...any) error }) (WebhookDeliverySummary, error) {
+	var delivery WebhookDeliverySummary
+	var httpStatus, latency sql.NullInt64
+	var jobID, errorCode sql.NullString
+	var nextAttemptAt sql.NullTime
+	var deliveredAt, deadAt sql.NullTime
+	if err := scanner.Scan(&delivery.InternalID, &delivery.EventID, &delivery.EventType, &jobID, &delivery.CallbackID,
+		&delivery.Status, &delivery.AttemptCount, &httpStatus, &latency, &errorCode,
+		&nextAttemptAt, &delivery.CreatedAt, &deliveredAt, &deadAt); err != nil {
+		return WebhookDeliverySummary{}, err
+	}
+	if delivery.Status == "" || !validWebhookDeliveryStatusFilter(delivery.Status) {
+		return WebhookDeliverySummary{}, errors.New("stored webhook delivery status is invalid")
+	}
+	delivery.CreatedAt = delivery.CreatedAt.UTC()
+	if httpStatus.Valid {
+		value := int(httpStatus.Int64)
+		delivery.LastHTTPStatus = &value
+	}
+	if latency.Valid {
+		value := latency.Int64
+		delivery.LastLatencyMillis = &value
+	}
+	delivery.JobID = jobID.String
+	delivery.LastErrorCode = errorCode.String
+	if delivery.Status == WebhookDeliveryPending && nextAttemptAt.Valid {
+		value := nextAttemptAt.Time.UTC()
+		delivery.NextAttemptAt = &value
+	}
+	if deliveredAt.Valid {
+		value := deliveredAt.Time.UTC()
+		delivery.DeliveredAt = &value
+	}
+	if deadAt.Valid {
+		value := deadAt.Time.UTC()
+		delivery.DeadAt = &value
+	}
+	return delivery, nil
+}
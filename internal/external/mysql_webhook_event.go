@@ -48,6 +48,11 @@ VALUES (?, ?, ?, ?, ?, ?, ?, 'PENDING', 0, ?, ?, ?)`,
 			eventID, tenantInternalID, job.InternalID, callbackInternalID.Int64, eventType,
 			payloadJSON, payloadBody, now, now, now.Add(repository.webhookDeliveryWindow))
 		if err == nil {
+			// A finished job makes its queued started and progress events
+			// obsolete; receivers learn the outcome from this event instead.
+			if err := supersedeOptionalWebhookEvents(ctx, tx, now, job.InternalID, WebhookEventJobStarted, WebhookEventJobProgress); err != nil {
+				return "", err
+			}
 			return eventID, nil
 		}
 		var mysqlError *mysqlDriver.MySQLError
@@ -61,7 +66,7 @@ VALUES (?, ?, ?, ?, ?, ?, ?, 'PENDING', 0, ?, ?, ?)`,
 		var authoritativeOccurredAt time.Time
 		err = tx.QueryRowContext(ctx, `
 SELECT event_id, tenant_id, callback_id, event_type, payload_body, created_at
-FROM t_external_webhook_outbox WHERE job_id = ? FOR UPDATE`, job.InternalID).Scan(
+FROM t_external_webhook_outbox WHERE terminal_job_id = ? FOR UPDATE`, job.InternalID).Scan(
 			&authoritativeEventID, &authoritativeTenantID, &authoritativeCallbackID,
 			&authoritativeType, &authoritativeBody, &authoritativeOccurredAt,
 		)
@@ -15,16 +15,23 @@ var (
 	ErrWebhookSettlementInvalid = errors.New("webhook settlement is invalid")
 )
 
+// MySQLWebhookOutboxRepositoryConfig configures delivery and the tenant
+// delivery log. CursorKey is only required by ListWebhookDeliveries;
+// DeliveryWindow bounds a manual redelivery like a fresh terminal event.
 type MySQLWebhookOutboxRepositoryConfig struct {
 	Database        *sql.DB
 	Random          io.Reader
 	MaximumAttempts uint
+	CursorKey       []byte
+	DeliveryWindow  time.Duration
 }
 
 type MySQLWebhookOutboxRepository struct {
 	database        *sql.DB
 	random          io.Reader
 	maximumAttempts uint
+	cursor          *WebhookDeliveryCursorCodec
+	deliveryWindow  time.Duration
 }
 
 type WebhookClaim struct {
@@ -43,6 +50,9 @@ type WebhookClaim struct {
 	LeaseUntil     time.Time
 	ExpiresAt      time.Time
 	secret         EncryptedCallbackSecret
+	// previousSecret is set while a rotated secret is inside its grace
+	// period; the delivery then carries a v1 signature for each secret.
+	previousSecret *EncryptedCallbackSecret
 }
 
 func (WebhookClaim) String() string   { return "[REDACTED WEBHOOK CLAIM]" }
@@ -59,12 +69,28 @@ func (claim WebhookClaim) EncryptedSecret() EncryptedCallbackSecret {
 	}
 }
 
+// PreviousEncryptedSecret returns the rotated secret that still signs
+// deliveries, if the callback is inside a rotation grace period.
+func (claim WebhookClaim) PreviousEncryptedSecret() (EncryptedCallbackSecret, bool) {
+	if claim.previousSecret == nil {
+		return EncryptedCallbackSecret{}, false
+	}
+	return EncryptedCallbackSecret{
+		Ciphertext: append([]byte(nil), claim.previousSecret.Ciphertext...),
+		Nonce:      append([]byte(nil), claim.previousSecret.Nonce...),
+		KeyVersion: claim.previousSecret.KeyVersion,
+	}, true
+}
+
 type WebhookSettlement struct {
 	Disposition WebhookDisposition
 	HTTPStatus  int
 	ErrorCode   string
 	RetryAt     time.Time
 	RetryDelay  time.Duration
+	// Latency is the measured round trip of the HTTP attempt; zero when no
+	// request was sent.
+	Latency time.Duration
 }
 
 func NewMySQLWebhookOutboxRepository(config MySQLWebhookOutboxRepositoryConfig) (*MySQLWebhookOutboxRepository, error) {
@@ -74,8 +100,22 @@ func NewMySQLWebhookOutboxRepository(config MySQLWebhookOutboxRepositoryConfig)
 	if config.Database == nil || config.Random == nil || config.MaximumAttempts > 100 {
 		return nil, fmt.Errorf("webhook database, random source, and maximum attempts from 1 to 100 are required")
 	}
+	if config.DeliveryWindow == 0 {
+		config.DeliveryWindow = 24 * time.Hour
+	}
+	if config.DeliveryWindow < time.Minute || config.DeliveryWindow > 7*24*time.Hour {
+		return nil, fmt.Errorf("webhook delivery window must be between one minute and seven days")
+	}
+	var cursor *WebhookDeliveryCursorCodec
+	if len(config.CursorKey) > 0 {
+		var err error
+		if cursor, err = NewWebhookDeliveryCursorCodec(config.CursorKey); err != nil {
+			return nil, err
+		}
+	}
 	return &MySQLWebhookOutboxRepository{
 		database: config.Database, random: config.Random, maximumAttempts: config.MaximumAttempts,
+		cursor: cursor, deliveryWindow: config.DeliveryWindow,
 	}, nil
 }
 
@@ -119,13 +159,18 @@ func (repository *MySQLWebhookOutboxRepository) claimWebhook(
 	var tenantStatus string
 	var callbackDisabled sql.NullTime
 	var allowedPort, secretVersion uint64
+	var previousCiphertext, previousNonce []byte
+	var previousVersion sql.NullInt64
 	err = tx.QueryRowContext(ctx, `
 SELECT outbox.id, outbox.event_id, outbox.event_type, outbox.payload_body,
        outbox.attempt_count, outbox.expires_at,
        tenant.external_id, tenant.status,
        callback.external_id, callback.destination_url, callback.allowed_host,
        callback.allowed_port, callback.secret_ciphertext, callback.secret_nonce,
-       callback.secret_key_version, callback.disabled_at
+       callback.secret_key_version, callback.disabled_at,
+       IF(callback.previous_secret_expires_at > ?, callback.previous_secret_ciphertext, NULL),
+       IF(callback.previous_secret_expires_at > ?, callback.previous_secret_nonce, NULL),
+       IF(callback.previous_secret_expires_at > ?, callback.previous_secret_key_version, NULL)
 FROM t_external_webhook_outbox AS outbox FORCE INDEX (idx_external_webhook_delivery)
 JOIN t_external_tenant AS tenant ON tenant.id = outbox.tenant_id
 JOIN t_external_callback AS callback
@@ -153,13 +198,14 @@ AND NOT EXISTS (
 )
 ORDER BY CASE WHEN outbox.status = 'DELIVERING' THEN outbox.lease_until ELSE outbox.next_attempt_at END,
          outbox.tenant_id, outbox.id
-LIMIT 1 FOR UPDATE SKIP LOCKED`, now, now, now, now).Scan(
+LIMIT 1 FOR UPDATE SKIP LOCKED`, now, now, now, now, now, now, now).Scan(
 		&claim.OutboxID, &claim.EventID, &claim.EventType, &claim.Body,
 		&claim.AttemptCount, &claim.ExpiresAt,
 		&claim.TenantID, &tenantStatus,
 		&claim.CallbackID, &claim.DestinationURL, &claim.AllowedHost,
 		&allowedPort, &claim.secret.Ciphertext, &claim.secret.Nonce,
 		&secretVersion, &callbackDisabled,
+		&previousCiphertext, &previousNonce, &previousVersion,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return WebhookClaim{}, false, ErrWebhookNotAvailable
@@ -227,6 +273,13 @@ WHERE id = ?`, workerID, token, leaseUntil, claim.OutboxID)
 	claim.Body = append([]byte(nil), claim.Body...)
 	claim.secret.Ciphertext = append([]byte(nil), claim.secret.Ciphertext...)
 	claim.secret.Nonce = append([]byte(nil), claim.secret.Nonce...)
+	if previousVersion.Valid && previousVersion.Int64 > 0 && previousVersion.Int64 <= 65535 {
+		claim.previousSecret = &EncryptedCallbackSecret{
+			Ciphertext: append([]byte(nil), previousCiphertext...),
+			Nonce:      append([]byte(nil), previousNonce...),
+			KeyVersion: uint16(previousVersion.Int64),
+		}
+	}
 	return claim, false, nil
 }
 
@@ -249,13 +302,14 @@ func (repository *MySQLWebhookOutboxRepository) SettleWebhook(
 	}
 	var expiresAt time.Time
 	var attemptCount uint
+	var eventType string
 	err = tx.QueryRowContext(ctx, `
-SELECT expires_at, attempt_count
+SELECT expires_at, attempt_count, event_type
 FROM t_external_webhook_outbox
 WHERE id = ? AND status = 'DELIVERING' AND attempt_count = ? AND worker_id = ?
   AND lease_token = ? AND lease_until > ?
 FOR UPDATE`, claim.OutboxID, claim.AttemptCount, claim.WorkerID, claim.LeaseToken, now).
-		Scan(&expiresAt, &attemptCount)
+		Scan(&expiresAt, &attemptCount, &eventType)
 	if errors.Is(err, sql.ErrNoRows) {
 		return ErrWebhookLeaseLost
 	}
@@ -264,19 +318,20 @@ FOR UPDATE`, claim.OutboxID, claim.AttemptCount, claim.WorkerID, claim.LeaseToke
 	}
 
 	httpStatus := nullableWebhookHTTPStatus(settlement.HTTPStatus)
+	latency := nullableWebhookLatency(settlement.Latency)
 	switch settlement.Disposition {
 	case WebhookDelivered:
 		_, err = tx.ExecContext(ctx, `
 UPDATE t_external_webhook_outbox
 SET status = 'DELIVERED', worker_id = NULL, lease_token = NULL, lease_until = NULL,
-    last_http_status = ?, last_error_code = NULL, delivered_at = ?
-WHERE id = ?`, httpStatus, now, claim.OutboxID)
+    last_http_status = ?, last_latency_ms = ?, last_error_code = NULL, delivered_at = ?
+WHERE id = ?`, httpStatus, latency, now, claim.OutboxID)
 	case WebhookPermanentFailure:
 		_, err = tx.ExecContext(ctx, `
 UPDATE t_external_webhook_outbox
 SET status = 'DEAD', worker_id = NULL, lease_token = NULL, lease_until = NULL,
-    last_http_status = ?, last_error_code = ?, dead_at = ?
-WHERE id = ?`, httpStatus, settlement.ErrorCode, now, claim.OutboxID)
+    last_http_status = ?, last_latency_ms = ?, last_error_code = ?, dead_at = ?
+WHERE id = ?`, httpStatus, latency, settlement.ErrorCode, now, claim.OutboxID)
 	case WebhookRetry:
 		retryAt := settlement.RetryAt.UTC()
 		if settlement.RetryDelay > 0 {
@@ -284,18 +339,20 @@ WHERE id = ?`, httpStatus, settlement.ErrorCode, now, claim.OutboxID)
 		} else if !retryAt.After(now) || retryAt.Sub(now) > maximumWebhookRetryAfter {
 			return ErrWebhookSettlementInvalid
 		}
-		if attemptCount >= repository.maximumAttempts || !retryAt.Before(expiresAt) {
+		// A ping reports one attempt to its caller; retrying it later would
+		// only add load to a receiver that is already failing.
+		if attemptCount >= repository.maximumAttempts || !retryAt.Before(expiresAt) || eventType == WebhookEventPing {
 			_, err = tx.ExecContext(ctx, `
 UPDATE t_external_webhook_outbox
 SET status = 'DEAD', worker_id = NULL, lease_token = NULL, lease_until = NULL,
-    last_http_status = ?, last_error_code = ?, dead_at = ?
-WHERE id = ?`, httpStatus, settlement.ErrorCode, now, claim.OutboxID)
+    last_http_status = ?, last_latency_ms = ?, last_error_code = ?, dead_at = ?
+WHERE id = ?`, httpStatus, latency, settlement.ErrorCode, now, claim.OutboxID)
 		} else {
 			_, err = tx.ExecContext(ctx, `
 UPDATE t_external_webhook_outbox
 SET status = 'PENDING', worker_id = NULL, lease_token = NULL, lease_until = NULL,
-    next_attempt_at = ?, last_http_status = ?, last_error_code = ?
-WHERE id = ?`, retryAt, httpStatus, settlement.ErrorCode, claim.OutboxID)
+    next_attempt_at = ?, last_http_status = ?, last_latency_ms = ?, last_error_code = ?
+WHERE id = ?`, retryAt, httpStatus, latency, settlement.ErrorCode, claim.OutboxID)
 		}
 	}
 	if err != nil {
@@ -372,7 +429,7 @@ func validWebhookClaim(claim WebhookClaim) bool {
 
 func validWebhookSettlement(settlement WebhookSettlement) bool {
 	statusValid := settlement.HTTPStatus == 0 || settlement.HTTPStatus >= 100 && settlement.HTTPStatus <= 599
-	if !statusValid {
+	if !statusValid || settlement.Latency < 0 || settlement.Latency > 15*time.Minute {
 		return false
 	}
 	switch settlement.Disposition {
@@ -411,6 +468,13 @@ func permanentWebhookHTTPStatus(status int) bool {
 	return status >= 300 && status <= 499 && !retryableWebhookHTTPStatus(status)
 }
 
+func nullableWebhookLatency(latency time.Duration) any {
+	if latency <= 0 {
+		return nil
+	}
+	return latency.Milliseconds()
+}
+
 func nullableWebhookHTTPStatus(status int) any {
 	if status == 0 {
 		return nil
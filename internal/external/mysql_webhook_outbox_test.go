@@ -47,7 +47,7 @@ func TestMySQLWebhookEndToEndHMACAndAtLeastOnceRecovery(t *testing.T) {
 	}
 	bundleID := strings.Repeat("b", 26)
 	insertWebhookContractBundle(t, database, tenantID, bundleID)
-	callback, err := provisioner.CreateCallback(context.Background(), tenantID, "https://webhook.example.test/judge")
+	callback, err := provisioner.CreateCallback(context.Background(), tenantID, "https://webhook.example.test/judge", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
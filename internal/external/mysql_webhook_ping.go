@@ -0,0 +1,155 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
+
+// PingCallback queues one judge.ping event for an enabled callback and waits
+// up to wait for the webhook worker to settle it. The ping travels through
+// the same outbox, SSRF-safe transport, and v1 signature as terminal events
+// but is attempted only once. When wait elapses first the returned ping is
+// still PENDING or DELIVERING and its outcome appears in the delivery log.
+func (repository *MySQLWebhookOutboxRepository) PingCallback(
+	ctx context.Context,
+	tenantID string,
+	callbackID string,
+	wait time.Duration,
+) (WebhookPing, error) {
+	if repository == nil || !externalIDPattern.MatchString(tenantID) || wait < 0 || wait > MaximumWebhookPingWait {
+		return WebhookPing{}, ErrWebhookSettlementInvalid
+	}
+	if !externalIDPattern.MatchString(callbackID) {
+		return WebhookPing{}, ErrCallbackNotFound
+	}
+	outboxID, err := repository.enqueuePing(ctx, tenantID, callbackID)
+	if err != nil {
+		return WebhookPing{}, err
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(webhookPingPollInterval)
+	defer ticker.Stop()
+	for {
+		ping, err := repository.readPing(ctx, outboxID)
+		if err != nil || ping.Settled() {
+			return ping, err
+		}
+		select {
+		case <-ctx.Done():
+			return WebhookPing{}, repositoryUnavailable("wait for webhook ping", context.Cause(ctx))
+		case <-deadline.C:
+			return ping, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (repository *MySQLWebhookOutboxRepository) enqueuePing(ctx context.Context, tenantID, callbackID string) (uint64, error) {
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, repositoryUnavailable("begin webhook ping", err)
+	}
+	defer tx.Rollback()
+	now, err := mysqlCurrentTime(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	// Locking the callback row serializes pings of one callback, so the
+	// in-progress check below cannot be raced by a concurrent request.
+	var tenantInternalID, callbackInternalID uint64
+	err = tx.QueryRowContext(ctx, `
+SELECT tenant.id, callback.id
+FROM t_external_callback AS callback
+JOIN t_external_tenant AS tenant ON tenant.id = callback.tenant_id
+WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'
+  AND callback.external_id = ? AND callback.disabled_at IS NULL
+FOR UPDATE OF callback`, tenantID, callbackID).Scan(&tenantInternalID, &callbackInternalID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrCallbackNotFound
+	}
+	if err != nil {
+		return 0, repositoryUnavailable("lock pinged callback", err)
+	}
+	var active int
+	if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM t_external_webhook_outbox
+WHERE callback_id = ? AND tenant_id = ? AND event_type = ? AND status IN ('PENDING', 'DELIVERING')`,
+		callbackInternalID, tenantInternalID, WebhookEventPing).Scan(&active); err != nil {
+		return 0, repositoryUnavailable("count active webhook pings", err)
+	}
+	if active > 0 {
+		return 0, ErrWebhookPingInProgress
+	}
+	const maximumEventIDAttempts = 8
+	for attempt := 0; attempt < maximumEventIDAttempts; attempt++ {
+		eventID, err := generateExternalID(repository.random)
+		if err != nil {
+			return 0, repositoryUnavailable("generate webhook ping event ID", err)
+		}
+		eventType, payloadJSON, payloadBody, err := EncodePingWebhookEvent(PingWebhookEvent{
+			EventID: eventID, OccurredAt: now, TenantID: tenantID, CallbackID: callbackID,
+		})
+		if err != nil {
+			return 0, repositoryUnavailable("encode webhook ping", err)
+		}
+		result, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_webhook_outbox(
+    event_id, tenant_id, job_id, callback_id, event_type,
+    payload_json, payload_body, status, attempt_count, next_attempt_at, created_at, expires_at
+)
+VALUES (?, ?, NULL, ?, ?, ?, ?, 'PENDING', 0, ?, ?, ?)`,
+			eventID, tenantInternalID, callbackInternalID, eventType,
+			payloadJSON, payloadBody, now, now, now.Add(webhookPingDeliveryWindow))
+		var mysqlError *mysqlDriver.MySQLError
+		if errors.As(err, &mysqlError) && mysqlError.Number == 1062 {
+			continue
+		}
+		if err != nil {
+			return 0, repositoryUnavailable("persist webhook ping", err)
+		}
+		outboxID, err := result.LastInsertId()
+		if err != nil || outboxID <= 0 {
+			return 0, repositoryUnavailable("read webhook ping identity", fmt.Errorf("insert ID %d: %v", outboxID, err))
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, repositoryUnavailable("commit webhook ping", err)
+		}
+		return uint64(outboxID), nil
+	}
+	return 0, repositoryUnavailable("persist webhook ping", fmt.Errorf("event ID collision budget exhausted"))
+}
+
+func (repository *MySQLWebhookOutboxRepository) readPing(ctx context.Context, outboxID uint64) (WebhookPing, error) {
+	var ping WebhookPing
+	var httpStatus, latency sql.NullInt64
+	var errorCode sql.NullString
+	err := repository.database.QueryRowContext(ctx, `
+SELECT outbox.event_id, callback.external_id, outbox.status,
+       outbox.last_http_status, outbox.last_latency_ms, outbox.last_error_code
+FROM t_external_webhook_outbox AS outbox
+JOIN t_external_callback AS callback ON callback.id = outbox.callback_id AND callback.tenant_id = outbox.tenant_id
+WHERE outbox.id = ?`, outboxID).Scan(&ping.EventID, &ping.CallbackID, &ping.Status, &httpStatus, &latency, &errorCode)
+	if err != nil {
+		return WebhookPing{}, repositoryUnavailable("read webhook ping", err)
+	}
+	if !ping.Settled() {
+		return WebhookPing{EventID: ping.EventID, CallbackID: ping.CallbackID, Status: ping.Status}, nil
+	}
+	if httpStatus.Valid {
+		value := int(httpStatus.Int64)
+		accepted := ping.Status == WebhookDeliveryDelivered
+		ping.HTTPStatus, ping.SignatureAccepted = &value, &accepted
+	}
+	if latency.Valid {
+		value := latency.Int64
+		ping.LatencyMillis = &value
+	}
+	ping.ErrorCode = errorCode.String
+	return ping, nil
+}
@@ -0,0 +1,95 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMySQLWebhookPingIsSignedOnceAndReportsItsOutcome(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID, bundleID, callbackID := strings.Repeat("p", 26), strings.Repeat("q", 26), strings.Repeat("r", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, callbackID, 8)
+	repository, err := NewMySQLWebhookOutboxRepository(MySQLWebhookOutboxRepositoryConfig{
+		Database: database, Random: rand.Reader, MaximumAttempts: 12, CursorKey: bytes.Repeat([]byte{9}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	pending, err := repository.PingCallback(ctx, tenantID, callbackID, 0)
+	if err != nil || pending.Status != WebhookDeliveryPending || pending.CallbackID != callbackID ||
+		pending.HTTPStatus != nil || pending.SignatureAccepted != nil {
+		t.Fatalf("pending ping = %+v error=%v", pending, err)
+	}
+	if _, err := repository.PingCallback(ctx, tenantID, callbackID, 0); !errors.Is(err, ErrWebhookPingInProgress) {
+		t.Fatalf("concurrent ping error = %v", err)
+	}
+	if _, err := repository.PingCallback(ctx, tenantID, strings.Repeat("s", 26), 0); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("unknown callback ping error = %v", err)
+	}
+
+	claim, err := repository.ClaimNextWebhook(ctx, "ping-worker", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim.EventID != pending.EventID || claim.EventType != WebhookEventPing || claim.CallbackID != callbackID ||
+		!bytes.Contains(claim.Body, []byte(`"eventType":"judge.ping"`)) || bytes.Contains(claim.Body, []byte(`"jobId"`)) {
+		t.Fatalf("ping claim event=%q type=%q body=%s", claim.EventID, claim.EventType, claim.Body)
+	}
+	if err := repository.SettleWebhook(ctx, claim, WebhookSettlement{
+		Disposition: WebhookRetry, HTTPStatus: 503, ErrorCode: WebhookErrorHTTPRetryable, RetryDelay: time.Second,
+		Latency: 42 * time.Millisecond,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertWebhookState(t, database, claim.OutboxID, "DEAD", 503, WebhookErrorHTTPRetryable, false, true)
+	failed, err := repository.readPing(ctx, claim.OutboxID)
+	if err != nil || failed.Status != WebhookDeliveryDead || failed.HTTPStatus == nil || *failed.HTTPStatus != 503 ||
+		failed.LatencyMillis == nil || *failed.LatencyMillis != 42 || failed.SignatureAccepted == nil || *failed.SignatureAccepted {
+		t.Fatalf("failed ping = %+v error=%v", failed, err)
+	}
+
+	second, err := repository.PingCallback(ctx, tenantID, callbackID, 0)
+	if err != nil || second.EventID == pending.EventID {
+		t.Fatalf("second ping = %+v error=%v", second, err)
+	}
+	claim, err = repository.ClaimNextWebhook(ctx, "ping-worker", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.SettleWebhook(ctx, claim, WebhookSettlement{
+		Disposition: WebhookDelivered, HTTPStatus: 204, Latency: 7 * time.Millisecond,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	third, err := repository.PingCallback(ctx, tenantID, callbackID, time.Second)
+	if err != nil || third.Status != WebhookDeliveryPending {
+		t.Fatalf("third ping = %+v error=%v", third, err)
+	}
+	accepted, err := repository.readPing(ctx, claim.OutboxID)
+	if err != nil || accepted.Status != WebhookDeliveryDelivered || accepted.SignatureAccepted == nil || !*accepted.SignatureAccepted ||
+		*accepted.HTTPStatus != 204 || *accepted.LatencyMillis != 7 || accepted.ErrorCode != "" {
+		t.Fatalf("accepted ping = %+v error=%v", accepted, err)
+	}
+
+	log, err := repository.ListWebhookDeliveries(ctx, tenantID, WebhookDeliveryListOptions{Limit: 10})
+	if err != nil || len(log.Items) != 3 || log.Items[1].EventID != second.EventID || log.Items[1].JobID != "" ||
+		log.Items[1].EventType != WebhookEventPing || log.Items[1].LastLatencyMillis == nil {
+		t.Fatalf("delivery log = %+v error=%v", log, err)
+	}
+	if _, err := database.Exec("UPDATE t_external_callback SET disabled_at = CURRENT_TIMESTAMP(3) WHERE external_id = ?", callbackID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("UPDATE t_external_webhook_outbox SET status = 'DEAD', dead_at = CURRENT_TIMESTAMP(3) WHERE status = 'PENDING'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.PingCallback(ctx, tenantID, callbackID, 0); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("disabled callback ping error = %v", err)
+	}
+}
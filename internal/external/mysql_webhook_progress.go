@@ -0,0 +1,226 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
+
+// WebhookErrorSuperseded dead-letters a started or progress event that a
+// newer event for the same job made obsolete before it was delivered.
+const WebhookErrorSuperseded = "superseded"
+
+const (
+	// webhookProgressCoalesceDelay holds a new progress event back so the
+	// reports that follow it within the delay rewrite the same undelivered
+	// row instead of queueing one delivery each.
+	webhookProgressCoalesceDelay = 2 * time.Second
+	// webhookProgressDeliveryWindow is short because a late progress report
+	// is worthless once the job has moved on.
+	webhookProgressDeliveryWindow = time.Hour
+)
+
+type optionalWebhookTarget struct {
+	tenantID   uint64
+	callbackID uint64
+	totalCases int
+}
+
+// optionalWebhookTargetFor returns the enabled callback of a job when it
+// subscribes to eventType. Jobs without such a callback report false.
+func optionalWebhookTargetFor(ctx context.Context, tx *sql.Tx, jobID uint64, eventType string) (optionalWebhookTarget, bool, error) {
+	var target optionalWebhookTarget
+	err := tx.QueryRowContext(ctx, `
+SELECT job.tenant_id, callback.id, bundle.case_count
+FROM t_external_job AS job
+JOIN t_external_callback AS callback ON callback.id = job.callback_id AND callback.tenant_id = job.tenant_id
+JOIN t_external_bundle AS bundle ON bundle.id = job.bundle_id AND bundle.tenant_id = job.tenant_id
+WHERE job.id = ? AND callback.disabled_at IS NULL
+  AND FIND_IN_SET(?, callback.optional_event_types) > 0`, jobID, eventType).
+		Scan(&target.tenantID, &target.callbackID, &target.totalCases)
+	if errors.Is(err, sql.ErrNoRows) {
+		return optionalWebhookTarget{}, false, nil
+	}
+	if err != nil {
+		return optionalWebhookTarget{}, false, repositoryUnavailable("read webhook subscription", err)
+	}
+	return target, true, nil
+}
+
+// insertJobStartedWebhookEvent queues judge.job.started in the claim
+// transaction that moved the job to RUNNING, once per attempt.
+func (repository *MySQLJobRepository) insertJobStartedWebhookEvent(
+	ctx context.Context,
+	tx *sql.Tx,
+	now time.Time,
+	job ExternalJobRecord,
+	attemptNo uint32,
+) error {
+	target, subscribed, err := optionalWebhookTargetFor(ctx, tx, job.InternalID, WebhookEventJobStarted)
+	if err != nil || !subscribed {
+		return err
+	}
+	return repository.insertOptionalWebhookEvent(ctx, tx, target, now, now, JobProgressWebhookEvent{
+		EventType: WebhookEventJobStarted, OccurredAt: now, Job: job, AttemptNo: attemptNo,
+	})
+}
+
+// recordJobProgressWebhookEvent queues or refreshes judge.job.progress in the
+// RecordProgress transaction. A progress row that has never been attempted is
+// rewritten in place under its original event ID, so every report made
+// during the coalescing delay yields a single delivery of the latest counts.
+// Once that row is leased the next report starts a new row and dead-letters
+// any older progress row still waiting for a retry. The counts are carried
+// forward from the newest progress row of the attempt plus the report being
+// recorded, so only the first report of an attempt reads its job events.
+func (repository *MySQLJobRepository) recordJobProgressWebhookEvent(
+	ctx context.Context,
+	tx *sql.Tx,
+	now time.Time,
+	claim WorkerJobClaim,
+	progress JobProgress,
+) error {
+	target, subscribed, err := optionalWebhookTargetFor(ctx, tx, claim.Job.InternalID, WebhookEventJobProgress)
+	if err != nil || !subscribed {
+		return err
+	}
+	event := JobProgressWebhookEvent{
+		EventType: WebhookEventJobProgress, OccurredAt: now, Job: claim.Job, AttemptNo: claim.AttemptNo,
+		TotalCases: target.totalCases,
+	}
+	carried, err := latestJobProgressCounts(ctx, tx, claim.Job.InternalID, claim.AttemptNo)
+	if err != nil {
+		return err
+	}
+	if carried != nil {
+		event.CompileStatus, event.CompletedCases = carried.CompileStatus, len(progress.Cases)
+		if carried.CompletedCases != nil {
+			event.CompletedCases += *carried.CompletedCases
+		}
+		if progress.CompileStatus != "" {
+			event.CompileStatus = progress.CompileStatus
+		}
+	} else if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(CASE WHEN event_type = 'CASE' THEN 1 END),
+       COALESCE(MAX(CASE WHEN event_type = 'COMPILE' THEN JSON_UNQUOTE(JSON_EXTRACT(payload_json, '$.compileStatus')) END), '')
+FROM t_external_job_event
+WHERE job_id = ? AND attempt_no = ?`, claim.Job.InternalID, claim.AttemptNo).
+		Scan(&event.CompletedCases, &event.CompileStatus); err != nil {
+		return repositoryUnavailable("count webhook progress", err)
+	}
+
+	var pendingID uint64
+	err = tx.QueryRowContext(ctx, `
+SELECT id, event_id
+FROM t_external_webhook_outbox
+WHERE job_id = ? AND event_type = ? AND status = 'PENDING' AND attempt_count = 0
+ORDER BY id DESC
+LIMIT 1 FOR UPDATE`, claim.Job.InternalID, WebhookEventJobProgress).Scan(&pendingID, &event.EventID)
+	if err == nil {
+		_, payloadJSON, payloadBody, encodeErr := EncodeJobProgressWebhookEvent(event)
+		if encodeErr != nil {
+			return repositoryUnavailable("encode job progress webhook event", encodeErr)
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE t_external_webhook_outbox
+SET payload_json = ?, payload_body = ?
+WHERE id = ? AND status = 'PENDING' AND attempt_count = 0`, payloadJSON, payloadBody, pendingID); err != nil {
+			return repositoryUnavailable("coalesce job progress webhook event", err)
+		}
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return repositoryUnavailable("lock pending job progress webhook event", err)
+	}
+	if err := supersedeOptionalWebhookEvents(ctx, tx, now, claim.Job.InternalID, WebhookEventJobProgress); err != nil {
+		return err
+	}
+	return repository.insertOptionalWebhookEvent(ctx, tx, target, now, now.Add(webhookProgressCoalesceDelay), event)
+}
+
+// latestJobProgressCounts returns the payload of the newest progress row of
+// the attempt, or nil before the attempt's first report. Reports of one
+// claim are serialized by the claim lock, so that row already counts every
+// earlier report.
+func latestJobProgressCounts(ctx context.Context, tx *sql.Tx, jobID uint64, attemptNo uint32) (*jobProgressWebhookPayload, error) {
+	var payloadJSON []byte
+	err := tx.QueryRowContext(ctx, `
+SELECT payload_json
+FROM t_external_webhook_outbox
+WHERE job_id = ? AND event_type = ?
+ORDER BY id DESC
+LIMIT 1`, jobID, WebhookEventJobProgress).Scan(&payloadJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, repositoryUnavailable("read latest job progress webhook event", err)
+	}
+	var payload jobProgressWebhookPayload
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return nil, repositoryUnavailable("decode latest job progress webhook event", err)
+	}
+	if payload.Attempt != attemptNo {
+		return nil, nil
+	}
+	return &payload, nil
+}
+
+func (repository *MySQLJobRepository) insertOptionalWebhookEvent(
+	ctx context.Context,
+	tx *sql.Tx,
+	target optionalWebhookTarget,
+	now, nextAttemptAt time.Time,
+	event JobProgressWebhookEvent,
+) error {
+	const maximumEventIDAttempts = 8
+	for attempt := 0; attempt < maximumEventIDAttempts; attempt++ {
+		eventID, err := generateExternalID(repository.random)
+		if err != nil {
+			return repositoryUnavailable("generate job progress webhook event ID", err)
+		}
+		event.EventID = eventID
+		eventType, payloadJSON, payloadBody, err := EncodeJobProgressWebhookEvent(event)
+		if err != nil {
+			return repositoryUnavailable("encode job progress webhook event", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO t_external_webhook_outbox(
+    event_id, tenant_id, job_id, callback_id, event_type,
+    payload_json, payload_body, status, attempt_count, next_attempt_at, created_at, expires_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, 'PENDING', 0, ?, ?, ?)`,
+			eventID, target.tenantID, event.Job.InternalID, target.callbackID, eventType,
+			payloadJSON, payloadBody, nextAttemptAt, now, now.Add(webhookProgressDeliveryWindow))
+		if err == nil {
+			return nil
+		}
+		// Only the event-id key can collide: non-terminal rows have a NULL
+		// terminal_job_id.
+		var mysqlError *mysqlDriver.MySQLError
+		if !errors.As(err, &mysqlError) || mysqlError.Number != 1062 {
+			return repositoryUnavailable("persist job progress webhook event", err)
+		}
+	}
+	return repositoryUnavailable("persist job progress webhook event", fmt.Errorf("event ID collision budget exhausted"))
+}
+
+// supersedeOptionalWebhookEvents dead-letters the job's queued events of the
+// given types. Rows being delivered keep their lease and settle normally.
+func supersedeOptionalWebhookEvents(ctx context.Context, tx *sql.Tx, now time.Time, jobID uint64, eventTypes ...string) error {
+	for _, eventType := range eventTypes {
+		if _, err := tx.ExecContext(ctx, `
+UPDATE t_external_webhook_outbox
+SET status = 'DEAD', last_error_code = ?, dead_at = ?
+WHERE job_id = ? AND event_type = ? AND status = 'PENDING'`,
+			WebhookErrorSuperseded, now, jobID, eventType); err != nil {
+			return repositoryUnavailable("supersede queued job webhook events", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMySQLSubscribedCallbackReceivesStartedAndCoalescedProgressEvents(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("a", 26)
+	bundleID := strings.Repeat("b", 26)
+	callbackID := strings.Repeat("c", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, callbackID, 8)
+	if _, err := database.Exec(`UPDATE t_external_callback SET optional_event_types = 'judge.job.started,judge.job.progress' WHERE external_id = ?`, callbackID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec(`UPDATE t_external_bundle SET case_count = 3 WHERE external_id = ?`, bundleID); err != nil {
+		t.Fatal(err)
+	}
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	submitted := submitWebhookJob(t, repository, tenantID, bundleID, callbackID, "progress-webhook-01")
+	claim, err := repository.ClaimNext(context.Background(), "progress-worker", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := readJobWebhookEvents(t, database, WebhookEventJobStarted)
+	if len(started) != 1 || started[0].status != "PENDING" || started[0].payload.Attempt != 1 ||
+		started[0].payload.JobID != submitted.Job.ExternalID || started[0].payload.TotalCases != nil {
+		t.Fatalf("started events = %+v", started)
+	}
+
+	accepted := func(caseID string) DurableCaseResult {
+		return DurableCaseResult{CaseID: caseID, Verdict: "ACCEPTED", TimeMillis: 1, MemoryBytes: 1024}
+	}
+	for _, progress := range []JobProgress{
+		{CompileStatus: "SUCCEEDED"},
+		{Cases: []DurableCaseResult{accepted("case-1")}},
+	} {
+		if err := repository.RecordProgress(context.Background(), claim, progress); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coalesced := readJobWebhookEvents(t, database, WebhookEventJobProgress)
+	if len(coalesced) != 1 || coalesced[0].payload.CompileStatus != "SUCCEEDED" ||
+		*coalesced[0].payload.CompletedCases != 1 || *coalesced[0].payload.TotalCases != 3 ||
+		coalesced[0].payload.EventID != coalesced[0].eventID || coalesced[0].delaySeconds < 1 {
+		t.Fatalf("coalesced progress events = %+v", coalesced)
+	}
+
+	// Once the row has been attempted its body is frozen; the next report
+	// starts a new event and retires the retrying one.
+	if _, err := database.Exec(`UPDATE t_external_webhook_outbox SET attempt_count = 1 WHERE event_id = ?`, coalesced[0].eventID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.RecordProgress(context.Background(), claim, JobProgress{Cases: []DurableCaseResult{accepted("case-2")}}); err != nil {
+		t.Fatal(err)
+	}
+	progressed := readJobWebhookEvents(t, database, WebhookEventJobProgress)
+	if len(progressed) != 2 || progressed[0].status != "DEAD" || progressed[0].errorCode != WebhookErrorSuperseded ||
+		progressed[1].status != "PENDING" || *progressed[1].payload.CompletedCases != 2 || progressed[1].payload.CompileStatus != "SUCCEEDED" {
+		t.Fatalf("progress events after a delivery attempt = %+v", progressed)
+	}
+	// A superseded snapshot stays dead: redelivering it would arrive after
+	// the newer one and could become the next coalescing target.
+	outbox := newTestMySQLWebhookOutboxRepository(t, database, 1)
+	if _, err := outbox.RedeliverWebhook(context.Background(), tenantID, progressed[0].eventID); !errors.Is(err, ErrWebhookDeliverySuperseded) {
+		t.Fatalf("superseded redelivery error = %v", err)
+	}
+	if retired := readJobWebhookEvents(t, database, WebhookEventJobProgress); retired[0].status != "DEAD" || retired[0].errorCode != WebhookErrorSuperseded {
+		t.Fatalf("superseded event after redelivery = %+v", retired[0])
+	}
+
+	result := DurableJobResult{Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED", Cases: []DurableCaseResult{}}
+	if err := repository.Complete(context.Background(), claim, result); err != nil {
+		t.Fatal(err)
+	}
+	for _, eventType := range []string{WebhookEventJobStarted, WebhookEventJobProgress} {
+		for _, event := range readJobWebhookEvents(t, database, eventType) {
+			if event.status != "DEAD" || event.errorCode != WebhookErrorSuperseded {
+				t.Fatalf("%s event after completion = %+v", eventType, event)
+			}
+		}
+	}
+	if got := mustCount(t, database, "SELECT COUNT(*) FROM t_external_webhook_outbox WHERE terminal_job_id IS NOT NULL AND status = 'PENDING'"); got != 1 {
+		t.Fatalf("pending terminal events = %d", got)
+	}
+}
+
+func TestMySQLUnsubscribedCallbackReceivesOnlyTerminalEvents(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("a", 26)
+	bundleID := strings.Repeat("b", 26)
+	callbackID := strings.Repeat("c", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, callbackID, 8)
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	submitWebhookJob(t, repository, tenantID, bundleID, callbackID, "progress-webhook-02")
+	claim, err := repository.ClaimNext(context.Background(), "progress-worker", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.RecordProgress(context.Background(), claim, JobProgress{CompileStatus: "SUCCEEDED"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustCount(t, database, "SELECT COUNT(*) FROM t_external_webhook_outbox"); got != 0 {
+		t.Fatalf("outbox rows before completion = %d", got)
+	}
+}
+
+type storedJobWebhookEvent struct {
+	eventID      string
+	status       string
+	errorCode    string
+	delaySeconds int
+	payload      jobProgressWebhookPayload
+}
+
+func readJobWebhookEvents(t *testing.T, database interface {
+	Query(string, ...any) (*sql.Rows, error)
+}, eventType string) []storedJobWebhookEvent {
+	t.Helper()
+	rows, err := database.Query(`
+SELECT event_id, status, COALESCE(last_error_code, ''),
+       TIMESTAMPDIFF(SECOND, created_at, next_attempt_at), payload_body
+FROM t_external_webhook_outbox
+WHERE event_type = ?
+ORDER BY id`, eventType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var events []storedJobWebhookEvent
+	for rows.Next() {
+		var event storedJobWebhookEvent
+		var body []byte
+		if err := rows.Scan(&event.eventID, &event.status, &event.errorCode, &event.delaySeconds, &body); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(body, &event.payload); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
@@ -117,6 +117,19 @@ func (store *MinIOBundleObjectStore) Discard(ctx context.Context, key string) er
 	return nil
 }
 
+// Delete removes a retired bundle from its final content address. Only keys
+// of the form external/<tenant>/sha256/<digest>.zip are accepted.
+func (store *MinIOBundleObjectStore) Delete(ctx context.Context, key string) error {
+	parts := strings.Split(key, "/")
+	if store == nil || store.client == nil || store.bucket == "" || len(parts) != 4 || !validBundleObjectKey(parts[1], key) {
+		return fmt.Errorf("bundle object delete request is invalid")
+	}
+	if err := store.client.RemoveObject(ctx, store.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("delete retired immutable bundle object: %w", err)
+	}
+	return nil
+}
+
 func (store *MinIOBundleObjectStore) ListStaging(ctx context.Context, before time.Time, after string, limit int) ([]BundleStagingObject, string, error) {
 	if store == nil || store.client == nil || store.bucket == "" || before.IsZero() || limit < 1 || limit > 1000 || after != "" && !validBundleStagingContinuation(after) {
 		return nil, "", fmt.Errorf("bundle staging list is not configured")
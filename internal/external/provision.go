@@ -6,6 +6,7 @@ import (
 	"encoding/base32"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -27,6 +28,20 @@ type TenantPolicy struct {
 	MaxInfrastructureTries int   `json:"maxInfrastructureTries"`
 	MaxTimeLimitMillis     int   `json:"maxTimeLimitMillis"`
 	MaxMemoryLimitMiB      int   `json:"maxMemoryLimitMiB"`
+	// MaxJobPriority is the highest priority a submission may request.
+	// Policies written before priorities existed decode as 0.
+	MaxJobPriority int `json:"maxJobPriority"`
+	// MaxRunningRuns caps concurrent custom runs apart from judge jobs. Zero
+	// keeps the cap custom runs had before it existed: MaxRunningJobs.
+	MaxRunningRuns int `json:"maxRunningRuns"`
+}
+
+// RunningRunLimit is the effective concurrent custom run ceiling.
+func (policy TenantPolicy) RunningRunLimit() int {
+	if policy.MaxRunningRuns > 0 {
+		return policy.MaxRunningRuns
+	}
+	return policy.MaxRunningJobs
 }
 
 func (policy TenantPolicy) validate() error {
@@ -42,15 +57,25 @@ func (policy TenantPolicy) validate() error {
 	if policy.MaxTimeLimitMillis <= 0 || policy.MaxMemoryLimitMiB <= 0 {
 		return fmt.Errorf("maximum execution time and memory limits must be positive")
 	}
+	if policy.MaxJobPriority < 0 || policy.MaxJobPriority > MaximumJobPriority {
+		return fmt.Errorf("maximum job priority must be between 0 and %d", MaximumJobPriority)
+	}
+	if policy.MaxRunningRuns < 0 {
+		return fmt.Errorf("running custom run limit must not be negative")
+	}
 	return nil
 }
 
 type provisionExecutor interface {
 	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
 }
 
 type Provisioner struct {
-	executor         provisionExecutor
+	executor provisionExecutor
+	// database runs the multi-statement operator changes that must commit
+	// together with their audit row.
+	database         *sql.DB
 	random           io.Reader
 	now              func() time.Time
 	callbackCipher   *CallbackCipher
@@ -83,7 +108,7 @@ func NewProvisioner(database *sql.DB, random io.Reader, options ...ProvisionerOp
 	if database == nil || random == nil {
 		return nil, fmt.Errorf("provisioning database and cryptographic random source are required")
 	}
-	provisioner := &Provisioner{executor: database, random: random, now: time.Now, callbackResolver: net.DefaultResolver}
+	provisioner := &Provisioner{executor: database, database: database, random: random, now: time.Now, callbackResolver: net.DefaultResolver}
 	for _, option := range options {
 		if option == nil {
 			return nil, fmt.Errorf("provisioner option is required")
@@ -95,13 +120,15 @@ func NewProvisioner(database *sql.DB, random io.Reader, options ...ProvisionerOp
 	return provisioner, nil
 }
 
+// CreateTenant inserts an active tenant and records its initial name and
+// policy as a tenant.created audit entry in the same transaction.
 func (provisioner *Provisioner) CreateTenant(ctx context.Context, name string, policy TenantPolicy) (string, error) {
-	if provisioner == nil || provisioner.executor == nil || provisioner.random == nil {
+	if provisioner == nil || provisioner.database == nil || provisioner.random == nil {
 		return "", fmt.Errorf("provisioner is not configured")
 	}
 	name = strings.TrimSpace(name)
-	if len(name) < 2 || len(name) > 128 {
-		return "", fmt.Errorf("tenant name must contain 2 to 128 bytes")
+	if err := validTenantName(name); err != nil {
+		return "", err
 	}
 	if err := policy.validate(); err != nil {
 		return "", err
@@ -114,11 +141,29 @@ func (provisioner *Provisioner) CreateTenant(ctx context.Context, name string, p
 	if err != nil {
 		return "", fmt.Errorf("encode tenant policy: %w", err)
 	}
-	if _, err := provisioner.executor.ExecContext(ctx,
+	tx, err := provisioner.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return "", fmt.Errorf("begin tenant creation: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	result, err := tx.ExecContext(ctx,
 		"INSERT INTO t_external_tenant(external_id, name, status, policy_json) VALUES (?, ?, 'ACTIVE', ?)",
-		tenantID, name, encodedPolicy); err != nil {
+		tenantID, name, encodedPolicy)
+	if err != nil {
 		return "", fmt.Errorf("create tenant: %w", err)
 	}
+	internalID, err := result.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("confirm tenant creation: %w", err)
+	}
+	if err := insertAdminAudit(ctx, tx, uint64(internalID), "tenant.created", tenantID, map[string]tenantRevision{
+		"after": {Name: name, Policy: policy},
+	}); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("commit tenant creation: %w", err)
+	}
 	return tenantID, nil
 }
 
@@ -172,48 +217,48 @@ WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'`,
 	return material, nil
 }
 
-func (provisioner *Provisioner) CreateCallback(ctx context.Context, tenantID, rawDestination string) (CallbackMaterial, error) {
+// CreateCallback registers an HTTPS destination for the tenant. eventTypes
+// subscribes it to optional events (see OptionalWebhookEventTypes) on top of
+// the terminal job events every callback receives.
+func (provisioner *Provisioner) CreateCallback(ctx context.Context, tenantID, rawDestination string, eventTypes []string) (CallbackMaterial, error) {
 	if provisioner == nil || provisioner.executor == nil || provisioner.random == nil || provisioner.callbackCipher == nil || provisioner.callbackResolver == nil {
 		return CallbackMaterial{}, fmt.Errorf("callback provisioner is not configured")
 	}
 	if !externalIDPattern.MatchString(tenantID) {
 		return CallbackMaterial{}, fmt.Errorf("tenant ID is invalid")
 	}
-	destination, err := CanonicalCallbackDestination(rawDestination)
+	subscribed, storedEventTypes, err := canonicalCallbackEventTypes(eventTypes)
 	if err != nil {
 		return CallbackMaterial{}, err
 	}
-	if _, err := resolvePublicCallback(ctx, provisioner.callbackResolver, destination.Host); err != nil {
-		return CallbackMaterial{}, fmt.Errorf("validate callback public destination: %w", err)
+	destination, err := provisioner.publicCallbackDestination(ctx, rawDestination)
+	if err != nil {
+		return CallbackMaterial{}, err
 	}
 	callbackID, err := generateExternalID(provisioner.random)
 	if err != nil {
 		return CallbackMaterial{}, err
 	}
-	secretEntropy := make([]byte, 32)
-	if _, err := io.ReadFull(provisioner.random, secretEntropy); err != nil {
-		return CallbackMaterial{}, fmt.Errorf("generate callback secret: %w", err)
-	}
-	secret := "croj_whsec_" + base64.RawURLEncoding.EncodeToString(secretEntropy)
-	clear(secretEntropy)
-	secretBytes := []byte(secret)
-	encrypted, err := provisioner.callbackCipher.Encrypt(tenantID, callbackID, destination.URL, secretBytes)
-	clear(secretBytes)
+	secret, encrypted, err := provisioner.newCallbackSecret(tenantID, callbackID, destination.URL)
 	if err != nil {
 		return CallbackMaterial{}, err
 	}
 	defer clear(encrypted.Ciphertext)
 	defer clear(encrypted.Nonce)
+	// INSERT ... SELECT share-locks the counted callback range, so concurrent
+	// creations for one tenant serialize instead of overshooting the limit.
 	result, err := provisioner.executor.ExecContext(ctx, `
 INSERT INTO t_external_callback(
     external_id, tenant_id, destination_url, allowed_host, allowed_port,
-    secret_ciphertext, secret_nonce, secret_key_version
+    secret_ciphertext, secret_nonce, secret_key_version, optional_event_types
 )
-SELECT ?, tenant.id, ?, ?, ?, ?, ?, ?
+SELECT ?, tenant.id, ?, ?, ?, ?, ?, ?, ?
 FROM t_external_tenant AS tenant
-WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'`,
+WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'
+  AND (SELECT COUNT(*) FROM t_external_callback AS active
+       WHERE active.tenant_id = tenant.id AND active.disabled_at IS NULL) < ?`,
 		callbackID, destination.URL, destination.Host, destination.Port,
-		encrypted.Ciphertext, encrypted.Nonce, encrypted.KeyVersion, tenantID)
+		encrypted.Ciphertext, encrypted.Nonce, encrypted.KeyVersion, storedEventTypes, tenantID, MaximumActiveCallbacks)
 	if err != nil {
 		return CallbackMaterial{}, fmt.Errorf("create callback: %w", err)
 	}
@@ -222,9 +267,70 @@ WHERE tenant.external_id = ? AND tenant.status = 'ACTIVE'`,
 		return CallbackMaterial{}, fmt.Errorf("confirm callback creation: %w", err)
 	}
 	if affected != 1 {
-		return CallbackMaterial{}, fmt.Errorf("tenant does not exist or is disabled")
+		return CallbackMaterial{}, provisioner.callbackCreationRefusal(ctx, tenantID)
+	}
+	return CallbackMaterial{CallbackID: callbackID, Destination: destination.URL, Secret: secret, EventTypes: subscribed}, nil
+}
+
+// callbackCreationRefusal explains why the guarded callback insert matched no
+// tenant row: the tenant is missing, disabled, or at its callback limit.
+func (provisioner *Provisioner) callbackCreationRefusal(ctx context.Context, tenantID string) error {
+	rows, err := provisioner.executor.QueryContext(ctx, "SELECT status FROM t_external_tenant WHERE external_id = ?", tenantID)
+	if err != nil {
+		return fmt.Errorf("read callback tenant: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("read callback tenant: %w", err)
+		}
+		return ErrTenantNotFound
+	}
+	var status TenantStatus
+	if err := rows.Scan(&status); err != nil {
+		return fmt.Errorf("read callback tenant: %w", err)
+	}
+	if status != TenantActive {
+		return ErrTenantDisabled
+	}
+	return fmt.Errorf("%w: tenant already has %d active callbacks", ErrCallbackLimitReached, MaximumActiveCallbacks)
+}
+
+// publicCallbackDestination canonicalizes a callback URL and checks that its
+// host currently resolves only to public addresses.
+func (provisioner *Provisioner) publicCallbackDestination(ctx context.Context, rawDestination string) (CallbackDestination, error) {
+	destination, err := CanonicalCallbackDestination(rawDestination)
+	if err != nil {
+		return CallbackDestination{}, fmt.Errorf("%w: %w", ErrInvalidCallbackDestination, err)
+	}
+	if len(destination.URL) > maximumCallbackDestinationBytes {
+		return CallbackDestination{}, fmt.Errorf("%w: canonical destination exceeds %d bytes", ErrInvalidCallbackDestination, maximumCallbackDestinationBytes)
+	}
+	if _, err := resolvePublicCallback(ctx, provisioner.callbackResolver, destination.Host); err != nil {
+		if !errors.Is(err, ErrUnsafeCallbackDestination) {
+			err = fmt.Errorf("%w: %w", ErrInvalidCallbackDestination, err)
+		}
+		return CallbackDestination{}, fmt.Errorf("validate callback public destination: %w", err)
+	}
+	return destination, nil
+}
+
+// newCallbackSecret generates a signing secret and encrypts it for the
+// callback identity and canonical destination that form its AAD.
+func (provisioner *Provisioner) newCallbackSecret(tenantID, callbackID, destination string) (string, EncryptedCallbackSecret, error) {
+	secretEntropy := make([]byte, 32)
+	if _, err := io.ReadFull(provisioner.random, secretEntropy); err != nil {
+		return "", EncryptedCallbackSecret{}, fmt.Errorf("generate callback secret: %w", err)
+	}
+	secret := "croj_whsec_" + base64.RawURLEncoding.EncodeToString(secretEntropy)
+	clear(secretEntropy)
+	secretBytes := []byte(secret)
+	encrypted, err := provisioner.callbackCipher.Encrypt(tenantID, callbackID, destination, secretBytes)
+	clear(secretBytes)
+	if err != nil {
+		return "", EncryptedCallbackSecret{}, err
 	}
-	return CallbackMaterial{CallbackID: callbackID, Secret: secret}, nil
+	return secret, encrypted, nil
 }
 
 func generateExternalID(random io.Reader) (string, error) {
@@ -6,15 +6,21 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/netip"
 	"strings"
 	"testing"
 	"time"
 )
 
-func TestProvisionerCreatesTenantWithValidatedPolicy(t *testing.T) {
-	executor := &provisionExecutorStub{affected: 1}
-	provisioner := &Provisioner{executor: executor, random: bytes.NewReader(bytes.Repeat([]byte{0x11}, externalIDRandomBytes))}
+func TestProvisionerCreatesTenantWithValidatedPolicyAndAudit(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	provisioner, err := NewProvisioner(database, bytes.NewReader(bytes.Repeat([]byte{0x11}, externalIDRandomBytes)))
+	if err != nil {
+		t.Fatal(err)
+	}
 	policy := TenantPolicy{
 		MaxQueuedJobs:          100,
 		MaxRunningJobs:         4,
@@ -25,18 +31,27 @@ func TestProvisionerCreatesTenantWithValidatedPolicy(t *testing.T) {
 		MaxTimeLimitMillis:     10_000,
 		MaxMemoryLimitMiB:      1024,
 	}
-	tenantID, err := provisioner.CreateTenant(context.Background(), "Acme OJ", policy)
+	tenantID, err := provisioner.CreateTenant(context.Background(), "  Acme OJ ", policy)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if len(tenantID) != 26 || strings.ToLower(tenantID) != tenantID {
 		t.Fatalf("tenant id = %q", tenantID)
 	}
-	if !strings.Contains(strings.ToLower(executor.query), "insert into t_external_tenant") || len(executor.arguments) != 3 {
-		t.Fatalf("execution: %s %#v", executor.query, executor.arguments)
+	details, err := provisioner.GetTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if executor.arguments[0] != tenantID || executor.arguments[1] != "Acme OJ" || !json.Valid(executor.arguments[2].([]byte)) {
-		t.Fatalf("arguments = %#v", executor.arguments)
+	if details.Name != "Acme OJ" || details.Status != TenantActive || details.Policy != policy || len(details.RecentAudit) != 1 {
+		t.Fatalf("tenant = %+v", details)
+	}
+	created := details.RecentAudit[0]
+	var revision struct {
+		After tenantRevision `json:"after"`
+	}
+	if created.Action != "tenant.created" || created.SubjectID != tenantID ||
+		json.Unmarshal(created.Detail, &revision) != nil || revision.After.Name != "Acme OJ" || revision.After.Policy != policy {
+		t.Fatalf("creation audit = %+v", created)
 	}
 }
 
@@ -52,7 +67,8 @@ func TestProvisionerCreatesEncryptedCallbackForPublicDestination(t *testing.T) {
 		callbackCipher:   callbackCipher,
 		callbackResolver: callbackResolverStub{addresses: []netip.Addr{netip.MustParseAddr("8.8.8.8")}},
 	}
-	material, err := provisioner.CreateCallback(context.Background(), "ceirceirceirceirceirceirce", "HTTPS://OJ.Example.com/hooks?b=2&a=1")
+	material, err := provisioner.CreateCallback(context.Background(), "ceirceirceirceirceirceirce", "HTTPS://OJ.Example.com/hooks?b=2&a=1",
+		[]string{WebhookEventJobProgress, WebhookEventJobStarted})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -62,7 +78,10 @@ func TestProvisionerCreatesEncryptedCallbackForPublicDestination(t *testing.T) {
 	if !strings.Contains(strings.ToLower(executor.query), "insert into t_external_callback") || !strings.Contains(strings.ToLower(executor.query), "tenant.status = 'active'") {
 		t.Fatalf("query = %s", executor.query)
 	}
-	if len(executor.arguments) != 8 || executor.arguments[0] != material.CallbackID || executor.arguments[1] != "https://oj.example.com:443/hooks?a=1&b=2" || executor.arguments[2] != "oj.example.com" || executor.arguments[3] != uint16(443) || executor.arguments[6] != uint16(7) || executor.arguments[7] != "ceirceirceirceirceirceirce" {
+	if fmt.Sprint(material.EventTypes) != "[judge.job.started judge.job.progress]" || executor.arguments[7] != "judge.job.started,judge.job.progress" {
+		t.Fatalf("event types = %v stored=%#v", material.EventTypes, executor.arguments[7])
+	}
+	if len(executor.arguments) != 10 || executor.arguments[9] != MaximumActiveCallbacks || material.Destination != "https://oj.example.com:443/hooks?a=1&b=2" || executor.arguments[0] != material.CallbackID || executor.arguments[1] != "https://oj.example.com:443/hooks?a=1&b=2" || executor.arguments[2] != "oj.example.com" || executor.arguments[3] != uint16(443) || executor.arguments[6] != uint16(7) || executor.arguments[8] != "ceirceirceirceirceirceirce" {
 		t.Fatalf("arguments = %#v", executor.arguments)
 	}
 	ciphertext, ok := executor.arguments[4].([]byte)
@@ -91,7 +110,7 @@ func TestProvisionerRejectsUnsafeCallbackBeforePersistence(t *testing.T) {
 				executor: executor, random: bytes.NewReader(bytes.Repeat([]byte{3}, externalIDRandomBytes+32)),
 				callbackCipher: callbackCipher, callbackResolver: resolver,
 			}
-			if _, err := provisioner.CreateCallback(context.Background(), "ceirceirceirceirceirceirce", "https://oj.example.com/hook"); err == nil || executor.query != "" {
+			if _, err := provisioner.CreateCallback(context.Background(), "ceirceirceirceirceirceirce", "https://oj.example.com/hook", nil); err == nil || executor.query != "" {
 				t.Fatalf("error=%v query=%q", err, executor.query)
 			}
 		})
@@ -141,8 +160,7 @@ func TestProvisionerRejectsUnsafePolicyScopesAndUnknownTenant(t *testing.T) {
 		"zero max memory":    {MaxQueuedJobs: 1, MaxRunningJobs: 1, MaxSourceBytes: 1, MaxRetainedBundles: 1, DailyExecutionMillis: 1, MaxInfrastructureTries: 1, MaxTimeLimitMillis: 1},
 	} {
 		t.Run(name, func(t *testing.T) {
-			provisioner := &Provisioner{executor: &provisionExecutorStub{affected: 1}, random: bytes.NewReader(make([]byte, externalIDRandomBytes))}
-			if _, err := provisioner.CreateTenant(context.Background(), "Acme OJ", policy); err == nil {
+			if err := policy.validate(); err == nil {
 				t.Fatal("expected policy rejection")
 			}
 		})
@@ -155,6 +173,14 @@ func TestProvisionerRejectsUnsafePolicyScopesAndUnknownTenant(t *testing.T) {
 	if err := validPolicy.validate(); err != nil {
 		t.Fatalf("maximum supported source size was rejected: %v", err)
 	}
+	validPolicy.MaxJobPriority = MaximumJobPriority + 1
+	if err := validPolicy.validate(); err == nil {
+		t.Fatal("policy accepted a priority class above the schema bound")
+	}
+	validPolicy.MaxJobPriority = MaximumJobPriority
+	if err := validPolicy.validate(); err != nil {
+		t.Fatalf("maximum priority class was rejected: %v", err)
+	}
 	unknownTenant := &Provisioner{executor: &provisionExecutorStub{affected: 0}, random: bytes.NewReader(make([]byte, apiKeyRandomBytes))}
 	if _, err := unknownTenant.CreateAPIKey(context.Background(), "ceirceirceirceirceirceirce", []Scope{ScopeJobRead}, nil, make([]byte, sha256.Size)); err == nil {
 		t.Fatal("expected unknown/disabled tenant rejection")
@@ -184,6 +210,12 @@ func (resolver callbackResolverStub) LookupNetIP(context.Context, string, string
 	return append([]netip.Addr(nil), resolver.addresses...), resolver.err
 }
 
+var errProvisionerQueryNotStubbed = errors.New("provisioner query is not stubbed")
+
+func (executor *provisionExecutorStub) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errProvisionerQueryNotStubbed
+}
+
 func (executor *provisionExecutorStub) ExecContext(_ context.Context, query string, arguments ...any) (sql.Result, error) {
 	executor.query = query
 	executor.arguments = arguments
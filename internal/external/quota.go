@@ -24,6 +24,7 @@ type QuotaKind string
 const (
 	QuotaJudgeSubmit       QuotaKind = "judge-submit"
 	QuotaBundleUploadBytes QuotaKind = "bundle-upload-bytes"
+	QuotaCustomRun         QuotaKind = "custom-run"
 )
 
 type QuotaLimit struct {
@@ -54,3 +55,9 @@ type QuotaDecision struct {
 type Quota interface {
 	Allow(context.Context, QuotaRequest) (QuotaDecision, error)
 }
+
+// QuotaInspector reads how many tokens a bucket holds without consuming
+// any. A bucket that has never been charged reports its full capacity.
+type QuotaInspector interface {
+	Available(context.Context, string, QuotaKind, QuotaLimit) (int64, error)
+}
@@ -77,6 +77,24 @@ func (quota *RedisQuota) Allow(ctx context.Context, request QuotaRequest) (Quota
 	return QuotaDecision{Allowed: allowed == 1, RetryAfter: time.Duration(retryMilliseconds) * time.Millisecond}, nil
 }
 
+// Available refills the bucket to the Redis clock without writing it, so an
+// inspection never delays the next charge.
+func (quota *RedisQuota) Available(ctx context.Context, tenantID string, kind QuotaKind, limit QuotaLimit) (int64, error) {
+	if err := validateQuotaRequest(QuotaRequest{TenantID: tenantID, Kind: kind, Cost: 1, Limit: limit}); err != nil {
+		return 0, err
+	}
+	result, err := quota.runner.Eval(ctx, redisTokenBucketLevelScript, []string{quota.key(tenantID, kind)},
+		limit.Capacity, limit.RefillPeriod.Milliseconds())
+	if err != nil {
+		return 0, ErrQuotaUnavailable
+	}
+	available, ok := quotaInteger(result)
+	if !ok || available < 0 || available > limit.Capacity {
+		return 0, ErrQuotaUnavailable
+	}
+	return available, nil
+}
+
 func quotaDependencyIsNil(dependency any) bool {
 	if dependency == nil {
 		return true
@@ -95,7 +113,7 @@ func validateQuotaRequest(request QuotaRequest) error {
 		return fmt.Errorf("%w: tenant ID is invalid", ErrQuotaInvalid)
 	}
 	switch request.Kind {
-	case QuotaJudgeSubmit, QuotaBundleUploadBytes:
+	case QuotaJudgeSubmit, QuotaBundleUploadBytes, QuotaCustomRun:
 	default:
 		return fmt.Errorf("%w: quota kind is invalid", ErrQuotaInvalid)
 	}
@@ -151,3 +169,16 @@ redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_ms', tostring(n
 redis.call('PEXPIRE', KEYS[1], math.ceil(math.max(period_ms * 2, retry_ms + period_ms)))
 return {allowed, retry_ms}
 `
+
+const redisTokenBucketLevelScript = `
+local capacity = tonumber(ARGV[1])
+local period_ms = tonumber(ARGV[2])
+local redis_time = redis.call('TIME')
+local now_ms = (tonumber(redis_time[1]) * 1000) + math.floor(tonumber(redis_time[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_ms')
+local tokens = tonumber(state[1]) or capacity
+local updated_ms = tonumber(state[2]) or now_ms
+local elapsed_ms = math.min(period_ms, math.max(0, now_ms - updated_ms))
+tokens = math.min(capacity, tokens + (elapsed_ms * capacity / period_ms))
+return math.floor(tokens)
+`
@@ -71,6 +71,9 @@ func TestRedisQuotaIsAtomicAcrossReplicas(t *testing.T) {
 	if allowed.Load() != 8 {
 		t.Fatalf("allowed=%d want=8", allowed.Load())
 	}
+	if available, err := second.Available(ctx, request.TenantID, request.Kind, request.Limit); err != nil || available != 0 {
+		t.Fatalf("available=%d error=%v", available, err)
+	}
 	decision, err := first.Allow(ctx, request)
 	if err != nil || decision.Allowed || decision.RetryAfter <= 0 {
 		t.Fatalf("exhausted decision=%+v error=%v", decision, err)
@@ -105,6 +108,9 @@ func TestRedisQuotaRefillsFromRedisTime(t *testing.T) {
 		TenantID: "tenant-refill", Kind: QuotaJudgeSubmit, Cost: 2,
 		Limit: QuotaLimit{Capacity: 2, RefillPeriod: 200 * time.Millisecond},
 	}
+	if available, err := quota.Available(context.Background(), request.TenantID, request.Kind, request.Limit); err != nil || available != 2 {
+		t.Fatalf("untouched bucket available=%d error=%v", available, err)
+	}
 	if decision, err := quota.Allow(context.Background(), request); err != nil || !decision.Allowed {
 		t.Fatalf("initial decision=%+v error=%v", decision, err)
 	}
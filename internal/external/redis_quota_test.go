@@ -107,3 +107,31 @@ func TestRedisQuotaRejectsInvalidConfigurationBeforeCallingRedis(t *testing.T) {
 		t.Fatalf("Redis was called for invalid input: %#v", runner.keys)
 	}
 }
+
+func TestRedisQuotaAvailableReadsTheSameBucketAndRejectsImpossibleLevels(t *testing.T) {
+	runner := &quotaScriptRunnerStub{result: int64(7)}
+	quota, err := NewRedisQuota(runner, "coderushoj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := QuotaLimit{Capacity: 20, RefillPeriod: time.Second}
+	available, err := quota.Available(context.Background(), "tenant-7", QuotaJudgeSubmit, limit)
+	if err != nil || available != 7 {
+		t.Fatalf("available=%d error=%v", available, err)
+	}
+	if want := quota.key("tenant-7", QuotaJudgeSubmit); len(runner.keys) != 1 || runner.keys[0] != want {
+		t.Fatalf("keys=%#v want %q", runner.keys, want)
+	}
+	if len(runner.args) != 2 || runner.args[0] != int64(20) || runner.args[1] != int64(1000) {
+		t.Fatalf("script args=%#v", runner.args)
+	}
+	for name, result := range map[string]any{"above capacity": int64(21), "negative": int64(-1), "malformed": []any{int64(1)}} {
+		runner.result = result
+		if _, err := quota.Available(context.Background(), "tenant-7", QuotaJudgeSubmit, limit); !errors.Is(err, ErrQuotaUnavailable) {
+			t.Fatalf("%s error=%v", name, err)
+		}
+	}
+	if _, err := quota.Available(context.Background(), "tenant-7", QuotaKind("other"), limit); !errors.Is(err, ErrQuotaInvalid) {
+		t.Fatalf("unknown kind error=%v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/minio/minio-go/v7"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const maximumEncryptedSourceObjectBytes = 64 << 20
@@ -34,7 +35,9 @@ func NewMinIOSourceObjectStore(client *minio.Client, bucket string) (*MinIOSourc
 	return &MinIOSourceObjectStore{client: client, bucket: bucket}, nil
 }
 
-func (store *MinIOSourceObjectStore) Create(ctx context.Context, key string, ciphertext []byte) error {
+func (store *MinIOSourceObjectStore) Create(ctx context.Context, key string, ciphertext []byte) (err error) {
+	ctx, span := startSpan(ctx, "SourceObjectStore.Create", attribute.Int("croj.source.ciphertext_bytes", len(ciphertext)))
+	defer func() { endSpan(span, err) }()
 	if store == nil || store.client == nil || !validSourceObjectKey(key) || len(ciphertext) == 0 || len(ciphertext) > maximumEncryptedSourceObjectBytes {
 		return fmt.Errorf("encrypted source object create request is invalid")
 	}
@@ -58,7 +61,9 @@ func (store *MinIOSourceObjectStore) Create(ctx context.Context, key string, cip
 	return nil
 }
 
-func (store *MinIOSourceObjectStore) Get(ctx context.Context, key string, maximumBytes int64) ([]byte, error) {
+func (store *MinIOSourceObjectStore) Get(ctx context.Context, key string, maximumBytes int64) (_ []byte, err error) {
+	ctx, span := startSpan(ctx, "SourceObjectStore.Get")
+	defer func() { endSpan(span, err) }()
 	if store == nil || store.client == nil || !validSourceObjectKey(key) || maximumBytes <= 0 || maximumBytes > maximumEncryptedSourceObjectBytes {
 		return nil, fmt.Errorf("%w: encrypted source object read request is invalid", ErrSourceEncryption)
 	}
@@ -78,7 +83,9 @@ func (store *MinIOSourceObjectStore) Get(ctx context.Context, key string, maximu
 	return payload, nil
 }
 
-func (store *MinIOSourceObjectStore) Delete(ctx context.Context, key string) error {
+func (store *MinIOSourceObjectStore) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := startSpan(ctx, "SourceObjectStore.Delete")
+	defer func() { endSpan(span, err) }()
 	if store == nil || store.client == nil || !validSourceObjectKey(key) {
 		return fmt.Errorf("encrypted source object delete request is invalid")
 	}
@@ -95,6 +95,72 @@ LIMIT ? FOR UPDATE SKIP LOCKED`, batch)
 	return affected, nil
 }
 
+// ExpireCustomRunBatch deletes up to batch custom runs that finished more
+// than retention ago, folding their settled time into the usage rollup in
+// the same transaction.
+func (repository *MySQLJobRepository) ExpireCustomRunBatch(ctx context.Context, retention time.Duration, batch int) (int64, error) {
+	if repository == nil || retention <= 0 || retention > 365*24*time.Hour || batch < 1 || batch > 1000 {
+		return 0, ErrSourceRetentionNotAvailable
+	}
+	tx, err := repository.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, repositoryUnavailable("begin custom run retention batch", err)
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, `
+SELECT id FROM t_external_run FORCE INDEX (idx_external_run_finished)
+WHERE finished_at <= CURRENT_TIMESTAMP(3) - INTERVAL ? MICROSECOND AND status <> 'RUNNING'
+ORDER BY finished_at, id
+LIMIT ? FOR UPDATE SKIP LOCKED`, retention.Microseconds(), batch)
+	if err != nil {
+		return 0, repositoryUnavailable("lock custom run retention batch", err)
+	}
+	ids := make([]uint64, 0, batch)
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, repositoryUnavailable("scan custom run retention batch", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, repositoryUnavailable("iterate custom run retention batch", err)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, repositoryUnavailable("close custom run retention batch", err)
+	}
+	if len(ids) == 0 {
+		return 0, ErrSourceRetentionNotAvailable
+	}
+	arguments := make([]any, len(ids))
+	placeholders := make([]string, len(ids))
+	for index, id := range ids {
+		arguments[index] = id
+		placeholders[index] = "?"
+	}
+	filter := "id IN (" + strings.Join(placeholders, ",") + ")"
+	if err := rollUpRetainedRunUsage(ctx, tx, filter, arguments); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM t_external_run WHERE "+filter, arguments...)
+	if err != nil {
+		return 0, repositoryUnavailable("expire custom run batch", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, repositoryUnavailable("count expired custom run batch", err)
+	}
+	if affected != int64(len(ids)) {
+		return 0, repositoryUnavailable("expire custom run batch", ErrExternalJobUnavailable)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, repositoryUnavailable("commit custom run retention batch", err)
+	}
+	return affected, nil
+}
+
 func (repository *MySQLJobRepository) ClaimSourceRetention(ctx context.Context, retention, leaseDuration time.Duration) (SourceRetentionClaim, error) {
 	if repository == nil || retention <= 0 || retention > 365*24*time.Hour || leaseDuration <= 0 || leaseDuration > 15*time.Minute {
 		return SourceRetentionClaim{}, ErrSourceRetentionNotAvailable
@@ -339,6 +405,12 @@ WHERE id = ? AND tenant_id = ? AND delete_token = ? AND delete_marked_at IS NOT
 	if err := insertRetentionAudit(ctx, tx, claim, "DELETED", now); err != nil {
 		return err
 	}
+	if err := rollUpRetainedJobUsage(ctx, tx, claim); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM t_external_job_event WHERE tenant_id = ? AND job_id = ?", claim.TenantInternalID, claim.JobInternalID); err != nil {
+		return repositoryUnavailable("delete retained job events", err)
+	}
 	if _, err := tx.ExecContext(ctx, "DELETE FROM t_external_job_attempt WHERE tenant_id = ? AND job_id = ?", claim.TenantInternalID, claim.JobInternalID); err != nil {
 		return repositoryUnavailable("delete retained attempts", err)
 	}
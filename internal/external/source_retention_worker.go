@@ -13,8 +13,18 @@ type SourceRetentionRepository interface {
 	ClaimSourceRetention(context.Context, time.Duration, time.Duration) (SourceRetentionClaim, error)
 	RecordSourceRetentionFailure(context.Context, SourceRetentionClaim, time.Duration) error
 	FinalizeSourceRetention(context.Context, SourceRetentionClaim) error
+	ExpireCustomRunBatch(context.Context, time.Duration, int) (int64, error)
+	RefundExpiredCustomRuns(context.Context, int) (int64, error)
 }
 
+const (
+	// customRunRetentionBatch bounds the custom runs one retention pass deletes.
+	customRunRetentionBatch = 1000
+	// customRunRefundTenants bounds the tenants one pass refunds expired
+	// custom run leases for.
+	customRunRefundTenants = 100
+)
+
 type SourceRetentionWorkerConfig struct {
 	Repository    SourceRetentionRepository
 	Objects       SourceObjectStore
@@ -86,6 +96,9 @@ func (worker *SourceRetentionWorker) ProcessNext(ctx context.Context) error {
 	return worker.repository.FinalizeSourceRetention(ctx, claim)
 }
 
+// Run deletes expired sources one job at a time. Once no source is due it
+// refunds custom runs whose lease ended unsettled and sweeps finished custom
+// runs past the same retention before idling.
 func (worker *SourceRetentionWorker) Run(ctx context.Context) error {
 	for {
 		err := worker.ProcessNext(ctx)
@@ -96,6 +109,17 @@ func (worker *SourceRetentionWorker) Run(ctx context.Context) error {
 			!IsTransientDatabaseError(err) {
 			return err
 		}
+		if _, err := worker.repository.RefundExpiredCustomRuns(ctx, customRunRefundTenants); err != nil &&
+			!errors.Is(err, ErrSourceRetentionNotAvailable) && !IsTransientDatabaseError(err) {
+			return err
+		}
+		count, err := worker.repository.ExpireCustomRunBatch(ctx, worker.retention, customRunRetentionBatch)
+		if err != nil && !errors.Is(err, ErrSourceRetentionNotAvailable) && !IsTransientDatabaseError(err) {
+			return err
+		}
+		if err == nil && count == customRunRetentionBatch {
+			continue
+		}
 		timer := time.NewTimer(worker.idleDelay)
 		select {
 		case <-ctx.Done():
@@ -64,6 +64,41 @@ func (failingRetentionRepository) RecordSourceRetentionFailure(context.Context,
 func (failingRetentionRepository) FinalizeSourceRetention(context.Context, SourceRetentionClaim) error {
 	return nil
 }
+func (failingRetentionRepository) ExpireCustomRunBatch(context.Context, time.Duration, int) (int64, error) {
+	return 0, ErrSourceRetentionNotAvailable
+}
+func (failingRetentionRepository) RefundExpiredCustomRuns(context.Context, int) (int64, error) {
+	return 0, nil
+}
+
+// customRunRetentionStub has no due sources and deletes the given run batches.
+type customRunRetentionStub struct {
+	failingRetentionRepository
+	batches    []int64
+	retentions []time.Duration
+	refunds    []int
+	drained    chan struct{}
+}
+
+func (repository *customRunRetentionStub) RefundExpiredCustomRuns(_ context.Context, tenants int) (int64, error) {
+	repository.refunds = append(repository.refunds, tenants)
+	return 0, nil
+}
+
+func (repository *customRunRetentionStub) ExpireCustomRunBatch(_ context.Context, retention time.Duration, batch int) (int64, error) {
+	repository.retentions = append(repository.retentions, retention)
+	if len(repository.batches) == 0 {
+		select {
+		case <-repository.drained:
+		default:
+			close(repository.drained)
+		}
+		return 0, ErrSourceRetentionNotAvailable
+	}
+	count := min(repository.batches[0], int64(batch))
+	repository.batches = repository.batches[1:]
+	return count, nil
+}
 
 type retentionRecordFailureRepository struct{ err error }
 
@@ -76,6 +111,12 @@ func (repository retentionRecordFailureRepository) RecordSourceRetentionFailure(
 func (retentionRecordFailureRepository) FinalizeSourceRetention(context.Context, SourceRetentionClaim) error {
 	return nil
 }
+func (retentionRecordFailureRepository) ExpireCustomRunBatch(context.Context, time.Duration, int) (int64, error) {
+	return 0, ErrSourceRetentionNotAvailable
+}
+func (retentionRecordFailureRepository) RefundExpiredCustomRuns(context.Context, int) (int64, error) {
+	return 0, nil
+}
 
 type idempotencyRetentionStub struct {
 	errors  []error
@@ -108,6 +149,14 @@ func (*transientRetentionRepository) FinalizeSourceRetention(context.Context, So
 	return nil
 }
 
+func (*transientRetentionRepository) ExpireCustomRunBatch(context.Context, time.Duration, int) (int64, error) {
+	return 0, ErrSourceRetentionNotAvailable
+}
+
+func (*transientRetentionRepository) RefundExpiredCustomRuns(context.Context, int) (int64, error) {
+	return 0, nil
+}
+
 func (store *flakyRetentionStore) Delete(ctx context.Context, key string) error {
 	if store.failures > 0 {
 		store.failures--
@@ -203,6 +252,46 @@ func TestSourceRetentionWorkerRetriesTransientRepositoryErrors(t *testing.T) {
 	}
 }
 
+func TestSourceRetentionWorkerDrainsFinishedCustomRunsWithoutIdling(t *testing.T) {
+	repository := &customRunRetentionStub{
+		failingRetentionRepository: failingRetentionRepository{err: ErrSourceRetentionNotAvailable},
+		batches:                    []int64{customRunRetentionBatch, customRunRetentionBatch, 7},
+		drained:                    make(chan struct{}),
+	}
+	worker, err := NewSourceRetentionWorker(SourceRetentionWorkerConfig{
+		Repository: repository, Objects: newMemorySourceStore(), Retention: 48 * time.Hour,
+		IdleDelay: time.Hour, DeleteTimeout: time.Second, ClaimLease: 2 * time.Second, RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(ctx) }()
+	select {
+	case <-repository.drained:
+		t.Fatal("retention worker swept again without idling after a partial batch")
+	case err := <-done:
+		t.Fatalf("retention worker stopped: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("retention worker shutdown error = %v", err)
+	}
+	if len(repository.batches) != 0 || len(repository.retentions) != 3 {
+		t.Fatalf("remaining batches=%v sweeps=%d", repository.batches, len(repository.retentions))
+	}
+	if len(repository.refunds) != 3 || repository.refunds[0] != customRunRefundTenants {
+		t.Fatalf("expired custom run refunds = %v", repository.refunds)
+	}
+	for _, retention := range repository.retentions {
+		if retention != 48*time.Hour {
+			t.Fatalf("custom run retention = %v", retention)
+		}
+	}
+}
+
 func TestSourceRetentionWorkerReturnsPermanentRepositoryInvariant(t *testing.T) {
 	permanent := fmt.Errorf("%w: accounting invariant", ErrExternalJobUnavailable)
 	worker, err := NewSourceRetentionWorker(SourceRetentionWorkerConfig{
@@ -0,0 +1,346 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tenantAuditEntries bounds the audit history that GetTenant returns.
+const tenantAuditEntries = 10
+
+var (
+	ErrTenantNotFound = errors.New("tenant does not exist")
+	ErrTenantDisabled = errors.New("tenant is disabled")
+)
+
+type TenantStatus string
+
+const (
+	TenantActive   TenantStatus = "ACTIVE"
+	TenantDisabled TenantStatus = "DISABLED"
+)
+
+// TenantSummary is the operator view of one tenant row.
+type TenantSummary struct {
+	TenantID  string
+	Name      string
+	Status    TenantStatus
+	Policy    TenantPolicy
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AdminAuditEntry is one operator change recorded in t_external_admin_audit.
+// SubjectID is the external ID of the changed tenant, key or callback.
+type AdminAuditEntry struct {
+	Action    string
+	SubjectID string
+	Detail    json.RawMessage
+	CreatedAt time.Time
+}
+
+// TenantDetails adds the live job counts and the most recent audit entries,
+// newest first, to a tenant summary.
+type TenantDetails struct {
+	TenantSummary
+	QueuedJobs  int
+	RunningJobs int
+	RecentAudit []AdminAuditEntry
+}
+
+// TenantUpdate changes only its non-nil fields; the result must still be a
+// valid policy.
+type TenantUpdate struct {
+	Name                   *string
+	MaxQueuedJobs          *int
+	MaxRunningJobs         *int
+	MaxSourceBytes         *int64
+	MaxRetainedBundles     *int
+	DailyExecutionMillis   *int64
+	MaxInfrastructureTries *int
+	MaxTimeLimitMillis     *int
+	MaxMemoryLimitMiB      *int
+	MaxJobPriority         *int
+	MaxRunningRuns         *int
+}
+
+func (update TenantUpdate) apply(tenant *TenantSummary) {
+	if update.Name != nil {
+		tenant.Name = strings.TrimSpace(*update.Name)
+	}
+	setIfPresent(&tenant.Policy.MaxQueuedJobs, update.MaxQueuedJobs)
+	setIfPresent(&tenant.Policy.MaxRunningJobs, update.MaxRunningJobs)
+	setIfPresent(&tenant.Policy.MaxSourceBytes, update.MaxSourceBytes)
+	setIfPresent(&tenant.Policy.MaxRetainedBundles, update.MaxRetainedBundles)
+	setIfPresent(&tenant.Policy.DailyExecutionMillis, update.DailyExecutionMillis)
+	setIfPresent(&tenant.Policy.MaxInfrastructureTries, update.MaxInfrastructureTries)
+	setIfPresent(&tenant.Policy.MaxTimeLimitMillis, update.MaxTimeLimitMillis)
+	setIfPresent(&tenant.Policy.MaxMemoryLimitMiB, update.MaxMemoryLimitMiB)
+	setIfPresent(&tenant.Policy.MaxJobPriority, update.MaxJobPriority)
+	setIfPresent(&tenant.Policy.MaxRunningRuns, update.MaxRunningRuns)
+}
+
+func setIfPresent[T any](target *T, value *T) {
+	if value != nil {
+		*target = *value
+	}
+}
+
+func validTenantName(name string) error {
+	if len(name) < 2 || len(name) > 128 {
+		return fmt.Errorf("tenant name must contain 2 to 128 bytes")
+	}
+	return nil
+}
+
+// ListTenants returns every tenant, active or disabled, in external ID
+// order.
+func (provisioner *Provisioner) ListTenants(ctx context.Context) ([]TenantSummary, error) {
+	if provisioner == nil || provisioner.executor == nil {
+		return nil, fmt.Errorf("provisioner is not configured")
+	}
+	rows, err := provisioner.executor.QueryContext(ctx, `
+SELECT id, external_id, name, status, policy_json, created_at, updated_at
+FROM t_external_tenant
+ORDER BY external_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+	defer rows.Close()
+	tenants := make([]TenantSummary, 0)
+	for rows.Next() {
+		_, tenant, err := scanTenantSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// GetTenant reads one tenant with its live job counts and recent audit.
+func (provisioner *Provisioner) GetTenant(ctx context.Context, tenantID string) (TenantDetails, error) {
+	if provisioner == nil || provisioner.database == nil {
+		return TenantDetails{}, fmt.Errorf("provisioner is not configured")
+	}
+	if !externalIDPattern.MatchString(tenantID) {
+		return TenantDetails{}, ErrTenantNotFound
+	}
+	tx, err := provisioner.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return TenantDetails{}, fmt.Errorf("begin tenant read: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	internalID, tenant, err := scanTenantSummary(tx.QueryRowContext(ctx, `
+SELECT id, external_id, name, status, policy_json, created_at, updated_at
+FROM t_external_tenant WHERE external_id = ?`, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return TenantDetails{}, ErrTenantNotFound
+	}
+	if err != nil {
+		return TenantDetails{}, err
+	}
+	details := TenantDetails{TenantSummary: tenant}
+	if err := tx.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(status = 'QUEUED'), 0), COALESCE(SUM(status = 'RUNNING'), 0)
+FROM t_external_job WHERE tenant_id = ? AND status IN ('QUEUED', 'RUNNING')`, internalID).
+		Scan(&details.QueuedJobs, &details.RunningJobs); err != nil {
+		return TenantDetails{}, fmt.Errorf("count tenant jobs: %w", err)
+	}
+	rows, err := tx.QueryContext(ctx, `
+SELECT action, subject_external_id, detail_json, created_at
+FROM t_external_admin_audit
+WHERE tenant_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?`, internalID, tenantAuditEntries)
+	if err != nil {
+		return TenantDetails{}, fmt.Errorf("read tenant audit: %w", err)
+	}
+	defer rows.Close()
+	details.RecentAudit = make([]AdminAuditEntry, 0, tenantAuditEntries)
+	for rows.Next() {
+		var entry AdminAuditEntry
+		var detail []byte
+		if err := rows.Scan(&entry.Action, &entry.SubjectID, &detail, &entry.CreatedAt); err != nil {
+			return TenantDetails{}, fmt.Errorf("scan tenant audit: %w", err)
+		}
+		entry.Detail, entry.CreatedAt = json.RawMessage(detail), entry.CreatedAt.UTC()
+		details.RecentAudit = append(details.RecentAudit, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return TenantDetails{}, fmt.Errorf("read tenant audit: %w", err)
+	}
+	return details, nil
+}
+
+// UpdateTenant applies update under the tenant row lock and records the
+// previous and new name and policy. A new policy applies to the next
+// admission and claim; already admitted jobs keep running.
+func (provisioner *Provisioner) UpdateTenant(ctx context.Context, tenantID string, update TenantUpdate) (TenantSummary, error) {
+	var updated TenantSummary
+	err := provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		updated = tenant
+		update.apply(&updated)
+		if err := validTenantName(updated.Name); err != nil {
+			return err
+		}
+		if err := updated.Policy.validate(); err != nil {
+			return err
+		}
+		encodedPolicy, err := json.Marshal(updated.Policy)
+		if err != nil {
+			return fmt.Errorf("encode tenant policy: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE t_external_tenant SET name = ?, policy_json = ? WHERE id = ?",
+			updated.Name, encodedPolicy, internalID); err != nil {
+			return fmt.Errorf("update tenant: %w", err)
+		}
+		return insertAdminAudit(ctx, tx, internalID, "tenant.updated", tenantID, map[string]tenantRevision{
+			"before": {Name: tenant.Name, Policy: tenant.Policy},
+			"after":  {Name: updated.Name, Policy: updated.Policy},
+		})
+	})
+	if err != nil {
+		return TenantSummary{}, err
+	}
+	return updated, nil
+}
+
+// DisableTenant stops the tenant's API keys, admission and claims at once.
+// Queued jobs stay queued until the tenant is enabled again, and running jobs
+// finish normally. Only with interruptRunning are the running jobs' leases
+// ended, so their workers lose the fence on the next heartbeat and the next
+// claim pass settles them as TENANT_DISABLED; that also works on a tenant
+// that is already disabled. It returns the number of running jobs
+// interrupted. A call that changes nothing records no audit entry.
+func (provisioner *Provisioner) DisableTenant(ctx context.Context, tenantID string, interruptRunning bool) (int64, error) {
+	var interrupted int64
+	err := provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		action := "tenant.disabled"
+		if tenant.Status == TenantDisabled {
+			action = "tenant.jobs_interrupted"
+		} else if _, err := tx.ExecContext(ctx, "UPDATE t_external_tenant SET status = 'DISABLED' WHERE id = ?", internalID); err != nil {
+			return fmt.Errorf("disable tenant: %w", err)
+		}
+		if interruptRunning {
+			result, err := tx.ExecContext(ctx, `
+UPDATE t_external_job
+SET lease_until = CURRENT_TIMESTAMP(3)
+WHERE tenant_id = ? AND status = 'RUNNING' AND lease_until > CURRENT_TIMESTAMP(3)`, internalID)
+			if err != nil {
+				return fmt.Errorf("end disabled tenant leases: %w", err)
+			}
+			if interrupted, err = result.RowsAffected(); err != nil {
+				return fmt.Errorf("confirm disabled tenant leases: %w", err)
+			}
+		}
+		if tenant.Status == TenantDisabled && interrupted == 0 {
+			return nil
+		}
+		return insertAdminAudit(ctx, tx, internalID, action, tenantID, map[string]int64{"interruptedJobs": interrupted})
+	})
+	return interrupted, err
+}
+
+// EnableTenant reactivates a disabled tenant; its queued jobs become
+// claimable again. Enabling an active tenant records no audit entry.
+func (provisioner *Provisioner) EnableTenant(ctx context.Context, tenantID string) error {
+	return provisioner.changeTenant(ctx, tenantID, func(tx *sql.Tx, internalID uint64, tenant TenantSummary) error {
+		if tenant.Status == TenantActive {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE t_external_tenant SET status = 'ACTIVE' WHERE id = ?", internalID); err != nil {
+			return fmt.Errorf("enable tenant: %w", err)
+		}
+		return insertAdminAudit(ctx, tx, internalID, "tenant.enabled", tenantID, struct{}{})
+	})
+}
+
+// changeTenant runs change in a transaction holding the tenant row lock,
+// the first lock of the canonical tenant -> job order.
+func (provisioner *Provisioner) changeTenant(
+	ctx context.Context,
+	tenantID string,
+	change func(*sql.Tx, uint64, TenantSummary) error,
+) error {
+	if provisioner == nil || provisioner.database == nil {
+		return fmt.Errorf("provisioner is not configured")
+	}
+	if !externalIDPattern.MatchString(tenantID) {
+		return ErrTenantNotFound
+	}
+	tx, err := provisioner.database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin tenant change: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	internalID, tenant, err := scanTenantSummary(tx.QueryRowContext(ctx, `
+SELECT id, external_id, name, status, policy_json, created_at, updated_at
+FROM t_external_tenant WHERE external_id = ? FOR UPDATE`, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTenantNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := change(tx, internalID, tenant); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tenant change: %w", err)
+	}
+	return nil
+}
+
+// tenantRevision is the audited name and policy of a tenant.
+type tenantRevision struct {
+	Name   string       `json:"name"`
+	Policy TenantPolicy `json:"policy"`
+}
+
+// insertAdminAudit records an operator change in the caller's transaction.
+func insertAdminAudit(ctx context.Context, tx *sql.Tx, tenantInternalID uint64, action, subjectID string, detail any) error {
+	encoded, err := json.Marshal(detail)
+	if err != nil {
+		return fmt.Errorf("encode %s audit: %w", action, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO t_external_admin_audit(tenant_id, action, subject_external_id, detail_json)
+VALUES (?, ?, ?, ?)`, tenantInternalID, action, subjectID, encoded); err != nil {
+		return fmt.Errorf("record %s audit: %w", action, err)
+	}
+	return nil
+}
+
+type tenantScanner interface {
+	Scan(...any) error
+}
+
+// scanTenantSummary reads id, external_id, name, status, policy_json,
+// created_at and updated_at. Policies are decoded leniently so an operator
+// can still see and repair a tenant whose stored policy no longer validates.
+func scanTenantSummary(row tenantScanner) (uint64, TenantSummary, error) {
+	var internalID uint64
+	var tenant TenantSummary
+	var encodedPolicy []byte
+	if err := row.Scan(&internalID, &tenant.TenantID, &tenant.Name, &tenant.Status, &encodedPolicy, &tenant.CreatedAt, &tenant.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, TenantSummary{}, err
+		}
+		return 0, TenantSummary{}, fmt.Errorf("scan tenant: %w", err)
+	}
+	if err := json.Unmarshal(encodedPolicy, &tenant.Policy); err != nil {
+		return 0, TenantSummary{}, fmt.Errorf("decode policy of tenant %s: %w", tenant.TenantID, err)
+	}
+	tenant.CreatedAt, tenant.UpdatedAt = tenant.CreatedAt.UTC(), tenant.UpdatedAt.UTC()
+	return internalID, tenant, nil
+}
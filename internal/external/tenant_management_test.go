@@ -0,0 +1,133 @@
+package external
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvisionerTenantLifecycleIsAuditedAndFencesRunningJobs(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID := strings.Repeat("n", 26)
+	bundleID := strings.Repeat("p", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, "", 10)
+	provisioner, err := NewProvisioner(database, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	var jobIDs []string
+	for _, key := range []string{"tenant-lifecycle-0001", "tenant-lifecycle-0002"} {
+		result, err := repository.Submit(context.Background(), tenantID, key, JudgeJobRequest{
+			BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){}"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobIDs = append(jobIDs, result.Job.ExternalID)
+	}
+	running, err := repository.ClaimNext(context.Background(), "tenant-lifecycle-worker", time.Minute)
+	if err != nil || running.Job.ExternalID != jobIDs[0] {
+		t.Fatalf("claim=%+v error=%v", running, err)
+	}
+
+	name, maxQueued, maxPriority := "Renamed OJ", 20, 3
+	updated, err := provisioner.UpdateTenant(context.Background(), tenantID, TenantUpdate{Name: &name, MaxQueuedJobs: &maxQueued, MaxJobPriority: &maxPriority})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || updated.Policy.MaxQueuedJobs != 20 || updated.Policy.MaxJobPriority != 3 || updated.Policy.MaxRunningJobs != 1 {
+		t.Fatalf("updated = %+v", updated)
+	}
+	invalidRunning := 50
+	if _, err := provisioner.UpdateTenant(context.Background(), tenantID, TenantUpdate{MaxRunningJobs: &invalidRunning}); err == nil {
+		t.Fatal("expected running limit above queued limit to be rejected")
+	}
+
+	if interrupted, err := provisioner.DisableTenant(context.Background(), tenantID, false); err != nil || interrupted != 0 {
+		t.Fatalf("disable interrupted=%d error=%v", interrupted, err)
+	}
+	// Disabling blocks new claims but lets the running job keep its lease.
+	if err := repository.Heartbeat(context.Background(), running, time.Minute); err != nil {
+		t.Fatalf("heartbeat after disable = %v", err)
+	}
+	if _, err := repository.ClaimNext(context.Background(), "tenant-lifecycle-other", time.Minute); !errors.Is(err, ErrJobNotClaimable) {
+		t.Fatalf("claim of a disabled tenant = %v", err)
+	}
+	if interrupted, err := provisioner.DisableTenant(context.Background(), tenantID, false); err != nil || interrupted != 0 {
+		t.Fatalf("repeated disable interrupted=%d error=%v", interrupted, err)
+	}
+	interrupted, err := provisioner.DisableTenant(context.Background(), tenantID, true)
+	if err != nil || interrupted != 1 {
+		t.Fatalf("interrupted=%d error=%v", interrupted, err)
+	}
+	if err := repository.Heartbeat(context.Background(), running, time.Minute); !errors.Is(err, ErrStaleJobClaim) {
+		t.Fatalf("heartbeat after interrupt = %v", err)
+	}
+	if _, err := repository.ClaimNext(context.Background(), "tenant-lifecycle-cleanup", time.Minute); !errors.Is(err, ErrJobNotClaimable) {
+		t.Fatalf("claim after disable = %v", err)
+	}
+	settled, err := repository.Get(context.Background(), tenantID, jobIDs[0])
+	if err != nil || settled.Status != JobStatusFailed || settled.FailureCode != "TENANT_DISABLED" {
+		t.Fatalf("running job after disable=%+v error=%v", settled, err)
+	}
+	if _, err := repository.Submit(context.Background(), tenantID, "tenant-lifecycle-0003", JudgeJobRequest{
+		BundleID: bundleID, Language: "cpp", SourceCode: []byte("int main(){}"),
+	}); err == nil {
+		t.Fatal("disabled tenant admitted a job")
+	}
+
+	if err := provisioner.EnableTenant(context.Background(), tenantID); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := repository.ClaimNext(context.Background(), "tenant-lifecycle-worker", time.Minute)
+	if err != nil || resumed.Job.ExternalID != jobIDs[1] {
+		t.Fatalf("queued job after enable=%+v error=%v", resumed, err)
+	}
+
+	details, err := provisioner.GetTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Name != name || details.Status != TenantActive || details.QueuedJobs != 0 || details.RunningJobs != 1 {
+		t.Fatalf("details = %+v", details)
+	}
+	var actions []string
+	for _, entry := range details.RecentAudit {
+		actions = append(actions, entry.Action)
+		if entry.SubjectID != tenantID {
+			t.Fatalf("audit subject = %q", entry.SubjectID)
+		}
+	}
+	if strings.Join(actions, ",") != "tenant.enabled,tenant.jobs_interrupted,tenant.disabled,tenant.updated" {
+		t.Fatalf("audit actions = %v", actions)
+	}
+	var revision struct {
+		Before struct {
+			Name   string       `json:"name"`
+			Policy TenantPolicy `json:"policy"`
+		} `json:"before"`
+		After struct {
+			Name string `json:"name"`
+		} `json:"after"`
+	}
+	if err := json.Unmarshal(details.RecentAudit[3].Detail, &revision); err != nil || revision.Before.Policy.MaxQueuedJobs != 10 || revision.After.Name != name {
+		t.Fatalf("update audit=%s error=%v", details.RecentAudit[3].Detail, err)
+	}
+
+	tenants, err := provisioner.ListTenants(context.Background())
+	if err != nil || len(tenants) != 1 || tenants[0].TenantID != tenantID || tenants[0].Status != TenantActive {
+		t.Fatalf("tenants=%+v error=%v", tenants, err)
+	}
+	if _, err := provisioner.GetTenant(context.Background(), strings.Repeat("q", 26)); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("unknown tenant error = %v", err)
+	}
+	if err := provisioner.EnableTenant(context.Background(), strings.Repeat("q", 26)); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("unknown tenant enable error = %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package external
+
+import (
+	"context"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan opens an internal span for one repository or object-store call.
+// Attributes carry identifiers and sizes only, never source or case bytes.
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+func endSpan(span trace.Span, err error) {
+	tracing.End(span, err)
+}
+
+func claimAttributes(claim WorkerJobClaim) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("croj.tenant", claim.Job.TenantExternalID),
+		attribute.String("croj.job", claim.Job.ExternalID),
+		attribute.Int("croj.attempt", int(claim.AttemptNo)),
+		attribute.String("croj.worker", claim.WorkerID),
+	}
+}
@@ -0,0 +1,48 @@
+package external
+
+import "errors"
+
+var ErrInvalidUsageQuery = errors.New("tenant usage query is invalid")
+
+// MaximumUsageHistoryDays bounds the daily execution series of one usage read.
+const MaximumUsageHistoryDays = 90
+
+// TenantUsage reports a tenant's current consumption against its policy.
+// Day values use the database's accounting day, the same calendar day that
+// workers reserve execution time against.
+type TenantUsage struct {
+	Execution ExecutionUsage      `json:"execution"`
+	Jobs      JobUsage            `json:"jobs"`
+	Bundles   BundleUsage         `json:"bundles"`
+	History   []ExecutionUsageDay `json:"history"`
+}
+
+// ExecutionUsage is today's daily execution ledger. ReservedMillis is held
+// by running attempts and custom runs; ConsumedMillis has been settled.
+type ExecutionUsage struct {
+	Day             string `json:"day"`
+	ReservedMillis  int64  `json:"reservedMillis"`
+	ConsumedMillis  int64  `json:"consumedMillis"`
+	RemainingMillis int64  `json:"remainingMillis"`
+	LimitMillis     int64  `json:"limitMillis"`
+}
+
+// ExecutionUsageDay is one day of the execution ledger; days without a
+// ledger row report zero.
+type ExecutionUsageDay struct {
+	Day            string `json:"day"`
+	ReservedMillis int64  `json:"reservedMillis"`
+	ConsumedMillis int64  `json:"consumedMillis"`
+}
+
+type JobUsage struct {
+	Queued     int `json:"queued"`
+	MaxQueued  int `json:"maxQueued"`
+	Running    int `json:"running"`
+	MaxRunning int `json:"maxRunning"`
+}
+
+type BundleUsage struct {
+	Retained    int `json:"retained"`
+	MaxRetained int `json:"maxRetained"`
+}
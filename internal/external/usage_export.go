@@ -0,0 +1,130 @@
+package external
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidUsageExport  = errors.New("usage export request is invalid")
+	ErrUsageTenantNotFound = errors.New("usage export tenant does not exist")
+)
+
+// MaximumUsageExportDays bounds one export to a little over a year of days.
+const MaximumUsageExportDays = 366
+
+type UsageExportFormat string
+
+const (
+	UsageExportCSV   UsageExportFormat = "csv"
+	UsageExportJSONL UsageExportFormat = "jsonl"
+)
+
+// UsageExportQuery selects the inclusive accounting days From..To. An empty
+// TenantID exports every tenant.
+type UsageExportQuery struct {
+	TenantID string
+	From     time.Time
+	To       time.Time
+}
+
+// ParseUsageExportQuery validates the YYYY-MM-DD bounds shared by the admin
+// CLI and the admin API.
+func ParseUsageExportQuery(tenantID, from, to string) (UsageExportQuery, error) {
+	query := UsageExportQuery{TenantID: tenantID}
+	var err error
+	if query.From, err = time.Parse(time.DateOnly, from); err != nil {
+		return UsageExportQuery{}, fmt.Errorf("%w: from must be a YYYY-MM-DD date", ErrInvalidUsageExport)
+	}
+	if query.To, err = time.Parse(time.DateOnly, to); err != nil {
+		return UsageExportQuery{}, fmt.Errorf("%w: to must be a YYYY-MM-DD date", ErrInvalidUsageExport)
+	}
+	return query, query.validate()
+}
+
+func (query UsageExportQuery) validate() error {
+	if query.TenantID != "" && !externalIDPattern.MatchString(query.TenantID) {
+		return fmt.Errorf("%w: tenant ID is invalid", ErrInvalidUsageExport)
+	}
+	if query.From.IsZero() || query.To.Before(query.From) || query.days() > MaximumUsageExportDays {
+		return fmt.Errorf("%w: from must not be after to, and the range may span at most %d days", ErrInvalidUsageExport, MaximumUsageExportDays)
+	}
+	return nil
+}
+
+func (query UsageExportQuery) days() int {
+	return int(query.To.Sub(query.From).Hours()/24) + 1
+}
+
+func ParseUsageExportFormat(value string) (UsageExportFormat, error) {
+	switch format := UsageExportFormat(value); format {
+	case UsageExportCSV, UsageExportJSONL:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: format must be csv or jsonl", ErrInvalidUsageExport)
+	}
+}
+
+// UsageExportRow is one tenant, day and language of billable usage. Every
+// tenant has one row per day with an empty Language that carries the bundle
+// bytes stored at the end of that day; language rows follow only for days
+// with settled execution or finished jobs, so summing any column over all
+// rows never counts a value twice.
+//
+// Execution is attributed to the accounting day the attempt or custom run
+// reserved against, and jobs to the day they finished.
+type UsageExportRow struct {
+	TenantID           string `json:"tenantId"`
+	Day                string `json:"day"`
+	Language           string `json:"language"`
+	JobExecutionMillis int64  `json:"jobExecutionMillis"`
+	RunExecutionMillis int64  `json:"runExecutionMillis"`
+	JobsSucceeded      int64  `json:"jobsSucceeded"`
+	JobsFailed         int64  `json:"jobsFailed"`
+	JobsCancelled      int64  `json:"jobsCancelled"`
+	BundleStorageBytes int64  `json:"bundleStorageBytes"`
+}
+
+var usageExportCSVHeader = []string{
+	"tenant_id", "day", "language", "job_execution_millis", "run_execution_millis",
+	"jobs_succeeded", "jobs_failed", "jobs_cancelled", "bundle_storage_bytes",
+}
+
+// WriteUsageExport renders rows in their given order. Both formats are
+// byte-for-byte deterministic for the same rows.
+func WriteUsageExport(output io.Writer, format UsageExportFormat, rows []UsageExportRow) error {
+	switch format {
+	case UsageExportCSV:
+		writer := csv.NewWriter(output)
+		if err := writer.Write(usageExportCSVHeader); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := writer.Write([]string{
+				row.TenantID, row.Day, row.Language,
+				strconv.FormatInt(row.JobExecutionMillis, 10), strconv.FormatInt(row.RunExecutionMillis, 10),
+				strconv.FormatInt(row.JobsSucceeded, 10), strconv.FormatInt(row.JobsFailed, 10),
+				strconv.FormatInt(row.JobsCancelled, 10), strconv.FormatInt(row.BundleStorageBytes, 10),
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case UsageExportJSONL:
+		encoder := json.NewEncoder(output)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: format must be csv or jsonl", ErrInvalidUsageExport)
+	}
+}
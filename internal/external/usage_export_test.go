@@ -0,0 +1,58 @@
+package external
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseUsageExportQueryValidatesTheDayRange(t *testing.T) {
+	query, err := ParseUsageExportQuery("", "2026-01-01", "2027-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.days() != MaximumUsageExportDays {
+		t.Fatalf("days = %d", query.days())
+	}
+	for name, bounds := range map[string][3]string{
+		"missing from":   {"", "", "2026-09-01"},
+		"timestamp":      {"", "2026-09-01T00:00:00Z", "2026-09-02"},
+		"reversed range": {"", "2026-09-02", "2026-09-01"},
+		"too long":       {"", "2026-01-01", "2027-01-02"},
+		"bad tenant":     {"tenant 1", "2026-09-01", "2026-09-01"},
+	} {
+		if _, err := ParseUsageExportQuery(bounds[0], bounds[1], bounds[2]); !errors.Is(err, ErrInvalidUsageExport) {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+	if _, err := ParseUsageExportFormat("xlsx"); !errors.Is(err, ErrInvalidUsageExport) {
+		t.Fatalf("format error = %v", err)
+	}
+}
+
+func TestWriteUsageExportEscapesCSVAndEncodesOneJSONObjectPerLine(t *testing.T) {
+	rows := []UsageExportRow{
+		{TenantID: strings.Repeat("a", 26), Day: "2026-09-01", Language: "c,pp", JobExecutionMillis: 7, JobsFailed: 1},
+	}
+	var output bytes.Buffer
+	if err := WriteUsageExport(&output, UsageExportCSV, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := "tenant_id,day,language,job_execution_millis,run_execution_millis,jobs_succeeded,jobs_failed,jobs_cancelled,bundle_storage_bytes\n" +
+		strings.Repeat("a", 26) + ",2026-09-01,\"c,pp\",7,0,0,1,0,0\n"
+	if output.String() != want {
+		t.Fatalf("csv = %q", output.String())
+	}
+	output.Reset()
+	if err := WriteUsageExport(&output, UsageExportJSONL, append(rows, rows...)); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"tenantId":"` + strings.Repeat("a", 26) + `","day":"2026-09-01","language":"c,pp","jobExecutionMillis":7,"runExecutionMillis":0,"jobsSucceeded":0,"jobsFailed":1,"jobsCancelled":0,"bundleStorageBytes":0}` + "\n"
+	if output.String() != line+line {
+		t.Fatalf("jsonl = %q", output.String())
+	}
+	if err := WriteUsageExport(&output, "xml", rows); !errors.Is(err, ErrInvalidUsageExport) {
+		t.Fatalf("unknown format error = %v", err)
+	}
+}
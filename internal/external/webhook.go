@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -51,7 +52,18 @@ type WebhookDelivery struct {
 	EventID        string
 	DestinationURL string
 	Secret         []byte
+	// PreviousSecret, when set, adds a second v1 signature so receivers that
+	// still hold a rotated secret keep verifying during its grace period.
+	PreviousSecret []byte
 	Body           []byte
+	// SigningKeys, when set, adds a v2 Ed25519 signature alongside v1.
+	SigningKeys *WebhookSigningKeyRing
+}
+
+// LogValue omits the destination URL, whose query may embed tenant tokens,
+// along with the signing secret and body.
+func (delivery WebhookDelivery) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("event_id", delivery.EventID), slog.Int("body_bytes", len(delivery.Body)))
 }
 
 type WebhookDeliverer struct {
@@ -94,7 +106,14 @@ func (deliverer *WebhookDeliverer) DeliverOutcome(ctx context.Context, delivery
 	request.Header.Set("User-Agent", "CodeRushOJ-Judge-Webhook/1.0")
 	request.Header.Set("X-CodeRushOJ-Event-Id", delivery.EventID)
 	request.Header.Set("X-CodeRushOJ-Timestamp", timestamp)
-	request.Header.Set("X-CodeRushOJ-Signature", signWebhook(delivery.Secret, delivery.EventID, timestamp, delivery.Body))
+	signature := signWebhook(delivery.Secret, delivery.EventID, timestamp, delivery.Body)
+	if delivery.PreviousSecret != nil {
+		signature += ", " + signWebhook(delivery.PreviousSecret, delivery.EventID, timestamp, delivery.Body)
+	}
+	if delivery.SigningKeys != nil {
+		signature += ", " + delivery.SigningKeys.sign(delivery.EventID, timestamp, delivery.Body)
+	}
+	request.Header.Set("X-CodeRushOJ-Signature", signature)
 	// RoundTrip deliberately bypasses http.Client's redirect machinery. A 3xx
 	// is classified as permanent and the signed body is never forwarded.
 	response, err := deliverer.transport.RoundTrip(request)
@@ -189,6 +208,9 @@ func validateWebhookDelivery(delivery WebhookDelivery) error {
 	if len(delivery.Secret) < sha256.Size || len(delivery.Secret) > 1024 {
 		return fmt.Errorf("webhook secret must contain 32 to 1024 bytes")
 	}
+	if delivery.PreviousSecret != nil && (len(delivery.PreviousSecret) < sha256.Size || len(delivery.PreviousSecret) > 1024) {
+		return fmt.Errorf("previous webhook secret must contain 32 to 1024 bytes")
+	}
 	if len(delivery.Body) == 0 || len(delivery.Body) > maximumWebhookBodyBytes {
 		return fmt.Errorf("webhook body is invalid")
 	}
@@ -0,0 +1,155 @@
+package external
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidWebhookDeliveryQuery  = errors.New("invalid webhook delivery list query")
+	ErrInvalidWebhookDeliveryCursor = errors.New("invalid webhook delivery cursor")
+	ErrWebhookDeliveryNotFound      = errors.New("webhook delivery does not exist")
+	ErrWebhookDeliveryNotDead       = errors.New("webhook delivery is not dead-lettered")
+	ErrWebhookDeliverySuperseded    = errors.New("webhook delivery was superseded by a newer event")
+	ErrWebhookCallbackDisabled      = errors.New("webhook delivery callback is disabled")
+)
+
+// WebhookDeliveryStatus is the outbox state of one webhook event. The
+// values are the stored t_external_webhook_outbox.status values.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending    WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivering WebhookDeliveryStatus = "DELIVERING"
+	WebhookDeliveryDelivered  WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryDead       WebhookDeliveryStatus = "DEAD"
+)
+
+// WebhookDeliverySummary is the tenant-visible delivery log entry of one
+// outbox row. It never carries the signed body or lease fields. JobID is
+// empty for judge.ping events.
+type WebhookDeliverySummary struct {
+	EventID           string                `json:"eventId"`
+	EventType         string                `json:"eventType"`
+	JobID             string                `json:"jobId,omitempty"`
+	CallbackID        string                `json:"callbackId"`
+	Status            WebhookDeliveryStatus `json:"status"`
+	AttemptCount      uint                  `json:"attemptCount"`
+	LastHTTPStatus    *int                  `json:"lastHttpStatus,omitempty"`
+	LastLatencyMillis *int64                `json:"lastLatencyMillis,omitempty"`
+	LastErrorCode     string                `json:"lastErrorCode,omitempty"`
+	NextAttemptAt     *time.Time            `json:"nextAttemptAt,omitempty"`
+	CreatedAt         time.Time             `json:"createdAt"`
+	DeliveredAt       *time.Time            `json:"deliveredAt,omitempty"`
+	DeadAt            *time.Time            `json:"deadAt,omitempty"`
+	InternalID        uint64                `json:"-"`
+}
+
+type WebhookDeliveryListOptions struct {
+	Cursor string
+	Limit  int
+	Status WebhookDeliveryStatus
+}
+
+type WebhookDeliveryListResult struct {
+	Items      []WebhookDeliverySummary `json:"items"`
+	NextCursor string                   `json:"nextCursor,omitempty"`
+}
+
+type WebhookDeliveryCursor struct {
+	TenantID   string
+	Status     WebhookDeliveryStatus
+	InternalID uint64
+}
+
+// webhookDeliveryCursorKind keeps delivery cursors apart from job and bundle
+// cursors signed with the same key.
+const webhookDeliveryCursorKind = "webhook-delivery"
+
+type webhookDeliveryCursorPayload struct {
+	Version    int                   `json:"v"`
+	Kind       string                `json:"k"`
+	TenantID   string                `json:"t"`
+	Status     WebhookDeliveryStatus `json:"s,omitempty"`
+	InternalID uint64                `json:"i"`
+}
+
+// WebhookDeliveryCursorCodec signs delivery log cursors so a page boundary
+// cannot be replayed against another tenant or status filter.
+type WebhookDeliveryCursorCodec struct{ key []byte }
+
+func NewWebhookDeliveryCursorCodec(key []byte) (*WebhookDeliveryCursorCodec, error) {
+	if len(key) < sha256.Size {
+		return nil, fmt.Errorf("webhook delivery cursor HMAC key must contain at least 256 bits")
+	}
+	return &WebhookDeliveryCursorCodec{key: append([]byte(nil), key...)}, nil
+}
+
+func (codec *WebhookDeliveryCursorCodec) Encode(cursor WebhookDeliveryCursor) (string, error) {
+	if codec == nil || len(codec.key) < sha256.Size || !validWebhookDeliveryCursor(cursor) {
+		return "", ErrInvalidWebhookDeliveryCursor
+	}
+	payload, err := json.Marshal(webhookDeliveryCursorPayload{
+		Version: 1, Kind: webhookDeliveryCursorKind, TenantID: cursor.TenantID, Status: cursor.Status, InternalID: cursor.InternalID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: encode payload", ErrInvalidWebhookDeliveryCursor)
+	}
+	signature := hmac.New(sha256.New, codec.key)
+	_, _ = signature.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signature.Sum(nil)), nil
+}
+
+func (codec *WebhookDeliveryCursorCodec) Decode(encoded, tenantID string, status WebhookDeliveryStatus) (WebhookDeliveryCursor, error) {
+	if codec == nil || len(codec.key) < sha256.Size || len(encoded) == 0 || len(encoded) > 512 {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	parts := strings.Split(encoded, ".")
+	if len(parts) != 2 {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	providedSignature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	expectedSignature := hmac.New(sha256.New, codec.key)
+	_, _ = expectedSignature.Write(payload)
+	if !hmac.Equal(providedSignature, expectedSignature.Sum(nil)) {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	var decoded webhookDeliveryCursorPayload
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decoded); err != nil || decoded.Version != 1 || decoded.Kind != webhookDeliveryCursorKind {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	cursor := WebhookDeliveryCursor{TenantID: decoded.TenantID, Status: decoded.Status, InternalID: decoded.InternalID}
+	if cursor.TenantID != tenantID || cursor.Status != status || !validWebhookDeliveryCursor(cursor) {
+		return WebhookDeliveryCursor{}, ErrInvalidWebhookDeliveryCursor
+	}
+	return cursor, nil
+}
+
+func validWebhookDeliveryCursor(cursor WebhookDeliveryCursor) bool {
+	return externalIDPattern.MatchString(cursor.TenantID) && cursor.InternalID != 0 && validWebhookDeliveryStatusFilter(cursor.Status)
+}
+
+func validWebhookDeliveryStatusFilter(status WebhookDeliveryStatus) bool {
+	switch status {
+	case "", WebhookDeliveryPending, WebhookDeliveryDelivering, WebhookDeliveryDelivered, WebhookDeliveryDead:
+		return true
+	default:
+		return false
+	}
+}
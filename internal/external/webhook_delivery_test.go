@@ -0,0 +1,158 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookDeliveryCursorIsBoundToTenantAndStatus(t *testing.T) {
+	codec, err := NewWebhookDeliveryCursorCodec([]byte(strings.Repeat("c", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := WebhookDeliveryCursor{TenantID: testTenantID, Status: WebhookDeliveryDead, InternalID: 42}
+	encoded, err := codec.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := codec.Decode(encoded, testTenantID, WebhookDeliveryDead); err != nil || got != want {
+		t.Fatalf("decoded cursor = %+v error=%v, want %+v", got, err, want)
+	}
+	parts := strings.Split(encoded, ".")
+	for name, attempt := range map[string]struct {
+		encoded string
+		tenant  string
+		status  WebhookDeliveryStatus
+	}{
+		"tampered": {parts[0] + "." + strings.Repeat("A", len(parts[1])), testTenantID, WebhookDeliveryDead},
+		"tenant":   {encoded, "bbbbbbbbbbbbbbbbbbbbbbbbbb", WebhookDeliveryDead},
+		"status":   {encoded, testTenantID, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := codec.Decode(attempt.encoded, attempt.tenant, attempt.status); !errors.Is(err, ErrInvalidWebhookDeliveryCursor) {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+	bundleCodec, err := NewBundleCursorCodec([]byte(strings.Repeat("c", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundleCursor, err := bundleCodec.Encode(BundleCursor{TenantID: testTenantID, CreatedAt: time.Now(), InternalID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.Decode(bundleCursor, testTenantID, ""); !errors.Is(err, ErrInvalidWebhookDeliveryCursor) {
+		t.Fatalf("bundle cursor accepted as delivery cursor: %v", err)
+	}
+	if _, err := codec.Encode(WebhookDeliveryCursor{TenantID: testTenantID, Status: "FAILED", InternalID: 42}); !errors.Is(err, ErrInvalidWebhookDeliveryCursor) {
+		t.Fatalf("unknown status encoded: %v", err)
+	}
+}
+
+func TestMySQLWebhookDeliveryLogListsAndRedeliversDeadEvents(t *testing.T) {
+	database := openMySQLIntegration(t)
+	prepareExternalJobDatabase(t, database)
+	tenantID, bundleID, callbackID := strings.Repeat("v", 26), strings.Repeat("w", 26), strings.Repeat("x", 26)
+	insertTenantBundleAndCallback(t, database, tenantID, bundleID, callbackID, 8)
+	otherTenantID := strings.Repeat("y", 26)
+	insertTenantBundleAndCallback(t, database, otherTenantID, strings.Repeat("z", 26), "", 8)
+	jobs := newTestMySQLJobRepository(t, database, newMemorySourceStore())
+	repository, err := NewMySQLWebhookOutboxRepository(MySQLWebhookOutboxRepositoryConfig{
+		Database: database, Random: rand.Reader, MaximumAttempts: 1, CursorKey: bytes.Repeat([]byte{7}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for index := 0; index < 3; index++ {
+		job := submitWebhookJob(t, jobs, tenantID, bundleID, callbackID, fmt.Sprintf("webhook-delivery-log-%d", index))
+		if _, err := jobs.Cancel(ctx, tenantID, job.Job.ExternalID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claim, err := repository.ClaimNextWebhook(ctx, "delivery-log-worker", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.SettleWebhook(ctx, claim, WebhookSettlement{
+		Disposition: WebhookRetry, HTTPStatus: 503, ErrorCode: WebhookErrorHTTPRetryable, RetryDelay: time.Second,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repository.ListWebhookDeliveries(ctx, tenantID, WebhookDeliveryListOptions{Limit: 2})
+	if err != nil || len(first.Items) != 2 || first.NextCursor == "" || first.Items[0].InternalID <= first.Items[1].InternalID {
+		t.Fatalf("first page = %+v error=%v", first, err)
+	}
+	second, err := repository.ListWebhookDeliveries(ctx, tenantID, WebhookDeliveryListOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil || len(second.Items) != 1 || second.NextCursor != "" || second.Items[0].EventID != claim.EventID {
+		t.Fatalf("second page = %+v error=%v", second, err)
+	}
+	if _, err := repository.ListWebhookDeliveries(ctx, tenantID, WebhookDeliveryListOptions{Limit: 2, Cursor: first.NextCursor, Status: WebhookDeliveryDead}); !errors.Is(err, ErrInvalidWebhookDeliveryCursor) {
+		t.Fatalf("cursor reused across filters: %v", err)
+	}
+	dead, err := repository.ListWebhookDeliveries(ctx, tenantID, WebhookDeliveryListOptions{Limit: 10, Status: WebhookDeliveryDead})
+	if err != nil || len(dead.Items) != 1 {
+		t.Fatalf("dead page = %+v error=%v", dead, err)
+	}
+	entry := dead.Items[0]
+	if entry.EventID != claim.EventID || entry.CallbackID != callbackID || entry.AttemptCount != 1 || entry.LastHTTPStatus == nil ||
+		*entry.LastHTTPStatus != 503 || entry.LastErrorCode != WebhookErrorHTTPRetryable || entry.DeadAt == nil || entry.NextAttemptAt != nil {
+		t.Fatalf("dead entry = %+v", entry)
+	}
+	if other, err := repository.ListWebhookDeliveries(ctx, otherTenantID, WebhookDeliveryListOptions{Limit: 10}); err != nil || len(other.Items) != 0 {
+		t.Fatalf("other tenant page = %+v error=%v", other, err)
+	}
+
+	if _, err := repository.RedeliverWebhook(ctx, otherTenantID, claim.EventID); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Fatalf("cross-tenant redelivery error = %v", err)
+	}
+	if _, err := repository.RedeliverWebhook(ctx, tenantID, first.Items[0].EventID); !errors.Is(err, ErrWebhookDeliveryNotDead) {
+		t.Fatalf("pending redelivery error = %v", err)
+	}
+	if _, err := database.Exec("UPDATE t_external_webhook_outbox SET expires_at = CURRENT_TIMESTAMP(3) - INTERVAL 1 SECOND WHERE id = ?", claim.OutboxID); err != nil {
+		t.Fatal(err)
+	}
+	redelivered, err := repository.RedeliverWebhook(ctx, tenantID, claim.EventID)
+	if err != nil || redelivered.Status != WebhookDeliveryPending || redelivered.AttemptCount != 0 || redelivered.NextAttemptAt == nil ||
+		redelivered.DeadAt != nil || redelivered.EventID != claim.EventID {
+		t.Fatalf("redelivered = %+v error=%v", redelivered, err)
+	}
+	if _, err := repository.RedeliverWebhook(ctx, tenantID, claim.EventID); !errors.Is(err, ErrWebhookDeliveryNotDead) {
+		t.Fatalf("repeated redelivery error = %v", err)
+	}
+	if _, err := database.Exec("UPDATE t_external_webhook_outbox SET status = 'DELIVERED', delivered_at = CURRENT_TIMESTAMP(3) WHERE id <> ?", claim.OutboxID); err != nil {
+		t.Fatal(err)
+	}
+	again, err := repository.ClaimNextWebhook(ctx, "delivery-log-worker-2", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.OutboxID != claim.OutboxID || again.EventID != claim.EventID || !bytes.Equal(again.Body, claim.Body) || again.AttemptCount != 1 {
+		t.Fatalf("redelivered claim changed event identity or body")
+	}
+	if err := repository.SettleWebhook(ctx, again, WebhookSettlement{
+		Disposition: WebhookPermanentFailure, HTTPStatus: 410, ErrorCode: WebhookErrorHTTPPermanent,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("UPDATE t_external_callback SET disabled_at = CURRENT_TIMESTAMP(3) WHERE external_id = ?", callbackID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.RedeliverWebhook(ctx, tenantID, claim.EventID); !errors.Is(err, ErrWebhookCallbackDisabled) {
+		t.Fatalf("disabled callback redelivery error = %v", err)
+	}
+	assertWebhookState(t, database, claim.OutboxID, "DEAD", 410, WebhookErrorHTTPPermanent, false, true)
+
+	unconfigured := newTestMySQLWebhookOutboxRepository(t, database, 1)
+	if _, err := unconfigured.ListWebhookDeliveries(ctx, tenantID, WebhookDeliveryListOptions{Limit: 1}); !errors.Is(err, ErrExternalJobUnavailable) {
+		t.Fatalf("listing without a cursor key error = %v", err)
+	}
+}
@@ -51,6 +51,7 @@ func EncodeTerminalWebhookEvent(event TerminalWebhookEvent) (string, []byte, []b
 		if result.Cases == nil {
 			result.Cases = []DurableCaseResult{}
 		}
+		result.Subtasks = append([]DurableSubtaskResult(nil), job.Result.Subtasks...)
 		payload.EventType = "judge.job.completed"
 		payload.Result = &result
 	case JobStatusFailed:
@@ -73,3 +74,121 @@ func EncodeTerminalWebhookEvent(event TerminalWebhookEvent) (string, []byte, []b
 	}
 	return payload.EventType, append([]byte(nil), encoded...), append([]byte(nil), encoded...), nil
 }
+
+// WebhookEventPing is the connectivity test event. It has no job, is signed
+// and delivered exactly like a terminal event, and is never retried.
+const WebhookEventPing = "judge.ping"
+
+type PingWebhookEvent struct {
+	EventID    string
+	OccurredAt time.Time
+	TenantID   string
+	CallbackID string
+}
+
+type pingWebhookPayload struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	EventID       string `json:"eventId"`
+	EventType     string `json:"eventType"`
+	OccurredAt    string `json:"occurredAt"`
+	TenantID      string `json:"tenantId"`
+	CallbackID    string `json:"callbackId"`
+}
+
+func EncodePingWebhookEvent(event PingWebhookEvent) (string, []byte, []byte, error) {
+	if !externalIDPattern.MatchString(event.EventID) || event.OccurredAt.IsZero() ||
+		!externalIDPattern.MatchString(event.TenantID) || !externalIDPattern.MatchString(event.CallbackID) {
+		return "", nil, nil, fmt.Errorf("ping webhook identity is invalid")
+	}
+	encoded, err := json.Marshal(pingWebhookPayload{
+		SchemaVersion: terminalWebhookSchemaVersion,
+		EventID:       event.EventID, EventType: WebhookEventPing,
+		OccurredAt: event.OccurredAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		TenantID:   event.TenantID, CallbackID: event.CallbackID,
+	})
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("encode ping webhook payload: %w", err)
+	}
+	return WebhookEventPing, append([]byte(nil), encoded...), append([]byte(nil), encoded...), nil
+}
+
+// Optional job events a callback can subscribe to in addition to the terminal
+// events, which every callback receives.
+const (
+	WebhookEventJobStarted  = "judge.job.started"
+	WebhookEventJobProgress = "judge.job.progress"
+)
+
+// OptionalWebhookEventTypes lists the subscribable event types in their
+// canonical order.
+var OptionalWebhookEventTypes = []string{WebhookEventJobStarted, WebhookEventJobProgress}
+
+// JobProgressWebhookEvent describes a running attempt. Started events carry
+// no progress; progress events report the attempt's compile status once known
+// and how many of the bundle's cases have been judged so far.
+type JobProgressWebhookEvent struct {
+	EventID        string
+	EventType      string
+	OccurredAt     time.Time
+	Job            ExternalJobRecord
+	AttemptNo      uint32
+	CompileStatus  string
+	CompletedCases int
+	TotalCases     int
+}
+
+type jobProgressWebhookPayload struct {
+	SchemaVersion   int       `json:"schemaVersion"`
+	EventID         string    `json:"eventId"`
+	EventType       string    `json:"eventType"`
+	OccurredAt      string    `json:"occurredAt"`
+	TenantID        string    `json:"tenantId"`
+	JobID           string    `json:"jobId"`
+	ClientReference string    `json:"clientReference,omitempty"`
+	Status          JobStatus `json:"status"`
+	Attempt         uint32    `json:"attempt"`
+	CompileStatus   string    `json:"compileStatus,omitempty"`
+	CompletedCases  *int      `json:"completedCases,omitempty"`
+	TotalCases      *int      `json:"totalCases,omitempty"`
+}
+
+func EncodeJobProgressWebhookEvent(event JobProgressWebhookEvent) (string, []byte, []byte, error) {
+	job := event.Job
+	if !externalIDPattern.MatchString(event.EventID) || event.OccurredAt.IsZero() || event.AttemptNo == 0 ||
+		!externalIDPattern.MatchString(job.TenantExternalID) || !externalIDPattern.MatchString(job.ExternalID) ||
+		len(job.ClientReference) > 255 || !utf8.ValidString(job.ClientReference) {
+		return "", nil, nil, fmt.Errorf("job progress webhook identity is invalid")
+	}
+	payload := jobProgressWebhookPayload{
+		SchemaVersion: terminalWebhookSchemaVersion,
+		EventID:       event.EventID, EventType: event.EventType,
+		OccurredAt: event.OccurredAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		TenantID:   job.TenantExternalID, JobID: job.ExternalID,
+		ClientReference: job.ClientReference, Status: JobStatusRunning, Attempt: event.AttemptNo,
+	}
+	switch event.EventType {
+	case WebhookEventJobStarted:
+		if event.CompileStatus != "" || event.CompletedCases != 0 || event.TotalCases != 0 {
+			return "", nil, nil, fmt.Errorf("started webhook event carries progress")
+		}
+	case WebhookEventJobProgress:
+		switch event.CompileStatus {
+		case "", "SUCCEEDED", "FAILED":
+		default:
+			return "", nil, nil, fmt.Errorf("progress webhook compile status is invalid")
+		}
+		if event.CompletedCases < 0 || event.TotalCases <= 0 || event.TotalCases > MaximumBundleCases {
+			return "", nil, nil, fmt.Errorf("progress webhook case counts are invalid")
+		}
+		completed, total := min(event.CompletedCases, event.TotalCases), event.TotalCases
+		payload.CompileStatus = event.CompileStatus
+		payload.CompletedCases, payload.TotalCases = &completed, &total
+	default:
+		return "", nil, nil, fmt.Errorf("webhook event type is not a job progress event")
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("encode job progress webhook payload: %w", err)
+	}
+	return payload.EventType, append([]byte(nil), encoded...), append([]byte(nil), encoded...), nil
+}
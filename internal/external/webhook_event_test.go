@@ -92,3 +92,59 @@ func TestEncodeTerminalWebhookRejectsIncompleteOrNonterminalInput(t *testing.T)
 		})
 	}
 }
+
+func TestEncodePingWebhookCarriesOnlyTheCallbackIdentity(t *testing.T) {
+	now := time.Date(2026, 7, 19, 12, 34, 56, 789_123_456, time.UTC)
+	eventType, semantic, exact, err := EncodePingWebhookEvent(PingWebhookEvent{
+		EventID: "ceirceirceirceirceirceirce", OccurredAt: now,
+		TenantID: "eeirceirceirceirceirceirce", CallbackID: "feirceirceirceirceirceirce",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"schemaVersion":1,"eventId":"ceirceirceirceirceirceirce","eventType":"judge.ping","occurredAt":"2026-07-19T12:34:56.789Z","tenantId":"eeirceirceirceirceirceirce","callbackId":"feirceirceirceirceirceirce"}`
+	if eventType != WebhookEventPing || string(semantic) != want || string(exact) != want {
+		t.Fatalf("type=%q semantic=%s exact=%s", eventType, semantic, exact)
+	}
+	if _, _, _, err := EncodePingWebhookEvent(PingWebhookEvent{EventID: "ceirceirceirceirceirceirce", OccurredAt: now, TenantID: "eeirceirceirceirceirceirce"}); err == nil {
+		t.Fatal("ping without a callback was encoded")
+	}
+}
+
+func TestEncodeJobProgressWebhookEventsCarryAttemptAndCounts(t *testing.T) {
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	job := ExternalJobRecord{
+		ExternalID: "deirceirceirceirceirceirce", TenantExternalID: "eeirceirceirceirceirceirce", ClientReference: "submission-7",
+	}
+	eventType, semantic, exact, err := EncodeJobProgressWebhookEvent(JobProgressWebhookEvent{
+		EventID: "ceirceirceirceirceirceirce", EventType: WebhookEventJobStarted, OccurredAt: now, Job: job, AttemptNo: 2,
+	})
+	want := `{"schemaVersion":1,"eventId":"ceirceirceirceirceirceirce","eventType":"judge.job.started","occurredAt":"2026-10-16T08:00:00.000Z","tenantId":"eeirceirceirceirceirceirce","jobId":"deirceirceirceirceirceirce","clientReference":"submission-7","status":"RUNNING","attempt":2}`
+	if err != nil || eventType != WebhookEventJobStarted || string(semantic) != want || string(exact) != want {
+		t.Fatalf("type=%q body=%s err=%v", eventType, exact, err)
+	}
+
+	_, _, exact, err = EncodeJobProgressWebhookEvent(JobProgressWebhookEvent{
+		EventID: "ceirceirceirceirceirceirce", EventType: WebhookEventJobProgress, OccurredAt: now, Job: job, AttemptNo: 1,
+		CompileStatus: "SUCCEEDED", CompletedCases: 0, TotalCases: 40,
+	})
+	if err != nil || !strings.HasSuffix(string(exact), `"attempt":1,"compileStatus":"SUCCEEDED","completedCases":0,"totalCases":40}`) {
+		t.Fatalf("progress body=%s err=%v", exact, err)
+	}
+
+	for name, event := range map[string]JobProgressWebhookEvent{
+		"terminal type":     {EventType: "judge.job.completed", AttemptNo: 1},
+		"started progress":  {EventType: WebhookEventJobStarted, AttemptNo: 1, TotalCases: 3},
+		"missing attempt":   {EventType: WebhookEventJobStarted},
+		"missing total":     {EventType: WebhookEventJobProgress, AttemptNo: 1},
+		"negative progress": {EventType: WebhookEventJobProgress, AttemptNo: 1, CompletedCases: -1, TotalCases: 3},
+		"compile status":    {EventType: WebhookEventJobProgress, AttemptNo: 1, CompileStatus: "RUNNING", TotalCases: 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			event.EventID, event.OccurredAt, event.Job = "ceirceirceirceirceirceirce", now, job
+			if _, _, _, err := EncodeJobProgressWebhookEvent(event); err == nil {
+				t.Fatal("invalid progress event was encoded")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package external
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrWebhookPingInProgress rejects a ping while an earlier ping of the same
+// callback is still pending, so one caller cannot queue unbounded test
+// traffic against a receiver.
+var ErrWebhookPingInProgress = errors.New("webhook ping is already in progress")
+
+// webhookPingDeliveryWindow bounds how long a ping may wait for a worker
+// before it is dead-lettered as delivery_expired.
+const webhookPingDeliveryWindow = 5 * time.Minute
+
+// MaximumWebhookPingWait bounds how long PingCallback polls for the outcome.
+const MaximumWebhookPingWait = time.Minute
+
+const webhookPingPollInterval = 200 * time.Millisecond
+
+// WebhookPing reports the outcome of one judge.ping delivery. The outcome
+// fields are present only once the worker settled the single attempt, and
+// SignatureAccepted only when the receiver answered at all: it is true for
+// a 2xx answer to the signed request, since receivers must reject a frame
+// whose v1 signature does not verify.
+type WebhookPing struct {
+	EventID           string                `json:"eventId"`
+	CallbackID        string                `json:"callbackId"`
+	Status            WebhookDeliveryStatus `json:"status"`
+	HTTPStatus        *int                  `json:"httpStatus,omitempty"`
+	LatencyMillis     *int64                `json:"latencyMillis,omitempty"`
+	SignatureAccepted *bool                 `json:"signatureAccepted,omitempty"`
+	ErrorCode         string                `json:"errorCode,omitempty"`
+}
+
+// Settled reports whether the ping reached DELIVERED or DEAD.
+func (ping WebhookPing) Settled() bool {
+	return ping.Status == WebhookDeliveryDelivered || ping.Status == WebhookDeliveryDead
+}
@@ -0,0 +1,155 @@
+package external
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var webhookSigningKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// WebhookSigningKeyRing holds the judge-owned Ed25519 keys behind the optional
+// v2 webhook signature. Only the active key signs; every key in the ring is
+// published so receivers can refresh their cache before the active key
+// switches (add-before-switch) and keep verifying until the old key is removed.
+type WebhookSigningKeyRing struct {
+	active string
+	keys   map[string]ed25519.PrivateKey
+}
+
+// WebhookPublicKey is one RFC 8037 OKP JSON Web Key.
+type WebhookPublicKey struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	X         string `json:"x"`
+}
+
+// WebhookPublicKeySet is the RFC 7517 JWK Set published for v2 verification.
+type WebhookPublicKeySet struct {
+	Keys []WebhookPublicKey `json:"keys"`
+}
+
+// NewWebhookSigningKeyRing copies 32-byte Ed25519 seeds keyed by key ID.
+func NewWebhookSigningKeyRing(active string, seeds map[string][]byte) (*WebhookSigningKeyRing, error) {
+	if !webhookSigningKeyIDPattern.MatchString(active) {
+		return nil, fmt.Errorf("webhook signing key ID must contain 1 to 64 letters, digits, '-' or '_'")
+	}
+	if len(seeds) > 16 {
+		return nil, fmt.Errorf("webhook signing key ring may hold at most 16 keys")
+	}
+	keys := make(map[string]ed25519.PrivateKey, len(seeds))
+	for keyID, seed := range seeds {
+		if !webhookSigningKeyIDPattern.MatchString(keyID) {
+			return nil, fmt.Errorf("webhook signing key ID must contain 1 to 64 letters, digits, '-' or '_'")
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("webhook signing key %q must be a 32-byte Ed25519 seed", keyID)
+		}
+		keys[keyID] = ed25519.NewKeyFromSeed(seed)
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active webhook signing key %q is missing", active)
+	}
+	return &WebhookSigningKeyRing{active: active, keys: keys}, nil
+}
+
+// DecodeWebhookSigningKeyRing parses {"<kid>":"<base64 32-byte seed>"}. The
+// v2 scheme is optional: both inputs empty returns a nil ring and no error.
+func DecodeWebhookSigningKeyRing(active, encoded string) (*WebhookSigningKeyRing, error) {
+	if active == "" && strings.TrimSpace(encoded) == "" {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(encoded))
+	first, err := decoder.Token()
+	if err != nil || first != json.Delim('{') {
+		return nil, fmt.Errorf("webhook signing key ring must be a JSON object")
+	}
+	seeds := make(map[string][]byte)
+	defer func() {
+		for _, seed := range seeds {
+			clear(seed)
+		}
+	}()
+	for decoder.More() {
+		rawKeyID, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decode webhook signing key ID")
+		}
+		keyID, ok := rawKeyID.(string)
+		if !ok || !webhookSigningKeyIDPattern.MatchString(keyID) {
+			return nil, fmt.Errorf("webhook signing key ID is invalid")
+		}
+		if _, exists := seeds[keyID]; exists {
+			return nil, fmt.Errorf("webhook signing key %q is duplicated", keyID)
+		}
+		var encodedSeed string
+		if err := decoder.Decode(&encodedSeed); err != nil {
+			return nil, fmt.Errorf("decode webhook signing key %q", keyID)
+		}
+		seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			clear(seed)
+			return nil, fmt.Errorf("webhook signing key %q must be 32 bytes encoded as base64", keyID)
+		}
+		seeds[keyID] = seed
+	}
+	closing, err := decoder.Token()
+	if err != nil || closing != json.Delim('}') {
+		return nil, fmt.Errorf("webhook signing key ring object is incomplete")
+	}
+	var trailing any
+	if err := decoder.Decode(&trailing); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("webhook signing key ring contains trailing data")
+	}
+	return NewWebhookSigningKeyRing(active, seeds)
+}
+
+// ActiveKeyID names the key that signs new deliveries.
+func (ring *WebhookSigningKeyRing) ActiveKeyID() string {
+	if ring == nil {
+		return ""
+	}
+	return ring.active
+}
+
+// PublicKeys returns every key in the ring ordered by key ID.
+func (ring *WebhookSigningKeyRing) PublicKeys() WebhookPublicKeySet {
+	set := WebhookPublicKeySet{Keys: []WebhookPublicKey{}}
+	if ring == nil {
+		return set
+	}
+	for keyID, key := range ring.keys {
+		set.Keys = append(set.Keys, WebhookPublicKey{
+			KeyType: "OKP", Curve: "Ed25519", KeyID: keyID, Use: "sig", Algorithm: "EdDSA",
+			X: base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		})
+	}
+	slices.SortFunc(set.Keys, func(left, right WebhookPublicKey) int { return strings.Compare(left.KeyID, right.KeyID) })
+	return set
+}
+
+// LogValue exposes only the active key ID.
+func (ring *WebhookSigningKeyRing) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("active_key_id", ring.ActiveKeyID()))
+}
+
+// sign returns the v2 signature element "v2=<kid>.<base64url(Ed25519)>". The
+// framing matches v1 apart from its scheme line, so a v1 signature can never
+// be replayed as v2 input or vice versa.
+func (ring *WebhookSigningKeyRing) sign(eventID, timestamp string, body []byte) string {
+	message := make([]byte, 0, len(eventID)+len(timestamp)+len(body)+32)
+	message = fmt.Appendf(message, "v2\n%d\n%s\n%s\n", len(eventID), eventID, timestamp)
+	message = append(message, body...)
+	signature := ed25519.Sign(ring.keys[ring.active], message)
+	return "v2=" + ring.active + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
@@ -0,0 +1,105 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeWebhookSigningKeyRingIsOptionalAndRejectsInvalidConfiguration(t *testing.T) {
+	seed := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x42}, ed25519.SeedSize))
+	if ring, err := DecodeWebhookSigningKeyRing("", ""); ring != nil || err != nil {
+		t.Fatalf("unconfigured ring = %v error=%v", ring, err)
+	}
+	ring, err := DecodeWebhookSigningKeyRing("2026-10", `{"2026-07":"`+seed+`","2026-10":"`+seed+`"}`)
+	if err != nil || ring.ActiveKeyID() != "2026-10" {
+		t.Fatalf("decode valid ring: ring=%v error=%v", ring, err)
+	}
+	for name, input := range map[string]struct{ active, ring string }{
+		"missing ring":   {"k1", ""},
+		"missing active": {"", `{"k1":"` + seed + `"}`},
+		"unknown active": {"k2", `{"k1":"` + seed + `"}`},
+		"invalid key ID": {"k/1", `{"k/1":"` + seed + `"}`},
+		"duplicate key":  {"k1", `{"k1":"` + seed + `","k1":"` + seed + `"}`},
+		"short seed":     {"k1", `{"k1":"` + base64.StdEncoding.EncodeToString([]byte("short")) + `"}`},
+		"trailing data":  {"k1", `{"k1":"` + seed + `"} true`},
+		"array":          {"k1", `[]`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeWebhookSigningKeyRing(input.active, input.ring); err == nil || strings.Contains(err.Error(), seed) {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookSigningKeyRingPublishesEveryPublicKeyAndNoSeed(t *testing.T) {
+	oldSeed, newSeed := bytes.Repeat([]byte{1}, ed25519.SeedSize), bytes.Repeat([]byte{2}, ed25519.SeedSize)
+	ring, err := NewWebhookSigningKeyRing("new", map[string][]byte{"old": oldSeed, "new": newSeed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := ring.PublicKeys()
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != "new" || set.Keys[1].KeyID != "old" {
+		t.Fatalf("key set = %+v", set)
+	}
+	for index, seed := range [][]byte{newSeed, oldSeed} {
+		key := set.Keys[index]
+		want := base64.RawURLEncoding.EncodeToString(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+		if key.KeyType != "OKP" || key.Curve != "Ed25519" || key.Use != "sig" || key.Algorithm != "EdDSA" || key.X != want {
+			t.Fatalf("public key %d = %+v", index, key)
+		}
+	}
+	if rendered := fmt.Sprintf("%+v %v", set, ring.LogValue()); strings.Contains(rendered, base64.RawURLEncoding.EncodeToString(newSeed)) {
+		t.Fatalf("seed leaked: %s", rendered)
+	}
+	var unconfigured *WebhookSigningKeyRing
+	if keys := unconfigured.PublicKeys().Keys; keys == nil || len(keys) != 0 {
+		t.Fatalf("unconfigured key set = %#v", keys)
+	}
+}
+
+func TestWebhookDeliverySendsVerifiableV2SignatureAlongsideV1(t *testing.T) {
+	seed := bytes.Repeat([]byte{3}, ed25519.SeedSize)
+	ring, err := NewWebhookSigningKeyRing("k1", map[string][]byte{"k1": seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := validWebhookDelivery()
+	delivery.SigningKeys = ring
+	var header string
+	deliverer, err := newWebhookDelivererForTest(webhookDoerFunc(func(request *http.Request) (*http.Response, error) {
+		header = request.Header.Get("X-CodeRushOJ-Signature")
+		return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverer.now = func() time.Time { return time.Unix(1784464496, 0) }
+	if disposition, err := deliverer.Deliver(context.Background(), delivery); err != nil || disposition != WebhookDelivered {
+		t.Fatalf("disposition=%q err=%v", disposition, err)
+	}
+	v1, v2, found := strings.Cut(header, ", ")
+	if !found || v1 != signWebhook(delivery.Secret, delivery.EventID, "1784464496", delivery.Body) || !strings.HasPrefix(v2, "v2=k1.") {
+		t.Fatalf("signature header = %q", header)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(v2, "v2=k1."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _ := base64.RawURLEncoding.DecodeString(ring.PublicKeys().Keys[0].X)
+	message := append([]byte("v2\n26\nceirceirceirceirceirceirce\n1784464496\n"), delivery.Body...)
+	if !ed25519.Verify(publicKey, message, signature) {
+		t.Fatal("v2 signature does not verify against the published key")
+	}
+	if ed25519.Verify(publicKey, append([]byte("v1"), message[2:]...), signature) {
+		t.Fatal("v2 signature verified under v1 framing")
+	}
+}
@@ -55,6 +55,44 @@ func TestWebhookDeliverySignsExactBodyAndRequiredHeaders(t *testing.T) {
 	}
 }
 
+func TestWebhookDeliverySignsWithTheCurrentAndPreviousSecret(t *testing.T) {
+	now := time.Date(2026, 7, 19, 12, 34, 56, 0, time.UTC)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	previous := []byte("fedcba9876543210fedcba9876543210")
+	body := []byte(`{"eventId":"ceirceirceirceirceirceirce","type":"judge.job.completed"}`)
+	sign := func(key []byte) string {
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte("v1\n26\nceirceirceirceirceirceirce\n1784464496\n"))
+		_, _ = mac.Write(body)
+		return "v1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	var signature string
+	deliverer, err := newWebhookDelivererForTest(webhookDoerFunc(func(request *http.Request) (*http.Response, error) {
+		signature = request.Header.Get("X-CodeRushOJ-Signature")
+		return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverer.now = func() time.Time { return now }
+	disposition, err := deliverer.Deliver(context.Background(), WebhookDelivery{
+		EventID: "ceirceirceirceirceirceirce", DestinationURL: "https://oj.example.com/hooks/croj",
+		Secret: secret, PreviousSecret: previous, Body: body,
+	})
+	if err != nil || disposition != WebhookDelivered {
+		t.Fatalf("disposition=%q err=%v", disposition, err)
+	}
+	if want := sign(secret) + ", " + sign(previous); signature != want {
+		t.Fatalf("signature=%q want=%q", signature, want)
+	}
+	if _, err := deliverer.Deliver(context.Background(), WebhookDelivery{
+		EventID: "ceirceirceirceirceirceirce", DestinationURL: "https://oj.example.com/hooks/croj",
+		Secret: secret, PreviousSecret: previous[:16], Body: body,
+	}); err == nil {
+		t.Fatal("short previous secret was accepted")
+	}
+}
+
 func TestWebhookDeliveryClassifiesHTTPStatusWithoutFollowingRedirects(t *testing.T) {
 	for _, test := range []struct {
 		status      int
@@ -8,10 +8,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/bits"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/logging"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -61,6 +66,7 @@ type webhookDelivererFactory interface {
 type WebhookWorkerConfig struct {
 	Repository         WebhookOutboxRepository
 	CallbackCipher     *CallbackCipher
+	SigningKeys        *WebhookSigningKeyRing
 	WorkerID           string
 	LeaseDuration      time.Duration
 	RequestTimeout     time.Duration
@@ -79,6 +85,7 @@ type WebhookWorkerConfig struct {
 type WebhookWorker struct {
 	repository        webhookOutboxRepository
 	decryptor         callbackSecretDecryptor
+	signingKeys       *WebhookSigningKeyRing
 	factory           webhookDelivererFactory
 	workerID          string
 	leaseDuration     time.Duration
@@ -122,7 +129,7 @@ func newWebhookWorker(config WebhookWorkerConfig, decryptor callbackSecretDecryp
 		return nil, fmt.Errorf("webhook random source and clock are required")
 	}
 	return &WebhookWorker{
-		repository: config.Repository, decryptor: decryptor, factory: factory,
+		repository: config.Repository, decryptor: decryptor, signingKeys: config.SigningKeys, factory: factory,
 		workerID: config.WorkerID, leaseDuration: config.LeaseDuration,
 		requestTimeout: config.RequestTimeout, dialTimeout: config.DialTimeout,
 		baseRetryDelay: config.BaseRetryDelay, maximumRetry: config.MaximumRetryDelay,
@@ -242,6 +249,21 @@ func (worker *WebhookWorker) processNext(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	return worker.deliverClaim(ctx, claim, localClaimStart)
+}
+
+// deliverClaim settles one claimed event under a span. Idle polls never
+// reach it, so an empty outbox produces no spans.
+func (worker *WebhookWorker) deliverClaim(ctx context.Context, claim WebhookClaim, localClaimStart time.Time) (err error) {
+	ctx, span := startSpan(ctx, "WebhookWorker.deliver",
+		attribute.String("croj.tenant", claim.TenantID),
+		attribute.String("croj.webhook.event_id", claim.EventID),
+		attribute.String("croj.webhook.event_type", claim.EventType),
+		attribute.Int("croj.webhook.attempt", int(claim.AttemptCount)),
+		attribute.String("croj.worker", worker.workerID),
+	)
+	defer func() { endSpan(span, err) }()
+	ctx = logging.With(ctx, logging.Tenant(claim.TenantID), logging.Worker(worker.workerID), slog.String("event_id", claim.EventID))
 	localClaimTime := worker.now()
 	databaseClaimTime := claim.LeaseUntil.Add(-worker.leaseDuration)
 	claimElapsed := worker.elapsedSince(localClaimStart)
@@ -250,6 +272,7 @@ func (worker *WebhookWorker) processNext(ctx context.Context) error {
 	}
 	databaseReceiveTime := databaseClaimTime.Add(claimElapsed)
 	if !claim.LeaseUntil.After(databaseClaimTime) {
+		metrics.RecordWebhookDelivery("lease_expired", "")
 		return nil
 	}
 	if !claim.ExpiresAt.After(databaseClaimTime) {
@@ -268,6 +291,18 @@ func (worker *WebhookWorker) processNext(ctx context.Context) error {
 	if err != nil {
 		return worker.settle(ctx, claim, WebhookSettlement{Disposition: WebhookPermanentFailure, ErrorCode: WebhookErrorCallbackDecrypt})
 	}
+	var previousSecret []byte
+	if previous, ok := claim.PreviousEncryptedSecret(); ok {
+		previousSecret, err = worker.decryptor.Decrypt(claim.TenantID, claim.CallbackID, claim.DestinationURL, previous)
+		defer clear(previousSecret)
+		clear(previous.Ciphertext)
+		clear(previous.Nonce)
+		clear(claim.previousSecret.Ciphertext)
+		clear(claim.previousSecret.Nonce)
+		if err != nil {
+			return worker.settle(ctx, claim, WebhookSettlement{Disposition: WebhookPermanentFailure, ErrorCode: WebhookErrorCallbackDecrypt})
+		}
+	}
 	deliverer, err := worker.cachedDeliverer(claim.AllowedHost, claim.AllowedPort)
 	if err != nil {
 		return worker.settle(ctx, claim, WebhookSettlement{Disposition: WebhookPermanentFailure, ErrorCode: WebhookErrorConfiguration})
@@ -275,23 +310,35 @@ func (worker *WebhookWorker) processNext(ctx context.Context) error {
 	databaseBeforeDelivery := estimatedWebhookDatabaseTime(databaseReceiveTime, worker.elapsedSince(localClaimTime))
 	remainingLease := claim.LeaseUntil.Sub(databaseBeforeDelivery)
 	if remainingLease <= 0 {
+		metrics.RecordWebhookDelivery("lease_expired", "")
 		return nil
 	}
 	// LeaseUntil is a database wall-clock value. A duration-based context keeps
 	// host clock offset from cancelling a live database lease prematurely.
 	deliveryContext, cancel := context.WithTimeout(ctx, remainingLease)
+	deliveryStart := worker.now()
 	outcome := deliverer.DeliverOutcome(deliveryContext, WebhookDelivery{
-		EventID: claim.EventID, DestinationURL: claim.DestinationURL, Secret: secret, Body: claim.Body,
+		EventID: claim.EventID, DestinationURL: claim.DestinationURL, Secret: secret, PreviousSecret: previousSecret, Body: claim.Body,
+		SigningKeys: worker.signingKeys,
 	}, worker.maximumRetry)
+	latency := worker.elapsedSince(deliveryStart)
 	cancel()
 	if err := context.Cause(ctx); err != nil {
 		return err
 	}
 	databaseNow := estimatedWebhookDatabaseTime(databaseReceiveTime, worker.elapsedSince(localClaimTime))
 	if !claim.LeaseUntil.After(databaseNow) {
+		metrics.RecordWebhookDelivery("lease_expired", outcome.ErrorCode)
 		return nil
 	}
+	span.SetAttributes(
+		attribute.String("croj.webhook.disposition", string(outcome.Disposition)),
+		attribute.Int("http.response.status_code", outcome.HTTPStatus),
+	)
 	settlement := WebhookSettlement{Disposition: outcome.Disposition, HTTPStatus: outcome.HTTPStatus, ErrorCode: outcome.ErrorCode}
+	if latency > 0 && latency <= worker.leaseDuration {
+		settlement.Latency = latency
+	}
 	if outcome.Disposition == WebhookRetry {
 		delay := worker.baseRetryDelay / 2
 		if claim.AttemptCount < worker.maximumAttempts {
@@ -315,8 +362,20 @@ func estimatedWebhookDatabaseTime(databaseClaimTime time.Time, elapsed time.Dura
 func (worker *WebhookWorker) settle(ctx context.Context, claim WebhookClaim, settlement WebhookSettlement) error {
 	err := worker.repository.SettleWebhook(ctx, claim, settlement)
 	if errors.Is(err, ErrWebhookLeaseLost) {
+		metrics.RecordWebhookDelivery("lease_lost", settlement.ErrorCode)
 		return nil
 	}
+	if err == nil {
+		metrics.RecordWebhookDelivery(string(settlement.Disposition), settlement.ErrorCode)
+		if settlement.Disposition != WebhookDelivered {
+			slog.WarnContext(ctx, "webhook delivery not accepted",
+				"disposition", string(settlement.Disposition),
+				"error_code", settlement.ErrorCode,
+				"http_status", settlement.HTTPStatus,
+				"attempt", claim.AttemptCount,
+			)
+		}
+	}
 	return err
 }
 
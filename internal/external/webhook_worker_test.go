@@ -76,11 +76,13 @@ func (decryptor *callbackDecryptorStub) Decrypt(_ string, _ string, _ string, en
 }
 
 type webhookOutcomeDeliverer struct {
-	outcome WebhookOutcome
-	block   bool
-	secret  []byte
-	calls   int
-	started chan struct{}
+	outcome        WebhookOutcome
+	block          bool
+	secret         []byte
+	previousSecret []byte
+	keys           *WebhookSigningKeyRing
+	calls          int
+	started        chan struct{}
 }
 
 type webhookContextProbeDeliverer struct{}
@@ -95,6 +97,8 @@ func (webhookContextProbeDeliverer) DeliverOutcome(ctx context.Context, _ Webhoo
 func (deliverer *webhookOutcomeDeliverer) DeliverOutcome(ctx context.Context, delivery WebhookDelivery, _ time.Duration) WebhookOutcome {
 	deliverer.calls++
 	deliverer.secret = delivery.Secret
+	deliverer.previousSecret = delivery.PreviousSecret
+	deliverer.keys = delivery.SigningKeys
 	if deliverer.block {
 		if deliverer.started != nil {
 			close(deliverer.started)
@@ -242,6 +246,30 @@ func TestWebhookWorkerClaimDecryptDeliverSettleAndClearsSecret(t *testing.T) {
 	}
 }
 
+func TestWebhookWorkerSignsWithThePreviousSecretDuringItsGracePeriod(t *testing.T) {
+	now := time.Date(2026, 7, 20, 3, 0, 0, 0, time.UTC)
+	claim := validWebhookWorkerClaim(now)
+	claim.previousSecret = &EncryptedCallbackSecret{Ciphertext: bytes.Repeat([]byte{4}, 48), Nonce: bytes.Repeat([]byte{5}, 12), KeyVersion: 1}
+	secret := bytes.Repeat([]byte{0x5b}, 32)
+	decryptor := &callbackDecryptorStub{secret: secret}
+	deliverer := &webhookOutcomeDeliverer{outcome: WebhookOutcome{Disposition: WebhookDelivered, HTTPStatus: http.StatusNoContent}}
+	worker := newWorkerForTest(t, &webhookRepositoryStub{claim: claim}, decryptor, &delivererFactoryStub{deliverer: deliverer},
+		now, bytes.NewReader(make([]byte, 64)), 2)
+
+	if err := worker.processNext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if decryptor.calls != 2 || deliverer.calls != 1 || len(deliverer.previousSecret) != 32 {
+		t.Fatalf("decrypt=%d deliver=%d previous=%d bytes", decryptor.calls, deliverer.calls, len(deliverer.previousSecret))
+	}
+	if !bytes.Equal(deliverer.previousSecret, make([]byte, 32)) {
+		t.Fatal("previous secret was not cleared")
+	}
+	if !bytes.Equal(claim.previousSecret.Ciphertext, make([]byte, 48)) || !bytes.Equal(decryptor.encrypted.Ciphertext, make([]byte, 48)) {
+		t.Fatal("encrypted previous secret copies were not cleared")
+	}
+}
+
 func TestWebhookWorkerClearsPartialPlaintextReturnedWithDecryptError(t *testing.T) {
 	now := time.Now().UTC()
 	partial := bytes.Repeat([]byte{0x6a}, 32)
@@ -626,3 +654,36 @@ func newWorkerForTest(t *testing.T, repository webhookOutboxRepository, decrypto
 	worker.elapsedSince = func(start time.Time) time.Duration { return worker.now().Sub(start) }
 	return worker
 }
+
+func TestWebhookWorkerRecordsTheHTTPRoundTripLatency(t *testing.T) {
+	now := time.Date(2026, 7, 20, 3, 0, 0, 0, time.UTC)
+	repository := &webhookRepositoryStub{claim: validWebhookWorkerClaim(now)}
+	deliverer := &webhookOutcomeDeliverer{outcome: WebhookOutcome{Disposition: WebhookDelivered, HTTPStatus: http.StatusOK}}
+	worker := newWorkerForTest(t, repository, &callbackDecryptorStub{secret: bytes.Repeat([]byte{1}, 32)},
+		&delivererFactoryStub{deliverer: deliverer}, now, bytes.NewReader(make([]byte, 64)), 2)
+	worker.elapsedSince = func(time.Time) time.Duration { return 250 * time.Millisecond }
+	if err := worker.processNext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repository.settlements) != 1 || repository.settlements[0].Latency != 250*time.Millisecond {
+		t.Fatalf("settlements=%+v", repository.settlements)
+	}
+}
+
+func TestWebhookWorkerPassesTheSigningKeyRingToEveryDelivery(t *testing.T) {
+	now := time.Date(2026, 7, 20, 3, 0, 0, 0, time.UTC)
+	ring, err := NewWebhookSigningKeyRing("k1", map[string][]byte{"k1": bytes.Repeat([]byte{4}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverer := &webhookOutcomeDeliverer{outcome: WebhookOutcome{Disposition: WebhookDelivered, HTTPStatus: http.StatusOK}}
+	worker := newWorkerForTest(t, &webhookRepositoryStub{claim: validWebhookWorkerClaim(now)}, &callbackDecryptorStub{secret: bytes.Repeat([]byte{1}, 32)},
+		&delivererFactoryStub{deliverer: deliverer}, now, bytes.NewReader(make([]byte, 64)), 2)
+	worker.signingKeys = ring
+	if err := worker.processNext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if deliverer.calls != 1 || deliverer.keys != ring {
+		t.Fatalf("calls=%d keys=%v", deliverer.calls, deliverer.keys)
+	}
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+const (
+	AdminUsageExportPath   = "/admin/v1/usage-export"
+	minimumAdminTokenBytes = 32
+)
+
+type UsageExporter interface {
+	ExportUsage(context.Context, external.UsageExportQuery) ([]external.UsageExportRow, error)
+}
+
+// AdminHandler serves operator endpoints. It must be mounted on a listener
+// that tenant traffic cannot reach; one static bearer token authenticates
+// every request.
+type AdminHandler struct {
+	tokenDigest [sha256.Size]byte
+	usage       UsageExporter
+}
+
+func NewAdminHandler(token string, usage UsageExporter) (*AdminHandler, error) {
+	if len(token) < minimumAdminTokenBytes {
+		return nil, fmt.Errorf("admin token must contain at least %d bytes", minimumAdminTokenBytes)
+	}
+	if usage == nil {
+		return nil, errors.New("usage exporter is required")
+	}
+	return &AdminHandler{tokenDigest: sha256.Sum256([]byte(token)), usage: usage}, nil
+}
+
+func (handler *AdminHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	requestID := newRequestID()
+	response.Header().Set("X-Request-Id", requestID)
+	if request.URL.Path != AdminUsageExportPath {
+		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
+		return
+	}
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET for this resource.", requestID))
+		return
+	}
+	if !handler.authorized(request) {
+		response.Header().Set("WWW-Authenticate", `Bearer realm="judge-admin"`)
+		writeProblem(response, problemFor(http.StatusUnauthorized, "unauthorized", "Unauthorized", "Provide the admin bearer token.", requestID))
+		return
+	}
+	handler.handleUsageExport(response, request, requestID)
+}
+
+// authorized compares digests so the comparison time does not depend on
+// the length of the presented token.
+func (handler *AdminHandler) authorized(request *http.Request) bool {
+	values := request.Header.Values("Authorization")
+	if len(values) != 1 {
+		return false
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found {
+		return false
+	}
+	digest := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(digest[:], handler.tokenDigest[:]) == 1
+}
+
+func (handler *AdminHandler) handleUsageExport(response http.ResponseWriter, request *http.Request, requestID string) {
+	values, err := url.ParseQuery(request.URL.RawQuery)
+	if err == nil {
+		for key, entries := range values {
+			if (key != "tenant" && key != "from" && key != "to" && key != "format") || len(entries) != 1 {
+				err = external.ErrInvalidUsageExport
+			}
+		}
+	}
+	format := external.UsageExportCSV
+	if err == nil && values.Has("format") {
+		format, err = external.ParseUsageExportFormat(values.Get("format"))
+	}
+	var query external.UsageExportQuery
+	if err == nil {
+		query, err = external.ParseUsageExportQuery(values.Get("tenant"), values.Get("from"), values.Get("to"))
+	}
+	if err != nil {
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-usage-export", "Invalid usage export",
+			"Use from and to as YYYY-MM-DD days at most 366 days apart, an optional tenant ID, and format csv or jsonl.", requestID))
+		return
+	}
+	rows, err := handler.usage.ExportUsage(request.Context(), query)
+	if errors.Is(err, external.ErrUsageTenantNotFound) {
+		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested tenant does not exist.", requestID))
+		return
+	}
+	var rendered bytes.Buffer
+	if err == nil {
+		err = external.WriteUsageExport(&rendered, format, rows)
+	}
+	if err != nil {
+		slog.ErrorContext(request.Context(), "Usage export failed", "request_id", requestID, "error", err)
+		response.Header().Set("Retry-After", "5")
+		writeProblem(response, problemFor(http.StatusServiceUnavailable, "usage-export-unavailable", "Usage export unavailable", "Retry the export later.", requestID))
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if format == external.UsageExportJSONL {
+		contentType = "application/x-ndjson"
+	}
+	response.Header().Set("Content-Type", contentType)
+	response.Header().Set("Cache-Control", "no-store")
+	response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="usage-%s-%s.%s"`,
+		query.From.Format("2006-01-02"), query.To.Format("2006-01-02"), format))
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(rendered.Bytes())
+}
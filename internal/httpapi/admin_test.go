@@ -0,0 +1,136 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+const testAdminToken = "admin-token-with-at-least-32-bytes"
+
+type usageExporterStub struct {
+	rows  []external.UsageExportRow
+	err   error
+	query external.UsageExportQuery
+	calls int
+}
+
+func (exporter *usageExporterStub) ExportUsage(_ context.Context, query external.UsageExportQuery) ([]external.UsageExportRow, error) {
+	exporter.query = query
+	exporter.calls++
+	return exporter.rows, exporter.err
+}
+
+func adminRequest(method, target, token string) *http.Request {
+	request := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request
+}
+
+func TestNewAdminHandlerRejectsShortTokens(t *testing.T) {
+	if _, err := NewAdminHandler("too-short", &usageExporterStub{}); err == nil {
+		t.Fatal("expected a short admin token to be rejected")
+	}
+	if _, err := NewAdminHandler(testAdminToken, nil); err == nil {
+		t.Fatal("expected a missing exporter to be rejected")
+	}
+}
+
+func TestAdminUsageExportStreamsDeterministicFormats(t *testing.T) {
+	exporter := &usageExporterStub{rows: []external.UsageExportRow{
+		{TenantID: "tenant-7", Day: "2026-09-01", BundleStorageBytes: 4096},
+		{TenantID: "tenant-7", Day: "2026-09-01", Language: "cpp17", JobExecutionMillis: 1500, JobsSucceeded: 2, JobsCancelled: 1},
+	}}
+	handler, err := NewAdminHandler(testAdminToken, exporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, adminRequest(http.MethodGet, AdminUsageExportPath+"?tenant=ceirceirceirceirceirceirce&from=2026-09-01&to=2026-09-30", testAdminToken))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "text/csv; charset=utf-8" ||
+		recorder.Header().Get("Cache-Control") != "no-store" ||
+		recorder.Header().Get("Content-Disposition") != `attachment; filename="usage-2026-09-01-2026-09-30.csv"` {
+		t.Fatalf("status=%d headers=%v", recorder.Code, recorder.Header())
+	}
+	want := "tenant_id,day,language,job_execution_millis,run_execution_millis,jobs_succeeded,jobs_failed,jobs_cancelled,bundle_storage_bytes\n" +
+		"tenant-7,2026-09-01,,0,0,0,0,0,4096\n" +
+		"tenant-7,2026-09-01,cpp17,1500,0,2,0,1,0\n"
+	if recorder.Body.String() != want {
+		t.Fatalf("body = %q", recorder.Body.String())
+	}
+	if exporter.query.TenantID != "ceirceirceirceirceirceirce" || exporter.query.From.Format(time.DateOnly) != "2026-09-01" || exporter.query.To.Format(time.DateOnly) != "2026-09-30" {
+		t.Fatalf("query = %+v", exporter.query)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, adminRequest(http.MethodGet, AdminUsageExportPath+"?from=2026-09-01&to=2026-09-01&format=jsonl", testAdminToken))
+	wantJSONL := `{"tenantId":"tenant-7","day":"2026-09-01","language":"","jobExecutionMillis":0,"runExecutionMillis":0,"jobsSucceeded":0,"jobsFailed":0,"jobsCancelled":0,"bundleStorageBytes":4096}` + "\n" +
+		`{"tenantId":"tenant-7","day":"2026-09-01","language":"cpp17","jobExecutionMillis":1500,"runExecutionMillis":0,"jobsSucceeded":2,"jobsFailed":0,"jobsCancelled":1,"bundleStorageBytes":0}` + "\n"
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/x-ndjson" || recorder.Body.String() != wantJSONL {
+		t.Fatalf("status=%d type=%q body=%q", recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.String())
+	}
+	if exporter.query.TenantID != "" {
+		t.Fatalf("export without tenant should cover every tenant: %+v", exporter.query)
+	}
+}
+
+func TestAdminUsageExportRejectsUnauthenticatedAndInvalidRequests(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		method string
+		target string
+		token  string
+		status int
+	}{
+		{"missing token", http.MethodGet, AdminUsageExportPath + "?from=2026-09-01&to=2026-09-30", "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, AdminUsageExportPath + "?from=2026-09-01&to=2026-09-30", testAdminToken + "x", http.StatusUnauthorized},
+		{"wrong method", http.MethodPost, AdminUsageExportPath, testAdminToken, http.StatusMethodNotAllowed},
+		{"unknown path", http.MethodGet, "/admin/v1/tenants", testAdminToken, http.StatusNotFound},
+		{"missing bounds", http.MethodGet, AdminUsageExportPath + "?from=2026-09-01", testAdminToken, http.StatusBadRequest},
+		{"reversed range", http.MethodGet, AdminUsageExportPath + "?from=2026-09-30&to=2026-09-01", testAdminToken, http.StatusBadRequest},
+		{"range too long", http.MethodGet, AdminUsageExportPath + "?from=2025-01-01&to=2026-09-01", testAdminToken, http.StatusBadRequest},
+		{"unknown format", http.MethodGet, AdminUsageExportPath + "?from=2026-09-01&to=2026-09-30&format=xlsx", testAdminToken, http.StatusBadRequest},
+		{"unknown parameter", http.MethodGet, AdminUsageExportPath + "?from=2026-09-01&to=2026-09-30&language=cpp17", testAdminToken, http.StatusBadRequest},
+		{"repeated parameter", http.MethodGet, AdminUsageExportPath + "?from=2026-09-01&from=2026-09-02&to=2026-09-30", testAdminToken, http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			exporter := &usageExporterStub{}
+			handler, err := NewAdminHandler(testAdminToken, exporter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, adminRequest(test.method, test.target, test.token))
+			if recorder.Code != test.status || recorder.Header().Get("Content-Type") != "application/problem+json" || exporter.calls != 0 {
+				t.Fatalf("status=%d type=%q calls=%d body=%s", recorder.Code, recorder.Header().Get("Content-Type"), exporter.calls, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminUsageExportMapsExporterFailures(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+	}{
+		{external.ErrUsageTenantNotFound, http.StatusNotFound},
+		{errors.New("mysql unavailable"), http.StatusServiceUnavailable},
+	} {
+		handler, err := NewAdminHandler(testAdminToken, &usageExporterStub{err: test.err})
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, adminRequest(http.MethodGet, AdminUsageExportPath+"?tenant=ceirceirceirceirceirceirce&from=2026-09-01&to=2026-09-30", testAdminToken))
+		if recorder.Code != test.status || recorder.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("%v: status=%d body=%s", test.err, recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -24,6 +24,8 @@ const (
 	ScopeJobSubmit        = external.ScopeJobSubmit
 	ScopeJobRead          = external.ScopeJobRead
 	ScopeJobCancel        = external.ScopeJobCancel
+	ScopeRunExecute       = external.ScopeRunExecute
+	ScopeCallbackWrite    = external.ScopeCallbackWrite
 )
 
 var (
@@ -34,6 +36,7 @@ var (
 
 type Credential = external.Credential
 type CredentialStore = external.CredentialStore
+type CredentialUsageRecorder = external.CredentialUsageRecorder
 
 type Principal struct {
 	TenantID string
@@ -49,20 +52,44 @@ type Authenticator struct {
 	store  CredentialStore
 	pepper []byte
 	now    func() time.Time
+	usage  CredentialUsageRecorder
 }
 
-func NewAuthenticator(store CredentialStore, pepper []byte) (*Authenticator, error) {
+type AuthenticatorOption func(*Authenticator) error
+
+// WithCredentialUsageRecorder reports every successful authentication to
+// recorder, which backs the last-used time of API keys.
+func WithCredentialUsageRecorder(recorder CredentialUsageRecorder) AuthenticatorOption {
+	return func(authenticator *Authenticator) error {
+		if recorder == nil {
+			return fmt.Errorf("credential usage recorder is required")
+		}
+		authenticator.usage = recorder
+		return nil
+	}
+}
+
+func NewAuthenticator(store CredentialStore, pepper []byte, options ...AuthenticatorOption) (*Authenticator, error) {
 	if store == nil {
 		return nil, fmt.Errorf("credential store is required")
 	}
 	if len(pepper) < sha256.Size {
 		return nil, fmt.Errorf("credential pepper must contain at least 256 bits")
 	}
-	return &Authenticator{
+	authenticator := &Authenticator{
 		store:  store,
 		pepper: append([]byte(nil), pepper...),
 		now:    time.Now,
-	}, nil
+	}
+	for _, option := range options {
+		if option == nil {
+			return nil, fmt.Errorf("authenticator option is required")
+		}
+		if err := option(authenticator); err != nil {
+			return nil, err
+		}
+	}
+	return authenticator, nil
 }
 
 func (authenticator *Authenticator) Authenticate(ctx context.Context, authorization string) (Principal, error) {
@@ -93,6 +120,9 @@ func (authenticator *Authenticator) Authenticate(ctx context.Context, authorizat
 	for _, scope := range credential.Scopes {
 		principal.scopes[scope] = struct{}{}
 	}
+	if authenticator.usage != nil {
+		authenticator.usage.RecordCredentialUse(prefix, now)
+	}
 	return principal, nil
 }
 
@@ -137,6 +137,40 @@ func TestAuthenticatorAcceptsCaseInsensitiveBearerWithOneOrMoreSpaces(t *testing
 	}
 }
 
+type credentialUsageStub struct {
+	prefixes []string
+	times    []time.Time
+}
+
+func (usage *credentialUsageStub) RecordCredentialUse(prefix string, usedAt time.Time) {
+	usage.prefixes = append(usage.prefixes, prefix)
+	usage.times = append(usage.times, usedAt)
+}
+
+func TestAuthenticatorRecordsUseOnlyForAcceptedKeys(t *testing.T) {
+	pepper := []byte("0123456789abcdef0123456789abcdef")
+	secret := base64.RawURLEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	key := "croj_public12_" + secret
+	now := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	store := &credentialStoreStub{credential: &Credential{TenantID: "tenant-7", Digest: keyDigest(pepper, key), Scopes: []Scope{ScopeJobRead}}}
+	usage := &credentialUsageStub{}
+	authenticator, err := NewAuthenticator(store, pepper, WithCredentialUsageRecorder(usage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator.now = func() time.Time { return now }
+	if _, err := authenticator.Authenticate(context.Background(), "Bearer "+key); err != nil {
+		t.Fatal(err)
+	}
+	store.credential.RevokedAt = pointer(now)
+	if _, err := authenticator.Authenticate(context.Background(), "Bearer "+key); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("revoked key error = %v", err)
+	}
+	if len(usage.prefixes) != 1 || usage.prefixes[0] != "public12" || !usage.times[0].Equal(now) {
+		t.Fatalf("recorded uses = %v at %v", usage.prefixes, usage.times)
+	}
+}
+
 func TestAuthenticatorRejectsUnsafeConfiguration(t *testing.T) {
 	if _, err := NewAuthenticator(nil, make([]byte, 32)); err == nil {
 		t.Fatal("expected nil store rejection")
@@ -144,6 +178,9 @@ func TestAuthenticatorRejectsUnsafeConfiguration(t *testing.T) {
 	if _, err := NewAuthenticator(&credentialStoreStub{}, []byte("too-short")); err == nil {
 		t.Fatal("expected short pepper rejection")
 	}
+	if _, err := NewAuthenticator(&credentialStoreStub{}, make([]byte, 32), WithCredentialUsageRecorder(nil)); err == nil {
+		t.Fatal("expected nil usage recorder rejection")
+	}
 }
 
 func keyDigest(pepper []byte, key string) []byte {
@@ -8,6 +8,7 @@ import (
 	"math"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +19,8 @@ import (
 type BundleApplication interface {
 	UploadWithAdmission(context.Context, string, string, io.Reader, external.BundleUploadAdmission) (external.BundleMetadata, bool, error)
 	Get(context.Context, string, string) (external.BundleMetadata, error)
+	List(context.Context, string, external.BundleListOptions) (external.BundleListResult, error)
+	Delete(context.Context, string, string) error
 }
 
 func WithBundleApplication(application BundleApplication) ServerOption {
@@ -49,11 +52,18 @@ func (server *Server) serveBundleCollection(response http.ResponseWriter, reques
 		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
 		return
 	}
-	if request.Method != http.MethodPost {
-		response.Header().Set("Allow", http.MethodPost)
-		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST for this resource.", requestID))
-		return
+	switch request.Method {
+	case http.MethodPost:
+		server.handleBundleUpload(response, request, requestID)
+	case http.MethodGet:
+		server.handleBundleList(response, request, requestID)
+	default:
+		response.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET or POST for this resource.", requestID))
 	}
+}
+
+func (server *Server) handleBundleUpload(response http.ResponseWriter, request *http.Request, requestID string) {
 	principal, ok := server.authenticate(response, request, requestID, ScopeBundleWrite)
 	if !ok {
 		return
@@ -132,17 +142,98 @@ func (server *Server) serveBundleCollection(response http.ResponseWriter, reques
 	_ = encodeJSON(response, metadata)
 }
 
+func (server *Server) handleBundleList(response http.ResponseWriter, request *http.Request, requestID string) {
+	principal, ok := server.authenticate(response, request, requestID, ScopeBundleRead)
+	if !ok {
+		return
+	}
+	options, err := parseBundleListQuery(request)
+	if err != nil {
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use only cursor, limit (1-100), a documented status, and RFC 3339 createdAfter/createdBefore bounds.", requestID))
+		return
+	}
+	page, err := server.bundles.List(request.Context(), principal.TenantID, options)
+	if err != nil {
+		if errors.Is(err, external.ErrInvalidBundleListQuery) {
+			writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use an untampered cursor issued for this tenant and filter.", requestID))
+			return
+		}
+		writeBundleProblem(response, requestID, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []external.BundleSummary{}
+	}
+	writeJSON(response, http.StatusOK, page)
+}
+
+func parseBundleListQuery(request *http.Request) (external.BundleListOptions, error) {
+	values, err := url.ParseQuery(request.URL.RawQuery)
+	if err != nil {
+		return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+	}
+	for key, entries := range values {
+		switch key {
+		case "cursor", "limit", "status", "createdAfter", "createdBefore":
+		default:
+			return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+		}
+		if len(entries) != 1 {
+			return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+		}
+	}
+	options := external.BundleListOptions{Cursor: values.Get("cursor"), Limit: 50}
+	if len(options.Cursor) > 512 {
+		return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+	}
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 100 {
+			return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+		}
+		options.Limit = limit
+	}
+	if raw := values.Get("status"); raw != "" {
+		options.Status = external.BundleStatus(raw)
+		switch options.Status {
+		case external.BundleStatusPending, external.BundleStatusReady, external.BundleStatusAbandoned, external.BundleStatusRetired:
+		default:
+			return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+		}
+	}
+	for key, target := range map[string]*time.Time{"createdAfter": &options.CreatedAfter, "createdBefore": &options.CreatedBefore} {
+		raw := values.Get(key)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil || len(raw) > 64 {
+			return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+		}
+		*target = parsed.UTC()
+	}
+	if !options.CreatedAfter.IsZero() && !options.CreatedBefore.IsZero() && !options.CreatedAfter.Before(options.CreatedBefore) {
+		return external.BundleListOptions{}, external.ErrInvalidBundleListQuery
+	}
+	return options, nil
+}
+
 func (server *Server) serveBundleMetadata(response http.ResponseWriter, request *http.Request, requestID string) {
 	if server.bundles == nil {
 		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
 		return
 	}
-	if request.Method != http.MethodGet {
-		response.Header().Set("Allow", http.MethodGet)
-		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET for this resource.", requestID))
+	scope := ScopeBundleRead
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		scope = ScopeBundleWrite
+	default:
+		response.Header().Set("Allow", http.MethodGet+", "+http.MethodDelete)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET or DELETE for this resource.", requestID))
 		return
 	}
-	principal, ok := server.authenticate(response, request, requestID, ScopeBundleRead)
+	principal, ok := server.authenticate(response, request, requestID, scope)
 	if !ok {
 		return
 	}
@@ -151,6 +242,15 @@ func (server *Server) serveBundleMetadata(response http.ResponseWriter, request
 		writeBundleProblem(response, requestID, external.ErrBundleNotFound)
 		return
 	}
+	if request.Method == http.MethodDelete {
+		if err := server.bundles.Delete(request.Context(), principal.TenantID, bundleID); err != nil {
+			writeBundleProblem(response, requestID, err)
+			return
+		}
+		response.Header().Set("Cache-Control", "no-store")
+		response.WriteHeader(http.StatusNoContent)
+		return
+	}
 	metadata, err := server.bundles.Get(request.Context(), principal.TenantID, bundleID)
 	if err != nil {
 		writeBundleProblem(response, requestID, err)
@@ -211,6 +311,10 @@ func writeBundleProblem(response http.ResponseWriter, requestID string, err erro
 		problem = problemFor(http.StatusBadRequest, "invalid-bundle", "Invalid bundle upload", "Provide one valid immutable bundle ZIP and an Idempotency-Key.", requestID)
 	case errors.Is(err, external.ErrIdempotencyConflict):
 		problem = problemFor(http.StatusConflict, "idempotency-conflict", "Idempotency conflict", "The Idempotency-Key was already used for different content.", requestID)
+	case errors.Is(err, external.ErrBundleInUse):
+		problem = problemFor(http.StatusConflict, "bundle-in-use", "Bundle in use", "The bundle is still publishing or is referenced by queued or running judge jobs.", requestID)
+	case errors.Is(err, external.ErrBundleRetentionLimit):
+		problem = problemFor(http.StatusConflict, "bundle-limit-reached", "Bundle limit reached", "Delete unused bundles before uploading new content.", requestID)
 	case errors.Is(err, external.ErrBundleNotFound):
 		problem = problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID)
 	case errors.Is(err, external.ErrBundlePublishing):
@@ -24,6 +24,9 @@ type bundleApplicationStub struct {
 	tenantID        string
 	key             string
 	getID           string
+	deletedID       string
+	listOptions     external.BundleListOptions
+	page            external.BundleListResult
 	calls           int
 	deadline        time.Time
 	hasDeadline     bool
@@ -99,6 +102,20 @@ func (application *bundleApplicationStub) Get(_ context.Context, tenantID, bundl
 	return application.metadata, application.err
 }
 
+func (application *bundleApplicationStub) List(_ context.Context, tenantID string, options external.BundleListOptions) (external.BundleListResult, error) {
+	application.calls++
+	application.tenantID = tenantID
+	application.listOptions = options
+	return application.page, application.err
+}
+
+func (application *bundleApplicationStub) Delete(_ context.Context, tenantID, bundleID string) error {
+	application.calls++
+	application.tenantID = tenantID
+	application.deletedID = bundleID
+	return application.err
+}
+
 func TestBundleUploadStreamsOneFileAndReturnsOnlyPublicMetadata(t *testing.T) {
 	application := &bundleApplicationStub{metadata: testBundleMetadata()}
 	server := newBundleTestServer(t, ScopeBundleWrite, application)
@@ -328,6 +345,14 @@ func (application *blockingBundleApplication) Get(context.Context, string, strin
 	return external.BundleMetadata{}, external.ErrBundleNotFound
 }
 
+func (application *blockingBundleApplication) List(context.Context, string, external.BundleListOptions) (external.BundleListResult, error) {
+	return external.BundleListResult{}, nil
+}
+
+func (application *blockingBundleApplication) Delete(context.Context, string, string) error {
+	return external.ErrBundleNotFound
+}
+
 func TestBundleUploadMapsBoundedAndIdempotencyFailures(t *testing.T) {
 	for name, test := range map[string]struct {
 		err       error
@@ -387,6 +412,108 @@ func TestBundleEndpointsRequireTheirOwnScopes(t *testing.T) {
 	}
 }
 
+func TestBundleListParsesFiltersAndReturnsTenantPage(t *testing.T) {
+	retiredAt := time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC)
+	application := &bundleApplicationStub{page: external.BundleListResult{
+		Items:      []external.BundleSummary{{BundleMetadata: testBundleMetadata(), Status: external.BundleStatusRetired, RetiredAt: &retiredAt, InternalID: 99}},
+		NextCursor: "next-page",
+	}}
+	server := newBundleTestServer(t, ScopeBundleRead, application)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/bundles?limit=10&status=RETIRED&createdAfter=2026-07-01T00:00:00Z&createdBefore=2026-08-01T08:00:00%2B08:00&cursor=abc", nil)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	if response.Code != http.StatusOK || application.tenantID != "tenant-7" {
+		t.Fatalf("status=%d tenant=%q body=%s", response.Code, application.tenantID, response.Body.String())
+	}
+	want := external.BundleListOptions{
+		Cursor: "abc", Limit: 10, Status: external.BundleStatusRetired,
+		CreatedAfter: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), CreatedBefore: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if application.listOptions != want {
+		t.Fatalf("options=%+v want %+v", application.listOptions, want)
+	}
+	body := response.Body.String()
+	if !strings.Contains(body, `"status":"RETIRED"`) || !strings.Contains(body, `"retiredAt":"2026-07-20T00:00:00Z"`) ||
+		!strings.Contains(body, `"nextCursor":"next-page"`) || strings.Contains(body, "99") {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestBundleListRejectsInvalidQueriesBeforeTheApplication(t *testing.T) {
+	for _, query := range []string{
+		"limit=0", "limit=101", "status=PUBLISHING", "status=READY&status=READY", "order=asc",
+		"createdAfter=yesterday", "createdAfter=2026-08-01T00:00:00Z&createdBefore=2026-07-01T00:00:00Z",
+		"cursor=" + strings.Repeat("a", 513),
+	} {
+		t.Run(query, func(t *testing.T) {
+			application := &bundleApplicationStub{}
+			server := newBundleTestServer(t, ScopeBundleRead, application)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/bundles?"+query, nil))
+			if response.Code != http.StatusBadRequest || application.calls != 0 || !strings.Contains(response.Body.String(), "invalid-list-query") {
+				t.Fatalf("status=%d calls=%d body=%s", response.Code, application.calls, response.Body.String())
+			}
+		})
+	}
+	application := &bundleApplicationStub{err: external.ErrInvalidBundleListQuery}
+	server := newBundleTestServer(t, ScopeBundleRead, application)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/bundles?cursor=forged", nil))
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "untampered cursor") {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+}
+
+func TestBundleDeleteRetiresAndMapsConflicts(t *testing.T) {
+	application := &bundleApplicationStub{}
+	server := newBundleTestServer(t, ScopeBundleWrite, application)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil))
+	if response.Code != http.StatusNoContent || response.Body.Len() != 0 || application.tenantID != "tenant-7" || application.deletedID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Fatalf("status=%d tenant=%q id=%q body=%s", response.Code, application.tenantID, application.deletedID, response.Body.String())
+	}
+	for name, test := range map[string]struct {
+		err  error
+		want int
+		kind string
+	}{
+		"in use":    {err: external.ErrBundleInUse, want: http.StatusConflict, kind: "bundle-in-use"},
+		"not found": {err: external.ErrBundleNotFound, want: http.StatusNotFound, kind: "not-found"},
+		"database":  {err: errors.New("database unavailable"), want: http.StatusServiceUnavailable, kind: "bundle-unavailable"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			application := &bundleApplicationStub{err: test.err}
+			server := newBundleTestServer(t, ScopeBundleWrite, application)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil))
+			if response.Code != test.want || !strings.Contains(response.Body.String(), test.kind) {
+				t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestBundleDeleteAndListRequireTheirOwnScopes(t *testing.T) {
+	application := &bundleApplicationStub{}
+	readOnly := newBundleTestServer(t, ScopeBundleRead, application)
+	response := httptest.NewRecorder()
+	readOnly.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil))
+	if response.Code != http.StatusForbidden || application.calls != 0 {
+		t.Fatalf("delete with bundle:read status=%d calls=%d", response.Code, application.calls)
+	}
+	writeOnly := newBundleTestServer(t, ScopeBundleWrite, application)
+	response = httptest.NewRecorder()
+	writeOnly.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/bundles", nil))
+	if response.Code != http.StatusForbidden || application.calls != 0 {
+		t.Fatalf("list with bundle:write status=%d calls=%d", response.Code, application.calls)
+	}
+	response = httptest.NewRecorder()
+	writeOnly.ServeHTTP(response, httptest.NewRequest(http.MethodPut, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil))
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != "GET, DELETE" {
+		t.Fatalf("status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+}
+
 func newBundleTestServer(t *testing.T, scope Scope, application BundleApplication) *Server {
 	t.Helper()
 	capabilities := testCapabilities()
@@ -0,0 +1,207 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+// maximumCallbackRequestBytes bounds POST /api/v1/callbacks; the canonical
+// destination itself is limited to 2048 bytes by the provisioner.
+const maximumCallbackRequestBytes = 4096
+
+type CallbackApplication interface {
+	ListCallbacks(context.Context, string) ([]external.CallbackSummary, error)
+	CreateCallback(context.Context, string, string, []string) (external.CallbackMaterial, error)
+	DisableCallback(context.Context, string, string) error
+	RotateCallbackSecret(context.Context, string, string) (external.CallbackMaterial, error)
+}
+
+func WithCallbackApplication(application CallbackApplication) ServerOption {
+	return func(server *Server) error {
+		if application == nil {
+			return errors.New("callback application is required")
+		}
+		server.callbacks = application
+		return nil
+	}
+}
+
+// CreateCallbackCommand is the body of POST /api/v1/callbacks. EventTypes
+// subscribes to optional events; terminal job events are always delivered.
+type CreateCallbackCommand struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes,omitempty"`
+}
+
+type CallbackListView struct {
+	Items []external.CallbackSummary `json:"items"`
+}
+
+// CallbackSecretView is returned only by creation and rotation; the secret is
+// not stored in plaintext and cannot be read again.
+type CallbackSecretView struct {
+	CallbackID string   `json:"callbackId"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret"`
+}
+
+func (CallbackSecretView) String() string   { return "[REDACTED CALLBACK SECRET VIEW]" }
+func (CallbackSecretView) GoString() string { return "[REDACTED CALLBACK SECRET VIEW]" }
+
+func callbackSecretView(material external.CallbackMaterial) CallbackSecretView {
+	eventTypes := material.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return CallbackSecretView{CallbackID: material.CallbackID, URL: material.Destination, EventTypes: eventTypes, Secret: material.Secret}
+}
+
+func (server *Server) serveCallbackCollection(response http.ResponseWriter, request *http.Request, requestID string) {
+	switch request.Method {
+	case http.MethodGet:
+		principal, ok := server.authenticate(response, request, requestID, ScopeCallbackWrite)
+		if !ok {
+			return
+		}
+		if request.URL.RawQuery != "" {
+			writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "The callback list does not accept query parameters.", requestID))
+			return
+		}
+		callbacks, err := server.callbacks.ListCallbacks(request.Context(), principal.TenantID)
+		if err != nil {
+			writeCallbackProblem(response, requestID, err)
+			return
+		}
+		if callbacks == nil {
+			callbacks = []external.CallbackSummary{}
+		}
+		for index := range callbacks {
+			if callbacks[index].EventTypes == nil {
+				callbacks[index].EventTypes = []string{}
+			}
+		}
+		writeJSON(response, http.StatusOK, CallbackListView{Items: callbacks})
+	case http.MethodPost:
+		server.handleCallbackCreate(response, request, requestID)
+	default:
+		response.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET or POST for this resource.", requestID))
+	}
+}
+
+func (server *Server) handleCallbackCreate(response http.ResponseWriter, request *http.Request, requestID string) {
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeCallbackWrite)
+	if !authenticated {
+		return
+	}
+	controller := http.NewResponseController(response)
+	if err := controller.SetReadDeadline(time.Now().Add(server.jobBodyReadTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		closeUnreadRequestBody(response, request, controller)
+		response.Header().Set("Retry-After", "1")
+		writeProblem(response, problemFor(http.StatusServiceUnavailable, "callback-unavailable", "Callback service unavailable", "The callback operation could not be completed.", requestID))
+		return
+	}
+	if request.URL.RawQuery != "" || !hasApplicationJSONContentType(request.Header.Values("Content-Type")) {
+		closeUnreadRequestBody(response, request, controller)
+		writeProblem(response, problemFor(http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported media type", "Use Content-Type: application/json for callback registration.", requestID))
+		return
+	}
+	var command CreateCallbackCommand
+	decodeErr := decodeStrictJSON(response, request, &command, maximumCallbackRequestBytes)
+	if !drainAndCloseRequestBody(request.Body) {
+		response.Header().Set("Connection", "close")
+	} else {
+		_ = controller.SetReadDeadline(time.Time{})
+	}
+	if decodeErr != nil {
+		if isRequestBodyTimeout(decodeErr) {
+			response.Header().Set("Connection", "close")
+			response.Header().Set("Retry-After", "1")
+			writeProblem(response, problemFor(http.StatusRequestTimeout, "request-body-timeout", "Request body timeout", "Retry the request with a complete JSON body before the read deadline.", requestID))
+			return
+		}
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-json", "Invalid request body", "Provide one JSON object containing only documented fields.", requestID))
+		return
+	}
+	operationContext, cancel := context.WithTimeout(request.Context(), server.jobSubmitTimeout)
+	defer cancel()
+	material, err := server.callbacks.CreateCallback(operationContext, principal.TenantID, command.URL, command.EventTypes)
+	if err != nil {
+		writeCallbackProblem(response, requestID, err)
+		return
+	}
+	response.Header().Set("Location", "/api/v1/callbacks/"+material.CallbackID)
+	writeJSON(response, http.StatusCreated, callbackSecretView(material))
+}
+
+func (server *Server) serveCallbackItem(response http.ResponseWriter, request *http.Request, requestID string) {
+	callbackID, action, _ := strings.Cut(strings.TrimPrefix(request.URL.Path, "/api/v1/callbacks/"), "/")
+	switch {
+	case action == "" && request.Method != http.MethodDelete:
+		response.Header().Set("Allow", http.MethodDelete)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use DELETE for this resource.", requestID))
+		return
+	case (action == "rotate-secret" || action == "ping" && server.webhookDeliveries != nil) && request.Method != http.MethodPost:
+		response.Header().Set("Allow", http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST for this resource.", requestID))
+		return
+	}
+	principal, ok := server.authenticate(response, request, requestID, ScopeCallbackWrite)
+	if !ok {
+		return
+	}
+	if callbackID == "" || (action != "" && action != "rotate-secret" && (action != "ping" || server.webhookDeliveries == nil)) || request.URL.RawQuery != "" {
+		writeCallbackProblem(response, requestID, external.ErrCallbackNotFound)
+		return
+	}
+	if action == "ping" {
+		server.handleCallbackPing(response, request, requestID, principal.TenantID, callbackID)
+		return
+	}
+	if action == "" {
+		if err := server.callbacks.DisableCallback(request.Context(), principal.TenantID, callbackID); err != nil {
+			writeCallbackProblem(response, requestID, err)
+			return
+		}
+		response.Header().Set("Cache-Control", "no-store")
+		response.WriteHeader(http.StatusNoContent)
+		return
+	}
+	closeUnreadRequestBody(response, request, http.NewResponseController(response))
+	material, err := server.callbacks.RotateCallbackSecret(request.Context(), principal.TenantID, callbackID)
+	if err != nil {
+		writeCallbackProblem(response, requestID, err)
+		return
+	}
+	writeJSON(response, http.StatusOK, callbackSecretView(material))
+}
+
+func writeCallbackProblem(response http.ResponseWriter, requestID string, err error) {
+	problem := problemFor(http.StatusServiceUnavailable, "callback-unavailable", "Callback service unavailable", "The callback operation could not be completed.", requestID)
+	switch {
+	case errors.Is(err, external.ErrUnsafeCallbackDestination):
+		problem = problemFor(http.StatusUnprocessableEntity, "unsafe-callback-destination", "Unsafe callback destination", "The callback host must resolve only to public unicast addresses.", requestID)
+	case errors.Is(err, external.ErrInvalidCallbackDestination):
+		problem = problemFor(http.StatusUnprocessableEntity, "invalid-callback-destination", "Invalid callback destination", "Use an absolute HTTPS URL with a resolvable DNS host name and no credentials or fragment.", requestID)
+	case errors.Is(err, external.ErrInvalidCallbackEventTypes):
+		problem = problemFor(http.StatusUnprocessableEntity, "invalid-callback-event-types", "Invalid callback event types", "Subscribe only to judge.job.started and judge.job.progress, each at most once.", requestID)
+	case errors.Is(err, external.ErrCallbackLimitReached):
+		problem = problemFor(http.StatusConflict, "callback-limit-reached", "Callback limit reached", "Disable unused callbacks before registering new destinations.", requestID)
+	case errors.Is(err, external.ErrCallbackChanged):
+		problem = problemFor(http.StatusConflict, "callback-changed", "Callback changed", "The callback was rotated or disabled concurrently; list callbacks and retry.", requestID)
+	case errors.Is(err, external.ErrCallbackNotFound):
+		problem = problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID)
+	case errors.Is(err, external.ErrTenantNotFound), errors.Is(err, external.ErrTenantDisabled):
+		problem = problemFor(http.StatusUnauthorized, "unauthorized", "Authentication required", "Provide a valid active API key.", requestID)
+	}
+	if problem.Status == http.StatusServiceUnavailable {
+		response.Header().Set("Retry-After", "5")
+	}
+	writeProblem(response, problem)
+}
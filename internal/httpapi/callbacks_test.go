@@ -0,0 +1,250 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+type callbackApplicationStub struct {
+	callbacks   []external.CallbackSummary
+	material    external.CallbackMaterial
+	err         error
+	tenantID    string
+	destination string
+	eventTypes  []string
+	callbackID  string
+	operation   string
+	hasDeadline bool
+}
+
+func (application *callbackApplicationStub) ListCallbacks(_ context.Context, tenantID string) ([]external.CallbackSummary, error) {
+	application.operation, application.tenantID = "list", tenantID
+	return application.callbacks, application.err
+}
+
+func (application *callbackApplicationStub) CreateCallback(ctx context.Context, tenantID, destination string, eventTypes []string) (external.CallbackMaterial, error) {
+	application.operation, application.tenantID, application.destination, application.eventTypes = "create", tenantID, destination, eventTypes
+	_, application.hasDeadline = ctx.Deadline()
+	return application.material, application.err
+}
+
+func (application *callbackApplicationStub) DisableCallback(_ context.Context, tenantID, callbackID string) error {
+	application.operation, application.tenantID, application.callbackID = "disable", tenantID, callbackID
+	return application.err
+}
+
+func (application *callbackApplicationStub) RotateCallbackSecret(_ context.Context, tenantID, callbackID string) (external.CallbackMaterial, error) {
+	application.operation, application.tenantID, application.callbackID = "rotate", tenantID, callbackID
+	return application.material, application.err
+}
+
+func TestCallbackCreateReturnsTheSecretOnce(t *testing.T) {
+	application := &callbackApplicationStub{material: external.CallbackMaterial{
+		CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", Destination: "https://oj.example.com:443/hooks", Secret: "croj_whsec_new",
+		EventTypes: []string{external.WebhookEventJobProgress},
+	}}
+	server := newCallbackTestServer(t, ScopeCallbackWrite, application)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/callbacks", strings.NewReader(`{"url":"https://oj.example.com/hooks","eventTypes":["judge.job.progress"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	if response.Code != http.StatusCreated || response.Header().Get("Location") != "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb" ||
+		response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status=%d headers=%v body=%s", response.Code, response.Header(), response.Body.String())
+	}
+	var view CallbackSecretView
+	if err := json.Unmarshal(response.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Secret != "croj_whsec_new" || view.URL != "https://oj.example.com:443/hooks" || application.tenantID != "tenant-7" ||
+		application.destination != "https://oj.example.com/hooks" || !application.hasDeadline ||
+		fmt.Sprint(application.eventTypes) != "[judge.job.progress]" || fmt.Sprint(view.EventTypes) != "[judge.job.progress]" {
+		t.Fatalf("view=%+v application=%+v", view, application)
+	}
+	if fmt.Sprint(view) != "[REDACTED CALLBACK SECRET VIEW]" {
+		t.Fatal("callback secret view must redact itself when formatted")
+	}
+}
+
+func TestCallbackCreateRejectsMalformedBodiesBeforeTheApplication(t *testing.T) {
+	for name, test := range map[string]struct {
+		body        string
+		contentType string
+		status      int
+	}{
+		"unknown field":  {body: `{"url":"https://oj.example.com","secret":"x"}`, contentType: "application/json", status: http.StatusBadRequest},
+		"two values":     {body: `{"url":"https://a.example"}{}`, contentType: "application/json", status: http.StatusBadRequest},
+		"oversized":      {body: `{"url":"https://oj.example.com/` + strings.Repeat("a", maximumCallbackRequestBytes) + `"}`, contentType: "application/json", status: http.StatusBadRequest},
+		"wrong media":    {body: `{"url":"https://oj.example.com"}`, contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+		"missing header": {body: `{"url":"https://oj.example.com"}`, status: http.StatusUnsupportedMediaType},
+	} {
+		t.Run(name, func(t *testing.T) {
+			application := &callbackApplicationStub{}
+			server := newCallbackTestServer(t, ScopeCallbackWrite, application)
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/callbacks", strings.NewReader(test.body))
+			if test.contentType != "" {
+				request.Header.Set("Content-Type", test.contentType)
+			}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			if response.Code != test.status || application.operation != "" {
+				t.Fatalf("status=%d operation=%q body=%s", response.Code, application.operation, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestCallbackErrorsMapToProblems(t *testing.T) {
+	for _, test := range []struct {
+		err        error
+		status     int
+		kind       string
+		retryAfter string
+	}{
+		{err: fmt.Errorf("validate: %w", external.ErrUnsafeCallbackDestination), status: http.StatusUnprocessableEntity, kind: "unsafe-callback-destination"},
+		{err: fmt.Errorf("%w: no such host", external.ErrInvalidCallbackDestination), status: http.StatusUnprocessableEntity, kind: "invalid-callback-destination"},
+		{err: fmt.Errorf("%w: judge.ping", external.ErrInvalidCallbackEventTypes), status: http.StatusUnprocessableEntity, kind: "invalid-callback-event-types"},
+		{err: external.ErrCallbackLimitReached, status: http.StatusConflict, kind: "callback-limit-reached"},
+		{err: external.ErrTenantDisabled, status: http.StatusUnauthorized, kind: "unauthorized"},
+		{err: errors.New("database is down"), status: http.StatusServiceUnavailable, kind: "callback-unavailable", retryAfter: "5"},
+	} {
+		application := &callbackApplicationStub{err: test.err}
+		server := newCallbackTestServer(t, ScopeCallbackWrite, application)
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/callbacks", strings.NewReader(`{"url":"https://oj.example.com/hooks"}`))
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		if response.Code != test.status || !strings.Contains(response.Body.String(), "/problems/"+test.kind) ||
+			response.Header().Get("Retry-After") != test.retryAfter || strings.Contains(response.Body.String(), "database") {
+			t.Fatalf("%v: status=%d retry=%q body=%s", test.err, response.Code, response.Header().Get("Retry-After"), response.Body.String())
+		}
+	}
+	for _, test := range []struct {
+		method string
+		path   string
+		err    error
+		status int
+	}{
+		{method: http.MethodDelete, path: "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb", err: external.ErrCallbackNotFound, status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", err: external.ErrCallbackChanged, status: http.StatusConflict},
+		{method: http.MethodPost, path: "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", err: external.ErrCallbackNotFound, status: http.StatusNotFound},
+	} {
+		server := newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{err: test.err})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != test.status {
+			t.Fatalf("%s %s: status=%d body=%s", test.method, test.path, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestCallbackListDisableAndRotateAreTenantScoped(t *testing.T) {
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	application := &callbackApplicationStub{
+		callbacks: []external.CallbackSummary{{CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", URL: "https://oj.example.com:443/hooks", CreatedAt: created}},
+		material:  external.CallbackMaterial{CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", Destination: "https://oj.example.com:443/hooks", Secret: "croj_whsec_rotated"},
+	}
+	server := newCallbackTestServer(t, ScopeCallbackWrite, application)
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/callbacks", nil))
+	var page CallbackListView
+	if response.Code != http.StatusOK || json.Unmarshal(response.Body.Bytes(), &page) != nil || len(page.Items) != 1 ||
+		!page.Items[0].CreatedAt.Equal(created) || strings.Contains(response.Body.String(), "secret") {
+		t.Fatalf("list status=%d body=%s", response.Code, response.Body.String())
+	}
+
+	response = httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", nil))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"secret":"croj_whsec_rotated"`) ||
+		application.operation != "rotate" || application.tenantID != "tenant-7" || application.callbackID != "cbcbcbcbcbcbcbcbcbcbcbcbcb" {
+		t.Fatalf("rotate status=%d body=%s application=%+v", response.Code, response.Body.String(), application)
+	}
+
+	response = httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb", nil))
+	if response.Code != http.StatusNoContent || application.operation != "disable" || response.Body.Len() != 0 {
+		t.Fatalf("disable status=%d operation=%q", response.Code, application.operation)
+	}
+
+	empty := newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{})
+	response = httptest.NewRecorder()
+	empty.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/callbacks", nil))
+	if response.Code != http.StatusOK || strings.TrimSpace(response.Body.String()) != `{"items":[]}` {
+		t.Fatalf("empty list status=%d body=%s", response.Code, response.Body.String())
+	}
+}
+
+func TestCallbackRoutesRequireCallbackWriteAndKnownMethods(t *testing.T) {
+	application := &callbackApplicationStub{}
+	server := newCallbackTestServer(t, ScopeJobSubmit, application)
+	for _, test := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/callbacks"},
+		{http.MethodPost, "/api/v1/callbacks"},
+		{http.MethodDelete, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb"},
+		{http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret"},
+	} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != http.StatusForbidden || application.operation != "" {
+			t.Fatalf("%s %s: status=%d operation=%q", test.method, test.path, response.Code, application.operation)
+		}
+	}
+	server = newCallbackTestServer(t, ScopeCallbackWrite, application)
+	for _, test := range []struct{ method, path, allow string }{
+		{http.MethodPut, "/api/v1/callbacks", "GET, POST"},
+		{http.MethodGet, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb", "DELETE"},
+		{http.MethodGet, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", "POST"},
+	} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != test.allow {
+			t.Fatalf("%s %s: status=%d allow=%q", test.method, test.path, response.Code, response.Header().Get("Allow"))
+		}
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/unknown", nil))
+	if response.Code != http.StatusNotFound || application.operation != "" {
+		t.Fatalf("unknown action status=%d operation=%q", response.Code, application.operation)
+	}
+}
+
+func newCallbackTestServer(t *testing.T, scope Scope, application CallbackApplication) *Server {
+	t.Helper()
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{scope: {}}}}, testCapabilities(),
+		WithCallbackApplication(application))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func callbackCreateRequest(t *testing.T, application *callbackApplicationStub) (*Server, *http.Request) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/callbacks", strings.NewReader(`{"url":"https://oj.example.com/webhooks/coderushoj"}`))
+	request.Header.Set("Content-Type", "application/json")
+	return newCallbackTestServer(t, ScopeCallbackWrite, application), request
+}
+
+func testCallbackSummary() external.CallbackSummary {
+	return external.CallbackSummary{
+		CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", URL: "https://oj.example.com:443/webhooks/coderushoj",
+		CreatedAt: time.Date(2026, 7, 19, 1, 2, 3, 0, time.UTC),
+	}
+}
+
+func testCallbackMaterial() external.CallbackMaterial {
+	return external.CallbackMaterial{
+		CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", Destination: "https://oj.example.com:443/webhooks/coderushoj",
+		Secret: "croj_whsec_" + strings.Repeat("A", 43),
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 const (
-	maximumV1CaseCount                 = 256
+	maximumV1CaseCount                 = 10_000
 	maximumJobRequestEncodingExpansion = int64(6)
 	maximumJobRequestEnvelopeBytes     = int64(64 << 10)
 	maximumV1SourceBytes               = (math.MaxInt64 - maximumJobRequestEnvelopeBytes) / maximumJobRequestEncodingExpansion
@@ -0,0 +1,189 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
+)
+
+// JobBatchPath submits several judge jobs, each under its own idempotency key.
+const JobBatchPath = "/api/v1/judge-jobs:batch"
+
+type BatchSubmitJobItem struct {
+	IdempotencyKey string `json:"idempotencyKey"`
+	SubmitJobCommand
+}
+
+type BatchSubmitJobsCommand struct {
+	Items []BatchSubmitJobItem `json:"items"`
+}
+
+type BatchJobOutcome string
+
+const (
+	BatchJobCreated     BatchJobOutcome = "created"
+	BatchJobReplayed    BatchJobOutcome = "replayed"
+	BatchJobConflict    BatchJobOutcome = "conflict"
+	BatchJobQuotaDenied BatchJobOutcome = "quota-denied"
+	BatchJobInvalid     BatchJobOutcome = "invalid"
+	BatchJobUnavailable BatchJobOutcome = "unavailable"
+)
+
+type BatchJobItemView struct {
+	IdempotencyKey    string          `json:"idempotencyKey"`
+	Outcome           BatchJobOutcome `json:"outcome"`
+	Job               *JobView        `json:"job,omitempty"`
+	Problem           *Problem        `json:"problem,omitempty"`
+	RetryAfterSeconds int64           `json:"retryAfterSeconds,omitempty"`
+}
+
+type BatchSubmitJobsView struct {
+	Items []BatchJobItemView `json:"items"`
+}
+
+// BatchSubmitResult is one item's outcome; Err holds what Submit would have
+// returned for the same item.
+type BatchSubmitResult struct {
+	View     JobView
+	Replayed bool
+	Err      error
+}
+
+// JobBatchService is implemented by job services that accept batches. The
+// admissions slice parallels the items and follows the JobAdmission contract
+// per item. Per-item failures are reported in the results; an error return
+// means no item was attempted.
+type JobBatchService interface {
+	SubmitBatch(context.Context, string, []BatchSubmitJobItem, []JobAdmission) ([]BatchSubmitResult, error)
+}
+
+func (server *Server) handleJobBatchSubmit(response http.ResponseWriter, request *http.Request, requestID string) {
+	batches, supported := server.jobs.(JobBatchService)
+	if !supported {
+		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
+		return
+	}
+	if request.Method != http.MethodPost {
+		response.Header().Set("Allow", http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST to submit a batch of judge jobs.", requestID))
+		return
+	}
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeJobSubmit)
+	if !authenticated {
+		return
+	}
+	finishBodyRead, acquired := server.acquireJobBodyReader(response, request, requestID)
+	if !acquired {
+		return
+	}
+	defer finishBodyRead()
+	if !hasApplicationJSONContentType(request.Header.Values("Content-Type")) {
+		finishBodyRead()
+		writeProblem(response, problemFor(http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported media type", "Use Content-Type: application/json for judge job submissions.", requestID))
+		return
+	}
+	var command BatchSubmitJobsCommand
+	decodeErr := decodeStrictJSON(response, request, &command, maximumJobBatchRequestBytes(server.capabilities.Limits.MaxSourceBytes))
+	finishBodyRead()
+	if decodeErr != nil {
+		writeJobBodyDecodeProblem(response, requestID, decodeErr)
+		return
+	}
+	if !validBatchItems(command.Items) {
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-batch", "Invalid batch",
+			"Provide 1 to "+strconv.Itoa(external.MaximumJobBatchSize)+" items, each with a distinct idempotencyKey of 16 to 128 visible ASCII characters.", requestID))
+		return
+	}
+	admissions := make([]JobAdmission, len(command.Items))
+	for index := range admissions {
+		admissions[index] = server.jobAdmission(principal.TenantID)
+	}
+	submitContext, cancelSubmit := context.WithTimeout(request.Context(), server.jobSubmitTimeout)
+	defer cancelSubmit()
+	results, err := batches.SubmitBatch(submitContext, principal.TenantID, command.Items, admissions)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		err = ErrJobUnavailable
+	}
+	if err == nil && len(results) != len(command.Items) {
+		err = ErrJobUnavailable
+	}
+	if err != nil {
+		metrics.RecordJobAdmission(jobAdmissionOutcome(err, false))
+		server.writeJobError(response, requestID, err)
+		return
+	}
+	page := BatchSubmitJobsView{Items: make([]BatchJobItemView, len(results))}
+	for index, result := range results {
+		if errors.Is(result.Err, context.DeadlineExceeded) || errors.Is(result.Err, context.Canceled) {
+			result.Err = ErrJobUnavailable
+		}
+		metrics.RecordJobAdmission(jobAdmissionOutcome(result.Err, result.Replayed))
+		page.Items[index] = batchJobItemView(command.Items[index].IdempotencyKey, result, requestID)
+	}
+	writeJSON(response, http.StatusOK, page)
+}
+
+func validBatchItems(items []BatchSubmitJobItem) bool {
+	if len(items) == 0 || len(items) > external.MaximumJobBatchSize {
+		return false
+	}
+	keys := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if external.ValidateIdempotencyKey(item.IdempotencyKey) != nil {
+			return false
+		}
+		if _, duplicate := keys[item.IdempotencyKey]; duplicate {
+			return false
+		}
+		keys[item.IdempotencyKey] = struct{}{}
+	}
+	return true
+}
+
+func batchJobItemView(idempotencyKey string, result BatchSubmitResult, requestID string) BatchJobItemView {
+	view := BatchJobItemView{IdempotencyKey: idempotencyKey}
+	if result.Err == nil {
+		job := result.View
+		if job.StatusURL == "" {
+			job.StatusURL = "/api/v1/judge-jobs/" + job.JobID
+		}
+		view.Job, view.Outcome = &job, BatchJobCreated
+		if result.Replayed {
+			view.Outcome = BatchJobReplayed
+		}
+		return view
+	}
+	problem, retryAfter := jobErrorProblem(result.Err, requestID)
+	view.Problem = &problem
+	if retryAfter != "" {
+		view.RetryAfterSeconds, _ = strconv.ParseInt(retryAfter, 10, 64)
+	}
+	var quotaError *jobQuotaAdmissionError
+	switch {
+	case errors.Is(result.Err, ErrIdempotencyConflict):
+		view.Outcome = BatchJobConflict
+	case errors.As(result.Err, &quotaError) && !quotaError.unavailable, errors.Is(result.Err, ErrJobQuotaExceeded):
+		view.Outcome = BatchJobQuotaDenied
+	case errors.Is(result.Err, ErrJobInvalid):
+		view.Outcome = BatchJobInvalid
+	default:
+		view.Outcome = BatchJobUnavailable
+	}
+	return view
+}
+
+// maximumJobBatchRequestBytes lets a batch carry one item envelope per entry
+// but bounds the combined source of all items by the single-job source limit.
+func maximumJobBatchRequestBytes(maxSourceBytes int64) int64 {
+	single := maximumJobRequestBytes(maxSourceBytes)
+	extra := int64(external.MaximumJobBatchSize-1) * maximumJobRequestEnvelopeBytes
+	if single > math.MaxInt64-extra {
+		return math.MaxInt64
+	}
+	return single + extra
+}
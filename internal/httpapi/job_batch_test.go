@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+func batchRequest(body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, JobBatchPath, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer valid")
+	request.Header.Set("Content-Type", "application/json")
+	return request
+}
+
+func TestSubmitJudgeJobBatchReportsEveryItemOutcome(t *testing.T) {
+	created := JobView{JobID: "ceirceirceirceirceirceirce", Status: JobQueued, CreatedAt: time.Date(2026, 7, 19, 8, 0, 0, 0, time.UTC)}
+	service := &jobServiceStub{batchResults: []BatchSubmitResult{
+		{View: created},
+		{View: created, Replayed: true},
+		{Err: ErrIdempotencyConflict},
+		{Err: ErrJobQuotaExceeded},
+		{Err: ErrJobInvalid},
+		{Err: ErrJobUnavailable},
+	}}
+	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
+	server := newJobQuotaTestServer(t, service, quota)
+	var items []string
+	for index := range service.batchResults {
+		items = append(items, fmt.Sprintf(`{"idempotencyKey":"batch-item-%08d","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"secret source"}`, index))
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, batchRequest(`{"items":[`+strings.Join(items, ",")+`]}`))
+
+	if response.Code != http.StatusOK || response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status=%d headers=%v body=%s", response.Code, response.Header(), response.Body)
+	}
+	if strings.Contains(response.Body.String(), "secret source") {
+		t.Fatalf("response leaked source: %s", response.Body)
+	}
+	var page BatchSubmitJobsView
+	if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	want := []BatchJobOutcome{BatchJobCreated, BatchJobReplayed, BatchJobConflict, BatchJobQuotaDenied, BatchJobInvalid, BatchJobUnavailable}
+	if len(page.Items) != len(want) {
+		t.Fatalf("items = %+v", page.Items)
+	}
+	for index, item := range page.Items {
+		if item.Outcome != want[index] || item.IdempotencyKey != fmt.Sprintf("batch-item-%08d", index) {
+			t.Fatalf("item %d = %+v", index, item)
+		}
+	}
+	if page.Items[0].Job == nil || page.Items[0].Job.StatusURL != "/api/v1/judge-jobs/ceirceirceirceirceirceirce" || page.Items[0].Problem != nil {
+		t.Fatalf("created item = %+v", page.Items[0])
+	}
+	if problem := page.Items[3].Problem; problem == nil || problem.Status != http.StatusTooManyRequests || page.Items[3].RetryAfterSeconds != 5 {
+		t.Fatalf("quota-denied item = %+v", page.Items[3])
+	}
+	if problem := page.Items[2].Problem; problem == nil || problem.Status != http.StatusConflict || page.Items[2].RetryAfterSeconds != 0 {
+		t.Fatalf("conflict item = %+v", page.Items[2])
+	}
+	if service.submittedTenant != "tenant-7" || service.batchItems[1].SourceCode != "secret source" || quota.calls != 1 {
+		t.Fatalf("tenant=%q items=%d admissions=%d", service.submittedTenant, len(service.batchItems), quota.calls)
+	}
+}
+
+func TestSubmitJudgeJobBatchDeniesItemsWhenRateLimited(t *testing.T) {
+	service := &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceirce", Status: JobQueued}}
+	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: false, RetryAfter: 2500 * time.Millisecond}}
+	server := newJobQuotaTestServer(t, service, quota)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, batchRequest(`{"items":[
+		{"idempotencyKey":"batch-item-00000001","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"x"},
+		{"idempotencyKey":"batch-item-00000002","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"y"}]}`))
+	var page BatchSubmitJobsView
+	if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil || response.Code != http.StatusOK || len(page.Items) != 2 {
+		t.Fatalf("status=%d body=%s err=%v", response.Code, response.Body, err)
+	}
+	for _, item := range page.Items {
+		if item.Outcome != BatchJobQuotaDenied || item.RetryAfterSeconds != 3 || item.Job != nil {
+			t.Fatalf("rate-limited item = %+v", item)
+		}
+	}
+	if quota.calls != 2 || quota.request.Cost != 1 || quota.request.Kind != external.QuotaJudgeSubmit {
+		t.Fatalf("quota calls=%d request=%+v", quota.calls, quota.request)
+	}
+}
+
+func TestSubmitJudgeJobBatchRejectsMalformedBatches(t *testing.T) {
+	item := func(key string) string {
+		return `{"idempotencyKey":"` + key + `","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"x"}`
+	}
+	tooMany := make([]string, external.MaximumJobBatchSize+1)
+	for index := range tooMany {
+		tooMany[index] = item(fmt.Sprintf("batch-item-%08d", index))
+	}
+	for name, test := range map[string]struct {
+		body    string
+		problem string
+	}{
+		"empty":          {`{"items":[]}`, "invalid-batch"},
+		"too many":       {`{"items":[` + strings.Join(tooMany, ",") + `]}`, "invalid-batch"},
+		"duplicate keys": {`{"items":[` + item("batch-item-00000001") + `,` + item("batch-item-00000001") + `]}`, "invalid-batch"},
+		"short key":      {`{"items":[` + item("short") + `]}`, "invalid-batch"},
+		"unknown field":  {`{"items":[{"idempotencyKey":"batch-item-00000001","queue":"contest"}]}`, "invalid-json"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := &jobServiceStub{}
+			response := httptest.NewRecorder()
+			newJobTestServer(t, service, ScopeJobSubmit).ServeHTTP(response, batchRequest(test.body))
+			if response.Code != http.StatusBadRequest || !strings.HasSuffix(problemType(t, response), "/"+test.problem) || service.batchItems != nil {
+				t.Fatalf("status=%d body=%s items=%d", response.Code, response.Body, len(service.batchItems))
+			}
+		})
+	}
+
+	response := httptest.NewRecorder()
+	request := batchRequest(`{"items":[` + item("batch-item-00000001") + `]}`)
+	request.Header.Set("Content-Type", "text/plain")
+	newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit).ServeHTTP(response, request)
+	if response.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("text/plain status = %d", response.Code)
+	}
+	response = httptest.NewRecorder()
+	newJobTestServer(t, &jobServiceStub{}, ScopeJobRead).ServeHTTP(response, batchRequest(`{"items":[`+item("batch-item-00000001")+`]}`))
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("job:read status = %d", response.Code)
+	}
+	response = httptest.NewRecorder()
+	newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit).ServeHTTP(response, httptest.NewRequest(http.MethodGet, JobBatchPath, nil))
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("GET status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+}
+
+type singleJobService struct{ JobService }
+
+func TestSubmitJudgeJobBatchIsNotRoutedForSingleSubmitServices(t *testing.T) {
+	response := httptest.NewRecorder()
+	server := newJobTestServer(t, singleJobService{&jobServiceStub{}}, ScopeJobSubmit)
+	server.ServeHTTP(response, batchRequest(`{"items":[]}`))
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("status = %d", response.Code)
+	}
+}
+
+func problemType(t *testing.T, response *httptest.ResponseRecorder) string {
+	t.Helper()
+	var problem Problem
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem %s: %v", response.Body, err)
+	}
+	return problem.Type
+}
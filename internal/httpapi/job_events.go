@@ -0,0 +1,188 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	jobEventPageSize            = 100
+	defaultJobEventPollInterval = time.Second
+	defaultJobEventKeepAlive    = 15 * time.Second
+	defaultJobEventMaxDuration  = 30 * time.Minute
+	jobEventWriteTimeout        = 30 * time.Second
+	jobEventRetryMillis         = 2000
+)
+
+// JobEventView is the data of one server-sent event. Sequence is also the SSE
+// id, so a reconnecting client resumes with Last-Event-ID.
+type JobEventView struct {
+	JobID         string          `json:"jobId"`
+	Sequence      uint32          `json:"sequence"`
+	Type          string          `json:"type"`
+	AttemptNo     uint32          `json:"attemptNo"`
+	Status        JobStatus       `json:"status,omitempty"`
+	FailureCode   string          `json:"failureCode,omitempty"`
+	CompileStatus string          `json:"compileStatus,omitempty"`
+	Case          *CaseResultView `json:"case,omitempty"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+}
+
+type JobEventPage struct {
+	Status JobStatus
+	Events []JobEventView
+}
+
+// JobEventSource returns events after afterSequence for a job owned by the
+// tenant. Status must be read before Events so a terminal Status means no
+// later event can appear.
+type JobEventSource interface {
+	Events(context.Context, string, string, uint32, int) (JobEventPage, error)
+}
+
+// WithJobEventStream enables the per-job event stream. Streams hold a
+// connection for up to 30 minutes, so they are capped separately from body
+// readers and bundle uploads.
+func WithJobEventStream(source JobEventSource, maximumStreams int) ServerOption {
+	return func(server *Server) error {
+		if source == nil {
+			return fmt.Errorf("job event source is required")
+		}
+		if maximumStreams < 1 || maximumStreams > 65536 {
+			return fmt.Errorf("job event stream concurrency must be between 1 and 65536")
+		}
+		server.jobEvents = source
+		server.jobEventStreams = make(chan struct{}, maximumStreams)
+		return nil
+	}
+}
+
+func (server *Server) handleJobEvents(response http.ResponseWriter, request *http.Request, requestID, jobID string) {
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET to stream judge job events.", requestID))
+		return
+	}
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeJobRead)
+	if !authenticated {
+		return
+	}
+	afterSequence, ok := parseLastEventID(request.Header.Values("Last-Event-ID"))
+	if !ok {
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-last-event-id", "Invalid Last-Event-ID", "Provide at most one Last-Event-ID holding an event id from this stream.", requestID))
+		return
+	}
+	select {
+	case server.jobEventStreams <- struct{}{}:
+		defer func() { <-server.jobEventStreams }()
+	default:
+		response.Header().Set("Retry-After", "1")
+		writeProblem(response, problemFor(http.StatusServiceUnavailable, "event-stream-capacity-exhausted", "Event stream capacity exhausted", "Reconnect to the judge job event stream later.", requestID))
+		return
+	}
+	ctx, cancel := context.WithTimeout(request.Context(), server.jobEventMaxDuration)
+	defer cancel()
+	// The first page is read before any header is written so a job that is
+	// missing or owned by another tenant still yields the ordinary 404 problem.
+	page, err := server.jobEvents.Events(ctx, principal.TenantID, jobID, afterSequence, jobEventPageSize)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			err = ErrJobUnavailable
+		}
+		server.writeJobError(response, requestID, err)
+		return
+	}
+	response.Header().Set("Content-Type", "text/event-stream")
+	response.Header().Set("Cache-Control", "no-store")
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+	stream := jobEventWriter{response: response, controller: http.NewResponseController(response)}
+	if !stream.write("retry: " + strconv.Itoa(jobEventRetryMillis) + "\n\n") {
+		return
+	}
+	ticker := time.NewTicker(server.jobEventPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, event := range page.Events {
+			if !stream.event(event) {
+				return
+			}
+			afterSequence = event.Sequence
+			if event.Type == "status" && terminalJobStatus(event.Status) {
+				return
+			}
+		}
+		if len(page.Events) < jobEventPageSize {
+			if terminalJobStatus(page.Status) {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if time.Since(stream.lastWrite) >= server.jobEventKeepAlive && !stream.write(": keepalive\n\n") {
+				return
+			}
+		}
+		page, err = server.jobEvents.Events(ctx, principal.TenantID, jobID, afterSequence, jobEventPageSize)
+		if err != nil {
+			// Headers are already sent; closing lets the client reconnect with
+			// the last delivered id once the source recovers.
+			return
+		}
+	}
+}
+
+func parseLastEventID(values []string) (uint32, bool) {
+	if len(values) == 0 {
+		return 0, true
+	}
+	if len(values) != 1 || values[0] == "" || len(values[0]) > 10 {
+		return 0, false
+	}
+	sequence, err := strconv.ParseUint(values[0], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(sequence), true
+}
+
+func terminalJobStatus(status JobStatus) bool {
+	return status == JobSucceeded || status == JobFailed || status == JobCancelled
+}
+
+type jobEventWriter struct {
+	response   http.ResponseWriter
+	controller *http.ResponseController
+	lastWrite  time.Time
+}
+
+func (stream *jobEventWriter) event(event JobEventView) bool {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return false
+	}
+	return stream.write("id: " + strconv.FormatUint(uint64(event.Sequence), 10) + "\nevent: " + event.Type + "\ndata: " + string(data) + "\n\n")
+}
+
+// write extends the write deadline per frame; the server-wide WriteTimeout
+// would otherwise cut a healthy long-lived stream.
+func (stream *jobEventWriter) write(frame string) bool {
+	if err := stream.controller.SetWriteDeadline(time.Now().Add(jobEventWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return false
+	}
+	if _, err := stream.response.Write([]byte(frame)); err != nil {
+		return false
+	}
+	if err := stream.controller.Flush(); err != nil {
+		return false
+	}
+	stream.lastWrite = time.Now()
+	return true
+}
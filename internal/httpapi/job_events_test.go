@@ -0,0 +1,198 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+// jobEventSourceStub replays pages in order and then keeps returning the last
+// one, recording the resume position of every read.
+type jobEventSourceStub struct {
+	mutex  sync.Mutex
+	pages  []JobEventPage
+	err    error
+	tenant string
+	after  []uint32
+	limits []int
+}
+
+func (source *jobEventSourceStub) Events(_ context.Context, tenantID, _ string, afterSequence uint32, limit int) (JobEventPage, error) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	source.tenant = tenantID
+	source.after = append(source.after, afterSequence)
+	source.limits = append(source.limits, limit)
+	if source.err != nil {
+		return JobEventPage{}, source.err
+	}
+	page := source.pages[0]
+	if len(source.pages) > 1 {
+		source.pages = source.pages[1:]
+	}
+	return page, nil
+}
+
+func newJobEventTestServer(t *testing.T, source JobEventSource, scopes ...Scope) *Server {
+	t.Helper()
+	granted := make(map[Scope]struct{}, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = struct{}{}
+	}
+	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: granted}}, testCapabilities(),
+		WithJobService(&jobServiceStub{}), WithJobWriteQuota(quota, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}),
+		WithJobEventStream(source, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.jobEventPollInterval = time.Millisecond
+	return server
+}
+
+func jobEventRequest(lastEventIDs ...string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+	request.Header.Set("Authorization", "Bearer valid")
+	for _, value := range lastEventIDs {
+		request.Header.Add("Last-Event-ID", value)
+	}
+	return request
+}
+
+func TestJobEventStreamResumesAfterLastEventIDUntilTerminalStatus(t *testing.T) {
+	now := time.Date(2026, 7, 19, 14, 0, 0, 0, time.UTC)
+	score, maximum := 10, 10
+	source := &jobEventSourceStub{pages: []JobEventPage{
+		{Status: JobRunning, Events: []JobEventView{
+			{JobID: "ceirceirceirceirceirceirce", Sequence: 3, Type: "compile", AttemptNo: 1, CompileStatus: "SUCCEEDED", OccurredAt: now},
+			{JobID: "ceirceirceirceirceirceirce", Sequence: 4, Type: "case", AttemptNo: 1, Case: &CaseResultView{CaseID: "case-1", Verdict: "ACCEPTED", Score: &score, MaxScore: &maximum}, OccurredAt: now},
+		}},
+		{Status: JobRunning},
+		{Status: JobSucceeded, Events: []JobEventView{
+			{JobID: "ceirceirceirceirceirceirce", Sequence: 5, Type: "status", AttemptNo: 1, Status: JobSucceeded, OccurredAt: now},
+		}},
+	}}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	server.jobEventKeepAlive = 0
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest("2"))
+
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "text/event-stream" || response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status=%d headers=%v body=%s", response.Code, response.Header(), response.Body.String())
+	}
+	body := response.Body.String()
+	for _, frame := range []string{
+		"retry: 2000\n\n",
+		"id: 3\nevent: compile\ndata: {\"jobId\":\"ceirceirceirceirceirceirce\",\"sequence\":3,\"type\":\"compile\",\"attemptNo\":1,\"compileStatus\":\"SUCCEEDED\"",
+		"id: 4\nevent: case\ndata: ",
+		"id: 5\nevent: status\ndata: {\"jobId\":\"ceirceirceirceirceirceirce\",\"sequence\":5,\"type\":\"status\",\"attemptNo\":1,\"status\":\"SUCCEEDED\"",
+		": keepalive\n\n",
+	} {
+		if !strings.Contains(body, frame) {
+			t.Fatalf("stream is missing %q:\n%s", frame, body)
+		}
+	}
+	if strings.Index(body, "id: 3\n") > strings.Index(body, "id: 4\n") || strings.Index(body, "id: 4\n") > strings.Index(body, "id: 5\n") {
+		t.Fatalf("events are out of order:\n%s", body)
+	}
+	if source.tenant != "tenant-7" || len(source.after) != 3 || source.after[0] != 2 || source.after[1] != 4 || source.after[2] != 4 || source.limits[0] != jobEventPageSize {
+		t.Fatalf("tenant=%q resume positions=%v limits=%v", source.tenant, source.after, source.limits)
+	}
+}
+
+func TestJobEventStreamClosesWhenAlreadyTerminal(t *testing.T) {
+	source := &jobEventSourceStub{pages: []JobEventPage{{Status: JobCancelled}}}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest("9"))
+	if response.Code != http.StatusOK || response.Body.String() != "retry: 2000\n\n" || len(source.after) != 1 {
+		t.Fatalf("status=%d reads=%v body=%q", response.Code, source.after, response.Body.String())
+	}
+}
+
+func TestJobEventStreamRefetchesFullPagesWithoutWaiting(t *testing.T) {
+	full := JobEventPage{Status: JobRunning, Events: make([]JobEventView, jobEventPageSize)}
+	for index := range full.Events {
+		full.Events[index] = JobEventView{Sequence: uint32(index + 1), Type: "case", Case: &CaseResultView{CaseID: "case"}}
+	}
+	source := &jobEventSourceStub{pages: []JobEventPage{full, {Status: JobFailed}}}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	server.jobEventPollInterval = time.Hour
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest())
+	if len(source.after) != 2 || source.after[1] != jobEventPageSize || strings.Count(response.Body.String(), "event: case\n") != jobEventPageSize {
+		t.Fatalf("reads=%v events=%d", source.after, strings.Count(response.Body.String(), "event: case\n"))
+	}
+}
+
+func TestJobEventStreamUsesTheJobResourceErrorsBeforeStreaming(t *testing.T) {
+	source := &jobEventSourceStub{err: ErrJobNotFound}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest())
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), "job-not-found") || response.Header().Get("Content-Type") == "text/event-stream" {
+		t.Fatalf("status=%d headers=%v body=%s", response.Code, response.Header(), response.Body.String())
+	}
+
+	unscoped := newJobEventTestServer(t, &jobEventSourceStub{}, ScopeJobSubmit)
+	response = httptest.NewRecorder()
+	unscoped.ServeHTTP(response, jobEventRequest())
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+}
+
+func TestJobEventStreamRejectsInvalidLastEventID(t *testing.T) {
+	source := &jobEventSourceStub{pages: []JobEventPage{{Status: JobSucceeded}}}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	for _, values := range [][]string{{""}, {"abc"}, {"-1"}, {"+1"}, {"4294967296"}, {"1", "2"}} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, jobEventRequest(values...))
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "invalid-last-event-id") {
+			t.Fatalf("Last-Event-ID=%q status=%d body=%s", values, response.Code, response.Body.String())
+		}
+	}
+	if len(source.after) != 0 {
+		t.Fatalf("invalid resume positions reached the source: %v", source.after)
+	}
+}
+
+func TestJobEventStreamShedsLoadAtCapacity(t *testing.T) {
+	source := &jobEventSourceStub{pages: []JobEventPage{{Status: JobSucceeded}}}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	server.jobEventStreams <- struct{}{}
+	server.jobEventStreams <- struct{}{}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest())
+	if response.Code != http.StatusServiceUnavailable || response.Header().Get("Retry-After") != "1" || len(source.after) != 0 {
+		t.Fatalf("status=%d headers=%v reads=%v", response.Code, response.Header(), source.after)
+	}
+}
+
+func TestJobEventStreamRouteRequiresConfigurationAndGET(t *testing.T) {
+	server := newJobTestServer(t, &jobServiceStub{}, ScopeJobRead)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest())
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("unconfigured status=%d", response.Code)
+	}
+
+	streaming := newJobEventTestServer(t, &jobEventSourceStub{}, ScopeJobRead)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+	request.Header.Set("Authorization", "Bearer valid")
+	response = httptest.NewRecorder()
+	streaming.ServeHTTP(response, request)
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != http.MethodGet {
+		t.Fatalf("status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+
+	if _, err := NewServer(staticAuthenticator{}, testCapabilities(), WithJobEventStream(&jobEventSourceStub{}, 1)); err == nil {
+		t.Fatal("event stream without a job service was accepted")
+	}
+}
@@ -0,0 +1,207 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
+)
+
+var ErrJobNotRejudgeable = errors.New("judge job cannot be rejudged")
+
+// BatchJobNotRejudgeable reports a bundle rejudge item whose original lost
+// its source to retention while the page was being built.
+const BatchJobNotRejudgeable BatchJobOutcome = "not-rejudgeable"
+
+type RejudgeJobCommand struct {
+	ReplacementBundleID string `json:"replacementBundleId,omitempty"`
+	Priority            int    `json:"priority,omitempty"`
+}
+
+type RejudgeBundleCommand struct {
+	RejudgeJobCommand
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// BundleRejudgeResult is the outcome of rejudging one original job.
+type BundleRejudgeResult struct {
+	RejudgeOf string
+	BatchSubmitResult
+}
+
+type BundleRejudgePage struct {
+	Items      []BundleRejudgeResult
+	NextCursor string
+}
+
+type BundleRejudgeItemView struct {
+	RejudgeOf         string          `json:"rejudgeOf"`
+	Outcome           BatchJobOutcome `json:"outcome"`
+	Job               *JobView        `json:"job,omitempty"`
+	Problem           *Problem        `json:"problem,omitempty"`
+	RetryAfterSeconds int64           `json:"retryAfterSeconds,omitempty"`
+}
+
+type BundleRejudgeView struct {
+	Items      []BundleRejudgeItemView `json:"items"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+// JobRejudgeService is implemented by job services that keep sources for
+// rejudging. RejudgeBundle calls admission once per job it creates; each
+// returned JobAdmission follows the JobAdmission contract.
+type JobRejudgeService interface {
+	Rejudge(context.Context, string, string, string, RejudgeJobCommand, JobAdmission) (JobView, bool, error)
+	RejudgeBundle(context.Context, string, string, string, RejudgeBundleCommand, func() JobAdmission) (BundleRejudgePage, error)
+}
+
+func (server *Server) handleJobRejudge(response http.ResponseWriter, request *http.Request, requestID, jobID string) {
+	rejudges, supported := server.jobs.(JobRejudgeService)
+	if !supported {
+		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
+		return
+	}
+	if request.Method != http.MethodPost {
+		response.Header().Set("Allow", http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST to rejudge a judge job.", requestID))
+		return
+	}
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeJobSubmit)
+	if !authenticated {
+		return
+	}
+	var command RejudgeJobCommand
+	idempotencyKey, decoded := server.decodeRejudgeRequest(response, request, requestID, &command)
+	if !decoded {
+		return
+	}
+	submitContext, cancelSubmit := context.WithTimeout(request.Context(), server.jobSubmitTimeout)
+	defer cancelSubmit()
+	view, replayed, err := rejudges.Rejudge(submitContext, principal.TenantID, idempotencyKey, jobID, command, server.jobAdmission(principal.TenantID))
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		err = ErrJobUnavailable
+	}
+	metrics.RecordJobAdmission(jobAdmissionOutcome(err, replayed))
+	if err != nil {
+		server.writeJobError(response, requestID, err)
+		return
+	}
+	if view.StatusURL == "" {
+		view.StatusURL = "/api/v1/judge-jobs/" + view.JobID
+	}
+	response.Header().Set("Location", view.StatusURL)
+	if replayed {
+		response.Header().Set("Idempotent-Replay", "true")
+	}
+	writeJSON(response, http.StatusAccepted, view)
+}
+
+// isBundleRejudgePath matches /api/v1/bundles/{bundleId}/rejudge, which the
+// job service serves rather than the bundle service.
+func isBundleRejudgePath(path string) bool {
+	bundleID, found := strings.CutSuffix(strings.TrimPrefix(path, "/api/v1/bundles/"), "/rejudge")
+	return found && strings.HasPrefix(path, "/api/v1/bundles/") && jobIDPattern.MatchString(bundleID)
+}
+
+func (server *Server) handleBundleRejudge(response http.ResponseWriter, request *http.Request, requestID string) {
+	rejudges, supported := server.jobs.(JobRejudgeService)
+	if !supported {
+		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
+		return
+	}
+	if request.Method != http.MethodPost {
+		response.Header().Set("Allow", http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST to rejudge the jobs of a bundle.", requestID))
+		return
+	}
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeJobSubmit)
+	if !authenticated {
+		return
+	}
+	bundleID := strings.TrimSuffix(strings.TrimPrefix(request.URL.Path, "/api/v1/bundles/"), "/rejudge")
+	var command RejudgeBundleCommand
+	idempotencyKey, decoded := server.decodeRejudgeRequest(response, request, requestID, &command)
+	if !decoded {
+		return
+	}
+	if command.Limit == 0 {
+		command.Limit = external.MaximumJobBatchSize
+	}
+	if command.Limit < 1 || command.Limit > external.MaximumJobBatchSize || len(command.Cursor) > 512 {
+		writeInvalidBundleRejudgeProblem(response, requestID)
+		return
+	}
+	submitContext, cancelSubmit := context.WithTimeout(request.Context(), server.jobSubmitTimeout)
+	defer cancelSubmit()
+	page, err := rejudges.RejudgeBundle(submitContext, principal.TenantID, idempotencyKey, bundleID, command, func() JobAdmission {
+		return server.jobAdmission(principal.TenantID)
+	})
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		err = ErrJobUnavailable
+	}
+	switch {
+	case errors.Is(err, ErrJobInvalid):
+		writeInvalidBundleRejudgeProblem(response, requestID)
+		return
+	case errors.Is(err, ErrJobNotFound):
+		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
+		return
+	case err != nil:
+		server.writeJobError(response, requestID, err)
+		return
+	}
+	view := BundleRejudgeView{Items: make([]BundleRejudgeItemView, len(page.Items)), NextCursor: page.NextCursor}
+	for index, result := range page.Items {
+		if errors.Is(result.Err, context.DeadlineExceeded) || errors.Is(result.Err, context.Canceled) {
+			result.Err = ErrJobUnavailable
+		}
+		metrics.RecordJobAdmission(jobAdmissionOutcome(result.Err, result.Replayed))
+		item := batchJobItemView("", result.BatchSubmitResult, requestID)
+		if errors.Is(result.Err, ErrJobNotRejudgeable) {
+			item.Outcome = BatchJobNotRejudgeable
+		}
+		view.Items[index] = BundleRejudgeItemView{
+			RejudgeOf: result.RejudgeOf, Outcome: item.Outcome, Job: item.Job,
+			Problem: item.Problem, RetryAfterSeconds: item.RetryAfterSeconds,
+		}
+	}
+	writeJSON(response, http.StatusOK, view)
+}
+
+func writeInvalidBundleRejudgeProblem(response http.ResponseWriter, requestID string) {
+	writeProblem(response, problemFor(http.StatusBadRequest, "invalid-rejudge", "Invalid rejudge request",
+		"Use a limit of 1 to "+strconv.Itoa(external.MaximumJobBatchSize)+", a valid replacementBundleId and priority, and an untampered cursor issued for the same bundle rejudge.", requestID))
+}
+
+// decodeRejudgeRequest reads the Idempotency-Key and the small JSON body the
+// rejudge endpoints share. It writes the problem and reports false on error.
+func (server *Server) decodeRejudgeRequest(response http.ResponseWriter, request *http.Request, requestID string, command any) (string, bool) {
+	finishBodyRead, acquired := server.acquireJobBodyReader(response, request, requestID)
+	if !acquired {
+		return "", false
+	}
+	defer finishBodyRead()
+	if !hasApplicationJSONContentType(request.Header.Values("Content-Type")) {
+		finishBodyRead()
+		writeProblem(response, problemFor(http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported media type", "Use Content-Type: application/json for judge job submissions.", requestID))
+		return "", false
+	}
+	idempotencyKey, detail := idempotencyKeyHeader(request)
+	if detail != "" {
+		finishBodyRead()
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-idempotency-key", "Invalid Idempotency-Key", detail, requestID))
+		return "", false
+	}
+	decodeErr := decodeStrictJSON(response, request, command, maximumJobRequestEnvelopeBytes)
+	finishBodyRead()
+	if decodeErr != nil {
+		writeJobBodyDecodeProblem(response, requestID, decodeErr)
+		return "", false
+	}
+	return idempotencyKey, true
+}
@@ -0,0 +1,154 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+const (
+	jobRejudgePath    = "/api/v1/judge-jobs/ceirceirceirceirceirceirce/rejudge"
+	bundleRejudgePath = "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa/rejudge"
+)
+
+func rejudgeRequest(path, body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer valid")
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Idempotency-Key", "rejudge-key-00000001")
+	return request
+}
+
+func TestRejudgeJudgeJobReturns202WithTheOriginalLinked(t *testing.T) {
+	service := &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceircf", Status: JobQueued, RejudgeOf: "ceirceirceirceirceirceirce"}}
+	response := httptest.NewRecorder()
+	newJobTestServer(t, service, ScopeJobSubmit).ServeHTTP(response, rejudgeRequest(jobRejudgePath, `{"replacementBundleId":"ceirceirceirceirceirceircg","priority":1}`))
+
+	if response.Code != http.StatusAccepted || response.Header().Get("Location") != "/api/v1/judge-jobs/ceirceirceirceirceirceircf" {
+		t.Fatalf("status=%d headers=%v body=%s", response.Code, response.Header(), response.Body)
+	}
+	var view JobView
+	if err := json.Unmarshal(response.Body.Bytes(), &view); err != nil || view.RejudgeOf != "ceirceirceirceirceirceirce" {
+		t.Fatalf("view=%+v err=%v", view, err)
+	}
+	if service.submittedTenant != "tenant-7" || service.idempotencyKey != "rejudge-key-00000001" || service.rejudgeID != "ceirceirceirceirceirceirce" {
+		t.Fatalf("tenant=%q key=%q job=%q", service.submittedTenant, service.idempotencyKey, service.rejudgeID)
+	}
+	if service.rejudgeCommand != (RejudgeJobCommand{ReplacementBundleID: "ceirceirceirceirceirceircg", Priority: 1}) {
+		t.Fatalf("command = %+v", service.rejudgeCommand)
+	}
+}
+
+func TestRejudgeJudgeJobRejectsJobsThatCannotBeRejudged(t *testing.T) {
+	response := httptest.NewRecorder()
+	newJobTestServer(t, &jobServiceStub{err: ErrJobNotRejudgeable}, ScopeJobSubmit).ServeHTTP(response, rejudgeRequest(jobRejudgePath, `{}`))
+	if response.Code != http.StatusConflict || !strings.HasSuffix(problemType(t, response), "/job-not-rejudgeable") {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body)
+	}
+
+	response = httptest.NewRecorder()
+	request := rejudgeRequest(jobRejudgePath, `{}`)
+	request.Header.Del("Idempotency-Key")
+	service := &jobServiceStub{}
+	newJobTestServer(t, service, ScopeJobSubmit).ServeHTTP(response, request)
+	if response.Code != http.StatusBadRequest || service.rejudgeID != "" {
+		t.Fatalf("missing key status=%d rejudged=%q", response.Code, service.rejudgeID)
+	}
+
+	response = httptest.NewRecorder()
+	newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit).ServeHTTP(response, httptest.NewRequest(http.MethodGet, jobRejudgePath, nil))
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("GET status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+}
+
+func TestRejudgeBundleReportsEveryOriginalJob(t *testing.T) {
+	service := &jobServiceStub{rejudgePage: BundleRejudgePage{NextCursor: "next-page", Items: []BundleRejudgeResult{
+		{RejudgeOf: "ceirceirceirceirceirceirce", BatchSubmitResult: BatchSubmitResult{View: JobView{JobID: "ceirceirceirceirceirceircf", Status: JobQueued}}},
+		{RejudgeOf: "ceirceirceirceirceirceircg", BatchSubmitResult: BatchSubmitResult{View: JobView{JobID: "ceirceirceirceirceirceirch", Status: JobQueued}, Replayed: true}},
+		{RejudgeOf: "ceirceirceirceirceirceirci", BatchSubmitResult: BatchSubmitResult{Err: ErrJobNotRejudgeable}},
+		{RejudgeOf: "ceirceirceirceirceirceircj", BatchSubmitResult: BatchSubmitResult{Err: ErrJobQuotaExceeded}},
+	}}}
+	response := httptest.NewRecorder()
+	newJobTestServer(t, service, ScopeJobSubmit).ServeHTTP(response, rejudgeRequest(bundleRejudgePath, `{"cursor":"page-2","limit":4,"priority":3}`))
+
+	var page BundleRejudgeView
+	if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s err=%v", response.Code, response.Body, err)
+	}
+	want := []BatchJobOutcome{BatchJobCreated, BatchJobReplayed, BatchJobNotRejudgeable, BatchJobQuotaDenied}
+	if len(page.Items) != len(want) || page.NextCursor != "next-page" {
+		t.Fatalf("page = %+v", page)
+	}
+	for index, item := range page.Items {
+		if item.Outcome != want[index] || item.RejudgeOf != service.rejudgePage.Items[index].RejudgeOf {
+			t.Fatalf("item %d = %+v", index, item)
+		}
+	}
+	if page.Items[0].Job == nil || page.Items[0].Job.StatusURL != "/api/v1/judge-jobs/ceirceirceirceirceirceircf" {
+		t.Fatalf("created item = %+v", page.Items[0])
+	}
+	if problem := page.Items[2].Problem; problem == nil || problem.Status != http.StatusConflict {
+		t.Fatalf("not-rejudgeable item = %+v", page.Items[2])
+	}
+	if service.rejudgeID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" || service.idempotencyKey != "rejudge-key-00000001" ||
+		service.rejudgeBundle.Cursor != "page-2" || service.rejudgeBundle.Limit != 4 || service.rejudgeBundle.Priority != 3 {
+		t.Fatalf("bundle=%q key=%q command=%+v", service.rejudgeID, service.idempotencyKey, service.rejudgeBundle)
+	}
+}
+
+func TestRejudgeBundleRejectsMalformedPages(t *testing.T) {
+	for name, body := range map[string]string{
+		"limit too large": `{"limit":101}`,
+		"negative limit":  `{"limit":-1}`,
+		"cursor too long": `{"cursor":"` + strings.Repeat("c", 513) + `"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := &jobServiceStub{}
+			response := httptest.NewRecorder()
+			newJobTestServer(t, service, ScopeJobSubmit).ServeHTTP(response, rejudgeRequest(bundleRejudgePath, body))
+			if response.Code != http.StatusBadRequest || !strings.HasSuffix(problemType(t, response), "/invalid-rejudge") || service.rejudgeID != "" {
+				t.Fatalf("status=%d body=%s", response.Code, response.Body)
+			}
+		})
+	}
+
+	response := httptest.NewRecorder()
+	newJobTestServer(t, &jobServiceStub{err: ErrJobInvalid}, ScopeJobSubmit).ServeHTTP(response, rejudgeRequest(bundleRejudgePath, `{"cursor":"tampered"}`))
+	if response.Code != http.StatusBadRequest || !strings.HasSuffix(problemType(t, response), "/invalid-rejudge") {
+		t.Fatalf("tampered cursor status=%d body=%s", response.Code, response.Body)
+	}
+	response = httptest.NewRecorder()
+	newJobTestServer(t, &jobServiceStub{err: ErrJobNotFound}, ScopeJobSubmit).ServeHTTP(response, rejudgeRequest(bundleRejudgePath, `{}`))
+	if response.Code != http.StatusNotFound || !strings.HasSuffix(problemType(t, response), "/not-found") {
+		t.Fatalf("unknown bundle status=%d body=%s", response.Code, response.Body)
+	}
+}
+
+func TestRejudgeBundleDefaultsToAFullPageAndChargesEachNewJob(t *testing.T) {
+	service := &jobServiceStub{rejudgePage: BundleRejudgePage{Items: []BundleRejudgeResult{
+		{RejudgeOf: "ceirceirceirceirceirceirce", BatchSubmitResult: BatchSubmitResult{View: JobView{JobID: "ceirceirceirceirceirceircf"}}},
+		{RejudgeOf: "ceirceirceirceirceirceircg", BatchSubmitResult: BatchSubmitResult{View: JobView{JobID: "ceirceirceirceirceirceirch"}, Replayed: true}},
+	}}}
+	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
+	response := httptest.NewRecorder()
+	newJobQuotaTestServer(t, service, quota).ServeHTTP(response, rejudgeRequest(bundleRejudgePath, `{}`))
+	if response.Code != http.StatusOK || service.rejudgeBundle.Limit != external.MaximumJobBatchSize || quota.calls != 1 {
+		t.Fatalf("status=%d limit=%d admissions=%d", response.Code, service.rejudgeBundle.Limit, quota.calls)
+	}
+}
+
+func TestRejudgeIsNotRoutedForServicesWithoutRejudgeSupport(t *testing.T) {
+	server := newJobTestServer(t, singleJobService{&jobServiceStub{}}, ScopeJobSubmit)
+	for _, path := range []string{jobRejudgePath, bundleRejudgePath} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, rejudgeRequest(path, `{}`))
+		if response.Code != http.StatusNotFound {
+			t.Fatalf("%s status = %d", path, response.Code)
+		}
+	}
+}
@@ -16,8 +16,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/judgecontract"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 )
 
 type JobStatus string
@@ -46,6 +49,7 @@ type SubmitJobCommand struct {
 	StopOnFailure   bool   `json:"stopOnFailure"`
 	CallbackID      string `json:"callbackId,omitempty"`
 	ClientReference string `json:"clientReference,omitempty"`
+	Priority        int    `json:"priority,omitempty"`
 }
 
 type CaseResultView struct {
@@ -57,14 +61,23 @@ type CaseResultView struct {
 	MaxScore    *int   `json:"maxScore,omitempty"`
 }
 
+type SubtaskResultView struct {
+	SubtaskID       string `json:"subtaskId"`
+	Verdict         string `json:"verdict"`
+	Score           int    `json:"score"`
+	MaxScore        int    `json:"maxScore"`
+	DependenciesMet bool   `json:"dependenciesMet"`
+}
+
 type JobResultView struct {
-	Verdict       string           `json:"verdict"`
-	CompileStatus string           `json:"compileStatus"`
-	TimeMillis    int64            `json:"timeMillis"`
-	MemoryBytes   int64            `json:"memoryBytes"`
-	Score         *int             `json:"score,omitempty"`
-	TotalScore    *int             `json:"totalScore,omitempty"`
-	Cases         []CaseResultView `json:"cases"`
+	Verdict       string              `json:"verdict"`
+	CompileStatus string              `json:"compileStatus"`
+	TimeMillis    int64               `json:"timeMillis"`
+	MemoryBytes   int64               `json:"memoryBytes"`
+	Score         *int                `json:"score,omitempty"`
+	TotalScore    *int                `json:"totalScore,omitempty"`
+	Cases         []CaseResultView    `json:"cases"`
+	Subtasks      []SubtaskResultView `json:"subtasks,omitempty"`
 }
 
 type JobView struct {
@@ -73,14 +86,23 @@ type JobView struct {
 	StatusURL       string         `json:"statusUrl,omitempty"`
 	CreatedAt       time.Time      `json:"createdAt"`
 	ClientReference string         `json:"clientReference,omitempty"`
+	Priority        int            `json:"priority,omitempty"`
+	RejudgeOf       string         `json:"rejudgeOf,omitempty"`
 	FailureCode     string         `json:"failureCode,omitempty"`
 	Result          *JobResultView `json:"result,omitempty"`
 }
 
+// JobListQuery carries the public list filters. Language is a public
+// language ID; CreatedAfter is inclusive and CreatedBefore exclusive.
 type JobListQuery struct {
-	Cursor string
-	Limit  int
-	Status JobStatus
+	Cursor          string
+	Limit           int
+	Status          JobStatus
+	BundleID        string
+	Language        string
+	ClientReference string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
 }
 
 type JobListPage struct {
@@ -101,6 +123,10 @@ type JobService interface {
 }
 
 func (server *Server) handleJobs(response http.ResponseWriter, request *http.Request, requestID string) {
+	if request.URL.Path == JobBatchPath {
+		server.handleJobBatchSubmit(response, request, requestID)
+		return
+	}
 	if request.URL.Path == "/api/v1/judge-jobs" {
 		server.handleJobCollection(response, request, requestID)
 		return
@@ -115,6 +141,14 @@ func (server *Server) handleJobs(response http.ResponseWriter, request *http.Req
 		server.handleJobCancel(response, request, requestID, segments[0])
 		return
 	}
+	if len(segments) == 2 && segments[1] == "rejudge" && jobIDPattern.MatchString(segments[0]) {
+		server.handleJobRejudge(response, request, requestID, segments[0])
+		return
+	}
+	if server.jobEvents != nil && len(segments) == 2 && segments[1] == "events" && jobIDPattern.MatchString(segments[0]) {
+		server.handleJobEvents(response, request, requestID, segments[0])
+		return
+	}
 	writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested judge job does not exist.", requestID))
 }
 
@@ -135,6 +169,68 @@ func (server *Server) handleJobSubmit(response http.ResponseWriter, request *htt
 	if !authenticated {
 		return
 	}
+	finishBodyRead, acquired := server.acquireJobBodyReader(response, request, requestID)
+	if !acquired {
+		return
+	}
+	defer finishBodyRead()
+	if !hasApplicationJSONContentType(request.Header.Values("Content-Type")) {
+		finishBodyRead()
+		writeProblem(response, problemFor(http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported media type", "Use Content-Type: application/json for judge job submissions.", requestID))
+		return
+	}
+	idempotencyKey, detail := idempotencyKeyHeader(request)
+	if detail != "" {
+		finishBodyRead()
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-idempotency-key", "Invalid Idempotency-Key", detail, requestID))
+		return
+	}
+	var command SubmitJobCommand
+	decodeErr := decodeStrictJSON(response, request, &command, maximumJobRequestBytes(server.capabilities.Limits.MaxSourceBytes))
+	finishBodyRead()
+	if decodeErr != nil {
+		writeJobBodyDecodeProblem(response, requestID, decodeErr)
+		return
+	}
+	submitContext, cancelSubmit := context.WithTimeout(request.Context(), server.jobSubmitTimeout)
+	defer cancelSubmit()
+	view, replayed, err := server.jobs.Submit(submitContext, principal.TenantID, idempotencyKey, command, server.jobAdmission(principal.TenantID))
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		err = ErrJobUnavailable
+	}
+	metrics.RecordJobAdmission(jobAdmissionOutcome(err, replayed))
+	if err != nil {
+		server.writeJobError(response, requestID, err)
+		return
+	}
+	if view.StatusURL == "" {
+		view.StatusURL = "/api/v1/judge-jobs/" + view.JobID
+	}
+	response.Header().Set("Location", view.StatusURL)
+	if replayed {
+		response.Header().Set("Idempotent-Replay", "true")
+	}
+	writeJSON(response, http.StatusAccepted, view)
+}
+
+// idempotencyKeyHeader returns the single valid Idempotency-Key, or the
+// problem detail explaining why the header is unusable.
+func idempotencyKeyHeader(request *http.Request) (string, string) {
+	values := request.Header.Values("Idempotency-Key")
+	if len(values) != 1 {
+		return "", "Provide exactly one Idempotency-Key header."
+	}
+	if err := external.ValidateIdempotencyKey(values[0]); err != nil {
+		return "", "Provide 16 to 128 visible ASCII characters."
+	}
+	return values[0], ""
+}
+
+// acquireJobBodyReader applies the JSON body read deadline and takes one of
+// the bounded body-reader slots. On success the returned function drains and
+// closes the body and frees the slot; it is idempotent, so handlers both
+// defer it and call it as soon as the body has been decoded.
+func (server *Server) acquireJobBodyReader(response http.ResponseWriter, request *http.Request, requestID string) (func(), bool) {
 	controller := http.NewResponseController(response)
 	deadlineSupported := true
 	if err := controller.SetReadDeadline(time.Now().Add(server.jobBodyReadTimeout)); err != nil {
@@ -144,19 +240,20 @@ func (server *Server) handleJobSubmit(response http.ResponseWriter, request *htt
 			closeUnreadRequestBody(response, request, controller)
 			response.Header().Set("Retry-After", "1")
 			writeProblem(response, problemFor(http.StatusServiceUnavailable, "submit-read-protection-unavailable", "Submit read protection unavailable", "Retry the judge job submission later.", requestID))
-			return
+			return nil, false
 		}
 	}
 	select {
 	case server.jobBodyReaders <- struct{}{}:
 	default:
 		closeUnreadRequestBody(response, request, controller)
+		metrics.RecordJobAdmission("capacity_exhausted")
 		response.Header().Set("Retry-After", "1")
 		writeProblem(response, problemFor(http.StatusServiceUnavailable, "submit-capacity-exhausted", "Submit capacity exhausted", "Retry the judge job submission later.", requestID))
-		return
+		return nil, false
 	}
 	var finishBodyReadOnce sync.Once
-	finishBodyRead := func() {
+	return func() {
 		finishBodyReadOnce.Do(func() {
 			if !deadlineSupported {
 				closeUnreadRequestBody(response, request, controller)
@@ -167,46 +264,28 @@ func (server *Server) handleJobSubmit(response http.ResponseWriter, request *htt
 			}
 			<-server.jobBodyReaders
 		})
-	}
-	defer finishBodyRead()
-	if !hasApplicationJSONContentType(request.Header.Values("Content-Type")) {
-		finishBodyRead()
-		writeProblem(response, problemFor(http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported media type", "Use Content-Type: application/json for judge job submissions.", requestID))
-		return
-	}
-	idempotencyValues := request.Header.Values("Idempotency-Key")
-	if len(idempotencyValues) != 1 {
-		finishBodyRead()
-		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-idempotency-key", "Invalid Idempotency-Key", "Provide exactly one Idempotency-Key header.", requestID))
-		return
-	}
-	idempotencyKey := idempotencyValues[0]
-	if err := external.ValidateIdempotencyKey(idempotencyKey); err != nil {
-		finishBodyRead()
-		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-idempotency-key", "Invalid Idempotency-Key", "Provide 16 to 128 visible ASCII characters.", requestID))
-		return
-	}
-	var command SubmitJobCommand
-	decodeErr := decodeStrictJSON(response, request, &command, maximumJobRequestBytes(server.capabilities.Limits.MaxSourceBytes))
-	finishBodyRead()
-	if decodeErr != nil {
-		if isRequestBodyTimeout(decodeErr) {
-			response.Header().Set("Connection", "close")
-			response.Header().Set("Retry-After", "1")
-			writeProblem(response, problemFor(http.StatusRequestTimeout, "request-body-timeout", "Request body timeout", "Retry the request with a complete JSON body before the read deadline.", requestID))
-			return
-		}
-		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-json", "Invalid request body", "Provide one JSON object containing only documented fields.", requestID))
+	}, true
+}
+
+func writeJobBodyDecodeProblem(response http.ResponseWriter, requestID string, err error) {
+	if isRequestBodyTimeout(err) {
+		response.Header().Set("Connection", "close")
+		response.Header().Set("Retry-After", "1")
+		writeProblem(response, problemFor(http.StatusRequestTimeout, "request-body-timeout", "Request body timeout", "Retry the request with a complete JSON body before the read deadline.", requestID))
 		return
 	}
-	submitContext, cancelSubmit := context.WithTimeout(request.Context(), server.jobSubmitTimeout)
-	defer cancelSubmit()
+	writeProblem(response, problemFor(http.StatusBadRequest, "invalid-json", "Invalid request body", "Provide one JSON object containing only documented fields.", requestID))
+}
+
+// jobAdmission charges one judge-submit token for the tenant. Each returned
+// function charges at most once however often a service invokes it.
+func (server *Server) jobAdmission(tenantID string) JobAdmission {
 	var admissionOnce sync.Once
 	var admissionErr error
-	admit := func(admissionContext context.Context) error {
+	return func(admissionContext context.Context) error {
 		admissionOnce.Do(func() {
 			decision, err := server.jobWriteQuota.Allow(admissionContext, external.QuotaRequest{
-				TenantID: principal.TenantID,
+				TenantID: tenantID,
 				Kind:     external.QuotaJudgeSubmit,
 				Cost:     1,
 				Limit:    server.jobWriteLimit,
@@ -225,22 +304,6 @@ func (server *Server) handleJobSubmit(response http.ResponseWriter, request *htt
 		})
 		return admissionErr
 	}
-	view, replayed, err := server.jobs.Submit(submitContext, principal.TenantID, idempotencyKey, command, admit)
-	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
-			err = ErrJobUnavailable
-		}
-		server.writeJobError(response, requestID, err)
-		return
-	}
-	if view.StatusURL == "" {
-		view.StatusURL = "/api/v1/judge-jobs/" + view.JobID
-	}
-	response.Header().Set("Location", view.StatusURL)
-	if replayed {
-		response.Header().Set("Idempotent-Replay", "true")
-	}
-	writeJSON(response, http.StatusAccepted, view)
 }
 
 func drainAndCloseRequestBody(body io.ReadCloser) bool {
@@ -297,13 +360,13 @@ func (server *Server) handleJobList(response http.ResponseWriter, request *http.
 	}
 	query, err := parseJobListQuery(request)
 	if err != nil {
-		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use only cursor, limit (1-100), and a documented status.", requestID))
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use only cursor, limit (1-100), a documented status, bundleId, language, clientReference, and RFC 3339 createdAfter/createdBefore bounds.", requestID))
 		return
 	}
 	page, err := server.jobs.List(request.Context(), principal.TenantID, query)
 	if err != nil {
 		if errors.Is(err, ErrJobInvalid) {
-			writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use an untampered cursor issued for this tenant and status filter.", requestID))
+			writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use an untampered cursor issued for this tenant and filter.", requestID))
 			return
 		}
 		server.writeJobError(response, requestID, err)
@@ -321,11 +384,19 @@ func parseJobListQuery(request *http.Request) (JobListQuery, error) {
 		return JobListQuery{}, ErrJobInvalid
 	}
 	for key, entries := range values {
-		if key != "cursor" && key != "limit" && key != "status" || len(entries) != 1 {
+		switch key {
+		case "cursor", "limit", "status", "bundleId", "language", "clientReference", "createdAfter", "createdBefore":
+		default:
+			return JobListQuery{}, ErrJobInvalid
+		}
+		if len(entries) != 1 {
 			return JobListQuery{}, ErrJobInvalid
 		}
 	}
-	query := JobListQuery{Cursor: values.Get("cursor"), Limit: 50}
+	query := JobListQuery{
+		Cursor: values.Get("cursor"), Limit: 50,
+		BundleID: values.Get("bundleId"), Language: values.Get("language"), ClientReference: values.Get("clientReference"),
+	}
 	if len(query.Cursor) > 512 {
 		return JobListQuery{}, ErrJobInvalid
 	}
@@ -344,9 +415,33 @@ func parseJobListQuery(request *http.Request) (JobListQuery, error) {
 			return JobListQuery{}, ErrJobInvalid
 		}
 	}
+	if query.BundleID != "" && !jobIDPattern.MatchString(query.BundleID) ||
+		query.Language != "" && !supportedLanguage(query.Language) ||
+		len(query.ClientReference) > 255 || !utf8.ValidString(query.ClientReference) {
+		return JobListQuery{}, ErrJobInvalid
+	}
+	for key, target := range map[string]*time.Time{"createdAfter": &query.CreatedAfter, "createdBefore": &query.CreatedBefore} {
+		raw := values.Get(key)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil || len(raw) > 64 {
+			return JobListQuery{}, ErrJobInvalid
+		}
+		*target = parsed.UTC()
+	}
+	if !query.CreatedAfter.IsZero() && !query.CreatedBefore.IsZero() && !query.CreatedAfter.Before(query.CreatedBefore) {
+		return JobListQuery{}, ErrJobInvalid
+	}
 	return query, nil
 }
 
+func supportedLanguage(publicID string) bool {
+	_, ok := judgecontract.ResolveLanguage(publicID)
+	return ok
+}
+
 func maximumJobRequestBytes(maxSourceBytes int64) int64 {
 	return maxSourceBytes*maximumJobRequestEncodingExpansion + maximumJobRequestEnvelopeBytes
 }
@@ -388,36 +483,70 @@ func (server *Server) handleJobCancel(response http.ResponseWriter, request *htt
 }
 
 func (server *Server) writeJobError(response http.ResponseWriter, requestID string, err error) {
+	problem, retryAfter := jobErrorProblem(err, requestID)
+	if retryAfter != "" {
+		response.Header().Set("Retry-After", retryAfter)
+	}
+	writeProblem(response, problem)
+}
+
+// jobErrorProblem maps a job service error to its problem and Retry-After
+// value, which is empty when the client should not retry.
+func jobErrorProblem(err error, requestID string) (Problem, string) {
 	var quotaError *jobQuotaAdmissionError
 	if errors.As(err, &quotaError) {
 		if quotaError.unavailable {
-			response.Header().Set("Retry-After", "5")
-			writeProblem(response, problemFor(http.StatusServiceUnavailable, "quota-unavailable", "Quota temporarily unavailable", "Retry the request later.", requestID))
-			return
+			return problemFor(http.StatusServiceUnavailable, "quota-unavailable", "Quota temporarily unavailable", "Retry the request later.", requestID), "5"
 		}
 		retrySeconds := int64(math.Ceil(quotaError.retryAfter.Seconds()))
 		if retrySeconds < 1 {
 			retrySeconds = 1
 		}
-		response.Header().Set("Retry-After", strconv.FormatInt(retrySeconds, 10))
-		writeProblem(response, problemFor(http.StatusTooManyRequests, "quota-exceeded", "Quota exceeded", "Retry after the indicated delay.", requestID))
-		return
+		return problemFor(http.StatusTooManyRequests, "quota-exceeded", "Quota exceeded", "Retry after the indicated delay.", requestID), strconv.FormatInt(retrySeconds, 10)
 	}
 	switch {
 	case errors.Is(err, ErrJobNotFound):
-		writeProblem(response, problemFor(http.StatusNotFound, "job-not-found", "Judge job not found", "The requested judge job does not exist.", requestID))
+		return problemFor(http.StatusNotFound, "job-not-found", "Judge job not found", "The requested judge job does not exist.", requestID), ""
+	case errors.Is(err, ErrJobNotRejudgeable):
+		return problemFor(http.StatusConflict, "job-not-rejudgeable", "Judge job cannot be rejudged", "Only finished jobs whose source is still retained can be rejudged.", requestID), ""
+	case errors.Is(err, ErrIdempotencyConflict):
+		return problemFor(http.StatusConflict, "idempotency-conflict", "Idempotency conflict", "The Idempotency-Key is already bound to a different request.", requestID), ""
+	case errors.Is(err, ErrJobInvalid):
+		return problemFor(http.StatusUnprocessableEntity, "invalid-job", "Judge job is invalid", "The job could not be accepted under tenant policy.", requestID), ""
+	case errors.Is(err, ErrJobQuotaExceeded):
+		return problemFor(http.StatusTooManyRequests, "job-quota-exceeded", "Judge job quota exceeded", "Wait for queued work to finish before retrying.", requestID), "5"
+	case errors.Is(err, ErrJobUnavailable):
+		return problemFor(http.StatusServiceUnavailable, "job-service-unavailable", "Judge job service unavailable", "Retry the request later.", requestID), "5"
+	default:
+		return problemFor(http.StatusInternalServerError, "internal-error", "Internal server error", "The request could not be completed.", requestID), ""
+	}
+}
+
+// jobAdmissionOutcome mirrors the problem types writeJobError chooses, so the
+// admission counter and the responses clients see stay comparable.
+func jobAdmissionOutcome(err error, replayed bool) string {
+	var quotaError *jobQuotaAdmissionError
+	switch {
+	case err == nil && replayed:
+		return "replayed"
+	case err == nil:
+		return "accepted"
+	case errors.As(err, &quotaError) && quotaError.unavailable:
+		return "quota_unavailable"
+	case errors.As(err, &quotaError):
+		return "rate_limited"
 	case errors.Is(err, ErrIdempotencyConflict):
-		writeProblem(response, problemFor(http.StatusConflict, "idempotency-conflict", "Idempotency conflict", "The Idempotency-Key is already bound to a different request.", requestID))
+		return "idempotency_conflict"
 	case errors.Is(err, ErrJobInvalid):
-		writeProblem(response, problemFor(http.StatusUnprocessableEntity, "invalid-job", "Judge job is invalid", "The job could not be accepted under tenant policy.", requestID))
+		return "invalid"
+	case errors.Is(err, ErrJobNotRejudgeable):
+		return "not_rejudgeable"
 	case errors.Is(err, ErrJobQuotaExceeded):
-		response.Header().Set("Retry-After", "5")
-		writeProblem(response, problemFor(http.StatusTooManyRequests, "job-quota-exceeded", "Judge job quota exceeded", "Wait for queued work to finish before retrying.", requestID))
+		return "queue_full"
 	case errors.Is(err, ErrJobUnavailable):
-		response.Header().Set("Retry-After", "5")
-		writeProblem(response, problemFor(http.StatusServiceUnavailable, "job-service-unavailable", "Judge job service unavailable", "Retry the request later.", requestID))
+		return "unavailable"
 	default:
-		writeProblem(response, problemFor(http.StatusInternalServerError, "internal-error", "Internal server error", "The request could not be completed.", requestID))
+		return "error"
 	}
 }
 
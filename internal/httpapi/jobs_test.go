@@ -35,6 +35,12 @@ type jobServiceStub struct {
 	admissionCalls  int
 	submitDeadline  time.Time
 	blockSubmit     bool
+	batchItems      []BatchSubmitJobItem
+	batchResults    []BatchSubmitResult
+	rejudgeID       string
+	rejudgeCommand  RejudgeJobCommand
+	rejudgeBundle   RejudgeBundleCommand
+	rejudgePage     BundleRejudgePage
 }
 
 type writeQuotaStub struct {
@@ -128,6 +134,44 @@ func (service *jobServiceStub) Submit(ctx context.Context, tenantID, idempotency
 	service.submittedTenant = tenantID
 	return service.view, service.replayed, service.err
 }
+func (service *jobServiceStub) SubmitBatch(ctx context.Context, tenantID string, items []BatchSubmitJobItem, admissions []JobAdmission) ([]BatchSubmitResult, error) {
+	service.submittedTenant, service.batchItems = tenantID, items
+	if service.err != nil {
+		return nil, service.err
+	}
+	results := service.batchResults
+	if results == nil {
+		results = make([]BatchSubmitResult, len(items))
+		for index := range results {
+			results[index].View = service.view
+		}
+	}
+	for index := range results {
+		if results[index].Err == nil && !results[index].Replayed {
+			results[index].Err = admissions[index](ctx)
+		}
+	}
+	return results, nil
+}
+func (service *jobServiceStub) Rejudge(ctx context.Context, tenantID, idempotencyKey, jobID string, command RejudgeJobCommand, admit JobAdmission) (JobView, bool, error) {
+	service.rejudgeID, service.rejudgeCommand = jobID, command
+	return service.Submit(ctx, tenantID, idempotencyKey, SubmitJobCommand{}, admit)
+}
+func (service *jobServiceStub) RejudgeBundle(ctx context.Context, tenantID, idempotencyKey, bundleID string, command RejudgeBundleCommand, admission func() JobAdmission) (BundleRejudgePage, error) {
+	service.submittedTenant, service.idempotencyKey = tenantID, idempotencyKey
+	service.rejudgeID, service.rejudgeBundle = bundleID, command
+	if service.err != nil {
+		return BundleRejudgePage{}, service.err
+	}
+	page := service.rejudgePage
+	page.Items = append([]BundleRejudgeResult(nil), page.Items...)
+	for index := range page.Items {
+		if page.Items[index].Err == nil && !page.Items[index].Replayed {
+			page.Items[index].Err = admission()(ctx)
+		}
+	}
+	return page, nil
+}
 func (service *jobServiceStub) Get(_ context.Context, tenantID, jobID string) (JobView, error) {
 	service.getTenant, service.getID = tenantID, jobID
 	return service.view, service.err
@@ -515,10 +559,34 @@ func TestListJudgeJobsUsesStableCursorQuery(t *testing.T) {
 	}
 }
 
+func TestListJudgeJobsParsesSearchFilters(t *testing.T) {
+	service := &jobServiceStub{}
+	server := newJobTestServer(t, service, ScopeJobRead)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs?bundleId=ceirceirceirceirceirceircf&language=cpp&clientReference=submission%2042"+
+		"&createdAfter=2026-07-19T08:00:00%2B08:00&createdBefore=2026-07-20T00:00:00.5Z", nil)
+	request.Header.Set("Authorization", "Bearer valid")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	want := JobListQuery{
+		Limit: 50, BundleID: "ceirceirceirceirceirceircf", Language: "cpp", ClientReference: "submission 42",
+		CreatedAfter:  time.Date(2026, 7, 19, 0, 0, 0, 0, time.UTC),
+		CreatedBefore: time.Date(2026, 7, 20, 0, 0, 0, 500000000, time.UTC),
+	}
+	if response.Code != http.StatusOK || service.listQuery != want {
+		t.Fatalf("status=%d query=%+v", response.Code, service.listQuery)
+	}
+}
+
 func TestListJudgeJobsRejectsInvalidFilters(t *testing.T) {
 	service := &jobServiceStub{}
 	server := newJobTestServer(t, service, ScopeJobRead)
-	for _, query := range []string{"limit=0", "limit=101", "limit=nope", "status=UNKNOWN", "extra=x", "limit=%ZZ"} {
+	for _, query := range []string{
+		"limit=0", "limit=101", "limit=nope", "status=UNKNOWN", "extra=x", "limit=%ZZ",
+		"bundleId=short", "language=cobol", "clientReference=" + strings.Repeat("r", 256), "clientReference=%FF",
+		"clientReference=a&clientReference=b", "createdAfter=yesterday", "createdBefore=2026-07-19",
+		"createdAfter=2026-07-20T00:00:00Z&createdBefore=2026-07-20T00:00:00Z",
+	} {
 		request := httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs?"+query, nil)
 		request.Header.Set("Authorization", "Bearer valid")
 		response := httptest.NewRecorder()
@@ -762,3 +830,26 @@ func newJobQuotaTestServer(t *testing.T, service JobService, quota external.Quot
 	}
 	return server
 }
+
+func TestJobAdmissionOutcomeFollowsTheProblemMapping(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		replayed bool
+		want     string
+	}{
+		{want: "accepted"},
+		{replayed: true, want: "replayed"},
+		{err: &jobQuotaAdmissionError{retryAfter: time.Second}, want: "rate_limited"},
+		{err: &jobQuotaAdmissionError{unavailable: true}, want: "quota_unavailable"},
+		{err: ErrIdempotencyConflict, want: "idempotency_conflict"},
+		{err: ErrJobInvalid, want: "invalid"},
+		{err: ErrJobNotRejudgeable, want: "not_rejudgeable"},
+		{err: ErrJobQuotaExceeded, want: "queue_full"},
+		{err: ErrJobUnavailable, want: "unavailable"},
+		{err: errors.New("boom"), want: "error"},
+	} {
+		if got := jobAdmissionOutcome(test.err, test.replayed); got != test.want {
+			t.Fatalf("outcome(%v, %t)=%q want %q", test.err, test.replayed, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/logging"
+)
+
+// withRequestLogging stores the request ID for every record written while the
+// request is served.
+func withRequestLogging(request *http.Request, requestID string) *http.Request {
+	return request.WithContext(logging.With(request.Context(), logging.RequestID(requestID)))
+}
+
+// tagLogTenant adds the authenticated tenant in place. The request was
+// already copied by ServeHTTP, so the access record written by the deferred
+// logRequest observes the tenant as well.
+func tagLogTenant(request *http.Request, tenantID string) {
+	*request = *request.WithContext(logging.With(request.Context(), logging.Tenant(tenantID)))
+}
+
+// logRequest writes one access record. Only the method, route template,
+// status and duration are recorded; paths, queries and bodies are not.
+func logRequest(request *http.Request, recorder *statusRecorder, started time.Time) {
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+	slog.Log(request.Context(), level, "http request",
+		"method", request.Method,
+		"route", spanRoute(request.URL.Path),
+		"status", status,
+		"duration_ms", time.Since(started).Milliseconds(),
+	)
+}
@@ -16,6 +16,25 @@ type durableJobRepository interface {
 	Cancel(context.Context, string, string) (external.ExternalJobRecord, error)
 }
 
+// durableJobEventRepository is optional so repositories without an event
+// table keep serving the job resource; the stream then reports unavailable.
+type durableJobEventRepository interface {
+	ListJobEvents(context.Context, string, string, uint32, int) (external.JobEventPage, error)
+}
+
+// durableJobBatchRepository is optional; without it SubmitBatch submits the
+// items one at a time.
+type durableJobBatchRepository interface {
+	SubmitBatch(context.Context, string, []external.BatchJobSubmission, func(context.Context, int) error) ([]external.BatchSubmitJobResult, error)
+}
+
+// durableJobRejudgeRepository is optional; without it the rejudge endpoints
+// report the service unavailable.
+type durableJobRejudgeRepository interface {
+	Rejudge(context.Context, string, string, string, external.RejudgeOptions, func(context.Context) error) (external.SubmitJobResult, error)
+	RejudgeBundle(context.Context, string, string, string, external.BundleRejudgeOptions, func(context.Context, int) error) (external.BundleRejudgeResult, error)
+}
+
 type MySQLJobService struct{ repository durableJobRepository }
 
 func NewMySQLJobService(repository durableJobRepository) (*MySQLJobService, error) {
@@ -32,15 +51,99 @@ func (service *MySQLJobService) Submit(
 	command SubmitJobCommand,
 	admit JobAdmission,
 ) (JobView, bool, error) {
-	language, ok := judgecontract.ResolveLanguage(command.Language)
+	request, err := durableJobRequest(command)
+	if err != nil {
+		return JobView{}, false, err
+	}
+	var admissionErr error
+	result, err := service.repository.Submit(ctx, tenantID, idempotencyKey, request, func(admissionContext context.Context) error {
+		admissionErr = admit(admissionContext)
+		return admissionErr
+	})
+	if err != nil {
+		if admissionErr != nil && errors.Is(err, admissionErr) {
+			return JobView{}, false, admissionErr
+		}
+		return JobView{}, false, mapRepositoryJobError(err)
+	}
+	view, err := publicJobView(result.Job)
+	return view, result.Replayed, err
+}
+
+func (service *MySQLJobService) SubmitBatch(
+	ctx context.Context,
+	tenantID string,
+	items []BatchSubmitJobItem,
+	admissions []JobAdmission,
+) ([]BatchSubmitResult, error) {
+	if len(admissions) != len(items) {
+		return nil, ErrJobInvalid
+	}
+	results := make([]BatchSubmitResult, len(items))
+	repository, ok := service.repository.(durableJobBatchRepository)
+	if !ok {
+		for index, item := range items {
+			view, replayed, err := service.Submit(ctx, tenantID, item.IdempotencyKey, item.SubmitJobCommand, admissions[index])
+			results[index] = BatchSubmitResult{View: view, Replayed: replayed, Err: err}
+		}
+		return results, nil
+	}
+	var submissions []external.BatchJobSubmission
+	var indexes []int
+	for index, item := range items {
+		request, err := durableJobRequest(item.SubmitJobCommand)
+		if err != nil {
+			results[index].Err = err
+			continue
+		}
+		submissions = append(submissions, external.BatchJobSubmission{IdempotencyKey: item.IdempotencyKey, Request: request})
+		indexes = append(indexes, index)
+	}
+	if len(submissions) == 0 {
+		return results, nil
+	}
+	admissionErrs := make([]error, len(items))
+	outcomes, err := repository.SubmitBatch(ctx, tenantID, submissions, func(admissionContext context.Context, position int) error {
+		index := indexes[position]
+		admissionErrs[index] = admissions[index](admissionContext)
+		return admissionErrs[index]
+	})
+	if err != nil {
+		return nil, mapRepositoryJobError(err)
+	}
+	if len(outcomes) != len(submissions) {
+		return nil, ErrJobUnavailable
+	}
+	for position, outcome := range outcomes {
+		index := indexes[position]
+		switch {
+		case outcome.Err != nil && admissionErrs[index] != nil && errors.Is(outcome.Err, admissionErrs[index]):
+			results[index].Err = admissionErrs[index]
+		case outcome.Err != nil:
+			results[index].Err = mapRepositoryJobError(outcome.Err)
+		default:
+			view, err := publicJobView(outcome.Job)
+			results[index] = BatchSubmitResult{View: view, Replayed: outcome.Replayed, Err: err}
+		}
+	}
+	return results, nil
+}
+
+func (service *MySQLJobService) Rejudge(
+	ctx context.Context,
+	tenantID string,
+	idempotencyKey string,
+	jobID string,
+	command RejudgeJobCommand,
+	admit JobAdmission,
+) (JobView, bool, error) {
+	repository, ok := service.repository.(durableJobRejudgeRepository)
 	if !ok {
-		return JobView{}, false, ErrJobInvalid
+		return JobView{}, false, ErrJobUnavailable
 	}
 	var admissionErr error
-	result, err := service.repository.Submit(ctx, tenantID, idempotencyKey, external.JudgeJobRequest{
-		BundleID: command.BundleID, Language: language.SandboxID, SourceCode: []byte(command.SourceCode),
-		StopOnFailure: command.StopOnFailure, CallbackID: command.CallbackID,
-		ClientReference: command.ClientReference,
+	result, err := repository.Rejudge(ctx, tenantID, idempotencyKey, jobID, external.RejudgeOptions{
+		BundleID: command.ReplacementBundleID, Priority: command.Priority,
 	}, func(admissionContext context.Context) error {
 		admissionErr = admit(admissionContext)
 		return admissionErr
@@ -55,10 +158,74 @@ func (service *MySQLJobService) Submit(
 	return view, result.Replayed, err
 }
 
+func (service *MySQLJobService) RejudgeBundle(
+	ctx context.Context,
+	tenantID string,
+	idempotencyKey string,
+	bundleID string,
+	command RejudgeBundleCommand,
+	admission func() JobAdmission,
+) (BundleRejudgePage, error) {
+	repository, ok := service.repository.(durableJobRejudgeRepository)
+	if !ok {
+		return BundleRejudgePage{}, ErrJobUnavailable
+	}
+	admissionErrs := make(map[int]error)
+	result, err := repository.RejudgeBundle(ctx, tenantID, idempotencyKey, bundleID, external.BundleRejudgeOptions{
+		RejudgeOptions: external.RejudgeOptions{BundleID: command.ReplacementBundleID, Priority: command.Priority},
+		Cursor:         command.Cursor, Limit: command.Limit,
+	}, func(admissionContext context.Context, index int) error {
+		admissionErrs[index] = admission()(admissionContext)
+		return admissionErrs[index]
+	})
+	if errors.Is(err, external.ErrBundleNotFound) {
+		return BundleRejudgePage{}, ErrJobNotFound
+	}
+	if err != nil {
+		return BundleRejudgePage{}, mapRepositoryJobError(err)
+	}
+	page := BundleRejudgePage{Items: make([]BundleRejudgeResult, len(result.Items)), NextCursor: result.NextCursor}
+	for index, item := range result.Items {
+		page.Items[index].RejudgeOf = item.RejudgeOf
+		switch {
+		case item.Err != nil && admissionErrs[index] != nil && errors.Is(item.Err, admissionErrs[index]):
+			page.Items[index].Err = admissionErrs[index]
+		case item.Err != nil:
+			page.Items[index].Err = mapRepositoryJobError(item.Err)
+		default:
+			view, err := publicJobView(item.Job)
+			page.Items[index].BatchSubmitResult = BatchSubmitResult{View: view, Replayed: item.Replayed, Err: err}
+		}
+	}
+	return page, nil
+}
+
+func durableJobRequest(command SubmitJobCommand) (external.JudgeJobRequest, error) {
+	language, ok := judgecontract.ResolveLanguage(command.Language)
+	if !ok {
+		return external.JudgeJobRequest{}, ErrJobInvalid
+	}
+	return external.JudgeJobRequest{
+		BundleID: command.BundleID, Language: language.SandboxID, SourceCode: []byte(command.SourceCode),
+		StopOnFailure: command.StopOnFailure, CallbackID: command.CallbackID,
+		ClientReference: command.ClientReference, Priority: command.Priority,
+	}, nil
+}
+
 func (service *MySQLJobService) List(ctx context.Context, tenantID string, query JobListQuery) (JobListPage, error) {
-	result, err := service.repository.List(ctx, tenantID, external.JobListOptions{
+	options := external.JobListOptions{
 		Cursor: query.Cursor, Limit: query.Limit, Status: external.JobStatus(query.Status),
-	})
+		BundleID: query.BundleID, ClientReference: query.ClientReference,
+		CreatedAfter: query.CreatedAfter, CreatedBefore: query.CreatedBefore,
+	}
+	if query.Language != "" {
+		language, ok := judgecontract.ResolveLanguage(query.Language)
+		if !ok {
+			return JobListPage{}, ErrJobInvalid
+		}
+		options.Language = language.SandboxID
+	}
+	result, err := service.repository.List(ctx, tenantID, options)
 	if err != nil {
 		return JobListPage{}, mapRepositoryJobError(err)
 	}
@@ -89,6 +256,62 @@ func (service *MySQLJobService) Cancel(ctx context.Context, tenantID, jobID stri
 	return publicJobView(job)
 }
 
+func (service *MySQLJobService) Events(ctx context.Context, tenantID, jobID string, afterSequence uint32, limit int) (JobEventPage, error) {
+	repository, ok := service.repository.(durableJobEventRepository)
+	if !ok {
+		return JobEventPage{}, ErrJobUnavailable
+	}
+	result, err := repository.ListJobEvents(ctx, tenantID, jobID, afterSequence, limit)
+	if err != nil {
+		return JobEventPage{}, mapRepositoryJobError(err)
+	}
+	status, err := publicJobStatus(result.Status)
+	if err != nil {
+		return JobEventPage{}, err
+	}
+	page := JobEventPage{Status: status, Events: make([]JobEventView, 0, len(result.Events))}
+	for _, event := range result.Events {
+		view, err := publicJobEventView(jobID, event)
+		if err != nil {
+			return JobEventPage{}, err
+		}
+		page.Events = append(page.Events, view)
+	}
+	return page, nil
+}
+
+func publicJobEventView(jobID string, event external.JobEvent) (JobEventView, error) {
+	view := JobEventView{
+		JobID: jobID, Sequence: event.Sequence, AttemptNo: event.AttemptNo, OccurredAt: event.OccurredAt,
+	}
+	switch event.Type {
+	case external.JobEventStatus:
+		status, err := publicJobStatus(event.Status)
+		if err != nil {
+			return JobEventView{}, err
+		}
+		view.Type, view.Status = "status", status
+		if event.Status == external.JobStatusFailed {
+			view.FailureCode = event.FailureCode
+		}
+	case external.JobEventCompile:
+		view.Type, view.CompileStatus = "compile", event.CompileStatus
+	case external.JobEventCase:
+		if event.Case == nil {
+			return JobEventView{}, ErrJobUnavailable
+		}
+		view.Type = "case"
+		view.Case = &CaseResultView{
+			CaseID: event.Case.CaseID, Verdict: event.Case.Verdict,
+			TimeMillis: event.Case.TimeMillis, MemoryBytes: event.Case.MemoryBytes,
+			Score: copyPublicScore(event.Case.Score), MaxScore: copyPublicScore(event.Case.MaxScore),
+		}
+	default:
+		return JobEventView{}, ErrJobUnavailable
+	}
+	return view, nil
+}
+
 func publicJobView(job external.ExternalJobRecord) (JobView, error) {
 	status, err := publicJobStatus(job.Status)
 	if err != nil {
@@ -96,7 +319,7 @@ func publicJobView(job external.ExternalJobRecord) (JobView, error) {
 	}
 	view := JobView{
 		JobID: job.ExternalID, Status: status, CreatedAt: job.CreatedAt,
-		ClientReference: job.ClientReference,
+		ClientReference: job.ClientReference, Priority: job.Priority, RejudgeOf: job.RejudgeOf,
 	}
 	if job.Status == external.JobStatusFailed {
 		view.FailureCode = job.FailureCode
@@ -115,6 +338,12 @@ func publicJobView(job external.ExternalJobRecord) (JobView, error) {
 				Score: copyPublicScore(item.Score), MaxScore: copyPublicScore(item.MaxScore),
 			})
 		}
+		for _, item := range job.Result.Subtasks {
+			view.Result.Subtasks = append(view.Result.Subtasks, SubtaskResultView{
+				SubtaskID: item.SubtaskID, Verdict: item.Verdict,
+				Score: item.Score, MaxScore: item.MaxScore, DependenciesMet: item.DependenciesMet,
+			})
+		}
 	}
 	return view, nil
 }
@@ -150,9 +379,12 @@ func mapRepositoryJobError(err error) error {
 		return ErrJobNotFound
 	case errors.Is(err, external.ErrExternalJobConflict):
 		return ErrIdempotencyConflict
+	case errors.Is(err, external.ErrJobNotRejudgeable):
+		return ErrJobNotRejudgeable
 	case errors.Is(err, external.ErrQueuedQuotaExceeded):
 		return ErrJobQuotaExceeded
-	case errors.Is(err, external.ErrExternalJobInvalid), errors.Is(err, external.ErrInvalidJobCursor):
+	case errors.Is(err, external.ErrExternalJobInvalid), errors.Is(err, external.ErrInvalidJobCursor),
+		errors.Is(err, external.ErrJobEventsInvalid):
 		return ErrJobInvalid
 	case errors.Is(err, external.ErrExternalJobUnavailable):
 		return ErrJobUnavailable
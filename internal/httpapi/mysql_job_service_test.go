@@ -3,6 +3,7 @@ package httpapi
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -66,7 +67,7 @@ func TestMySQLJobServiceMapsCommandsAndRedactsRepositoryInternals(t *testing.T)
 			ExternalID: "dddddddddddddddddddddddddd", ObjectKey: "external/secret/source.bin",
 			SHA256: []byte("secret-digest"), Nonce: []byte("secret-nonce"), KeyVersion: 7,
 		},
-		Status: external.JobStatusSucceeded, Language: "cpp", ClientReference: "client-7",
+		Status: external.JobStatusSucceeded, Language: "cpp", ClientReference: "client-7", Priority: 3,
 		WorkerID: "private-worker", CreatedAt: now,
 		Result: &external.DurableJobResult{
 			Verdict: "ACCEPTED", CompileStatus: "SUCCEEDED", TimeMillis: 7, MemoryBytes: 1024,
@@ -81,7 +82,7 @@ func TestMySQLJobServiceMapsCommandsAndRedactsRepositoryInternals(t *testing.T)
 	}
 	view, replayed, err := service.Submit(context.Background(), record.TenantExternalID, "idempotency-key", SubmitJobCommand{
 		BundleID: record.BundleExternalID, Language: "cpp", SourceCode: "int main(){}",
-		StopOnFailure: true, CallbackID: "eeeeeeeeeeeeeeeeeeeeeeeeee", ClientReference: "client-7",
+		StopOnFailure: true, CallbackID: "eeeeeeeeeeeeeeeeeeeeeeeeee", ClientReference: "client-7", Priority: 3,
 	}, func(context.Context) error { return nil })
 	if err != nil {
 		t.Fatal(err)
@@ -94,7 +95,8 @@ func TestMySQLJobServiceMapsCommandsAndRedactsRepositoryInternals(t *testing.T)
 		*view.Result.Cases[0].Score != 100 {
 		t.Fatalf("score view = %+v", view.Result)
 	}
-	if repository.request.BundleID != record.BundleExternalID || string(repository.request.SourceCode) != "int main(){}" || repository.request.CallbackID == "" {
+	if repository.request.BundleID != record.BundleExternalID || string(repository.request.SourceCode) != "int main(){}" ||
+		repository.request.CallbackID == "" || repository.request.Priority != 3 || view.Priority != 3 {
 		t.Fatalf("repository request = %+v", repository.request)
 	}
 	if view.StatusURL != "" {
@@ -143,6 +145,32 @@ func TestMySQLJobServiceMapsListAndPublicErrors(t *testing.T) {
 	}
 }
 
+func TestMySQLJobServiceResolvesListFilters(t *testing.T) {
+	repository := &durableJobRepositoryStub{}
+	service, err := NewMySQLJobService(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := JobListQuery{
+		Limit: 10, BundleID: "cccccccccccccccccccccccccc", Language: "python", ClientReference: "submission-42",
+		CreatedAfter: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), CreatedBefore: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if _, err := service.List(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", query); err != nil {
+		t.Fatal(err)
+	}
+	want := external.JobListOptions{
+		Limit: 10, BundleID: query.BundleID, Language: "python", ClientReference: query.ClientReference,
+		CreatedAfter: query.CreatedAfter, CreatedBefore: query.CreatedBefore,
+	}
+	if repository.listOptions != want {
+		t.Fatalf("list options = %+v", repository.listOptions)
+	}
+	query.Language = "cobol"
+	if _, err := service.List(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", query); !errors.Is(err, ErrJobInvalid) {
+		t.Fatalf("unknown language error = %v", err)
+	}
+}
+
 func TestMySQLJobServicePreservesAdmissionError(t *testing.T) {
 	repository := &durableJobRepositoryStub{}
 	service, err := NewMySQLJobService(repository)
@@ -240,3 +268,285 @@ func TestMySQLJobServiceHidesFailureCodeOutsideFailedState(t *testing.T) {
 		t.Fatalf("queued job leaked historical failureCode = %q", view.FailureCode)
 	}
 }
+
+type durableJobEventRepositoryStub struct {
+	durableJobRepositoryStub
+	eventPage     external.JobEventPage
+	eventError    error
+	afterSequence uint32
+	limit         int
+}
+
+func (repository *durableJobEventRepositoryStub) ListJobEvents(_ context.Context, tenantID, _ string, afterSequence uint32, limit int) (external.JobEventPage, error) {
+	repository.tenantID, repository.afterSequence, repository.limit = tenantID, afterSequence, limit
+	return repository.eventPage, repository.eventError
+}
+
+func TestMySQLJobServiceMapsDurableEventsToPublicViews(t *testing.T) {
+	now := time.Date(2026, 7, 19, 14, 0, 0, 0, time.UTC)
+	score, maximum := 0, 10
+	repository := &durableJobEventRepositoryStub{eventPage: external.JobEventPage{
+		Status: external.JobStatusFailed,
+		Events: []external.JobEvent{
+			{Sequence: 4, Type: external.JobEventCompile, AttemptNo: 2, CompileStatus: "SUCCEEDED", OccurredAt: now},
+			{Sequence: 5, Type: external.JobEventCase, AttemptNo: 2, Case: &external.DurableCaseResult{CaseID: "case-1", Verdict: "WRONG_ANSWER", TimeMillis: 3, MemoryBytes: 64, Score: &score, MaxScore: &maximum}, OccurredAt: now},
+			{Sequence: 6, Type: external.JobEventStatus, AttemptNo: 2, Status: external.JobStatusFailed, FailureCode: "SANDBOX_UNAVAILABLE", OccurredAt: now},
+		},
+	}}
+	service, err := NewMySQLJobService(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := service.Events(context.Background(), "tenant-7", "aaaaaaaaaaaaaaaaaaaaaaaaaa", 3, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repository.tenantID != "tenant-7" || repository.afterSequence != 3 || repository.limit != 100 || page.Status != JobFailed || len(page.Events) != 3 {
+		t.Fatalf("repository=%+v page=%+v", repository, page)
+	}
+	compile, judged, terminal := page.Events[0], page.Events[1], page.Events[2]
+	if compile.Type != "compile" || compile.CompileStatus != "SUCCEEDED" || compile.JobID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Fatalf("compile event=%+v", compile)
+	}
+	if judged.Type != "case" || judged.Case == nil || judged.Case.Verdict != "WRONG_ANSWER" || *judged.Case.MaxScore != 10 {
+		t.Fatalf("case event=%+v", judged)
+	}
+	*repository.eventPage.Events[1].Case.MaxScore = 99
+	if *judged.Case.MaxScore != 10 {
+		t.Fatal("case view aliases the repository score")
+	}
+	if terminal.Type != "status" || terminal.Status != JobFailed || terminal.FailureCode != "SANDBOX_UNAVAILABLE" || terminal.Sequence != 6 {
+		t.Fatalf("status event=%+v", terminal)
+	}
+
+	repository.eventError = external.ErrExternalJobNotFound
+	if _, err := service.Events(context.Background(), "tenant-8", "aaaaaaaaaaaaaaaaaaaaaaaaaa", 0, 100); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("foreign job error=%v", err)
+	}
+	plain, err := NewMySQLJobService(&durableJobRepositoryStub{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Events(context.Background(), "tenant-7", "aaaaaaaaaaaaaaaaaaaaaaaaaa", 0, 100); !errors.Is(err, ErrJobUnavailable) {
+		t.Fatalf("repository without events error=%v", err)
+	}
+}
+
+type durableJobBatchRepositoryStub struct {
+	durableJobRepositoryStub
+	batchItems   []external.BatchJobSubmission
+	batchResults []external.BatchSubmitJobResult
+	batchError   error
+}
+
+func (repository *durableJobBatchRepositoryStub) SubmitBatch(ctx context.Context, tenantID string, items []external.BatchJobSubmission, admit func(context.Context, int) error) ([]external.BatchSubmitJobResult, error) {
+	repository.tenantID, repository.batchItems = tenantID, items
+	if repository.batchError != nil {
+		return nil, repository.batchError
+	}
+	results := append([]external.BatchSubmitJobResult(nil), repository.batchResults...)
+	for index := range results {
+		if results[index].Err == nil && !results[index].Replayed {
+			if err := admit(ctx, index); err != nil {
+				results[index] = external.BatchSubmitJobResult{Err: err}
+			}
+		}
+	}
+	return results, nil
+}
+
+func TestMySQLJobServiceSubmitBatchMapsItemsAndPreservesAdmissionErrors(t *testing.T) {
+	created := external.SubmitJobResult{Job: external.ExternalJobRecord{
+		ExternalID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", Status: external.JobStatusQueued, Language: "cpp", CreatedAt: time.Now(),
+	}}
+	repository := &durableJobBatchRepositoryStub{batchResults: []external.BatchSubmitJobResult{
+		{SubmitJobResult: created},
+		{SubmitJobResult: created},
+		{Err: external.ErrExternalJobConflict},
+		{Err: external.ErrQueuedQuotaExceeded},
+	}}
+	service, err := NewMySQLJobService(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := func(key, language string) BatchSubmitJobItem {
+		return BatchSubmitJobItem{IdempotencyKey: key, SubmitJobCommand: SubmitJobCommand{
+			BundleID: "cccccccccccccccccccccccccc", Language: language, SourceCode: "int main(){}",
+		}}
+	}
+	items := []BatchSubmitJobItem{
+		item("batch-submission-01", "cpp"), item("batch-submission-02", "cobol"), item("batch-submission-03", "cpp"),
+		item("batch-submission-04", "cpp"), item("batch-submission-05", "cpp"),
+	}
+	quotaError := errors.New("rate limited")
+	var admitted []int
+	admissions := make([]JobAdmission, len(items))
+	for index := range admissions {
+		admissions[index] = func(context.Context) error {
+			admitted = append(admitted, index)
+			if index == 2 {
+				return quotaError
+			}
+			return nil
+		}
+	}
+	results, err := service.SubmitBatch(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", items, admissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repository.batchItems) != 4 || repository.batchItems[1].IdempotencyKey != "batch-submission-03" {
+		t.Fatalf("repository items = %+v", repository.batchItems)
+	}
+	if results[0].Err != nil || results[0].View.JobID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" ||
+		!errors.Is(results[1].Err, ErrJobInvalid) || results[2].Err != quotaError ||
+		!errors.Is(results[3].Err, ErrIdempotencyConflict) || !errors.Is(results[4].Err, ErrJobQuotaExceeded) {
+		t.Fatalf("results = %+v", results)
+	}
+	if len(admitted) != 2 || admitted[0] != 0 || admitted[1] != 2 {
+		t.Fatalf("admitted item indexes = %v", admitted)
+	}
+
+	repository.batchError = errors.New("database detail")
+	if _, err := service.SubmitBatch(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", items[:1], admissions[:1]); !errors.Is(err, ErrJobUnavailable) {
+		t.Fatalf("whole-batch repository error = %v", err)
+	}
+}
+
+func TestMySQLJobServiceSubmitBatchFallsBackToSequentialSubmits(t *testing.T) {
+	repository := &durableJobRepositoryStub{submitResult: external.SubmitJobResult{Job: external.ExternalJobRecord{
+		ExternalID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", Status: external.JobStatusQueued, Language: "cpp", CreatedAt: time.Now(),
+	}}}
+	service, err := NewMySQLJobService(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admit := func(context.Context) error { return nil }
+	results, err := service.SubmitBatch(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", []BatchSubmitJobItem{
+		{IdempotencyKey: "batch-submission-01", SubmitJobCommand: SubmitJobCommand{BundleID: "cccccccccccccccccccccccccc", Language: "cpp"}},
+		{IdempotencyKey: "batch-submission-02", SubmitJobCommand: SubmitJobCommand{BundleID: "cccccccccccccccccccccccccc", Language: "cpp"}},
+	}, []JobAdmission{admit, admit})
+	if err != nil || len(results) != 2 || results[1].Err != nil || results[1].View.JobID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Fatalf("results=%+v err=%v", results, err)
+	}
+	if repository.key != "batch-submission-02" {
+		t.Fatalf("last submitted key = %q", repository.key)
+	}
+	if _, err := service.SubmitBatch(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", make([]BatchSubmitJobItem, 2), []JobAdmission{admit}); !errors.Is(err, ErrJobInvalid) {
+		t.Fatalf("mismatched admissions error = %v", err)
+	}
+}
+
+type durableJobRejudgeRepositoryStub struct {
+	durableJobRepositoryStub
+	rejudgeJobID   string
+	rejudgeOptions external.RejudgeOptions
+	bundleOptions  external.BundleRejudgeOptions
+	bundleResult   external.BundleRejudgeResult
+	bundleError    error
+}
+
+func (repository *durableJobRejudgeRepositoryStub) Rejudge(ctx context.Context, tenantID, key, jobID string, options external.RejudgeOptions, admit func(context.Context) error) (external.SubmitJobResult, error) {
+	repository.rejudgeJobID, repository.rejudgeOptions = jobID, options
+	return repository.Submit(ctx, tenantID, key, external.JudgeJobRequest{}, admit)
+}
+
+func (repository *durableJobRejudgeRepositoryStub) RejudgeBundle(ctx context.Context, tenantID, key, bundleID string, options external.BundleRejudgeOptions, admit func(context.Context, int) error) (external.BundleRejudgeResult, error) {
+	repository.tenantID, repository.key, repository.rejudgeJobID, repository.bundleOptions = tenantID, key, bundleID, options
+	if repository.bundleError != nil {
+		return external.BundleRejudgeResult{}, repository.bundleError
+	}
+	result := repository.bundleResult
+	result.Items = append([]external.BundleRejudgeItem(nil), result.Items...)
+	for index := range result.Items {
+		if result.Items[index].Err == nil && !result.Items[index].Replayed {
+			if err := admit(ctx, index); err != nil {
+				result.Items[index].BatchSubmitJobResult = external.BatchSubmitJobResult{Err: err}
+			}
+		}
+	}
+	return result, nil
+}
+
+func TestMySQLJobServiceRejudgeMapsOptionsAndLinksTheOriginal(t *testing.T) {
+	repository := &durableJobRejudgeRepositoryStub{durableJobRepositoryStub: durableJobRepositoryStub{submitResult: external.SubmitJobResult{Job: external.ExternalJobRecord{
+		ExternalID: "dddddddddddddddddddddddddd", Status: external.JobStatusQueued, Language: "cpp", CreatedAt: time.Now(),
+		RejudgeOf: "aaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}}}}
+	service, err := NewMySQLJobService(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, replayed, err := service.Rejudge(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", "rejudge-key-00000001", "aaaaaaaaaaaaaaaaaaaaaaaaaa",
+		RejudgeJobCommand{ReplacementBundleID: "cccccccccccccccccccccccccc", Priority: 2}, func(context.Context) error { return nil })
+	if err != nil || replayed || view.JobID != "dddddddddddddddddddddddddd" || view.RejudgeOf != "aaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Fatalf("view=%+v replayed=%t err=%v", view, replayed, err)
+	}
+	if repository.rejudgeJobID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" || repository.rejudgeOptions != (external.RejudgeOptions{BundleID: "cccccccccccccccccccccccccc", Priority: 2}) {
+		t.Fatalf("job=%q options=%+v", repository.rejudgeJobID, repository.rejudgeOptions)
+	}
+	quotaError := errors.New("rate limited")
+	if _, _, err := service.Rejudge(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", "rejudge-key-00000001", "aaaaaaaaaaaaaaaaaaaaaaaaaa",
+		RejudgeJobCommand{}, func(context.Context) error { return quotaError }); err != quotaError {
+		t.Fatalf("admission error = %v", err)
+	}
+	repository.submitError = fmt.Errorf("%w: job has not finished", external.ErrJobNotRejudgeable)
+	if _, _, err := service.Rejudge(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", "rejudge-key-00000001", "aaaaaaaaaaaaaaaaaaaaaaaaaa",
+		RejudgeJobCommand{}, func(context.Context) error { return nil }); !errors.Is(err, ErrJobNotRejudgeable) {
+		t.Fatalf("not rejudgeable error = %v", err)
+	}
+}
+
+func TestMySQLJobServiceRejudgeBundleMapsItemsAndErrors(t *testing.T) {
+	created := external.SubmitJobResult{Job: external.ExternalJobRecord{
+		ExternalID: "dddddddddddddddddddddddddd", Status: external.JobStatusQueued, Language: "cpp", CreatedAt: time.Now(),
+		RejudgeOf: "aaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}}
+	repository := &durableJobRejudgeRepositoryStub{bundleResult: external.BundleRejudgeResult{NextCursor: "next-page", Items: []external.BundleRejudgeItem{
+		{RejudgeOf: "aaaaaaaaaaaaaaaaaaaaaaaaaa", BatchSubmitJobResult: external.BatchSubmitJobResult{SubmitJobResult: created}},
+		{RejudgeOf: "eeeeeeeeeeeeeeeeeeeeeeeeee", BatchSubmitJobResult: external.BatchSubmitJobResult{SubmitJobResult: created}},
+		{RejudgeOf: "ffffffffffffffffffffffffff", BatchSubmitJobResult: external.BatchSubmitJobResult{Err: external.ErrJobNotRejudgeable}},
+	}}}
+	service, err := NewMySQLJobService(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotaError := errors.New("rate limited")
+	admissions := 0
+	page, err := service.RejudgeBundle(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", "rejudge-key-00000001", "cccccccccccccccccccccccccc",
+		RejudgeBundleCommand{RejudgeJobCommand: RejudgeJobCommand{Priority: 1}, Cursor: "page-2", Limit: 3}, func() JobAdmission {
+			admissions++
+			if admissions == 2 {
+				return func(context.Context) error { return quotaError }
+			}
+			return func(context.Context) error { return nil }
+		})
+	if err != nil || page.NextCursor != "next-page" || len(page.Items) != 3 {
+		t.Fatalf("page=%+v err=%v", page, err)
+	}
+	if page.Items[0].Err != nil || page.Items[0].View.RejudgeOf != "aaaaaaaaaaaaaaaaaaaaaaaaaa" || page.Items[1].Err != quotaError ||
+		!errors.Is(page.Items[2].Err, ErrJobNotRejudgeable) || page.Items[2].RejudgeOf != "ffffffffffffffffffffffffff" {
+		t.Fatalf("items = %+v", page.Items)
+	}
+	if repository.bundleOptions != (external.BundleRejudgeOptions{RejudgeOptions: external.RejudgeOptions{Priority: 1}, Cursor: "page-2", Limit: 3}) {
+		t.Fatalf("options = %+v", repository.bundleOptions)
+	}
+	for repositoryErr, want := range map[error]error{
+		external.ErrBundleNotFound:    ErrJobNotFound,
+		external.ErrInvalidJobCursor:  ErrJobInvalid,
+		errors.New("database detail"): ErrJobUnavailable,
+	} {
+		repository.bundleError = repositoryErr
+		if _, err := service.RejudgeBundle(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", "rejudge-key-00000001", "cccccccccccccccccccccccccc",
+			RejudgeBundleCommand{Limit: 1}, func() JobAdmission { return nil }); !errors.Is(err, want) {
+			t.Fatalf("repository error %v mapped to %v, want %v", repositoryErr, err, want)
+		}
+	}
+
+	plain, err := NewMySQLJobService(&durableJobRepositoryStub{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := plain.Rejudge(context.Background(), "bbbbbbbbbbbbbbbbbbbbbbbbbb", "rejudge-key-00000001", "aaaaaaaaaaaaaaaaaaaaaaaaaa", RejudgeJobCommand{}, nil); !errors.Is(err, ErrJobUnavailable) {
+		t.Fatalf("repository without rejudge error = %v", err)
+	}
+}
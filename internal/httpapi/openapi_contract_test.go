@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -51,7 +52,7 @@ func TestOpenAPIScoreAndManifestBoundsMatchRuntimeContract(t *testing.T) {
 		property string
 		maximum  int64
 	}{
-		{"BundleMetadata", "manifestVersion", 2},
+		{"BundleMetadata", "manifestVersion", 3},
 		{"CaseResultView", "score", 1_000_000_000},
 		{"CaseResultView", "maxScore", 1_000_000_000},
 		{"JobResultView", "score", 1_000_000_000},
@@ -112,12 +113,25 @@ func stringEnumValues(t *testing.T, values []any) []string {
 func TestOpenAPIOperationsMatchLiveHTTPHandlers(t *testing.T) {
 	document := loadOpenAPIContract(t)
 	want := map[string]map[string][]int{
-		"/api/v1/capabilities":              {http.MethodGet: {200, 401, 403, 503}},
-		"/api/v1/bundles":                   {http.MethodPost: {200, 201, 400, 401, 403, 409, 413, 429, 503}},
-		"/api/v1/bundles/{bundleId}":        {http.MethodGet: {200, 401, 403, 404, 503}},
-		"/api/v1/judge-jobs":                {http.MethodGet: {200, 400, 401, 403, 500, 503}, http.MethodPost: {202, 400, 401, 403, 404, 408, 409, 415, 422, 429, 500, 503}},
-		"/api/v1/judge-jobs/{jobId}":        {http.MethodGet: {200, 401, 403, 404, 500, 503}},
-		"/api/v1/judge-jobs/{jobId}/cancel": {http.MethodPost: {200, 401, 403, 404, 500, 503}},
+		"/api/v1/capabilities":                           {http.MethodGet: {200, 401, 403, 503}},
+		"/api/v1/bundles":                                {http.MethodGet: {200, 400, 401, 403, 503}, http.MethodPost: {200, 201, 400, 401, 403, 409, 413, 429, 503}},
+		"/api/v1/bundles/{bundleId}":                     {http.MethodGet: {200, 401, 403, 404, 503}, http.MethodDelete: {204, 401, 403, 404, 409, 503}},
+		"/api/v1/bundles/{bundleId}/rejudge":             {http.MethodPost: {200, 400, 401, 403, 404, 408, 415, 500, 503}},
+		"/api/v1/judge-jobs":                             {http.MethodGet: {200, 400, 401, 403, 500, 503}, http.MethodPost: {202, 400, 401, 403, 404, 408, 409, 415, 422, 429, 500, 503}},
+		"/api/v1/judge-jobs:batch":                       {http.MethodPost: {200, 400, 401, 403, 408, 415, 500, 503}},
+		"/api/v1/judge-jobs/{jobId}":                     {http.MethodGet: {200, 401, 403, 404, 500, 503}},
+		"/api/v1/judge-jobs/{jobId}/cancel":              {http.MethodPost: {200, 401, 403, 404, 500, 503}},
+		"/api/v1/judge-jobs/{jobId}/rejudge":             {http.MethodPost: {202, 400, 401, 403, 404, 408, 409, 415, 422, 429, 500, 503}},
+		"/api/v1/judge-jobs/{jobId}/events":              {http.MethodGet: {200, 400, 401, 403, 404, 500, 503}},
+		"/api/v1/runs":                                   {http.MethodPost: {200, 400, 401, 403, 408, 415, 422, 429, 503}},
+		"/api/v1/callbacks":                              {http.MethodGet: {200, 400, 401, 403, 503}, http.MethodPost: {201, 400, 401, 403, 408, 409, 415, 422, 503}},
+		"/api/v1/callbacks/{callbackId}":                 {http.MethodDelete: {204, 401, 403, 404, 503}},
+		"/api/v1/callbacks/{callbackId}/rotate-secret":   {http.MethodPost: {200, 401, 403, 404, 409, 503}},
+		"/api/v1/callbacks/{callbackId}/ping":            {http.MethodPost: {200, 202, 401, 403, 404, 409, 503}},
+		"/api/v1/webhook-deliveries":                     {http.MethodGet: {200, 400, 401, 403, 503}},
+		"/api/v1/webhook-deliveries/{eventId}/redeliver": {http.MethodPost: {202, 401, 403, 404, 409, 503}},
+		"/api/v1/usage":                                  {http.MethodGet: {200, 400, 401, 403, 500, 503}},
+		"/.well-known/croj-webhook-keys":                 {http.MethodGet: {200}},
 	}
 
 	got := make(map[string]map[string][]int, document.Paths.Len())
@@ -144,7 +158,8 @@ func TestOpenAPIOperationsMatchLiveHTTPHandlers(t *testing.T) {
 	server := newOpenAPIProbeServer(t)
 	for path, operations := range want {
 		for method := range operations {
-			requestPath := strings.ReplaceAll(strings.ReplaceAll(path, "{bundleId}", "aaaaaaaaaaaaaaaaaaaaaaaaaa"), "{jobId}", "ceirceirceirceirceirceirce")
+			requestPath := strings.NewReplacer("{bundleId}", "aaaaaaaaaaaaaaaaaaaaaaaaaa", "{jobId}", "ceirceirceirceirceirceirce", "{callbackId}", "cbcbcbcbcbcbcbcbcbcbcbcbcb",
+				"{eventId}", "aaaaaaaaaaaaaaaaaaaaaaaaaa").Replace(path)
 			request := httptest.NewRequest(method, requestPath, nil)
 			request.Header.Set("Authorization", "Bearer dummy")
 			response := httptest.NewRecorder()
@@ -177,6 +192,16 @@ func TestOpenAPIContractCoversLiveHandlerResponses(t *testing.T) {
 	jobRequest := func(t *testing.T, service *jobServiceStub) (*Server, *http.Request) {
 		return jobRawRequest(t, service, `{"bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"x"}`, "submission-00000042")
 	}
+	runRequest := func(t *testing.T, service *runServiceStub, quota external.Quota) (*Server, *http.Request) {
+		if quota == nil {
+			quota = &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
+		}
+		server := newRunTestServer(t, service, quota, ScopeRunExecute)
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/runs", strings.NewReader(`{"language":"cpp","sourceCode":"x","stdin":"1 2\n"}`))
+		request.Header.Set("Authorization", "Bearer dummy")
+		request.Header.Set("Content-Type", "application/json")
+		return server, request
+	}
 	bundleRequest := func(t *testing.T, application *bundleApplicationStub, quota external.Quota) (*Server, *http.Request) {
 		if quota == nil {
 			quota = &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
@@ -317,6 +342,58 @@ func TestOpenAPIContractCoversLiveHandlerResponses(t *testing.T) {
 			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{err: fmt.Errorf("store unavailable")}, nil)
 			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
 		}, 503, []string{"X-Request-Id"}},
+		"bundle limit reached": {"/api/v1/bundles", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return bundleRequest(t, &bundleApplicationStub{err: external.ErrBundleRetentionLimit}, nil)
+		}, 409, []string{"X-Request-Id"}},
+		"bundle list success": {"/api/v1/bundles", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			page := external.BundleListResult{Items: []external.BundleSummary{{BundleMetadata: testBundleMetadata(), Status: external.BundleStatusReady}}, NextCursor: "next"}
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{page: page}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles?status=READY&limit=10", nil)
+		}, 200, []string{"X-Request-Id"}},
+		"bundle list invalid query": {"/api/v1/bundles", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles?createdAfter=yesterday", nil)
+		}, 400, []string{"X-Request-Id"}},
+		"bundle list forged cursor": {"/api/v1/bundles", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{err: external.ErrInvalidBundleListQuery}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles?cursor=forged", nil)
+		}, 400, []string{"X-Request-Id"}},
+		"bundle list unauthenticated": {"/api/v1/bundles", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{err: ErrUnauthenticated}, &bundleApplicationStub{}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles", nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"bundle list forbidden": {"/api/v1/bundles", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{ScopeBundleWrite: {}}}}, &bundleApplicationStub{}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"bundle list unavailable": {"/api/v1/bundles", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{err: fmt.Errorf("store unavailable")}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/bundles", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"bundle delete success": {"/api/v1/bundles/{bundleId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{}, nil)
+			return server, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
+		}, 204, []string{"X-Request-Id"}},
+		"bundle delete unauthenticated": {"/api/v1/bundles/{bundleId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{err: ErrUnauthenticated}, &bundleApplicationStub{}, nil)
+			return server, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"bundle delete forbidden": {"/api/v1/bundles/{bundleId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{ScopeBundleRead: {}}}}, &bundleApplicationStub{}, nil)
+			return server, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"bundle delete not found": {"/api/v1/bundles/{bundleId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{err: external.ErrBundleNotFound}, nil)
+			return server, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
+		}, 404, []string{"X-Request-Id"}},
+		"bundle delete in use": {"/api/v1/bundles/{bundleId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{err: external.ErrBundleInUse}, nil)
+			return server, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
+		}, 409, []string{"X-Request-Id"}},
+		"bundle delete unavailable": {"/api/v1/bundles/{bundleId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			server := newBundleServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &bundleApplicationStub{err: fmt.Errorf("store unavailable")}, nil)
+			return server, httptest.NewRequest(http.MethodDelete, "/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
 		"job accepted": {"/api/v1/judge-jobs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
 			return jobRequest(t, &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceirce", Status: JobQueued}})
 		}, 202, []string{"X-Request-Id", "Location"}},
@@ -379,10 +456,138 @@ func TestOpenAPIContractCoversLiveHandlerResponses(t *testing.T) {
 			request.Header.Set("Idempotency-Key", "submission-00000042")
 			return server, request
 		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"job batch accepted": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			service := &jobServiceStub{batchResults: []BatchSubmitResult{
+				{View: JobView{JobID: "ceirceirceirceirceirceirce", Status: JobQueued}},
+				{Err: ErrJobQuotaExceeded},
+			}}
+			return newJobTestServer(t, service, ScopeJobSubmit), batchRequest(`{"items":[
+				{"idempotencyKey":"batch-submission-42","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"x"},
+				{"idempotencyKey":"batch-submission-43","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"y"}]}`)
+		}, 200, []string{"X-Request-Id"}},
+		"job batch invalid": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), batchRequest(`{"items":[]}`)
+		}, 400, []string{"X-Request-Id"}},
+		"job batch invalid JSON": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), batchRequest(`{"items":`)
+		}, 400, []string{"X-Request-Id"}},
+		"job batch unsupported media type": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			request := batchRequest(`{"items":[]}`)
+			request.Header.Set("Content-Type", "text/plain")
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), request
+		}, 415, []string{"X-Request-Id"}},
+		"job batch forbidden": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobRead), batchRequest(`{"items":[]}`)
+		}, 403, []string{"X-Request-Id"}},
+		"job batch unauthenticated": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobServer(t, staticAuthenticator{err: ErrUnauthenticated}, &jobServiceStub{}, nil)
+			return server, httptest.NewRequest(http.MethodPost, JobBatchPath, nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"job batch internal": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: fmt.Errorf("database detail")}, ScopeJobSubmit),
+				batchRequest(`{"items":[{"idempotencyKey":"batch-submission-42","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"x"}]}`)
+		}, 500, []string{"X-Request-Id"}},
+		"job batch unavailable": {JobBatchPath, http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobUnavailable}, ScopeJobSubmit),
+				batchRequest(`{"items":[{"idempotencyKey":"batch-submission-42","bundleId":"ceirceirceirceirceirceircf","language":"cpp","sourceCode":"x"}]}`)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"job rejudge accepted": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			service := &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceircf", Status: JobQueued, RejudgeOf: "ceirceirceirceirceirceirce"}}
+			return newJobTestServer(t, service, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 202, []string{"X-Request-Id", "Location"}},
+		"job rejudge replay": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			service := &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceircf", Status: JobQueued, RejudgeOf: "ceirceirceirceirceirceirce"}, replayed: true}
+			return newJobTestServer(t, service, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 202, []string{"X-Request-Id", "Location", "Idempotent-Replay"}},
+		"job rejudge invalid idempotency": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			request := rejudgeRequest(jobRejudgePath, `{}`)
+			request.Header.Set("Idempotency-Key", "short")
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), request
+		}, 400, []string{"X-Request-Id"}},
+		"job rejudge invalid JSON": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{"sourceCode":"x"}`)
+		}, 400, []string{"X-Request-Id"}},
+		"job rejudge unsupported media type": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			request := rejudgeRequest(jobRejudgePath, `{}`)
+			request.Header.Set("Content-Type", "text/plain")
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), request
+		}, 415, []string{"X-Request-Id"}},
+		"job rejudge unauthenticated": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobServer(t, staticAuthenticator{err: ErrUnauthenticated}, &jobServiceStub{}, nil)
+			return server, httptest.NewRequest(http.MethodPost, jobRejudgePath, nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"job rejudge forbidden": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobRead), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 403, []string{"X-Request-Id"}},
+		"job rejudge not found": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobNotFound}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 404, []string{"X-Request-Id"}},
+		"job rejudge not rejudgeable": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobNotRejudgeable}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 409, []string{"X-Request-Id"}},
+		"job rejudge conflict": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrIdempotencyConflict}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 409, []string{"X-Request-Id"}},
+		"job rejudge invalid": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobInvalid}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{"replacementBundleId":"ceirceirceirceirceirceircg"}`)
+		}, 422, []string{"X-Request-Id"}},
+		"job rejudge quota exceeded": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: false, RetryAfter: time.Second}}
+			return newJobQuotaTestServer(t, &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceircf"}}, quota), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 429, []string{"X-Request-Id", "Retry-After"}},
+		"job rejudge internal": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: fmt.Errorf("database detail")}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 500, []string{"X-Request-Id"}},
+		"job rejudge unavailable": {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobUnavailable}, ScopeJobSubmit), rejudgeRequest(jobRejudgePath, `{}`)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"bundle rejudge page": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			service := &jobServiceStub{rejudgePage: BundleRejudgePage{NextCursor: "next-page", Items: []BundleRejudgeResult{
+				{RejudgeOf: "ceirceirceirceirceirceirce", BatchSubmitResult: BatchSubmitResult{View: JobView{JobID: "ceirceirceirceirceirceircf", Status: JobQueued, RejudgeOf: "ceirceirceirceirceirceirce"}}},
+				{RejudgeOf: "ceirceirceirceirceirceircg", BatchSubmitResult: BatchSubmitResult{Err: ErrJobNotRejudgeable}},
+			}}}
+			return newJobTestServer(t, service, ScopeJobSubmit), rejudgeRequest(bundleRejudgePath, `{"limit":2}`)
+		}, 200, []string{"X-Request-Id"}},
+		"bundle rejudge invalid limit": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), rejudgeRequest(bundleRejudgePath, `{"limit":101}`)
+		}, 400, []string{"X-Request-Id"}},
+		"bundle rejudge invalid idempotency": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			request := rejudgeRequest(bundleRejudgePath, `{}`)
+			request.Header.Set("Idempotency-Key", "short")
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), request
+		}, 400, []string{"X-Request-Id"}},
+		"bundle rejudge invalid JSON": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), rejudgeRequest(bundleRejudgePath, `{"limit":`)
+		}, 400, []string{"X-Request-Id"}},
+		"bundle rejudge unsupported media type": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			request := rejudgeRequest(bundleRejudgePath, `{}`)
+			request.Header.Set("Content-Type", "text/plain")
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobSubmit), request
+		}, 415, []string{"X-Request-Id"}},
+		"bundle rejudge unauthenticated": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobServer(t, staticAuthenticator{err: ErrUnauthenticated}, &jobServiceStub{}, nil)
+			return server, httptest.NewRequest(http.MethodPost, bundleRejudgePath, nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"bundle rejudge forbidden": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{}, ScopeJobRead), rejudgeRequest(bundleRejudgePath, `{}`)
+		}, 403, []string{"X-Request-Id"}},
+		"bundle rejudge not found": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobNotFound}, ScopeJobSubmit), rejudgeRequest(bundleRejudgePath, `{}`)
+		}, 404, []string{"X-Request-Id"}},
+		"bundle rejudge internal": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: fmt.Errorf("database detail")}, ScopeJobSubmit), rejudgeRequest(bundleRejudgePath, `{}`)
+		}, 500, []string{"X-Request-Id"}},
+		"bundle rejudge unavailable": {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newJobTestServer(t, &jobServiceStub{err: ErrJobUnavailable}, ScopeJobSubmit), rejudgeRequest(bundleRejudgePath, `{}`)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
 		"invalid list": {"/api/v1/judge-jobs", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
 			server := newJobTestServer(t, &jobServiceStub{}, ScopeJobRead)
 			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs?limit=0", nil)
 		}, 400, []string{"X-Request-Id"}},
+		"invalid list cursor": {"/api/v1/judge-jobs", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &jobServiceStub{err: ErrJobInvalid}, nil)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs?cursor=tampered&clientReference=submission-42", nil)
+		}, 400, []string{"X-Request-Id"}},
 		"job list success": {"/api/v1/judge-jobs", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
 			server := newJobServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &jobServiceStub{listPage: JobListPage{Items: []JobView{}}}, nil)
 			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs", nil)
@@ -423,10 +628,263 @@ func TestOpenAPIContractCoversLiveHandlerResponses(t *testing.T) {
 			server := newJobServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &jobServiceStub{err: ErrJobUnavailable}, nil)
 			return server, httptest.NewRequest(http.MethodPost, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/cancel", nil)
 		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"job events invalid last event id": {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobEventTestServer(t, &jobEventSourceStub{}, ScopeJobRead)
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+			request.Header.Set("Last-Event-ID", "latest")
+			return server, request
+		}, 400, []string{"X-Request-Id"}},
+		"job events forbidden": {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobEventTestServer(t, &jobEventSourceStub{}, ScopeJobSubmit)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"job events not found": {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobEventTestServer(t, &jobEventSourceStub{err: ErrJobNotFound}, ScopeJobRead)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+		}, 404, []string{"X-Request-Id"}},
+		"job events unavailable": {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobEventTestServer(t, &jobEventSourceStub{err: ErrJobUnavailable}, ScopeJobRead)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"job events capacity": {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobEventTestServer(t, &jobEventSourceStub{}, ScopeJobRead)
+			server.jobEventStreams <- struct{}{}
+			server.jobEventStreams <- struct{}{}
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"job events internal": {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server := newJobEventTestServer(t, &jobEventSourceStub{err: fmt.Errorf("database detail")}, ScopeJobRead)
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/events", nil)
+		}, 500, []string{"X-Request-Id"}},
 		"job cancel internal": {"/api/v1/judge-jobs/{jobId}/cancel", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
 			server := newJobServer(t, staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: allScopes}}, &jobServiceStub{err: fmt.Errorf("database detail")}, nil)
 			return server, httptest.NewRequest(http.MethodPost, "/api/v1/judge-jobs/ceirceirceirceirceirceirce/cancel", nil)
 		}, 500, []string{"X-Request-Id"}},
+		"run success": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{view: RunView{
+				RunID: "ceirceirceirceirceirceirce", Verdict: "WRONG_ANSWER", TimeMillis: 3, MemoryBytes: 4096,
+				Stdout: "4", Limits: RunLimitsView{TimeLimitMillis: 1000, MemoryLimitMiB: 64},
+			}}, nil)
+		}, 200, []string{"X-Request-Id"}},
+		"run compile error": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{view: RunView{
+				RunID: "ceirceirceirceirceirceirce", Verdict: "COMPILE_ERROR", CompileDiagnostics: "main.cpp:1: expected ';'",
+				Limits: RunLimitsView{TimeLimitMillis: 1000, MemoryLimitMiB: 64},
+			}}, nil)
+		}, 200, []string{"X-Request-Id"}},
+		"run invalid JSON": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server, request := runRequest(t, &runServiceStub{}, nil)
+			request.Body = io.NopCloser(strings.NewReader(`{"language":`))
+			return server, request
+		}, 400, []string{"X-Request-Id"}},
+		"run unauthenticated": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server, err := NewServer(staticAuthenticator{err: ErrUnauthenticated}, testCapabilities(), WithRunService(&runServiceStub{}),
+				WithRunQuota(&writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return server, httptest.NewRequest(http.MethodPost, "/api/v1/runs", nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"run forbidden": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server := newRunTestServer(t, &runServiceStub{}, &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}, ScopeJobSubmit)
+			return server, httptest.NewRequest(http.MethodPost, "/api/v1/runs", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"run unsupported media type": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server, request := runRequest(t, &runServiceStub{}, nil)
+			request.Header.Set("Content-Type", "text/plain")
+			return server, request
+		}, 415, []string{"X-Request-Id"}},
+		"run invalid": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{err: ErrRunInvalid}, nil)
+		}, 422, []string{"X-Request-Id"}},
+		"run quota exceeded": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{}, &writeQuotaStub{decision: external.QuotaDecision{Allowed: false, RetryAfter: time.Second}})
+		}, 429, []string{"X-Request-Id", "Retry-After"}},
+		"run concurrency exceeded": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{err: ErrRunCapacityExceeded}, nil)
+		}, 429, []string{"X-Request-Id", "Retry-After"}},
+		"run daily budget exhausted": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{err: ErrRunDailyLimitExceeded}, nil)
+		}, 429, []string{"X-Request-Id", "Retry-After"}},
+		"run quota unavailable": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{}, &writeQuotaStub{err: external.ErrQuotaUnavailable})
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"run capacity exhausted": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server, request := runRequest(t, &runServiceStub{}, nil)
+			for range cap(server.runSlots) {
+				server.runSlots <- struct{}{}
+			}
+			return server, request
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"run unavailable": {"/api/v1/runs", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return runRequest(t, &runServiceStub{err: ErrRunUnavailable}, nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"callback list": {"/api/v1/callbacks", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{callbacks: []external.CallbackSummary{testCallbackSummary()}}),
+				httptest.NewRequest(http.MethodGet, "/api/v1/callbacks", nil)
+		}, 200, []string{"X-Request-Id"}},
+		"callback list query": {"/api/v1/callbacks", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{}), httptest.NewRequest(http.MethodGet, "/api/v1/callbacks?limit=1", nil)
+		}, 400, []string{"X-Request-Id"}},
+		"callback list forbidden": {"/api/v1/callbacks", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeJobSubmit, &callbackApplicationStub{}), httptest.NewRequest(http.MethodGet, "/api/v1/callbacks", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"callback list unauthenticated": {"/api/v1/callbacks", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server, err := NewServer(staticAuthenticator{err: ErrUnauthenticated}, testCapabilities(), WithCallbackApplication(&callbackApplicationStub{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return server, httptest.NewRequest(http.MethodGet, "/api/v1/callbacks", nil)
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"callback list unavailable": {"/api/v1/callbacks", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{err: errors.New("database is down")}),
+				httptest.NewRequest(http.MethodGet, "/api/v1/callbacks", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"callback created": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return callbackCreateRequest(t, &callbackApplicationStub{material: testCallbackMaterial()})
+		}, 201, []string{"X-Request-Id", "Location"}},
+		"callback invalid JSON": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server, request := callbackCreateRequest(t, &callbackApplicationStub{})
+			request.Body = io.NopCloser(strings.NewReader(`{"url":1}`))
+			return server, request
+		}, 400, []string{"X-Request-Id"}},
+		"callback unsupported media type": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			server, request := callbackCreateRequest(t, &callbackApplicationStub{})
+			request.Header.Set("Content-Type", "text/plain")
+			return server, request
+		}, 415, []string{"X-Request-Id"}},
+		"callback invalid destination": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return callbackCreateRequest(t, &callbackApplicationStub{err: external.ErrInvalidCallbackDestination})
+		}, 422, []string{"X-Request-Id"}},
+		"callback unsafe destination": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return callbackCreateRequest(t, &callbackApplicationStub{err: external.ErrUnsafeCallbackDestination})
+		}, 422, []string{"X-Request-Id"}},
+		"callback limit reached": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return callbackCreateRequest(t, &callbackApplicationStub{err: external.ErrCallbackLimitReached})
+		}, 409, []string{"X-Request-Id"}},
+		"callback create unavailable": {"/api/v1/callbacks", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return callbackCreateRequest(t, &callbackApplicationStub{err: errors.New("database is down")})
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"callback disabled": {"/api/v1/callbacks/{callbackId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{}),
+				httptest.NewRequest(http.MethodDelete, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb", nil)
+		}, 204, []string{"X-Request-Id"}},
+		"callback disable not found": {"/api/v1/callbacks/{callbackId}", http.MethodDelete, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{err: external.ErrCallbackNotFound}),
+				httptest.NewRequest(http.MethodDelete, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb", nil)
+		}, 404, []string{"X-Request-Id"}},
+		"callback secret rotated": {"/api/v1/callbacks/{callbackId}/rotate-secret", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{material: testCallbackMaterial()}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", nil)
+		}, 200, []string{"X-Request-Id"}},
+		"callback rotation conflict": {"/api/v1/callbacks/{callbackId}/rotate-secret", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{err: external.ErrCallbackChanged}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", nil)
+		}, 409, []string{"X-Request-Id"}},
+		"callback rotation forbidden": {"/api/v1/callbacks/{callbackId}/rotate-secret", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackTestServer(t, ScopeJobSubmit, &callbackApplicationStub{}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/rotate-secret", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"callback ping delivered": {"/api/v1/callbacks/{callbackId}/ping", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackPingTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{ping: testWebhookPing(external.WebhookDeliveryDelivered)}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil)
+		}, 200, []string{"X-Request-Id"}},
+		"callback ping pending": {"/api/v1/callbacks/{callbackId}/ping", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackPingTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{ping: testWebhookPing(external.WebhookDeliveryPending)}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil)
+		}, 202, []string{"X-Request-Id"}},
+		"callback ping in progress": {"/api/v1/callbacks/{callbackId}/ping", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackPingTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrWebhookPingInProgress}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil)
+		}, 409, []string{"X-Request-Id"}},
+		"callback ping not found": {"/api/v1/callbacks/{callbackId}/ping", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackPingTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrCallbackNotFound}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil)
+		}, 404, []string{"X-Request-Id"}},
+		"callback ping unavailable": {"/api/v1/callbacks/{callbackId}/ping", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newCallbackPingTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: errors.New("database is down")}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"webhook deliveries listed": {"/api/v1/webhook-deliveries", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			page := external.WebhookDeliveryListResult{
+				Items:      []external.WebhookDeliverySummary{testWebhookDeliverySummary(external.WebhookDeliveryDead), testWebhookDeliverySummary(external.WebhookDeliveryPending)},
+				NextCursor: "opaque-next-page",
+			}
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{page: page}),
+				httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries?status=DEAD", nil)
+		}, 200, []string{"X-Request-Id"}},
+		"webhook delivery list invalid": {"/api/v1/webhook-deliveries", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{}),
+				httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries?status=FAILED", nil)
+		}, 400, []string{"X-Request-Id"}},
+		"webhook delivery list forged cursor": {"/api/v1/webhook-deliveries", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrInvalidWebhookDeliveryCursor}),
+				httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries?cursor=forged", nil)
+		}, 400, []string{"X-Request-Id"}},
+		"webhook delivery list unavailable": {"/api/v1/webhook-deliveries", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: errors.New("database is down")}),
+				httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries", nil)
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"webhook redelivered": {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{delivery: testWebhookDeliverySummary(external.WebhookDeliveryPending)}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil)
+		}, 202, []string{"X-Request-Id"}},
+		"webhook redelivery not dead": {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrWebhookDeliveryNotDead}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil)
+		}, 409, []string{"X-Request-Id"}},
+		"webhook redelivery superseded": {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrWebhookDeliverySuperseded}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil)
+		}, 409, []string{"X-Request-Id"}},
+		"webhook redelivery callback disabled": {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrWebhookCallbackDisabled}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil)
+		}, 409, []string{"X-Request-Id"}},
+		"webhook redelivery not found": {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrWebhookDeliveryNotFound}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil)
+		}, 404, []string{"X-Request-Id"}},
+		"webhook redelivery forbidden": {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, func(t *testing.T) (*Server, *http.Request) {
+			return newWebhookDeliveryTestServer(t, ScopeJobRead, &webhookDeliveryApplicationStub{}),
+				httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil)
+		}, 403, []string{"X-Request-Id"}},
+		"usage reported": {UsagePath, http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			usage := external.TenantUsage{
+				Execution: external.ExecutionUsage{Day: "2026-07-19", ReservedMillis: 1000, ConsumedMillis: 2500, RemainingMillis: 6500, LimitMillis: 10000},
+				Jobs:      external.JobUsage{Queued: 3, MaxQueued: 10, Running: 1, MaxRunning: 2},
+				Bundles:   external.BundleUsage{Retained: 4, MaxRetained: 100},
+				History:   []external.ExecutionUsageDay{{Day: "2026-07-19", ReservedMillis: 1000, ConsumedMillis: 2500}},
+			}
+			quota := &inspectableQuotaStub{levels: map[external.QuotaKind]int64{external.QuotaJudgeSubmit: 12}}
+			return newUsageTestServer(t, &usageApplicationStub{usage: usage}, quota, ScopeJobRead), usageRequest("?days=1")
+		}, 200, []string{"X-Request-Id", "Cache-Control"}},
+		"usage invalid query": {UsagePath, http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newUsageTestServer(t, &usageApplicationStub{}, &writeQuotaStub{}, ScopeJobRead), usageRequest("?days=91")
+		}, 400, []string{"X-Request-Id"}},
+		"usage unauthenticated": {UsagePath, http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server, err := NewServer(staticAuthenticator{err: ErrUnauthenticated}, testCapabilities(), WithUsageApplication(&usageApplicationStub{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return server, usageRequest("")
+		}, 401, []string{"X-Request-Id", "WWW-Authenticate"}},
+		"usage forbidden": {UsagePath, http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newUsageTestServer(t, &usageApplicationStub{}, &writeQuotaStub{}, ScopeJobSubmit), usageRequest("")
+		}, 403, []string{"X-Request-Id"}},
+		"usage internal error": {UsagePath, http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newUsageTestServer(t, &usageApplicationStub{err: errors.New("unexpected")}, &writeQuotaStub{}, ScopeJobRead), usageRequest("")
+		}, 500, []string{"X-Request-Id"}},
+		"usage unavailable": {UsagePath, http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			return newUsageTestServer(t, &usageApplicationStub{err: external.ErrExternalJobUnavailable}, &writeQuotaStub{}, ScopeJobRead), usageRequest("")
+		}, 503, []string{"X-Request-Id", "Retry-After"}},
+		"webhook signing keys": {"/.well-known/croj-webhook-keys", http.MethodGet, func(t *testing.T) (*Server, *http.Request) {
+			server, err := NewServer(staticAuthenticator{err: ErrUnauthenticated}, testCapabilities(), WithWebhookSigningKeys(testWebhookSigningKeys(t)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return server, httptest.NewRequest(http.MethodGet, "/.well-known/croj-webhook-keys", nil)
+		}, 200, []string{"X-Request-Id"}},
 	}
 
 	for name, test := range cases {
@@ -466,7 +924,9 @@ func TestOpenAPIContractCoversLiveHandlerResponses(t *testing.T) {
 				if err := media.Schema.Value.VisitJSON(body); err != nil {
 					t.Errorf("live response body violates OpenAPI schema: %v; body=%s", err, response.Body.String())
 				}
-				assertSafePublicExample(t, "live response body", body)
+				if !revealsCallbackSecret(test.path, test.method, response.Code) {
+					assertSafePublicExample(t, "live response body", body)
+				}
 			}
 			if response.Code >= 400 {
 				var actual Problem
@@ -490,6 +950,51 @@ func TestOpenAPIContractCoversLiveHandlerResponses(t *testing.T) {
 	}
 }
 
+func TestOpenAPIJobEventSchemaMatchesLiveStreamData(t *testing.T) {
+	document := loadOpenAPIContract(t)
+	schema := document.Components.Schemas["JobEvent"]
+	if schema == nil || schema.Value == nil {
+		t.Fatal("components.schemas.JobEvent is missing")
+	}
+	now := time.Date(2026, 7, 19, 8, 0, 0, 0, time.UTC)
+	score, maximum := 0, 10
+	source := &jobEventSourceStub{pages: []JobEventPage{{Status: JobFailed, Events: []JobEventView{
+		{JobID: "ceirceirceirceirceirceirce", Sequence: 1, Type: "status", Status: JobQueued, OccurredAt: now},
+		{JobID: "ceirceirceirceirceirceirce", Sequence: 2, Type: "compile", AttemptNo: 1, CompileStatus: "SUCCEEDED", OccurredAt: now},
+		{JobID: "ceirceirceirceirceirceirce", Sequence: 3, Type: "case", AttemptNo: 1, Case: &CaseResultView{CaseID: "case-01", Verdict: "WRONG_ANSWER", Score: &score, MaxScore: &maximum}, OccurredAt: now},
+		{JobID: "ceirceirceirceirceirceirce", Sequence: 4, Type: "status", AttemptNo: 1, Status: JobFailed, FailureCode: "SANDBOX_UNAVAILABLE", OccurredAt: now},
+	}}}}
+	server := newJobEventTestServer(t, source, ScopeJobRead)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, jobEventRequest())
+	documented := operation(t, document, "/api/v1/judge-jobs/{jobId}/events", http.MethodGet).Responses.Status(http.StatusOK)
+	if response.Code != http.StatusOK || documented == nil || documented.Value.Content["text/event-stream"] == nil {
+		t.Fatalf("status=%d documented=%#v", response.Code, documented)
+	}
+	for _, finding := range liveResponseHeaderFindings(response.Header(), documented.Value) {
+		t.Error(finding)
+	}
+	frames := 0
+	for _, line := range strings.Split(response.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		frames++
+		var body any
+		if err := json.Unmarshal([]byte(data), &body); err != nil {
+			t.Fatalf("decode event data: %v", err)
+		}
+		if err := schema.Value.VisitJSON(body); err != nil {
+			t.Errorf("event data violates JobEvent: %v; data=%s", err, data)
+		}
+		assertSafePublicExample(t, "live event data", body)
+	}
+	if frames != 4 {
+		t.Fatalf("frames=%d body=%s", frames, response.Body.String())
+	}
+}
+
 func TestLiveResponseHeaderValidationRejectsSensitiveValues(t *testing.T) {
 	document := loadOpenAPIContract(t)
 	documented := operation(t, document, "/api/v1/capabilities", http.MethodGet).Responses.Status(http.StatusUnauthorized)
@@ -536,6 +1041,9 @@ func TestOpenAPIContractPinsSecurityHeadersAndAsynchronousSemantics(t *testing.T
 	if len(document.Security) != 1 || len(document.Security[0]) != 1 {
 		t.Fatalf("global security = %#v, want one BearerAuth requirement", document.Security)
 	}
+	if keys := operation(t, document, "/.well-known/croj-webhook-keys", http.MethodGet); keys.Security == nil || len(*keys.Security) != 0 {
+		t.Fatalf("webhook key set security = %#v, want an explicit empty requirement", keys.Security)
+	}
 
 	assertParameter := func(t *testing.T, operation *openapi3.Operation, name string, required bool) {
 		t.Helper()
@@ -559,6 +1067,7 @@ func TestOpenAPIContractPinsSecurityHeadersAndAsynchronousSemantics(t *testing.T
 		"/api/v1/judge-jobs":                http.MethodGet,
 		"/api/v1/judge-jobs/{jobId}":        http.MethodGet,
 		"/api/v1/judge-jobs/{jobId}/cancel": http.MethodPost,
+		"/api/v1/judge-jobs/{jobId}/events": http.MethodGet,
 	} {
 		for _, responseRef := range operation(t, document, path, method).Responses.Map() {
 			if responseRef.Value == nil || responseRef.Value.Headers["X-Request-Id"] == nil {
@@ -582,10 +1091,13 @@ func TestOpenAPIContractPinsSecurityHeadersAndAsynchronousSemantics(t *testing.T
 			t.Errorf("job submit %d response misses Retry-After", status)
 		}
 	}
-	for path, method := range map[string]string{
-		"/api/v1/bundles":            http.MethodPost,
-		"/api/v1/bundles/{bundleId}": http.MethodGet,
+	for _, route := range []struct{ path, method string }{
+		{"/api/v1/bundles", http.MethodGet},
+		{"/api/v1/bundles", http.MethodPost},
+		{"/api/v1/bundles/{bundleId}", http.MethodGet},
+		{"/api/v1/bundles/{bundleId}", http.MethodDelete},
 	} {
+		path, method := route.path, route.method
 		for status, response := range operation(t, document, path, method).Responses.Map() {
 			if response.Value == nil {
 				continue
@@ -686,7 +1198,9 @@ func TestOpenAPIPublicSchemasAreClosedAndDoNotExposeSensitiveFields(t *testing.T
 			}
 			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
 				for contentType, media := range operation.RequestBody.Value.Content {
-					allowSource := method == http.MethodPost && path == "/api/v1/judge-jobs"
+					// Submit requests carry caller-owned source; custom runs also
+					// carry caller-owned stdin and expected output. Responses never do.
+					allowSource := method == http.MethodPost && (path == "/api/v1/judge-jobs" || path == JobBatchPath || path == "/api/v1/runs")
 					audit(prefix+" request "+contentType, media.Schema, !allowSource)
 					auditPublicValue(t, prefix+" request "+contentType+".example", media.Example, !allowSource)
 					for name, exampleRef := range media.Examples {
@@ -711,13 +1225,18 @@ func TestOpenAPIPublicSchemasAreClosedAndDoNotExposeSensitiveFields(t *testing.T
 						}
 					}
 				}
+				var code int
+				_, _ = fmt.Sscanf(status, "%d", &code)
+				// Callback registration and rotation show the new signing
+				// secret exactly once; no other response may carry it.
+				sensitive := !revealsCallbackSecret(path, method, code)
 				for contentType, media := range responseRef.Value.Content {
 					location := prefix + " " + status + " " + contentType
-					audit(location, media.Schema, true)
-					auditPublicValue(t, location+".example", media.Example, true)
+					audit(location, media.Schema, sensitive)
+					auditPublicValue(t, location+".example", media.Example, sensitive)
 					for name, exampleRef := range media.Examples {
 						if exampleRef != nil && exampleRef.Value != nil {
-							auditPublicValue(t, location+".examples."+name, exampleRef.Value.Value, true)
+							auditPublicValue(t, location+".examples."+name, exampleRef.Value.Value, sensitive)
 						}
 					}
 				}
@@ -742,18 +1261,54 @@ func TestSchemaAuditTraversesOpenAPI31CompositionAndImplicitObjects(t *testing.T
 	}
 }
 
-func TestOpenAPISourceCodeExistsOnlyInSubmitRequest(t *testing.T) {
+func TestOpenAPISourceCodeExistsOnlyInSubmitAndRunRequests(t *testing.T) {
 	document := loadOpenAPIContract(t)
 	var locations []string
 	for name, reference := range document.Components.Schemas {
 		collectPropertyLocations("#/components/schemas/"+name, "sourceCode", reference, map[*openapi3.Schema]bool{}, &locations)
 	}
-	want := []string{"#/components/schemas/SubmitJobRequest.sourceCode"}
+	sort.Strings(locations)
+	want := []string{
+		"#/components/schemas/RunRequest.sourceCode",
+		"#/components/schemas/SubmitJobBatchItem.sourceCode",
+		"#/components/schemas/SubmitJobBatchRequest.items[].sourceCode",
+		"#/components/schemas/SubmitJobRequest.sourceCode",
+	}
 	if !reflect.DeepEqual(locations, want) {
 		t.Fatalf("sourceCode property locations = %v, want %v", locations, want)
 	}
 }
 
+func TestOpenAPICallbackSecretExistsOnlyInOneTimeSecretResponses(t *testing.T) {
+	document := loadOpenAPIContract(t)
+	var locations []string
+	for name, reference := range document.Components.Schemas {
+		collectPropertyLocations("#/components/schemas/"+name, "secret", reference, map[*openapi3.Schema]bool{}, &locations)
+	}
+	if want := []string{"#/components/schemas/CallbackSecret.secret"}; !reflect.DeepEqual(locations, want) {
+		t.Fatalf("secret property locations = %v, want %v", locations, want)
+	}
+	secretSchema := document.Components.Schemas["CallbackSecret"]
+	for _, path := range document.Paths.Keys() {
+		for method, operation := range document.Paths.Value(path).Operations() {
+			for status, responseRef := range operation.Responses.Map() {
+				var code int
+				_, _ = fmt.Sscanf(status, "%d", &code)
+				media := responseRef.Value.Content["application/json"]
+				references := media != nil && media.Schema != nil && media.Schema.Value == secretSchema.Value
+				if references != revealsCallbackSecret(path, method, code) {
+					t.Errorf("%s %s %s references CallbackSecret = %t", method, path, status, references)
+				}
+			}
+		}
+	}
+}
+
+func revealsCallbackSecret(path, method string, status int) bool {
+	return method == http.MethodPost && ((path == "/api/v1/callbacks" && status == http.StatusCreated) ||
+		(path == "/api/v1/callbacks/{callbackId}/rotate-secret" && status == http.StatusOK))
+}
+
 func TestSensitiveExampleValueMarkerRejectsPayloadsButAllowsCapabilityNames(t *testing.T) {
 	for name, value := range map[string]string{
 		"source":  "sourceCode=int main(){}",
@@ -787,12 +1342,30 @@ func TestOpenAPIProblemExamplesMatchHandlerProblemTypes(t *testing.T) {
 		"invalid bundle":           {"/api/v1/bundles", http.MethodPost, 400, "invalid-bundle"},
 		"bundle unavailable":       {"/api/v1/bundles", http.MethodPost, 503, "bundle-unavailable"},
 		"bundle not found":         {"/api/v1/bundles/{bundleId}", http.MethodGet, 404, "not-found"},
+		"bundle limit reached":     {"/api/v1/bundles", http.MethodPost, 409, "bundle-limit-reached"},
+		"invalid bundle list":      {"/api/v1/bundles", http.MethodGet, 400, "invalid-list-query"},
+		"bundle delete in use":     {"/api/v1/bundles/{bundleId}", http.MethodDelete, 409, "bundle-in-use"},
 		"invalid job JSON":         {"/api/v1/judge-jobs", http.MethodPost, 400, "invalid-json"},
 		"job unsupported media":    {"/api/v1/judge-jobs", http.MethodPost, 415, "unsupported-media-type"},
 		"job not found":            {"/api/v1/judge-jobs", http.MethodPost, 404, "job-not-found"},
 		"invalid list query":       {"/api/v1/judge-jobs", http.MethodGet, 400, "invalid-list-query"},
 		"job get not found":        {"/api/v1/judge-jobs/{jobId}", http.MethodGet, 404, "job-not-found"},
 		"job cancel not found":     {"/api/v1/judge-jobs/{jobId}/cancel", http.MethodPost, 404, "job-not-found"},
+		"job events not found":     {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, 404, "job-not-found"},
+		"job not rejudgeable":      {"/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, 409, "job-not-rejudgeable"},
+		"invalid bundle rejudge":   {"/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, 400, "invalid-rejudge"},
+		"invalid last event id":    {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, 400, "invalid-last-event-id"},
+		"event stream capacity":    {"/api/v1/judge-jobs/{jobId}/events", http.MethodGet, 503, "event-stream-capacity-exhausted"},
+		"callback limit reached":   {"/api/v1/callbacks", http.MethodPost, 409, "callback-limit-reached"},
+		"unsafe callback":          {"/api/v1/callbacks", http.MethodPost, 422, "unsafe-callback-destination"},
+		"callback changed":         {"/api/v1/callbacks/{callbackId}/rotate-secret", http.MethodPost, 409, "callback-changed"},
+		"callback unavailable":     {"/api/v1/callbacks/{callbackId}", http.MethodDelete, 503, "callback-unavailable"},
+		"invalid delivery list":    {"/api/v1/webhook-deliveries", http.MethodGet, 400, "invalid-list-query"},
+		"delivery not dead":        {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, 409, "webhook-delivery-not-dead"},
+		"delivery callback gone":   {"/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, 409, "callback-disabled"},
+		"delivery unavailable":     {"/api/v1/webhook-deliveries", http.MethodGet, 503, "webhook-delivery-unavailable"},
+		"ping in progress":         {"/api/v1/callbacks/{callbackId}/ping", http.MethodPost, 409, "webhook-ping-in-progress"},
+		"invalid usage query":      {UsagePath, http.MethodGet, 400, "invalid-usage-query"},
 	} {
 		t.Run(name, func(t *testing.T) {
 			want := "https://coderushoj.dev/problems/" + test.problemType
@@ -816,15 +1389,18 @@ func TestOpenAPIConflictExamplesMatchLiveHandlerDetails(t *testing.T) {
 	for name, test := range map[string]struct {
 		path, detail string
 	}{
-		"bundle": {"/api/v1/bundles", "The Idempotency-Key was already used for different content."},
-		"job":    {"/api/v1/judge-jobs", "The Idempotency-Key is already bound to a different request."},
+		"bundle":       {"/api/v1/bundles", "The Idempotency-Key was already used for different content."},
+		"bundle limit": {"/api/v1/bundles", "Delete unused bundles before uploading new content."},
+		"job":          {"/api/v1/judge-jobs", "The Idempotency-Key is already bound to a different request."},
 	} {
 		t.Run(name, func(t *testing.T) {
-			example := responseExample(t, document, test.path, http.MethodPost, 409)
-			object, ok := example.(map[string]any)
-			if !ok || object["detail"] != test.detail {
-				t.Fatalf("conflict example = %#v, want detail %q", example, test.detail)
+			examples := responseExamples(t, document, test.path, http.MethodPost, 409)
+			for _, example := range examples {
+				if object, ok := example.(map[string]any); ok && object["detail"] == test.detail {
+					return
+				}
 			}
+			t.Fatalf("conflict examples = %#v, want detail %q", examples, test.detail)
 		})
 	}
 }
@@ -840,11 +1416,28 @@ func TestOpenAPIJSONExamplesDecodeIntoRealDTOs(t *testing.T) {
 		{"bundle replay response", responseExample(t, document, "/api/v1/bundles", http.MethodPost, 200), &external.BundleMetadata{}},
 		{"bundle created response", responseExample(t, document, "/api/v1/bundles", http.MethodPost, 201), &external.BundleMetadata{}},
 		{"bundle metadata response", responseExample(t, document, "/api/v1/bundles/{bundleId}", http.MethodGet, 200), &external.BundleMetadata{}},
+		{"bundle list response", responseExample(t, document, "/api/v1/bundles", http.MethodGet, 200), &external.BundleListResult{}},
 		{"job submit request", requestExample(t, document, "/api/v1/judge-jobs", http.MethodPost), &SubmitJobCommand{}},
 		{"job submit response", responseExample(t, document, "/api/v1/judge-jobs", http.MethodPost, 202), &JobView{}},
+		{"job batch request", requestExample(t, document, JobBatchPath, http.MethodPost), &BatchSubmitJobsCommand{}},
+		{"job batch response", responseExample(t, document, JobBatchPath, http.MethodPost, 200), &BatchSubmitJobsView{}},
 		{"job list response", responseExample(t, document, "/api/v1/judge-jobs", http.MethodGet, 200), &JobListPage{}},
 		{"job detail response", responseExample(t, document, "/api/v1/judge-jobs/{jobId}", http.MethodGet, 200), &JobView{}},
 		{"job cancel response", responseExample(t, document, "/api/v1/judge-jobs/{jobId}/cancel", http.MethodPost, 200), &JobView{}},
+		{"job rejudge request", requestExample(t, document, "/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost), &RejudgeJobCommand{}},
+		{"job rejudge response", responseExample(t, document, "/api/v1/judge-jobs/{jobId}/rejudge", http.MethodPost, 202), &JobView{}},
+		{"bundle rejudge request", requestExample(t, document, "/api/v1/bundles/{bundleId}/rejudge", http.MethodPost), &RejudgeBundleCommand{}},
+		{"bundle rejudge response", responseExample(t, document, "/api/v1/bundles/{bundleId}/rejudge", http.MethodPost, 200), &BundleRejudgeView{}},
+		{"callback list response", responseExample(t, document, "/api/v1/callbacks", http.MethodGet, 200), &CallbackListView{}},
+		{"callback create request", requestExample(t, document, "/api/v1/callbacks", http.MethodPost), &CreateCallbackCommand{}},
+		{"callback create response", responseExample(t, document, "/api/v1/callbacks", http.MethodPost, 201), &CallbackSecretView{}},
+		{"callback rotate response", responseExample(t, document, "/api/v1/callbacks/{callbackId}/rotate-secret", http.MethodPost, 200), &CallbackSecretView{}},
+		{"webhook delivery list response", responseExample(t, document, "/api/v1/webhook-deliveries", http.MethodGet, 200), &external.WebhookDeliveryListResult{}},
+		{"webhook redelivery response", responseExample(t, document, "/api/v1/webhook-deliveries/{eventId}/redeliver", http.MethodPost, 202), &external.WebhookDeliverySummary{}},
+		{"callback ping response", responseExample(t, document, "/api/v1/callbacks/{callbackId}/ping", http.MethodPost, 200), &external.WebhookPing{}},
+		{"callback ping pending response", responseExample(t, document, "/api/v1/callbacks/{callbackId}/ping", http.MethodPost, 202), &external.WebhookPing{}},
+		{"usage response", responseExample(t, document, UsagePath, http.MethodGet, 200), &UsageView{}},
+		{"webhook signing keys response", responseExample(t, document, "/.well-known/croj-webhook-keys", http.MethodGet, 200), &external.WebhookPublicKeySet{}},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1283,7 +1876,7 @@ func newOpenAPIProbeServer(t *testing.T) *Server {
 	t.Helper()
 	scopes := map[Scope]struct{}{
 		ScopeCapabilitiesRead: {}, ScopeBundleWrite: {}, ScopeBundleRead: {},
-		ScopeJobSubmit: {}, ScopeJobRead: {}, ScopeJobCancel: {},
+		ScopeJobSubmit: {}, ScopeJobRead: {}, ScopeJobCancel: {}, ScopeRunExecute: {}, ScopeCallbackWrite: {},
 	}
 	capabilities := testCapabilities()
 	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
@@ -1294,6 +1887,13 @@ func newOpenAPIProbeServer(t *testing.T) *Server {
 		WithBundleWriteQuota(quota, external.QuotaLimit{Capacity: capabilities.Limits.MaxBundleBytes, RefillPeriod: time.Minute}),
 		WithJobService(&jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceirce", Status: JobQueued}, listPage: JobListPage{Items: []JobView{}}}),
 		WithJobWriteQuota(quota, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}),
+		WithJobEventStream(&jobEventSourceStub{pages: []JobEventPage{{Status: JobSucceeded}}}, 1),
+		WithRunService(&runServiceStub{view: RunView{RunID: "ceirceirceirceirceirceirce", Verdict: "ACCEPTED", Limits: RunLimitsView{TimeLimitMillis: 1000, MemoryLimitMiB: 64}}}),
+		WithRunQuota(quota, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}),
+		WithCallbackApplication(&callbackApplicationStub{material: testCallbackMaterial()}),
+		WithWebhookDeliveryApplication(&webhookDeliveryApplicationStub{delivery: testWebhookDeliverySummary(external.WebhookDeliveryPending)}),
+		WithWebhookSigningKeys(testWebhookSigningKeys(t)),
+		WithUsageApplication(&usageApplicationStub{}),
 	)
 	if err != nil {
 		t.Fatal(err)
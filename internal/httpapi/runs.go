@@ -0,0 +1,238 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/judgecontract"
+)
+
+var (
+	ErrRunInvalid            = errors.New("custom run request is invalid")
+	ErrRunCapacityExceeded   = errors.New("custom run concurrency is exceeded")
+	ErrRunDailyLimitExceeded = errors.New("daily execution budget is exhausted")
+	ErrRunUnavailable        = errors.New("custom run service is unavailable")
+)
+
+const (
+	defaultRunConcurrency = 16
+	// runCompileAllowance is added to the tenant's largest time limit to bound
+	// one synchronous run including compilation and sandbox queueing.
+	runCompileAllowance = 90 * time.Second
+)
+
+// RunCommand is the body of POST /api/v1/runs. Omitted limits select the
+// tenant ceilings; expectedOutput enables a token comparison.
+type RunCommand struct {
+	Language        string  `json:"language"`
+	SourceCode      string  `json:"sourceCode"`
+	Stdin           string  `json:"stdin"`
+	ExpectedOutput  *string `json:"expectedOutput,omitempty"`
+	TimeLimitMillis int     `json:"timeLimitMillis,omitempty"`
+	MemoryLimitMiB  int     `json:"memoryLimitMiB,omitempty"`
+}
+
+type RunLimitsView struct {
+	TimeLimitMillis int `json:"timeLimitMillis"`
+	MemoryLimitMiB  int `json:"memoryLimitMiB"`
+}
+
+type RunView struct {
+	RunID              string        `json:"runId"`
+	Verdict            string        `json:"verdict"`
+	ExitCode           int           `json:"exitCode"`
+	TimeMillis         int64         `json:"timeMillis"`
+	MemoryBytes        int64         `json:"memoryBytes"`
+	Stdout             string        `json:"stdout"`
+	Stderr             string        `json:"stderr"`
+	CompileDiagnostics string        `json:"compileDiagnostics,omitempty"`
+	Limits             RunLimitsView `json:"limits"`
+}
+
+type RunService interface {
+	Run(context.Context, string, RunCommand) (RunView, error)
+}
+
+func WithRunService(service RunService) ServerOption {
+	return func(server *Server) error {
+		if service == nil {
+			return fmt.Errorf("run service is required")
+		}
+		server.runs = service
+		return nil
+	}
+}
+
+func WithRunQuota(quota external.Quota, limit external.QuotaLimit) ServerOption {
+	return func(server *Server) error {
+		if quota == nil {
+			return fmt.Errorf("run quota is required")
+		}
+		if err := limit.Validate(); err != nil {
+			return err
+		}
+		server.runQuota = quota
+		server.runLimit = limit
+		return nil
+	}
+}
+
+// WithRunConcurrency bounds synchronous runs held open by one pod. Each one
+// occupies a connection for up to the tenant time limit plus compilation.
+func WithRunConcurrency(maximum int) ServerOption {
+	return func(server *Server) error {
+		if maximum < 1 || maximum > 1024 {
+			return fmt.Errorf("run concurrency must be between 1 and 1024")
+		}
+		server.runSlots = make(chan struct{}, maximum)
+		return nil
+	}
+}
+
+func (server *Server) handleRuns(response http.ResponseWriter, request *http.Request, requestID string) {
+	if request.Method != http.MethodPost {
+		response.Header().Set("Allow", http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST to execute a custom run.", requestID))
+		return
+	}
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeRunExecute)
+	if !authenticated {
+		return
+	}
+	controller := http.NewResponseController(response)
+	select {
+	case server.runSlots <- struct{}{}:
+		defer func() { <-server.runSlots }()
+	default:
+		closeUnreadRequestBody(response, request, controller)
+		response.Header().Set("Retry-After", "1")
+		writeProblem(response, problemFor(http.StatusServiceUnavailable, "run-capacity-exhausted", "Run capacity exhausted", "Retry the custom run later.", requestID))
+		return
+	}
+	if err := controller.SetReadDeadline(time.Now().Add(server.jobBodyReadTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		closeUnreadRequestBody(response, request, controller)
+		response.Header().Set("Retry-After", "1")
+		writeProblem(response, problemFor(http.StatusServiceUnavailable, "run-read-protection-unavailable", "Run read protection unavailable", "Retry the custom run later.", requestID))
+		return
+	}
+	if !hasApplicationJSONContentType(request.Header.Values("Content-Type")) {
+		closeUnreadRequestBody(response, request, controller)
+		writeProblem(response, problemFor(http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported media type", "Use Content-Type: application/json for custom runs.", requestID))
+		return
+	}
+	var command RunCommand
+	decodeErr := decodeStrictJSON(response, request, &command, maximumRunRequestBytes(server.capabilities.Limits.MaxSourceBytes))
+	if !drainAndCloseRequestBody(request.Body) {
+		response.Header().Set("Connection", "close")
+	} else {
+		_ = controller.SetReadDeadline(time.Time{})
+	}
+	if decodeErr != nil {
+		if isRequestBodyTimeout(decodeErr) {
+			response.Header().Set("Connection", "close")
+			response.Header().Set("Retry-After", "1")
+			writeProblem(response, problemFor(http.StatusRequestTimeout, "request-body-timeout", "Request body timeout", "Retry the request with a complete JSON body before the read deadline.", requestID))
+			return
+		}
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-json", "Invalid request body", "Provide one JSON object containing only documented fields.", requestID))
+		return
+	}
+	runTimeout := time.Duration(server.capabilities.Limits.MaxTimeLimitMillis)*time.Millisecond + runCompileAllowance
+	runContext, cancelRun := context.WithTimeout(request.Context(), runTimeout)
+	defer cancelRun()
+	decision, err := server.runQuota.Allow(runContext, external.QuotaRequest{
+		TenantID: principal.TenantID, Kind: external.QuotaCustomRun, Cost: 1, Limit: server.runLimit,
+	})
+	if err != nil {
+		server.writeRunError(response, requestID, &jobQuotaAdmissionError{unavailable: true})
+		return
+	}
+	if !decision.Allowed {
+		server.writeRunError(response, requestID, &jobQuotaAdmissionError{retryAfter: decision.RetryAfter})
+		return
+	}
+	view, err := server.runs.Run(runContext, principal.TenantID, command)
+	if err != nil {
+		server.writeRunError(response, requestID, err)
+		return
+	}
+	writeJSON(response, http.StatusOK, view)
+}
+
+func (server *Server) writeRunError(response http.ResponseWriter, requestID string, err error) {
+	var quotaError *jobQuotaAdmissionError
+	switch {
+	case errors.As(err, &quotaError):
+		server.writeJobError(response, requestID, err)
+	case errors.Is(err, ErrRunInvalid):
+		writeProblem(response, problemFor(http.StatusUnprocessableEntity, "invalid-run", "Custom run is invalid", "The run could not be accepted under tenant policy.", requestID))
+	case errors.Is(err, ErrRunCapacityExceeded):
+		response.Header().Set("Retry-After", "1")
+		writeProblem(response, problemFor(http.StatusTooManyRequests, "run-concurrency-exceeded", "Custom run concurrency exceeded", "Wait for running custom runs to finish before retrying.", requestID))
+	case errors.Is(err, ErrRunDailyLimitExceeded):
+		response.Header().Set("Retry-After", "60")
+		writeProblem(response, problemFor(http.StatusTooManyRequests, "daily-execution-exhausted", "Daily execution budget exhausted", "The tenant daily execution budget cannot cover this run.", requestID))
+	default:
+		response.Header().Set("Retry-After", "5")
+		writeProblem(response, problemFor(http.StatusServiceUnavailable, "run-service-unavailable", "Custom run service unavailable", "Retry the request later.", requestID))
+	}
+}
+
+func maximumRunRequestBytes(maxSourceBytes int64) int64 {
+	return maximumJobRequestBytes(maxSourceBytes) + 2*external.MaximumRunInputBytes*maximumJobRequestEncodingExpansion
+}
+
+type customRunExecutor interface {
+	Run(context.Context, string, external.CustomRunRequest) (external.CustomRunResult, error)
+}
+
+// CanonicalRunService adapts the custom runner to the public run resource.
+type CanonicalRunService struct{ executor customRunExecutor }
+
+func NewCanonicalRunService(executor customRunExecutor) (*CanonicalRunService, error) {
+	if executor == nil {
+		return nil, fmt.Errorf("custom run executor is required")
+	}
+	return &CanonicalRunService{executor: executor}, nil
+}
+
+func (service *CanonicalRunService) Run(ctx context.Context, tenantID string, command RunCommand) (RunView, error) {
+	language, ok := judgecontract.ResolveLanguage(command.Language)
+	if !ok {
+		return RunView{}, ErrRunInvalid
+	}
+	request := external.CustomRunRequest{
+		Language: language.SandboxID, SourceCode: []byte(command.SourceCode), Stdin: []byte(command.Stdin),
+		TimeLimitMillis: command.TimeLimitMillis, MemoryLimitMiB: command.MemoryLimitMiB,
+	}
+	if command.ExpectedOutput != nil {
+		request.ExpectedOutput, request.CompareOutput = []byte(*command.ExpectedOutput), true
+	}
+	result, err := service.executor.Run(ctx, tenantID, request)
+	if err != nil {
+		return RunView{}, mapCustomRunError(err)
+	}
+	return RunView{
+		RunID: result.RunID, Verdict: result.Verdict, ExitCode: result.ExitCode,
+		TimeMillis: result.TimeMillis, MemoryBytes: result.MemoryBytes,
+		Stdout: result.Stdout, Stderr: result.Stderr, CompileDiagnostics: result.CompileOutput,
+		Limits: RunLimitsView{TimeLimitMillis: result.TimeLimitMillis, MemoryLimitMiB: result.MemoryLimitMiB},
+	}, nil
+}
+
+func mapCustomRunError(err error) error {
+	switch {
+	case errors.Is(err, external.ErrCustomRunInvalid):
+		return ErrRunInvalid
+	case errors.Is(err, external.ErrCustomRunCapacity):
+		return ErrRunCapacityExceeded
+	case errors.Is(err, external.ErrDailyExecutionExhausted):
+		return ErrRunDailyLimitExceeded
+	default:
+		return ErrRunUnavailable
+	}
+}
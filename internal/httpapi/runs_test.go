@@ -0,0 +1,211 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+type runServiceStub struct {
+	tenant  string
+	command RunCommand
+	view    RunView
+	err     error
+	block   chan struct{}
+}
+
+func (service *runServiceStub) Run(_ context.Context, tenantID string, command RunCommand) (RunView, error) {
+	if service.block != nil {
+		<-service.block
+	}
+	service.tenant, service.command = tenantID, command
+	return service.view, service.err
+}
+
+type customRunExecutorStub struct {
+	request external.CustomRunRequest
+	result  external.CustomRunResult
+	err     error
+}
+
+func (executor *customRunExecutorStub) Run(_ context.Context, _ string, request external.CustomRunRequest) (external.CustomRunResult, error) {
+	executor.request = request
+	return executor.result, executor.err
+}
+
+func TestRunReturnsOutputAndEffectiveLimits(t *testing.T) {
+	service := &runServiceStub{view: RunView{
+		RunID: "ceirceirceirceirceirceirce", Verdict: "ACCEPTED", TimeMillis: 12, MemoryBytes: 4096,
+		Stdout: "3\n", Limits: RunLimitsView{TimeLimitMillis: 2000, MemoryLimitMiB: 256},
+	}}
+	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
+	server := newRunTestServer(t, service, quota, ScopeRunExecute)
+	response := serveRun(server, `{"language":"cpp","sourceCode":"int main(){}","stdin":"1 2\n","expectedOutput":"3","timeLimitMillis":2000}`)
+	if response.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+	var view RunView
+	if err := json.Unmarshal(response.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	if view != service.view {
+		t.Fatalf("view = %+v", view)
+	}
+	if service.tenant != "tenant-7" || service.command.Stdin != "1 2\n" || service.command.ExpectedOutput == nil || *service.command.ExpectedOutput != "3" {
+		t.Fatalf("run tenant=%q command=%+v", service.tenant, service.command)
+	}
+	if quota.request.Kind != external.QuotaCustomRun || quota.request.Cost != 1 || quota.request.TenantID != "tenant-7" {
+		t.Fatalf("quota request = %+v", quota.request)
+	}
+}
+
+func TestRunRequiresScopeJSONAndQuota(t *testing.T) {
+	service := &runServiceStub{}
+	server := newRunTestServer(t, service, &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}, ScopeJobSubmit)
+	if response := serveRun(server, `{}`); response.Code != http.StatusForbidden {
+		t.Fatalf("missing scope status=%d", response.Code)
+	}
+	server = newRunTestServer(t, service, &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}, ScopeRunExecute)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs", strings.NewReader(`{}`))
+	request.Header.Set("Authorization", "Bearer valid")
+	request.Header.Set("Content-Type", "text/plain")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	if response.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("content type status=%d", response.Code)
+	}
+	if response := serveRun(server, `{"language":"cpp","unknown":true}`); response.Code != http.StatusBadRequest {
+		t.Fatalf("unknown field status=%d", response.Code)
+	}
+	limited := &writeQuotaStub{decision: external.QuotaDecision{Allowed: false, RetryAfter: 1500 * time.Millisecond}}
+	server = newRunTestServer(t, service, limited, ScopeRunExecute)
+	if response := serveRun(server, `{"language":"cpp","sourceCode":"x"}`); response.Code != http.StatusTooManyRequests || response.Header().Get("Retry-After") != "2" {
+		t.Fatalf("quota status=%d headers=%v", response.Code, response.Header())
+	}
+	unavailable := &writeQuotaStub{err: external.ErrQuotaUnavailable}
+	server = newRunTestServer(t, service, unavailable, ScopeRunExecute)
+	if response := serveRun(server, `{"language":"cpp","sourceCode":"x"}`); response.Code != http.StatusServiceUnavailable {
+		t.Fatalf("quota unavailable status=%d", response.Code)
+	}
+	if service.tenant != "" {
+		t.Fatal("run executed without admission")
+	}
+}
+
+func TestRunMapsAccountingErrorsToProblems(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+		kind   string
+	}{
+		{ErrRunInvalid, http.StatusUnprocessableEntity, "invalid-run"},
+		{ErrRunCapacityExceeded, http.StatusTooManyRequests, "run-concurrency-exceeded"},
+		{ErrRunDailyLimitExceeded, http.StatusTooManyRequests, "daily-execution-exhausted"},
+		{ErrRunUnavailable, http.StatusServiceUnavailable, "run-service-unavailable"},
+		{context.DeadlineExceeded, http.StatusServiceUnavailable, "run-service-unavailable"},
+	} {
+		server := newRunTestServer(t, &runServiceStub{err: test.err}, &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}, ScopeRunExecute)
+		response := serveRun(server, `{"language":"cpp","sourceCode":"x"}`)
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.kind) {
+			t.Fatalf("%v: status=%d body=%s", test.err, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestRunRejectsExcessConcurrentRunsBeforeReadingBody(t *testing.T) {
+	service := &runServiceStub{block: make(chan struct{})}
+	quota := &writeQuotaStub{decision: external.QuotaDecision{Allowed: true}}
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{ScopeRunExecute: {}}}}, testCapabilities(),
+		WithRunService(service), WithRunQuota(quota, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}), WithRunConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveRun(server, `{"language":"cpp","sourceCode":"x"}`)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(server.runSlots) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	body := &trackingBody{reader: strings.NewReader(`{"language":"cpp","sourceCode":"x"}`)}
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs", body)
+	request.Header.Set("Authorization", "Bearer valid")
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	close(service.block)
+	<-done
+	if response.Code != http.StatusServiceUnavailable || response.Header().Get("Retry-After") != "1" || !body.closed {
+		t.Fatalf("status=%d headers=%v closed=%v", response.Code, response.Header(), body.closed)
+	}
+}
+
+func TestCanonicalRunServiceMapsLanguageExpectedOutputAndErrors(t *testing.T) {
+	executor := &customRunExecutorStub{result: external.CustomRunResult{
+		RunID: "ceirceirceirceirceirceirce", Verdict: "WRONG_ANSWER", TimeLimitMillis: 1000, MemoryLimitMiB: 64,
+	}}
+	service, err := NewCanonicalRunService(executor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "4"
+	view, err := service.Run(context.Background(), "tenant-7", RunCommand{Language: "cpp", SourceCode: "x", ExpectedOutput: &expected})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Verdict != "WRONG_ANSWER" || view.Limits.TimeLimitMillis != 1000 || !executor.request.CompareOutput || string(executor.request.ExpectedOutput) != "4" {
+		t.Fatalf("view=%+v request=%+v", view, executor.request)
+	}
+	if _, err := service.Run(context.Background(), "tenant-7", RunCommand{Language: "cobol", SourceCode: "x"}); err != ErrRunInvalid {
+		t.Fatalf("unknown language error = %v", err)
+	}
+	for source, want := range map[error]error{
+		external.ErrCustomRunInvalid:        ErrRunInvalid,
+		external.ErrCustomRunCapacity:       ErrRunCapacityExceeded,
+		external.ErrDailyExecutionExhausted: ErrRunDailyLimitExceeded,
+		external.ErrExternalJobUnavailable:  ErrRunUnavailable,
+	} {
+		executor.err = source
+		if _, err := service.Run(context.Background(), "tenant-7", RunCommand{Language: "cpp", SourceCode: "x"}); err != want {
+			t.Fatalf("%v mapped to %v", source, err)
+		}
+	}
+}
+
+func TestServerRejectsRunServiceWithoutQuota(t *testing.T) {
+	_, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7"}}, testCapabilities(), WithRunService(&runServiceStub{}))
+	if err == nil || !strings.Contains(err.Error(), "run quota") {
+		t.Fatalf("error=%v", err)
+	}
+}
+
+func newRunTestServer(t *testing.T, service RunService, quota external.Quota, scopes ...Scope) *Server {
+	t.Helper()
+	granted := make(map[Scope]struct{}, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = struct{}{}
+	}
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: granted}}, testCapabilities(),
+		WithRunService(service), WithRunQuota(quota, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func serveRun(server *Server, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/runs", strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer valid")
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	return response
+}
@@ -32,6 +32,19 @@ type Server struct {
 	jobBodyReadTimeout     time.Duration
 	jobBodyReaders         chan struct{}
 	jobSubmitTimeout       time.Duration
+	jobEvents              JobEventSource
+	jobEventStreams        chan struct{}
+	jobEventPollInterval   time.Duration
+	jobEventKeepAlive      time.Duration
+	jobEventMaxDuration    time.Duration
+	runs                   RunService
+	runQuota               external.Quota
+	runLimit               external.QuotaLimit
+	runSlots               chan struct{}
+	callbacks              CallbackApplication
+	webhookDeliveries      WebhookDeliveryApplication
+	webhookKeys            WebhookKeySource
+	usage                  UsageApplication
 }
 
 const (
@@ -136,6 +149,9 @@ func NewServer(authenticator RequestAuthenticator, capabilities Capabilities, op
 		jobBodyReadTimeout:     defaultJobBodyReadTimeout,
 		jobBodyReaders:         make(chan struct{}, defaultJobBodyConcurrency),
 		jobSubmitTimeout:       defaultJobSubmitTimeout,
+		jobEventPollInterval:   defaultJobEventPollInterval,
+		jobEventKeepAlive:      defaultJobEventKeepAlive,
+		jobEventMaxDuration:    defaultJobEventMaxDuration,
 	}
 	for _, option := range options {
 		if option == nil {
@@ -148,6 +164,15 @@ func NewServer(authenticator RequestAuthenticator, capabilities Capabilities, op
 	if server.jobs != nil && server.jobWriteQuota == nil {
 		return nil, fmt.Errorf("write quota is required when the job service is enabled")
 	}
+	if server.jobEvents != nil && server.jobs == nil {
+		return nil, fmt.Errorf("job service is required when the job event stream is enabled")
+	}
+	if server.runs != nil && server.runQuota == nil {
+		return nil, fmt.Errorf("run quota is required when custom runs are enabled")
+	}
+	if server.runs != nil && server.runSlots == nil {
+		server.runSlots = make(chan struct{}, defaultRunConcurrency)
+	}
 	if server.bundles != nil && server.bundleWriteQuota == nil {
 		return nil, fmt.Errorf("bundle write quota is required when bundle uploads are enabled")
 	}
@@ -163,15 +188,37 @@ func NewServer(authenticator RequestAuthenticator, capabilities Capabilities, op
 func (server *Server) ServeHTTP(response http.ResponseWriter, request *http.Request) {
 	requestID := newRequestID()
 	response.Header().Set("X-Request-Id", requestID)
+	started := time.Now()
+	recorder, request, span := startServerSpan(response, request, requestID)
+	defer endServerSpan(span, recorder)
+	request = withRequestLogging(request, requestID)
+	defer func() { logRequest(request, recorder, started) }()
+	response = recorder
 	switch {
 	case request.URL.Path == "/api/v1/capabilities":
 		server.handleCapabilities(response, request, requestID)
+	case server.webhookKeys != nil && request.URL.Path == WebhookKeysPath:
+		server.handleWebhookKeys(response, request, requestID)
+	case server.jobs != nil && isBundleRejudgePath(request.URL.Path):
+		server.handleBundleRejudge(response, request, requestID)
 	case server.bundles != nil && request.URL.Path == "/api/v1/bundles":
 		server.serveBundleCollection(response, request, requestID)
 	case server.bundles != nil && strings.HasPrefix(request.URL.Path, "/api/v1/bundles/"):
 		server.serveBundleMetadata(response, request, requestID)
-	case server.jobs != nil && (request.URL.Path == "/api/v1/judge-jobs" || strings.HasPrefix(request.URL.Path, "/api/v1/judge-jobs/")):
+	case server.jobs != nil && (request.URL.Path == "/api/v1/judge-jobs" || request.URL.Path == JobBatchPath || strings.HasPrefix(request.URL.Path, "/api/v1/judge-jobs/")):
 		server.handleJobs(response, request, requestID)
+	case server.runs != nil && request.URL.Path == "/api/v1/runs":
+		server.handleRuns(response, request, requestID)
+	case server.callbacks != nil && request.URL.Path == "/api/v1/callbacks":
+		server.serveCallbackCollection(response, request, requestID)
+	case server.callbacks != nil && strings.HasPrefix(request.URL.Path, "/api/v1/callbacks/"):
+		server.serveCallbackItem(response, request, requestID)
+	case server.usage != nil && request.URL.Path == UsagePath:
+		server.handleUsage(response, request, requestID)
+	case server.webhookDeliveries != nil && request.URL.Path == "/api/v1/webhook-deliveries":
+		server.serveWebhookDeliveryCollection(response, request, requestID)
+	case server.webhookDeliveries != nil && strings.HasPrefix(request.URL.Path, "/api/v1/webhook-deliveries/"):
+		server.serveWebhookDeliveryItem(response, request, requestID)
 	default:
 		writeProblem(response, problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID))
 	}
@@ -215,6 +262,8 @@ func (server *Server) authenticate(response http.ResponseWriter, request *http.R
 		writeProblem(response, problemFor(http.StatusUnauthorized, "unauthorized", "Authentication required", "Provide a valid active API key.", requestID))
 		return Principal{}, false
 	}
+	tagSpanTenant(request, principal.TenantID)
+	tagLogTenant(request, principal.TenantID)
 	if !principal.Has(scope) {
 		writeProblem(response, problemFor(http.StatusForbidden, "insufficient-scope", "Insufficient scope", "The API key does not grant this operation.", requestID))
 		return Principal{}, false
@@ -190,7 +190,7 @@ func TestServerRejectsCapabilitiesOutsideThePublishedV1Contract(t *testing.T) {
 		"zero bundle limit":       func(value *Capabilities) { value.Limits.MaxBundleBytes = 0 },
 		"zero case limit":         func(value *Capabilities) { value.Limits.MaxCaseBytes = 0 },
 		"zero case count":         func(value *Capabilities) { value.Limits.MaxCaseCount = 0 },
-		"excess case count":       func(value *Capabilities) { value.Limits.MaxCaseCount = 257 },
+		"excess case count":       func(value *Capabilities) { value.Limits.MaxCaseCount = 10_001 },
 		"zero time limit":         func(value *Capabilities) { value.Limits.MaxTimeLimitMillis = 0 },
 		"zero memory limit":       func(value *Capabilities) { value.Limits.MaxMemoryLimitMiB = 0 },
 	} {
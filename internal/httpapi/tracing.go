@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// statusRecorder captures the response status for the server span. Unwrap
+// keeps http.ResponseController deadlines and flushes reaching the listener.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(body []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	return recorder.ResponseWriter.Write(body)
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// startServerSpan continues any caller trace and names the span after the
+// route template so identifiers never become span names.
+func startServerSpan(response http.ResponseWriter, request *http.Request, requestID string) (*statusRecorder, *http.Request, trace.Span) {
+	route := spanRoute(request.URL.Path)
+	ctx := tracing.Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+	ctx, span := tracing.Start(ctx, request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", request.Method),
+			attribute.String("http.route", route),
+			attribute.String("croj.request_id", requestID),
+		),
+	)
+	return &statusRecorder{ResponseWriter: response}, request.WithContext(ctx), span
+}
+
+func endServerSpan(span trace.Span, recorder *statusRecorder) {
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
+
+// tagSpanTenant records the authenticated tenant, never the key prefix.
+func tagSpanTenant(request *http.Request, tenantID string) {
+	trace.SpanFromContext(request.Context()).SetAttributes(attribute.String("croj.tenant", tenantID))
+}
+
+func spanRoute(path string) string {
+	switch {
+	case path == "/api/v1/capabilities", path == "/api/v1/bundles", path == "/api/v1/judge-jobs", path == "/api/v1/runs", path == "/api/v1/callbacks",
+		path == "/api/v1/webhook-deliveries", path == WebhookKeysPath, path == UsagePath:
+		return path
+	case isBundleRejudgePath(path):
+		return "/api/v1/bundles/{bundleId}/rejudge"
+	case strings.HasPrefix(path, "/api/v1/bundles/"):
+		return "/api/v1/bundles/{bundleId}"
+	case strings.HasPrefix(path, "/api/v1/judge-jobs/"):
+		segments := strings.Split(strings.TrimPrefix(path, "/api/v1/judge-jobs/"), "/")
+		if len(segments) == 2 && (segments[1] == "cancel" || segments[1] == "events" || segments[1] == "rejudge") {
+			return "/api/v1/judge-jobs/{jobId}/" + segments[1]
+		}
+		return "/api/v1/judge-jobs/{jobId}"
+	case strings.HasPrefix(path, "/api/v1/callbacks/"):
+		if strings.HasSuffix(path, "/rotate-secret") {
+			return "/api/v1/callbacks/{callbackId}/rotate-secret"
+		}
+		if strings.HasSuffix(path, "/ping") {
+			return "/api/v1/callbacks/{callbackId}/ping"
+		}
+		return "/api/v1/callbacks/{callbackId}"
+	case strings.HasPrefix(path, "/api/v1/webhook-deliveries/"):
+		return "/api/v1/webhook-deliveries/{eventId}/redeliver"
+	default:
+		return "unmatched"
+	}
+}
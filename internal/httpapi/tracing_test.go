@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServerSpanContinuesCallerTraceAndNamesRouteTemplate(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	service := &jobServiceStub{view: JobView{JobID: "ceirceirceirceirceirceirce", Status: JobRunning}}
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{ScopeJobRead: {}}}}, testCapabilities(),
+		WithJobService(service), WithJobWriteQuota(&writeQuotaStub{}, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const callerTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/judge-jobs/ceirceirceirceirceirceirce", nil)
+	request.Header.Set("Authorization", "Bearer valid-secret")
+	request.Header.Set("traceparent", "00-"+callerTrace+"-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	spans := recorder.Ended()
+	if response.Code != http.StatusOK || len(spans) != 1 {
+		t.Fatalf("status=%d ended spans=%d", response.Code, len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/judge-jobs/{jobId}" || span.SpanContext().TraceID().String() != callerTrace {
+		t.Fatalf("span name=%q trace=%s", span.Name(), span.SpanContext().TraceID())
+	}
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["croj.request_id"].AsString() != response.Header().Get("X-Request-Id") ||
+		attributes["croj.tenant"].AsString() != "tenant-7" ||
+		attributes["http.response.status_code"].AsInt64() != int64(response.Code) {
+		t.Fatalf("span attributes = %v, response status %d", attributes, response.Code)
+	}
+}
+
+func TestSpanRouteNeverEmbedsIdentifiers(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/v1/capabilities":                               "/api/v1/capabilities",
+		"/api/v1/bundles/bundle-1":                           "/api/v1/bundles/{bundleId}",
+		"/api/v1/bundles/aaaaaaaaaaaaaaaaaaaaaaaaaa/rejudge": "/api/v1/bundles/{bundleId}/rejudge",
+		"/api/v1/judge-jobs":                                 "/api/v1/judge-jobs",
+		"/api/v1/judge-jobs/job-1/cancel":                    "/api/v1/judge-jobs/{jobId}/cancel",
+		"/api/v1/judge-jobs/job-1/events":                    "/api/v1/judge-jobs/{jobId}/events",
+		"/api/v1/judge-jobs/job-1/rejudge":                   "/api/v1/judge-jobs/{jobId}/rejudge",
+		"/api/v1/usage":                                      "/api/v1/usage",
+		"/api/v1/judge-jobs/job-1/unknown":                   "/api/v1/judge-jobs/{jobId}",
+		"/api/v1/callbacks/cb-1":                             "/api/v1/callbacks/{callbackId}",
+		"/api/v1/callbacks/cb-1/rotate-secret":               "/api/v1/callbacks/{callbackId}/rotate-secret",
+		"/api/v1/callbacks/cb-1/ping":                        "/api/v1/callbacks/{callbackId}/ping",
+		"/api/v1/webhook-deliveries/ev-1/redeliver":          "/api/v1/webhook-deliveries/{eventId}/redeliver",
+		"/.well-known/croj-webhook-keys":                     "/.well-known/croj-webhook-keys",
+		"/secret/path":                                       "unmatched",
+	} {
+		if got := spanRoute(path); got != want {
+			t.Errorf("spanRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+const (
+	UsagePath               = "/api/v1/usage"
+	defaultUsageHistoryDays = 7
+)
+
+type UsageApplication interface {
+	TenantUsage(context.Context, string, int) (external.TenantUsage, error)
+}
+
+// UsageView adds the tenant's rate-limit buckets to its stored usage.
+type UsageView struct {
+	external.TenantUsage
+	RateLimits []RateLimitUsageView `json:"rateLimits"`
+}
+
+// RateLimitUsageView reports one token bucket. Available is null when the
+// quota store could not be read; admission would then fail closed.
+type RateLimitUsageView struct {
+	Kind               external.QuotaKind `json:"kind"`
+	Capacity           int64              `json:"capacity"`
+	RefillPeriodMillis int64              `json:"refillPeriodMillis"`
+	Available          *int64             `json:"available"`
+}
+
+func WithUsageApplication(application UsageApplication) ServerOption {
+	return func(server *Server) error {
+		if application == nil {
+			return errors.New("usage application is required")
+		}
+		server.usage = application
+		return nil
+	}
+}
+
+func (server *Server) handleUsage(response http.ResponseWriter, request *http.Request, requestID string) {
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET for this resource.", requestID))
+		return
+	}
+	principal, authenticated := server.authenticate(response, request, requestID, ScopeJobRead)
+	if !authenticated {
+		return
+	}
+	days, err := parseUsageQuery(request)
+	if err != nil {
+		writeInvalidUsageQueryProblem(response, requestID)
+		return
+	}
+	usage, err := server.usage.TenantUsage(request.Context(), principal.TenantID, days)
+	switch {
+	case errors.Is(err, external.ErrInvalidUsageQuery):
+		writeInvalidUsageQueryProblem(response, requestID)
+		return
+	case errors.Is(err, external.ErrExternalJobUnavailable), errors.Is(err, external.ErrExternalJobNotFound):
+		server.writeJobError(response, requestID, ErrJobUnavailable)
+		return
+	case err != nil:
+		server.writeJobError(response, requestID, err)
+		return
+	}
+	view := UsageView{TenantUsage: usage, RateLimits: []RateLimitUsageView{}}
+	for _, bucket := range []struct {
+		quota external.Quota
+		kind  external.QuotaKind
+		limit external.QuotaLimit
+	}{
+		{server.jobWriteQuota, external.QuotaJudgeSubmit, server.jobWriteLimit},
+		{server.bundleWriteQuota, external.QuotaBundleUploadBytes, server.bundleWriteLimit},
+		{server.runQuota, external.QuotaCustomRun, server.runLimit},
+	} {
+		if bucket.quota == nil {
+			continue
+		}
+		level := RateLimitUsageView{Kind: bucket.kind, Capacity: bucket.limit.Capacity, RefillPeriodMillis: bucket.limit.RefillPeriod.Milliseconds()}
+		if inspector, ok := bucket.quota.(external.QuotaInspector); ok {
+			if available, err := inspector.Available(request.Context(), principal.TenantID, bucket.kind, bucket.limit); err == nil {
+				level.Available = &available
+			}
+		}
+		view.RateLimits = append(view.RateLimits, level)
+	}
+	writeJSON(response, http.StatusOK, view)
+}
+
+func parseUsageQuery(request *http.Request) (int, error) {
+	values, err := url.ParseQuery(request.URL.RawQuery)
+	if err != nil {
+		return 0, external.ErrInvalidUsageQuery
+	}
+	for key, entries := range values {
+		if key != "days" || len(entries) != 1 {
+			return 0, external.ErrInvalidUsageQuery
+		}
+	}
+	raw := values.Get("days")
+	if raw == "" {
+		return defaultUsageHistoryDays, nil
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 || days > external.MaximumUsageHistoryDays {
+		return 0, external.ErrInvalidUsageQuery
+	}
+	return days, nil
+}
+
+func writeInvalidUsageQueryProblem(response http.ResponseWriter, requestID string) {
+	writeProblem(response, problemFor(http.StatusBadRequest, "invalid-usage-query", "Invalid usage query",
+		"Use only days (1-"+strconv.Itoa(external.MaximumUsageHistoryDays)+").", requestID))
+}
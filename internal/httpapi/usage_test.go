@@ -0,0 +1,152 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+type usageApplicationStub struct {
+	usage  external.TenantUsage
+	err    error
+	tenant string
+	days   int
+}
+
+func (application *usageApplicationStub) TenantUsage(_ context.Context, tenantID string, days int) (external.TenantUsage, error) {
+	application.tenant, application.days = tenantID, days
+	return application.usage, application.err
+}
+
+// inspectableQuotaStub reports a fixed level per bucket kind and fails
+// inspection for kinds it does not know.
+type inspectableQuotaStub struct {
+	writeQuotaStub
+	levels map[external.QuotaKind]int64
+}
+
+func (quota *inspectableQuotaStub) Available(_ context.Context, tenantID string, kind external.QuotaKind, _ external.QuotaLimit) (int64, error) {
+	level, ok := quota.levels[kind]
+	if !ok || tenantID != "tenant-7" {
+		return 0, external.ErrQuotaUnavailable
+	}
+	return level, nil
+}
+
+func newUsageTestServer(t *testing.T, application UsageApplication, quota external.Quota, scopes ...Scope) *Server {
+	t.Helper()
+	granted := make(map[Scope]struct{}, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = struct{}{}
+	}
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: granted}}, testCapabilities(),
+		WithJobService(&jobServiceStub{}), WithJobWriteQuota(quota, external.QuotaLimit{Capacity: 20, RefillPeriod: time.Second}),
+		WithBundleApplication(&bundleApplicationStub{}), WithBundleWriteQuota(quota, external.QuotaLimit{Capacity: 64 << 20, RefillPeriod: time.Minute}),
+		WithUsageApplication(application))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func usageRequest(query string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, UsagePath+query, nil)
+	request.Header.Set("Authorization", "Bearer valid")
+	return request
+}
+
+func TestUsageReportsStoredUsageAndTokenBucketLevels(t *testing.T) {
+	application := &usageApplicationStub{usage: external.TenantUsage{
+		Execution: external.ExecutionUsage{Day: "2026-07-19", ReservedMillis: 1000, ConsumedMillis: 2500, RemainingMillis: 6500, LimitMillis: 10000},
+		Jobs:      external.JobUsage{Queued: 3, MaxQueued: 10, Running: 1, MaxRunning: 2},
+		Bundles:   external.BundleUsage{Retained: 4, MaxRetained: 100},
+		History:   []external.ExecutionUsageDay{{Day: "2026-07-18", ConsumedMillis: 700}, {Day: "2026-07-19", ReservedMillis: 1000, ConsumedMillis: 2500}},
+	}}
+	quota := &inspectableQuotaStub{levels: map[external.QuotaKind]int64{external.QuotaJudgeSubmit: 12}}
+	response := httptest.NewRecorder()
+	newUsageTestServer(t, application, quota, ScopeJobRead).ServeHTTP(response, usageRequest("?days=2"))
+
+	if response.Code != http.StatusOK || response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status=%d headers=%v body=%s", response.Code, response.Header(), response.Body)
+	}
+	if application.tenant != "tenant-7" || application.days != 2 {
+		t.Fatalf("tenant=%q days=%d", application.tenant, application.days)
+	}
+	var view UsageView
+	if err := json.Unmarshal(response.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Execution != application.usage.Execution || view.Jobs != application.usage.Jobs || view.Bundles != application.usage.Bundles || len(view.History) != 2 {
+		t.Fatalf("view = %+v", view)
+	}
+	if len(view.RateLimits) != 2 {
+		t.Fatalf("rate limits = %+v", view.RateLimits)
+	}
+	submit, upload := view.RateLimits[0], view.RateLimits[1]
+	if submit.Kind != external.QuotaJudgeSubmit || submit.Capacity != 20 || submit.RefillPeriodMillis != 1000 || submit.Available == nil || *submit.Available != 12 {
+		t.Fatalf("submit bucket = %+v", submit)
+	}
+	// An unreadable bucket is reported with a null level rather than failing the read.
+	if upload.Kind != external.QuotaBundleUploadBytes || upload.Capacity != 64<<20 || upload.Available != nil || !strings.Contains(response.Body.String(), `"available":null`) {
+		t.Fatalf("upload bucket = %+v", upload)
+	}
+}
+
+func TestUsageDefaultsToAWeekAndRejectsInvalidQueries(t *testing.T) {
+	application := &usageApplicationStub{}
+	server := newUsageTestServer(t, application, &writeQuotaStub{}, ScopeJobRead)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, usageRequest(""))
+	if response.Code != http.StatusOK || application.days != 7 {
+		t.Fatalf("status=%d days=%d", response.Code, application.days)
+	}
+	var view UsageView
+	if err := json.Unmarshal(response.Body.Bytes(), &view); err != nil || len(view.RateLimits) != 2 || view.RateLimits[0].Available != nil {
+		t.Fatalf("view=%+v err=%v", view, err)
+	}
+
+	for _, query := range []string{"?days=0", "?days=91", "?days=x", "?days=1&days=2", "?limit=1", "?days=%ZZ"} {
+		application.days = 0
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, usageRequest(query))
+		if response.Code != http.StatusBadRequest || !strings.HasSuffix(problemType(t, response), "/invalid-usage-query") || application.days != 0 {
+			t.Fatalf("query=%q status=%d body=%s", query, response.Code, response.Body)
+		}
+	}
+}
+
+func TestUsageMapsStorageErrorsScopeAndMethod(t *testing.T) {
+	for name, test := range map[string]struct {
+		err    error
+		status int
+	}{
+		"unavailable": {external.ErrExternalJobUnavailable, http.StatusServiceUnavailable},
+		"internal":    {errors.New("database detail"), http.StatusInternalServerError},
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			newUsageTestServer(t, &usageApplicationStub{err: test.err}, &writeQuotaStub{}, ScopeJobRead).ServeHTTP(response, usageRequest(""))
+			if response.Code != test.status || strings.Contains(response.Body.String(), "database detail") {
+				t.Fatalf("status=%d body=%s", response.Code, response.Body)
+			}
+		})
+	}
+
+	response := httptest.NewRecorder()
+	newUsageTestServer(t, &usageApplicationStub{}, &writeQuotaStub{}, ScopeJobSubmit).ServeHTTP(response, usageRequest(""))
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("job:submit status = %d", response.Code)
+	}
+	response = httptest.NewRecorder()
+	newUsageTestServer(t, &usageApplicationStub{}, &writeQuotaStub{}, ScopeJobRead).ServeHTTP(response, httptest.NewRequest(http.MethodPost, UsagePath, nil))
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != http.MethodGet {
+		t.Fatalf("POST status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+}
@@ -0,0 +1,159 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+type WebhookDeliveryApplication interface {
+	ListWebhookDeliveries(context.Context, string, external.WebhookDeliveryListOptions) (external.WebhookDeliveryListResult, error)
+	RedeliverWebhook(context.Context, string, string) (external.WebhookDeliverySummary, error)
+	PingCallback(context.Context, string, string, time.Duration) (external.WebhookPing, error)
+}
+
+// callbackPingWait bounds how long POST /api/v1/callbacks/{callbackId}/ping
+// holds the request open for the single delivery attempt.
+const callbackPingWait = 20 * time.Second
+
+func WithWebhookDeliveryApplication(application WebhookDeliveryApplication) ServerOption {
+	return func(server *Server) error {
+		if application == nil {
+			return errors.New("webhook delivery application is required")
+		}
+		server.webhookDeliveries = application
+		return nil
+	}
+}
+
+func (server *Server) serveWebhookDeliveryCollection(response http.ResponseWriter, request *http.Request, requestID string) {
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET for this resource.", requestID))
+		return
+	}
+	principal, ok := server.authenticate(response, request, requestID, ScopeCallbackWrite)
+	if !ok {
+		return
+	}
+	options, err := parseWebhookDeliveryListQuery(request)
+	if err != nil {
+		writeProblem(response, problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use only cursor, limit (1-100), and a documented delivery status.", requestID))
+		return
+	}
+	page, err := server.webhookDeliveries.ListWebhookDeliveries(request.Context(), principal.TenantID, options)
+	if err != nil {
+		writeWebhookDeliveryProblem(response, requestID, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []external.WebhookDeliverySummary{}
+	}
+	writeJSON(response, http.StatusOK, page)
+}
+
+func parseWebhookDeliveryListQuery(request *http.Request) (external.WebhookDeliveryListOptions, error) {
+	values, err := url.ParseQuery(request.URL.RawQuery)
+	if err != nil {
+		return external.WebhookDeliveryListOptions{}, external.ErrInvalidWebhookDeliveryQuery
+	}
+	for key, entries := range values {
+		switch key {
+		case "cursor", "limit", "status":
+		default:
+			return external.WebhookDeliveryListOptions{}, external.ErrInvalidWebhookDeliveryQuery
+		}
+		if len(entries) != 1 {
+			return external.WebhookDeliveryListOptions{}, external.ErrInvalidWebhookDeliveryQuery
+		}
+	}
+	options := external.WebhookDeliveryListOptions{Cursor: values.Get("cursor"), Limit: 50}
+	if len(options.Cursor) > 512 {
+		return external.WebhookDeliveryListOptions{}, external.ErrInvalidWebhookDeliveryQuery
+	}
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 100 {
+			return external.WebhookDeliveryListOptions{}, external.ErrInvalidWebhookDeliveryQuery
+		}
+		options.Limit = limit
+	}
+	if raw := values.Get("status"); raw != "" {
+		options.Status = external.WebhookDeliveryStatus(raw)
+		switch options.Status {
+		case external.WebhookDeliveryPending, external.WebhookDeliveryDelivering, external.WebhookDeliveryDelivered, external.WebhookDeliveryDead:
+		default:
+			return external.WebhookDeliveryListOptions{}, external.ErrInvalidWebhookDeliveryQuery
+		}
+	}
+	return options, nil
+}
+
+func (server *Server) serveWebhookDeliveryItem(response http.ResponseWriter, request *http.Request, requestID string) {
+	eventID, action, _ := strings.Cut(strings.TrimPrefix(request.URL.Path, "/api/v1/webhook-deliveries/"), "/")
+	if action == "redeliver" && request.Method != http.MethodPost {
+		response.Header().Set("Allow", http.MethodPost)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use POST for this resource.", requestID))
+		return
+	}
+	principal, ok := server.authenticate(response, request, requestID, ScopeCallbackWrite)
+	if !ok {
+		return
+	}
+	if eventID == "" || action != "redeliver" || request.URL.RawQuery != "" {
+		writeWebhookDeliveryProblem(response, requestID, external.ErrWebhookDeliveryNotFound)
+		return
+	}
+	closeUnreadRequestBody(response, request, http.NewResponseController(response))
+	delivery, err := server.webhookDeliveries.RedeliverWebhook(request.Context(), principal.TenantID, eventID)
+	if err != nil {
+		writeWebhookDeliveryProblem(response, requestID, err)
+		return
+	}
+	writeJSON(response, http.StatusAccepted, delivery)
+}
+
+// handleCallbackPing answers 200 once the ping settled and 202 when the
+// worker has not settled it within callbackPingWait; the outcome then
+// appears in the delivery log under the returned event ID.
+func (server *Server) handleCallbackPing(response http.ResponseWriter, request *http.Request, requestID, tenantID, callbackID string) {
+	closeUnreadRequestBody(response, request, http.NewResponseController(response))
+	ping, err := server.webhookDeliveries.PingCallback(request.Context(), tenantID, callbackID, min(callbackPingWait, server.jobSubmitTimeout))
+	if err != nil {
+		writeWebhookDeliveryProblem(response, requestID, err)
+		return
+	}
+	if !ping.Settled() {
+		writeJSON(response, http.StatusAccepted, ping)
+		return
+	}
+	writeJSON(response, http.StatusOK, ping)
+}
+
+func writeWebhookDeliveryProblem(response http.ResponseWriter, requestID string, err error) {
+	problem := problemFor(http.StatusServiceUnavailable, "webhook-delivery-unavailable", "Webhook delivery log unavailable", "The webhook delivery operation could not be completed.", requestID)
+	switch {
+	case errors.Is(err, external.ErrInvalidWebhookDeliveryCursor), errors.Is(err, external.ErrInvalidWebhookDeliveryQuery):
+		problem = problemFor(http.StatusBadRequest, "invalid-list-query", "Invalid list query", "Use an untampered cursor issued for this tenant and filter.", requestID)
+	case errors.Is(err, external.ErrWebhookDeliveryNotDead):
+		problem = problemFor(http.StatusConflict, "webhook-delivery-not-dead", "Webhook delivery not dead-lettered", "Only DEAD deliveries can be redelivered; list deliveries to see the current status.", requestID)
+	case errors.Is(err, external.ErrWebhookDeliverySuperseded):
+		problem = problemFor(http.StatusConflict, "webhook-delivery-superseded", "Webhook delivery superseded", "A newer event of this job replaced this delivery, so it cannot be redelivered.", requestID)
+	case errors.Is(err, external.ErrWebhookCallbackDisabled):
+		problem = problemFor(http.StatusConflict, "callback-disabled", "Callback disabled", "The callback of this delivery is disabled and cannot receive redeliveries.", requestID)
+	case errors.Is(err, external.ErrWebhookPingInProgress):
+		problem = problemFor(http.StatusConflict, "webhook-ping-in-progress", "Webhook ping in progress", "An earlier ping of this callback has not settled yet; list deliveries to see its outcome.", requestID)
+	case errors.Is(err, external.ErrWebhookDeliveryNotFound), errors.Is(err, external.ErrCallbackNotFound):
+		problem = problemFor(http.StatusNotFound, "not-found", "Resource not found", "The requested API resource does not exist.", requestID)
+	}
+	if problem.Status == http.StatusServiceUnavailable {
+		response.Header().Set("Retry-After", "5")
+	}
+	writeProblem(response, problem)
+}
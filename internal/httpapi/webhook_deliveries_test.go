@@ -0,0 +1,250 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+type webhookDeliveryApplicationStub struct {
+	page       external.WebhookDeliveryListResult
+	delivery   external.WebhookDeliverySummary
+	ping       external.WebhookPing
+	err        error
+	tenantID   string
+	eventID    string
+	callbackID string
+	wait       time.Duration
+	options    external.WebhookDeliveryListOptions
+	operation  string
+}
+
+func (application *webhookDeliveryApplicationStub) ListWebhookDeliveries(_ context.Context, tenantID string, options external.WebhookDeliveryListOptions) (external.WebhookDeliveryListResult, error) {
+	application.operation, application.tenantID, application.options = "list", tenantID, options
+	return application.page, application.err
+}
+
+func (application *webhookDeliveryApplicationStub) RedeliverWebhook(_ context.Context, tenantID, eventID string) (external.WebhookDeliverySummary, error) {
+	application.operation, application.tenantID, application.eventID = "redeliver", tenantID, eventID
+	return application.delivery, application.err
+}
+
+func (application *webhookDeliveryApplicationStub) PingCallback(_ context.Context, tenantID, callbackID string, wait time.Duration) (external.WebhookPing, error) {
+	application.operation, application.tenantID, application.callbackID, application.wait = "ping", tenantID, callbackID, wait
+	return application.ping, application.err
+}
+
+func TestWebhookDeliveryListParsesFiltersAndNeverReturnsNull(t *testing.T) {
+	application := &webhookDeliveryApplicationStub{}
+	server := newWebhookDeliveryTestServer(t, ScopeCallbackWrite, application)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries?status=DEAD&limit=5&cursor=abc", nil))
+	if response.Code != http.StatusOK || strings.TrimSpace(response.Body.String()) != `{"items":[]}` ||
+		response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+	if application.tenantID != "tenant-7" || application.options != (external.WebhookDeliveryListOptions{Cursor: "abc", Limit: 5, Status: external.WebhookDeliveryDead}) {
+		t.Fatalf("application=%+v", application)
+	}
+	for _, query := range []string{"?status=FAILED", "?limit=0", "?limit=101", "?status=DEAD&status=PENDING", "?jobId=x", "?cursor=" + strings.Repeat("a", 513)} {
+		application := &webhookDeliveryApplicationStub{}
+		server := newWebhookDeliveryTestServer(t, ScopeCallbackWrite, application)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries"+query, nil))
+		if response.Code != http.StatusBadRequest || application.operation != "" || !strings.Contains(response.Body.String(), "/problems/invalid-list-query") {
+			t.Fatalf("%s: status=%d operation=%q body=%s", query, response.Code, application.operation, response.Body.String())
+		}
+	}
+}
+
+func TestWebhookDeliveryRedeliverReturnsTheRescheduledDelivery(t *testing.T) {
+	application := &webhookDeliveryApplicationStub{delivery: testWebhookDeliverySummary(external.WebhookDeliveryPending)}
+	server := newWebhookDeliveryTestServer(t, ScopeCallbackWrite, application)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil))
+	var view external.WebhookDeliverySummary
+	if response.Code != http.StatusAccepted || json.Unmarshal(response.Body.Bytes(), &view) != nil ||
+		view.Status != external.WebhookDeliveryPending || view.NextAttemptAt == nil {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+	if application.operation != "redeliver" || application.tenantID != "tenant-7" || application.eventID != "aaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Fatalf("application=%+v", application)
+	}
+}
+
+func TestWebhookDeliveryErrorsMapToProblems(t *testing.T) {
+	for _, test := range []struct {
+		err        error
+		status     int
+		kind       string
+		retryAfter string
+	}{
+		{err: external.ErrWebhookDeliveryNotDead, status: http.StatusConflict, kind: "webhook-delivery-not-dead"},
+		{err: external.ErrWebhookDeliverySuperseded, status: http.StatusConflict, kind: "webhook-delivery-superseded"},
+		{err: external.ErrWebhookCallbackDisabled, status: http.StatusConflict, kind: "callback-disabled"},
+		{err: external.ErrWebhookDeliveryNotFound, status: http.StatusNotFound, kind: "not-found"},
+		{err: errors.New("database is down"), status: http.StatusServiceUnavailable, kind: "webhook-delivery-unavailable", retryAfter: "5"},
+	} {
+		server := newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: test.err})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), "/problems/"+test.kind) ||
+			response.Header().Get("Retry-After") != test.retryAfter || strings.Contains(response.Body.String(), "database") {
+			t.Fatalf("%v: status=%d retry=%q body=%s", test.err, response.Code, response.Header().Get("Retry-After"), response.Body.String())
+		}
+	}
+	server := newWebhookDeliveryTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: external.ErrInvalidWebhookDeliveryCursor})
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries?cursor=forged", nil))
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "untampered cursor") {
+		t.Fatalf("forged cursor status=%d body=%s", response.Code, response.Body.String())
+	}
+}
+
+func TestWebhookDeliveryRoutesRequireCallbackWriteAndKnownMethods(t *testing.T) {
+	application := &webhookDeliveryApplicationStub{}
+	server := newWebhookDeliveryTestServer(t, ScopeJobRead, application)
+	for _, test := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/webhook-deliveries"},
+		{http.MethodPost, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver"},
+	} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != http.StatusForbidden || application.operation != "" {
+			t.Fatalf("%s %s: status=%d operation=%q", test.method, test.path, response.Code, application.operation)
+		}
+	}
+	server = newWebhookDeliveryTestServer(t, ScopeCallbackWrite, application)
+	for _, test := range []struct{ method, path, allow string }{
+		{http.MethodPost, "/api/v1/webhook-deliveries", "GET"},
+		{http.MethodGet, "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/redeliver", "POST"},
+	} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != test.allow {
+			t.Fatalf("%s %s: status=%d allow=%q", test.method, test.path, response.Code, response.Header().Get("Allow"))
+		}
+	}
+	for _, path := range []string{"/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa", "/api/v1/webhook-deliveries/aaaaaaaaaaaaaaaaaaaaaaaaaa/retry"} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, path, nil))
+		if response.Code != http.StatusNotFound || application.operation != "" {
+			t.Fatalf("%s: status=%d operation=%q", path, response.Code, application.operation)
+		}
+	}
+}
+
+func TestCallbackPingReportsTheSettledOutcomeOrAcceptsAPendingPing(t *testing.T) {
+	for _, test := range []struct {
+		ping   external.WebhookPing
+		status int
+	}{
+		{testWebhookPing(external.WebhookDeliveryDelivered), http.StatusOK},
+		{testWebhookPing(external.WebhookDeliveryDelivering), http.StatusAccepted},
+	} {
+		application := &webhookDeliveryApplicationStub{ping: test.ping}
+		server := newCallbackPingTestServer(t, ScopeCallbackWrite, application)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil))
+		var view external.WebhookPing
+		if response.Code != test.status || json.Unmarshal(response.Body.Bytes(), &view) != nil || view.Status != test.ping.Status ||
+			response.Header().Get("Cache-Control") != "no-store" {
+			t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+		}
+		if application.operation != "ping" || application.tenantID != "tenant-7" || application.callbackID != "cbcbcbcbcbcbcbcbcbcbcbcbcb" ||
+			application.wait != callbackPingWait {
+			t.Fatalf("application=%+v", application)
+		}
+	}
+	for _, test := range []struct {
+		err    error
+		status int
+		kind   string
+	}{
+		{external.ErrWebhookPingInProgress, http.StatusConflict, "webhook-ping-in-progress"},
+		{external.ErrCallbackNotFound, http.StatusNotFound, "not-found"},
+		{errors.New("database is down"), http.StatusServiceUnavailable, "webhook-delivery-unavailable"},
+	} {
+		server := newCallbackPingTestServer(t, ScopeCallbackWrite, &webhookDeliveryApplicationStub{err: test.err})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), "/problems/"+test.kind) {
+			t.Fatalf("%v: status=%d body=%s", test.err, response.Code, response.Body.String())
+		}
+	}
+	application := &webhookDeliveryApplicationStub{}
+	server := newCallbackPingTestServer(t, ScopeJobRead, application)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil))
+	if response.Code != http.StatusForbidden || application.operation != "" {
+		t.Fatalf("unscoped ping status=%d operation=%q", response.Code, application.operation)
+	}
+	server = newCallbackPingTestServer(t, ScopeCallbackWrite, application)
+	response = httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil))
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != "POST" || application.operation != "" {
+		t.Fatalf("GET ping status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+	withoutLog := newCallbackTestServer(t, ScopeCallbackWrite, &callbackApplicationStub{})
+	response = httptest.NewRecorder()
+	withoutLog.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/cbcbcbcbcbcbcbcbcbcbcbcbcb/ping", nil))
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("ping without a delivery application status=%d", response.Code)
+	}
+}
+
+func newCallbackPingTestServer(t *testing.T, scope Scope, application WebhookDeliveryApplication) *Server {
+	t.Helper()
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{scope: {}}}}, testCapabilities(),
+		WithCallbackApplication(&callbackApplicationStub{}), WithWebhookDeliveryApplication(application))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func newWebhookDeliveryTestServer(t *testing.T, scope Scope, application WebhookDeliveryApplication) *Server {
+	t.Helper()
+	server, err := NewServer(staticAuthenticator{principal: Principal{TenantID: "tenant-7", scopes: map[Scope]struct{}{scope: {}}}}, testCapabilities(),
+		WithWebhookDeliveryApplication(application))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func testWebhookDeliverySummary(status external.WebhookDeliveryStatus) external.WebhookDeliverySummary {
+	created := time.Date(2026, 7, 19, 1, 2, 3, 0, time.UTC)
+	httpStatus := http.StatusServiceUnavailable
+	summary := external.WebhookDeliverySummary{
+		EventID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", EventType: "judge.job.completed",
+		JobID: "ceirceirceirceirceirceirce", CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb",
+		Status: status, AttemptCount: 12, LastHTTPStatus: &httpStatus, LastErrorCode: external.WebhookErrorHTTPRetryable,
+		CreatedAt: created,
+	}
+	switch status {
+	case external.WebhookDeliveryPending:
+		next := created.Add(time.Hour)
+		summary.AttemptCount, summary.NextAttemptAt = 0, &next
+	case external.WebhookDeliveryDead:
+		dead := created.Add(time.Hour)
+		summary.DeadAt = &dead
+	}
+	return summary
+}
+
+func testWebhookPing(status external.WebhookDeliveryStatus) external.WebhookPing {
+	ping := external.WebhookPing{EventID: "aaaaaaaaaaaaaaaaaaaaaaaaaa", CallbackID: "cbcbcbcbcbcbcbcbcbcbcbcbcb", Status: status}
+	if status == external.WebhookDeliveryDelivered {
+		httpStatus, latency, accepted := http.StatusNoContent, int64(37), true
+		ping.HTTPStatus, ping.LatencyMillis, ping.SignatureAccepted = &httpStatus, &latency, &accepted
+	}
+	return ping
+}
@@ -0,0 +1,37 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+// WebhookKeysPath publishes the Ed25519 keys behind v2 webhook signatures.
+const WebhookKeysPath = "/.well-known/croj-webhook-keys"
+
+type WebhookKeySource interface {
+	PublicKeys() external.WebhookPublicKeySet
+}
+
+// WithWebhookSigningKeys enables the unauthenticated JWK Set endpoint. Like
+// every other response it is no-store: receivers cache the set themselves and
+// refetch it when a signature names an unknown key ID.
+func WithWebhookSigningKeys(keys WebhookKeySource) ServerOption {
+	return func(server *Server) error {
+		if keys == nil {
+			return errors.New("webhook signing keys are required")
+		}
+		server.webhookKeys = keys
+		return nil
+	}
+}
+
+func (server *Server) handleWebhookKeys(response http.ResponseWriter, request *http.Request, requestID string) {
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		writeProblem(response, problemFor(http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", "Use GET for this resource.", requestID))
+		return
+	}
+	writeJSON(response, http.StatusOK, server.webhookKeys.PublicKeys())
+}
@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+)
+
+func testWebhookSigningKeys(t *testing.T) *external.WebhookSigningKeyRing {
+	t.Helper()
+	ring, err := external.NewWebhookSigningKeyRing("2026-10", map[string][]byte{
+		"2026-07": bytes.Repeat([]byte{1}, 32), "2026-10": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+func TestWebhookKeysArePublishedWithoutAuthentication(t *testing.T) {
+	authenticator := &recordingAuthenticator{}
+	server, err := NewServer(authenticator, testCapabilities(), WithWebhookSigningKeys(testWebhookSigningKeys(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, WebhookKeysPath, nil))
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/json" ||
+		response.Header().Get("Cache-Control") != "no-store" || authenticator.calls != 0 {
+		t.Fatalf("status=%d headers=%v authentications=%d", response.Code, response.Header(), authenticator.calls)
+	}
+	var set external.WebhookPublicKeySet
+	if err := json.Unmarshal(response.Body.Bytes(), &set); err != nil || len(set.Keys) != 2 ||
+		set.Keys[0].KeyID != "2026-07" || set.Keys[1].Curve != "Ed25519" || set.Keys[1].X == "" {
+		t.Fatalf("key set = %s error=%v", response.Body, err)
+	}
+
+	response = httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, WebhookKeysPath, nil))
+	if response.Code != http.StatusMethodNotAllowed || response.Header().Get("Allow") != http.MethodGet {
+		t.Fatalf("POST status=%d allow=%q", response.Code, response.Header().Get("Allow"))
+	}
+	if _, err := NewServer(authenticator, testCapabilities(), WithWebhookSigningKeys(nil)); err == nil {
+		t.Fatal("nil webhook key source was accepted")
+	}
+}
+
+func TestWebhookKeysAreNotFoundWhenV2SigningIsDisabled(t *testing.T) {
+	server, err := NewServer(staticAuthenticator{}, testCapabilities())
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, WebhookKeysPath, nil))
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("status = %d", response.Code)
+	}
+}
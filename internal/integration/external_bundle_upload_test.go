@@ -176,6 +176,14 @@ func (repository *integrationBundleRepository) FindBundle(_ context.Context, ten
 	return external.BundleMetadata{}, external.ErrBundleNotFound
 }
 
+func (repository *integrationBundleRepository) ListBundles(context.Context, string, external.BundleListQuery) ([]external.BundleSummary, error) {
+	return nil, nil
+}
+
+func (repository *integrationBundleRepository) RetireBundle(context.Context, string, string) error {
+	return external.ErrBundleNotFound
+}
+
 type integrationFileObjectStore struct {
 	root     string
 	mu       sync.Mutex
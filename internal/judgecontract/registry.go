@@ -15,9 +15,11 @@ type LanguageDefinition struct {
 type Checker string
 
 const (
-	CheckerExact   Checker = "exact"
-	CheckerToken   Checker = "token"
-	CheckerSpecial Checker = "special"
+	CheckerExact       Checker = "exact"
+	CheckerToken       Checker = "token"
+	CheckerSpecial     Checker = "special"
+	CheckerInteractive Checker = "interactive"
+	CheckerFloat       Checker = "float"
 )
 
 var canonicalLanguages = [...]LanguageDefinition{
@@ -28,7 +30,7 @@ var canonicalLanguages = [...]LanguageDefinition{
 	{PublicID: "javascript", SandboxID: "javascript", DisplayName: "JavaScript", Runtime: "node"},
 }
 
-var canonicalCheckers = [...]Checker{CheckerExact, CheckerToken, CheckerSpecial}
+var canonicalCheckers = [...]Checker{CheckerExact, CheckerToken, CheckerSpecial, CheckerInteractive, CheckerFloat}
 
 func CanonicalLanguages() []LanguageDefinition {
 	return append([]LanguageDefinition(nil), canonicalLanguages[:]...)
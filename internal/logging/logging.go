@@ -0,0 +1,129 @@
+// Package logging builds the process-wide log/slog logger. Every record
+// passes through a redacting handler that adds the correlation fields stored
+// in the context and refuses to serialize values that could carry contestant
+// source, hidden case data, API keys or callback secrets.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Correlation keys shared by every component so one request, job attempt or
+// webhook delivery can be followed across log lines.
+const (
+	KeyRequestID = "request_id"
+	KeyTenant    = "tenant"
+	KeyJob       = "job"
+	KeyAttempt   = "attempt"
+	KeyWorker    = "worker"
+)
+
+// Config selects the minimum level (debug, info, warn, error) and the output
+// format (json or text). Empty values select info and json.
+type Config struct {
+	Level  string
+	Format string
+}
+
+// New returns a logger writing to output through the redacting handler.
+func New(output io.Writer, config Config) (*slog.Logger, error) {
+	if output == nil {
+		return nil, fmt.Errorf("log output is required")
+	}
+	level, err := parseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(config.Format)) {
+	case "", "json":
+		handler = slog.NewJSONHandler(output, options)
+	case "text":
+		handler = slog.NewTextHandler(output, options)
+	default:
+		return nil, fmt.Errorf("log format must be json or text")
+	}
+	return slog.New(NewRedactingHandler(handler)), nil
+}
+
+// Setup installs the logger as the slog default. The standard library log
+// package is routed through it as well, so remaining log.Printf callers in
+// dependencies are still structured and redacted.
+func Setup(config Config) error {
+	logger, err := New(os.Stderr, config)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// Validate reports whether config would be accepted by New.
+func Validate(config Config) error {
+	_, err := New(io.Discard, config)
+	return err
+}
+
+func parseLevel(raw string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("log level must be debug, info, warn or error")
+	}
+}
+
+type contextKey struct{}
+
+// With returns ctx carrying additional correlation attributes. A later value
+// for the same key replaces the earlier one, so a worker can refine the
+// context it inherited from its claim loop.
+func With(ctx context.Context, attributes ...slog.Attr) context.Context {
+	if len(attributes) == 0 {
+		return ctx
+	}
+	existing := fromContext(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attributes))
+	for _, attribute := range existing {
+		if !containsKey(attributes, attribute.Key) {
+			merged = append(merged, attribute)
+		}
+	}
+	merged = append(merged, attributes...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+func fromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attributes, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attributes
+}
+
+func containsKey(attributes []slog.Attr, key string) bool {
+	for _, attribute := range attributes {
+		if attribute.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func RequestID(value string) slog.Attr { return slog.String(KeyRequestID, value) }
+func Tenant(value string) slog.Attr    { return slog.String(KeyTenant, value) }
+func Job(value string) slog.Attr       { return slog.String(KeyJob, value) }
+func Attempt(value uint32) slog.Attr   { return slog.Uint64(KeyAttempt, uint64(value)) }
+func Worker(value string) slog.Attr    { return slog.String(KeyWorker, value) }
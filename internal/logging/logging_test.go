@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type caseFile struct {
+	Input  string
+	Output string
+}
+
+type verdict string
+
+func decodeRecord(t *testing.T, output *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+		t.Fatalf("decode record %q: %v", output.String(), err)
+	}
+	return record
+}
+
+func TestNewValidatesLevelAndFormat(t *testing.T) {
+	for _, config := range []Config{{}, {Level: "debug", Format: "text"}, {Level: "WARNING", Format: "JSON"}, {Level: "error"}} {
+		if err := Validate(config); err != nil {
+			t.Fatalf("Validate(%+v) error = %v", config, err)
+		}
+	}
+	for _, config := range []Config{{Level: "trace"}, {Format: "logfmt"}} {
+		if err := Validate(config); err == nil {
+			t.Fatalf("Validate(%+v) accepted an invalid config", config)
+		}
+	}
+	if _, err := New(nil, Config{}); err == nil {
+		t.Fatal("New() accepted a nil output")
+	}
+	var output bytes.Buffer
+	logger, err := New(&output, Config{Level: "warn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("suppressed")
+	if output.Len() != 0 {
+		t.Fatalf("info record written at warn level: %s", output.String())
+	}
+}
+
+func TestHandlerRedactsPayloadTypes(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := New(&output, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("payload",
+		"source", []byte("int main() { return 0; }"),
+		"raw", json.RawMessage(`{"secret":"value"}`),
+		"case", caseFile{Input: "1 2", Output: "3"},
+		"cases", []caseFile{{Input: "4"}},
+		"headers", map[string]string{"Authorization": "Bearer croj_live_secret"},
+		"error", errors.New("sandbox unavailable"),
+		"status", verdict("ACCEPTED"),
+		"count", 3,
+		slog.Group("nested", slog.Any("body", []byte("hidden")), slog.String("kind", "job")),
+	)
+	text := output.String()
+	for _, leaked := range []string{"int main", "secret", "1 2", "Bearer", "hidden"} {
+		if strings.Contains(text, leaked) {
+			t.Fatalf("record leaked %q: %s", leaked, text)
+		}
+	}
+	record := decodeRecord(t, &output)
+	if record["source"] != "[REDACTED 24 BYTES]" || record["raw"] != "[REDACTED 18 BYTES]" {
+		t.Fatalf("byte payloads = %v, %v", record["source"], record["raw"])
+	}
+	if record["case"] != "[REDACTED logging.caseFile]" || record["cases"] != "[REDACTED []logging.caseFile]" {
+		t.Fatalf("composite payloads = %v, %v", record["case"], record["cases"])
+	}
+	if record["error"] != "sandbox unavailable" || record["status"] != "ACCEPTED" || record["count"] != float64(3) {
+		t.Fatalf("scalar values were not preserved: %v", record)
+	}
+	nested, _ := record["nested"].(map[string]any)
+	if nested["body"] != "[REDACTED 6 BYTES]" || nested["kind"] != "job" {
+		t.Fatalf("nested group = %v", record["nested"])
+	}
+}
+
+func TestHandlerRedactsSensitiveKeysAndErrorText(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := New(&output, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leakedKey := "croj_live_plainstringsecret"
+	leakedDSN := "judge:dsnpassword@tcp(mysql:3306)/croj"
+	logger.Info("request",
+		"api_key", leakedKey,
+		"sourceCode", "int main() { return 0; }",
+		"Authorization", "Bearer plainbearer",
+		"lease_token", 42,
+		slog.Group("callback", slog.String("secret", "croj_whsec_grouped")),
+		"source_bytes", 24,
+		"active_key_id", "key-1",
+		"error", fmt.Errorf("deliver webhook: %w", fmt.Errorf("sign with %s: %w", "croj_whsec_wrapped", errors.New("rejected"))),
+		"cause", fmt.Errorf("open database %s: %w", leakedDSN, errors.New("refused")),
+		"header", fmt.Errorf("upstream echoed Authorization: Bearer echoedbearer token=querytoken"),
+	)
+	text := output.String()
+	for _, leaked := range []string{"plainstringsecret", "int main", "plainbearer", "croj_whsec_grouped", "croj_whsec_wrapped", "dsnpassword", "echoedbearer", "querytoken"} {
+		if strings.Contains(text, leaked) {
+			t.Fatalf("record leaked %q: %s", leaked, text)
+		}
+	}
+	record := decodeRecord(t, &output)
+	if record["api_key"] != "[REDACTED 27 BYTES]" || record["Authorization"] != "[REDACTED 18 BYTES]" || record["lease_token"] != "[REDACTED]" {
+		t.Fatalf("sensitive keys = %v, %v, %v", record["api_key"], record["Authorization"], record["lease_token"])
+	}
+	if record["source_bytes"] != float64(24) || record["active_key_id"] != "key-1" {
+		t.Fatalf("metadata keys were redacted: %v", record)
+	}
+	if record["error"] != "deliver webhook: sign with croj_[REDACTED]: rejected" ||
+		record["cause"] != "open database judge:[REDACTED]@tcp(mysql:3306)/croj: refused" {
+		t.Fatalf("error text = %q, %q", record["error"], record["cause"])
+	}
+}
+
+func TestHandlerAddsContextCorrelationAndTraceIDs(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := New(&output, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := With(context.Background(), RequestID("request-1"), Tenant("tenant-a"))
+	ctx = With(ctx, Tenant("tenant-b"), Job("job-1"), Attempt(2), Worker("worker-1"))
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+	ctx, span := provider.Tracer("test").Start(ctx, "operation")
+	defer span.End()
+
+	logger.InfoContext(ctx, "attempt started")
+	record := decodeRecord(t, &output)
+	expected := map[string]any{
+		KeyRequestID: "request-1", KeyTenant: "tenant-b", KeyJob: "job-1",
+		KeyAttempt: float64(2), KeyWorker: "worker-1",
+		"trace_id": span.SpanContext().TraceID().String(),
+		"span_id":  span.SpanContext().SpanID().String(),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Fatalf("%s = %v, want %v (record %v)", key, record[key], value, record)
+		}
+	}
+	if strings.Count(output.String(), `"tenant"`) != 1 {
+		t.Fatalf("replaced tenant was written twice: %s", output.String())
+	}
+}
@@ -0,0 +1,216 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedactingHandler enforces redaction by type and by key. A value is written
+// only when it is a scalar, a time, an error, a fmt.Stringer or a
+// slog.LogValuer; raw byte slices and every other composite value are
+// replaced with a marker. Types holding contestant or credential material
+// implement slog.LogValuer or fmt.Stringer to expose identifiers and sizes.
+// Independently of type, an attribute whose key names source code or a
+// credential is replaced unless its value is a slog.LogValuer, and
+// credential-shaped text is scrubbed from error messages, which routinely
+// wrap values the caller never inspected.
+type RedactingHandler struct {
+	next slog.Handler
+}
+
+func NewRedactingHandler(next slog.Handler) *RedactingHandler {
+	return &RedactingHandler{next: next}
+}
+
+func (handler *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	for _, attribute := range fromContext(ctx) {
+		redacted.AddAttrs(redactAttr(attribute))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		redacted.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attribute))
+		return true
+	})
+	return handler.next.Handle(ctx, redacted)
+}
+
+func (handler *RedactingHandler) WithAttrs(attributes []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attributes))
+	for _, attribute := range attributes {
+		redacted = append(redacted, redactAttr(attribute))
+	}
+	return &RedactingHandler{next: handler.next.WithAttrs(redacted)}
+}
+
+func (handler *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: handler.next.WithGroup(name)}
+}
+
+// sensitiveKeyWords name attribute keys whose values are never logged. A key
+// matches when any of its words does, so "api_key", "sourceCode" and
+// "Authorization" all match.
+var sensitiveKeyWords = map[string]struct{}{
+	"authorization": {}, "cookie": {}, "credential": {}, "credentials": {},
+	"dsn": {}, "key": {}, "passphrase": {}, "password": {}, "plaintext": {},
+	"secret": {}, "signature": {}, "source": {}, "token": {},
+}
+
+// metadataKeyWords end keys that describe a sensitive value without holding
+// it, such as "source_bytes" or "active_key_id".
+var metadataKeyWords = map[string]struct{}{
+	"bytes": {}, "count": {}, "hash": {}, "id": {}, "kind": {}, "len": {},
+	"length": {}, "prefix": {}, "sha256": {}, "size": {}, "type": {},
+}
+
+// errorSecretPatterns match credentials that error messages pick up from
+// wrapped URLs, DSNs and headers: issued API keys and webhook secrets share
+// the croj_ prefix.
+var errorSecretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`croj_[A-Za-z0-9_-]+`), "croj_[REDACTED]"},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[^\s,;"']+`), "$1 [REDACTED]"},
+	{regexp.MustCompile(`(?i)\b(password|passwd|secret|token|key|signature)=[^\s&,;"']+`), "$1=[REDACTED]"},
+	{regexp.MustCompile(`([^\s:/@]+):[^\s:/@]+@`), "$1:[REDACTED]@"},
+}
+
+// redactAttr trusts a slog.LogValuer under a sensitive key, because such a
+// type already chose which identifiers of its secret material to expose.
+func redactAttr(attribute slog.Attr) slog.Attr {
+	if attribute.Value.Kind() != slog.KindLogValuer && sensitiveKey(attribute.Key) {
+		return slog.Attr{Key: attribute.Key, Value: redactSensitiveValue(attribute.Value)}
+	}
+	return slog.Attr{Key: attribute.Key, Value: redactValue(attribute.Value)}
+}
+
+func sensitiveKey(key string) bool {
+	words := keyWords(key)
+	if len(words) == 0 {
+		return false
+	}
+	if _, metadata := metadataKeyWords[words[len(words)-1]]; metadata {
+		return false
+	}
+	for _, word := range words {
+		if _, sensitive := sensitiveKeyWords[word]; sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// keyWords splits a key on punctuation and camelCase boundaries and
+// lowercases the words.
+func keyWords(key string) []string {
+	var words []string
+	var word strings.Builder
+	previous := rune(0)
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, character := range key {
+		switch {
+		case !unicode.IsLetter(character) && !unicode.IsDigit(character):
+			flush()
+		case unicode.IsUpper(character) && unicode.IsLower(previous):
+			flush()
+			word.WriteRune(unicode.ToLower(character))
+		default:
+			word.WriteRune(unicode.ToLower(character))
+		}
+		previous = character
+	}
+	flush()
+	return words
+}
+
+// redactSensitiveValue keeps only the size of a value stored under a
+// sensitive key.
+func redactSensitiveValue(value slog.Value) slog.Value {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return redactedBytes(len(value.String()))
+	case slog.KindAny:
+		if bytes := reflect.ValueOf(value.Any()); bytes.Kind() == reflect.Slice && bytes.Type().Elem().Kind() == reflect.Uint8 {
+			return redactedBytes(bytes.Len())
+		}
+	}
+	return slog.StringValue("[REDACTED]")
+}
+
+func redactErrorText(text string) string {
+	for _, secret := range errorSecretPatterns {
+		text = secret.pattern.ReplaceAllString(text, secret.replacement)
+	}
+	return text
+}
+
+func redactValue(value slog.Value) slog.Value {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, 0, len(group))
+		for _, attribute := range group {
+			redacted = append(redacted, redactAttr(attribute))
+		}
+		return slog.GroupValue(redacted...)
+	case slog.KindAny:
+		return redactAny(value.Any())
+	default:
+		return value
+	}
+}
+
+func redactAny(value any) slog.Value {
+	if value == nil {
+		return slog.AnyValue(nil)
+	}
+	valueType := reflect.TypeOf(value)
+	if valueType.Kind() == reflect.Slice && valueType.Elem().Kind() == reflect.Uint8 {
+		// Checked before fmt.Stringer: json.RawMessage prints its content.
+		return redactedBytes(reflect.ValueOf(value).Len())
+	}
+	switch typed := value.(type) {
+	case error:
+		return slog.StringValue(redactErrorText(typed.Error()))
+	case fmt.Stringer:
+		return slog.StringValue(typed.String())
+	case []string:
+		return slog.AnyValue(typed)
+	}
+	switch valueType.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		// Named scalars such as external.JobStatus carry no payload.
+		return slog.AnyValue(value)
+	}
+	return slog.StringValue(fmt.Sprintf("[REDACTED %T]", value))
+}
+
+func redactedBytes(length int) slog.Value {
+	return slog.StringValue(fmt.Sprintf("[REDACTED %d BYTES]", length))
+}
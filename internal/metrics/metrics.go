@@ -0,0 +1,187 @@
+// Package metrics owns the process-wide Prometheus registry. Instrumented
+// packages record through the functions below so every label value comes from
+// a closed set chosen at the call site, never from request or contestant data.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "croj"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	jobAdmissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "external", Name: "job_admissions_total",
+		Help: "Judge job submissions by admission outcome.",
+	}, []string{"outcome"})
+	workerClaimDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "worker", Name: "claim_duration_seconds",
+		Help:    "Round-trip latency of one durable job claim attempt.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"outcome"})
+	workerQueueWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "worker", Name: "queue_wait_seconds",
+		Help:    "Time between job submission and a successful claim, both on the database clock.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 16),
+	})
+	sandboxBatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "sandbox", Name: "batch_duration_seconds",
+		Help:    "Latency of one compile-once sandbox stream against one endpoint.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 14),
+	}, []string{"kind", "outcome"})
+	sandboxBatchRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "sandbox", Name: "batch_retries_total",
+		Help: "Sandbox streams retried on another endpoint, by gRPC reason.",
+	}, []string{"kind", "reason"})
+	bundleCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "bundle_cache", Name: "lookups_total",
+		Help: "Bundle cache resolutions by result.",
+	}, []string{"result"})
+	bundleCacheEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "bundle_cache", Name: "evictions_total",
+		Help: "Bundle cache entries removed, by reason.",
+	}, []string{"reason"})
+	webhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "webhook", Name: "deliveries_total",
+		Help: "Webhook delivery attempts by settled disposition and error code.",
+	}, []string{"disposition", "error_code"})
+	legacyConsumes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "legacy", Name: "messages_consumed_total",
+		Help: "RocketMQ SubmissionRequested messages by consume result.",
+	}, []string{"result"})
+	legacyCallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "legacy", Name: "callbacks_total",
+		Help: "Backend judge result callbacks by disposition.",
+	}, []string{"disposition"})
+
+	queueDepth = &queueDepthCollector{
+		description: prometheus.NewDesc(namespace+"_external_queue_depth",
+			"Queued and running judge jobs per tenant, read from MySQL at scrape time.",
+			[]string{"tenant", "status"}, nil),
+	}
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		jobAdmissions, workerClaimDuration, workerQueueWait,
+		sandboxBatchDuration, sandboxBatchRetries,
+		bundleCacheLookups, bundleCacheEvictions,
+		webhookDeliveries, legacyConsumes, legacyCallbacks,
+		queueDepth,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Gatherer exposes the registry to callers that merge or inspect it.
+func Gatherer() prometheus.Gatherer {
+	return registry
+}
+
+func RecordJobAdmission(outcome string) {
+	jobAdmissions.WithLabelValues(outcome).Inc()
+}
+
+func ObserveWorkerClaim(outcome string, elapsed time.Duration) {
+	workerClaimDuration.WithLabelValues(outcome).Observe(elapsed.Seconds())
+}
+
+func ObserveQueueWait(wait time.Duration) {
+	if wait >= 0 {
+		workerQueueWait.Observe(wait.Seconds())
+	}
+}
+
+func ObserveSandboxBatch(kind, outcome string, elapsed time.Duration) {
+	sandboxBatchDuration.WithLabelValues(kind, outcome).Observe(elapsed.Seconds())
+}
+
+func RecordSandboxRetry(kind, reason string) {
+	sandboxBatchRetries.WithLabelValues(kind, reason).Inc()
+}
+
+func RecordBundleCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	bundleCacheLookups.WithLabelValues(result).Inc()
+}
+
+func RecordBundleCacheEviction(reason string) {
+	bundleCacheEvictions.WithLabelValues(reason).Inc()
+}
+
+func RecordWebhookDelivery(disposition, errorCode string) {
+	webhookDeliveries.WithLabelValues(strings.ToLower(disposition), errorCode).Inc()
+}
+
+func RecordLegacyConsume(result string) {
+	legacyConsumes.WithLabelValues(result).Inc()
+}
+
+func RecordLegacyCallback(disposition string) {
+	legacyCallbacks.WithLabelValues(strings.ToLower(disposition)).Inc()
+}
+
+// TenantQueueDepth is one tenant's count of jobs in a non-terminal status.
+type TenantQueueDepth struct {
+	Tenant string
+	Status string
+	Jobs   int
+}
+
+// SetQueueDepthSource installs the scrape-time reader for per-tenant queue
+// depth. Runtimes without durable jobs leave it unset and export no series.
+func SetQueueDepthSource(source func(context.Context) ([]TenantQueueDepth, error)) {
+	queueDepth.mutex.Lock()
+	defer queueDepth.mutex.Unlock()
+	queueDepth.source = source
+}
+
+const queueDepthTimeout = 2 * time.Second
+
+type queueDepthCollector struct {
+	description *prometheus.Desc
+	mutex       sync.Mutex
+	source      func(context.Context) ([]TenantQueueDepth, error)
+}
+
+func (collector *queueDepthCollector) Describe(descriptions chan<- *prometheus.Desc) {
+	descriptions <- collector.description
+}
+
+// Collect reports nothing when the source fails, so a database outage shows
+// as missing series instead of a misleading zero backlog.
+func (collector *queueDepthCollector) Collect(metrics chan<- prometheus.Metric) {
+	collector.mutex.Lock()
+	source := collector.source
+	collector.mutex.Unlock()
+	if source == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), queueDepthTimeout)
+	defer cancel()
+	depths, err := source(ctx)
+	if err != nil {
+		return
+	}
+	for _, depth := range depths {
+		metrics <- prometheus.MustNewConstMetric(collector.description, prometheus.GaugeValue, float64(depth.Jobs), depth.Tenant, depth.Status)
+	}
+}
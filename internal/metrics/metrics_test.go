@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandlerExposesRecordedSeries(t *testing.T) {
+	RecordJobAdmission("accepted")
+	ObserveWorkerClaim("claimed", 20*time.Millisecond)
+	ObserveSandboxBatch("batch", "completed", time.Second)
+	RecordSandboxRetry("batch", "unavailable")
+	RecordBundleCacheLookup(true)
+	RecordBundleCacheEviction("ttl")
+	RecordWebhookDelivery("PERMANENT_FAILURE", "http_permanent")
+	RecordLegacyConsume("processed")
+	RecordLegacyCallback("APPLIED")
+
+	response := httptest.NewRecorder()
+	Handler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := response.Body.String()
+	for _, series := range []string{
+		`croj_external_job_admissions_total{outcome="accepted"}`,
+		`croj_worker_claim_duration_seconds_count{outcome="claimed"}`,
+		`croj_sandbox_batch_duration_seconds_count{kind="batch",outcome="completed"}`,
+		`croj_sandbox_batch_retries_total{kind="batch",reason="unavailable"}`,
+		`croj_bundle_cache_lookups_total{result="hit"}`,
+		`croj_bundle_cache_evictions_total{reason="ttl"}`,
+		`croj_webhook_deliveries_total{disposition="permanent_failure",error_code="http_permanent"}`,
+		`croj_legacy_messages_consumed_total{result="processed"}`,
+		`croj_legacy_callbacks_total{disposition="applied"}`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, series) {
+			t.Fatalf("exposition is missing %s:\n%s", series, body)
+		}
+	}
+}
+
+func TestQueueDepthIsReadAtScrapeTimeAndOmittedOnFailure(t *testing.T) {
+	t.Cleanup(func() { SetQueueDepthSource(nil) })
+	if count := testutil.CollectAndCount(queueDepth); count != 0 {
+		t.Fatalf("unset source exported %d series", count)
+	}
+	SetQueueDepthSource(func(ctx context.Context) ([]TenantQueueDepth, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("queue depth source ran without a deadline")
+		}
+		return []TenantQueueDepth{{Tenant: "tenant-a", Status: "QUEUED", Jobs: 3}, {Tenant: "tenant-a", Status: "RUNNING", Jobs: 1}}, nil
+	})
+	expected := `
+# HELP croj_external_queue_depth Queued and running judge jobs per tenant, read from MySQL at scrape time.
+# TYPE croj_external_queue_depth gauge
+croj_external_queue_depth{status="QUEUED",tenant="tenant-a"} 3
+croj_external_queue_depth{status="RUNNING",tenant="tenant-a"} 1
+`
+	if err := testutil.CollectAndCompare(queueDepth, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+	SetQueueDepthSource(func(context.Context) ([]TenantQueueDepth, error) { return nil, errors.New("database down") })
+	if count := testutil.CollectAndCount(queueDepth); count != 0 {
+		t.Fatalf("failed source exported %d series", count)
+	}
+}
@@ -9,7 +9,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
 	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	_ "google.golang.org/grpc/balancer/roundrobin"
 	"google.golang.org/grpc/credentials/insecure"
@@ -34,20 +37,33 @@ func (guard *batchStreamGuard) observe(event *sandboxpb.ExecuteBatchV1Event) err
 	if event == nil {
 		return fmt.Errorf("%w: nil event", ErrInvalidBatchStream)
 	}
+	return guard.record(proto.Size(event),
+		event.Kind == sandboxpb.ExecuteBatchV1Event_COMPLETED || event.Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR)
+}
+
+func (guard *batchStreamGuard) observeInteractive(event *sandboxpb.ExecuteInteractiveV1Event) error {
+	if event == nil {
+		return fmt.Errorf("%w: nil event", ErrInvalidBatchStream)
+	}
+	return guard.record(proto.Size(event),
+		event.Kind == sandboxpb.ExecuteInteractiveV1Event_COMPLETED ||
+			event.Kind == sandboxpb.ExecuteInteractiveV1Event_COMPILE_ERROR ||
+			event.Kind == sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_COMPILE_ERROR)
+}
+
+func (guard *batchStreamGuard) record(size int, terminal bool) error {
 	if guard.terminal {
 		return fmt.Errorf("%w: event after terminal", ErrInvalidBatchStream)
 	}
 	guard.events++
-	guard.bytes += proto.Size(event)
+	guard.bytes += size
 	if guard.events > guard.maxEvents {
 		return fmt.Errorf("%w: event count exceeds %d", ErrInvalidBatchStream, guard.maxEvents)
 	}
 	if guard.bytes > guard.maxBytes {
 		return fmt.Errorf("%w: event bytes exceed %d", ErrInvalidBatchStream, guard.maxBytes)
 	}
-	if event.Kind == sandboxpb.ExecuteBatchV1Event_COMPLETED || event.Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR {
-		guard.terminal = true
-	}
+	guard.terminal = terminal
 	return nil
 }
 
@@ -101,13 +117,15 @@ func NewClientWithCache(timeout time.Duration, maxConnections int, idleTTL time.
 	}
 }
 
-func (c *Client) Execute(ctx context.Context, address string, request *sandboxpb.ExecuteRequest) (*sandboxpb.ExecuteResponse, error) {
+func (c *Client) Execute(ctx context.Context, address string, request *sandboxpb.ExecuteRequest) (_ *sandboxpb.ExecuteResponse, err error) {
 	if address == "" {
 		return nil, fmt.Errorf("sandbox address is required")
 	}
 	if request == nil {
 		return nil, fmt.Errorf("sandbox execute request is required")
 	}
+	ctx, span := startRPCSpan(ctx, "sandbox.Execute", address, 1)
+	defer func() { tracing.End(span, err) }()
 
 	entry, err := c.acquire(address)
 	if err != nil {
@@ -124,17 +142,23 @@ func (c *Client) Execute(ctx context.Context, address string, request *sandboxpb
 }
 
 func batchRPCTimeout(base time.Duration, request *sandboxpb.ExecuteBatchV1Request) time.Duration {
-	if request == nil || len(request.Cases) <= 1 {
+	if request == nil {
+		return base
+	}
+	return streamRPCTimeout(base, len(request.Cases), request.Timeout)
+}
+
+func streamRPCTimeout(base time.Duration, caseCount int, caseTimeoutSeconds int32) time.Duration {
+	if caseCount <= 1 {
 		return base
 	}
-	caseTimeoutSeconds := request.Timeout
 	if caseTimeoutSeconds <= 0 {
 		caseTimeoutSeconds = 1
 	}
 	if caseTimeoutSeconds > 30 {
 		caseTimeoutSeconds = 30
 	}
-	return base + time.Duration(len(request.Cases)-1)*time.Duration(caseTimeoutSeconds)*time.Second
+	return base + time.Duration(caseCount-1)*time.Duration(caseTimeoutSeconds)*time.Second
 }
 
 // ExecuteBatch runs one compile-once stream and returns events only after a clean EOF.
@@ -144,12 +168,26 @@ func (c *Client) ExecuteBatch(
 	address string,
 	request *sandboxpb.ExecuteBatchV1Request,
 ) ([]*sandboxpb.ExecuteBatchV1Event, error) {
+	return c.ExecuteBatchObserved(ctx, address, request, nil)
+}
+
+// ExecuteBatchObserved is ExecuteBatch that also passes each event within the
+// response bounds to observe as it arrives. Observed events are provisional:
+// the stream can still fail and be discarded after they were seen.
+func (c *Client) ExecuteBatchObserved(
+	ctx context.Context,
+	address string,
+	request *sandboxpb.ExecuteBatchV1Request,
+	observe func(*sandboxpb.ExecuteBatchV1Event),
+) (_ []*sandboxpb.ExecuteBatchV1Event, err error) {
 	if address == "" {
 		return nil, fmt.Errorf("sandbox address is required")
 	}
 	if request == nil {
 		return nil, fmt.Errorf("sandbox batch request is required")
 	}
+	ctx, span := startRPCSpan(ctx, "sandbox.ExecuteBatch", address, len(request.Cases))
+	defer func() { tracing.End(span, err) }()
 	entry, err := c.acquire(address)
 	if err != nil {
 		return nil, err
@@ -182,10 +220,94 @@ func (c *Client) ExecuteBatch(
 		if err := guard.observe(event); err != nil {
 			return nil, err
 		}
+		if observe != nil {
+			observe(event)
+		}
+		events = append(events, event)
+	}
+}
+
+// ExecuteInteractive runs one compile-once contestant/interactor stream with
+// the same clean-EOF and bounded-response guarantees as ExecuteBatch.
+func (c *Client) ExecuteInteractive(
+	ctx context.Context,
+	address string,
+	request *sandboxpb.ExecuteInteractiveV1Request,
+) ([]*sandboxpb.ExecuteInteractiveV1Event, error) {
+	return c.ExecuteInteractiveObserved(ctx, address, request, nil)
+}
+
+// ExecuteInteractiveObserved is ExecuteInteractive with the provisional
+// per-event observer of ExecuteBatchObserved.
+func (c *Client) ExecuteInteractiveObserved(
+	ctx context.Context,
+	address string,
+	request *sandboxpb.ExecuteInteractiveV1Request,
+	observe func(*sandboxpb.ExecuteInteractiveV1Event),
+) (_ []*sandboxpb.ExecuteInteractiveV1Event, err error) {
+	if address == "" {
+		return nil, fmt.Errorf("sandbox address is required")
+	}
+	if request == nil {
+		return nil, fmt.Errorf("sandbox interactive request is required")
+	}
+	ctx, span := startRPCSpan(ctx, "sandbox.ExecuteInteractive", address, len(request.Cases))
+	defer func() { tracing.End(span, err) }()
+	entry, err := c.acquire(address)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(address, entry)
+	// The pair shares one wall-clock budget per case, bounded by the slower of
+	// the two process limits.
+	rpcContext, cancel := context.WithTimeout(ctx, streamRPCTimeout(c.timeout, len(request.Cases), max(request.Timeout, request.InteractorTimeout)))
+	defer cancel()
+	stream, err := sandboxpb.NewSandboxServiceClient(entry.connection).ExecuteInteractiveV1(
+		rpcContext,
+		request,
+		grpc.MaxCallSendMsgSize(maxBatchMessageBytesV1),
+		grpc.MaxCallRecvMsgSize(maxBatchMessageBytesV1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("start interactive batch on sandbox %s: %w", address, err)
+	}
+	events := make([]*sandboxpb.ExecuteInteractiveV1Event, 0, len(request.Cases)+1)
+	guard := batchStreamGuard{maxEvents: len(request.Cases) + 1, maxBytes: maxBatchResponseBytesV1}
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if err := guard.finish(); err != nil {
+				return nil, err
+			}
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("receive interactive batch from sandbox %s: %w", address, err)
+		}
+		if err := guard.observeInteractive(event); err != nil {
+			return nil, err
+		}
+		if observe != nil {
+			observe(event)
+		}
 		events = append(events, event)
 	}
 }
 
+// startRPCSpan opens a client span and forwards its context in gRPC metadata
+// so sandbox workers can join the judge attempt's trace.
+func startRPCSpan(ctx context.Context, name, address string, caseCount int) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("server.address", address),
+			attribute.Int("croj.sandbox.cases", caseCount),
+		),
+	)
+	return tracing.OutgoingGRPC(ctx), span
+}
+
 func (c *Client) acquire(address string) (*connectionEntry, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
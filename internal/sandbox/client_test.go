@@ -5,14 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	grpcresolver "google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/resolver/manual"
 	"google.golang.org/grpc/status"
@@ -23,6 +27,7 @@ type sandboxTestServer struct {
 	sandboxpb.UnimplementedSandboxServiceServer
 	execute      func(context.Context, *sandboxpb.ExecuteRequest) (*sandboxpb.ExecuteResponse, error)
 	executeBatch func(*sandboxpb.ExecuteBatchV1Request, grpc.ServerStreamingServer[sandboxpb.ExecuteBatchV1Event]) error
+	interactive  func(*sandboxpb.ExecuteInteractiveV1Request, grpc.ServerStreamingServer[sandboxpb.ExecuteInteractiveV1Event]) error
 }
 
 func (s *sandboxTestServer) Execute(ctx context.Context, request *sandboxpb.ExecuteRequest) (*sandboxpb.ExecuteResponse, error) {
@@ -36,6 +41,13 @@ func (s *sandboxTestServer) ExecuteBatchV1(request *sandboxpb.ExecuteBatchV1Requ
 	return s.executeBatch(request, stream)
 }
 
+func (s *sandboxTestServer) ExecuteInteractiveV1(request *sandboxpb.ExecuteInteractiveV1Request, stream grpc.ServerStreamingServer[sandboxpb.ExecuteInteractiveV1Event]) error {
+	if s.interactive == nil {
+		return status.Error(codes.Unimplemented, "interactive not configured")
+	}
+	return s.interactive(request, stream)
+}
+
 func TestClientCollectsCompleteBatchStream(t *testing.T) {
 	client, stop := newBufconnBatchClient(t, time.Second, func(request *sandboxpb.ExecuteBatchV1Request, stream grpc.ServerStreamingServer[sandboxpb.ExecuteBatchV1Event]) error {
 		for _, testCase := range request.Cases {
@@ -62,6 +74,125 @@ func TestClientCollectsCompleteBatchStream(t *testing.T) {
 	}
 }
 
+func TestClientObservesBatchEventsBeforeStreamCompletes(t *testing.T) {
+	firstObserved := make(chan struct{})
+	client, stop := newBufconnBatchClient(t, time.Second, func(request *sandboxpb.ExecuteBatchV1Request, stream grpc.ServerStreamingServer[sandboxpb.ExecuteBatchV1Event]) error {
+		for index, testCase := range request.Cases {
+			if err := stream.Send(&sandboxpb.ExecuteBatchV1Event{
+				Kind:   sandboxpb.ExecuteBatchV1Event_CASE_RESULT,
+				CaseId: testCase.CaseId,
+				Result: &sandboxpb.ExecuteResponse{Status: "Accepted"},
+			}); err != nil {
+				return err
+			}
+			if index == 0 {
+				select {
+				case <-firstObserved:
+				case <-stream.Context().Done():
+					return stream.Context().Err()
+				}
+			}
+		}
+		return stream.Send(&sandboxpb.ExecuteBatchV1Event{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED})
+	})
+	defer stop()
+
+	var observed []string
+	events, err := client.ExecuteBatchObserved(context.Background(), "sandbox.test:50051", &sandboxpb.ExecuteBatchV1Request{
+		Cases: []*sandboxpb.ExecuteBatchV1Case{{CaseId: "case-1"}, {CaseId: "case-2"}},
+	}, func(event *sandboxpb.ExecuteBatchV1Event) {
+		if len(observed) == 0 {
+			close(firstObserved)
+		}
+		observed = append(observed, event.CaseId)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || !slices.Equal(observed, []string{"case-1", "case-2", ""}) {
+		t.Fatalf("events=%+v observed=%v", events, observed)
+	}
+}
+
+func TestClientPropagatesTraceContextInBatchMetadata(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	received := make(chan []string, 1)
+	client, stop := newBufconnBatchClient(t, time.Second, func(_ *sandboxpb.ExecuteBatchV1Request, stream grpc.ServerStreamingServer[sandboxpb.ExecuteBatchV1Event]) error {
+		incoming, _ := metadata.FromIncomingContext(stream.Context())
+		received <- incoming.Get("traceparent")
+		return stream.Send(&sandboxpb.ExecuteBatchV1Event{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED})
+	})
+	defer stop()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "attempt")
+	defer parent.End()
+	if _, err := client.ExecuteBatch(ctx, "sandbox.test:50051", &sandboxpb.ExecuteBatchV1Request{}); err != nil {
+		t.Fatal(err)
+	}
+	values := <-received
+	traceID := parent.SpanContext().TraceID().String()
+	if len(values) != 1 || !strings.Contains(values[0], traceID) || strings.Contains(values[0], parent.SpanContext().SpanID().String()) {
+		t.Fatalf("traceparent = %v, want child of trace %s", values, traceID)
+	}
+}
+
+func TestClientCollectsCompleteInteractiveStream(t *testing.T) {
+	client, stop := newBufconnSandboxClient(t, time.Second, &sandboxTestServer{
+		interactive: func(request *sandboxpb.ExecuteInteractiveV1Request, stream grpc.ServerStreamingServer[sandboxpb.ExecuteInteractiveV1Event]) error {
+			if request.InteractorSourceCode != "interactor" || request.Cases[0].Answer != "42\n" {
+				return status.Error(codes.InvalidArgument, "interactor request was not forwarded")
+			}
+			if err := stream.Send(&sandboxpb.ExecuteInteractiveV1Event{
+				Kind:              sandboxpb.ExecuteInteractiveV1Event_CASE_RESULT,
+				CaseId:            request.Cases[0].CaseId,
+				Result:            &sandboxpb.ExecuteResponse{Status: "Accepted"},
+				InteractorVerdict: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_WRONG_ANSWER,
+			}); err != nil {
+				return err
+			}
+			return stream.Send(&sandboxpb.ExecuteInteractiveV1Event{Kind: sandboxpb.ExecuteInteractiveV1Event_COMPLETED})
+		},
+	})
+	defer stop()
+
+	events, err := client.ExecuteInteractive(context.Background(), "sandbox.test:50051", &sandboxpb.ExecuteInteractiveV1Request{
+		InteractorSourceCode: "interactor",
+		Cases:                []*sandboxpb.ExecuteInteractiveV1Case{{CaseId: "case-1", Input: "42\n", Answer: "42\n"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].InteractorVerdict != sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_WRONG_ANSWER ||
+		events[1].Kind != sandboxpb.ExecuteInteractiveV1Event_COMPLETED {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestClientRejectsInteractiveEventAfterInteractorCompileError(t *testing.T) {
+	client, stop := newBufconnSandboxClient(t, time.Second, &sandboxTestServer{
+		interactive: func(_ *sandboxpb.ExecuteInteractiveV1Request, stream grpc.ServerStreamingServer[sandboxpb.ExecuteInteractiveV1Event]) error {
+			if err := stream.Send(&sandboxpb.ExecuteInteractiveV1Event{Kind: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_COMPILE_ERROR}); err != nil {
+				return err
+			}
+			return stream.Send(&sandboxpb.ExecuteInteractiveV1Event{Kind: sandboxpb.ExecuteInteractiveV1Event_COMPLETED})
+		},
+	})
+	defer stop()
+
+	_, err := client.ExecuteInteractive(context.Background(), "sandbox.test:50051", &sandboxpb.ExecuteInteractiveV1Request{
+		Cases: []*sandboxpb.ExecuteInteractiveV1Case{{CaseId: "case-1"}},
+	})
+	if !errors.Is(err, ErrInvalidBatchStream) {
+		t.Fatalf("err = %v, want invalid stream after terminal interactor compile error", err)
+	}
+}
+
 func TestClientUsesGRPCRoundRobinAcrossResolvedHeadlessServiceEndpoints(t *testing.T) {
 	addresses := make([]string, 0, 2)
 	stops := make([]func(), 0, 2)
@@ -370,14 +501,19 @@ func newBufconnBatchClient(
 	executeBatch func(*sandboxpb.ExecuteBatchV1Request, grpc.ServerStreamingServer[sandboxpb.ExecuteBatchV1Event]) error,
 ) (*Client, func()) {
 	t.Helper()
-	listener := bufconn.Listen(1024 * 1024)
-	server := grpc.NewServer(grpc.MaxRecvMsgSize(maxBatchMessageBytesV1))
-	sandboxpb.RegisterSandboxServiceServer(server, &sandboxTestServer{
+	return newBufconnSandboxClient(t, timeout, &sandboxTestServer{
 		execute: func(context.Context, *sandboxpb.ExecuteRequest) (*sandboxpb.ExecuteResponse, error) {
 			return nil, status.Error(codes.Unimplemented, "unary not configured")
 		},
 		executeBatch: executeBatch,
 	})
+}
+
+func newBufconnSandboxClient(t *testing.T, timeout time.Duration, service *sandboxTestServer) (*Client, func()) {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.MaxRecvMsgSize(maxBatchMessageBytesV1))
+	sandboxpb.RegisterSandboxServiceServer(server, service)
 	go func() {
 		_ = server.Serve(listener)
 	}()
@@ -3,7 +3,7 @@ package scheduler
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -46,7 +46,7 @@ func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
 		interval = 5 * time.Second
 	}
 	if err := s.Refresh(ctx); err != nil {
-		log.Printf("initial sandbox discovery failed: %v", err)
+		slog.WarnContext(ctx, "initial sandbox discovery failed", "error", err)
 	}
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -56,12 +56,19 @@ func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
 			return
 		case <-ticker.C:
 			if err := s.Refresh(ctx); err != nil {
-				log.Printf("sandbox discovery refresh failed; keeping last known endpoints: %v", err)
+				slog.WarnContext(ctx, "sandbox discovery refresh failed; keeping last known endpoints", "error", err)
 			}
 		}
 	}
 }
 
+// ReadySandboxCount reports the size of the last endpoint snapshot.
+func (s *Scheduler) ReadySandboxCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.endpoints)
+}
+
 func (s *Scheduler) SelectSandbox() (string, error) {
 	return s.SelectSandboxExcluding(nil)
 }
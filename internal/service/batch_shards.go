@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+)
+
+// maxConcurrentBatchShards bounds how many compile-once streams one submission
+// may hold open at a time, leaving sandbox capacity for other jobs.
+const maxConcurrentBatchShards = 4
+
+// shardEndpoints records the sandbox endpoints held by the concurrently
+// running shards of one submission, so that each shard runs on its own Ready
+// endpoint instead of adding concurrency against a single sandbox.
+type shardEndpoints struct {
+	mutex sync.Mutex
+	busy  map[string]struct{}
+}
+
+type shardEndpointsKey struct{}
+
+func withShardEndpoints(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shardEndpointsKey{}, &shardEndpoints{busy: make(map[string]struct{})})
+}
+
+func shardEndpointsFrom(ctx context.Context) *shardEndpoints {
+	endpoints, _ := ctx.Value(shardEndpointsKey{}).(*shardEndpoints)
+	return endpoints
+}
+
+// caseShardPlan assigns consecutive manifest cases to shards that each fit the
+// ExecuteBatchV1 case-count and wire-size limits. Every shard repeats the
+// fixed request fields, so baseBytes is charged once per shard.
+type caseShardPlan struct {
+	baseBytes int
+	maxBytes  int
+	offsets   []int
+	cases     int
+	bytes     int
+}
+
+// place charges one case to the plan. opened reports that the case starts a
+// new shard; ok is false when the case cannot fit even in an empty shard.
+func (plan *caseShardPlan) place(index, caseBytes int) (opened bool, ok bool) {
+	if plan.baseBytes+caseBytes > plan.maxBytes {
+		return false, false
+	}
+	if len(plan.offsets) > 0 && plan.cases < maxSandboxBatchCasesV1 && plan.bytes+caseBytes <= plan.maxBytes {
+		plan.cases++
+		plan.bytes += caseBytes
+		return false, true
+	}
+	plan.offsets = append(plan.offsets, index)
+	plan.cases = 1
+	plan.bytes = plan.baseBytes + caseBytes
+	return true, true
+}
+
+type batchShard struct {
+	request *sandboxpb.ExecuteBatchV1Request
+	offset  int
+}
+
+// executeShards runs shards in manifest order and returns their event streams
+// in the same order. A sequential run stops after the first shard that failed,
+// which preserves ACM early-stop semantics across shard boundaries; otherwise
+// at most concurrency shards run at once, each on a distinct endpoint, and
+// every shard must complete.
+func executeShards[Shard any, Event any](
+	ctx context.Context,
+	shards []Shard,
+	sequential bool,
+	concurrency int,
+	execute func(context.Context, Shard) ([]Event, bool, error),
+	failed func(Shard, []Event) bool,
+) ([][]Event, bool, error) {
+	if sequential || len(shards) == 1 {
+		results := make([][]Event, 0, len(shards))
+		for _, shard := range shards {
+			events, invalidResponse, err := execute(ctx, shard)
+			if err != nil || invalidResponse {
+				return nil, invalidResponse, err
+			}
+			results = append(results, events)
+			if sequential && failed(shard, events) {
+				break
+			}
+		}
+		return results, false, nil
+	}
+	shardContext, cancel := context.WithCancel(withShardEndpoints(ctx))
+	defer cancel()
+	var (
+		results = make([][]Event, len(shards))
+		slots   = make(chan struct{}, max(concurrency, 1))
+		running sync.WaitGroup
+		mutex   sync.Mutex
+		failure error
+		invalid bool
+	)
+	for index, shard := range shards {
+		running.Go(func() {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-shardContext.Done():
+				return
+			}
+			if shardContext.Err() != nil {
+				return
+			}
+			events, invalidResponse, err := execute(shardContext, shard)
+			if err == nil && !invalidResponse {
+				results[index] = events
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			// Only the first failure is reported; shards cancelled because
+			// of it would otherwise mask the original cause.
+			if failure == nil && !invalid {
+				failure, invalid = err, invalidResponse
+				cancel()
+			}
+		})
+	}
+	running.Wait()
+	if failure != nil || invalid {
+		return nil, invalid, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	return results, false, nil
+}
+
+// mergeBatchShardEvents joins validated shard streams into one stream in the
+// shape aggregateBatchResult expects. Compilation is deterministic, so a
+// compile error from any shard stands for the whole submission.
+func mergeBatchShardEvents(shardEvents [][]*sandboxpb.ExecuteBatchV1Event) []*sandboxpb.ExecuteBatchV1Event {
+	if len(shardEvents) == 1 {
+		return shardEvents[0]
+	}
+	merged := make([]*sandboxpb.ExecuteBatchV1Event, 0)
+	for _, events := range shardEvents {
+		if events[0].Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR {
+			return events
+		}
+		merged = append(merged, events[:len(events)-1]...)
+	}
+	return append(merged, &sandboxpb.ExecuteBatchV1Event{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED})
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shardingBatchExecutor answers every case with its expected output unless
+// the case is listed in verdicts, honouring StopOnFailure like the sandbox.
+type shardingBatchExecutor struct {
+	mutex     sync.Mutex
+	requests  []*sandboxpb.ExecuteBatchV1Request
+	addresses []string
+	verdicts  map[string]string
+	failFirst string
+}
+
+func (executor *shardingBatchExecutor) ExecuteBatch(
+	_ context.Context,
+	address string,
+	request *sandboxpb.ExecuteBatchV1Request,
+) ([]*sandboxpb.ExecuteBatchV1Event, error) {
+	executor.mutex.Lock()
+	executor.requests = append(executor.requests, request)
+	executor.addresses = append(executor.addresses, address)
+	executor.mutex.Unlock()
+	if request.Cases[0].CaseId == executor.failFirst {
+		return nil, status.Error(codes.Internal, "sandbox crashed")
+	}
+	events := make([]*sandboxpb.ExecuteBatchV1Event, 0, len(request.Cases)+1)
+	for _, requestCase := range request.Cases {
+		verdict := executor.verdicts[requestCase.CaseId]
+		if verdict == "" {
+			verdict = "Accepted"
+		}
+		events = append(events, &sandboxpb.ExecuteBatchV1Event{
+			Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: requestCase.CaseId,
+			Result: &sandboxpb.ExecuteResponse{Status: verdict, Stdout: requestCase.ExpectedOutput, TimeUsed: 3, MemoryUsed: 64},
+		})
+		if request.StopOnFailure && verdict != "Accepted" {
+			break
+		}
+	}
+	return append(events, &sandboxpb.ExecuteBatchV1Event{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED}), nil
+}
+
+type lockedSelector struct {
+	mutex sync.Mutex
+	sequenceSelector
+}
+
+func (selector *lockedSelector) SelectSandbox() (string, error) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	return selector.sequenceSelector.SelectSandbox()
+}
+
+func (selector *lockedSelector) SelectSandboxExcluding(excluded map[string]struct{}) (string, error) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	return selector.sequenceSelector.SelectSandboxExcluding(excluded)
+}
+
+func largeExactArtifact(mode bundle.JudgeMode, count int) *memoryArtifact {
+	artifact := &memoryArtifact{
+		manifest: bundle.Manifest{SchemaVersion: 2, JudgeMode: mode, Checker: bundle.CheckerExact, Limits: bundle.Limits{TimeLimitMillis: 1000, MemoryLimitMiB: 64}},
+		contents: make(map[string]string, count*2),
+	}
+	for index := range count {
+		id := fmt.Sprintf("case-%03d", index)
+		input, output := id+".in", id+".out"
+		artifact.manifest.Cases = append(artifact.manifest.Cases, bundle.Case{ID: id, Input: input, Output: output, Weight: 1})
+		artifact.contents[input], artifact.contents[output] = "input-"+id, "output-"+id
+	}
+	if mode == bundle.JudgeModeOI {
+		artifact.manifest.TotalScore = &count
+	}
+	return artifact
+}
+
+func TestBatchBundlePipelineRunsEveryOIShardAndMergesInManifestOrder(t *testing.T) {
+	executor := &shardingBatchExecutor{verdicts: map[string]string{"case-300": "Wrong Answer"}}
+	selector := &lockedSelector{sequenceSelector: sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b", "sandbox-c"}}}
+	pipeline := NewBatchBundlePipeline(selector, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int main(){}", StopOnFailure: true,
+	}, largeExactArtifact(bundle.JudgeModeOI, 600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusWrongAnswer || result.Score == nil || *result.Score != 599 || len(result.Cases) != 600 {
+		t.Fatalf("result status=%s score=%v cases=%d", result.Status, result.Score, len(result.Cases))
+	}
+	for index, item := range result.Cases {
+		if item.CaseID != fmt.Sprintf("case-%03d", index) {
+			t.Fatalf("case %d=%s, want manifest order", index, item.CaseID)
+		}
+	}
+	if result.Cases[300].Status != callback.StatusWrongAnswer || result.Cases[301].Status != callback.StatusAccepted {
+		t.Fatalf("shard boundary verdicts=%+v %+v", result.Cases[300], result.Cases[301])
+	}
+	sizes := make([]int, 0, len(executor.requests))
+	for _, request := range executor.requests {
+		if request.StopOnFailure || request.SourceCode != "int main(){}" {
+			t.Fatalf("shard request=%+v, want OI shards to compile the same source and run fully", request)
+		}
+		sizes = append(sizes, len(request.Cases))
+	}
+	slices.Sort(sizes)
+	if !slices.Equal(sizes, []int{88, 256, 256}) {
+		t.Fatalf("shard sizes=%v", sizes)
+	}
+	addresses := slices.Clone(executor.addresses)
+	slices.Sort(addresses)
+	if !slices.Equal(addresses, []string{"sandbox-a", "sandbox-b", "sandbox-c"}) {
+		t.Fatalf("shard endpoints=%v, want one shard per endpoint", executor.addresses)
+	}
+}
+
+// preferringSelector always returns the first endpoint that is not excluded,
+// so shards only spread out if the pipeline excludes busy endpoints.
+type preferringSelector struct {
+	endpoints []string
+}
+
+func (selector preferringSelector) SelectSandbox() (string, error) {
+	return selector.SelectSandboxExcluding(nil)
+}
+
+func (selector preferringSelector) SelectSandboxExcluding(excluded map[string]struct{}) (string, error) {
+	for _, endpoint := range selector.endpoints {
+		if _, skip := excluded[endpoint]; !skip {
+			return endpoint, nil
+		}
+	}
+	return "", errors.New("no untried endpoint")
+}
+
+func (selector preferringSelector) ReadySandboxCount() int { return len(selector.endpoints) }
+
+// overlapBatchExecutor records whether two shards ever ran on one endpoint at
+// the same time, holding each shard open briefly so that shards overlap.
+type overlapBatchExecutor struct {
+	shardingBatchExecutor
+	inFlightMutex sync.Mutex
+	inFlight      map[string]int
+	running       int
+	maxRunning    int
+	shared        []string
+}
+
+func (executor *overlapBatchExecutor) ExecuteBatch(
+	ctx context.Context,
+	address string,
+	request *sandboxpb.ExecuteBatchV1Request,
+) ([]*sandboxpb.ExecuteBatchV1Event, error) {
+	executor.inFlightMutex.Lock()
+	executor.inFlight[address]++
+	executor.running++
+	executor.maxRunning = max(executor.maxRunning, executor.running)
+	if executor.inFlight[address] > 1 {
+		executor.shared = append(executor.shared, address)
+	}
+	executor.inFlightMutex.Unlock()
+	defer func() {
+		executor.inFlightMutex.Lock()
+		executor.inFlight[address]--
+		executor.running--
+		executor.inFlightMutex.Unlock()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	return executor.shardingBatchExecutor.ExecuteBatch(ctx, address, request)
+}
+
+func TestBatchBundlePipelineRunsConcurrentShardsOnDistinctEndpoints(t *testing.T) {
+	executor := &overlapBatchExecutor{inFlight: make(map[string]int)}
+	pipeline := NewBatchBundlePipeline(preferringSelector{endpoints: []string{"sandbox-a", "sandbox-b"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int main(){}",
+	}, largeExactArtifact(bundle.JudgeModeOI, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusAccepted || len(result.Cases) != 1000 || len(executor.requests) != 4 {
+		t.Fatalf("result status=%s cases=%d shards=%d", result.Status, len(result.Cases), len(executor.requests))
+	}
+	if len(executor.shared) != 0 {
+		t.Fatalf("concurrent shards shared endpoints %v", executor.shared)
+	}
+	if executor.maxRunning != 2 {
+		t.Fatalf("max concurrent shards=%d, want one per Ready endpoint", executor.maxRunning)
+	}
+	if !slices.Contains(executor.addresses, "sandbox-a") || !slices.Contains(executor.addresses, "sandbox-b") {
+		t.Fatalf("shard endpoints=%v, want both Ready endpoints used", executor.addresses)
+	}
+}
+
+func TestBatchBundlePipelineStopsACMShardsAfterFirstFailure(t *testing.T) {
+	for _, test := range []struct {
+		failedCase    string
+		wantShards    int
+		wantCaseCount int
+	}{
+		{failedCase: "case-010", wantShards: 1, wantCaseCount: 11},
+		{failedCase: "case-300", wantShards: 2, wantCaseCount: 301},
+	} {
+		t.Run(test.failedCase, func(t *testing.T) {
+			executor := &shardingBatchExecutor{verdicts: map[string]string{test.failedCase: "Time Limit Exceeded"}}
+			pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b"}}, executor, 1)
+
+			result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+				Language: "cpp", SourceCode: "int main(){}", StopOnFailure: true,
+			}, largeExactArtifact(bundle.JudgeModeACM, 600))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != callback.StatusTimeLimitExceeded || len(executor.requests) != test.wantShards ||
+				len(result.Cases) != test.wantCaseCount || result.Cases[len(result.Cases)-1].CaseID != test.failedCase {
+				t.Fatalf("result status=%s cases=%d shards=%d", result.Status, len(result.Cases), len(executor.requests))
+			}
+			if test.wantShards == 2 && executor.addresses[0] == executor.addresses[1] {
+				t.Fatalf("sequential shards reused endpoint %s", executor.addresses[0])
+			}
+		})
+	}
+}
+
+func TestBatchBundlePipelineFailsWholeSubmissionWhenOneShardFails(t *testing.T) {
+	executor := &shardingBatchExecutor{failFirst: "case-256"}
+	selector := &lockedSelector{sequenceSelector: sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b"}}}
+	pipeline := NewBatchBundlePipeline(selector, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int main(){}", StopOnFailure: false,
+	}, largeExactArtifact(bundle.JudgeModeOI, 300))
+	if status.Code(err) != codes.Internal || result.Status != "" {
+		t.Fatalf("result=%+v error=%v", result, err)
+	}
+}
+
+func TestCaseShardPlanSplitsOnCaseCountAndWireSize(t *testing.T) {
+	plan := caseShardPlan{baseBytes: 10, maxBytes: 100}
+	for index := range maxSandboxBatchCasesV1 + 1 {
+		if _, ok := plan.place(index, 0); !ok {
+			t.Fatalf("case %d did not fit", index)
+		}
+	}
+	if !slices.Equal(plan.offsets, []int{0, maxSandboxBatchCasesV1}) {
+		t.Fatalf("case-count offsets=%v", plan.offsets)
+	}
+
+	plan = caseShardPlan{baseBytes: 10, maxBytes: 100}
+	for index, size := range []int{50, 40, 1, 90} {
+		if _, ok := plan.place(index, size); !ok {
+			t.Fatalf("case %d did not fit", index)
+		}
+	}
+	if !slices.Equal(plan.offsets, []int{0, 2, 3}) {
+		t.Fatalf("wire-size offsets=%v", plan.offsets)
+	}
+	if _, ok := plan.place(4, 91); ok {
+		t.Fatal("case larger than an empty shard was accepted")
+	}
+}
+
+type progressRecorder struct {
+	mutex    sync.Mutex
+	compiled []bool
+	cases    []CanonicalCaseResult
+	reports  int
+}
+
+func (recorder *progressRecorder) CompileFinished(_ context.Context, succeeded bool) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.compiled = append(recorder.compiled, succeeded)
+}
+
+func (recorder *progressRecorder) CasesJudged(_ context.Context, cases []CanonicalCaseResult) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.cases = append(recorder.cases, cases...)
+	recorder.reports++
+}
+
+// observedBatchExecutor passes every event to the observer before the shard
+// stream completes, like the gRPC client.
+type observedBatchExecutor struct {
+	shardingBatchExecutor
+	progress *progressRecorder
+	early    bool
+}
+
+func (executor *observedBatchExecutor) ExecuteBatchObserved(
+	ctx context.Context,
+	address string,
+	request *sandboxpb.ExecuteBatchV1Request,
+	observe func(*sandboxpb.ExecuteBatchV1Event),
+) ([]*sandboxpb.ExecuteBatchV1Event, error) {
+	events, err := executor.ExecuteBatch(ctx, address, request)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		observe(event)
+	}
+	executor.progress.mutex.Lock()
+	defer executor.progress.mutex.Unlock()
+	executor.early = executor.early || len(executor.progress.cases) > 0
+	return events, nil
+}
+
+func TestBatchBundlePipelineReportsProgressPerJudgedCase(t *testing.T) {
+	progress := &progressRecorder{}
+	executor := &observedBatchExecutor{shardingBatchExecutor: shardingBatchExecutor{verdicts: map[string]string{"case-400": "Wrong Answer"}}, progress: progress}
+	selector := &lockedSelector{sequenceSelector: sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b", "sandbox-c"}}}
+	pipeline := NewBatchBundlePipeline(selector, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int main(){}", Progress: progress,
+	}, largeExactArtifact(bundle.JudgeModeOI, 600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !executor.early {
+		t.Fatal("cases were reported only after their shard completed")
+	}
+	if progress.reports != len(result.Cases) || len(progress.cases) != len(result.Cases) || len(progress.compiled) != len(result.Cases) {
+		t.Fatalf("case reports=%d cases=%d compile reports=%d, want one per case", progress.reports, len(progress.cases), len(progress.compiled))
+	}
+	reported := make(map[string]callback.Status, len(progress.cases))
+	for _, item := range progress.cases {
+		reported[item.CaseID] = item.Status
+	}
+	for _, item := range result.Cases {
+		if reported[item.CaseID] != item.Status {
+			t.Fatalf("case %s reported %s, final %s", item.CaseID, reported[item.CaseID], item.Status)
+		}
+	}
+}
+
+func TestBatchBundlePipelineReportsProgressPerCompletedShard(t *testing.T) {
+	executor := &shardingBatchExecutor{verdicts: map[string]string{"case-400": "Wrong Answer"}}
+	selector := &lockedSelector{sequenceSelector: sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b", "sandbox-c"}}}
+	pipeline := NewBatchBundlePipeline(selector, executor, 1)
+	progress := &progressRecorder{}
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int main(){}", Progress: progress,
+	}, largeExactArtifact(bundle.JudgeModeOI, 600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(progress.compiled, []bool{true, true, true}) || len(progress.cases) != len(result.Cases) {
+		t.Fatalf("compile reports=%v case reports=%d", progress.compiled, len(progress.cases))
+	}
+	reported := make(map[string]callback.Status, len(progress.cases))
+	for _, item := range progress.cases {
+		reported[item.CaseID] = item.Status
+	}
+	for _, item := range result.Cases {
+		if reported[item.CaseID] != item.Status {
+			t.Fatalf("case %s reported %s, final %s", item.CaseID, reported[item.CaseID], item.Status)
+		}
+	}
+}
@@ -10,11 +10,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
 	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 	judgesandbox "github.com/CodeRushOJ/croj-judging-server/internal/sandbox"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/model"
 	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
@@ -33,14 +36,33 @@ type SandboxBatchExecutor interface {
 	ExecuteBatch(context.Context, string, *sandboxpb.ExecuteBatchV1Request) ([]*sandboxpb.ExecuteBatchV1Event, error)
 }
 
+// SandboxObservedBatchExecutor is implemented by executors that surface each
+// batch event as it arrives, so progress advances per case rather than per
+// completed shard.
+type SandboxObservedBatchExecutor interface {
+	ExecuteBatchObserved(context.Context, string, *sandboxpb.ExecuteBatchV1Request, func(*sandboxpb.ExecuteBatchV1Event)) ([]*sandboxpb.ExecuteBatchV1Event, error)
+}
+
 type SandboxExcludingSelector interface {
 	SelectSandboxExcluding(map[string]struct{}) (string, error)
 }
 
+// SandboxEndpointCounter is implemented by selectors that choose among an
+// enumerable set of Ready endpoints. Concurrent shards of one submission are
+// then limited to that many and each is given its own endpoint. Selectors
+// that delegate balancing to gRPC do not implement it.
+type SandboxEndpointCounter interface {
+	ReadySandboxCount() int
+}
+
 type SpecialJudgeArtifact interface {
 	ReadSpecialJudge() (string, error)
 }
 
+type GraderArtifact interface {
+	ReadGrader(string) ([]bundle.CompileFile, error)
+}
+
 const maxSandboxBatchCasesV1 = 256
 const maxSandboxBatchRequestBytesV1 = 64 << 20
 const maxSpecialJudgeProtocolBytesV1 = 4 << 20
@@ -61,6 +83,21 @@ type CanonicalExecutionRequest struct {
 	Language      string
 	SourceCode    string
 	StopOnFailure bool
+	Progress      CanonicalProgress
+}
+
+func (request CanonicalExecutionRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("language", request.Language), slog.Int("source_bytes", len(request.SourceCode)))
+}
+
+// CanonicalProgress observes partial results while a submission is judged.
+// Shards report concurrently and in completion order, a shard retried on
+// another endpoint reports its cases again, and a later failure can still
+// discard everything reported; only the returned result is authoritative.
+// Implementations must not block judging on slow consumers.
+type CanonicalProgress interface {
+	CompileFinished(ctx context.Context, succeeded bool)
+	CasesJudged(ctx context.Context, cases []CanonicalCaseResult)
 }
 
 type CanonicalCaseResult struct {
@@ -72,6 +109,17 @@ type CanonicalCaseResult struct {
 	MaxScore       *int
 }
 
+// CanonicalSubtaskResult reports one manifest v3 scoring group. Status is the
+// first non-accepted verdict among its cases; DependenciesMet is false when an
+// earlier required subtask did not earn its full score.
+type CanonicalSubtaskResult struct {
+	SubtaskID       string
+	Status          callback.Status
+	Score           int
+	MaxScore        int
+	DependenciesMet bool
+}
+
 type CanonicalResult struct {
 	Status         callback.Status
 	ExitCode       int
@@ -82,6 +130,7 @@ type CanonicalResult struct {
 	Score          *int
 	TotalScore     *int
 	Cases          []CanonicalCaseResult
+	Subtasks       []CanonicalSubtaskResult
 }
 
 func (result CanonicalResult) CallbackResult() callback.Result {
@@ -155,6 +204,12 @@ func (pipeline *BatchBundlePipeline) ExecuteArtifact(
 	} else if executionConfig.SpecialJudge {
 		return systemErrorResult("immutable special judge config requires a special checker bundle"), nil
 	}
+	if manifest.Checker == bundle.CheckerInteractive {
+		return systemErrorResult("interactive test bundles are not supported by immutable problem versions"), nil
+	}
+	if len(manifest.Graders) != 0 {
+		return systemErrorResult("grader test bundles are not supported by immutable problem versions"), nil
+	}
 	result, err := pipeline.ExecuteCanonical(ctx, CanonicalExecutionRequest{
 		Language: submission.Language, SourceCode: submission.Code, StopOnFailure: true,
 	}, artifact)
@@ -173,35 +228,39 @@ func (pipeline *BatchBundlePipeline) ExecuteCanonical(
 	if err := manifest.Validate(); err != nil {
 		return CanonicalResult{}, fmt.Errorf("%w: bundle manifest became invalid", ErrCanonicalInfrastructure)
 	}
-	if len(manifest.Cases) > maxSandboxBatchCasesV1 {
-		return CanonicalResult{}, fmt.Errorf("%w: bundle exceeds sandbox batch case limit", ErrCanonicalInfrastructure)
+	compileFiles, err := graderCompileFiles(manifest, artifact, input.Language)
+	if err != nil {
+		return CanonicalResult{}, err
+	}
+	if manifest.Checker == bundle.CheckerInteractive {
+		return pipeline.executeInteractive(ctx, input, manifest, artifact, compileFiles)
 	}
 	stopOnFailure := input.StopOnFailure &&
 		manifest.JudgeMode == bundle.JudgeModeACM &&
 		manifest.Checker != bundle.CheckerSpecial
-	request := &sandboxpb.ExecuteBatchV1Request{
-		Language:      input.Language,
-		SourceCode:    input.SourceCode,
-		Timeout:       timeoutSeconds(manifest.Limits.TimeLimitMillis),
-		MemoryLimit:   boundedInt32(manifest.Limits.MemoryLimitMiB),
-		StopOnFailure: stopOnFailure,
-		Cases:         make([]*sandboxpb.ExecuteBatchV1Case, 0, len(manifest.Cases)),
-	}
-	maxRequestBytes := pipeline.maxRequestBytes
-	if maxRequestBytes <= 0 {
-		maxRequestBytes = maxSandboxBatchRequestBytesV1
-	}
-	if proto.Size(request) > maxRequestBytes {
+	newRequest := func() *sandboxpb.ExecuteBatchV1Request {
+		return &sandboxpb.ExecuteBatchV1Request{
+			Language:      input.Language,
+			SourceCode:    input.SourceCode,
+			Timeout:       timeoutSeconds(manifest.Limits.TimeLimitMillis),
+			MemoryLimit:   boundedInt32(manifest.Limits.MemoryLimitMiB),
+			StopOnFailure: stopOnFailure,
+			CompileFiles:  compileFiles,
+		}
+	}
+	plan := caseShardPlan{baseBytes: proto.Size(newRequest()), maxBytes: pipeline.maxBatchRequestBytes()}
+	if plan.baseBytes > plan.maxBytes {
 		return CanonicalResult{}, fmt.Errorf("%w: submission exceeds sandbox batch byte limit", ErrCanonicalInfrastructure)
 	}
-	requestBytes := proto.Size(request)
+	shards := make([]batchShard, 0, 1)
+	contestantCases := make([]*sandboxpb.ExecuteBatchV1Case, 0, len(manifest.Cases))
 	expectedChecks := make([]string, 0, len(manifest.Cases))
 	maxExpectedCheckBytes := pipeline.maxExpectedCheckBytes
 	if maxExpectedCheckBytes <= 0 {
 		maxExpectedCheckBytes = maxSandboxBatchRequestBytesV1
 	}
 	retainedExpectedCheckBytes := 0
-	for _, testCase := range manifest.Cases {
+	for index, testCase := range manifest.Cases {
 		input, expected, err := artifact.ReadCase(testCase)
 		if err != nil {
 			return CanonicalResult{}, fmt.Errorf("%w: bundle case could not be read", ErrCanonicalInfrastructure)
@@ -218,6 +277,9 @@ func (pipeline *BatchBundlePipeline) ExecuteCanonical(
 		case bundle.CheckerSpecial:
 			// The expected output remains only in judging-server memory and is
 			// sent to the separately sandboxed checker through its bounded ABI.
+		case bundle.CheckerFloat:
+			// Tolerant comparison runs locally against the retained expected
+			// output, so the sandbox reports stdout without judging it.
 		default:
 			return CanonicalResult{}, fmt.Errorf("%w: unsupported checker", ErrCanonicalInfrastructure)
 		}
@@ -233,45 +295,163 @@ func (pipeline *BatchBundlePipeline) ExecuteCanonical(
 			CompareOutput:       manifest.Checker == bundle.CheckerExact,
 			TokenExpectedSha256: expectedTokenSHA256,
 		}
-		requestBytes += batchCaseWireBytes(requestCase)
-		if requestBytes > maxRequestBytes {
+		opened, ok := plan.place(index, batchCaseWireBytes(requestCase))
+		if !ok {
 			return CanonicalResult{}, fmt.Errorf("%w: bundle exceeds sandbox batch byte limit", ErrCanonicalInfrastructure)
 		}
-		request.Cases = append(request.Cases, requestCase)
-	}
-	events, invalidResponse, err := pipeline.executeBatch(ctx, request)
+		if opened {
+			shards = append(shards, batchShard{request: newRequest(), offset: index})
+		}
+		shard := shards[len(shards)-1].request
+		shard.Cases = append(shard.Cases, requestCase)
+		contestantCases = append(contestantCases, requestCase)
+	}
+	var observe func(*sandboxpb.ExecuteBatchV1Event)
+	if _, ok := pipeline.executor.(SandboxObservedBatchExecutor); ok && input.Progress != nil {
+		observe = batchCaseProgress(ctx, input.Progress, manifest, expectedChecks)
+	}
+	shardEvents, invalidResponse, err := executeShards(ctx, shards, stopOnFailure, pipeline.shardConcurrency(),
+		func(ctx context.Context, shard batchShard) ([]*sandboxpb.ExecuteBatchV1Event, bool, error) {
+			events, invalidResponse, err := pipeline.executeBatch(ctx, shard.request, observe)
+			if err == nil && !invalidResponse && input.Progress != nil && observe == nil {
+				shardResult, _ := aggregateBatchResult(manifest, expectedChecks[shard.offset:], events)
+				// Special-checker verdicts are only known after the checker runs.
+				reportShardProgress(ctx, input.Progress, shardResult, manifest.Checker != bundle.CheckerSpecial)
+			}
+			return events, invalidResponse, err
+		},
+		func(shard batchShard, events []*sandboxpb.ExecuteBatchV1Event) bool {
+			return batchShardFailed(manifest, expectedChecks[shard.offset:], events)
+		},
+	)
 	if err != nil {
 		return CanonicalResult{}, err
 	}
 	if invalidResponse {
 		return CanonicalResult{}, fmt.Errorf("%w: sandbox batch response was invalid", ErrCanonicalInfrastructure)
 	}
-	result, actualOutputs := aggregateBatchResult(manifest, expectedChecks, events)
+	result, actualOutputs := aggregateBatchResult(manifest, expectedChecks, mergeBatchShardEvents(shardEvents))
 	if result.Status == callback.StatusSystemError {
 		return CanonicalResult{}, fmt.Errorf("%w: sandbox returned a system error", ErrCanonicalInfrastructure)
 	}
 	if manifest.Checker == bundle.CheckerSpecial && result.Status != callback.StatusCompileError {
-		result, err = pipeline.applySpecialJudge(ctx, manifest, artifact, request, expectedChecks, actualOutputs, result)
+		result, err = pipeline.applySpecialJudge(ctx, manifest, artifact, contestantCases, expectedChecks, actualOutputs, result)
 		if err != nil {
 			return CanonicalResult{}, err
 		}
+		if input.Progress != nil {
+			input.Progress.CasesJudged(ctx, result.Cases)
+		}
 	}
 	return applyManifestScoring(manifest, result), nil
 }
 
+// batchCaseProgress reports every case as its event arrives from the sandbox,
+// applying the same local output check as the final aggregation.
+// Special-checker verdicts are only known after the checker runs, so those
+// streams report compilation alone.
+func batchCaseProgress(
+	ctx context.Context,
+	progress CanonicalProgress,
+	manifest bundle.Manifest,
+	expectedChecks []string,
+) func(*sandboxpb.ExecuteBatchV1Event) {
+	indexes := make(map[string]int, len(manifest.Cases))
+	for index, testCase := range manifest.Cases {
+		indexes[testCase.ID] = index
+	}
+	return func(event *sandboxpb.ExecuteBatchV1Event) {
+		switch event.Kind {
+		case sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR:
+			progress.CompileFinished(ctx, false)
+		case sandboxpb.ExecuteBatchV1Event_CASE_RESULT:
+			index, ok := indexes[event.CaseId]
+			if !ok || event.Result == nil {
+				// The completed stream fails validation instead.
+				return
+			}
+			progress.CompileFinished(ctx, true)
+			if manifest.Checker != bundle.CheckerSpecial {
+				progress.CasesJudged(ctx, []CanonicalCaseResult{batchCaseResult(manifest, expectedChecks[index], event)})
+			}
+		}
+	}
+}
+
+// reportShardProgress forwards one completed shard. Compilation is
+// deterministic, so every shard reports the same compile outcome and the
+// consumer is expected to deduplicate it.
+func reportShardProgress(ctx context.Context, progress CanonicalProgress, shardResult CanonicalResult, casesFinal bool) {
+	if shardResult.Status == callback.StatusCompileError && len(shardResult.Cases) == 0 {
+		progress.CompileFinished(ctx, false)
+		return
+	}
+	progress.CompileFinished(ctx, true)
+	if casesFinal && len(shardResult.Cases) > 0 {
+		progress.CasesJudged(ctx, shardResult.Cases)
+	}
+}
+
+// graderCompileFiles loads the grader that is compiled together with the
+// contestant source. Admission only accepts languages the bundle declares, so
+// a missing grader means the stored job and the immutable bundle disagree.
+func graderCompileFiles(manifest bundle.Manifest, artifact CaseArtifact, language string) ([]*sandboxpb.CompileFile, error) {
+	if len(manifest.Graders) == 0 {
+		return nil, nil
+	}
+	graderArtifact, ok := artifact.(GraderArtifact)
+	if !ok || !manifest.SupportsLanguage(language) {
+		return nil, fmt.Errorf("%w: bundle has no grader for the submission language", ErrCanonicalInfrastructure)
+	}
+	files, err := graderArtifact.ReadGrader(language)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bundle grader could not be read", ErrCanonicalInfrastructure)
+	}
+	compileFiles := make([]*sandboxpb.CompileFile, 0, len(files))
+	for _, file := range files {
+		compileFiles = append(compileFiles, &sandboxpb.CompileFile{Name: file.Name, Content: file.Content, Header: file.Header})
+	}
+	return compileFiles, nil
+}
+
 func batchCaseWireBytes(requestCase *sandboxpb.ExecuteBatchV1Case) int {
 	caseBytes := proto.Size(requestCase)
 	return protowire.SizeTag(executeBatchCasesFieldNumber) + protowire.SizeVarint(uint64(caseBytes)) + caseBytes
 }
 
+// executeBatch runs one shard. A non-nil observe requires an executor that
+// implements SandboxObservedBatchExecutor.
 func (pipeline *BatchBundlePipeline) executeBatch(
 	ctx context.Context,
 	request *sandboxpb.ExecuteBatchV1Request,
+	observe func(*sandboxpb.ExecuteBatchV1Event),
 ) ([]*sandboxpb.ExecuteBatchV1Event, bool, error) {
+	return executeOnDistinctSandboxes(ctx, pipeline, "batch",
+		func(address string) ([]*sandboxpb.ExecuteBatchV1Event, error) {
+			if observe != nil {
+				return pipeline.executor.(SandboxObservedBatchExecutor).ExecuteBatchObserved(ctx, address, request, observe)
+			}
+			return pipeline.executor.ExecuteBatch(ctx, address, request)
+		},
+		func(events []*sandboxpb.ExecuteBatchV1Event) error { return validateBatchEvents(request, events) },
+	)
+}
+
+// executeOnDistinctSandboxes retries a whole compile-once stream on another
+// Ready endpoint after capacity failures. The boolean result reports a stream
+// that completed but violated its protocol and must not be retried. Kind
+// labels the per-endpoint latency and retry metrics.
+func executeOnDistinctSandboxes[Event any](
+	ctx context.Context,
+	pipeline *BatchBundlePipeline,
+	kind string,
+	execute func(string) ([]Event, error),
+	validate func([]Event) error,
+) ([]Event, bool, error) {
 	var lastRetryable error
 	attempted := make(map[string]struct{}, pipeline.maxInfraAttempts)
 	for attempt := 0; attempt < pipeline.maxInfraAttempts; attempt++ {
-		address, err := pipeline.selectUntriedSandbox(attempted)
+		address, release, err := pipeline.selectShardSandbox(ctx, attempted)
 		if err != nil {
 			if lastRetryable != nil {
 				return nil, false, fmt.Errorf("execute sandbox batch after %d distinct endpoint attempts: %w", len(attempted), lastRetryable)
@@ -279,21 +459,29 @@ func (pipeline *BatchBundlePipeline) executeBatch(
 			return nil, false, fmt.Errorf("select sandbox: %w", err)
 		}
 		attempted[address] = struct{}{}
-		events, err := pipeline.executor.ExecuteBatch(ctx, address, request)
+		started := time.Now()
+		events, err := execute(address)
+		release()
 		if err != nil {
 			if errors.Is(err, judgesandbox.ErrInvalidBatchStream) {
+				metrics.ObserveSandboxBatch(kind, "invalid", time.Since(started))
 				return nil, true, nil
 			}
 			code := status.Code(err)
 			if code == codes.Unavailable || code == codes.ResourceExhausted {
+				metrics.ObserveSandboxBatch(kind, "retryable", time.Since(started))
+				metrics.RecordSandboxRetry(kind, sandboxRetryReason(code))
 				lastRetryable = err
 				continue
 			}
+			metrics.ObserveSandboxBatch(kind, "error", time.Since(started))
 			return nil, false, fmt.Errorf("execute sandbox batch: %w", err)
 		}
-		if err := validateBatchEvents(request, events); err != nil {
+		if err := validate(events); err != nil {
+			metrics.ObserveSandboxBatch(kind, "invalid", time.Since(started))
 			return nil, true, nil
 		}
+		metrics.ObserveSandboxBatch(kind, "completed", time.Since(started))
 		return events, false, nil
 	}
 	if lastRetryable != nil {
@@ -302,6 +490,54 @@ func (pipeline *BatchBundlePipeline) executeBatch(
 	return nil, true, nil
 }
 
+func sandboxRetryReason(code codes.Code) string {
+	if code == codes.ResourceExhausted {
+		return "resource_exhausted"
+	}
+	return "unavailable"
+}
+
+// shardConcurrency is how many shards of one submission may run at once:
+// never more than there are Ready endpoints to give each its own.
+func (pipeline *BatchBundlePipeline) shardConcurrency() int {
+	if counter, ok := pipeline.selector.(SandboxEndpointCounter); ok {
+		return max(min(maxConcurrentBatchShards, counter.ReadySandboxCount()), 1)
+	}
+	return maxConcurrentBatchShards
+}
+
+// selectShardSandbox selects an untried endpoint that no other running shard
+// of the submission holds; release returns it. If the Ready set shrank below
+// the running shards, it falls back to any untried endpoint rather than
+// failing the submission.
+func (pipeline *BatchBundlePipeline) selectShardSandbox(ctx context.Context, attempted map[string]struct{}) (string, func(), error) {
+	endpoints := shardEndpointsFrom(ctx)
+	if _, counted := pipeline.selector.(SandboxEndpointCounter); endpoints == nil || !counted {
+		address, err := pipeline.selectUntriedSandbox(attempted)
+		return address, func() {}, err
+	}
+	endpoints.mutex.Lock()
+	defer endpoints.mutex.Unlock()
+	excluded := make(map[string]struct{}, len(attempted)+len(endpoints.busy))
+	for address := range attempted {
+		excluded[address] = struct{}{}
+	}
+	for address := range endpoints.busy {
+		excluded[address] = struct{}{}
+	}
+	address, err := pipeline.selectUntriedSandbox(excluded)
+	if err != nil {
+		address, err := pipeline.selectUntriedSandbox(attempted)
+		return address, func() {}, err
+	}
+	endpoints.busy[address] = struct{}{}
+	return address, func() {
+		endpoints.mutex.Lock()
+		defer endpoints.mutex.Unlock()
+		delete(endpoints.busy, address)
+	}, nil
+}
+
 func (pipeline *BatchBundlePipeline) selectUntriedSandbox(attempted map[string]struct{}) (string, error) {
 	if selector, ok := pipeline.selector.(SandboxExcludingSelector); ok {
 		return selector.SelectSandboxExcluding(attempted)
@@ -367,6 +603,22 @@ func validateBatchEvents(request *sandboxpb.ExecuteBatchV1Request, events []*san
 	return fmt.Errorf("batch completion event is missing")
 }
 
+// batchShardFailed reports whether a stop-on-failure run must skip the shards
+// after this one. A locally rejected float output counts as a failure even
+// though the sandbox reported the case as Accepted.
+func batchShardFailed(manifest bundle.Manifest, expectedChecks []string, events []*sandboxpb.ExecuteBatchV1Event) bool {
+	if events[0].Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR {
+		return true
+	}
+	for index, event := range events[:len(events)-1] {
+		if event.Result.Status != "Accepted" ||
+			manifest.Checker != bundle.CheckerSpecial && !outputMatchesExpectedCheck(manifest, event.Result.Stdout, expectedChecks[index]) {
+			return true
+		}
+	}
+	return false
+}
+
 func aggregateBatchResult(
 	manifest bundle.Manifest,
 	expectedChecks []string,
@@ -383,11 +635,8 @@ func aggregateBatchResult(
 	actualOutputs := make([]string, 0, len(events)-1)
 	summaries := make([]string, 0, len(events)-1)
 	for index, event := range events[:len(events)-1] {
-		caseStatus := mapBundleStatus(event.Result.Status)
-		if event.Result.Status == "Accepted" && manifest.Checker != bundle.CheckerSpecial &&
-			!outputMatchesExpectedCheck(manifest.Checker, event.Result.Stdout, expectedChecks[index]) {
-			caseStatus = callback.StatusWrongAnswer
-		}
+		caseResult := batchCaseResult(manifest, expectedChecks[index], event)
+		caseStatus := caseResult.Status
 		actualOutputs = append(actualOutputs, event.Result.Stdout)
 		result.TimeUsedMillis = max(result.TimeUsedMillis, boundedMetric(event.Result.TimeUsed, 86_400_000))
 		result.MemoryUsedKB = max(result.MemoryUsedKB, boundedMetric(event.Result.MemoryUsed, 2_147_483_647))
@@ -395,11 +644,7 @@ func aggregateBatchResult(
 			result.Status = caseStatus
 			result.ExitCode = int(event.Result.ExitCode)
 		}
-		result.Cases = append(result.Cases, CanonicalCaseResult{
-			CaseID: event.CaseId, Status: caseStatus,
-			TimeUsedMillis: boundedMetric(event.Result.TimeUsed, 86_400_000),
-			MemoryUsedKB:   boundedMetric(event.Result.MemoryUsed, 2_147_483_647),
-		})
+		result.Cases = append(result.Cases, caseResult)
 		summaries = append(summaries, fmt.Sprintf("case=%s sandboxStatus=%s status=%s", event.CaseId, event.Result.Status, caseStatus))
 		result.Stderr = callback.TruncateUTF16(strings.Join(summaries, ";"), 65_536)
 		if caseStatus == callback.StatusCompileError {
@@ -412,11 +657,29 @@ func aggregateBatchResult(
 	return result, actualOutputs
 }
 
-func outputMatchesExpectedCheck(checker bundle.Checker, actual, expectedCheck string) bool {
-	if checker == bundle.CheckerToken {
+// batchCaseResult maps one sandbox case event, rejecting an Accepted output
+// that fails the local token or float check.
+func batchCaseResult(manifest bundle.Manifest, expectedCheck string, event *sandboxpb.ExecuteBatchV1Event) CanonicalCaseResult {
+	caseStatus := mapBundleStatus(event.Result.Status)
+	if event.Result.Status == "Accepted" && manifest.Checker != bundle.CheckerSpecial &&
+		!outputMatchesExpectedCheck(manifest, event.Result.Stdout, expectedCheck) {
+		caseStatus = callback.StatusWrongAnswer
+	}
+	return CanonicalCaseResult{
+		CaseID: event.CaseId, Status: caseStatus,
+		TimeUsedMillis: boundedMetric(event.Result.TimeUsed, 86_400_000),
+		MemoryUsedKB:   boundedMetric(event.Result.MemoryUsed, 2_147_483_647),
+	}
+}
+
+func outputMatchesExpectedCheck(manifest bundle.Manifest, actual, expectedCheck string) bool {
+	switch manifest.Checker {
+	case bundle.CheckerToken:
 		return tokenOutputSHA256(actual) == expectedCheck
+	case bundle.CheckerFloat:
+		return manifest.FloatTolerance != nil && floatOutputsMatch(actual, expectedCheck, *manifest.FloatTolerance)
 	}
-	return outputsMatch(checker, actual, expectedCheck)
+	return outputsMatch(manifest.Checker, actual, expectedCheck)
 }
 
 type specialJudgeInputV1 struct {
@@ -437,14 +700,14 @@ func (pipeline *BatchBundlePipeline) applySpecialJudge(
 	ctx context.Context,
 	manifest bundle.Manifest,
 	artifact CaseArtifact,
-	contestantRequest *sandboxpb.ExecuteBatchV1Request,
+	contestantCases []*sandboxpb.ExecuteBatchV1Case,
 	expectedOutputs []string,
 	actualOutputs []string,
 	result CanonicalResult,
 ) (CanonicalResult, error) {
 	specialArtifact, ok := artifact.(SpecialJudgeArtifact)
 	if !ok || manifest.SpecialJudge == nil || len(result.Cases) != len(manifest.Cases) ||
-		len(contestantRequest.Cases) != len(manifest.Cases) || len(expectedOutputs) != len(manifest.Cases) ||
+		len(contestantCases) != len(manifest.Cases) || len(expectedOutputs) != len(manifest.Cases) ||
 		len(actualOutputs) != len(manifest.Cases) {
 		return CanonicalResult{}, fmt.Errorf("%w: special judge artifact is incomplete", ErrCanonicalInfrastructure)
 	}
@@ -452,15 +715,17 @@ func (pipeline *BatchBundlePipeline) applySpecialJudge(
 	if err != nil || source == "" {
 		return CanonicalResult{}, fmt.Errorf("%w: special judge source is unavailable", ErrCanonicalInfrastructure)
 	}
-	checkerRequest := &sandboxpb.ExecuteBatchV1Request{
-		Language:      manifest.SpecialJudge.Language,
-		SourceCode:    source,
-		Timeout:       timeoutSeconds(manifest.SpecialJudge.TimeLimitMillis),
-		MemoryLimit:   boundedInt32(manifest.SpecialJudge.MemoryLimitMiB),
-		StopOnFailure: false,
-		Cases:         make([]*sandboxpb.ExecuteBatchV1Case, 0, len(manifest.Cases)),
+	newCheckerRequest := func() *sandboxpb.ExecuteBatchV1Request {
+		return &sandboxpb.ExecuteBatchV1Request{
+			Language:      manifest.SpecialJudge.Language,
+			SourceCode:    source,
+			Timeout:       timeoutSeconds(manifest.SpecialJudge.TimeLimitMillis),
+			MemoryLimit:   boundedInt32(manifest.SpecialJudge.MemoryLimitMiB),
+			StopOnFailure: false,
+		}
 	}
-	requestBytes := proto.Size(checkerRequest)
+	plan := caseShardPlan{baseBytes: proto.Size(newCheckerRequest()), maxBytes: pipeline.maxBatchRequestBytes()}
+	shards := make([]batchShard, 0, 1)
 	caseIndexes := make([]int, 0, len(manifest.Cases))
 	for index, item := range result.Cases {
 		if item.Status != callback.StatusAccepted {
@@ -468,7 +733,7 @@ func (pipeline *BatchBundlePipeline) applySpecialJudge(
 		}
 		if !specialJudgePayloadWithinPreEncodeLimit(
 			item.CaseID,
-			contestantRequest.Cases[index].Stdin,
+			contestantCases[index].Stdin,
 			expectedOutputs[index],
 			actualOutputs[index],
 		) {
@@ -477,7 +742,7 @@ func (pipeline *BatchBundlePipeline) applySpecialJudge(
 		payload, err := json.Marshal(specialJudgeInputV1{
 			SchemaVersion:  1,
 			CaseID:         item.CaseID,
-			Input:          contestantRequest.Cases[index].Stdin,
+			Input:          contestantCases[index].Stdin,
 			ExpectedOutput: expectedOutputs[index],
 			ActualOutput:   actualOutputs[index],
 		})
@@ -486,47 +751,62 @@ func (pipeline *BatchBundlePipeline) applySpecialJudge(
 			return CanonicalResult{}, fmt.Errorf("%w: special judge ABI input exceeds its limit", ErrTenantCheckerFailure)
 		}
 		requestCase := &sandboxpb.ExecuteBatchV1Case{CaseId: item.CaseID, Stdin: string(payload)}
-		requestBytes += batchCaseWireBytes(requestCase)
-		if requestBytes > pipeline.maxBatchRequestBytes() {
+		opened, ok := plan.place(len(caseIndexes), batchCaseWireBytes(requestCase))
+		if !ok {
 			return CanonicalResult{}, fmt.Errorf("%w: special judge batch exceeds sandbox byte limit", ErrTenantCheckerFailure)
 		}
-		checkerRequest.Cases = append(checkerRequest.Cases, requestCase)
+		if opened {
+			shards = append(shards, batchShard{request: newCheckerRequest(), offset: len(caseIndexes)})
+		}
+		shard := shards[len(shards)-1].request
+		shard.Cases = append(shard.Cases, requestCase)
 		caseIndexes = append(caseIndexes, index)
 	}
-	if len(checkerRequest.Cases) == 0 {
+	if len(shards) == 0 {
 		return recomputeCanonicalVerdict(result), nil
 	}
-	events, invalidResponse, err := pipeline.executeBatch(ctx, checkerRequest)
+	shardEvents, invalidResponse, err := executeShards(ctx, shards, false, pipeline.shardConcurrency(),
+		func(ctx context.Context, shard batchShard) ([]*sandboxpb.ExecuteBatchV1Event, bool, error) {
+			return pipeline.executeBatch(ctx, shard.request, nil)
+		},
+		func(batchShard, []*sandboxpb.ExecuteBatchV1Event) bool { return false },
+	)
 	if err != nil {
 		return CanonicalResult{}, err
 	}
-	if invalidResponse || len(events) != len(checkerRequest.Cases)+1 {
+	if invalidResponse {
 		return CanonicalResult{}, fmt.Errorf("%w: special judge sandbox response was invalid", ErrCanonicalInfrastructure)
 	}
-	if events[0].Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR {
-		return CanonicalResult{}, fmt.Errorf("%w: special judge compilation failed", ErrTenantCheckerFailure)
-	}
-	for eventIndex, event := range events[:len(events)-1] {
-		if event.Result == nil {
-			return CanonicalResult{}, fmt.Errorf("%w: special judge sandbox response was invalid", ErrCanonicalInfrastructure)
+	for shardIndex, events := range shardEvents {
+		shard := shards[shardIndex]
+		if len(events) == 1 && events[0].Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR {
+			return CanonicalResult{}, fmt.Errorf("%w: special judge compilation failed", ErrTenantCheckerFailure)
 		}
-		if event.Result.Status != "Accepted" {
-			return CanonicalResult{}, fmt.Errorf("%w: special judge did not execute successfully", ErrTenantCheckerFailure)
+		if len(events) != len(shard.request.Cases)+1 {
+			return CanonicalResult{}, fmt.Errorf("%w: special judge sandbox response was invalid", ErrCanonicalInfrastructure)
 		}
-		accepted, err := parseSpecialJudgeOutput(event.Result.Stdout)
-		if err != nil {
-			return CanonicalResult{}, fmt.Errorf("%w: special judge output was invalid", ErrTenantCheckerFailure)
-		}
-		index := caseIndexes[eventIndex]
-		result.Cases[index].TimeUsedMillis += boundedMetric(event.Result.TimeUsed, 86_400_000)
-		result.Cases[index].MemoryUsedKB = max(
-			result.Cases[index].MemoryUsedKB,
-			boundedMetric(event.Result.MemoryUsed, 2_147_483_647),
-		)
-		if accepted {
-			result.Cases[index].Status = callback.StatusAccepted
-		} else {
-			result.Cases[index].Status = callback.StatusWrongAnswer
+		for eventIndex, event := range events[:len(events)-1] {
+			if event.Result == nil {
+				return CanonicalResult{}, fmt.Errorf("%w: special judge sandbox response was invalid", ErrCanonicalInfrastructure)
+			}
+			if event.Result.Status != "Accepted" {
+				return CanonicalResult{}, fmt.Errorf("%w: special judge did not execute successfully", ErrTenantCheckerFailure)
+			}
+			accepted, err := parseSpecialJudgeOutput(event.Result.Stdout)
+			if err != nil {
+				return CanonicalResult{}, fmt.Errorf("%w: special judge output was invalid", ErrTenantCheckerFailure)
+			}
+			index := caseIndexes[shard.offset+eventIndex]
+			result.Cases[index].TimeUsedMillis += boundedMetric(event.Result.TimeUsed, 86_400_000)
+			result.Cases[index].MemoryUsedKB = max(
+				result.Cases[index].MemoryUsedKB,
+				boundedMetric(event.Result.MemoryUsed, 2_147_483_647),
+			)
+			if accepted {
+				result.Cases[index].Status = callback.StatusAccepted
+			} else {
+				result.Cases[index].Status = callback.StatusWrongAnswer
+			}
 		}
 	}
 	return recomputeCanonicalVerdict(result), nil
@@ -631,6 +911,9 @@ func applyManifestScoring(manifest bundle.Manifest, result CanonicalResult) Cano
 	if manifest.JudgeMode != bundle.JudgeModeOI || manifest.TotalScore == nil {
 		return result
 	}
+	if len(manifest.Subtasks) > 0 {
+		return applySubtaskScoring(manifest, result)
+	}
 	score, total := 0, *manifest.TotalScore
 	for index := range result.Cases {
 		caseScore := 0
@@ -653,3 +936,62 @@ func applyManifestScoring(manifest bundle.Manifest, result CanonicalResult) Cano
 	}
 	return result
 }
+
+// applySubtaskScoring scores manifest v3 groups in manifest order. Case results
+// carry no individual score because one case may belong to several groups.
+func applySubtaskScoring(manifest bundle.Manifest, result CanonicalResult) CanonicalResult {
+	score, total := 0, *manifest.TotalScore
+	result.Score, result.TotalScore = copyInt(&score), copyInt(&total)
+	if result.Status == callback.StatusCompileError {
+		return result
+	}
+	caseIndexes := make(map[string]int, len(manifest.Cases))
+	for index, testCase := range manifest.Cases {
+		caseIndexes[testCase.ID] = index
+	}
+	fullScore := make(map[string]bool, len(manifest.Subtasks))
+	result.Subtasks = make([]CanonicalSubtaskResult, 0, len(manifest.Subtasks))
+	for _, subtask := range manifest.Subtasks {
+		item := CanonicalSubtaskResult{
+			SubtaskID: subtask.ID, Status: callback.StatusAccepted,
+			MaxScore: subtask.Score, DependenciesMet: true,
+		}
+		for _, dependency := range subtask.Dependencies {
+			item.DependenciesMet = item.DependenciesMet && fullScore[dependency]
+		}
+		var acceptedWeight, totalWeight int64
+		for _, caseID := range subtask.Cases {
+			index := caseIndexes[caseID]
+			weight := int64(manifest.Cases[index].Weight)
+			totalWeight += weight
+			caseStatus := callback.StatusSystemError
+			if index < len(result.Cases) {
+				caseStatus = result.Cases[index].Status
+			}
+			if caseStatus == callback.StatusAccepted {
+				acceptedWeight += weight
+			} else if item.Status == callback.StatusAccepted {
+				item.Status = caseStatus
+			}
+		}
+		if item.DependenciesMet {
+			switch {
+			case item.Status == callback.StatusAccepted:
+				item.Score = subtask.Score
+			case subtask.Aggregation == bundle.SubtaskAggregationSum:
+				item.Score = int(int64(subtask.Score) * acceptedWeight / totalWeight)
+			}
+		}
+		fullScore[subtask.ID] = item.Score == item.MaxScore
+		score += item.Score
+		result.Subtasks = append(result.Subtasks, item)
+	}
+	result.Score = copyInt(&score)
+	if score == total {
+		result.Status = callback.StatusAccepted
+		result.ExitCode = 0
+	} else {
+		result.Status = callback.StatusWrongAnswer
+	}
+	return result
+}
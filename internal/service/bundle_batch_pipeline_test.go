@@ -13,6 +13,7 @@ import (
 	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
 	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
 	judgesandbox "github.com/CodeRushOJ/croj-judging-server/internal/sandbox"
+	"github.com/CodeRushOJ/croj-judging-server/pkg/model"
 	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -116,26 +117,6 @@ func TestBatchBundlePipelineAllowsLegacyUnpinnedTokenChecker(t *testing.T) {
 	}
 }
 
-func TestBatchBundlePipelineRejectsOversizedBatchBeforeSandbox(t *testing.T) {
-	artifact := &memoryArtifact{
-		manifest: bundle.Manifest{SchemaVersion: 1, JudgeMode: bundle.JudgeModeACM, Checker: bundle.CheckerExact, Limits: bundle.Limits{TimeLimitMillis: 1000, MemoryLimitMiB: 64}},
-		contents: map[string]string{},
-	}
-	for index := 0; index < 257; index++ {
-		id := fmt.Sprintf("case-%03d", index)
-		input, output := id+".in", id+".out"
-		artifact.manifest.Cases = append(artifact.manifest.Cases, bundle.Case{ID: id, Input: input, Output: output, Weight: 1})
-		artifact.contents[input], artifact.contents[output] = "input", "output"
-	}
-	executor := &batchExecutorStub{}
-	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
-
-	result, err := pipeline.ExecuteArtifact(context.Background(), validBundleSubmission(), validExecutionConfig(), artifact)
-	if !errors.Is(err, ErrCanonicalInfrastructure) || result.Status != "" || len(executor.requests) != 0 {
-		t.Fatalf("result=%+v sandbox calls=%d", result, len(executor.requests))
-	}
-}
-
 func TestIncrementalBatchCaseWireSizeMatchesProtobuf(t *testing.T) {
 	request := &sandboxpb.ExecuteBatchV1Request{Language: "cpp", SourceCode: "int main(){}", Timeout: 2, MemoryLimit: 64}
 	incremental := proto.Size(request)
@@ -270,6 +251,67 @@ func TestBatchBundlePipelineSendsAllCasesInOneCompileOnceRequest(t *testing.T) {
 	}
 }
 
+type graderArtifact struct {
+	*memoryArtifact
+	files map[string][]bundle.CompileFile
+}
+
+func (artifact *graderArtifact) ReadGrader(language string) ([]bundle.CompileFile, error) {
+	files, ok := artifact.files[language]
+	if !ok {
+		return nil, errors.New("grader is not declared")
+	}
+	return files, nil
+}
+
+func graderTestArtifact() *graderArtifact {
+	artifact := exactArtifact(1)
+	artifact.manifest.SchemaVersion = 2
+	artifact.manifest.Graders = []bundle.Grader{{
+		Language: "cpp",
+		Sources:  []bundle.GraderFile{{Name: "grader.cpp", Source: "grader/grader.cpp", SourceSHA256: strings.Repeat("a", 64)}},
+		Headers:  []bundle.GraderFile{{Name: "solution.h", Source: "grader/solution.h", SourceSHA256: strings.Repeat("b", 64)}},
+	}}
+	return &graderArtifact{memoryArtifact: artifact, files: map[string][]bundle.CompileFile{"cpp": {
+		{Name: "grader.cpp", Content: "int main() { return solve(); }"},
+		{Name: "solution.h", Content: "int solve();", Header: true},
+	}}}
+}
+
+func TestBatchBundlePipelineCompilesGraderFilesWithContestantSource(t *testing.T) {
+	executor := &batchExecutorStub{events: []*sandboxpb.ExecuteBatchV1Event{
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "case-1", Result: &sandboxpb.ExecuteResponse{Status: "Accepted", Stdout: "one"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED},
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int solve() { return 0; }",
+	}, graderTestArtifact())
+	if err != nil || result.Status != callback.StatusAccepted {
+		t.Fatalf("result=%+v error=%v", result, err)
+	}
+	files := executor.requests[0].CompileFiles
+	if executor.requests[0].SourceCode != "int solve() { return 0; }" || len(files) != 2 ||
+		files[0].Name != "grader.cpp" || files[0].Header || files[1].Name != "solution.h" || !files[1].Header {
+		t.Fatalf("compile files = %+v", files)
+	}
+}
+
+func TestBatchBundlePipelineRejectsGraderBundlesOutsideDeclaredLanguages(t *testing.T) {
+	executor := &batchExecutorStub{}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	_, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{Language: "python", SourceCode: "def solve(): pass"}, graderTestArtifact())
+	if !errors.Is(err, ErrCanonicalInfrastructure) || len(executor.requests) != 0 {
+		t.Fatalf("err=%v calls=%d, want failure before sandbox", err, len(executor.requests))
+	}
+	result, err := pipeline.ExecuteArtifact(context.Background(), &model.Task{ID: 1, Language: "cpp", Code: "int solve();"}, validExecutionConfig(), graderTestArtifact())
+	if err != nil || result.Status != callback.StatusSystemError || len(executor.requests) != 0 {
+		t.Fatalf("result=%+v err=%v, want immutable problem versions to reject grader bundles", result, err)
+	}
+}
+
 func TestBatchBundlePipelineCanonicalRequestUsesBundleLimitsAndStopPolicy(t *testing.T) {
 	artifact := exactArtifact(1)
 	artifact.manifest.Limits = bundle.Limits{TimeLimitMillis: 1500, MemoryLimitMiB: 512}
@@ -394,6 +436,36 @@ func TestBatchBundlePipelineKeepsTokenExpectedOutputOutOfSandbox(t *testing.T) {
 	}
 }
 
+func TestBatchBundlePipelineComparesFloatOutputLocallyWithinTolerance(t *testing.T) {
+	artifact := exactArtifact(2)
+	artifact.manifest.SchemaVersion = 2
+	artifact.manifest.Checker = bundle.CheckerFloat
+	artifact.manifest.FloatTolerance = &bundle.FloatTolerance{AbsoluteEpsilon: 1e-6}
+	artifact.contents["case-1.out"], artifact.contents["case-2.out"] = "0.333333\n", "2.5\n"
+	executor := &batchExecutorStub{events: []*sandboxpb.ExecuteBatchV1Event{
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "case-1", Result: &sandboxpb.ExecuteResponse{Status: "Accepted", Stdout: "0.3333333333"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "case-2", Result: &sandboxpb.ExecuteResponse{Status: "Accepted", Stdout: "2.4999"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED},
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "cpp", SourceCode: "int main(){}", StopOnFailure: true,
+	}, artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusWrongAnswer || result.Cases[0].Status != callback.StatusAccepted ||
+		result.Cases[1].Status != callback.StatusWrongAnswer {
+		t.Fatalf("float result = %+v", result)
+	}
+	for _, requestCase := range executor.requests[0].Cases {
+		if requestCase.ExpectedOutput != "" || requestCase.CompareOutput || requestCase.TokenExpectedSha256 != "" {
+			t.Fatalf("float expected output reached the sandbox: %+v", requestCase)
+		}
+	}
+}
+
 func TestBatchBundlePipelineRechecksTokenVerdictFromRetainedHash(t *testing.T) {
 	artifact := exactArtifact(1)
 	artifact.manifest.Checker = bundle.CheckerToken
@@ -456,6 +528,44 @@ func TestBatchBundlePipelineScoresEveryOICaseDeterministically(t *testing.T) {
 	}
 }
 
+func TestBatchBundlePipelineScoresV3SubtasksWithDependencies(t *testing.T) {
+	total := 100
+	artifact := exactArtifact(3)
+	artifact.manifest.SchemaVersion = 3
+	artifact.manifest.JudgeMode = bundle.JudgeModeOI
+	artifact.manifest.TotalScore = &total
+	artifact.manifest.Cases[2].Weight = 3
+	artifact.manifest.Subtasks = []bundle.Subtask{
+		{ID: "small", Score: 20, Aggregation: bundle.SubtaskAggregationMin, Cases: []string{"case-1"}},
+		{ID: "partial", Score: 40, Aggregation: bundle.SubtaskAggregationSum, Cases: []string{"case-2", "case-3"}},
+		{ID: "full", Score: 40, Aggregation: bundle.SubtaskAggregationMin, Cases: []string{"case-1", "case-2"}, Dependencies: []string{"partial"}},
+	}
+	executor := &batchExecutorStub{events: []*sandboxpb.ExecuteBatchV1Event{
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "case-1", Result: &sandboxpb.ExecuteResponse{Status: "Accepted", Stdout: "one\n"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "case-2", Result: &sandboxpb.ExecuteResponse{Status: "Accepted", Stdout: "two\n"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "case-3", Result: &sandboxpb.ExecuteResponse{Status: "Wrong Answer"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED},
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "go", SourceCode: "package main", StopOnFailure: true,
+	}, artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CanonicalSubtaskResult{
+		{SubtaskID: "small", Status: callback.StatusAccepted, Score: 20, MaxScore: 20, DependenciesMet: true},
+		{SubtaskID: "partial", Status: callback.StatusWrongAnswer, Score: 10, MaxScore: 40, DependenciesMet: true},
+		{SubtaskID: "full", Status: callback.StatusAccepted, Score: 0, MaxScore: 40, DependenciesMet: false},
+	}
+	if fmt.Sprint(result.Subtasks) != fmt.Sprint(want) || result.Status != callback.StatusWrongAnswer ||
+		result.Score == nil || *result.Score != 30 || result.TotalScore == nil || *result.TotalScore != 100 ||
+		result.Cases[0].Score != nil || executor.requests[0].StopOnFailure {
+		t.Fatalf("result=%+v subtasks=%+v", result, result.Subtasks)
+	}
+}
+
 func TestBatchBundlePipelineRunsSpecialJudgeThroughASecondSandboxBatch(t *testing.T) {
 	artifact, config := specialJudgeArtifact(t, bundle.JudgeModeACM, []int{1, 1})
 	executor := &sequenceBatchExecutor{eventSets: [][]*sandboxpb.ExecuteBatchV1Event{
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// SandboxInteractiveExecutor is implemented by executors that also speak the
+// ExecuteInteractiveV1 protocol. Interactive bundles fail closed without it.
+type SandboxInteractiveExecutor interface {
+	ExecuteInteractive(context.Context, string, *sandboxpb.ExecuteInteractiveV1Request) ([]*sandboxpb.ExecuteInteractiveV1Event, error)
+}
+
+// SandboxObservedInteractiveExecutor is the interactive counterpart of
+// SandboxObservedBatchExecutor.
+type SandboxObservedInteractiveExecutor interface {
+	ExecuteInteractiveObserved(context.Context, string, *sandboxpb.ExecuteInteractiveV1Request, func(*sandboxpb.ExecuteInteractiveV1Event)) ([]*sandboxpb.ExecuteInteractiveV1Event, error)
+}
+
+type InteractorArtifact interface {
+	ReadInteractor() (string, error)
+}
+
+const idlenessLimitExceededStatus = "Idleness Limit Exceeded"
+const executeInteractiveCasesFieldNumber = protowire.Number(10)
+
+func (pipeline *BatchBundlePipeline) executeInteractive(
+	ctx context.Context,
+	input CanonicalExecutionRequest,
+	manifest bundle.Manifest,
+	artifact CaseArtifact,
+	compileFiles []*sandboxpb.CompileFile,
+) (CanonicalResult, error) {
+	executor, ok := pipeline.executor.(SandboxInteractiveExecutor)
+	if !ok {
+		return CanonicalResult{}, fmt.Errorf("%w: sandbox executor does not support interactive batches", ErrCanonicalInfrastructure)
+	}
+	interactorArtifact, ok := artifact.(InteractorArtifact)
+	if !ok || manifest.Interactor == nil {
+		return CanonicalResult{}, fmt.Errorf("%w: interactor artifact is incomplete", ErrCanonicalInfrastructure)
+	}
+	interactor, err := interactorArtifact.ReadInteractor()
+	if err != nil || interactor == "" {
+		return CanonicalResult{}, fmt.Errorf("%w: interactor source is unavailable", ErrCanonicalInfrastructure)
+	}
+	stopOnFailure := input.StopOnFailure && manifest.JudgeMode == bundle.JudgeModeACM
+	newRequest := func() *sandboxpb.ExecuteInteractiveV1Request {
+		return &sandboxpb.ExecuteInteractiveV1Request{
+			Language:              input.Language,
+			SourceCode:            input.SourceCode,
+			Timeout:               timeoutSeconds(manifest.Limits.TimeLimitMillis),
+			MemoryLimit:           boundedInt32(manifest.Limits.MemoryLimitMiB),
+			StopOnFailure:         stopOnFailure,
+			InteractorLanguage:    manifest.Interactor.Language,
+			InteractorSourceCode:  interactor,
+			InteractorTimeout:     timeoutSeconds(manifest.Interactor.TimeLimitMillis),
+			InteractorMemoryLimit: boundedInt32(manifest.Interactor.MemoryLimitMiB),
+			CompileFiles:          compileFiles,
+		}
+	}
+	plan := caseShardPlan{baseBytes: proto.Size(newRequest()), maxBytes: pipeline.maxBatchRequestBytes()}
+	if plan.baseBytes > plan.maxBytes {
+		return CanonicalResult{}, fmt.Errorf("%w: submission exceeds sandbox batch byte limit", ErrCanonicalInfrastructure)
+	}
+	shards := make([]*sandboxpb.ExecuteInteractiveV1Request, 0, 1)
+	for index, testCase := range manifest.Cases {
+		caseInput, answer, err := artifact.ReadCase(testCase)
+		if err != nil {
+			return CanonicalResult{}, fmt.Errorf("%w: bundle case could not be read", ErrCanonicalInfrastructure)
+		}
+		requestCase := &sandboxpb.ExecuteInteractiveV1Case{CaseId: testCase.ID, Input: caseInput, Answer: answer}
+		caseBytes := proto.Size(requestCase)
+		opened, ok := plan.place(index, protowire.SizeTag(executeInteractiveCasesFieldNumber)+protowire.SizeVarint(uint64(caseBytes))+caseBytes)
+		if !ok {
+			return CanonicalResult{}, fmt.Errorf("%w: bundle exceeds sandbox batch byte limit", ErrCanonicalInfrastructure)
+		}
+		if opened {
+			shards = append(shards, newRequest())
+		}
+		shards[len(shards)-1].Cases = append(shards[len(shards)-1].Cases, requestCase)
+	}
+	observed, _ := executor.(SandboxObservedInteractiveExecutor)
+	var observe func(*sandboxpb.ExecuteInteractiveV1Event)
+	if observed != nil && input.Progress != nil {
+		observe = interactiveCaseProgress(ctx, input.Progress)
+	}
+	shardEvents, invalidResponse, err := executeShards(ctx, shards, stopOnFailure, pipeline.shardConcurrency(),
+		func(ctx context.Context, request *sandboxpb.ExecuteInteractiveV1Request) ([]*sandboxpb.ExecuteInteractiveV1Event, bool, error) {
+			events, invalidResponse, err := executeOnDistinctSandboxes(ctx, pipeline, "interactive",
+				func(address string) ([]*sandboxpb.ExecuteInteractiveV1Event, error) {
+					if observe != nil {
+						return observed.ExecuteInteractiveObserved(ctx, address, request, observe)
+					}
+					return executor.ExecuteInteractive(ctx, address, request)
+				},
+				func(events []*sandboxpb.ExecuteInteractiveV1Event) error {
+					return validateInteractiveEvents(request, events)
+				},
+			)
+			if err == nil && !invalidResponse && input.Progress != nil && observe == nil {
+				// Interactor failures surface through the merged result instead.
+				if shardResult, aggregateErr := aggregateInteractiveResult(events); aggregateErr == nil {
+					reportShardProgress(ctx, input.Progress, shardResult, true)
+				}
+			}
+			return events, invalidResponse, err
+		},
+		func(_ *sandboxpb.ExecuteInteractiveV1Request, events []*sandboxpb.ExecuteInteractiveV1Event) bool {
+			return interactiveShardFailed(events)
+		},
+	)
+	if err != nil {
+		return CanonicalResult{}, err
+	}
+	if invalidResponse {
+		return CanonicalResult{}, fmt.Errorf("%w: sandbox interactive response was invalid", ErrCanonicalInfrastructure)
+	}
+	events := mergeInteractiveShardEvents(shardEvents)
+	result, err := aggregateInteractiveResult(events)
+	if err != nil {
+		return CanonicalResult{}, err
+	}
+	return applyManifestScoring(manifest, result), nil
+}
+
+// interactiveCaseProgress reports every case as its event arrives from the
+// sandbox. A case the interactor failed to judge is left to the merged result.
+func interactiveCaseProgress(ctx context.Context, progress CanonicalProgress) func(*sandboxpb.ExecuteInteractiveV1Event) {
+	return func(event *sandboxpb.ExecuteInteractiveV1Event) {
+		switch event.Kind {
+		case sandboxpb.ExecuteInteractiveV1Event_COMPILE_ERROR:
+			progress.CompileFinished(ctx, false)
+		case sandboxpb.ExecuteInteractiveV1Event_CASE_RESULT:
+			if event.Result == nil {
+				return
+			}
+			caseResult, err := interactiveCaseResult(event)
+			if err != nil {
+				return
+			}
+			progress.CompileFinished(ctx, true)
+			progress.CasesJudged(ctx, []CanonicalCaseResult{caseResult})
+		}
+	}
+}
+
+func validateInteractiveEvents(request *sandboxpb.ExecuteInteractiveV1Request, events []*sandboxpb.ExecuteInteractiveV1Event) error {
+	if request == nil || len(events) == 0 {
+		return fmt.Errorf("interactive response is empty")
+	}
+	if len(events) == 1 && events[0] != nil {
+		switch events[0].Kind {
+		case sandboxpb.ExecuteInteractiveV1Event_COMPILE_ERROR:
+			if events[0].CaseId != "" || events[0].Result == nil || events[0].Result.Status != "Compile Error" {
+				return fmt.Errorf("compile event is malformed")
+			}
+			return nil
+		case sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_COMPILE_ERROR:
+			if events[0].CaseId != "" {
+				return fmt.Errorf("interactor compile event is malformed")
+			}
+			return nil
+		}
+	}
+	caseIndex := 0
+	for eventIndex, event := range events {
+		if event == nil {
+			return fmt.Errorf("event %d is nil", eventIndex)
+		}
+		switch event.Kind {
+		case sandboxpb.ExecuteInteractiveV1Event_CASE_RESULT:
+			if request.StopOnFailure && caseIndex > 0 && interactiveCaseFailed(events[eventIndex-1]) {
+				return fmt.Errorf("interactive batch continued after a failed case")
+			}
+			if caseIndex >= len(request.Cases) || event.Result == nil || event.CaseId != request.Cases[caseIndex].CaseId ||
+				!isKnownInteractiveContestantStatus(event.Result.Status) ||
+				event.InteractorVerdict == sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_VERDICT_UNSPECIFIED ||
+				event.Result.Status == "Accepted" && event.InteractorVerdict == sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_NOT_FINISHED {
+				return fmt.Errorf("case event %d is malformed", eventIndex)
+			}
+			caseIndex++
+		case sandboxpb.ExecuteInteractiveV1Event_COMPLETED:
+			if eventIndex != len(events)-1 || event.CaseId != "" || event.Result != nil || caseIndex == 0 {
+				return fmt.Errorf("completion event is malformed")
+			}
+			if caseIndex != len(request.Cases) && (!request.StopOnFailure || !interactiveCaseFailed(events[eventIndex-1])) {
+				return fmt.Errorf("interactive batch completed before all required cases")
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected interactive event kind %s", event.Kind)
+		}
+	}
+	return fmt.Errorf("interactive completion event is missing")
+}
+
+func interactiveShardFailed(events []*sandboxpb.ExecuteInteractiveV1Event) bool {
+	if events[0].Kind != sandboxpb.ExecuteInteractiveV1Event_CASE_RESULT {
+		return true
+	}
+	for _, event := range events[:len(events)-1] {
+		if interactiveCaseFailed(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeInteractiveShardEvents mirrors mergeBatchShardEvents. Either compile
+// error ends the submission, whichever shard reported it.
+func mergeInteractiveShardEvents(shardEvents [][]*sandboxpb.ExecuteInteractiveV1Event) []*sandboxpb.ExecuteInteractiveV1Event {
+	if len(shardEvents) == 1 {
+		return shardEvents[0]
+	}
+	merged := make([]*sandboxpb.ExecuteInteractiveV1Event, 0)
+	for _, events := range shardEvents {
+		if events[0].Kind != sandboxpb.ExecuteInteractiveV1Event_CASE_RESULT {
+			return events
+		}
+		merged = append(merged, events[:len(events)-1]...)
+	}
+	return append(merged, &sandboxpb.ExecuteInteractiveV1Event{Kind: sandboxpb.ExecuteInteractiveV1Event_COMPLETED})
+}
+
+func isKnownInteractiveContestantStatus(value string) bool {
+	// The contestant stdout is judged only by the interactor, so the sandbox
+	// never reports its own Wrong Answer or Compile Error per case.
+	return value == idlenessLimitExceededStatus ||
+		isKnownContestantStatus(value) && value != "Wrong Answer" && value != "Compile Error"
+}
+
+func interactiveCaseFailed(event *sandboxpb.ExecuteInteractiveV1Event) bool {
+	return event.Result.Status != "Accepted" ||
+		event.InteractorVerdict != sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED
+}
+
+// mapInteractiveCase applies the interactive verdict precedence: a rejection
+// reported by the interactor wins, because a contestant usually crashes on a
+// closed pipe after the interactor exits; otherwise a contestant crash, limit
+// or idleness wins; only then is an interactor failure blamed on the tenant.
+// Idleness keeps its own verdict: a contestant that blocks on a read used
+// little CPU time and did not exceed the time limit.
+func mapInteractiveCase(event *sandboxpb.ExecuteInteractiveV1Event) (callback.Status, error) {
+	switch {
+	case event.InteractorVerdict == sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_WRONG_ANSWER:
+		return callback.StatusWrongAnswer, nil
+	case event.Result.Status == idlenessLimitExceededStatus:
+		return callback.StatusIdlenessLimitExceeded, nil
+	case event.Result.Status != "Accepted":
+		return mapBundleStatus(event.Result.Status), nil
+	case event.InteractorVerdict == sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED:
+		return callback.StatusAccepted, nil
+	default:
+		return "", fmt.Errorf("%w: interactor did not execute successfully", ErrTenantCheckerFailure)
+	}
+}
+
+func interactiveCaseResult(event *sandboxpb.ExecuteInteractiveV1Event) (CanonicalCaseResult, error) {
+	caseStatus, err := mapInteractiveCase(event)
+	if err != nil {
+		return CanonicalCaseResult{}, err
+	}
+	return CanonicalCaseResult{
+		CaseID: event.CaseId, Status: caseStatus,
+		TimeUsedMillis: boundedMetric(event.Result.TimeUsed, 86_400_000),
+		MemoryUsedKB:   boundedMetric(event.Result.MemoryUsed, 2_147_483_647),
+	}, nil
+}
+
+func aggregateInteractiveResult(events []*sandboxpb.ExecuteInteractiveV1Event) (CanonicalResult, error) {
+	switch events[0].Kind {
+	case sandboxpb.ExecuteInteractiveV1Event_COMPILE_ERROR:
+		return CanonicalResult{
+			Status:       callback.StatusCompileError,
+			CompileError: "compilation failed; diagnostics redacted",
+			Stderr:       "compilation failed",
+		}, nil
+	case sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_COMPILE_ERROR:
+		return CanonicalResult{}, fmt.Errorf("%w: interactor compilation failed", ErrTenantCheckerFailure)
+	}
+	result := CanonicalResult{Status: callback.StatusAccepted, Cases: make([]CanonicalCaseResult, 0, len(events)-1)}
+	summaries := make([]string, 0, len(events)-1)
+	for _, event := range events[:len(events)-1] {
+		caseResult, err := interactiveCaseResult(event)
+		if err != nil {
+			return CanonicalResult{}, err
+		}
+		caseStatus := caseResult.Status
+		result.TimeUsedMillis = max(result.TimeUsedMillis, boundedMetric(event.Result.TimeUsed, 86_400_000))
+		result.MemoryUsedKB = max(result.MemoryUsedKB, boundedMetric(event.Result.MemoryUsed, 2_147_483_647))
+		if result.Status == callback.StatusAccepted && caseStatus != callback.StatusAccepted {
+			result.Status = caseStatus
+			result.ExitCode = int(event.Result.ExitCode)
+		}
+		result.Cases = append(result.Cases, caseResult)
+		summaries = append(summaries, fmt.Sprintf(
+			"case=%s sandboxStatus=%s interactor=%s status=%s",
+			event.CaseId, event.Result.Status, event.InteractorVerdict, caseStatus,
+		))
+	}
+	result.Stderr = callback.TruncateUTF16(strings.Join(summaries, ";"), 65_536)
+	if result.Status == callback.StatusAccepted {
+		result.ExitCode = 0
+	}
+	return result, nil
+}
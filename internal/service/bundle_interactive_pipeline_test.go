@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+)
+
+type interactiveExecutorStub struct {
+	batchExecutorStub
+	interactiveRequests []*sandboxpb.ExecuteInteractiveV1Request
+	interactiveEvents   []*sandboxpb.ExecuteInteractiveV1Event
+}
+
+func (executor *interactiveExecutorStub) ExecuteInteractive(
+	_ context.Context,
+	address string,
+	request *sandboxpb.ExecuteInteractiveV1Request,
+) ([]*sandboxpb.ExecuteInteractiveV1Event, error) {
+	executor.interactiveRequests = append(executor.interactiveRequests, request)
+	executor.addresses = append(executor.addresses, address)
+	return executor.interactiveEvents, nil
+}
+
+type interactiveArtifact struct {
+	*memoryArtifact
+	interactorSource string
+}
+
+func (artifact *interactiveArtifact) ReadInteractor() (string, error) {
+	return artifact.interactorSource, nil
+}
+
+func interactiveTestArtifact(mode bundle.JudgeMode, count int) *interactiveArtifact {
+	source := "int main(){return 0;}"
+	digest := sha256.Sum256([]byte(source))
+	artifact := exactArtifact(count)
+	artifact.manifest.SchemaVersion = 2
+	artifact.manifest.JudgeMode = mode
+	artifact.manifest.Checker = bundle.CheckerInteractive
+	artifact.manifest.Interactor = &bundle.Interactor{
+		Language: "cpp", Source: "interactor/main.cpp", SourceSHA256: hex.EncodeToString(digest[:]),
+		TimeLimitMillis: 3000, MemoryLimitMiB: 256,
+	}
+	if mode == bundle.JudgeModeOI {
+		total := count
+		artifact.manifest.TotalScore = &total
+	}
+	return &interactiveArtifact{memoryArtifact: artifact, interactorSource: source}
+}
+
+func interactiveCaseEvent(caseID, status string, verdict sandboxpb.ExecuteInteractiveV1Event_InteractorVerdict) *sandboxpb.ExecuteInteractiveV1Event {
+	return &sandboxpb.ExecuteInteractiveV1Event{
+		Kind: sandboxpb.ExecuteInteractiveV1Event_CASE_RESULT, CaseId: caseID,
+		Result: &sandboxpb.ExecuteResponse{Status: status, TimeUsed: 7, MemoryUsed: 1024}, InteractorVerdict: verdict,
+	}
+}
+
+func interactiveCompleted() *sandboxpb.ExecuteInteractiveV1Event {
+	return &sandboxpb.ExecuteInteractiveV1Event{Kind: sandboxpb.ExecuteInteractiveV1Event_COMPLETED}
+}
+
+func TestBatchBundlePipelineSendsInteractorAndAnswersInOneInteractiveRequest(t *testing.T) {
+	artifact := interactiveTestArtifact(bundle.JudgeModeACM, 2)
+	executor := &interactiveExecutorStub{interactiveEvents: []*sandboxpb.ExecuteInteractiveV1Event{
+		interactiveCaseEvent("case-1", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED),
+		interactiveCaseEvent("case-2", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED),
+		interactiveCompleted(),
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "go", SourceCode: "package main", StopOnFailure: true,
+	}, artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusAccepted || len(result.Cases) != 2 || result.TimeUsedMillis != 7 {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(executor.requests) != 0 || len(executor.interactiveRequests) != 1 {
+		t.Fatalf("batch calls=%d interactive calls=%d, want one interactive call", len(executor.requests), len(executor.interactiveRequests))
+	}
+	request := executor.interactiveRequests[0]
+	if request.InteractorLanguage != "cpp" || request.InteractorSourceCode != "int main(){return 0;}" ||
+		request.InteractorTimeout != 3 || request.InteractorMemoryLimit != 256 ||
+		request.Timeout != 1 || request.MemoryLimit != 64 || !request.StopOnFailure {
+		t.Fatalf("interactive request = %+v", request)
+	}
+	if request.Cases[1].Input != "input-2" || request.Cases[1].Answer != "two\n" {
+		t.Fatalf("interactive case = %+v, want input and answer forwarded to the interactor", request.Cases[1])
+	}
+}
+
+func TestBatchBundlePipelineMapsInteractiveVerdictPrecedence(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		status  string
+		verdict sandboxpb.ExecuteInteractiveV1Event_InteractorVerdict
+		want    callback.Status
+	}{
+		{name: "interactor rejection beats contestant crash", status: "Runtime Error", verdict: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_WRONG_ANSWER, want: callback.StatusWrongAnswer},
+		{name: "idleness keeps its own verdict", status: idlenessLimitExceededStatus, verdict: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_NOT_FINISHED, want: callback.StatusIdlenessLimitExceeded},
+		{name: "contestant time limit", status: "Time Limit Exceeded", verdict: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_NOT_FINISHED, want: callback.StatusTimeLimitExceeded},
+		{name: "contestant crash before interactor verdict", status: "Runtime Error", verdict: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_NOT_FINISHED, want: callback.StatusRuntimeError},
+		{name: "contestant crash beats interactor failure", status: "Memory Limit Exceeded", verdict: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_FAILED, want: callback.StatusMemoryLimitExceeded},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			executor := &interactiveExecutorStub{interactiveEvents: []*sandboxpb.ExecuteInteractiveV1Event{
+				interactiveCaseEvent("case-1", test.status, test.verdict),
+				interactiveCompleted(),
+			}}
+			pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+			result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{Language: "go", SourceCode: "package main"}, interactiveTestArtifact(bundle.JudgeModeACM, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != test.want || result.Cases[0].Status != test.want ||
+				!strings.Contains(result.Stderr, "sandboxStatus="+test.status) {
+				t.Fatalf("result = %+v, want %s", result, test.want)
+			}
+		})
+	}
+}
+
+func TestBatchBundlePipelineScoresInteractiveOICases(t *testing.T) {
+	executor := &interactiveExecutorStub{interactiveEvents: []*sandboxpb.ExecuteInteractiveV1Event{
+		interactiveCaseEvent("case-1", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_WRONG_ANSWER),
+		interactiveCaseEvent("case-2", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED),
+		interactiveCompleted(),
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "go", SourceCode: "package main", StopOnFailure: true,
+	}, interactiveTestArtifact(bundle.JudgeModeOI, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusWrongAnswer || result.Score == nil || *result.Score != 1 ||
+		executor.interactiveRequests[0].StopOnFailure {
+		t.Fatalf("OI interactive result = %+v", result)
+	}
+}
+
+func TestBatchBundlePipelineRoutesInteractorFailuresToTenantCheckerFailure(t *testing.T) {
+	for name, events := range map[string][]*sandboxpb.ExecuteInteractiveV1Event{
+		"interactor failed": {
+			interactiveCaseEvent("case-1", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_FAILED),
+			interactiveCompleted(),
+		},
+		"interactor compile error": {{Kind: sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_COMPILE_ERROR}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			executor := &interactiveExecutorStub{interactiveEvents: events}
+			pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+			_, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{Language: "go", SourceCode: "package main"}, interactiveTestArtifact(bundle.JudgeModeACM, 1))
+			if !errors.Is(err, ErrTenantCheckerFailure) {
+				t.Fatalf("err = %v, want tenant checker failure", err)
+			}
+		})
+	}
+}
+
+func TestBatchBundlePipelineReportsInteractiveContestantCompileError(t *testing.T) {
+	executor := &interactiveExecutorStub{interactiveEvents: []*sandboxpb.ExecuteInteractiveV1Event{{
+		Kind:   sandboxpb.ExecuteInteractiveV1Event_COMPILE_ERROR,
+		Result: &sandboxpb.ExecuteResponse{Status: "Compile Error", Stderr: "/tmp/private/main.go: secret"},
+	}}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{Language: "go", SourceCode: "package main"}, interactiveTestArtifact(bundle.JudgeModeACM, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusCompileError || strings.Contains(result.CompileError, "secret") {
+		t.Fatalf("result = %+v, want redacted compile error", result)
+	}
+}
+
+func TestBatchBundlePipelineRejectsMalformedInteractiveStreams(t *testing.T) {
+	for name, events := range map[string][]*sandboxpb.ExecuteInteractiveV1Event{
+		"missing completion": {interactiveCaseEvent("case-1", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED)},
+		"unspecified verdict": {
+			interactiveCaseEvent("case-1", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_VERDICT_UNSPECIFIED),
+			interactiveCompleted(),
+		},
+		"accepted but unfinished": {
+			interactiveCaseEvent("case-1", "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_NOT_FINISHED),
+			interactiveCompleted(),
+		},
+		"sandbox-side wrong answer": {
+			interactiveCaseEvent("case-1", "Wrong Answer", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED),
+			interactiveCompleted(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			executor := &interactiveExecutorStub{interactiveEvents: events}
+			pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b"}}, executor, 2)
+
+			_, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{Language: "go", SourceCode: "package main"}, interactiveTestArtifact(bundle.JudgeModeACM, 1))
+			if !errors.Is(err, ErrCanonicalInfrastructure) || errors.Is(err, ErrTenantCheckerFailure) || len(executor.interactiveRequests) != 1 {
+				t.Fatalf("err=%v calls=%d, want one non-retried infrastructure failure", err, len(executor.interactiveRequests))
+			}
+		})
+	}
+}
+
+func TestBatchBundlePipelineFailsClosedWithoutInteractiveExecutor(t *testing.T) {
+	executor := &batchExecutorStub{}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+
+	_, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{Language: "go", SourceCode: "package main"}, interactiveTestArtifact(bundle.JudgeModeACM, 1))
+	if !errors.Is(err, ErrCanonicalInfrastructure) || len(executor.requests) != 0 {
+		t.Fatalf("err=%v batch calls=%d, want infrastructure failure before sandbox", err, len(executor.requests))
+	}
+}
+
+type acceptingInteractiveExecutor struct {
+	batchExecutorStub
+	interactiveRequests []*sandboxpb.ExecuteInteractiveV1Request
+}
+
+func (executor *acceptingInteractiveExecutor) ExecuteInteractive(
+	_ context.Context,
+	address string,
+	request *sandboxpb.ExecuteInteractiveV1Request,
+) ([]*sandboxpb.ExecuteInteractiveV1Event, error) {
+	executor.interactiveRequests = append(executor.interactiveRequests, request)
+	executor.addresses = append(executor.addresses, address)
+	events := make([]*sandboxpb.ExecuteInteractiveV1Event, 0, len(request.Cases)+1)
+	for _, requestCase := range request.Cases {
+		events = append(events, interactiveCaseEvent(requestCase.CaseId, "Accepted", sandboxpb.ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED))
+	}
+	return append(events, interactiveCompleted()), nil
+}
+
+func TestBatchBundlePipelineShardsLargeInteractiveBundles(t *testing.T) {
+	artifact := interactiveTestArtifact(bundle.JudgeModeACM, 1)
+	artifact.manifest.Cases = nil
+	for index := range 300 {
+		id := fmt.Sprintf("case-%03d", index)
+		artifact.manifest.Cases = append(artifact.manifest.Cases, bundle.Case{ID: id, Input: id + ".in", Output: id + ".out", Weight: 1})
+		artifact.contents[id+".in"], artifact.contents[id+".out"] = "input", "answer"
+	}
+	executor := &acceptingInteractiveExecutor{}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a", "sandbox-b"}}, executor, 1)
+
+	result, err := pipeline.ExecuteCanonical(context.Background(), CanonicalExecutionRequest{
+		Language: "go", SourceCode: "package main", StopOnFailure: true,
+	}, artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != callback.StatusAccepted || len(result.Cases) != 300 || result.Cases[299].CaseID != "case-299" {
+		t.Fatalf("result status=%s cases=%d", result.Status, len(result.Cases))
+	}
+	if len(executor.interactiveRequests) != 2 || len(executor.interactiveRequests[0].Cases) != 256 ||
+		len(executor.interactiveRequests[1].Cases) != 44 || executor.interactiveRequests[1].InteractorSourceCode == "" ||
+		executor.addresses[0] == executor.addresses[1] {
+		t.Fatalf("interactive shards=%d addresses=%v", len(executor.interactiveRequests), executor.addresses)
+	}
+}
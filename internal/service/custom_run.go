@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+)
+
+const customRunCaseID = "run"
+
+// CustomRunRequest executes caller-owned source against caller-owned input.
+// Limits are already capped by the tenant policy; nothing here is hidden
+// from the caller, so compiler diagnostics are returned instead of redacted.
+type CustomRunRequest struct {
+	Language        string
+	SourceCode      string
+	Stdin           string
+	ExpectedOutput  string
+	CompareOutput   bool
+	TimeLimitMillis int
+	MemoryLimitMiB  int
+}
+
+func (request CustomRunRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("language", request.Language),
+		slog.Int("source_bytes", len(request.SourceCode)),
+		slog.Int("stdin_bytes", len(request.Stdin)),
+	)
+}
+
+type CustomRunOutput struct {
+	Status         callback.Status
+	ExitCode       int
+	TimeUsedMillis int
+	MemoryUsedKB   int
+	Stdout         string
+	Stderr         string
+	CompileError   string
+}
+
+// ExecuteCustomRun sends one single-case compile-once batch through the same
+// distinct-sandbox retry path as bundle judging. Expected output is compared
+// locally with the token checker, so it never reaches the sandbox.
+func (pipeline *BatchBundlePipeline) ExecuteCustomRun(ctx context.Context, input CustomRunRequest) (CustomRunOutput, error) {
+	if pipeline == nil || strings.TrimSpace(input.Language) == "" || input.SourceCode == "" ||
+		input.TimeLimitMillis <= 0 || input.MemoryLimitMiB <= 0 {
+		return CustomRunOutput{}, fmt.Errorf("custom run language, source and limits are required")
+	}
+	request := &sandboxpb.ExecuteBatchV1Request{
+		Language:    input.Language,
+		SourceCode:  input.SourceCode,
+		Timeout:     timeoutSeconds(input.TimeLimitMillis),
+		MemoryLimit: boundedInt32(input.MemoryLimitMiB),
+		Cases:       []*sandboxpb.ExecuteBatchV1Case{{CaseId: customRunCaseID, Stdin: input.Stdin}},
+	}
+	events, invalidResponse, err := pipeline.executeBatch(ctx, request, nil)
+	if err != nil {
+		return CustomRunOutput{}, err
+	}
+	if invalidResponse {
+		return CustomRunOutput{}, fmt.Errorf("%w: sandbox custom run response was invalid", ErrCanonicalInfrastructure)
+	}
+	if events[0].Kind == sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR {
+		return CustomRunOutput{
+			Status:       callback.StatusCompileError,
+			CompileError: firstDiagnostic(events[0].Result.CompileError, events[0].Result.Error, "compilation failed"),
+		}, nil
+	}
+	result := events[0].Result
+	status := mapBundleStatus(result.Status)
+	if status == callback.StatusSystemError {
+		return CustomRunOutput{}, fmt.Errorf("%w: sandbox returned a system error", ErrCanonicalInfrastructure)
+	}
+	if status == callback.StatusAccepted && input.CompareOutput && !outputsMatch(bundle.CheckerToken, result.Stdout, input.ExpectedOutput) {
+		status = callback.StatusWrongAnswer
+	}
+	return CustomRunOutput{
+		Status:         status,
+		ExitCode:       int(result.ExitCode),
+		TimeUsedMillis: boundedMetric(result.TimeUsed, 86_400_000),
+		MemoryUsedKB:   boundedMetric(result.MemoryUsed, 2_147_483_647),
+		Stdout:         callback.TruncateUTF16(result.Stdout, 65_536),
+		Stderr:         callback.TruncateUTF16(result.Stderr, 65_536),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	sandboxpb "github.com/CodeRushOJ/croj-judging-server/proto"
+)
+
+func TestCustomRunSendsStdinWithoutExpectedOutputAndComparesTokensLocally(t *testing.T) {
+	executor := &batchExecutorStub{events: []*sandboxpb.ExecuteBatchV1Event{
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "run", Result: &sandboxpb.ExecuteResponse{
+			Status: "Accepted", Stdout: "3 \n", Stderr: "debug", TimeUsed: 12, MemoryUsed: 2048,
+		}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED},
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+	output, err := pipeline.ExecuteCustomRun(context.Background(), CustomRunRequest{
+		Language: "cpp", SourceCode: "int main(){}", Stdin: "1 2\n",
+		ExpectedOutput: "4", CompareOutput: true, TimeLimitMillis: 1500, MemoryLimitMiB: 64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Status != callback.StatusWrongAnswer || output.Stdout != "3 \n" || output.Stderr != "debug" ||
+		output.TimeUsedMillis != 12 || output.MemoryUsedKB != 2048 {
+		t.Fatalf("output = %+v", output)
+	}
+	request := executor.requests[0]
+	if request.Timeout != 2 || request.MemoryLimit != 64 || len(request.Cases) != 1 ||
+		request.Cases[0].Stdin != "1 2\n" || request.Cases[0].ExpectedOutput != "" || request.Cases[0].CompareOutput {
+		t.Fatalf("sandbox request = %+v", request)
+	}
+}
+
+func TestCustomRunReturnsCallerCompileDiagnostics(t *testing.T) {
+	executor := &batchExecutorStub{events: []*sandboxpb.ExecuteBatchV1Event{{
+		Kind:   sandboxpb.ExecuteBatchV1Event_COMPILE_ERROR,
+		Result: &sandboxpb.ExecuteResponse{Status: "Compile Error", CompileError: "main.cpp:1: expected ';'"},
+	}}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+	output, err := pipeline.ExecuteCustomRun(context.Background(), CustomRunRequest{
+		Language: "cpp", SourceCode: "int main(){", TimeLimitMillis: 1000, MemoryLimitMiB: 64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Status != callback.StatusCompileError || output.CompileError != "main.cpp:1: expected ';'" {
+		t.Fatalf("output = %+v", output)
+	}
+}
+
+func TestCustomRunRoutesSandboxSystemErrorToInfrastructureFailure(t *testing.T) {
+	executor := &batchExecutorStub{events: []*sandboxpb.ExecuteBatchV1Event{
+		{Kind: sandboxpb.ExecuteBatchV1Event_CASE_RESULT, CaseId: "run", Result: &sandboxpb.ExecuteResponse{Status: "System Error"}},
+		{Kind: sandboxpb.ExecuteBatchV1Event_COMPLETED},
+	}}
+	pipeline := NewBatchBundlePipeline(&sequenceSelector{endpoints: []string{"sandbox-a"}}, executor, 1)
+	if _, err := pipeline.ExecuteCustomRun(context.Background(), CustomRunRequest{
+		Language: "cpp", SourceCode: "int main(){}", TimeLimitMillis: 1000, MemoryLimitMiB: 64,
+	}); !errors.Is(err, ErrCanonicalInfrastructure) {
+		t.Fatalf("system error = %v", err)
+	}
+}
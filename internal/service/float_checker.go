@@ -0,0 +1,53 @@
+package service
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+)
+
+// decimalTokenPattern deliberately excludes the hexadecimal, infinity, NaN and
+// underscore forms strconv.ParseFloat would otherwise accept from contestants.
+var decimalTokenPattern = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// floatOutputsMatch compares whitespace-separated tokens. When the expected
+// token is a finite decimal, the actual token must be one too and lie within
+// the absolute or relative epsilon; every other token must match exactly.
+func floatOutputsMatch(actual, expected string, tolerance bundle.FloatTolerance) bool {
+	actualTokens, expectedTokens := strings.Fields(actual), strings.Fields(expected)
+	if len(actualTokens) != len(expectedTokens) {
+		return false
+	}
+	for index, expectedToken := range expectedTokens {
+		expectedValue, expectedNumeric := parseDecimalToken(expectedToken)
+		if !expectedNumeric {
+			if actualTokens[index] != expectedToken {
+				return false
+			}
+			continue
+		}
+		actualValue, actualNumeric := parseDecimalToken(actualTokens[index])
+		if !actualNumeric {
+			return false
+		}
+		difference := math.Abs(actualValue - expectedValue)
+		if difference > tolerance.AbsoluteEpsilon && difference > tolerance.RelativeEpsilon*math.Abs(expectedValue) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseDecimalToken(token string) (float64, bool) {
+	if !decimalTokenPattern.MatchString(token) {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
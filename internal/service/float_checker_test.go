@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
+)
+
+func TestFloatOutputsMatchAppliesAbsoluteOrRelativeTolerance(t *testing.T) {
+	tolerance := bundle.FloatTolerance{AbsoluteEpsilon: 1e-6, RelativeEpsilon: 1e-9}
+	for _, test := range []struct {
+		name     string
+		actual   string
+		expected string
+		want     bool
+	}{
+		{name: "within absolute", actual: "3.1415929\n", expected: "3.1415926", want: true},
+		{name: "outside absolute", actual: "3.14160", expected: "3.1415926", want: false},
+		{name: "within relative", actual: "1000000000.5", expected: "1000000000", want: true},
+		{name: "outside relative", actual: "1000000002", expected: "1000000000", want: false},
+		{name: "whitespace layout", actual: " 1.0\t2.0\r\n", expected: "1\n2", want: true},
+		{name: "exponent form", actual: "1.5e-3", expected: "0.0015", want: true},
+		{name: "word tokens exact", actual: "YES 0.5000001", expected: "YES 0.5", want: true},
+		{name: "word token mismatch", actual: "yes 0.5", expected: "YES 0.5", want: false},
+		{name: "missing token", actual: "1.0", expected: "1.0 2.0", want: false},
+		{name: "extra token", actual: "1.0 2.0", expected: "1.0", want: false},
+		{name: "nan rejected", actual: "NaN", expected: "0.0", want: false},
+		{name: "infinity rejected", actual: "inf", expected: "1e308", want: false},
+		{name: "hex float rejected", actual: "0x1p-1", expected: "0.5", want: false},
+		{name: "overflow rejected", actual: "1e400", expected: "1e308", want: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := floatOutputsMatch(test.actual, test.expected, tolerance); got != test.want {
+				t.Fatalf("floatOutputsMatch(%q, %q) = %v, want %v", test.actual, test.expected, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFloatOutputsMatchHonorsSingleEpsilon(t *testing.T) {
+	if floatOutputsMatch("100.5", "100", bundle.FloatTolerance{RelativeEpsilon: 1e-3}) {
+		t.Fatal("relative-only tolerance accepted an absolute error above its bound")
+	}
+	if !floatOutputsMatch("100.05", "100", bundle.FloatTolerance{RelativeEpsilon: 1e-3}) {
+		t.Fatal("relative-only tolerance rejected an error within its bound")
+	}
+	if floatOutputsMatch("0.0000002", "0.0000001", bundle.FloatTolerance{AbsoluteEpsilon: 1e-8}) {
+		t.Fatal("absolute-only tolerance accepted an error above its bound")
+	}
+}
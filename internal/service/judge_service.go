@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 	"github.com/CodeRushOJ/croj-judging-server/pkg/model"
 )
 
@@ -50,7 +51,15 @@ func (service *JudgeService) ProcessEvent(ctx context.Context, event model.Submi
 			return service.execute(ctx, event)
 		},
 		func(ctx context.Context, result callback.Result) error {
-			_, err := service.publisher.Publish(ctx, result)
+			disposition, err := service.publisher.Publish(ctx, result)
+			switch {
+			case err == nil:
+				metrics.RecordLegacyCallback(string(disposition))
+			case callback.IsPermanent(err):
+				metrics.RecordLegacyCallback("rejected")
+			default:
+				metrics.RecordLegacyCallback("failed")
+			}
 			return err
 		},
 	)
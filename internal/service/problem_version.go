@@ -95,7 +95,7 @@ func ParseExecutionConfig(version *model.ProblemVersion) (ExecutionConfig, error
 	}
 	if judge.Checker != nil {
 		checker := bundle.Checker(*judge.Checker)
-		if !judgecontract.IsCanonicalChecker(checker) {
+		if !judgecontract.IsCanonicalChecker(checker) || checker == bundle.CheckerInteractive {
 			return ExecutionConfig{}, fmt.Errorf("immutable checker is unsupported")
 		}
 		if (*judge.SpecialJudge && checker != bundle.CheckerSpecial) ||
@@ -0,0 +1,153 @@
+// Package tracing owns the process-wide OpenTelemetry tracer provider.
+// Instrumented packages start spans through Start so every span shares one
+// instrumentation scope, and carry trace context across the two boundaries
+// that leave the process: sandbox gRPC metadata and the stored traceparent
+// that links a durable worker attempt back to its REST submission.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const instrumentationName = "github.com/CodeRushOJ/croj-judging-server"
+
+const defaultServiceName = "croj-judging-server"
+
+// traceParentLength is the fixed width of a version-00 W3C traceparent.
+const traceParentLength = 55
+
+var propagator = propagation.TraceContext{}
+
+func init() {
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Config selects the OTLP/gRPC collector. An empty Endpoint keeps the global
+// no-op provider, so instrumented code costs one interface call per span.
+type Config struct {
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+// Setup installs a batching OTLP exporter as the global tracer provider and
+// returns the flush-and-stop function the caller must run before exit.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if strings.TrimSpace(config.Endpoint) == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be within [0, 1]")
+	}
+	serviceName := strings.TrimSpace(config.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+	))
+	if err != nil {
+		_ = exporter.Shutdown(ctx)
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start opens a span on the global provider. Callers end it with End.
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, options...)
+}
+
+// End records err on span, when present, before ending it. Context
+// cancellation is recorded as an event rather than an error status because a
+// cancelled lease or client disconnect is not a server fault.
+func End(span trace.Span, err error) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			span.AddEvent("cancelled")
+		} else {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// Extract reads incoming W3C trace context from carrier into ctx.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return propagator.Extract(ctx, carrier)
+}
+
+// OutgoingGRPC returns ctx with the active span context appended to outgoing
+// gRPC metadata so sandbox workers can parent their own spans.
+func OutgoingGRPC(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return ctx
+	}
+	pairs := make([]string, 0, len(carrier)*2)
+	for _, key := range carrier.Keys() {
+		pairs = append(pairs, key, carrier.Get(key))
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// TraceParent serializes the sampled span context in ctx for durable storage.
+// It returns "" when ctx carries no valid span, which callers store as NULL.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	value := carrier.Get("traceparent")
+	if len(value) != traceParentLength {
+		return ""
+	}
+	return value
+}
+
+// LinkFromTraceParent rebuilds a span link from a stored traceparent. A
+// missing or malformed value yields no link rather than an error: tracing
+// must never fail a judge attempt.
+func LinkFromTraceParent(value string) []trace.SpanStartOption {
+	if value == "" {
+		return nil
+	}
+	remote := trace.SpanContextFromContext(propagator.Extract(
+		context.Background(), propagation.MapCarrier{"traceparent": value},
+	))
+	if !remote.IsValid() {
+		return nil
+	}
+	return []trace.SpanStartOption{trace.WithLinks(trace.Link{
+		SpanContext: remote,
+		Attributes:  []attribute.KeyValue{attribute.String("croj.link", "submission")},
+	})}
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/metadata"
+)
+
+func installRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestSetupWithoutEndpointKeepsNoopProvider(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if _, err := Setup(context.Background(), Config{Endpoint: "127.0.0.1:4317", SampleRatio: 1.5}); err == nil {
+		t.Fatal("Setup() accepted a sample ratio above 1")
+	}
+}
+
+func TestStoredTraceParentLinksWorkerSpanToSubmission(t *testing.T) {
+	recorder := installRecorder(t)
+	submitContext, submit := Start(context.Background(), "submit")
+	stored := TraceParent(submitContext)
+	End(submit, nil)
+	if len(stored) != traceParentLength {
+		t.Fatalf("TraceParent() = %q", stored)
+	}
+
+	_, attempt := Start(context.Background(), "attempt", LinkFromTraceParent(stored)...)
+	End(attempt, errors.New("sandbox unavailable"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d", len(spans))
+	}
+	worker := spans[1]
+	if worker.Parent().IsValid() {
+		t.Fatal("worker span inherited a parent instead of a link")
+	}
+	links := worker.Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != spans[0].SpanContext().SpanID() {
+		t.Fatalf("worker links = %+v", links)
+	}
+	if worker.Status().Code != codes.Error {
+		t.Fatalf("worker status = %+v", worker.Status())
+	}
+}
+
+func TestTraceParentAbsentOrMalformedYieldsNoLink(t *testing.T) {
+	if value := TraceParent(context.Background()); value != "" {
+		t.Fatalf("TraceParent() without span = %q", value)
+	}
+	for _, value := range []string{"", "00-zz-invalid"} {
+		if options := LinkFromTraceParent(value); options != nil {
+			t.Fatalf("LinkFromTraceParent(%q) = %v", value, options)
+		}
+	}
+}
+
+func TestOutgoingGRPCCarriesTraceParent(t *testing.T) {
+	installRecorder(t)
+	ctx, span := Start(context.Background(), "execute")
+	defer span.End()
+	outgoing, ok := metadata.FromOutgoingContext(OutgoingGRPC(ctx))
+	if !ok {
+		t.Fatal("OutgoingGRPC() attached no metadata")
+	}
+	if values := outgoing.Get("traceparent"); len(values) != 1 || values[0] != TraceParent(ctx) {
+		t.Fatalf("traceparent metadata = %v", values)
+	}
+	if _, ok := metadata.FromOutgoingContext(OutgoingGRPC(context.Background())); ok {
+		t.Fatal("OutgoingGRPC() attached metadata without a span")
+	}
+}
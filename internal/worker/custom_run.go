@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/logging"
+	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+)
+
+// customRunSettleTimeout bounds the settlement that runs after the request
+// context may already be cancelled.
+const customRunSettleTimeout = 10 * time.Second
+
+type CustomRunRepository interface {
+	ReserveCustomRun(context.Context, string, external.CustomRunRequest) (external.CustomRunReservation, error)
+	SettleCustomRun(context.Context, external.CustomRunReservation, external.CustomRunSettlement) error
+}
+
+type CustomRunCore interface {
+	ExecuteCustomRun(context.Context, service.CustomRunRequest) (service.CustomRunOutput, error)
+}
+
+// CustomRunner executes one synchronous custom run between a MySQL daily
+// ledger reservation and its settlement. Unlike durable jobs there is no
+// retry: the caller receives the outcome or an unavailable error.
+type CustomRunner struct {
+	repository CustomRunRepository
+	core       CustomRunCore
+}
+
+func NewCustomRunner(repository CustomRunRepository, core CustomRunCore) (*CustomRunner, error) {
+	if repository == nil || core == nil {
+		return nil, fmt.Errorf("custom run repository and execution core are required")
+	}
+	return &CustomRunner{repository: repository, core: core}, nil
+}
+
+func (runner *CustomRunner) Run(ctx context.Context, tenantID string, request external.CustomRunRequest) (external.CustomRunResult, error) {
+	reservation, err := runner.repository.ReserveCustomRun(ctx, tenantID, request)
+	if err != nil {
+		return external.CustomRunResult{}, err
+	}
+	ctx = logging.With(ctx, logging.Tenant(tenantID), slog.String("run", reservation.ExternalID))
+	output, err := runner.core.ExecuteCustomRun(ctx, service.CustomRunRequest{
+		Language: request.Language, SourceCode: string(request.SourceCode), Stdin: string(request.Stdin),
+		ExpectedOutput: string(request.ExpectedOutput), CompareOutput: request.CompareOutput,
+		TimeLimitMillis: reservation.TimeLimitMillis, MemoryLimitMiB: reservation.MemoryLimitMiB,
+	})
+	if err != nil {
+		// A caller that abandons its run still consumed sandbox time, so it is
+		// billed like a cancelled job. Platform faults, including the server's
+		// own run deadline, are refunded.
+		cancelled := errors.Is(context.Cause(ctx), context.Canceled)
+		runner.settle(ctx, reservation, external.CustomRunSettlement{ChargeFullReservation: cancelled, InfrastructureFailure: !cancelled})
+		if cancelled {
+			return external.CustomRunResult{}, context.Cause(ctx)
+		}
+		slog.WarnContext(ctx, "custom run failed", "error", err)
+		return external.CustomRunResult{}, fmt.Errorf("%w: custom run execution failed", external.ErrExternalJobUnavailable)
+	}
+	result, err := customRunResult(reservation, output)
+	if err != nil {
+		runner.settle(ctx, reservation, external.CustomRunSettlement{InfrastructureFailure: true})
+		return external.CustomRunResult{}, fmt.Errorf("%w: %v", external.ErrExternalJobUnavailable, err)
+	}
+	// Compilation failure reports no trusted run time and consumes the whole
+	// reservation, matching durable job accounting.
+	runner.settle(ctx, reservation, external.CustomRunSettlement{
+		ConsumedMillis:        result.TimeMillis,
+		ChargeFullReservation: output.Status == callback.StatusCompileError,
+	})
+	return result, nil
+}
+
+func (runner *CustomRunner) settle(ctx context.Context, reservation external.CustomRunReservation, settlement external.CustomRunSettlement) {
+	settleContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), customRunSettleTimeout)
+	defer cancel()
+	// A lost lease was already refunded by expiry. Any other failure leaves the
+	// reservation for that expiry to release.
+	if err := runner.repository.SettleCustomRun(settleContext, reservation, settlement); err != nil && !errors.Is(err, external.ErrCustomRunLeaseLost) {
+		slog.WarnContext(ctx, "custom run settlement failed", "error", err)
+	}
+}
+
+func customRunResult(reservation external.CustomRunReservation, output service.CustomRunOutput) (external.CustomRunResult, error) {
+	if !durableVerdict(output.Status) || output.TimeUsedMillis < 0 || output.MemoryUsedKB < 0 || int64(output.MemoryUsedKB) > math.MaxInt64/1024 {
+		return external.CustomRunResult{}, fmt.Errorf("custom run result is invalid")
+	}
+	return external.CustomRunResult{
+		RunID: reservation.ExternalID, Verdict: string(output.Status), ExitCode: output.ExitCode,
+		TimeMillis: int64(output.TimeUsedMillis), MemoryBytes: int64(output.MemoryUsedKB) * 1024,
+		Stdout: output.Stdout, Stderr: output.Stderr, CompileOutput: output.CompileError,
+		TimeLimitMillis: reservation.TimeLimitMillis, MemoryLimitMiB: reservation.MemoryLimitMiB,
+	}, nil
+}
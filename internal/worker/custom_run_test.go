@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+)
+
+func TestCustomRunnerExecutesWithReservedLimitsAndSettlesMeasuredTime(t *testing.T) {
+	repository := &customRunRepository{}
+	core := &customRunCore{output: service.CustomRunOutput{Status: callback.StatusAccepted, TimeUsedMillis: 40, MemoryUsedKB: 3, Stdout: "3\n"}}
+	runner, err := NewCustomRunner(repository, core)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := runner.Run(context.Background(), "tenant", customRunRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if core.request.TimeLimitMillis != 2000 || core.request.MemoryLimitMiB != 128 || core.request.Stdin != "1 2\n" {
+		t.Fatalf("core request = %+v", core.request)
+	}
+	if result.RunID != "run-1" || result.Verdict != "ACCEPTED" || result.MemoryBytes != 3072 || result.Stdout != "3\n" || result.TimeLimitMillis != 2000 {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(repository.settlements) != 1 || repository.settlements[0] != (external.CustomRunSettlement{ConsumedMillis: 40}) {
+		t.Fatalf("settlements = %+v", repository.settlements)
+	}
+}
+
+func TestCustomRunnerRefundsInfrastructureAndBillsCompileErrorsAndCancellation(t *testing.T) {
+	for name, test := range map[string]struct {
+		core      *customRunCore
+		cancel    bool
+		want      external.CustomRunSettlement
+		wantError error
+	}{
+		"infrastructure": {
+			core:      &customRunCore{err: service.ErrCanonicalInfrastructure},
+			want:      external.CustomRunSettlement{InfrastructureFailure: true},
+			wantError: external.ErrExternalJobUnavailable,
+		},
+		"compile error": {
+			core: &customRunCore{output: service.CustomRunOutput{Status: callback.StatusCompileError, CompileError: "error"}},
+			want: external.CustomRunSettlement{ChargeFullReservation: true},
+		},
+		"client cancelled": {
+			core:      &customRunCore{err: context.Canceled},
+			cancel:    true,
+			want:      external.CustomRunSettlement{ChargeFullReservation: true},
+			wantError: context.Canceled,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repository := &customRunRepository{}
+			runner, err := NewCustomRunner(repository, test.core)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+			_, err = runner.Run(ctx, "tenant", customRunRequest())
+			if !errors.Is(err, test.wantError) || test.wantError == nil && err != nil {
+				t.Fatalf("run error = %v, want %v", err, test.wantError)
+			}
+			if len(repository.settlements) != 1 || repository.settlements[0] != test.want {
+				t.Fatalf("settlements = %+v", repository.settlements)
+			}
+		})
+	}
+}
+
+func customRunRequest() external.CustomRunRequest {
+	return external.CustomRunRequest{Language: "cpp", SourceCode: []byte("int main(){}"), Stdin: []byte("1 2\n")}
+}
+
+type customRunRepository struct {
+	settlements []external.CustomRunSettlement
+}
+
+func (repository *customRunRepository) ReserveCustomRun(context.Context, string, external.CustomRunRequest) (external.CustomRunReservation, error) {
+	return external.CustomRunReservation{
+		InternalID: 1, ExternalID: "run-1", TenantInternalID: 1,
+		TimeLimitMillis: 2000, MemoryLimitMiB: 128, ReservedMillis: 2000,
+	}, nil
+}
+
+func (repository *customRunRepository) SettleCustomRun(ctx context.Context, _ external.CustomRunReservation, settlement external.CustomRunSettlement) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	repository.settlements = append(repository.settlements, settlement)
+	return nil
+}
+
+type customRunCore struct {
+	request service.CustomRunRequest
+	output  service.CustomRunOutput
+	err     error
+}
+
+func (core *customRunCore) ExecuteCustomRun(_ context.Context, request service.CustomRunRequest) (service.CustomRunOutput, error) {
+	core.request = request
+	return core.output, core.err
+}
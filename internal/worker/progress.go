@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+)
+
+const (
+	// progressFlushInterval groups the cases judged within it into one
+	// RecordProgress transaction.
+	progressFlushInterval = 500 * time.Millisecond
+	// progressBuffer bounds the reports queued behind a slow database; later
+	// reports are dropped rather than stalling the sandbox stream.
+	progressBuffer = 256
+)
+
+// claimProgress publishes partial results for one claim. Writes are advisory:
+// a failed, stale, or dropped write is acceptable because Complete still
+// persists the authoritative result, and the lease monitor owns stale-claim
+// handling. Reports are handed to one drain goroutine without blocking, so a
+// slow database never holds up judging or makes shards wait on each other.
+type claimProgress struct {
+	repository ProgressRepository
+	claim      external.WorkerJobClaim
+	timeout    time.Duration
+	interval   time.Duration
+	updates    chan external.JobProgress
+	stopped    chan struct{}
+
+	mutex           sync.Mutex
+	closed          bool
+	compileReported bool
+	reportedCases   map[string]struct{}
+}
+
+// newClaimProgress starts draining reports for claim until Close is called or
+// ctx ends. Each RecordProgress call is bounded by timeout.
+func newClaimProgress(ctx context.Context, repository ProgressRepository, claim external.WorkerJobClaim, timeout time.Duration) *claimProgress {
+	progress := &claimProgress{
+		repository: repository, claim: claim, timeout: timeout, interval: progressFlushInterval,
+		updates: make(chan external.JobProgress, progressBuffer), stopped: make(chan struct{}),
+	}
+	go progress.drain(ctx)
+	return progress
+}
+
+func (progress *claimProgress) CompileFinished(_ context.Context, succeeded bool) {
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+	// Every shard compiles the same source; only the first outcome is news.
+	if progress.compileReported {
+		return
+	}
+	progress.compileReported = true
+	compileStatus := "SUCCEEDED"
+	if !succeeded {
+		compileStatus = "FAILED"
+	}
+	progress.enqueue(external.JobProgress{CompileStatus: compileStatus})
+}
+
+func (progress *claimProgress) CasesJudged(_ context.Context, cases []service.CanonicalCaseResult) {
+	durable := make([]external.DurableCaseResult, 0, len(cases))
+	for _, item := range cases {
+		durableCase, err := durableCaseResult(item)
+		if err != nil {
+			// A verdict that cannot be persisted will fail the attempt; do not
+			// show clients cases the final result is about to discard.
+			return
+		}
+		durable = append(durable, durableCase)
+	}
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+	// A shard retried on another endpoint reports its cases again.
+	fresh := durable[:0]
+	for _, item := range durable {
+		if _, seen := progress.reportedCases[item.CaseID]; !seen {
+			fresh = append(fresh, item)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+	if progress.reportedCases == nil {
+		progress.reportedCases = make(map[string]struct{}, len(fresh))
+	}
+	for _, item := range fresh {
+		progress.reportedCases[item.CaseID] = struct{}{}
+	}
+	progress.enqueue(external.JobProgress{Cases: fresh})
+}
+
+// enqueue hands update to the drain goroutine without waiting. The caller
+// holds the mutex, which orders it against Close.
+func (progress *claimProgress) enqueue(update external.JobProgress) {
+	if progress.closed {
+		return
+	}
+	select {
+	case progress.updates <- update:
+	default:
+	}
+}
+
+// Close flushes the reports still pending and waits for the drain goroutine.
+// When the claim context has already ended, pending reports are discarded.
+func (progress *claimProgress) Close() {
+	progress.mutex.Lock()
+	if !progress.closed {
+		progress.closed = true
+		close(progress.updates)
+	}
+	progress.mutex.Unlock()
+	<-progress.stopped
+}
+
+func (progress *claimProgress) drain(ctx context.Context) {
+	defer close(progress.stopped)
+	var pending external.JobProgress
+	var timer *time.Timer
+	var flushDue <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-progress.updates:
+			if !ok {
+				progress.record(ctx, pending)
+				return
+			}
+			if update.CompileStatus != "" {
+				pending.CompileStatus = update.CompileStatus
+			}
+			pending.Cases = append(pending.Cases, update.Cases...)
+			if flushDue == nil {
+				timer = time.NewTimer(progress.interval)
+				flushDue = timer.C
+			}
+		case <-flushDue:
+			flushDue = nil
+			progress.record(ctx, pending)
+			pending = external.JobProgress{}
+		}
+	}
+}
+
+func (progress *claimProgress) record(ctx context.Context, update external.JobProgress) {
+	if update.CompileStatus == "" && len(update.Cases) == 0 {
+		return
+	}
+	callContext, cancel := context.WithTimeout(ctx, progress.timeout)
+	defer cancel()
+	_ = progress.repository.RecordProgress(callContext, progress.claim, update)
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
+	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+)
+
+func TestClaimProgressNeverBlocksJudgingOnASlowDatabase(t *testing.T) {
+	repository := &blockingProgressRepository{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := newClaimProgress(ctx, repository, external.WorkerJobClaim{WorkerID: "slow-progress"}, time.Minute)
+	progress.CompileFinished(ctx, true)
+	reported := make(chan struct{})
+	go func() {
+		defer close(reported)
+		// Far more reports than the buffer holds while the first write is stuck.
+		for index := range 4 * progressBuffer {
+			progress.CasesJudged(ctx, []service.CanonicalCaseResult{{CaseID: fmt.Sprintf("case-%d", index), Status: callback.StatusAccepted}})
+		}
+	}()
+	select {
+	case <-reported:
+	case <-time.After(5 * time.Second):
+		t.Fatal("case reports waited for the database")
+	}
+	select {
+	case <-repository.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("progress was never flushed")
+	}
+	// The claim ends while a write is stuck: Close must not flush again or hang.
+	cancel()
+	closed := make(chan struct{})
+	go func() { progress.Close(); close(closed) }()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close waited for the stuck write after the claim ended")
+	}
+	close(repository.release)
+	if calls := repository.count(); calls != 1 {
+		t.Fatalf("progress writes = %d", calls)
+	}
+}
+
+func TestClaimProgressGroupsReportsAndFlushesOnClose(t *testing.T) {
+	repository := &blockingProgressRepository{entered: make(chan struct{}, 8), release: make(chan struct{})}
+	close(repository.release)
+	progress := newClaimProgress(context.Background(), repository, external.WorkerJobClaim{WorkerID: "grouped-progress"}, time.Second)
+	progress.CompileFinished(context.Background(), false)
+	for _, caseID := range []string{"case-1", "case-2", "case-1"} {
+		progress.CasesJudged(context.Background(), []service.CanonicalCaseResult{{CaseID: caseID, Status: callback.StatusWrongAnswer}})
+	}
+	progress.Close()
+	progress.CasesJudged(context.Background(), []service.CanonicalCaseResult{{CaseID: "case-3", Status: callback.StatusAccepted}})
+	if len(repository.updates) != 1 || repository.updates[0].CompileStatus != "FAILED" || len(repository.updates[0].Cases) != 2 {
+		t.Fatalf("progress writes = %+v", repository.updates)
+	}
+}
+
+type blockingProgressRepository struct {
+	mutex   sync.Mutex
+	updates []external.JobProgress
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (repository *blockingProgressRepository) RecordProgress(ctx context.Context, _ external.WorkerJobClaim, progress external.JobProgress) error {
+	repository.mutex.Lock()
+	repository.updates = append(repository.updates, progress)
+	repository.mutex.Unlock()
+	repository.entered <- struct{}{}
+	select {
+	case <-repository.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (repository *blockingProgressRepository) count() int {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	return len(repository.updates)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"sync"
 	"time"
@@ -11,7 +12,12 @@ import (
 	"github.com/CodeRushOJ/croj-judging-server/internal/bundle"
 	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
 	"github.com/CodeRushOJ/croj-judging-server/internal/external"
+	"github.com/CodeRushOJ/croj-judging-server/internal/logging"
+	"github.com/CodeRushOJ/croj-judging-server/internal/metrics"
 	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var errCancellationRequested = errors.New("durable job cancellation requested")
@@ -24,6 +30,12 @@ type Repository interface {
 	FailInfrastructure(context.Context, external.WorkerJobClaim, external.InfrastructureFailure) (external.FailureDisposition, error)
 }
 
+// ProgressRepository is implemented by repositories that publish partial
+// results to live job event streams. Runners use it when available.
+type ProgressRepository interface {
+	RecordProgress(context.Context, external.WorkerJobClaim, external.JobProgress) error
+}
+
 type ClaimRepository interface {
 	Repository
 	ClaimNext(context.Context, string, time.Duration) (external.WorkerJobClaim, error)
@@ -61,7 +73,32 @@ func NewRunner(repository Repository, provider ArtifactProvider, core CanonicalC
 	return &Runner{repository: repository, provider: provider, core: core, config: config}, nil
 }
 
-func (runner *Runner) ExecuteClaim(ctx context.Context, claim external.WorkerJobClaim) error {
+// ExecuteClaim runs one attempt under a new root span. The attempt starts
+// long after the submitting request ended, so it links to the stored
+// submission span instead of becoming its child.
+func (runner *Runner) ExecuteClaim(ctx context.Context, claim external.WorkerJobClaim) (err error) {
+	options := append([]trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("croj.tenant", claim.Job.TenantExternalID),
+			attribute.String("croj.job", claim.Job.ExternalID),
+			attribute.Int("croj.attempt", int(claim.AttemptNo)),
+			attribute.String("croj.worker", claim.WorkerID),
+		),
+	}, tracing.LinkFromTraceParent(claim.Job.TraceParent)...)
+	ctx, span := tracing.Start(ctx, "worker.ExecuteClaim", options...)
+	defer func() { tracing.End(span, err) }()
+	ctx = logging.With(ctx,
+		logging.Tenant(claim.Job.TenantExternalID),
+		logging.Job(claim.Job.ExternalID),
+		logging.Attempt(claim.AttemptNo),
+		logging.Worker(claim.WorkerID),
+	)
+	return runner.executeClaim(ctx, claim)
+}
+
+func (runner *Runner) executeClaim(ctx context.Context, claim external.WorkerJobClaim) error {
 	executionContext, cancel := context.WithCancel(ctx)
 	controlDone := make(chan struct{})
 	controlResult := make(chan error, 1)
@@ -115,9 +152,17 @@ func (runner *Runner) executeControlled(ctx context.Context, claim external.Work
 		return service.CanonicalResult{}, "LOAD_BUNDLE_FAILED", err
 	}
 	defer artifact.Close()
-	result, err := runner.core.ExecuteCanonical(ctx, service.CanonicalExecutionRequest{
+	request := service.CanonicalExecutionRequest{
 		Language: input.Language, SourceCode: string(input.SourceCode), StopOnFailure: input.StopOnFailure,
-	}, artifact)
+	}
+	if repository, ok := runner.repository.(ProgressRepository); ok {
+		progress := newClaimProgress(ctx, repository, claim, runner.config.HeartbeatInterval)
+		// Pending reports are flushed before the claim settles, so none
+		// arrives after the result.
+		defer progress.Close()
+		request.Progress = progress
+	}
+	result, err := runner.core.ExecuteCanonical(ctx, request, artifact)
 	return result, "SANDBOX_EXECUTION_FAILED", err
 }
 
@@ -137,7 +182,14 @@ func (runner *Runner) Run(ctx context.Context, workerID string, idleBackoff time
 		return fmt.Errorf("durable worker claim loop is invalid")
 	}
 	for {
+		claimStarted := time.Now()
 		claim, err := repository.ClaimNext(ctx, workerID, runner.config.LeaseDuration)
+		metrics.ObserveWorkerClaim(claimOutcome(err), time.Since(claimStarted))
+		if err == nil && claim.AttemptNo == 1 {
+			// Both instants come from the database clock; later attempts would
+			// also count the time spent running earlier ones.
+			metrics.ObserveQueueWait(claim.LeaseUntil.Add(-runner.config.LeaseDuration).Sub(claim.Job.CreatedAt))
+		}
 		if errors.Is(err, external.ErrJobNotClaimable) {
 			if err := waitForRepositoryRetry(ctx, idleBackoff); err != nil {
 				return err
@@ -158,6 +210,7 @@ func (runner *Runner) Run(ctx context.Context, workerID string, idleBackoff time
 		}
 		if err := runner.ExecuteClaim(ctx, claim); err != nil {
 			if external.IsTransientDatabaseError(err) {
+				slog.WarnContext(ctx, "durable judge job settlement failed; retrying", logging.Worker(workerID), logging.Job(claim.Job.ExternalID), "error", err)
 				if err := waitForRepositoryRetry(ctx, idleBackoff); err != nil {
 					return err
 				}
@@ -168,6 +221,17 @@ func (runner *Runner) Run(ctx context.Context, workerID string, idleBackoff time
 	}
 }
 
+func claimOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "claimed"
+	case errors.Is(err, external.ErrJobNotClaimable):
+		return "empty"
+	default:
+		return "error"
+	}
+}
+
 func waitForRepositoryRetry(ctx context.Context, delay time.Duration) error {
 	timer := time.NewTimer(delay)
 	defer timer.Stop()
@@ -260,6 +324,7 @@ func (runner *Runner) failInfrastructure(
 	chargeFullReservation bool,
 	permanent bool,
 ) error {
+	slog.WarnContext(ctx, "durable judge job attempt failed", "failure_code", code, "permanent", permanent)
 	_, err := runner.repository.FailInfrastructure(ctx, claim, external.InfrastructureFailure{
 		Code: code, RetryDelay: runner.config.RetryDelay,
 		ChargeFullReservation: chargeFullReservation, Permanent: permanent,
@@ -288,16 +353,16 @@ func durableResult(result service.CanonicalResult) (external.DurableJobResult, e
 		Cases: make([]external.DurableCaseResult, 0, len(result.Cases)),
 	}
 	for _, item := range result.Cases {
-		if item.CaseID == "" || !durableVerdict(item.Status) || item.TimeUsedMillis < 0 || item.MemoryUsedKB < 0 || int64(item.MemoryUsedKB) > math.MaxInt64/1024 {
-			return external.DurableJobResult{}, fmt.Errorf("canonical case result is invalid")
-		}
-		if !validCanonicalScorePair(item.Score, item.MaxScore) {
-			return external.DurableJobResult{}, fmt.Errorf("canonical case score is invalid")
+		durableCase, err := durableCaseResult(item)
+		if err != nil {
+			return external.DurableJobResult{}, err
 		}
-		durable.Cases = append(durable.Cases, external.DurableCaseResult{
-			CaseID: item.CaseID, Verdict: string(item.Status),
-			TimeMillis: int64(item.TimeUsedMillis), MemoryBytes: int64(item.MemoryUsedKB) * 1024,
-			Score: copyResultScore(item.Score), MaxScore: copyResultScore(item.MaxScore),
+		durable.Cases = append(durable.Cases, durableCase)
+	}
+	for _, item := range result.Subtasks {
+		durable.Subtasks = append(durable.Subtasks, external.DurableSubtaskResult{
+			SubtaskID: item.SubtaskID, Verdict: string(item.Status),
+			Score: item.Score, MaxScore: item.MaxScore, DependenciesMet: item.DependenciesMet,
 		})
 	}
 	if err := external.ValidateDurableJobResult(durable); err != nil {
@@ -306,6 +371,20 @@ func durableResult(result service.CanonicalResult) (external.DurableJobResult, e
 	return durable, nil
 }
 
+func durableCaseResult(item service.CanonicalCaseResult) (external.DurableCaseResult, error) {
+	if item.CaseID == "" || !durableVerdict(item.Status) || item.TimeUsedMillis < 0 || item.MemoryUsedKB < 0 || int64(item.MemoryUsedKB) > math.MaxInt64/1024 {
+		return external.DurableCaseResult{}, fmt.Errorf("canonical case result is invalid")
+	}
+	if !validCanonicalScorePair(item.Score, item.MaxScore) {
+		return external.DurableCaseResult{}, fmt.Errorf("canonical case score is invalid")
+	}
+	return external.DurableCaseResult{
+		CaseID: item.CaseID, Verdict: string(item.Status),
+		TimeMillis: int64(item.TimeUsedMillis), MemoryBytes: int64(item.MemoryUsedKB) * 1024,
+		Score: copyResultScore(item.Score), MaxScore: copyResultScore(item.MaxScore),
+	}, nil
+}
+
 func validCanonicalScorePair(score, maximum *int) bool {
 	if (score == nil) != (maximum == nil) {
 		return false
@@ -326,7 +405,7 @@ func durableVerdict(status callback.Status) bool {
 	switch status {
 	case callback.StatusAccepted, callback.StatusWrongAnswer, callback.StatusCompileError,
 		callback.StatusTimeLimitExceeded, callback.StatusMemoryLimitExceeded,
-		callback.StatusRuntimeError, callback.StatusOutputLimitExceeded:
+		callback.StatusRuntimeError, callback.StatusOutputLimitExceeded, callback.StatusIdlenessLimitExceeded:
 		return true
 	default:
 		return false
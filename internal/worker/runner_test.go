@@ -12,7 +12,11 @@ import (
 	"github.com/CodeRushOJ/croj-judging-server/internal/callback"
 	"github.com/CodeRushOJ/croj-judging-server/internal/external"
 	"github.com/CodeRushOJ/croj-judging-server/internal/service"
+	"github.com/CodeRushOJ/croj-judging-server/internal/tracing"
 	mysqlDriver "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestDurableResultPreservesCanonicalVerdictCasesAndUnits(t *testing.T) {
@@ -46,6 +50,31 @@ func TestDurableResultPreservesCanonicalVerdictCasesAndUnits(t *testing.T) {
 	}
 }
 
+func TestDurableResultPreservesSubtaskScores(t *testing.T) {
+	score, total := 40, 100
+	result, err := durableResult(service.CanonicalResult{
+		Status: callback.StatusWrongAnswer, Score: &score, TotalScore: &total,
+		Cases: []service.CanonicalCaseResult{
+			{CaseID: "case-1", Status: callback.StatusAccepted},
+			{CaseID: "case-2", Status: callback.StatusTimeLimitExceeded},
+		},
+		Subtasks: []service.CanonicalSubtaskResult{
+			{SubtaskID: "small", Status: callback.StatusAccepted, Score: 40, MaxScore: 40, DependenciesMet: true},
+			{SubtaskID: "large", Status: callback.StatusTimeLimitExceeded, MaxScore: 60, DependenciesMet: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []external.DurableSubtaskResult{
+		{SubtaskID: "small", Verdict: "ACCEPTED", Score: 40, MaxScore: 40, DependenciesMet: true},
+		{SubtaskID: "large", Verdict: "TIME_LIMIT_EXCEEDED", MaxScore: 60, DependenciesMet: true},
+	}
+	if fmt.Sprint(result.Subtasks) != fmt.Sprint(want) || result.Cases[0].Score != nil {
+		t.Fatalf("durable subtasks = %+v", result.Subtasks)
+	}
+}
+
 func TestDurableResultRejectsInfrastructureSystemError(t *testing.T) {
 	if _, err := durableResult(service.CanonicalResult{Status: callback.StatusSystemError}); err == nil {
 		t.Fatal("SYSTEM_ERROR must be routed through infrastructure failure")
@@ -56,6 +85,9 @@ func TestDurableVerdictAcceptsOutputLimitExceeded(t *testing.T) {
 	if !durableVerdict(callback.StatusOutputLimitExceeded) {
 		t.Fatal("OUTPUT_LIMIT_EXCEEDED must be a durable contestant verdict")
 	}
+	if !durableVerdict(callback.StatusIdlenessLimitExceeded) {
+		t.Fatal("IDLENESS_LIMIT_EXCEEDED must be a durable contestant verdict")
+	}
 }
 
 func TestRunnerPropagatesCancellationToCanonicalCoreAndFencedCompletion(t *testing.T) {
@@ -293,6 +325,122 @@ func TestRunnerChargesTenantSpecialJudgeFailuresToTheAttemptReservation(t *testi
 	}
 }
 
+func TestRunnerPublishesDeduplicatedProgressForItsClaim(t *testing.T) {
+	claim := external.WorkerJobClaim{
+		Job: external.ExternalJobRecord{InternalID: 15}, WorkerID: "progress-worker",
+		AttemptNo: 2, LeaseToken: make([]byte, 32), LeaseUntil: time.Now().Add(time.Second),
+	}
+	repository := &progressRunnerRepository{runnerRepository: runnerRepository{input: external.WorkerExecutionInput{
+		Language: "go126", SourceCode: []byte("package main"),
+		Bundle: external.WorkerBundleInput{ObjectKey: "bundle.zip", SHA256: strings.Repeat("a", 64), SizeBytes: 1},
+	}}}
+	runner, err := NewRunner(repository, staticProvider{artifact: &runnerArtifact{}}, progressCore{}, Config{
+		LeaseDuration: time.Second, HeartbeatInterval: 20 * time.Millisecond, ControlPollInterval: 10 * time.Millisecond, RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.ExecuteClaim(context.Background(), claim); err != nil {
+		t.Fatal(err)
+	}
+	// Reports made within one flush interval are grouped into one write,
+	// and the pending group is flushed before the claim completes.
+	if repository.completions != 1 || len(repository.progress) != 1 || repository.afterCompletion != 0 {
+		t.Fatalf("completions=%d progress=%+v after completion=%d", repository.completions, repository.progress, repository.afterCompletion)
+	}
+	if repository.progress[0].CompileStatus != "SUCCEEDED" {
+		t.Fatalf("compile progress = %+v", repository.progress[0])
+	}
+	cases := repository.progress[0].Cases
+	if len(cases) != 1 || cases[0].CaseID != "case-1" || cases[0].Verdict != string(callback.StatusWrongAnswer) || cases[0].MemoryBytes != 2048 {
+		t.Fatalf("case progress = %+v", repository.progress[0])
+	}
+	for _, recorded := range repository.claims {
+		if recorded.AttemptNo != claim.AttemptNo || recorded.WorkerID != claim.WorkerID {
+			t.Fatalf("progress claim = %+v", recorded)
+		}
+	}
+}
+
+func TestRunnerLinksAttemptSpanToStoredSubmissionTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	submitContext, submit := otel.Tracer("test").Start(context.Background(), "submit")
+	traceParent := tracing.TraceParent(submitContext)
+	submit.End()
+
+	claim := external.WorkerJobClaim{
+		Job: external.ExternalJobRecord{InternalID: 16, ExternalID: "job-traced", TraceParent: traceParent}, WorkerID: "traced-worker",
+		AttemptNo: 1, LeaseToken: make([]byte, 32), LeaseUntil: time.Now().Add(time.Second),
+	}
+	repository := &runnerRepository{input: external.WorkerExecutionInput{
+		Language: "go126", SourceCode: []byte("package main"),
+		Bundle: external.WorkerBundleInput{ObjectKey: "bundle.zip", SHA256: strings.Repeat("a", 64), SizeBytes: 1},
+	}}
+	runner, err := NewRunner(repository, staticProvider{artifact: &runnerArtifact{}}, acceptedCore{}, Config{
+		LeaseDuration: time.Second, HeartbeatInterval: 20 * time.Millisecond, ControlPollInterval: 10 * time.Millisecond, RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.ExecuteClaim(submitContext, claim); err != nil {
+		t.Fatal(err)
+	}
+	var attempt sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "worker.ExecuteClaim" {
+			attempt = span
+		}
+	}
+	if attempt == nil {
+		t.Fatal("attempt span was not recorded")
+	}
+	if attempt.Parent().IsValid() || attempt.SpanContext().TraceID() == submit.SpanContext().TraceID() {
+		t.Fatal("attempt span joined the submission trace instead of starting a new root")
+	}
+	links := attempt.Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != submit.SpanContext().SpanID() {
+		t.Fatalf("attempt links = %+v", links)
+	}
+}
+
+type progressRunnerRepository struct {
+	runnerRepository
+	progress        []external.JobProgress
+	claims          []external.WorkerJobClaim
+	afterCompletion int
+}
+
+func (repository *progressRunnerRepository) RecordProgress(_ context.Context, claim external.WorkerJobClaim, progress external.JobProgress) error {
+	if repository.completions != 0 {
+		repository.afterCompletion++
+	}
+	repository.progress = append(repository.progress, progress)
+	repository.claims = append(repository.claims, claim)
+	return nil
+}
+
+// progressCore reports like a two-shard pipeline, including a non-durable
+// verdict that must never reach the event stream and a retried shard that
+// reports a case again.
+type progressCore struct{}
+
+func (progressCore) ExecuteCanonical(ctx context.Context, request service.CanonicalExecutionRequest, _ service.CaseArtifact) (service.CanonicalResult, error) {
+	wrongAnswer := service.CanonicalCaseResult{CaseID: "case-1", Status: callback.StatusWrongAnswer, TimeUsedMillis: 4, MemoryUsedKB: 2}
+	request.Progress.CompileFinished(ctx, true)
+	request.Progress.CasesJudged(ctx, []service.CanonicalCaseResult{wrongAnswer})
+	request.Progress.CompileFinished(ctx, true)
+	request.Progress.CasesJudged(ctx, []service.CanonicalCaseResult{{CaseID: "case-2", Status: callback.StatusSystemError}})
+	request.Progress.CasesJudged(ctx, []service.CanonicalCaseResult{wrongAnswer})
+	return service.CanonicalResult{Status: callback.StatusWrongAnswer, Cases: []service.CanonicalCaseResult{wrongAnswer}}, nil
+}
+
 type runnerRepository struct {
 	input                     external.WorkerExecutionInput
 	cancelled                 bool
@@ -18,12 +18,17 @@ type Config struct {
 	SandboxDiscovery SandboxDiscoveryConfig `yaml:"sandbox-discovery"`
 	ExternalAPI      ExternalAPIConfig      `yaml:"external-api"`
 	LegacyJudge      LegacyJudgeConfig      `yaml:"legacy-judge"`
-	// 可以添加其他配置项，例如日志级别、沙盒路径等
+	Metrics          MetricsConfig          `yaml:"metrics"`
+	Tracing          TracingConfig          `yaml:"tracing"`
+	Logging          LoggingConfig          `yaml:"logging"`
+	// 可以添加其他配置项，例如沙盒路径等
 }
 
 type ExternalAPIConfig struct {
 	Enabled                       bool   `yaml:"enabled"`
 	ListenAddress                 string `yaml:"listen-address"`
+	AdminListenAddress            string `yaml:"admin-listen-address"`
+	AdminToken                    string `yaml:"-"`
 	WorkerID                      string `yaml:"worker-id"`
 	WorkerConcurrency             int    `yaml:"worker-concurrency"`
 	LeaseDuration                 string `yaml:"lease-duration"`
@@ -40,6 +45,8 @@ type ExternalAPIConfig struct {
 	JobBodyReadTimeout            string `yaml:"job-body-read-timeout"`
 	JobSubmitTimeout              string `yaml:"job-submit-timeout"`
 	JobBodyConcurrency            int    `yaml:"job-body-concurrency"`
+	JobEventStreamConcurrency     int    `yaml:"job-event-stream-concurrency"`
+	RunConcurrency                int    `yaml:"run-concurrency"`
 	BundleOperationTimeout        string `yaml:"bundle-operation-timeout"`
 	BundleMinUploadBytesPerSecond int64  `yaml:"bundle-min-upload-bytes-per-second"`
 	BundleUploadConcurrency       int    `yaml:"bundle-upload-concurrency"`
@@ -58,17 +65,39 @@ type ExternalAPIConfig struct {
 	SourceKeysJSON                string `yaml:"-"`
 	CallbackKeyVersion            string `yaml:"-"`
 	CallbackKeysJSON              string `yaml:"-"`
+	WebhookSigningKeyID           string `yaml:"-"`
+	WebhookSigningKeysJSON        string `yaml:"-"`
 	WebhookWorkerConcurrency      int    `yaml:"webhook-worker-concurrency"`
 	IdempotencyTTL                string `yaml:"idempotency-ttl"`
 	QuotaRefillPeriod             string `yaml:"quota-refill-period"`
 	JobSubmitCapacity             int64  `yaml:"job-submit-capacity"`
 	BundleByteCapacity            int64  `yaml:"bundle-byte-capacity"`
+	RunCapacity                   int64  `yaml:"run-capacity"`
 }
 
 type LegacyJudgeConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+// MetricsConfig 独立的 Prometheus 监听地址，为空时不暴露 /metrics
+type MetricsConfig struct {
+	ListenAddress string `yaml:"listen-address"`
+}
+
+// TracingConfig OpenTelemetry OTLP/gRPC 导出配置，endpoint 为空时不导出 span
+type TracingConfig struct {
+	OTLPEndpoint string  `yaml:"otlp-endpoint"`
+	Insecure     bool    `yaml:"insecure"`
+	ServiceName  string  `yaml:"service-name"`
+	SampleRatio  float64 `yaml:"sample-ratio"`
+}
+
+// LoggingConfig 结构化日志级别（debug/info/warn/error）与格式（json/text）
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
 // RocketMQConfig RocketMQ 相关配置
 type RocketMQConfig struct {
 	NameServer string `yaml:"name-server"`
@@ -141,7 +170,6 @@ type SandboxDiscoveryConfig struct {
 
 // LoadConfig 从指定路径加载配置文件
 func LoadConfig(configPath string) (*Config, error) {
-	fmt.Printf("Loading config from: %s\n", configPath)
 	config := &Config{LegacyJudge: LegacyJudgeConfig{Enabled: true}}
 
 	file, err := os.Open(configPath)
@@ -171,7 +199,28 @@ func (config *Config) applyEnvironment() error {
 		config.ExternalAPI.Enabled = parsed
 	}
 	overrideString(&config.ExternalAPI.ListenAddress, "EXTERNAL_API_LISTEN_ADDRESS")
+	overrideString(&config.ExternalAPI.AdminListenAddress, "EXTERNAL_ADMIN_LISTEN_ADDRESS")
+	overrideString(&config.ExternalAPI.AdminToken, "EXTERNAL_ADMIN_TOKEN")
 	overrideString(&config.ExternalAPI.WorkerID, "EXTERNAL_WORKER_ID")
+	overrideString(&config.Metrics.ListenAddress, "METRICS_LISTEN_ADDRESS")
+	overrideString(&config.Logging.Level, "LOG_LEVEL")
+	overrideString(&config.Logging.Format, "LOG_FORMAT")
+	overrideString(&config.Tracing.OTLPEndpoint, "TRACING_OTLP_ENDPOINT")
+	overrideString(&config.Tracing.ServiceName, "TRACING_SERVICE_NAME")
+	if value, ok := os.LookupEnv("TRACING_OTLP_INSECURE"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("TRACING_OTLP_INSECURE must be true or false")
+		}
+		config.Tracing.Insecure = parsed
+	}
+	if value, ok := os.LookupEnv("TRACING_SAMPLE_RATIO"); ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return fmt.Errorf("TRACING_SAMPLE_RATIO must be a number within [0, 1]")
+		}
+		config.Tracing.SampleRatio = parsed
+	}
 	overrideString(&config.ExternalAPI.LeaseDuration, "EXTERNAL_WORKER_LEASE_DURATION")
 	overrideString(&config.ExternalAPI.HeartbeatInterval, "EXTERNAL_WORKER_HEARTBEAT_INTERVAL")
 	overrideString(&config.ExternalAPI.ControlPollInterval, "EXTERNAL_WORKER_CONTROL_POLL_INTERVAL")
@@ -202,6 +251,8 @@ func (config *Config) applyEnvironment() error {
 	overrideString(&config.ExternalAPI.SourceKeysJSON, "EXTERNAL_SOURCE_KEYS_JSON")
 	overrideString(&config.ExternalAPI.CallbackKeyVersion, "JUDGE_CALLBACK_KEY_VERSION")
 	overrideString(&config.ExternalAPI.CallbackKeysJSON, "JUDGE_CALLBACK_KEYS_JSON")
+	overrideString(&config.ExternalAPI.WebhookSigningKeyID, "JUDGE_WEBHOOK_SIGNING_KEY_ID")
+	overrideString(&config.ExternalAPI.WebhookSigningKeysJSON, "JUDGE_WEBHOOK_SIGNING_KEYS_JSON")
 	overrideString(&config.ExternalAPI.IdempotencyTTL, "EXTERNAL_IDEMPOTENCY_TTL")
 	overrideString(&config.ExternalAPI.QuotaRefillPeriod, "EXTERNAL_QUOTA_REFILL_PERIOD")
 	if err := overridePositiveInt(&config.ExternalAPI.WorkerConcurrency, "EXTERNAL_WORKER_CONCURRENCY"); err != nil {
@@ -219,6 +270,12 @@ func (config *Config) applyEnvironment() error {
 	if err := overridePositiveInt(&config.ExternalAPI.JobBodyConcurrency, "EXTERNAL_JOB_BODY_CONCURRENCY"); err != nil {
 		return err
 	}
+	if err := overridePositiveInt(&config.ExternalAPI.JobEventStreamConcurrency, "EXTERNAL_JOB_EVENT_STREAM_CONCURRENCY"); err != nil {
+		return err
+	}
+	if err := overridePositiveInt(&config.ExternalAPI.RunConcurrency, "EXTERNAL_RUN_CONCURRENCY"); err != nil {
+		return err
+	}
 	if value, ok := os.LookupEnv("LEGACY_JUDGE_ENABLED"); ok {
 		parsed, err := strconv.ParseBool(value)
 		if err != nil {
@@ -239,6 +296,9 @@ func (config *Config) applyEnvironment() error {
 	if err := overridePositiveInt64(&config.ExternalAPI.BundleByteCapacity, "EXTERNAL_BUNDLE_BYTE_CAPACITY"); err != nil {
 		return err
 	}
+	if err := overridePositiveInt64(&config.ExternalAPI.RunCapacity, "EXTERNAL_RUN_CAPACITY"); err != nil {
+		return err
+	}
 	overrideString(&config.Database.Host, "DATABASE_HOST")
 	overrideString(&config.Database.User, "DATABASE_USERNAME")
 	overrideString(&config.Database.Password, "DATABASE_PASSWORD")
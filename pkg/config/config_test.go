@@ -76,6 +76,8 @@ sandbox-discovery:
 	t.Setenv("EXTERNAL_SOURCE_KEYS_JSON", `{"1":"source-key"}`)
 	t.Setenv("JUDGE_CALLBACK_KEY_VERSION", "2")
 	t.Setenv("JUDGE_CALLBACK_KEYS_JSON", `{"2":"callback-key"}`)
+	t.Setenv("JUDGE_WEBHOOK_SIGNING_KEY_ID", "2026-10")
+	t.Setenv("JUDGE_WEBHOOK_SIGNING_KEYS_JSON", `{"2026-10":"signing-seed"}`)
 	t.Setenv("EXTERNAL_WEBHOOK_WORKER_CONCURRENCY", "4")
 	t.Setenv("EXTERNAL_API_READ_HEADER_TIMEOUT", "4s")
 	t.Setenv("EXTERNAL_API_READ_TIMEOUT", "45s")
@@ -84,6 +86,9 @@ sandbox-discovery:
 	t.Setenv("EXTERNAL_JOB_BODY_READ_TIMEOUT", "90s")
 	t.Setenv("EXTERNAL_JOB_SUBMIT_TIMEOUT", "3m")
 	t.Setenv("EXTERNAL_JOB_BODY_CONCURRENCY", "23")
+	t.Setenv("EXTERNAL_JOB_EVENT_STREAM_CONCURRENCY", "300")
+	t.Setenv("EXTERNAL_RUN_CONCURRENCY", "9")
+	t.Setenv("EXTERNAL_RUN_CAPACITY", "45")
 	t.Setenv("EXTERNAL_BUNDLE_OPERATION_TIMEOUT", "15m")
 	t.Setenv("EXTERNAL_BUNDLE_MIN_UPLOAD_BYTES_PER_SECOND", "1048576")
 	t.Setenv("EXTERNAL_BUNDLE_UPLOAD_CONCURRENCY", "7")
@@ -91,6 +96,14 @@ sandbox-discovery:
 	t.Setenv("EXTERNAL_RETENTION_IDLE_DELAY", "2m")
 	t.Setenv("EXTERNAL_RETENTION_DELETE_TIMEOUT", "20s")
 	t.Setenv("LEGACY_JUDGE_ENABLED", "false")
+	t.Setenv("METRICS_LISTEN_ADDRESS", "127.0.0.1:9464")
+	t.Setenv("TRACING_OTLP_ENDPOINT", "127.0.0.1:4317")
+	t.Setenv("TRACING_OTLP_INSECURE", "true")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("EXTERNAL_ADMIN_LISTEN_ADDRESS", "127.0.0.1:9465")
+	t.Setenv("EXTERNAL_ADMIN_TOKEN", "runtime-only-admin-token-32-bytes")
 
 	config, err := LoadConfig(path)
 	if err != nil {
@@ -123,18 +136,44 @@ sandbox-discovery:
 	if config.ExternalAPI.JudgeDatabaseDSN != "judge:secret@tcp(judge-mysql:3306)/coderushoj_judge" ||
 		config.ExternalAPI.SourceKeysJSON != `{"1":"source-key"}` || config.ExternalAPI.CallbackKeyVersion != "2" ||
 		config.ExternalAPI.CallbackKeysJSON != `{"2":"callback-key"}` || config.ExternalAPI.WebhookWorkerConcurrency != 4 ||
+		config.ExternalAPI.WebhookSigningKeyID != "2026-10" || config.ExternalAPI.WebhookSigningKeysJSON != `{"2026-10":"signing-seed"}` ||
 		config.ExternalAPI.ReadHeaderTimeout != "4s" || config.ExternalAPI.ReadTimeout != "45s" ||
 		config.ExternalAPI.WriteTimeout != "50s" || config.ExternalAPI.IdleTimeout != "70s" ||
 		config.ExternalAPI.JobBodyReadTimeout != "90s" || config.ExternalAPI.JobSubmitTimeout != "3m" ||
-		config.ExternalAPI.JobBodyConcurrency != 23 || config.ExternalAPI.BundleOperationTimeout != "15m" ||
+		config.ExternalAPI.JobBodyConcurrency != 23 || config.ExternalAPI.JobEventStreamConcurrency != 300 ||
+		config.ExternalAPI.RunConcurrency != 9 || config.ExternalAPI.RunCapacity != 45 ||
+		config.ExternalAPI.BundleOperationTimeout != "15m" ||
 		config.ExternalAPI.BundleMinUploadBytesPerSecond != 1048576 ||
 		config.ExternalAPI.BundleUploadConcurrency != 7 || config.ExternalAPI.SourceRetention != "1080h" ||
-		config.ExternalAPI.RetentionIdleDelay != "2m" || config.ExternalAPI.RetentionDeleteTimeout != "20s" {
+		config.ExternalAPI.RetentionIdleDelay != "2m" || config.ExternalAPI.RetentionDeleteTimeout != "20s" ||
+		config.ExternalAPI.AdminListenAddress != "127.0.0.1:9465" || config.ExternalAPI.AdminToken != "runtime-only-admin-token-32-bytes" {
 		t.Fatalf("external secret/runtime overrides not applied: %+v", config.ExternalAPI)
 	}
 	if config.LegacyJudge.Enabled {
 		t.Fatal("legacy adapter opt-out was not applied")
 	}
+	if config.Metrics.ListenAddress != "127.0.0.1:9464" {
+		t.Fatalf("metrics listen address = %q", config.Metrics.ListenAddress)
+	}
+	if config.Tracing.OTLPEndpoint != "127.0.0.1:4317" || !config.Tracing.Insecure || config.Tracing.SampleRatio != 0.25 {
+		t.Fatalf("tracing overrides not applied: %+v", config.Tracing)
+	}
+	if config.Logging.Level != "debug" || config.Logging.Format != "text" {
+		t.Fatalf("logging overrides not applied: %+v", config.Logging)
+	}
+}
+
+func TestLoadConfigRejectsOutOfRangeTracingSampleRatio(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("tracing: {otlp-endpoint: 127.0.0.1:4317}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRACING_SAMPLE_RATIO", "2")
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected out-of-range TRACING_SAMPLE_RATIO to fail")
+	}
 }
 
 func TestLoadConfigRejectsInvalidEnvironmentPort(t *testing.T) {
@@ -70,7 +70,121 @@ func (x ExecuteBatchV1Event_Kind) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ExecuteBatchV1Event_Kind.Descriptor instead.
 func (ExecuteBatchV1Event_Kind) EnumDescriptor() ([]byte, []int) {
-	return file_proto_sandbox_proto_rawDescGZIP(), []int{4, 0}
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{5, 0}
+}
+
+type ExecuteInteractiveV1Event_Kind int32
+
+const (
+	ExecuteInteractiveV1Event_KIND_UNSPECIFIED         ExecuteInteractiveV1Event_Kind = 0
+	ExecuteInteractiveV1Event_CASE_RESULT              ExecuteInteractiveV1Event_Kind = 1
+	ExecuteInteractiveV1Event_COMPILE_ERROR            ExecuteInteractiveV1Event_Kind = 2
+	ExecuteInteractiveV1Event_INTERACTOR_COMPILE_ERROR ExecuteInteractiveV1Event_Kind = 3
+	ExecuteInteractiveV1Event_COMPLETED                ExecuteInteractiveV1Event_Kind = 4
+)
+
+// Enum value maps for ExecuteInteractiveV1Event_Kind.
+var (
+	ExecuteInteractiveV1Event_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "CASE_RESULT",
+		2: "COMPILE_ERROR",
+		3: "INTERACTOR_COMPILE_ERROR",
+		4: "COMPLETED",
+	}
+	ExecuteInteractiveV1Event_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED":         0,
+		"CASE_RESULT":              1,
+		"COMPILE_ERROR":            2,
+		"INTERACTOR_COMPILE_ERROR": 3,
+		"COMPLETED":                4,
+	}
+)
+
+func (x ExecuteInteractiveV1Event_Kind) Enum() *ExecuteInteractiveV1Event_Kind {
+	p := new(ExecuteInteractiveV1Event_Kind)
+	*p = x
+	return p
+}
+
+func (x ExecuteInteractiveV1Event_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExecuteInteractiveV1Event_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sandbox_proto_enumTypes[1].Descriptor()
+}
+
+func (ExecuteInteractiveV1Event_Kind) Type() protoreflect.EnumType {
+	return &file_proto_sandbox_proto_enumTypes[1]
+}
+
+func (x ExecuteInteractiveV1Event_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExecuteInteractiveV1Event_Kind.Descriptor instead.
+func (ExecuteInteractiveV1Event_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{8, 0}
+}
+
+// InteractorVerdict is derived from the interactor exit: 0 accepts the
+// dialogue, 1 rejects it, and any other exit, signal or limit is FAILED.
+// NOT_FINISHED means the sandbox stopped the pair because of a contestant
+// limit before the interactor exited.
+type ExecuteInteractiveV1Event_InteractorVerdict int32
+
+const (
+	ExecuteInteractiveV1Event_INTERACTOR_VERDICT_UNSPECIFIED ExecuteInteractiveV1Event_InteractorVerdict = 0
+	ExecuteInteractiveV1Event_INTERACTOR_ACCEPTED            ExecuteInteractiveV1Event_InteractorVerdict = 1
+	ExecuteInteractiveV1Event_INTERACTOR_WRONG_ANSWER        ExecuteInteractiveV1Event_InteractorVerdict = 2
+	ExecuteInteractiveV1Event_INTERACTOR_FAILED              ExecuteInteractiveV1Event_InteractorVerdict = 3
+	ExecuteInteractiveV1Event_INTERACTOR_NOT_FINISHED        ExecuteInteractiveV1Event_InteractorVerdict = 4
+)
+
+// Enum value maps for ExecuteInteractiveV1Event_InteractorVerdict.
+var (
+	ExecuteInteractiveV1Event_InteractorVerdict_name = map[int32]string{
+		0: "INTERACTOR_VERDICT_UNSPECIFIED",
+		1: "INTERACTOR_ACCEPTED",
+		2: "INTERACTOR_WRONG_ANSWER",
+		3: "INTERACTOR_FAILED",
+		4: "INTERACTOR_NOT_FINISHED",
+	}
+	ExecuteInteractiveV1Event_InteractorVerdict_value = map[string]int32{
+		"INTERACTOR_VERDICT_UNSPECIFIED": 0,
+		"INTERACTOR_ACCEPTED":            1,
+		"INTERACTOR_WRONG_ANSWER":        2,
+		"INTERACTOR_FAILED":              3,
+		"INTERACTOR_NOT_FINISHED":        4,
+	}
+)
+
+func (x ExecuteInteractiveV1Event_InteractorVerdict) Enum() *ExecuteInteractiveV1Event_InteractorVerdict {
+	p := new(ExecuteInteractiveV1Event_InteractorVerdict)
+	*p = x
+	return p
+}
+
+func (x ExecuteInteractiveV1Event_InteractorVerdict) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExecuteInteractiveV1Event_InteractorVerdict) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sandbox_proto_enumTypes[2].Descriptor()
+}
+
+func (ExecuteInteractiveV1Event_InteractorVerdict) Type() protoreflect.EnumType {
+	return &file_proto_sandbox_proto_enumTypes[2]
+}
+
+func (x ExecuteInteractiveV1Event_InteractorVerdict) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExecuteInteractiveV1Event_InteractorVerdict.Descriptor instead.
+func (ExecuteInteractiveV1Event_InteractorVerdict) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{8, 1}
 }
 
 type ExecuteRequest struct {
@@ -265,6 +379,7 @@ type ExecuteBatchV1Request struct {
 	MemoryLimit   int32                  `protobuf:"varint,4,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
 	StopOnFailure bool                   `protobuf:"varint,5,opt,name=stop_on_failure,json=stopOnFailure,proto3" json:"stop_on_failure,omitempty"`
 	Cases         []*ExecuteBatchV1Case  `protobuf:"bytes,6,rep,name=cases,proto3" json:"cases,omitempty"`
+	CompileFiles  []*CompileFile         `protobuf:"bytes,7,rep,name=compile_files,json=compileFiles,proto3" json:"compile_files,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -341,6 +456,77 @@ func (x *ExecuteBatchV1Request) GetCases() []*ExecuteBatchV1Case {
 	return nil
 }
 
+func (x *ExecuteBatchV1Request) GetCompileFiles() []*CompileFile {
+	if x != nil {
+		return x.CompileFiles
+	}
+	return nil
+}
+
+// CompileFile is a trusted file placed beside the contestant source before the
+// single compilation. Non-header files are compiled and linked with it;
+// headers are only visible to includes. Names are bare file names and must not
+// collide with the contestant source file chosen by the sandbox.
+type CompileFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Header        bool                   `protobuf:"varint,3,opt,name=header,proto3" json:"header,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompileFile) Reset() {
+	*x = CompileFile{}
+	mi := &file_proto_sandbox_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompileFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileFile) ProtoMessage() {}
+
+func (x *CompileFile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sandbox_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileFile.ProtoReflect.Descriptor instead.
+func (*CompileFile) Descriptor() ([]byte, []int) {
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CompileFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CompileFile) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CompileFile) GetHeader() bool {
+	if x != nil {
+		return x.Header
+	}
+	return false
+}
+
 type ExecuteBatchV1Case struct {
 	state               protoimpl.MessageState `protogen:"open.v1"`
 	CaseId              string                 `protobuf:"bytes,1,opt,name=case_id,json=caseId,proto3" json:"case_id,omitempty"`
@@ -354,7 +540,7 @@ type ExecuteBatchV1Case struct {
 
 func (x *ExecuteBatchV1Case) Reset() {
 	*x = ExecuteBatchV1Case{}
-	mi := &file_proto_sandbox_proto_msgTypes[3]
+	mi := &file_proto_sandbox_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -366,7 +552,7 @@ func (x *ExecuteBatchV1Case) String() string {
 func (*ExecuteBatchV1Case) ProtoMessage() {}
 
 func (x *ExecuteBatchV1Case) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sandbox_proto_msgTypes[3]
+	mi := &file_proto_sandbox_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -379,7 +565,7 @@ func (x *ExecuteBatchV1Case) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteBatchV1Case.ProtoReflect.Descriptor instead.
 func (*ExecuteBatchV1Case) Descriptor() ([]byte, []int) {
-	return file_proto_sandbox_proto_rawDescGZIP(), []int{3}
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ExecuteBatchV1Case) GetCaseId() string {
@@ -428,7 +614,7 @@ type ExecuteBatchV1Event struct {
 
 func (x *ExecuteBatchV1Event) Reset() {
 	*x = ExecuteBatchV1Event{}
-	mi := &file_proto_sandbox_proto_msgTypes[4]
+	mi := &file_proto_sandbox_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -440,7 +626,7 @@ func (x *ExecuteBatchV1Event) String() string {
 func (*ExecuteBatchV1Event) ProtoMessage() {}
 
 func (x *ExecuteBatchV1Event) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sandbox_proto_msgTypes[4]
+	mi := &file_proto_sandbox_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -453,7 +639,7 @@ func (x *ExecuteBatchV1Event) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteBatchV1Event.ProtoReflect.Descriptor instead.
 func (*ExecuteBatchV1Event) Descriptor() ([]byte, []int) {
-	return file_proto_sandbox_proto_rawDescGZIP(), []int{4}
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ExecuteBatchV1Event) GetKind() ExecuteBatchV1Event_Kind {
@@ -477,6 +663,264 @@ func (x *ExecuteBatchV1Event) GetResult() *ExecuteResponse {
 	return nil
 }
 
+// ExecuteInteractiveV1Request compiles the contestant and the interactor once,
+// then runs every case with the contestant stdout piped to the interactor stdin
+// and the interactor stdout piped to the contestant stdin. Case input and
+// answer are visible only to the interactor.
+type ExecuteInteractiveV1Request struct {
+	state                 protoimpl.MessageState      `protogen:"open.v1"`
+	Language              string                      `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	SourceCode            string                      `protobuf:"bytes,2,opt,name=source_code,json=sourceCode,proto3" json:"source_code,omitempty"`
+	Timeout               int32                       `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	MemoryLimit           int32                       `protobuf:"varint,4,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
+	StopOnFailure         bool                        `protobuf:"varint,5,opt,name=stop_on_failure,json=stopOnFailure,proto3" json:"stop_on_failure,omitempty"`
+	InteractorLanguage    string                      `protobuf:"bytes,6,opt,name=interactor_language,json=interactorLanguage,proto3" json:"interactor_language,omitempty"`
+	InteractorSourceCode  string                      `protobuf:"bytes,7,opt,name=interactor_source_code,json=interactorSourceCode,proto3" json:"interactor_source_code,omitempty"`
+	InteractorTimeout     int32                       `protobuf:"varint,8,opt,name=interactor_timeout,json=interactorTimeout,proto3" json:"interactor_timeout,omitempty"`
+	InteractorMemoryLimit int32                       `protobuf:"varint,9,opt,name=interactor_memory_limit,json=interactorMemoryLimit,proto3" json:"interactor_memory_limit,omitempty"`
+	Cases                 []*ExecuteInteractiveV1Case `protobuf:"bytes,10,rep,name=cases,proto3" json:"cases,omitempty"`
+	CompileFiles          []*CompileFile              `protobuf:"bytes,11,rep,name=compile_files,json=compileFiles,proto3" json:"compile_files,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ExecuteInteractiveV1Request) Reset() {
+	*x = ExecuteInteractiveV1Request{}
+	mi := &file_proto_sandbox_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteInteractiveV1Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteInteractiveV1Request) ProtoMessage() {}
+
+func (x *ExecuteInteractiveV1Request) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sandbox_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteInteractiveV1Request.ProtoReflect.Descriptor instead.
+func (*ExecuteInteractiveV1Request) Descriptor() ([]byte, []int) {
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExecuteInteractiveV1Request) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Request) GetSourceCode() string {
+	if x != nil {
+		return x.SourceCode
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Request) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *ExecuteInteractiveV1Request) GetMemoryLimit() int32 {
+	if x != nil {
+		return x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *ExecuteInteractiveV1Request) GetStopOnFailure() bool {
+	if x != nil {
+		return x.StopOnFailure
+	}
+	return false
+}
+
+func (x *ExecuteInteractiveV1Request) GetInteractorLanguage() string {
+	if x != nil {
+		return x.InteractorLanguage
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Request) GetInteractorSourceCode() string {
+	if x != nil {
+		return x.InteractorSourceCode
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Request) GetInteractorTimeout() int32 {
+	if x != nil {
+		return x.InteractorTimeout
+	}
+	return 0
+}
+
+func (x *ExecuteInteractiveV1Request) GetInteractorMemoryLimit() int32 {
+	if x != nil {
+		return x.InteractorMemoryLimit
+	}
+	return 0
+}
+
+func (x *ExecuteInteractiveV1Request) GetCases() []*ExecuteInteractiveV1Case {
+	if x != nil {
+		return x.Cases
+	}
+	return nil
+}
+
+func (x *ExecuteInteractiveV1Request) GetCompileFiles() []*CompileFile {
+	if x != nil {
+		return x.CompileFiles
+	}
+	return nil
+}
+
+type ExecuteInteractiveV1Case struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CaseId        string                 `protobuf:"bytes,1,opt,name=case_id,json=caseId,proto3" json:"case_id,omitempty"`
+	Input         string                 `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	Answer        string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteInteractiveV1Case) Reset() {
+	*x = ExecuteInteractiveV1Case{}
+	mi := &file_proto_sandbox_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteInteractiveV1Case) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteInteractiveV1Case) ProtoMessage() {}
+
+func (x *ExecuteInteractiveV1Case) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sandbox_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteInteractiveV1Case.ProtoReflect.Descriptor instead.
+func (*ExecuteInteractiveV1Case) Descriptor() ([]byte, []int) {
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExecuteInteractiveV1Case) GetCaseId() string {
+	if x != nil {
+		return x.CaseId
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Case) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Case) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+type ExecuteInteractiveV1Event struct {
+	state  protoimpl.MessageState         `protogen:"open.v1"`
+	Kind   ExecuteInteractiveV1Event_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=sandbox.ExecuteInteractiveV1Event_Kind" json:"kind,omitempty"`
+	CaseId string                         `protobuf:"bytes,2,opt,name=case_id,json=caseId,proto3" json:"case_id,omitempty"`
+	// result describes the contestant process. Its status additionally uses
+	// "Idleness Limit Exceeded" when both processes blocked on each other.
+	Result            *ExecuteResponse                            `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	InteractorVerdict ExecuteInteractiveV1Event_InteractorVerdict `protobuf:"varint,4,opt,name=interactor_verdict,json=interactorVerdict,proto3,enum=sandbox.ExecuteInteractiveV1Event_InteractorVerdict" json:"interactor_verdict,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ExecuteInteractiveV1Event) Reset() {
+	*x = ExecuteInteractiveV1Event{}
+	mi := &file_proto_sandbox_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteInteractiveV1Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteInteractiveV1Event) ProtoMessage() {}
+
+func (x *ExecuteInteractiveV1Event) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sandbox_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteInteractiveV1Event.ProtoReflect.Descriptor instead.
+func (*ExecuteInteractiveV1Event) Descriptor() ([]byte, []int) {
+	return file_proto_sandbox_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExecuteInteractiveV1Event) GetKind() ExecuteInteractiveV1Event_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return ExecuteInteractiveV1Event_KIND_UNSPECIFIED
+}
+
+func (x *ExecuteInteractiveV1Event) GetCaseId() string {
+	if x != nil {
+		return x.CaseId
+	}
+	return ""
+}
+
+func (x *ExecuteInteractiveV1Event) GetResult() *ExecuteResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ExecuteInteractiveV1Event) GetInteractorVerdict() ExecuteInteractiveV1Event_InteractorVerdict {
+	if x != nil {
+		return x.InteractorVerdict
+	}
+	return ExecuteInteractiveV1Event_INTERACTOR_VERDICT_UNSPECIFIED
+}
+
 var File_proto_sandbox_proto protoreflect.FileDescriptor
 
 const file_proto_sandbox_proto_rawDesc = "" +
@@ -499,7 +943,7 @@ const file_proto_sandbox_proto_rawDesc = "" +
 	"\rcompile_error\x18\x06 \x01(\tR\fcompileError\x12\x1b\n" +
 	"\ttime_used\x18\a \x01(\x03R\btimeUsed\x12\x1f\n" +
 	"\vmemory_used\x18\b \x01(\x03R\n" +
-	"memoryUsed\"\xec\x01\n" +
+	"memoryUsed\"\xa7\x02\n" +
 	"\x15ExecuteBatchV1Request\x12\x1a\n" +
 	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x1f\n" +
 	"\vsource_code\x18\x02 \x01(\tR\n" +
@@ -507,7 +951,12 @@ const file_proto_sandbox_proto_rawDesc = "" +
 	"\atimeout\x18\x03 \x01(\x05R\atimeout\x12!\n" +
 	"\fmemory_limit\x18\x04 \x01(\x05R\vmemoryLimit\x12&\n" +
 	"\x0fstop_on_failure\x18\x05 \x01(\bR\rstopOnFailure\x121\n" +
-	"\x05cases\x18\x06 \x03(\v2\x1b.sandbox.ExecuteBatchV1CaseR\x05cases\"\xc7\x01\n" +
+	"\x05cases\x18\x06 \x03(\v2\x1b.sandbox.ExecuteBatchV1CaseR\x05cases\x129\n" +
+	"\rcompile_files\x18\a \x03(\v2\x14.sandbox.CompileFileR\fcompileFiles\"S\n" +
+	"\vCompileFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x16\n" +
+	"\x06header\x18\x03 \x01(\bR\x06header\"\xc7\x01\n" +
 	"\x12ExecuteBatchV1Case\x12\x17\n" +
 	"\acase_id\x18\x01 \x01(\tR\x06caseId\x12\x14\n" +
 	"\x05stdin\x18\x02 \x01(\tR\x05stdin\x12'\n" +
@@ -522,10 +971,46 @@ const file_proto_sandbox_proto_rawDesc = "" +
 	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0f\n" +
 	"\vCASE_RESULT\x10\x01\x12\x11\n" +
 	"\rCOMPILE_ERROR\x10\x02\x12\r\n" +
-	"\tCOMPLETED\x10\x032\xa4\x01\n" +
+	"\tCOMPLETED\x10\x03\"\x81\x04\n" +
+	"\x1bExecuteInteractiveV1Request\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x1f\n" +
+	"\vsource_code\x18\x02 \x01(\tR\n" +
+	"sourceCode\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\x05R\atimeout\x12!\n" +
+	"\fmemory_limit\x18\x04 \x01(\x05R\vmemoryLimit\x12&\n" +
+	"\x0fstop_on_failure\x18\x05 \x01(\bR\rstopOnFailure\x12/\n" +
+	"\x13interactor_language\x18\x06 \x01(\tR\x12interactorLanguage\x124\n" +
+	"\x16interactor_source_code\x18\a \x01(\tR\x14interactorSourceCode\x12-\n" +
+	"\x12interactor_timeout\x18\b \x01(\x05R\x11interactorTimeout\x126\n" +
+	"\x17interactor_memory_limit\x18\t \x01(\x05R\x15interactorMemoryLimit\x127\n" +
+	"\x05cases\x18\n" +
+	" \x03(\v2!.sandbox.ExecuteInteractiveV1CaseR\x05cases\x129\n" +
+	"\rcompile_files\x18\v \x03(\v2\x14.sandbox.CompileFileR\fcompileFiles\"a\n" +
+	"\x18ExecuteInteractiveV1Case\x12\x17\n" +
+	"\acase_id\x18\x01 \x01(\tR\x06caseId\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\tR\x05input\x12\x16\n" +
+	"\x06answer\x18\x03 \x01(\tR\x06answer\"\x9b\x04\n" +
+	"\x19ExecuteInteractiveV1Event\x12;\n" +
+	"\x04kind\x18\x01 \x01(\x0e2'.sandbox.ExecuteInteractiveV1Event.KindR\x04kind\x12\x17\n" +
+	"\acase_id\x18\x02 \x01(\tR\x06caseId\x120\n" +
+	"\x06result\x18\x03 \x01(\v2\x18.sandbox.ExecuteResponseR\x06result\x12c\n" +
+	"\x12interactor_verdict\x18\x04 \x01(\x0e24.sandbox.ExecuteInteractiveV1Event.InteractorVerdictR\x11interactorVerdict\"m\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vCASE_RESULT\x10\x01\x12\x11\n" +
+	"\rCOMPILE_ERROR\x10\x02\x12\x1c\n" +
+	"\x18INTERACTOR_COMPILE_ERROR\x10\x03\x12\r\n" +
+	"\tCOMPLETED\x10\x04\"\xa1\x01\n" +
+	"\x11InteractorVerdict\x12\"\n" +
+	"\x1eINTERACTOR_VERDICT_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13INTERACTOR_ACCEPTED\x10\x01\x12\x1b\n" +
+	"\x17INTERACTOR_WRONG_ANSWER\x10\x02\x12\x15\n" +
+	"\x11INTERACTOR_FAILED\x10\x03\x12\x1b\n" +
+	"\x17INTERACTOR_NOT_FINISHED\x10\x042\x8a\x02\n" +
 	"\x0eSandboxService\x12>\n" +
 	"\aExecute\x12\x17.sandbox.ExecuteRequest\x1a\x18.sandbox.ExecuteResponse\"\x00\x12R\n" +
-	"\x0eExecuteBatchV1\x12\x1e.sandbox.ExecuteBatchV1Request\x1a\x1c.sandbox.ExecuteBatchV1Event\"\x000\x01B1Z/github.com/CodeRushOJ/croj-judging-server/protob\x06proto3"
+	"\x0eExecuteBatchV1\x12\x1e.sandbox.ExecuteBatchV1Request\x1a\x1c.sandbox.ExecuteBatchV1Event\"\x000\x01\x12d\n" +
+	"\x14ExecuteInteractiveV1\x12$.sandbox.ExecuteInteractiveV1Request\x1a\".sandbox.ExecuteInteractiveV1Event\"\x000\x01B1Z/github.com/CodeRushOJ/croj-judging-server/protob\x06proto3"
 
 var (
 	file_proto_sandbox_proto_rawDescOnce sync.Once
@@ -539,29 +1024,43 @@ func file_proto_sandbox_proto_rawDescGZIP() []byte {
 	return file_proto_sandbox_proto_rawDescData
 }
 
-var file_proto_sandbox_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_sandbox_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_sandbox_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_sandbox_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_sandbox_proto_goTypes = []any{
-	(ExecuteBatchV1Event_Kind)(0), // 0: sandbox.ExecuteBatchV1Event.Kind
-	(*ExecuteRequest)(nil),        // 1: sandbox.ExecuteRequest
-	(*ExecuteResponse)(nil),       // 2: sandbox.ExecuteResponse
-	(*ExecuteBatchV1Request)(nil), // 3: sandbox.ExecuteBatchV1Request
-	(*ExecuteBatchV1Case)(nil),    // 4: sandbox.ExecuteBatchV1Case
-	(*ExecuteBatchV1Event)(nil),   // 5: sandbox.ExecuteBatchV1Event
+	(ExecuteBatchV1Event_Kind)(0),                    // 0: sandbox.ExecuteBatchV1Event.Kind
+	(ExecuteInteractiveV1Event_Kind)(0),              // 1: sandbox.ExecuteInteractiveV1Event.Kind
+	(ExecuteInteractiveV1Event_InteractorVerdict)(0), // 2: sandbox.ExecuteInteractiveV1Event.InteractorVerdict
+	(*ExecuteRequest)(nil),                           // 3: sandbox.ExecuteRequest
+	(*ExecuteResponse)(nil),                          // 4: sandbox.ExecuteResponse
+	(*ExecuteBatchV1Request)(nil),                    // 5: sandbox.ExecuteBatchV1Request
+	(*CompileFile)(nil),                              // 6: sandbox.CompileFile
+	(*ExecuteBatchV1Case)(nil),                       // 7: sandbox.ExecuteBatchV1Case
+	(*ExecuteBatchV1Event)(nil),                      // 8: sandbox.ExecuteBatchV1Event
+	(*ExecuteInteractiveV1Request)(nil),              // 9: sandbox.ExecuteInteractiveV1Request
+	(*ExecuteInteractiveV1Case)(nil),                 // 10: sandbox.ExecuteInteractiveV1Case
+	(*ExecuteInteractiveV1Event)(nil),                // 11: sandbox.ExecuteInteractiveV1Event
 }
 var file_proto_sandbox_proto_depIdxs = []int32{
-	4, // 0: sandbox.ExecuteBatchV1Request.cases:type_name -> sandbox.ExecuteBatchV1Case
-	0, // 1: sandbox.ExecuteBatchV1Event.kind:type_name -> sandbox.ExecuteBatchV1Event.Kind
-	2, // 2: sandbox.ExecuteBatchV1Event.result:type_name -> sandbox.ExecuteResponse
-	1, // 3: sandbox.SandboxService.Execute:input_type -> sandbox.ExecuteRequest
-	3, // 4: sandbox.SandboxService.ExecuteBatchV1:input_type -> sandbox.ExecuteBatchV1Request
-	2, // 5: sandbox.SandboxService.Execute:output_type -> sandbox.ExecuteResponse
-	5, // 6: sandbox.SandboxService.ExecuteBatchV1:output_type -> sandbox.ExecuteBatchV1Event
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	7,  // 0: sandbox.ExecuteBatchV1Request.cases:type_name -> sandbox.ExecuteBatchV1Case
+	6,  // 1: sandbox.ExecuteBatchV1Request.compile_files:type_name -> sandbox.CompileFile
+	0,  // 2: sandbox.ExecuteBatchV1Event.kind:type_name -> sandbox.ExecuteBatchV1Event.Kind
+	4,  // 3: sandbox.ExecuteBatchV1Event.result:type_name -> sandbox.ExecuteResponse
+	10, // 4: sandbox.ExecuteInteractiveV1Request.cases:type_name -> sandbox.ExecuteInteractiveV1Case
+	6,  // 5: sandbox.ExecuteInteractiveV1Request.compile_files:type_name -> sandbox.CompileFile
+	1,  // 6: sandbox.ExecuteInteractiveV1Event.kind:type_name -> sandbox.ExecuteInteractiveV1Event.Kind
+	4,  // 7: sandbox.ExecuteInteractiveV1Event.result:type_name -> sandbox.ExecuteResponse
+	2,  // 8: sandbox.ExecuteInteractiveV1Event.interactor_verdict:type_name -> sandbox.ExecuteInteractiveV1Event.InteractorVerdict
+	3,  // 9: sandbox.SandboxService.Execute:input_type -> sandbox.ExecuteRequest
+	5,  // 10: sandbox.SandboxService.ExecuteBatchV1:input_type -> sandbox.ExecuteBatchV1Request
+	9,  // 11: sandbox.SandboxService.ExecuteInteractiveV1:input_type -> sandbox.ExecuteInteractiveV1Request
+	4,  // 12: sandbox.SandboxService.Execute:output_type -> sandbox.ExecuteResponse
+	8,  // 13: sandbox.SandboxService.ExecuteBatchV1:output_type -> sandbox.ExecuteBatchV1Event
+	11, // 14: sandbox.SandboxService.ExecuteInteractiveV1:output_type -> sandbox.ExecuteInteractiveV1Event
+	12, // [12:15] is the sub-list for method output_type
+	9,  // [9:12] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_sandbox_proto_init() }
@@ -574,8 +1073,8 @@ func file_proto_sandbox_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_sandbox_proto_rawDesc), len(file_proto_sandbox_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   5,
+			NumEnums:      3,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SandboxService_Execute_FullMethodName        = "/sandbox.SandboxService/Execute"
-	SandboxService_ExecuteBatchV1_FullMethodName = "/sandbox.SandboxService/ExecuteBatchV1"
+	SandboxService_Execute_FullMethodName              = "/sandbox.SandboxService/Execute"
+	SandboxService_ExecuteBatchV1_FullMethodName       = "/sandbox.SandboxService/ExecuteBatchV1"
+	SandboxService_ExecuteInteractiveV1_FullMethodName = "/sandbox.SandboxService/ExecuteInteractiveV1"
 )
 
 // SandboxServiceClient is the client API for SandboxService service.
@@ -31,6 +32,7 @@ const (
 type SandboxServiceClient interface {
 	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
 	ExecuteBatchV1(ctx context.Context, in *ExecuteBatchV1Request, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecuteBatchV1Event], error)
+	ExecuteInteractiveV1(ctx context.Context, in *ExecuteInteractiveV1Request, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecuteInteractiveV1Event], error)
 }
 
 type sandboxServiceClient struct {
@@ -70,6 +72,25 @@ func (c *sandboxServiceClient) ExecuteBatchV1(ctx context.Context, in *ExecuteBa
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SandboxService_ExecuteBatchV1Client = grpc.ServerStreamingClient[ExecuteBatchV1Event]
 
+func (c *sandboxServiceClient) ExecuteInteractiveV1(ctx context.Context, in *ExecuteInteractiveV1Request, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecuteInteractiveV1Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SandboxService_ServiceDesc.Streams[1], SandboxService_ExecuteInteractiveV1_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecuteInteractiveV1Request, ExecuteInteractiveV1Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SandboxService_ExecuteInteractiveV1Client = grpc.ServerStreamingClient[ExecuteInteractiveV1Event]
+
 // SandboxServiceServer is the server API for SandboxService service.
 // All implementations must embed UnimplementedSandboxServiceServer
 // for forward compatibility.
@@ -78,6 +99,7 @@ type SandboxService_ExecuteBatchV1Client = grpc.ServerStreamingClient[ExecuteBat
 type SandboxServiceServer interface {
 	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
 	ExecuteBatchV1(*ExecuteBatchV1Request, grpc.ServerStreamingServer[ExecuteBatchV1Event]) error
+	ExecuteInteractiveV1(*ExecuteInteractiveV1Request, grpc.ServerStreamingServer[ExecuteInteractiveV1Event]) error
 	mustEmbedUnimplementedSandboxServiceServer()
 }
 
@@ -94,6 +116,9 @@ func (UnimplementedSandboxServiceServer) Execute(context.Context, *ExecuteReques
 func (UnimplementedSandboxServiceServer) ExecuteBatchV1(*ExecuteBatchV1Request, grpc.ServerStreamingServer[ExecuteBatchV1Event]) error {
 	return status.Errorf(codes.Unimplemented, "method ExecuteBatchV1 not implemented")
 }
+func (UnimplementedSandboxServiceServer) ExecuteInteractiveV1(*ExecuteInteractiveV1Request, grpc.ServerStreamingServer[ExecuteInteractiveV1Event]) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteInteractiveV1 not implemented")
+}
 func (UnimplementedSandboxServiceServer) mustEmbedUnimplementedSandboxServiceServer() {}
 func (UnimplementedSandboxServiceServer) testEmbeddedByValue()                        {}
 
@@ -144,6 +169,17 @@ func _SandboxService_ExecuteBatchV1_Handler(srv interface{}, stream grpc.ServerS
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SandboxService_ExecuteBatchV1Server = grpc.ServerStreamingServer[ExecuteBatchV1Event]
 
+func _SandboxService_ExecuteInteractiveV1_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecuteInteractiveV1Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SandboxServiceServer).ExecuteInteractiveV1(m, &grpc.GenericServerStream[ExecuteInteractiveV1Request, ExecuteInteractiveV1Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SandboxService_ExecuteInteractiveV1Server = grpc.ServerStreamingServer[ExecuteInteractiveV1Event]
+
 // SandboxService_ServiceDesc is the grpc.ServiceDesc for SandboxService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -162,6 +198,11 @@ var SandboxService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _SandboxService_ExecuteBatchV1_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExecuteInteractiveV1",
+			Handler:       _SandboxService_ExecuteInteractiveV1_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/sandbox.proto",
 }